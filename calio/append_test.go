@@ -0,0 +1,95 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// appendBackends open an empty log to append to.
+var appendBackends = []struct {
+	name string
+	open func(t *testing.T) Storage
+}{
+	{"file", func(t *testing.T) Storage { return NewFileStorage(t.TempDir()) }},
+	{"sheets", func(t *testing.T) Storage { return newFakeSheets("Log").mustStorage(t, SheetsConfig{}) }},
+	{"sheets per year", func(t *testing.T) Storage { return newFakeSheets().mustStorage(t, SheetsConfig{PerYear: true}) }},
+}
+
+// TestAppendRowIndex checks the RowIndex Append and AppendBatch return is
+// the one a later read reports for the entry.
+func TestAppendRowIndex(t *testing.T) {
+	ctx := context.Background()
+	for _, backend := range appendBackends {
+		storage := backend.open(t)
+		first, err := storage.Append(ctx, pushups)
+		if err != nil {
+			t.Fatalf("%s: %v", backend.name, err)
+		}
+		batch, err := storage.AppendBatch(ctx, []WorkoutEntry{squats, withDate(pushups, "2026-03-05")})
+		if err != nil {
+			t.Fatalf("%s: %v", backend.name, err)
+		}
+		appended := append([]WorkoutEntry{first}, batch...)
+
+		for _, entry := range appended {
+			read, err := storage.SearchByDate(ctx, entry.Date)
+			if err != nil {
+				t.Fatalf("%s: %v", backend.name, err)
+			}
+			found := false
+			for _, r := range read {
+				if r.Exercise == entry.Exercise {
+					found = true
+					if r.RowIndex != entry.RowIndex || r.Type != entry.Type || r.Category != entry.Category {
+						t.Errorf("%s: %s appended at row %d as %s/%s, read at row %d as %s/%s", backend.name,
+							entry.Exercise, entry.RowIndex, entry.Type, entry.Category, r.RowIndex, r.Type, r.Category)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("%s: %s of %s isn't read back", backend.name, entry.Exercise, entry.Date)
+			}
+		}
+		if appended[0].RowIndex == appended[1].RowIndex || appended[1].RowIndex == appended[2].RowIndex {
+			t.Errorf("%s: entries appended at rows %d, %d and %d", backend.name, appended[0].RowIndex, appended[1].RowIndex, appended[2].RowIndex)
+		}
+	}
+}
+
+// TestAppendAfterUnterminatedLine appends to a log file whose last line was
+// written without a newline.
+func TestAppendAfterUnterminatedLine(t *testing.T) {
+	ctx := context.Background()
+	f := NewFileStorage(t.TempDir())
+	if err := os.WriteFile(f.FileFor(pushups.Date), []byte(strings.TrimSuffix(serializeLogEntry(pushups), "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := f.Append(ctx, squats)
+	if err != nil || entry.RowIndex != 1 {
+		t.Fatalf("Append = row %d, %v, want row 1", entry.RowIndex, err)
+	}
+	read, err := f.SearchByDate(ctx, pushups.Date)
+	if err != nil || len(read) != 2 || read[1].Exercise != "Squats" || read[1].RowIndex != 1 {
+		t.Errorf("SearchByDate = %+v, %v, want Pushups then Squats on line 2", read, err)
+	}
+}
+
+func TestRowURL(t *testing.T) {
+	f := newFakeSheets("Notes", "Log")
+	s := f.mustStorage(t, SheetsConfig{})
+	entry, err := s.Append(context.Background(), pushups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheetID := f.tab("Log").id
+	want := fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/edit#gid=%d&range=%d:%d", fakeSpreadsheetID, sheetID, entry.RowIndex+1, entry.RowIndex+1)
+	if got := s.RowURL(entry); got != want {
+		t.Errorf("RowURL = %q, want %q", got, want)
+	}
+	if got := s.TabFor(entry.Date); got != "Log" {
+		t.Errorf("TabFor = %q, want Log", got)
+	}
+}
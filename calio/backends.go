@@ -0,0 +1,132 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BackendConfig is what the cali command resolved before opening storage,
+// handed to the constructor of the backend CALI_STORAGE names.
+type BackendConfig struct {
+	// Name is the backend's registered name, as CALI_STORAGE selected it.
+	Name string
+	// Sheet is the log chosen with --sheet: a tab, a directory, a table;
+	// whatever the backend keeps separate logs in. Empty for the default.
+	Sheet string
+	// Getenv reads settings, with config.env applied. Backends take their
+	// own settings from it, e.g. a connection string.
+	Getenv func(string) string
+	// Now is the current time, for anything dated by the backend.
+	Now func() time.Time
+	// Writer names the program writing. The built-in backends record it
+	// with every entry they add, in its schema marker.
+	Writer string
+	// Progress reports slow operations. Never nil.
+	Progress Progress
+	// Logf prints diagnostics shown with --verbose. Never nil.
+	Logf func(format string, args ...any)
+}
+
+// BackendFunc opens a storage backend. The contract, which CheckBackend
+// checks:
+//
+//   - It returns a usable Storage or an error, never neither. Missing
+//     settings are reported as a *ConfigError naming the setting.
+//   - It stops and returns ctx's error once ctx is done; opening may reach
+//     a server, reading the log must wait for the first method call.
+//   - The Storage is safe for concurrent use and meets the Storage
+//     interface's documented behaviour, errors included. It may also
+//     implement the optional interfaces (EntryWalker, GoalStore,
+//     EntryRestorer, ...); the commands needing one say so when it doesn't.
+//   - Entries keep their User as given: the cali command wraps the
+//     Storage in a UserStorage itself, which fills it in and filters by it.
+type BackendFunc func(ctx context.Context, cfg BackendConfig) (Storage, error)
+
+// withDefaults returns cfg with the functions left nil filled in: no
+// settings, the wall clock, no progress and no diagnostics.
+func (cfg BackendConfig) withDefaults() BackendConfig {
+	if cfg.Getenv == nil {
+		cfg.Getenv = func(string) string { return "" }
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.Progress == nil {
+		cfg.Progress = noProgress{}
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(string, ...any) {}
+	}
+	return cfg
+}
+
+// UnknownBackendError reports a CALI_STORAGE value no backend is registered
+// under.
+type UnknownBackendError struct {
+	Name      string
+	Available []string
+}
+
+func (e *UnknownBackendError) Error() string {
+	return fmt.Sprintf("unknown storage backend %q (available: %s)", e.Name, strings.Join(e.Available, ", "))
+}
+
+var backends = struct {
+	mu    sync.RWMutex
+	opens map[string]BackendFunc
+}{opens: map[string]BackendFunc{}}
+
+// RegisterBackend makes a backend available under name, which CALI_STORAGE
+// then selects, ignoring case. It is meant to be called from an init
+// function or before running the cali command, and panics when name is
+// empty or taken or open is nil, like database/sql.Register.
+func RegisterBackend(name string, open BackendFunc) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	backends.mu.Lock()
+	defer backends.mu.Unlock()
+	switch {
+	case key == "":
+		panic("calio: RegisterBackend with an empty name")
+	case open == nil:
+		panic(fmt.Sprintf("calio: RegisterBackend %q with a nil constructor", name))
+	case backends.opens[key] != nil:
+		panic(fmt.Sprintf("calio: RegisterBackend called twice for %q", name))
+	}
+	backends.opens[key] = open
+}
+
+// Backends returns the registered backend names, sorted.
+func Backends() []string {
+	backends.mu.RLock()
+	defer backends.mu.RUnlock()
+	names := make([]string, 0, len(backends.opens))
+	for name := range backends.opens {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// OpenBackend opens the backend registered under name, ignoring case, with
+// cfg.Name set to it. An unregistered name fails with an
+// *UnknownBackendError listing the ones there are.
+func OpenBackend(ctx context.Context, name string, cfg BackendConfig) (Storage, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	backends.mu.RLock()
+	open := backends.opens[key]
+	backends.mu.RUnlock()
+	if open == nil {
+		return nil, &UnknownBackendError{Name: name, Available: Backends()}
+	}
+	cfg = cfg.withDefaults()
+	cfg.Name = key
+	storage, err := open(ctx, cfg)
+	if err == nil && storage == nil {
+		err = fmt.Errorf("the %s backend returned no storage", key)
+	}
+	return storage, err
+}
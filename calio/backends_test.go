@@ -0,0 +1,188 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryBackend is a backend as another module would write one: a Storage
+// of its own, kept in memory, registered under a name.
+type memoryBackend struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	entries []WorkoutEntry
+}
+
+func (m *memoryBackend) Append(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, error) {
+	stored, err := m.AppendBatch(ctx, []WorkoutEntry{entry})
+	if err != nil {
+		return WorkoutEntry{}, err
+	}
+	return stored[0], nil
+}
+
+func (m *memoryBackend) AppendBatch(ctx context.Context, entries []WorkoutEntry) ([]WorkoutEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := make([]WorkoutEntry, len(entries))
+	for i, entry := range entries {
+		stored[i] = storedAt(entry, int64(len(m.entries)))
+		m.entries = append(m.entries, stored[i])
+	}
+	return stored, nil
+}
+
+// sorted returns the entries oldest first, in the order stored within a
+// day.
+func (m *memoryBackend) sorted(ctx context.Context) ([]WorkoutEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries := slices.Clone(m.entries)
+	slices.SortStableFunc(entries, func(a, b WorkoutEntry) int { return strings.Compare(a.Date, b.Date) })
+	return entries, nil
+}
+
+func (m *memoryBackend) Recent(ctx context.Context, limit int) ([]WorkoutEntry, error) {
+	entries, err := m.sorted(ctx)
+	return entries[max(0, len(entries)-limit):], err
+}
+
+func (m *memoryBackend) All(ctx context.Context) ([]WorkoutEntry, error) {
+	return m.sorted(ctx)
+}
+
+func (m *memoryBackend) Range(ctx context.Context, since, until string) ([]WorkoutEntry, error) {
+	entries, err := m.sorted(ctx)
+	return slices.DeleteFunc(entries, func(entry WorkoutEntry) bool {
+		return since != "" && entry.Date < since || until != "" && entry.Date > until
+	}), err
+}
+
+func (m *memoryBackend) SearchByDate(ctx context.Context, date string) ([]WorkoutEntry, error) {
+	return m.Range(ctx, date, date)
+}
+
+func (m *memoryBackend) RemoveByDateIndex(ctx context.Context, date string, index int) error {
+	found, err := m.SearchByDate(ctx, date)
+	if err != nil {
+		return err
+	}
+	if err := checkIndex(date, index, len(found)); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = slices.DeleteFunc(m.entries, func(entry WorkoutEntry) bool { return entry.RowIndex == found[index].RowIndex })
+	return nil
+}
+
+func (m *memoryBackend) LastTrainingDay(ctx context.Context) (string, string, error) {
+	entries, err := m.sorted(ctx)
+	day, date := LastStrengthDay(WithoutFuture(entries, m.now()))
+	return day, date, err
+}
+
+// openMemoryBackend opens a new, empty memoryBackend.
+func openMemoryBackend(ctx context.Context, cfg BackendConfig) (Storage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &memoryBackend{now: cfg.Now}, nil
+}
+
+// TestMemoryBackendConformance checks the backend of the tests below meets
+// the contract, so what they see is the registry's doing.
+func TestMemoryBackendConformance(t *testing.T) {
+	if err := CheckBackend(context.Background(), openMemoryBackend, BackendConfig{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var (
+	registerTestBackends sync.Once
+	memoryTestConfig     BackendConfig // what memory-test was last opened with
+	errNoServer          = errors.New("no server")
+)
+
+// registerTestOnce registers the backends of the tests below, once however
+// many times they run: memory-test, one returning neither a storage nor an
+// error, and one failing.
+func registerTestOnce() {
+	registerTestBackends.Do(func() {
+		RegisterBackend("Memory-Test", func(ctx context.Context, cfg BackendConfig) (Storage, error) {
+			memoryTestConfig = cfg
+			return openMemoryBackend(ctx, cfg)
+		})
+		RegisterBackend("nothing-test", func(context.Context, BackendConfig) (Storage, error) { return nil, nil })
+		RegisterBackend("failing-test", func(context.Context, BackendConfig) (Storage, error) { return nil, errNoServer })
+	})
+}
+
+// TestRegisterBackend registers a backend and opens it by name, in any
+// case, with the config's gaps filled in.
+func TestRegisterBackend(t *testing.T) {
+	ctx := context.Background()
+	registerTestOnce()
+	if !slices.Contains(Backends(), "memory-test") || !slices.IsSorted(Backends()) {
+		t.Errorf("Backends() = %q", Backends())
+	}
+
+	storage, err := OpenBackend(ctx, " MEMORY-test ", BackendConfig{Sheet: "legs", Writer: "cali/test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := memoryTestConfig
+	if got.Name != "memory-test" || got.Sheet != "legs" || got.Writer != "cali/test" {
+		t.Errorf("the constructor got %+v", got)
+	}
+	if got.Getenv == nil || got.Now == nil || got.Progress == nil || got.Logf == nil {
+		t.Errorf("the constructor got nil functions: %+v", got)
+	}
+	if _, err := storage.Append(ctx, pushups); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := storage.All(ctx); err != nil || len(entries) != 1 || entries[0].Exercise != "Pushups" {
+		t.Errorf("All = %+v, %v", entries, err)
+	}
+
+	_, err = OpenBackend(ctx, "postgres", BackendConfig{})
+	var unknown *UnknownBackendError
+	if !errors.As(err, &unknown) || unknown.Name != "postgres" || !slices.Equal(unknown.Available, Backends()) {
+		t.Errorf("OpenBackend(postgres) = %v", err)
+	}
+	if storage, err := OpenBackend(ctx, "nothing-test", BackendConfig{}); err == nil {
+		t.Errorf("a constructor returning neither gave %v", storage)
+	}
+	if _, err := OpenBackend(ctx, "failing-test", BackendConfig{}); !errors.Is(err, errNoServer) {
+		t.Errorf("a failing constructor gave %v", err)
+	}
+}
+
+func TestRegisterBackendPanics(t *testing.T) {
+	registerTestOnce()
+	for name, register := range map[string]func(){
+		"empty name": func() { RegisterBackend(" ", openMemoryBackend) },
+		"nil":        func() { RegisterBackend("nil-test", nil) },
+		"taken":      func() { RegisterBackend(" MEMORY-TEST", openMemoryBackend) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: RegisterBackend didn't panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
@@ -0,0 +1,189 @@
+// Package calio is the library behind the cali command: the workout log
+// entry, the Storage interface with its local-file and Google Sheets
+// backends, and the Convict Conditioning dataset of exercises, levels, goals
+// and tutorial links.
+//
+// The cali binary is a consumer of this package; other programs (a web
+// viewer, an exporter) can read and write the same log through it, or give
+// the cali command a backend of their own (see RegisterBackend).
+package calio
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout of WorkoutEntry.Date, as stored by every backend.
+const DateLayout = "2006-01-02"
+
+// DefaultSheetName is the tab the Sheets backend uses when none is given.
+const DefaultSheetName = "Log"
+
+// Workout types stored alongside each entry. Entries written before the type
+// column existed read back as straight sets.
+const (
+	TypeStraightSets = "straight-sets"
+	TypeInterval     = "interval"
+)
+
+// Session categories stored alongside each entry. Entries written before the
+// category column existed read back as strength work.
+const (
+	CategoryStrength = "strength"
+	CategoryMobility = "mobility"
+)
+
+// WorkoutEntry is one logged exercise. RowIndex is where the entry is stored,
+// counting from 0: its row within its sheet tab for the Sheets backend, its
+// line within its year file for local files. It is -1 on an appended entry
+// whose row the Sheets API didn't report. User
+// names who logged it in a shared log (see UserStorage) and is empty for
+// entries logged without one. Schema and Writer come from the row's schema
+// marker and are 0 and empty for rows written before markers existed.
+// RawDate is the date cell as the sheet returned it when that wasn't
+// already Date (see NormalizeDate), and empty otherwise. Duration is how
+// many minutes the session took, measured by the interactive log flow; 0
+// when not measured. Load is the weight added to the exercise, as "+10kg"
+// (see Load), and empty for bodyweight work. LoggedAt is when the entry was
+// written, in UTC (see LoggedTime), and empty for rows written before
+// schema 4.
+type WorkoutEntry struct {
+	Date     string
+	RawDate  string
+	Day      string
+	Exercise string
+	Level    string
+	RepsSets string
+	Goal     string
+	Comment  string
+	Type     string
+	Category string
+	User     string
+	Schema   int
+	Writer   string
+	Duration int
+	Load     string
+	LoggedAt string
+	RowIndex int64
+}
+
+// storedAt returns entry as the backends read it back from row: with its
+// type and category normalized.
+func storedAt(entry WorkoutEntry, row int64) WorkoutEntry {
+	entry.Type = NormalizeWorkoutType(entry.Type)
+	entry.Category = NormalizeCategory(entry.Category)
+	entry.RowIndex = row
+	return entry
+}
+
+// Storage reads and writes the workout log. Dates are YYYY-MM-DD strings
+// (DateLayout); entries come back oldest first. Every method stops early and
+// returns ctx's error once ctx is done.
+//
+// The implementations in this package are safe for concurrent use: one
+// Storage can serve many requests at once, each with its own ctx. Writes
+// from one process don't interleave, but nothing coordinates separate
+// processes sharing a log.
+type Storage interface {
+	// Append adds one entry and returns it as stored, with RowIndex set.
+	Append(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, error)
+	// AppendBatch adds several entries as one write where the backend allows
+	// and returns them as stored, in the order given.
+	AppendBatch(ctx context.Context, entries []WorkoutEntry) ([]WorkoutEntry, error)
+	// Recent returns up to limit of the latest entries.
+	Recent(ctx context.Context, limit int) ([]WorkoutEntry, error)
+	// All returns every entry.
+	All(ctx context.Context) ([]WorkoutEntry, error)
+	// Range returns the entries dated within [since, until]; an empty bound
+	// is open.
+	Range(ctx context.Context, since, until string) ([]WorkoutEntry, error)
+	// SearchByDate returns the entries logged on date.
+	SearchByDate(ctx context.Context, date string) ([]WorkoutEntry, error)
+	// RemoveByDateIndex deletes the index-th entry (from 0) of those
+	// SearchByDate returns for date. It fails with ErrNoData when nothing is
+	// logged on date and ErrInvalidIndex when index is out of range.
+	RemoveByDateIndex(ctx context.Context, date string, index int) error
+	// LastTrainingDay returns the day letter and date of the latest strength
+	// entry, ignoring future-dated ones, or empty strings when there is none.
+	LastTrainingDay(ctx context.Context) (string, string, error)
+}
+
+// Progress reports slow storage operations, e.g. as a terminal spinner.
+type Progress interface {
+	Start(message string)
+	Step(done, total int)
+	Finish()
+}
+
+type noProgress struct{}
+
+func (noProgress) Start(string)  {}
+func (noProgress) Step(int, int) {}
+func (noProgress) Finish()       {}
+
+// NormalizeWorkoutType returns the canonical workout type for a stored value;
+// anything but "interval" is straight sets.
+func NormalizeWorkoutType(value string) string {
+	if strings.EqualFold(strings.TrimSpace(value), TypeInterval) {
+		return TypeInterval
+	}
+	return TypeStraightSets
+}
+
+// NormalizeCategory returns the canonical category for a stored value;
+// anything but "mobility" is strength.
+func NormalizeCategory(value string) string {
+	if strings.EqualFold(strings.TrimSpace(value), CategoryMobility) {
+		return CategoryMobility
+	}
+	return CategoryStrength
+}
+
+// IsMobility reports whether entry is mobility work, which sits outside the
+// A/B/C rotation.
+func IsMobility(entry WorkoutEntry) bool {
+	return NormalizeCategory(entry.Category) == CategoryMobility
+}
+
+// LastStrengthDay returns the day letter and date of the latest strength
+// entry; mobility sessions don't advance the rotation.
+func LastStrengthDay(entries []WorkoutEntry) (string, string) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !IsMobility(entries[i]) {
+			return entries[i].Day, entries[i].Date
+		}
+	}
+	return "", ""
+}
+
+// InRange reports whether date lies within [since, until]; an empty bound is
+// open. Dates compare as YYYY-MM-DD strings.
+func InRange(date, since, until string) bool {
+	return (since == "" || date >= since) && (until == "" || date <= until)
+}
+
+func filterRange(entries []WorkoutEntry, since, until string) []WorkoutEntry {
+	if since == "" && until == "" {
+		return entries
+	}
+	var filtered []WorkoutEntry
+	for _, entry := range entries {
+		if InRange(entry.Date, since, until) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// yearFromDate returns the year of a YYYY-MM-DD date, or the current year
+// when date has none.
+func yearFromDate(date string, now func() time.Time) int {
+	if len(date) >= 4 {
+		if year, err := strconv.Atoi(date[:4]); err == nil {
+			return year
+		}
+	}
+	return now().Year()
+}
@@ -0,0 +1,115 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+// TestLastTrainingDaySkipsMobility checks mobility holds logged after a
+// strength session, even ones carrying a day letter, don't move the A/B/C
+// rotation on.
+func TestLastTrainingDaySkipsMobility(t *testing.T) {
+	hold := WorkoutEntry{Date: "2025-03-14", Day: "C", Exercise: "L-Sit", Level: "Tuck", RepsSets: "30s", Category: CategoryMobility}
+	entries := []WorkoutEntry{withDate(pushups, "2025-03-12"), withDate(squats, "2025-03-13"), hold}
+	entries[1].Day = "B"
+	if day, date := LastStrengthDay(entries); day != "B" || date != "2025-03-13" {
+		t.Errorf("LastStrengthDay = %q, %q, want B of 2025-03-13", day, date)
+	}
+	if day, date := LastStrengthDay([]WorkoutEntry{hold}); day != "" || date != "" {
+		t.Errorf("LastStrengthDay of mobility only = %q, %q, want none", day, date)
+	}
+
+	f := NewFileStorage(t.TempDir())
+	f.Now = func() time.Time { return time.Date(2025, 3, 20, 9, 0, 0, 0, time.UTC) }
+	for _, entry := range entries {
+		if _, err := f.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	day, date, err := f.LastTrainingDay(context.Background())
+	if err != nil || day != "B" || date != "2025-03-13" {
+		t.Errorf("LastTrainingDay = %q, %q, %v, want B of 2025-03-13", day, date, err)
+	}
+}
+
+// slowSheets delays every request to the fake spreadsheet by delay once
+// slow is set, unless the request's context ends first.
+type slowSheets struct {
+	*fakeSheets
+	slow  atomic.Bool
+	delay time.Duration
+}
+
+func (s *slowSheets) RoundTrip(req *http.Request) (*http.Response, error) {
+	if s.slow.Load() {
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(s.delay):
+		}
+	}
+	return s.fakeSheets.RoundTrip(req)
+}
+
+// TestStorageStopsWithContext checks every Storage method of the Sheets
+// backend returns soon after its context ends while a request hangs, with
+// the context's error, and the file backend doesn't start on an ended one.
+func TestStorageStopsWithContext(t *testing.T) {
+	fake := &slowSheets{fakeSheets: newFakeSheets("Log"), delay: time.Minute}
+	fake.setRows("Log", []string{"2026-03-04", "A", "Pushups", "Full", "20x2", "20x2"})
+	sheets, err := NewSheetsStorage(context.Background(), SheetsConfig{
+		SpreadsheetID: fakeSpreadsheetID,
+		ClientOptions: []option.ClientOption{
+			option.WithHTTPClient(&http.Client{Transport: fake}),
+			option.WithEndpoint("https://sheets.test/"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.slow.Store(true)
+
+	calls := map[string]func(ctx context.Context, s Storage) error{
+		"Append": func(ctx context.Context, s Storage) error { _, err := s.Append(ctx, pushups); return err },
+		"AppendBatch": func(ctx context.Context, s Storage) error {
+			_, err := s.AppendBatch(ctx, []WorkoutEntry{pushups})
+			return err
+		},
+		"Recent":            func(ctx context.Context, s Storage) error { _, err := s.Recent(ctx, 5); return err },
+		"All":               func(ctx context.Context, s Storage) error { _, err := s.All(ctx); return err },
+		"Range":             func(ctx context.Context, s Storage) error { _, err := s.Range(ctx, "2026-01-01", ""); return err },
+		"SearchByDate":      func(ctx context.Context, s Storage) error { _, err := s.SearchByDate(ctx, "2026-03-04"); return err },
+		"RemoveByDateIndex": func(ctx context.Context, s Storage) error { return s.RemoveByDateIndex(ctx, "2026-03-04", 0) },
+		"LastTrainingDay":   func(ctx context.Context, s Storage) error { _, _, err := s.LastTrainingDay(ctx); return err },
+	}
+	for name, call := range calls {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		err := call(ctx, sheets)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("sheets %s = %v, want the deadline's error", name, err)
+		}
+		if took := time.Since(start); took > 5*time.Second {
+			t.Errorf("sheets %s returned %v after its deadline", name, took)
+		}
+	}
+
+	file := NewFileStorage(t.TempDir())
+	if _, err := file.Append(context.Background(), pushups); err != nil {
+		t.Fatal(err)
+	}
+	ended, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, call := range calls {
+		if err := call(ended, file); !errors.Is(err, context.Canceled) {
+			t.Errorf("file %s with an ended context = %v, want context.Canceled", name, err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults for CoalescerConfig.
+const (
+	DefaultCoalesceWindow   = 2 * time.Second
+	DefaultCoalesceMaxBatch = 100
+)
+
+// ErrCoalescerClosed is returned by appends to a Coalescer that was closed
+// or whose context ended.
+var ErrCoalescerClosed = errors.New("write coalescer closed")
+
+// CoalescerConfig configures NewCoalescer. Zero values take the defaults.
+type CoalescerConfig struct {
+	// Window is how long the first queued append waits for others to join
+	// its write.
+	Window time.Duration
+	// MaxBatch flushes as soon as this many entries are queued. An append
+	// with more entries than that is written on its own, never split.
+	MaxBatch int
+	// After returns a channel that fires once d has passed. Defaults to
+	// time.After; tests pass a fake clock.
+	After func(d time.Duration) <-chan time.Time
+}
+
+// Coalescer merges appends that arrive close together into a single
+// AppendBatch on the storage it wraps, so a burst of writes from a
+// long-running process stays within the Sheets per-minute write quota. Each
+// caller blocks until the write that carries its entries has finished and
+// gets its own entries back, or the write's error. Reads and removals go
+// straight to the wrapped storage.
+//
+// Only long-running modes need it; a one-shot command writes once and should
+// use the storage directly.
+type Coalescer struct {
+	Storage
+
+	window   time.Duration
+	maxBatch int
+	after    func(time.Duration) <-chan time.Time
+
+	requests  chan coalesceRequest
+	closing   chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+type coalesceRequest struct {
+	entries []WorkoutEntry
+	reply   chan coalesceResult
+}
+
+type coalesceResult struct {
+	stored []WorkoutEntry
+	err    error
+}
+
+// NewCoalescer starts coalescing appends to storage. Queued entries are
+// flushed when the window expires, when MaxBatch is reached, and once more
+// when ctx ends or Close is called; after that, appends fail with
+// ErrCoalescerClosed. The final flush runs without ctx's cancellation so
+// entries already acknowledged as queued are not dropped on shutdown.
+func NewCoalescer(ctx context.Context, storage Storage, cfg CoalescerConfig) *Coalescer {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultCoalesceWindow
+	}
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = DefaultCoalesceMaxBatch
+	}
+	if cfg.After == nil {
+		cfg.After = time.After
+	}
+	c := &Coalescer{
+		Storage:  storage,
+		window:   cfg.Window,
+		maxBatch: cfg.MaxBatch,
+		after:    cfg.After,
+		requests: make(chan coalesceRequest),
+		closing:  make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go c.run(ctx)
+	return c
+}
+
+// Append queues entry and returns it as stored once its write has finished.
+func (c *Coalescer) Append(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, error) {
+	stored, err := c.AppendBatch(ctx, []WorkoutEntry{entry})
+	if err != nil {
+		return WorkoutEntry{}, err
+	}
+	return stored[0], nil
+}
+
+// AppendBatch queues entries, which are always written in the same
+// AppendBatch call on the wrapped storage. If ctx ends while waiting, the
+// entries may still be written.
+func (c *Coalescer) AppendBatch(ctx context.Context, entries []WorkoutEntry) ([]WorkoutEntry, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	req := coalesceRequest{entries: entries, reply: make(chan coalesceResult, 1)}
+	select {
+	case c.requests <- req:
+	case <-c.stopped:
+		return nil, ErrCoalescerClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case res := <-req.reply:
+		return res.stored, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close flushes what is queued and stops the coalescer. It waits for the
+// flush and is safe to call more than once.
+func (c *Coalescer) Close() {
+	c.closeOnce.Do(func() { close(c.closing) })
+	<-c.stopped
+}
+
+func (c *Coalescer) run(ctx context.Context) {
+	defer close(c.stopped)
+	flushCtx := context.WithoutCancel(ctx)
+
+	var pending []coalesceRequest
+	queued := 0
+	var expired <-chan time.Time
+	flush := func() {
+		if len(pending) > 0 {
+			c.write(flushCtx, pending, queued)
+		}
+		pending, queued, expired = nil, 0, nil
+	}
+
+	for {
+		select {
+		case req := <-c.requests:
+			if queued > 0 && queued+len(req.entries) > c.maxBatch {
+				flush()
+			}
+			pending = append(pending, req)
+			queued += len(req.entries)
+			if queued >= c.maxBatch {
+				flush()
+			} else if expired == nil {
+				expired = c.after(c.window)
+			}
+		case <-expired:
+			flush()
+		case <-c.closing:
+			flush()
+			return
+		case <-ctx.Done():
+			flush()
+			return
+		}
+	}
+}
+
+// write appends the entries of every pending request in one call and hands
+// each caller its slice of the result.
+func (c *Coalescer) write(ctx context.Context, pending []coalesceRequest, queued int) {
+	entries := make([]WorkoutEntry, 0, queued)
+	for _, req := range pending {
+		entries = append(entries, req.entries...)
+	}
+	stored, err := c.Storage.AppendBatch(ctx, entries)
+	if err == nil && len(stored) != len(entries) {
+		err = errors.New("storage returned a different number of entries than were appended")
+	}
+	offset := 0
+	for _, req := range pending {
+		if err != nil {
+			req.reply <- coalesceResult{err: err}
+			continue
+		}
+		n := len(req.entries)
+		req.reply <- coalesceResult{stored: stored[offset : offset+n : offset+n]}
+		offset += n
+	}
+}
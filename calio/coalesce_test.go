@@ -0,0 +1,282 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock stands in for time.After: armed receives each window started,
+// and a send on fire ends it. fire is unbuffered, so the coalescer has
+// handled every request sent before it when it takes the tick.
+type fakeClock struct {
+	armed chan time.Duration
+	fire  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{armed: make(chan time.Duration, 10), fire: make(chan time.Time)}
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.armed <- d
+	return c.fire
+}
+
+// batchStorage records the AppendBatch calls that reach it and numbers the
+// rows it stores. err, when set, fails every call.
+type batchStorage struct {
+	Storage
+	mu      sync.Mutex
+	batches [][]WorkoutEntry
+	err     error
+	ctxErrs []error
+}
+
+func (s *batchStorage) AppendBatch(ctx context.Context, entries []WorkoutEntry) ([]WorkoutEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, slices.Clone(entries))
+	s.ctxErrs = append(s.ctxErrs, ctx.Err())
+	if s.err != nil {
+		return nil, s.err
+	}
+	stored := slices.Clone(entries)
+	for i := range stored {
+		stored[i].RowIndex = int64(i + 1)
+	}
+	return stored, nil
+}
+
+// sizes returns the number of entries of each batch written so far.
+func (s *batchStorage) sizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sizes []int
+	for _, batch := range s.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+// queue hands entries named after exercises to the coalescer and returns
+// where the result will come. The send returns once the coalescer took the
+// request, so requests queued one after another arrive in order.
+func queue(c *Coalescer, exercises ...string) chan coalesceResult {
+	req := coalesceRequest{reply: make(chan coalesceResult, 1)}
+	for _, exercise := range exercises {
+		req.entries = append(req.entries, WorkoutEntry{Date: "2026-03-04", Exercise: exercise})
+	}
+	c.requests <- req
+	return req.reply
+}
+
+// stored returns the exercises and rows of a result, or its error.
+func stored(res coalesceResult) string {
+	if res.err != nil {
+		return res.err.Error()
+	}
+	var out []string
+	for _, entry := range res.stored {
+		out = append(out, fmt.Sprintf("%s@%d", entry.Exercise, entry.RowIndex))
+	}
+	return fmt.Sprint(out)
+}
+
+// TestCoalescerWindow merges the appends of one window into one write and
+// starts a new window with the next append.
+func TestCoalescerWindow(t *testing.T) {
+	storage, clock := &batchStorage{}, newFakeClock()
+	c := NewCoalescer(context.Background(), storage, CoalescerConfig{Window: 5 * time.Second, After: clock.After})
+	defer c.Close()
+
+	first := queue(c, "Pushups")
+	if d := <-clock.armed; d != 5*time.Second {
+		t.Errorf("the window is %v, want 5s", d)
+	}
+	second := queue(c, "Squats", "Pullups")
+	third := queue(c, "Bridges")
+	if len(clock.armed) != 0 || len(storage.sizes()) != 0 {
+		t.Fatalf("before the window ended: %d more window(s), writes %v", len(clock.armed), storage.sizes())
+	}
+
+	clock.fire <- time.Time{}
+	if got := stored(<-first) + stored(<-second) + stored(<-third); got != "[Pushups@1][Squats@2 Pullups@3][Bridges@4]" {
+		t.Errorf("the callers got back %s", got)
+	}
+	if sizes := storage.sizes(); !slices.Equal(sizes, []int{4}) {
+		t.Errorf("the writes were %v, want one of 4", sizes)
+	}
+
+	fourth := queue(c, "Leg Raises")
+	<-clock.armed
+	clock.fire <- time.Time{}
+	if got := stored(<-fourth); got != "[Leg Raises@1]" {
+		t.Errorf("the next window's caller got back %s", got)
+	}
+	if sizes := storage.sizes(); !slices.Equal(sizes, []int{4, 1}) {
+		t.Errorf("the writes were %v, want 4 then 1", sizes)
+	}
+}
+
+// TestCoalescerMaxBatch flushes as soon as a batch is full, writes an append
+// that wouldn't fit with what is queued in the next batch and never splits
+// one that is larger than a batch.
+func TestCoalescerMaxBatch(t *testing.T) {
+	storage, clock := &batchStorage{}, newFakeClock()
+	c := NewCoalescer(context.Background(), storage, CoalescerConfig{MaxBatch: 3, After: clock.After})
+	defer c.Close()
+
+	first := queue(c, "Pushups")
+	if d := <-clock.armed; d != DefaultCoalesceWindow {
+		t.Errorf("the default window is %v", d)
+	}
+	second := queue(c, "Squats")
+	third := queue(c, "Pullups", "Bridges")
+	if got := stored(<-first) + stored(<-second); got != "[Pushups@1][Squats@2]" {
+		t.Errorf("the callers before the one that didn't fit got back %s", got)
+	}
+	<-clock.armed // the window of the append that didn't fit
+	fourth := queue(c, "Leg Raises")
+	if got := stored(<-third) + stored(<-fourth); got != "[Pullups@1 Bridges@2][Leg Raises@3]" {
+		t.Errorf("the callers of the full batch got back %s", got)
+	}
+
+	large := queue(c, "A", "B", "C", "D", "E")
+	if got := stored(<-large); got != "[A@1 B@2 C@3 D@4 E@5]" {
+		t.Errorf("the large append got back %s", got)
+	}
+	if sizes := storage.sizes(); !slices.Equal(sizes, []int{2, 3, 5}) {
+		t.Errorf("the writes were %v, want 2, 3 and 5", sizes)
+	}
+	if len(clock.armed) != 0 {
+		t.Errorf("a full batch started a window")
+	}
+}
+
+// TestCoalescerError hands a failed write's error to every caller in it.
+func TestCoalescerError(t *testing.T) {
+	storage, clock := &batchStorage{err: errors.New("quota exceeded")}, newFakeClock()
+	c := NewCoalescer(context.Background(), storage, CoalescerConfig{After: clock.After})
+	defer c.Close()
+
+	replies := []chan coalesceResult{queue(c, "Pushups"), queue(c, "Squats", "Pullups"), queue(c, "Bridges")}
+	<-clock.armed
+	clock.fire <- time.Time{}
+	for i, reply := range replies {
+		if res := <-reply; res.err != storage.err || res.stored != nil {
+			t.Errorf("caller %d got %+v, want the write's error", i+1, res)
+		}
+	}
+
+	// The next window writes again.
+	storage.mu.Lock()
+	storage.err = nil
+	storage.mu.Unlock()
+	reply := queue(c, "Leg Raises")
+	<-clock.armed
+	clock.fire <- time.Time{}
+	if got := stored(<-reply); got != "[Leg Raises@1]" {
+		t.Errorf("after a failed write the next caller got back %s", got)
+	}
+}
+
+// TestCoalescerClose flushes what is queued on Close without waiting for the
+// window, and refuses appends afterwards.
+func TestCoalescerClose(t *testing.T) {
+	storage, clock := &batchStorage{}, newFakeClock()
+	c := NewCoalescer(context.Background(), storage, CoalescerConfig{After: clock.After})
+	first, second := queue(c, "Pushups"), queue(c, "Squats")
+	c.Close()
+	if got := stored(<-first) + stored(<-second); got != "[Pushups@1][Squats@2]" {
+		t.Errorf("the callers queued at Close got back %s", got)
+	}
+	c.Close()
+	if _, err := c.Append(context.Background(), pushups); !errors.Is(err, ErrCoalescerClosed) {
+		t.Errorf("Append after Close = %v, want ErrCoalescerClosed", err)
+	}
+	if sizes := storage.sizes(); !slices.Equal(sizes, []int{2}) {
+		t.Errorf("the writes were %v, want the one at Close", sizes)
+	}
+}
+
+// TestCoalescerCancel flushes when the coalescer's context ends, with a
+// context that isn't cancelled so the write goes through.
+func TestCoalescerCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	storage, clock := &batchStorage{}, newFakeClock()
+	c := NewCoalescer(ctx, storage, CoalescerConfig{After: clock.After})
+	reply := queue(c, "Pushups")
+	cancel()
+	if got := stored(<-reply); got != "[Pushups@1]" {
+		t.Errorf("the caller queued at cancellation got back %s", got)
+	}
+	<-c.stopped
+	if storage.ctxErrs[0] != nil {
+		t.Errorf("the final write ran with a context that ended: %v", storage.ctxErrs[0])
+	}
+	if _, err := c.AppendBatch(context.Background(), []WorkoutEntry{pushups}); !errors.Is(err, ErrCoalescerClosed) {
+		t.Errorf("AppendBatch after cancellation = %v, want ErrCoalescerClosed", err)
+	}
+	c.Close()
+}
+
+// TestCoalescerConcurrentAppends goes through Append from many goroutines at
+// once; each gets back its own entry from the one write.
+func TestCoalescerConcurrentAppends(t *testing.T) {
+	const callers = 20
+	storage := &batchStorage{}
+	c := NewCoalescer(context.Background(), storage, CoalescerConfig{MaxBatch: callers, After: newFakeClock().After})
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry := WorkoutEntry{Date: "2026-03-04", Exercise: fmt.Sprint("Exercise ", i)}
+			got, err := c.Append(context.Background(), entry)
+			if err == nil && got.Exercise != entry.Exercise {
+				err = fmt.Errorf("appended %s, got back %s", entry.Exercise, got.Exercise)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if sizes := storage.sizes(); !slices.Equal(sizes, []int{callers}) {
+		t.Errorf("the writes were %v, want one of %d", sizes, callers)
+	}
+}
+
+// TestCoalescerCallerGivesUp returns the caller's context error while the
+// entry waits for its window; the entry is still written.
+func TestCoalescerCallerGivesUp(t *testing.T) {
+	storage, clock := &batchStorage{}, newFakeClock()
+	c := NewCoalescer(context.Background(), storage, CoalescerConfig{After: clock.After})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := c.Append(ctx, pushups)
+		done <- err
+	}()
+	<-clock.armed
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Append whose context ended = %v", err)
+	}
+	c.Close()
+	if sizes := storage.sizes(); !slices.Equal(sizes, []int{1}) {
+		t.Errorf("the writes were %v, want the abandoned entry written", sizes)
+	}
+}
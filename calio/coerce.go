@@ -0,0 +1,121 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// A spreadsheet reads what is typed into a cell as a number, date or time
+// when it can, by its locale: "10-30" becomes October 30th, "1:30" half past
+// one and "8.7" the 8th of July. Read back, the cell holds its display,
+// "10/30/2026", "1:30:00 AM" or "08.07.2026", or, once formatted as plain
+// text, the serial number behind it. cali writes its cells as text, and
+// formats the RepsxSets, Goal and Load columns as plain text (see
+// textColumnRequests), but cells typed or pasted in by hand before that can
+// already be mangled. CoercedCells finds them for cali doctor.
+
+// monthNames matches the start of a month's name in English or German.
+const monthNames = `(jan|feb|mar|mär|mrz|apr|may|mai|jun|jul|aug|sep|oct|okt|nov|dec|dez)`
+
+var (
+	// coercedSerial is a whole date serial from 1927 to 2173: nobody does
+	// 10000 reps.
+	coercedSerial = regexp.MustCompile(`^\d{5}$`)
+	// coercedFraction is a time of day as a fraction of a day, e.g. 0.0625
+	// for 1:30. A decimal comma only counts after 0, as "8,7" lists sets.
+	coercedFraction = regexp.MustCompile(`^(\d+\.\d+|0,\d+)$`)
+	// coercedTime is a time as sheets display it, "1:30:00" or "1:30 AM".
+	// "1:30" alone is a hold.
+	coercedTime = regexp.MustCompile(`(?i)^(\d{1,3}:\d{2}:\d{2}(\.\d+)?(\s*[ap]\.?m\.?)?|\d{1,2}:\d{2}\s*[ap]\.?m\.?)$`)
+	// coercedDate is a full date, with the year sheets add, and maybe a
+	// time: "10/30/2026", "30.10.2026", "2026-10-30 1:30:00". Lists such as
+	// "8/7/6" have no four-digit part.
+	coercedDate = regexp.MustCompile(`^(\d{1,2}[/.-]\d{1,2}[/.-]\d{4}|\d{4}[/.-]\d{1,2}[/.-]\d{1,2})(\s+\d{1,2}:\d{2}(:\d{2})?)?$`)
+	// coercedMonth is a date shown with its month's name, in English or
+	// German, and nothing else: "30-Oct", "Oct 30", "30. Okt. 2026".
+	coercedMonth = regexp.MustCompile(`(?i)^[\d\s.,/-]*\d[\d\s.,/-]*` + monthNames + `[a-zä]*\.?[\d\s.,/-]*$|` +
+		`^` + monthNames + `[a-zä]*\.?[\s.,/-]*\d[\d\s.,/-]*$`)
+)
+
+// coercedWhen reports whether value looks like a date or time a sheet made
+// of what was typed, as a serial or as it displays one.
+func coercedWhen(value string) bool {
+	return coercedSerial.MatchString(value) || coercedTime.MatchString(value) ||
+		coercedDate.MatchString(value) || coercedMonth.MatchString(value)
+}
+
+// CoercedWork reports whether value, a RepsxSets or Goal cell, is a number,
+// date or time the sheet made of the work typed into it rather than the
+// work itself. Every form cali reads as work, such as "10-30x2", "8/7/6",
+// "1:30" or "EMOM 10min @ 12", is left alone.
+func CoercedWork(value string) bool {
+	value = strings.TrimSpace(value)
+	return coercedWhen(value) || coercedFraction.MatchString(value)
+}
+
+// CoercedLoad reports whether value, a Load cell, is a date or time the
+// sheet made of the load typed into it. Decimals are loads: "22.5".
+func CoercedLoad(value string) bool {
+	return coercedWhen(strings.TrimSpace(value))
+}
+
+// CoercedCells describes the RepsxSets, Goal and Load cells of entry that
+// CoercedWork or CoercedLoad reject, e.g. `RepsxSets "10/30/2026"`.
+func CoercedCells(entry WorkoutEntry) []string {
+	var cells []string
+	if CoercedWork(entry.RepsSets) {
+		cells = append(cells, fmt.Sprintf("%s %q", sheetHeader[fieldRepsSets], entry.RepsSets))
+	}
+	if CoercedWork(entry.Goal) {
+		cells = append(cells, fmt.Sprintf("%s %q", sheetHeader[fieldGoal], entry.Goal))
+	}
+	if CoercedLoad(entry.Load) {
+		cells = append(cells, fmt.Sprintf("%s %q", loadHeader, entry.Load))
+	}
+	return cells
+}
+
+// textFields are the columns formatted as plain text, so what is typed into
+// them stays as typed.
+var textFields = []int{fieldRepsSets, fieldGoal, fieldLoad}
+
+// textColumnRequests format the textFields columns of a tab laid out as
+// layout as plain text below the header. Cells already turned into dates
+// then show their serial number, which CoercedWork still recognizes.
+func textColumnRequests(sheetID int64, layout columnLayout) []*sheets.Request {
+	requests := make([]*sheets.Request, len(textFields))
+	for i, field := range textFields {
+		column := int64(layout[field])
+		requests[i] = &sheets.Request{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Range: logColumns(sheetID, column, column+1),
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{NumberFormat: &sheets.NumberFormat{Type: "TEXT"}},
+				},
+				Fields: "userEnteredFormat.numberFormat",
+			},
+		}
+	}
+	return requests
+}
+
+// keepTyped formats the work columns of tab as plain text the first time
+// this process writes to it (see textColumnRequests), so what is typed there
+// by hand later stays as typed. The rows are written already, so a failure
+// is only logged.
+func (s *SheetsStorage) keepTyped(ctx context.Context, tab string, layout columnLayout) {
+	sheetID, ok := s.meta.tab(tab)
+	if !ok || !s.meta.markTexted(tab) {
+		return
+	}
+	_, err := s.svc.Spreadsheets.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: textColumnRequests(sheetID, layout),
+	}).Context(ctx).Do()
+	if err != nil {
+		s.logf("Couldn't format the work columns of %q as plain text: %v\n", tab, err)
+	}
+}
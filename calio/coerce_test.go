@@ -0,0 +1,138 @@
+package calio
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// mangledWork are RepsxSets and Goal cells as sheets show them after turning
+// what was typed into a date or time, by locale, with what was typed.
+var mangledWork = []string{
+	"46325",              // 10-30 as a date serial, once the column is text
+	"45931",              // 2025-10-01
+	"0.0625",             // 1:30 as a fraction of a day
+	"0,0625",             // the same with a decimal comma
+	"0.5",                // 12:00
+	"1:30:00",            // 1:30 as a duration
+	"1:30:00 AM",         // 1:30 as a time of day
+	"01:30:00.000",       // with milliseconds
+	"1:30 PM",            // 13:30
+	"1:30 a.m.",          // 1:30, with dots
+	"10/30/2026",         // 10-30 in the US
+	"30.10.2026",         // 10-30 in Germany
+	"30/10/2026",         // 10-30 in the UK
+	"2026-10-30",         // 10-30, ISO
+	"2026/10/30",         // 10-30, Japan
+	"2026-10-30 1:30:00", // a date and time
+	"30-Oct",             // 10-30 shown as a month
+	"Oct-30",             // the other way round
+	"Oct 30",             //
+	"30 Oct 2026",        //
+	"30. Okt. 2026",      // 10-30 in German
+	"8. Mär",             // 8.3 in German
+	"Mai 8",              //
+	"8-Jul",              // 8-7
+	"Sep-2026",           // 9-2026
+	" 46325 ",            // padded
+}
+
+// legitWork are forms cali reads as work that look close to a date or time.
+var legitWork = []string{
+	"", "8x2", "8 x 2", "10-30x2", "10-30", "8-12x3", "12/10/10", "8/7/6", "8,7", "8,7,6",
+	"10", "100", "1000", "1:30", "01:30", "90s", "1min", "1m30s", "45s x3", "45s,40s",
+	"EMOM 10min @ 12", "tabata4min@8", "amrap12min@15x6", "3x20s", "12.10.10", "30x2",
+}
+
+func TestCoercedWork(t *testing.T) {
+	for _, value := range mangledWork {
+		if !CoercedWork(value) {
+			t.Errorf("CoercedWork(%q) = false, want true", value)
+		}
+	}
+	for _, value := range legitWork {
+		if CoercedWork(value) {
+			t.Errorf("CoercedWork(%q) = true, want false", value)
+		}
+	}
+}
+
+func TestCoercedLoad(t *testing.T) {
+	for _, value := range []string{"10/1/2026", "2026-01-10", "10-Jan", "Jan 10", "46032", "1:30:00 AM"} {
+		if !CoercedLoad(value) {
+			t.Errorf("CoercedLoad(%q) = false, want true", value)
+		}
+	}
+	for _, value := range []string{"", "+10kg", "10kg", "-5kg", "22.5", "22,5kg", "10", "+20lb", "0.5"} {
+		if CoercedLoad(value) {
+			t.Errorf("CoercedLoad(%q) = true, want false", value)
+		}
+	}
+}
+
+func TestCoercedCells(t *testing.T) {
+	entry := WorkoutEntry{Date: "2026-10-30", Exercise: "Pushups", Level: "Full", RepsSets: "10/30/2026", Goal: "20x2", Load: "Jan-10"}
+	want := []string{`RepsxSets "10/30/2026"`, `Load "Jan-10"`}
+	if got := CoercedCells(entry); !slices.Equal(got, want) {
+		t.Errorf("CoercedCells = %q, want %q", got, want)
+	}
+	entry.Goal = "0.0625"
+	if got := CoercedCells(entry); len(got) != 3 || got[1] != `Goal "0.0625"` {
+		t.Errorf("with a coerced goal, CoercedCells = %q", got)
+	}
+	if got := CoercedCells(pushups); got != nil {
+		t.Errorf("CoercedCells of a good entry = %q", got)
+	}
+}
+
+func TestTextColumnRequests(t *testing.T) {
+	layout := standardLayout
+	layout[fieldRepsSets], layout[fieldGoal], layout[fieldLoad] = 7, 2, 11
+	requests := textColumnRequests(5, layout)
+	var columns []int64
+	for _, request := range requests {
+		repeat := request.RepeatCell
+		if repeat == nil || repeat.Range.SheetId != 5 || repeat.Range.EndColumnIndex != repeat.Range.StartColumnIndex+1 ||
+			repeat.Range.StartRowIndex != 1 || repeat.Cell.UserEnteredFormat.NumberFormat.Type != "TEXT" ||
+			repeat.Fields != "userEnteredFormat.numberFormat" {
+			t.Fatalf("request %+v doesn't format one column below the header as text", request)
+		}
+		columns = append(columns, repeat.Range.StartColumnIndex)
+	}
+	if !slices.Equal(columns, []int64{7, 2, 11}) {
+		t.Errorf("formatted columns %v, want 7, 2 and 11", columns)
+	}
+}
+
+// TestSheetsKeepTyped checks the first append of a storage to a tab formats
+// its work columns as text, where its header puts them, and later appends
+// don't.
+func TestSheetsKeepTyped(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSheets(DefaultSheetName)
+	fake.setRows(DefaultSheetName, []string{"Date", "Exercise", "Level", "Day", "RepsxSets", "Goal", "Comment", "Duration", "Type", "Category", "Load"})
+	s := fake.mustStorage(t, SheetsConfig{})
+	for _, entry := range []WorkoutEntry{pushups, squats} {
+		if _, err := s.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := fake.tab(DefaultSheetName).text; !slices.Equal(got, []int64{4, 5, 10}) {
+		t.Errorf("text columns %v, want RepsxSets, Goal and Load (4, 5 and 10)", got)
+	}
+	if fake.calls["POST batchUpdate"] != 1 {
+		t.Errorf("two appends made %d batchUpdates, want 1", fake.calls["POST batchUpdate"])
+	}
+
+	// A per-year tab created on the way gets cali's own layout.
+	perYear := newFakeSheets()
+	y := perYear.mustStorage(t, SheetsConfig{PerYear: true})
+	if _, err := y.AppendBatch(ctx, []WorkoutEntry{pushups, withDate(squats, "2025-12-30")}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tab := range perYear.tabs {
+		if !slices.Equal(tab.text, []int64{fieldRepsSets, fieldGoal, fieldLoad}) {
+			t.Errorf("%s: text columns %v", tab.title, tab.text)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Fields of a log row, numbered by the column cali puts them in: A to I,
+// then the optional % of goal (J), the user (K), the schema marker (L), the
+// session minutes (M), the load (N) and the time logged (O).
+const (
+	fieldDate = iota
+	fieldDay
+	fieldExercise
+	fieldLevel
+	fieldRepsSets
+	fieldGoal
+	fieldComment
+	fieldType
+	fieldCategory
+	fieldPercent
+	fieldUser
+	fieldSchema
+	fieldDuration
+	fieldLoad
+	fieldLogged
+	fieldCount
+)
+
+// columnLayout holds the 0-based column of each field of a tab. Columns
+// moved around in the browser keep their header, so a tab with a header
+// row is mapped by header name; other tabs are read by position.
+type columnLayout [fieldCount]int
+
+// standardLayout is the column order cali writes.
+var standardLayout = func() columnLayout {
+	var layout columnLayout
+	for field := range layout {
+		layout[field] = field
+	}
+	return layout
+}()
+
+// width is the number of columns a row needs to hold every field.
+func (l columnLayout) width() int {
+	width := 0
+	for _, column := range l {
+		width = max(width, column+1)
+	}
+	return width
+}
+
+// lastColumn is the letter of the rightmost column of the layout.
+func (l columnLayout) lastColumn() string {
+	return columnName(l.width() - 1)
+}
+
+// columnName returns the A1 letters of a 0-based column: A, ..., Z, AA, ...
+func columnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name
+}
+
+// headerNames maps normalized headings, including the synonyms people
+// retype them as, to fields.
+var headerNames = map[string]int{
+	"date":          fieldDate,
+	"day":           fieldDay,
+	"exercise":      fieldExercise,
+	"level":         fieldLevel,
+	"variation":     fieldLevel,
+	"step":          fieldLevel,
+	"repsxsets":     fieldRepsSets,
+	"reps":          fieldRepsSets,
+	"repssets":      fieldRepsSets,
+	"reps/sets":     fieldRepsSets,
+	"repsandsets":   fieldRepsSets,
+	"goal":          fieldGoal,
+	"target":        fieldGoal,
+	"comment":       fieldComment,
+	"comments":      fieldComment,
+	"note":          fieldComment,
+	"notes":         fieldComment,
+	"type":          fieldType,
+	"category":      fieldCategory,
+	"%ofgoal":       fieldPercent,
+	"percentofgoal": fieldPercent,
+	"user":          fieldUser,
+	"schema":        fieldSchema,
+	"minutes":       fieldDuration,
+	"duration":      fieldDuration,
+	"load":          fieldLoad,
+	"addedload":     fieldLoad,
+	"addedweight":   fieldLoad,
+	"weight":        fieldLoad,
+	"logged":        fieldLogged,
+	"loggedat":      fieldLogged,
+	"timestamp":     fieldLogged,
+}
+
+// LogColumns returns the headings of columns A to I, the columns every cali
+// log has, in order.
+func LogColumns() []string {
+	columns := make([]string, len(sheetHeader))
+	for i, heading := range sheetHeader {
+		columns[i] = heading.(string)
+	}
+	return columns
+}
+
+// LogRow returns the values of entry in the columns of LogColumns, as the
+// Sheets backend writes them but with the comment uncut.
+func LogRow(entry WorkoutEntry) []string {
+	return []string{
+		entry.Date, entry.Day, entry.Exercise, entry.Level, entry.RepsSets, entry.Goal, entry.Comment,
+		NormalizeWorkoutType(entry.Type), NormalizeCategory(entry.Category),
+	}
+}
+
+// normalizeHeading folds case, spacing and the × sign, so "Reps × Sets",
+// "RepsxSets" and "reps x sets" read alike.
+func normalizeHeading(heading string) string {
+	heading = strings.ToLower(strings.ReplaceAll(heading, "×", "x"))
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(heading)
+}
+
+// headingField returns the field a heading names.
+func headingField(heading string) (int, bool) {
+	field, ok := headerNames[normalizeHeading(heading)]
+	return field, ok
+}
+
+// minHeaderFields is how many known headings, Date among them, make the
+// first row a header.
+const minHeaderFields = 3
+
+// detectLayout maps the columns of a tab whose first row is row. A header
+// needs a Date heading and at least minHeaderFields known ones; without
+// it, or when it is too ambiguous to trust, the tab is read by position.
+// A field without a heading keeps its standard column if that column has no
+// heading either, as in tabs cali created before a column existed, and is
+// otherwise placed past the last heading and column O. warnings describe
+// what cali couldn't map cleanly, for cali doctor.
+func detectLayout(row []interface{}) (layout columnLayout, warnings []string) {
+	found := map[int]int{} // field -> column
+	headed := map[int]bool{}
+	for column := range row {
+		heading := strings.TrimSpace(valueAt(row, column))
+		if heading == "" {
+			continue
+		}
+		headed[column] = true
+		field, ok := headingField(heading)
+		if !ok {
+			continue
+		}
+		if first, seen := found[field]; seen {
+			warnings = append(warnings, fmt.Sprintf("columns %s and %s are both headed like %q; using %s",
+				columnName(first), columnName(column), heading, columnName(first)))
+			continue
+		}
+		found[field] = column
+	}
+
+	if _, ok := found[fieldDate]; !ok || len(found) < minHeaderFields {
+		if len(found) >= 2 {
+			warnings = append(warnings, fmt.Sprintf("row 1 names %d log column(s) but isn't a full header (it needs Date and %d known headings); reading by position",
+				len(found), minHeaderFields))
+		}
+		return standardLayout, warnings
+	}
+
+	// Fields placed by cali go past every heading and every standard
+	// column, so they can't land on another field.
+	next := max(len(row), fieldCount)
+	for field := range layout {
+		if column, ok := found[field]; ok {
+			layout[field] = column
+			continue
+		}
+		if !headed[field] {
+			layout[field] = field
+			continue
+		}
+		layout[field] = next
+		if field <= fieldCategory {
+			warnings = append(warnings, fmt.Sprintf("no column is headed %q and column %s holds something else; new rows put it in column %s",
+				sheetHeader[field], columnName(field), columnName(next)))
+		}
+		next++
+	}
+	return layout, warnings
+}
+
+// tabLayout is the detected layout of a tab and what was odd about it.
+type tabLayout struct {
+	columns  columnLayout
+	warnings []string
+}
+
+// learnLayout records the layout of tab from its first row, unless known.
+func (s *SheetsStorage) learnLayout(tab string, first []interface{}) columnLayout {
+	if known, ok := s.meta.layout(tab); ok {
+		return known.columns
+	}
+	columns, warnings := detectLayout(first)
+	layout, learned := s.meta.learn(tab, tabLayout{columns: columns, warnings: warnings})
+	if learned && columns != standardLayout {
+		s.logf("Columns of %q mapped by header: %v\n", tab, describeLayout(columns))
+	}
+	return layout.columns
+}
+
+// layoutFor returns the layout of tab, reading its first row unless a read
+// of the tab's top already did.
+func (s *SheetsStorage) layoutFor(ctx context.Context, tab string) (columnLayout, error) {
+	if known, ok := s.meta.layout(tab); ok {
+		return known.columns, nil
+	}
+	if _, ok := s.meta.tab(tab); !ok {
+		return standardLayout, nil
+	}
+	resp, err := s.svc.Spreadsheets.Values.Get(s.spreadsheetID, a1Range(tab, "1:1")).Context(ctx).Do()
+	if err != nil {
+		return columnLayout{}, fmt.Errorf("reading the header of %q: %w", tab, err)
+	}
+	return s.learnLayout(tab, firstValues(resp.Values)), nil
+}
+
+// describeLayout lists the fields that left their standard column, e.g.
+// "Goal→G, Comment→F".
+func describeLayout(layout columnLayout) string {
+	var moved []string
+	for field, column := range layout {
+		if field != column && field <= fieldCategory {
+			moved = append(moved, sheetHeader[field].(string)+"→"+columnName(column))
+		}
+	}
+	return strings.Join(moved, ", ")
+}
+
+// ColumnChecker is implemented by backends whose columns can be rearranged
+// by hand, as in a spreadsheet.
+type ColumnChecker interface {
+	// CheckColumns maps the columns of every log tab and describes those it
+	// couldn't map cleanly: duplicated headings, a partial header, or a
+	// field whose column holds something else. moved lists the tabs read by
+	// header name with their moved columns.
+	CheckColumns(ctx context.Context) (moved, warnings []string, err error)
+}
+
+// ErrNoColumnChecker is returned when the storage has no columns to check.
+var ErrNoColumnChecker = errors.New("this storage has no columns to check")
+
+// CheckColumns reads the header row of every log tab.
+func (s *SheetsStorage) CheckColumns(ctx context.Context) (moved, warnings []string, err error) {
+	for _, tab := range s.readTabsFor("", "") {
+		if _, ok := s.meta.tab(tab); !ok {
+			continue
+		}
+		columns, err := s.layoutFor(ctx, tab)
+		if err != nil {
+			return nil, nil, err
+		}
+		if columns != standardLayout {
+			moved = append(moved, fmt.Sprintf("%s: %s", tab, describeLayout(columns)))
+		}
+		layout, _ := s.meta.layout(tab)
+		for _, warning := range layout.warnings {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", tab, warning))
+		}
+	}
+	return moved, warnings, nil
+}
+
+// CheckColumns forwards to the underlying storage.
+func (u *UserStorage) CheckColumns(ctx context.Context) (moved, warnings []string, err error) {
+	checker, ok := u.Storage.(ColumnChecker)
+	if !ok {
+		return nil, nil, ErrNoColumnChecker
+	}
+	return checker.CheckColumns(ctx)
+}
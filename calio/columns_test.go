@@ -0,0 +1,153 @@
+package calio
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestColumnName(t *testing.T) {
+	for column, want := range map[int]string{0: "A", 5: "F", 14: "O", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(column); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", column, got, want)
+		}
+	}
+}
+
+// header turns headings into a first row as the API returns it.
+func header(headings ...string) []interface{} {
+	return cells(headings)
+}
+
+// layoutOf lists where layout puts the first count fields, as column
+// letters in field order.
+func layoutOf(layout columnLayout, count int) string {
+	var columns []string
+	for _, column := range layout[:count] {
+		columns = append(columns, columnName(column))
+	}
+	return strings.Join(columns, " ")
+}
+
+func TestDetectLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		row      []interface{}
+		want     string // the columns of Date to Category
+		warnings []string
+	}{
+		{"no header", header("2026-03-04", "A", "Pushups", "Full", "20x2", "20x2", ""),
+			"A B C D E F G H I", nil},
+		{"cali's header", header("Date", "Day", "Exercise", "Level", "RepsxSets", "Goal", "Comment", "Type", "Category"),
+			"A B C D E F G H I", nil},
+		{"comment before goal", header("Date", "Day", "Exercise", "Level", "RepsxSets", "Comment", "Goal", "Type", "Category"),
+			"A B C D E G F H I", nil},
+		{"synonyms, cased and spaced", header("DATE", "Variation", "exercise", "Reps × Sets", "Target", "Notes", "day"),
+			"A G C B D E F H I", nil},
+		{"more synonyms", header("Exercise", "Step", "Reps", "Date", "Comments"),
+			"D P A B C F E H I", []string{`no column is headed "Day" and column B holds something else; new rows put it in column P`}},
+		{"extra columns", header("Date", "Coach", "Day", "Exercise", "Level", "Reps/Sets", "Goal", "Comment", "Type", "Category", "RPE"),
+			"A C D E F G H I J", nil},
+		{"fields missing from an old header", header("Date", "Day", "Exercise", "Level", "RepsxSets", "Goal", "Comment"),
+			"A B C D E F G H I", nil},
+		{"duplicate heading", header("Date", "Day", "Exercise", "Level", "Reps", "RepsxSets", "Goal", "Comment"),
+			"A B C D E G H P I", []string{
+				`columns E and F are both headed like "RepsxSets"; using E`,
+				`no column is headed "Type" and column H holds something else; new rows put it in column P`,
+			}},
+		{"partial header", header("Date", "Notes", "", "Whatever"),
+			"A B C D E F G H I", []string{"row 1 names 2 log column(s) but isn't a full header (it needs Date and 3 known headings); reading by position"}},
+		{"headings without Date", header("Day", "Exercise", "Level", "Reps"),
+			"A B C D E F G H I", []string{"row 1 names 4 log column(s) but isn't a full header (it needs Date and 3 known headings); reading by position"}},
+		{"empty row", nil, "A B C D E F G H I", nil},
+	}
+	for _, tt := range tests {
+		layout, warnings := detectLayout(tt.row)
+		if got := layoutOf(layout, fieldCategory+1); got != tt.want {
+			t.Errorf("%s: Date to Category go in %s, want %s", tt.name, got, tt.want)
+		}
+		if !slices.Equal(warnings, tt.warnings) {
+			t.Errorf("%s: warnings %q, want %q", tt.name, warnings, tt.warnings)
+		}
+		seen := map[int]bool{}
+		for field, column := range layout {
+			if seen[column] {
+				t.Errorf("%s: field %d shares column %s", tt.name, field, columnName(column))
+			}
+			seen[column] = true
+		}
+	}
+}
+
+// reorderedSheet is a log whose partner moved Comment before Goal and added
+// a column of their own.
+func reorderedSheet(t *testing.T) (*fakeSheets, *SheetsStorage) {
+	f := newFakeSheets()
+	f.setRows("Log",
+		[]string{"Date", "Day", "Exercise", "Level", "RepsxSets", "Comment", "Goal", "Coach", "Type", "Category"},
+		[]string{"2026-03-02", "A", "Pushups", "Full", "10x2", "shaky", "20x2", "ok", "straight-sets", "strength"},
+		[]string{"2026-03-03", "A", "Squats", "Half", "35x2", "", "50x2", "more depth", "straight-sets", "strength"},
+	)
+	return f, f.mustStorage(t, SheetsConfig{})
+}
+
+func TestReorderedColumns(t *testing.T) {
+	ctx := context.Background()
+	f, s := reorderedSheet(t)
+	all, err := s.All(ctx)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("All = %+v, %v", all, err)
+	}
+	if all[0].Comment != "shaky" || all[0].Goal != "20x2" || all[1].Comment != "" || all[1].Goal != "50x2" || all[1].Category != CategoryStrength {
+		t.Errorf("the rows read as %+v", all)
+	}
+
+	entry := withDate(pushups, "2026-03-04")
+	entry.Comment = "easier"
+	if _, err := s.Append(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	row := f.rows("Log")[3]
+	if row[5] != "easier" || row[6] != "20x2" || row[7] != "" || row[8] != "straight-sets" || row[9] != "strength" {
+		t.Errorf("the new row is %q, want it in the sheet's order with Coach left empty", row)
+	}
+	if all, err = s.All(ctx); err != nil || all[2].Comment != "easier" || all[2].Goal != "20x2" {
+		t.Errorf("the new row reads as %+v, %v", all[2], err)
+	}
+
+	// Rewriting a goal sets the Goal cell only.
+	if err := s.RewriteGoals(ctx, []GoalFix{{Entry: all[1], Goal: "40x2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if row := f.rows("Log")[2]; row[6] != "40x2" || row[5] != "" || row[7] != "more depth" {
+		t.Errorf("after rewriting its goal the row is %q", row)
+	}
+	if err := s.RemoveEntry(ctx, all[0]); err != nil {
+		t.Fatal(err)
+	}
+	rows := f.rows("Log")
+	if len(rows) != 3 || rows[1][7] != "more depth" || rows[1][2] != "Squats" {
+		t.Errorf("after removing the first entry the sheet holds %q", rows)
+	}
+}
+
+func TestCheckColumns(t *testing.T) {
+	ctx := context.Background()
+	_, s := reorderedSheet(t)
+	moved, warnings, err := s.CheckColumns(ctx)
+	if err != nil || !slices.Equal(moved, []string{"Log: Goal→G, Comment→F, Type→I, Category→J"}) || len(warnings) != 0 {
+		t.Errorf("CheckColumns = %q, %q, %v", moved, warnings, err)
+	}
+
+	f := newFakeSheets()
+	f.setRows("Log", []string{"Date", "Day", "Exercise", "Level", "Reps", "RepsxSets", "Goal", "Comment", "Type", "Category"})
+	moved, warnings, err = f.mustStorage(t, SheetsConfig{}).CheckColumns(ctx)
+	if err != nil || len(moved) != 1 || !slices.Equal(warnings, []string{`Log: columns E and F are both headed like "RepsxSets"; using E`}) {
+		t.Errorf("CheckColumns of a duplicated heading = %q, %q, %v", moved, warnings, err)
+	}
+
+	if moved, warnings, err := newFakeSheets("Log").mustStorage(t, SheetsConfig{}).CheckColumns(ctx); err != nil || moved != nil || warnings != nil {
+		t.Errorf("CheckColumns of a headerless tab = %q, %q, %v", moved, warnings, err)
+	}
+}
@@ -0,0 +1,104 @@
+package calio
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// longComment is a 100KB comment with separators, line breaks and
+// multi-byte characters all the way through.
+var longComment = strings.Repeat("felt strong | then ä\nweaker ", 3600)
+
+// TestLongCommentFile writes a 100KB comment between two entries and reads
+// all three back: the comment keeps its length on its one line, and
+// neither its own fields nor its neighbours' move.
+func TestLongCommentFile(t *testing.T) {
+	ctx := context.Background()
+	f := NewFileStorage(t.TempDir())
+	long := pushups
+	long.Comment = longComment
+	for _, entry := range []WorkoutEntry{squats, long, withDate(squats, "2026-03-05")} {
+		if _, err := f.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(f.FileFor(pushups.Date))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 || len(lines[1]) < 100*1024 {
+		t.Fatalf("the log has %d lines, want the long comment on one line of its own", len(lines))
+	}
+	all, err := f.All(ctx)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("All = %d entries, %v", len(all), err)
+	}
+	want := strings.NewReplacer("|", "/", "\n", " ").Replace(longComment)
+	if got := all[1]; got.Comment != want || got.Exercise != "Pushups" || got.RepsSets != "20x2" || got.Category != CategoryStrength {
+		t.Errorf("the long entry reads back as %s %s %s with a comment of %d bytes, want %d",
+			got.Exercise, got.RepsSets, got.Category, len(got.Comment), len(want))
+	}
+	if all[0].Comment != "" || all[2].Date != "2026-03-05" || all[2].Exercise != "Squats" {
+		t.Errorf("the entries around it read back as %+v and %+v", all[0], all[2])
+	}
+	found, err := f.SearchByDate(ctx, pushups.Date)
+	if err != nil || len(found) != 2 || found[1].Comment != want {
+		t.Errorf("SearchByDate = %d entries, %v", len(found), err)
+	}
+}
+
+// TestLongLineRead reads a year file whose long comment was written before
+// cali replaced separators: bufio.Scanner's 64KB default once failed the
+// whole read on it.
+func TestLongLineRead(t *testing.T) {
+	f := NewFileStorage(t.TempDir())
+	comment := strings.Repeat("x", 100*1024)
+	log := "2026-03-04|A|Pushups|Full|20x2|20x2|" + comment + "\n2026-03-05|A|Squats|Half|35x2|50x2|\n"
+	if err := os.WriteFile(f.FileFor(pushups.Date), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	all, err := f.All(context.Background())
+	if err != nil || len(all) != 2 || all[0].Comment != comment || all[1].Exercise != "Squats" {
+		t.Errorf("All = %d entries, %v", len(all), err)
+	}
+}
+
+// TestLongCommentSheets appends a 100KB comment to a sheet. The cell is cut
+// to what Sheets keeps rather than failing the write, and the row reads
+// back with every other field in place.
+func TestLongCommentSheets(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeSheets()
+	f.setRows("Log", logRow(squats))
+	s := f.mustStorage(t, SheetsConfig{})
+	long := pushups
+	long.Comment = longComment
+	if _, err := s.Append(ctx, long); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := f.rows("Log")
+	if len(rows) != 2 {
+		t.Fatalf("the sheet has %d rows", len(rows))
+	}
+	cell := rows[1][fieldComment]
+	if utf8.RuneCountInString(cell) != SheetsCellLimit || !strings.HasSuffix(cell, "…") ||
+		!strings.HasPrefix(longComment, strings.TrimSuffix(cell, "…")) {
+		t.Errorf("the comment cell holds %d characters, want %d ending in …", utf8.RuneCountInString(cell), SheetsCellLimit)
+	}
+	all, err := s.All(ctx)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("All = %d entries, %v", len(all), err)
+	}
+	if got := all[1]; got.Comment != cell || got.Exercise != "Pushups" || got.RepsSets != "20x2" || got.Category != CategoryStrength {
+		t.Errorf("the long row reads back as %s %s %s", got.Exercise, got.RepsSets, got.Category)
+	}
+	if short := "short"; fitCell(short) != short {
+		t.Errorf("fitCell changed a short value")
+	}
+}
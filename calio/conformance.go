@@ -0,0 +1,206 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// conformanceWriters is how many appends CheckBackend runs at once.
+const conformanceWriters = 8
+
+// conformanceNow is the time CheckBackend runs backends at: the day after
+// the last entry it logs, in the same year.
+var conformanceNow = time.Date(2024, time.March, 6, 12, 0, 0, 0, time.UTC)
+
+// CheckBackend checks a backend against the BackendFunc contract and the
+// Storage interface, as testing/fstest.TestFS checks a file system: it
+// opens the backend twice, once with a cancelled context, then logs a few
+// entries and reads, searches and removes them through every Storage
+// method and RemoveEntry, some of the appends running at once. open must give an empty log
+// each time it is called with cfg, which CheckBackend writes to; cfg.Now is
+// set to a fixed day of 2024, the year the entries are dated. It returns
+// every problem found, joined, or nil when there is none.
+//
+// A backend module can run it from a test:
+//
+//	if err := calio.CheckBackend(ctx, openPostgres, cfg); err != nil {
+//		t.Fatal(err)
+//	}
+func CheckBackend(ctx context.Context, open BackendFunc, cfg BackendConfig) error {
+	cfg = cfg.withDefaults()
+	cfg.Now = func() time.Time { return conformanceNow }
+	c := &conformance{}
+	c.check(ctx, open, cfg)
+	return errors.Join(c.problems...)
+}
+
+type conformance struct {
+	problems []error
+}
+
+func (c *conformance) fail(format string, args ...any) {
+	c.problems = append(c.problems, fmt.Errorf(format, args...))
+}
+
+func (c *conformance) check(ctx context.Context, open BackendFunc, cfg BackendConfig) {
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if storage, err := open(cancelled, cfg); !errors.Is(err, context.Canceled) {
+		c.fail("opening with a cancelled context returned (%T, %v), want context.Canceled", storage, err)
+	}
+
+	storage, err := open(ctx, cfg)
+	switch {
+	case err != nil:
+		c.fail("opening: %v", err)
+		return
+	case storage == nil:
+		c.fail("opening returned neither a storage nor an error")
+		return
+	}
+	if entries, err := storage.All(ctx); err != nil || len(entries) > 0 {
+		c.fail("All on a new log returned %d entries, %v; want none", len(entries), err)
+		return
+	}
+	if _, err := storage.Recent(cancelled, 1); !errors.Is(err, context.Canceled) {
+		c.fail("Recent with a cancelled context returned %v, want context.Canceled", err)
+	}
+
+	exercise := Exercises()[0]
+	level := Levels(exercise)[0]
+	mobility := MobilityExercises()[0]
+	want := []WorkoutEntry{
+		{Date: "2024-03-04", Day: "A", Exercise: exercise, Level: level, RepsSets: "10x2", Goal: "12x3", Comment: "first", Category: CategoryStrength},
+		{Date: "2024-03-04", Exercise: mobility, Level: Levels(mobility)[0], RepsSets: "30sx1", Category: CategoryMobility},
+		{Date: "2024-03-05", Day: "B", Exercise: exercise, Level: level, RepsSets: "12x2", Category: CategoryStrength},
+	}
+	stored, err := storage.AppendBatch(ctx, want[:2])
+	if err != nil {
+		c.fail("AppendBatch: %v", err)
+		return
+	}
+	c.same("AppendBatch", stored, want[:2])
+	one, err := storage.Append(ctx, want[2])
+	if err != nil {
+		c.fail("Append: %v", err)
+		return
+	}
+	c.same("Append", []WorkoutEntry{one}, want[2:])
+
+	all, err := storage.All(ctx)
+	if err != nil {
+		c.fail("All: %v", err)
+		return
+	}
+	c.same("All", all, want)
+	recent, err := storage.Recent(ctx, 2)
+	if err != nil {
+		c.fail("Recent: %v", err)
+	} else {
+		c.same("Recent(2)", recent, want[1:])
+	}
+	if ranged, err := storage.Range(ctx, "2024-03-05", ""); err != nil {
+		c.fail("Range: %v", err)
+	} else {
+		c.same("Range(2024-03-05, open)", ranged, want[2:])
+	}
+	if found, err := storage.SearchByDate(ctx, "2024-03-04"); err != nil {
+		c.fail("SearchByDate: %v", err)
+	} else {
+		c.same("SearchByDate(2024-03-04)", found, want[:2])
+	}
+	if day, date, err := storage.LastTrainingDay(ctx); err != nil || day != "B" || date != "2024-03-05" {
+		c.fail("LastTrainingDay returned %q, %q, %v; want B, 2024-03-05", day, date, err)
+	}
+
+	if err := storage.RemoveByDateIndex(ctx, "2024-03-06", 0); !errors.Is(err, ErrNoData) {
+		c.fail("RemoveByDateIndex on an empty date returned %v, want ErrNoData", err)
+	}
+	if err := storage.RemoveByDateIndex(ctx, "2024-03-04", 2); !errors.Is(err, ErrInvalidIndex) {
+		c.fail("RemoveByDateIndex past the end returned %v, want ErrInvalidIndex", err)
+	}
+	if err := storage.RemoveByDateIndex(ctx, "2024-03-04", 0); err != nil {
+		c.fail("RemoveByDateIndex: %v", err)
+	} else if found, err := storage.SearchByDate(ctx, "2024-03-04"); err != nil {
+		c.fail("SearchByDate after a removal: %v", err)
+	} else {
+		c.same("SearchByDate after removing the first entry", found, want[1:2])
+	}
+	c.checkRemoveEntry(ctx, storage, want[2])
+
+	var wg sync.WaitGroup
+	errs := make([]error, conformanceWriters)
+	for i := range conformanceWriters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry := want[2]
+			entry.Date = "2024-03-06"
+			entry.Comment = fmt.Sprint(i)
+			_, errs[i] = storage.Append(ctx, entry)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		c.fail("Append from %d goroutines at once: %v", conformanceWriters, err)
+	} else if found, err := storage.SearchByDate(ctx, "2024-03-06"); err != nil || len(found) != conformanceWriters {
+		c.fail("after %d appends at once SearchByDate returned %d entries, %v", conformanceWriters, len(found), err)
+	}
+}
+
+// checkRemoveEntry removes an entry as read after another writer appended
+// and removed entries of its date: the one read goes, not the one now at
+// its position. like is a strength entry to log copies of on 2024-03-04,
+// which holds one other entry.
+func (c *conformance) checkRemoveEntry(ctx context.Context, storage Storage, like WorkoutEntry) {
+	second, third := like, like
+	second.Date, second.Comment = "2024-03-04", "second"
+	third.Date, third.Comment = "2024-03-04", "third"
+	if _, err := storage.Append(ctx, second); err != nil {
+		c.fail("Append: %v", err)
+		return
+	}
+	read, err := storage.SearchByDate(ctx, second.Date)
+	if err != nil || len(read) != 2 {
+		c.fail("SearchByDate returned %d entries, %v; want 2", len(read), err)
+		return
+	}
+	if _, err := storage.Append(ctx, third); err != nil {
+		c.fail("Append: %v", err)
+		return
+	}
+	if err := storage.RemoveByDateIndex(ctx, second.Date, 0); err != nil {
+		c.fail("RemoveByDateIndex: %v", err)
+		return
+	}
+	if err := RemoveEntry(ctx, storage, read[1]); err != nil {
+		c.fail("RemoveEntry after entries were added and removed: %v", err)
+	} else if found, err := storage.SearchByDate(ctx, second.Date); err != nil {
+		c.fail("SearchByDate after RemoveEntry: %v", err)
+	} else {
+		c.same("SearchByDate after RemoveEntry of the entry read second", found, []WorkoutEntry{third})
+	}
+	if err := RemoveEntry(ctx, storage, read[1]); !errors.Is(err, ErrEntryChanged) {
+		c.fail("RemoveEntry of an entry removed already returned %v, want ErrEntryChanged", err)
+	}
+}
+
+// same records a problem when got doesn't hold the entries of want, in
+// order, as they were given: the fields a backend has to keep.
+func (c *conformance) same(what string, got, want []WorkoutEntry) {
+	if len(got) != len(want) {
+		c.fail("%s returned %d entries, want %d", what, len(got), len(want))
+		return
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Date != w.Date || g.Day != w.Day || g.Exercise != w.Exercise || g.Level != w.Level ||
+			g.RepsSets != w.RepsSets || g.Goal != w.Goal || g.Comment != w.Comment ||
+			NormalizeCategory(g.Category) != w.Category {
+			c.fail("%s: entry %d is %+v, want %+v", what, i, g, w)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package calio
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFileStorageConformance(t *testing.T) {
+	open := func(ctx context.Context, cfg BackendConfig) (Storage, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		storage := NewFileStorage(t.TempDir())
+		storage.Now, storage.Writer = cfg.Now, cfg.Writer
+		return storage, nil
+	}
+	if err := CheckBackend(context.Background(), open, BackendConfig{Writer: "cali/test"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSheetsStorageConformance runs the checks against a new, empty fake
+// spreadsheet each time the backend is opened.
+func TestSheetsStorageConformance(t *testing.T) {
+	for _, perYear := range []bool{false, true} {
+		open := func(ctx context.Context, cfg BackendConfig) (Storage, error) {
+			f := newFakeSheets(DefaultSheetName)
+			return f.storage(ctx, SheetsConfig{PerYear: perYear, Now: cfg.Now, Writer: cfg.Writer})
+		}
+		if err := CheckBackend(context.Background(), open, BackendConfig{Writer: "cali/test"}); err != nil {
+			t.Errorf("PerYear %v: %v", perYear, err)
+		}
+	}
+}
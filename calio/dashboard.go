@@ -0,0 +1,80 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// DashboardTab is the tab WriteDashboard fills.
+const DashboardTab = "Dashboard"
+
+// WriteDashboard replaces the contents of DashboardTab with grid, creating
+// the tab when the spreadsheet has none. Cells hold plain values, never
+// formulas: ints and float64s as numbers, anything else as text, nil as an
+// empty cell. Clearing the tab and writing grid happen in one BatchUpdate,
+// so a refresh never leaves the previous dashboard's rows behind.
+func (s *SheetsStorage) WriteDashboard(ctx context.Context, grid [][]any) error {
+	if s.sheetName == DashboardTab {
+		return fmt.Errorf("the log tab is named %q; the dashboard would overwrite it", DashboardTab)
+	}
+	sheetID, err := s.sideTab(ctx, DashboardTab)
+	if err != nil {
+		return err
+	}
+	if err := s.replaceTab(ctx, sheetID, grid); err != nil {
+		return fmt.Errorf("writing the dashboard: %w", err)
+	}
+	s.logf("Wrote %d dashboard row(s)\n", len(grid))
+	return nil
+}
+
+// sideTab returns the sheet ID of a tab cali writes beside the log, such as
+// DashboardTab, adding the tab when the spreadsheet has none.
+func (s *SheetsStorage) sideTab(ctx context.Context, title string) (int64, error) {
+	return s.addTab(ctx, title, nil)
+}
+
+// replaceTab clears the tab and writes grid from its top left cell, in one
+// BatchUpdate. Cells are converted with dashboardValue.
+func (s *SheetsStorage) replaceTab(ctx context.Context, sheetID int64, grid [][]any) error {
+	rows := make([]*sheets.RowData, len(grid))
+	for i, row := range grid {
+		rows[i] = &sheets.RowData{}
+		for _, value := range row {
+			rows[i].Values = append(rows[i].Values, &sheets.CellData{UserEnteredValue: dashboardValue(value)})
+		}
+	}
+	whole := &sheets.GridRange{SheetId: sheetID, ForceSendFields: []string{"SheetId"}}
+	_, err := s.svc.Spreadsheets.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			// Without rows, UpdateCells clears the fields named over the range.
+			{UpdateCells: &sheets.UpdateCellsRequest{Range: whole, Fields: "userEnteredValue"}},
+			{UpdateCells: &sheets.UpdateCellsRequest{
+				Start:  &sheets.GridCoordinate{SheetId: sheetID, ForceSendFields: []string{"SheetId", "RowIndex", "ColumnIndex"}},
+				Rows:   rows,
+				Fields: "userEnteredValue",
+			}},
+		},
+	}).Context(ctx).Do()
+	return err
+}
+
+// dashboardValue is the cell value of one grid value.
+func dashboardValue(value any) *sheets.ExtendedValue {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case int:
+		n := float64(v)
+		return &sheets.ExtendedValue{NumberValue: &n}
+	case float64:
+		return &sheets.ExtendedValue{NumberValue: &v}
+	case string:
+		return &sheets.ExtendedValue{StringValue: &v}
+	default:
+		text := fmt.Sprint(v)
+		return &sheets.ExtendedValue{StringValue: &text}
+	}
+}
@@ -0,0 +1,71 @@
+package calio
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestWriteDashboard checks the dashboard tab is added once, holds the grid
+// as plain values, and that a refresh replaces it in one batch update.
+func TestWriteDashboard(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSheets(DefaultSheetName)
+	fake.setRows(DefaultSheetName, []string{"Date", "Day"})
+	s := fake.mustStorage(t, SheetsConfig{})
+
+	grid := [][]any{
+		{"Dashboard", "Updated", "2026-03-04 18:00"},
+		{},
+		{"Month", "Sessions"},
+		{"2026-03", 12},
+		// Text that looks like a formula stays text.
+		{"Ratio", 0.5, nil, "=SUM(B4:B5)"},
+		{"Rows", int64(7)},
+	}
+	if err := s.WriteDashboard(ctx, grid); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Dashboard", "Updated", "2026-03-04 18:00"},
+		nil,
+		{"Month", "Sessions"},
+		{"2026-03", "12"},
+		{"Ratio", "0.5", "", "=SUM(B4:B5)"},
+		{"Rows", "7"},
+	}
+	if got := fake.rows(DashboardTab); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("dashboard rows = %q, want %q", got, want)
+	}
+	if got := fake.rows(DefaultSheetName); len(got) != 1 {
+		t.Errorf("the log tab changed: %q", got)
+	}
+
+	clear(fake.calls)
+	if err := s.WriteDashboard(ctx, [][]any{{"Dashboard"}, {"Streak", 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fake.rows(DashboardTab), [][]string{{"Dashboard"}, {"Streak", "3"}}; !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("refreshed rows = %q, want %q", got, want)
+	}
+	if fake.calls["POST batchUpdate"] != 1 || len(fake.calls) != 1 {
+		t.Errorf("a refresh made calls %v, want one batch update", fake.calls)
+	}
+	if n := strings.Count(strings.Join(tabTitles(fake), ","), DashboardTab); n != 1 {
+		t.Errorf("%d dashboard tabs", n)
+	}
+
+	logTab := newFakeSheets(DashboardTab).mustStorage(t, SheetsConfig{SheetName: DashboardTab})
+	if err := logTab.WriteDashboard(ctx, grid); err == nil {
+		t.Error("wrote the dashboard over a log tab named Dashboard")
+	}
+}
+
+func tabTitles(f *fakeSheets) []string {
+	var titles []string
+	for _, tab := range f.tabs {
+		titles = append(titles, tab.title)
+	}
+	return titles
+}
@@ -0,0 +1,93 @@
+package calio
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// dumpDataset lists every lookup derived from the dataset, in the order the
+// lookups return them.
+func dumpDataset() string {
+	var b strings.Builder
+	for _, exercise := range append(Exercises(), MobilityExercises()...) {
+		playlist, _ := Playlist(exercise)
+		fmt.Fprintf(&b, "%s mobility=%v playlist=%q\n", exercise, !slices.Contains(Exercises(), exercise), playlist)
+		for i, level := range Levels(exercise) {
+			goal, _ := Goal(exercise, level)
+			tutorial, _ := Tutorial(exercise, level)
+			fmt.Fprintf(&b, "  %d %s goal=%s tutorial=%s\n", i+1, level, goal, tutorial)
+		}
+	}
+	for _, day := range DayLetters() {
+		fmt.Fprintf(&b, "day %s: %s\n", day, strings.Join(DayPlan(day), ", "))
+	}
+	return b.String()
+}
+
+// TestDatasetUnchanged checks the embedded dataset validates and derives
+// what the hand-written maps it replaced did: testdata/dataset.golden was
+// written by those maps.
+func TestDatasetUnchanged(t *testing.T) {
+	if err := ValidateDataset(); err != nil {
+		t.Fatal(err)
+	}
+	got := dumpDataset()
+	golden := filepath.Join("testdata", "dataset.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("the dataset derives something else than %s:\n%s", golden, got)
+	}
+}
+
+func TestValidateDataset(t *testing.T) {
+	valid := func() dataset {
+		var ds dataset
+		for _, name := range []string{"Pushups", "Squats", "Pullups", "Leg Raises", "Bridges", "Handstand Push-ups"} {
+			ds.Exercises = append(ds.Exercises, datasetExercise{Name: name, Category: CategoryStrength, Levels: []datasetLevel{{Name: "Wall", Goal: "50x3"}}})
+		}
+		return ds
+	}
+	tests := []struct {
+		name string
+		edit func(ds *dataset)
+		err  string
+	}{
+		{"valid", func(*dataset) {}, ""},
+		{"unnamed exercise", func(ds *dataset) { ds.Exercises[0].Name = " " }, "exercise without a name"},
+		{"duplicate exercise", func(ds *dataset) { ds.Exercises[1].Name = "Pushups" }, "duplicate exercise"},
+		{"unknown category", func(ds *dataset) { ds.Exercises[0].Category = "cardio" }, "unknown category"},
+		{"no levels", func(ds *dataset) { ds.Exercises[0].Levels = nil }, "has no levels"},
+		{"duplicate level", func(ds *dataset) {
+			ds.Exercises[0].Levels = append(ds.Exercises[0].Levels, datasetLevel{Name: "Wall", Goal: "40x3"})
+		}, "duplicate level"},
+		{"no goal", func(ds *dataset) { ds.Exercises[0].Levels[0].Goal = "" }, "no goal"},
+		{"empty description", func(ds *dataset) { ds.Exercises[0].Levels[0].Description = &Description{} }, "empty description"},
+		{"mobility in the day plan", func(ds *dataset) { ds.Exercises[0].Category = CategoryMobility }, "not a strength exercise"},
+	}
+	for _, tt := range tests {
+		ds := valid()
+		tt.edit(&ds)
+		err := ds.validate()
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: validate = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+	if err := loadDataset([]byte(`{"exercises": [], "extra": true}`)); err == nil {
+		t.Error("loadDataset took a field it doesn't know")
+	}
+}
@@ -0,0 +1,50 @@
+package calio
+
+import (
+	"strings"
+	"time"
+)
+
+// Date cells edited by hand in a spreadsheet can come back reformatted by the
+// sheet's locale, e.g. "1/24/2026", or with a stray space from a phone. The
+// Sheets backend reads them back in DateLayout so searching and removing find
+// the same rows the history shows, and keeps the cell in RawDate.
+
+// dateLayouts are the formats NormalizeDate understands: ISO, then US
+// month/day and European day.month with or without leading zeros.
+var dateLayouts = []string{DateLayout, "1/2/2006", "2.1.2006"}
+
+// NormalizeDate returns raw in DateLayout, or false when it is none of the
+// known formats.
+func NormalizeDate(raw string) (string, bool) {
+	value := strings.TrimSpace(raw)
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format(DateLayout), true
+		}
+	}
+	return "", false
+}
+
+// withNormalizedDate sets entry's Date from the cell raw. A cell that isn't
+// already in DateLayout is kept in RawDate; one that can't be read at all
+// leaves Date as the trimmed cell, so the row still shows, and
+// UnreadableDate reports it.
+func withNormalizedDate(entry WorkoutEntry, raw string) WorkoutEntry {
+	date, ok := NormalizeDate(raw)
+	if !ok {
+		date = strings.TrimSpace(raw)
+	}
+	entry.Date = date
+	if raw != date {
+		entry.RawDate = raw
+	}
+	return entry
+}
+
+// UnreadableDate reports whether entry's date cell is in no known format.
+// Such entries match no date search or range.
+func UnreadableDate(entry WorkoutEntry) bool {
+	_, err := time.Parse(DateLayout, entry.Date)
+	return err != nil
+}
@@ -0,0 +1,72 @@
+package calio
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNormalizeDate(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		ok        bool
+	}{
+		{"2026-01-24", "2026-01-24", true},
+		{" 2026-01-24 ", "2026-01-24", true},
+		{"1/24/2026", "2026-01-24", true},
+		{"01/04/2026", "2026-01-04", true},
+		{"24.1.2026", "2026-01-24", true},
+		{"04.01.2026", "2026-01-04", true},
+		{"2026/01/24", "", false},
+		{"24/1/2026", "", false}, // no 24th month
+		{"Jan 24", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeDate(tt.raw)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeDate(%q) = %q, %v, want %q, %v", tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestMixedDateFormats reads a sheet that has the same day in several
+// formats, searches and removes by it, and keeps an unreadable row.
+func TestMixedDateFormats(t *testing.T) {
+	ctx := context.Background()
+	row := func(date, exercise string) []string {
+		return []string{date, "A", exercise, "Full", "20x2", "20x2", "", "straight-sets", "strength"}
+	}
+	f := newFakeSheets()
+	f.setRows("Log",
+		[]string{"Date", "Day", "Exercise", "Level", "RepsxSets", "Goal", "Comment", "Type", "Category"},
+		row("2026-03-04", "Pushups"),
+		row("3/4/2026", "Squats"),
+		row("4.3.2026 ", "Pullups"),
+		row("March 4th", "Bridges"),
+		row("2026-03-05", "Leg Raises"),
+	)
+	s := f.mustStorage(t, SheetsConfig{})
+
+	all, err := s.All(ctx)
+	if err != nil || len(all) != 5 {
+		t.Fatalf("All = %d entries, %v, want all 5", len(all), err)
+	}
+	if all[1].Date != "2026-03-04" || all[1].RawDate != "3/4/2026" || all[0].RawDate != "" {
+		t.Errorf("a reformatted row reads as %q from %q", all[1].Date, all[1].RawDate)
+	}
+	if !UnreadableDate(all[3]) || all[3].Date != "March 4th" || UnreadableDate(all[2]) {
+		t.Errorf("the unreadable row reads as %+v", all[3])
+	}
+
+	found, err := s.SearchByDate(ctx, "2026-03-04")
+	if err != nil || len(found) != 3 || found[0].Exercise != "Pushups" || found[1].Exercise != "Squats" || found[2].Exercise != "Pullups" {
+		t.Fatalf("SearchByDate = %+v, %v, want the three rows of the day", found, err)
+	}
+	if err := s.RemoveByDateIndex(ctx, "2026-03-04", 1); err != nil {
+		t.Fatal(err)
+	}
+	rows := f.rows("Log")
+	if len(rows) != 5 || rows[2][2] != "Pullups" {
+		t.Errorf("after removing the second entry of the day the sheet holds %q, want Squats gone", rows)
+	}
+}
@@ -0,0 +1,110 @@
+package calio
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDurationField(t *testing.T) {
+	tests := []struct {
+		value   string
+		minutes int
+	}{
+		{"40", 40},
+		{" 40 ", 40},
+		{"", 0},
+		{"0", 0},
+		{"-5", 0},
+		{"40m", 0},
+		{"1h", 0},
+	}
+	for _, tt := range tests {
+		if got := parseDuration(tt.value); got != tt.minutes {
+			t.Errorf("parseDuration(%q) = %d, want %d", tt.value, got, tt.minutes)
+		}
+	}
+	for minutes, want := range map[int]string{40: "40", 1: "1", 0: "", -3: ""} {
+		if got := formatDuration(minutes); got != want {
+			t.Errorf("formatDuration(%d) = %q, want %q", minutes, got, want)
+		}
+	}
+}
+
+// TestDurationFile writes a timed and an untimed entry after a line from
+// before the duration field and reads all three back.
+func TestDurationFile(t *testing.T) {
+	ctx := context.Background()
+	f := NewFileStorage(t.TempDir())
+	old := "2026-03-04|A|Squats|Half|35x2|50x2|\n"
+	if err := os.WriteFile(f.FileFor(pushups.Date), []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	timed := withDate(pushups, "2026-03-05")
+	timed.Duration = 40
+	for _, entry := range []WorkoutEntry{timed, withDate(pushups, "2026-03-06")} {
+		if _, err := f.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(f.FileFor(pushups.Date))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 || lines[0]+"\n" != old {
+		t.Fatalf("the log holds %q, want the old line kept as it was", lines)
+	}
+	if fields := strings.Split(lines[1], "|"); len(fields) <= durationField || fields[durationField] != "40" {
+		t.Errorf("the timed entry is written as %q, want 40 in the duration field", lines[1])
+	}
+	if fields := strings.Split(lines[2], "|"); len(fields) <= durationField || strings.TrimSpace(fields[durationField]) != "" {
+		t.Errorf("the untimed entry is written as %q, want an empty duration field", lines[2])
+	}
+
+	all, err := f.All(ctx)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("All = %+v, %v", all, err)
+	}
+	for i, want := range []int{0, 40, 0} {
+		if all[i].Duration != want {
+			t.Errorf("entry %d on %s reads a duration of %d, want %d", i, all[i].Date, all[i].Duration, want)
+		}
+	}
+}
+
+// TestDurationSheets does the same on a sheet, where the duration has its
+// own column and a row from before that column reads as not measured.
+func TestDurationSheets(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeSheets()
+	f.setRows("Log", []string{"2026-03-04", "A", "Squats", "Half", "35x2", "50x2", "", "straight-sets", "strength"})
+	s := f.mustStorage(t, SheetsConfig{})
+	timed := withDate(pushups, "2026-03-05")
+	timed.Duration = 40
+	for _, entry := range []WorkoutEntry{timed, withDate(pushups, "2026-03-06")} {
+		if _, err := s.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows := f.rows("Log")
+	if len(rows) != 3 || len(rows[0]) > fieldDuration {
+		t.Fatalf("the sheet holds %q, want the old row kept as it was", rows)
+	}
+	if rows[1][fieldDuration] != "40" || rows[2][fieldDuration] != "" {
+		t.Errorf("the durations written are %q and %q, want 40 and empty", rows[1], rows[2])
+	}
+
+	all, err := s.All(ctx)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("All = %+v, %v", all, err)
+	}
+	for i, want := range []int{0, 40, 0} {
+		if all[i].Duration != want {
+			t.Errorf("row %d on %s reads a duration of %d, want %d", i+1, all[i].Date, all[i].Duration, want)
+		}
+	}
+}
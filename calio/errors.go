@@ -0,0 +1,68 @@
+package calio
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cloud.google.com/go/auth"
+	"google.golang.org/api/googleapi"
+)
+
+// Errors both backends return, wrapped with %w, for the same logical failure.
+// Match them with errors.Is.
+var (
+	// ErrNotFound means a spreadsheet tab the storage needs does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrNoData means nothing is logged on the date an operation targets.
+	ErrNoData = errors.New("no workouts logged")
+	// ErrInvalidIndex means an index is outside the entries logged on a date.
+	ErrInvalidIndex = errors.New("invalid entry index")
+	// ErrEntryChanged means an entry to remove is gone since it was read,
+	// or moved and can't be told apart from identical ones.
+	ErrEntryChanged = errors.New("the log changed while removing")
+	// ErrUnrecognizedTab means a spreadsheet tab holds something other than
+	// a cali log, so the Sheets backend won't write to it.
+	ErrUnrecognizedTab = errors.New("doesn't look like a cali log")
+)
+
+// ConfigError reports a required setting that is missing. Name is the
+// setting as the caller knows it: a SheetsConfig field for this package, an
+// environment variable for the cali command.
+type ConfigError struct {
+	Name   string
+	Reason string // full message; defaults to "<Name> is required"
+}
+
+func (e *ConfigError) Error() string {
+	if e.Reason == "" {
+		return e.Name + " is required"
+	}
+	return e.Reason
+}
+
+// AuthRefused reports whether err is Google refusing the credentials of the
+// Sheets backend: the token request was turned down, as for a service
+// account key that was disabled, deleted or rotated away, or a request came
+// back unauthenticated. Missing permission on a spreadsheet is not such an
+// error, and neither is the token server failing for a while.
+func AuthRefused(err error) bool {
+	var tokenErr *auth.Error
+	if errors.As(err, &tokenErr) {
+		return !tokenErr.Temporary()
+	}
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusUnauthorized
+}
+
+// checkIndex validates the index RemoveByDateIndex was given against the n
+// entries logged on date.
+func checkIndex(date string, index, n int) error {
+	if n == 0 {
+		return fmt.Errorf("%w on %s", ErrNoData, date)
+	}
+	if index < 0 || index >= n {
+		return fmt.Errorf("%w %d for %s (%d logged)", ErrInvalidIndex, index, date, n)
+	}
+	return nil
+}
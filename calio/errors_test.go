@@ -0,0 +1,94 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"cloud.google.com/go/auth"
+	"google.golang.org/api/googleapi"
+)
+
+// TestRemoveByDateIndexErrors checks every backend reports an empty date and
+// an index past the end with the same sentinel errors.
+func TestRemoveByDateIndexErrors(t *testing.T) {
+	ctx := context.Background()
+	for _, backend := range removeBackends {
+		storage, _ := backend.open(t)
+		if _, err := storage.Append(ctx, pushups); err != nil {
+			t.Fatalf("%s: %v", backend.name, err)
+		}
+		tests := []struct {
+			date  string
+			index int
+			want  error
+		}{
+			{"2026-03-05", 0, ErrNoData},
+			{pushups.Date, 1, ErrInvalidIndex},
+			{pushups.Date, -1, ErrInvalidIndex},
+		}
+		for _, tt := range tests {
+			err := storage.RemoveByDateIndex(ctx, tt.date, tt.index)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("%s: RemoveByDateIndex(%s, %d) = %v, want %v", backend.name, tt.date, tt.index, err, tt.want)
+			}
+		}
+		if entries, err := storage.SearchByDate(ctx, pushups.Date); err != nil || len(entries) != 1 {
+			t.Errorf("%s: after the failed removals the log holds %+v, %v", backend.name, entries, err)
+		}
+	}
+}
+
+func TestSheetsNotFound(t *testing.T) {
+	ctx := context.Background()
+	_, err := newFakeSheets("Notes", "Log 2025", `Sam's "log"`).storage(ctx, SheetsConfig{SheetName: "Experiments"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("opening a spreadsheet without the log tab = %v, want ErrNotFound", err)
+	}
+	if want := `sheet tab "Experiments" not found in spreadsheet (its tabs: "Notes", "Log 2025", "Sam's \"log\"")`; err == nil || err.Error() != want {
+		t.Errorf("the error is %v, want %s", err, want)
+	}
+	// The per-year layout creates tabs as it goes; the single tab one
+	// won't.
+	f := newFakeSheets("Log")
+	s := f.mustStorage(t, SheetsConfig{})
+	if err := s.ensureTab(ctx, "Log 2026", false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ensureTab of a missing tab = %v, want ErrNotFound", err)
+	}
+}
+
+func TestConfigError(t *testing.T) {
+	var ce *ConfigError
+	_, err := NewSheetsStorage(context.Background(), SheetsConfig{})
+	if !errors.As(err, &ce) || ce.Name != "SpreadsheetID" || err.Error() != "SpreadsheetID is required" {
+		t.Errorf("NewSheetsStorage without an ID = %v, want a ConfigError naming SpreadsheetID", err)
+	}
+	_, err = NewSheetsStorage(context.Background(), SheetsConfig{SpreadsheetID: "not an id!"})
+	if !errors.As(err, &ce) || ce.Name != "SpreadsheetID" {
+		t.Errorf("NewSheetsStorage with a bad ID = %v, want a ConfigError naming SpreadsheetID", err)
+	}
+}
+
+func TestAuthRefused(t *testing.T) {
+	status := func(code int) *http.Response { return &http.Response{StatusCode: code} }
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"token refused", fmt.Errorf("reading the log: %w", &auth.Error{Response: status(http.StatusBadRequest), Body: []byte(`{"error":"invalid_grant"}`)}), true},
+		{"token refused without a response", &auth.Error{Err: errors.New("invalid_grant")}, true},
+		{"token server down", &auth.Error{Response: status(http.StatusServiceUnavailable)}, false},
+		{"unauthenticated", fmt.Errorf("appending: %w", &googleapi.Error{Code: http.StatusUnauthorized}), true},
+		{"no permission", &googleapi.Error{Code: http.StatusForbidden}, false},
+		{"offline", errors.New("dial tcp: network is unreachable"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := AuthRefused(tt.err); got != tt.want {
+			t.Errorf("%s: AuthRefused = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
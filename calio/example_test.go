@@ -0,0 +1,59 @@
+package calio_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func ExampleLevels() {
+	levels := calio.Levels("Pushups")
+	goal, _ := calio.Goal("Pushups", levels[0])
+	fmt.Printf("%d levels, from %s (goal %s) to %s\n", len(levels), levels[0], goal, levels[len(levels)-1])
+	// Output: 10 levels, from Wall (goal 50x3) to One-Arm
+}
+
+func ExampleCanonical() {
+	entry := calio.Canonical(calio.WorkoutEntry{Exercise: " pushups", Level: "half one-arm"})
+	fmt.Printf("%s / %s\n", entry.Exercise, entry.Level)
+	// Output: Pushups / Half One-Arm
+}
+
+func ExampleDayPlan() {
+	for _, day := range calio.DayLetters() {
+		fmt.Println(day, strings.Join(calio.DayPlan(day), ", "))
+	}
+	// Output:
+	// A Pushups, Squats
+	// B Pullups, Leg Raises
+	// C Bridges, Handstand Push-ups
+}
+
+func ExampleFileStorage() {
+	dir, err := os.MkdirTemp("", "cali-example")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	storage := calio.NewFileStorage(dir)
+	_, err = storage.Append(ctx, calio.WorkoutEntry{
+		Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	entries, err := storage.SearchByDate(ctx, "2026-03-04")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, entry := range entries {
+		fmt.Println(entry.Exercise, entry.Level, entry.RepsSets)
+	}
+	// Output: Pushups Full 20x2
+}
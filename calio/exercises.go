@@ -0,0 +1,286 @@
+package calio
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PlaylistsURL is the Convicted Condition channel's playlists page.
+const PlaylistsURL = "https://www.youtube.com/@convictedcondition/playlists"
+
+// Day letters in rotation order and the exercises planned for each.
+var dayLetters = []string{"A", "B", "C"}
+
+var dayPlan = map[string][]string{
+	"A": {"Pushups", "Squats"},
+	"B": {"Pullups", "Leg Raises"},
+	"C": {"Bridges", "Handstand Push-ups"},
+}
+
+//go:embed dataset.json
+var datasetJSON []byte
+
+// The exercise dataset: every exercise with its levels, easiest first, and
+// per level the progression goal, tutorial video and offline description.
+// File order is the order Exercises and Levels return. The maps below are
+// derived from it when the package loads; ValidateDataset reports a dataset
+// that fails to load.
+type dataset struct {
+	Exercises []datasetExercise `json:"exercises"`
+}
+
+type datasetExercise struct {
+	Name     string         `json:"name"`
+	Category string         `json:"category"` // CategoryStrength or CategoryMobility
+	Playlist string         `json:"playlist,omitempty"`
+	Levels   []datasetLevel `json:"levels"`
+}
+
+type datasetLevel struct {
+	Name        string       `json:"name"`
+	Goal        string       `json:"goal"`
+	Tutorial    string       `json:"tutorial,omitempty"`
+	Description *Description `json:"description,omitempty"`
+}
+
+// Description is the offline text for one level: what the movement is and
+// the form cues to keep in mind.
+type Description struct {
+	Summary string   `json:"summary"`
+	Cues    []string `json:"cues"`
+}
+
+var (
+	exercises         []string                         // strength, in order
+	mobilityExercises []string                         // the Trifecta holds, kept out of the A/B/C rotation and strength stats
+	levelOrder        = map[string][]string{}          // exercise -> levels, easiest first
+	goals             = map[string]map[string]string{} // exercise -> level -> goal
+	tutorials         = map[string]map[string]string{} // exercise -> level -> video
+	tutorialPlaylists = map[string]string{}            // exercise -> playlist; cali falls back to PlaylistsURL
+	descriptions      = map[string]map[string]Description{}
+	datasetErr        error
+)
+
+func init() {
+	datasetErr = loadDataset(datasetJSON)
+}
+
+// loadDataset validates data and fills the lookup maps from it.
+func loadDataset(data []byte) error {
+	var ds dataset
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&ds); err != nil {
+		return fmt.Errorf("reading dataset.json: %w", err)
+	}
+	if err := ds.validate(); err != nil {
+		return err
+	}
+
+	for _, ex := range ds.Exercises {
+		if ex.Category == CategoryMobility {
+			mobilityExercises = append(mobilityExercises, ex.Name)
+		} else {
+			exercises = append(exercises, ex.Name)
+		}
+		if ex.Playlist != "" {
+			tutorialPlaylists[ex.Name] = ex.Playlist
+		}
+		goals[ex.Name] = map[string]string{}
+		tutorials[ex.Name] = map[string]string{}
+		descriptions[ex.Name] = map[string]Description{}
+		for _, level := range ex.Levels {
+			levelOrder[ex.Name] = append(levelOrder[ex.Name], level.Name)
+			goals[ex.Name][level.Name] = level.Goal
+			if level.Tutorial != "" {
+				tutorials[ex.Name][level.Name] = level.Tutorial
+			}
+			if level.Description != nil {
+				descriptions[ex.Name][level.Name] = *level.Description
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks the dataset's shape: unique named exercises with a known
+// category and at least one level, unique named levels with a goal,
+// YouTube links only, a summary in every description, and the day plan
+// naming only strength exercises.
+func (ds dataset) validate() error {
+	seen := map[string]string{}
+	for _, ex := range ds.Exercises {
+		if strings.TrimSpace(ex.Name) == "" {
+			return errors.New("dataset: exercise without a name")
+		}
+		if _, dup := seen[ex.Name]; dup {
+			return fmt.Errorf("dataset: duplicate exercise %q", ex.Name)
+		}
+		seen[ex.Name] = ex.Category
+		if ex.Category != CategoryStrength && ex.Category != CategoryMobility {
+			return fmt.Errorf("dataset: %q has unknown category %q", ex.Name, ex.Category)
+		}
+		if ex.Playlist != "" && !strings.HasPrefix(strings.TrimSpace(ex.Playlist), "https://www.youtube.com/playlist?list=") {
+			return fmt.Errorf("invalid youtube playlist link for %q: %q", ex.Name, ex.Playlist)
+		}
+		if len(ex.Levels) == 0 {
+			return fmt.Errorf("dataset: %q has no levels", ex.Name)
+		}
+
+		levels := map[string]bool{}
+		for _, level := range ex.Levels {
+			switch {
+			case strings.TrimSpace(level.Name) == "":
+				return fmt.Errorf("dataset: %q has a level without a name", ex.Name)
+			case levels[level.Name]:
+				return fmt.Errorf("dataset: duplicate level %q -> %q", ex.Name, level.Name)
+			case strings.TrimSpace(level.Goal) == "":
+				return fmt.Errorf("dataset: no goal for %q -> %q", ex.Name, level.Name)
+			case level.Tutorial != "" && !strings.HasPrefix(strings.TrimSpace(level.Tutorial), "https://www.youtube.com/watch?v="):
+				return fmt.Errorf("invalid youtube link for %q -> %q: %q", ex.Name, level.Name, level.Tutorial)
+			case level.Description != nil && strings.TrimSpace(level.Description.Summary) == "":
+				return fmt.Errorf("dataset: empty description for %q -> %q", ex.Name, level.Name)
+			}
+			levels[level.Name] = true
+		}
+	}
+
+	for _, day := range dayLetters {
+		for _, exercise := range dayPlan[day] {
+			if seen[exercise] != CategoryStrength {
+				return fmt.Errorf("dataset: day %s plans %q, which is not a strength exercise", day, exercise)
+			}
+		}
+	}
+	return nil
+}
+
+// Exercises returns the six strength exercises in their usual order.
+func Exercises() []string {
+	return slices.Clone(exercises)
+}
+
+// MobilityExercises returns the mobility exercises (the Trifecta holds).
+func MobilityExercises() []string {
+	return slices.Clone(mobilityExercises)
+}
+
+// IsExercise reports whether exercise is a known strength or mobility
+// exercise, matched exactly.
+func IsExercise(exercise string) bool {
+	_, ok := goals[exercise]
+	return ok
+}
+
+// CanonicalExercise returns the dataset's spelling of exercise, matched
+// ignoring case and surrounding spaces, so "pushups " finds "Pushups".
+func CanonicalExercise(exercise string) (string, bool) {
+	exercise = strings.TrimSpace(exercise)
+	for _, known := range append(Exercises(), MobilityExercises()...) {
+		if strings.EqualFold(exercise, known) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+// CanonicalLevel returns the dataset's spelling of a level of exercise,
+// which must already be spelled as the dataset does, matched like
+// CanonicalExercise.
+func CanonicalLevel(exercise, level string) (string, bool) {
+	level = strings.TrimSpace(level)
+	for _, known := range levelOrder[exercise] {
+		if strings.EqualFold(level, known) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+// Canonical returns entry with its exercise and level spelled as the
+// dataset spells them. Values that match nothing known are left as they
+// are, and so is the level of an unknown exercise.
+func Canonical(entry WorkoutEntry) WorkoutEntry {
+	exercise, ok := CanonicalExercise(entry.Exercise)
+	if !ok {
+		return entry
+	}
+	entry.Exercise = exercise
+	if level, ok := CanonicalLevel(exercise, entry.Level); ok {
+		entry.Level = level
+	}
+	return entry
+}
+
+// Levels returns the levels of exercise, easiest first, or nil for an
+// unknown exercise.
+func Levels(exercise string) []string {
+	return slices.Clone(levelOrder[exercise])
+}
+
+// Goal returns the progression goal of a level, e.g. "20x2" or "1min".
+func Goal(exercise, level string) (string, bool) {
+	goal, ok := goals[exercise][level]
+	return goal, ok
+}
+
+// LevelDescription returns the built-in description of a level.
+func LevelDescription(exercise, level string) (Description, bool) {
+	desc, ok := descriptions[exercise][level]
+	if ok {
+		desc.Cues = slices.Clone(desc.Cues)
+	}
+	return desc, ok
+}
+
+// Tutorial returns the YouTube video for a level, if one is mapped.
+func Tutorial(exercise, level string) (string, bool) {
+	link, ok := tutorials[exercise][level]
+	return link, ok
+}
+
+// Playlist returns the YouTube playlist for an exercise, if one is mapped.
+func Playlist(exercise string) (string, bool) {
+	link, ok := tutorialPlaylists[exercise]
+	return link, ok
+}
+
+// DayLetters returns the training days in rotation order.
+func DayLetters() []string {
+	return slices.Clone(dayLetters)
+}
+
+// DayPlan returns the exercises planned for a day letter.
+func DayPlan(day string) []string {
+	return slices.Clone(dayPlan[day])
+}
+
+// PlannedDay returns the day letter whose plan includes exercise; ok is
+// false for exercises no day plans, such as the mobility holds.
+func PlannedDay(exercise string) (day string, ok bool) {
+	for _, day := range dayLetters {
+		if slices.Contains(dayPlan[day], exercise) {
+			return day, true
+		}
+	}
+	return "", false
+}
+
+// ValidateDataset reports whether the embedded exercise dataset loaded:
+// well-formed, with every link pointing at YouTube. Lookups on a dataset
+// that failed to load find nothing.
+func ValidateDataset() error {
+	return datasetErr
+}
+
+// ValidateTutorials checks the tutorial links, which are part of the dataset.
+//
+// Deprecated: use ValidateDataset.
+func ValidateTutorials() error {
+	return ValidateDataset()
+}
@@ -0,0 +1,92 @@
+package calio
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestValidateLinks checks the dataset takes playlist links for whole
+// exercises and watch links for levels, and neither in the other's place.
+func TestValidateLinks(t *testing.T) {
+	const (
+		watch    = "https://www.youtube.com/watch?v=N5C9NUHZ20U"
+		playlist = "https://www.youtube.com/playlist?list=PLpushups"
+	)
+	tests := []struct {
+		playlist, tutorial string
+		err                string // part of the error, "" for none
+	}{
+		{"", watch, ""},
+		{playlist, watch, ""},
+		{" " + playlist, "", ""},
+		{watch, watch, "invalid youtube playlist link"},
+		{"https://www.youtube.com/@convictedcondition/playlists", watch, "invalid youtube playlist link"},
+		{"http://www.youtube.com/playlist?list=PLpushups", watch, "invalid youtube playlist link"},
+		{playlist, playlist, "invalid youtube link"},
+		{playlist, "https://vimeo.com/1", "invalid youtube link"},
+	}
+	for _, tt := range tests {
+		ds := dataset{Exercises: []datasetExercise{{
+			Name:     "Pushups",
+			Category: CategoryStrength,
+			Playlist: tt.playlist,
+			Levels:   []datasetLevel{{Name: "Wall", Goal: "50x3", Tutorial: tt.tutorial}},
+		}}}
+		// The day plan names the other strength exercises too.
+		for _, name := range []string{"Squats", "Pullups", "Leg Raises", "Bridges", "Handstand Push-ups"} {
+			ds.Exercises = append(ds.Exercises, datasetExercise{Name: name, Category: CategoryStrength, Levels: []datasetLevel{{Name: "One", Goal: "1x1"}}})
+		}
+		err := ds.validate()
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("playlist %q, tutorial %q: validate = %v, want %q", tt.playlist, tt.tutorial, err, tt.err)
+		}
+	}
+}
+
+// TestDatasetPlaylists checks the embedded dataset loads and every
+// playlist it maps is one Playlist returns.
+func TestDatasetPlaylists(t *testing.T) {
+	if err := ValidateDataset(); err != nil {
+		t.Fatal(err)
+	}
+	var ds dataset
+	if err := json.Unmarshal(datasetJSON, &ds); err != nil {
+		t.Fatal(err)
+	}
+	for _, ex := range ds.Exercises {
+		link, ok := Playlist(ex.Name)
+		if ok != (ex.Playlist != "") || link != ex.Playlist {
+			t.Errorf("Playlist(%q) = %q, %v, want %q", ex.Name, link, ok, ex.Playlist)
+		}
+	}
+}
+
+// TestLevelDescriptionCopiesCues checks a caller changing the cues it got
+// leaves the dataset's as they were.
+func TestLevelDescriptionCopiesCues(t *testing.T) {
+	desc, ok := LevelDescription("Pushups", "Wall")
+	if !ok || desc.Summary == "" || len(desc.Cues) == 0 {
+		t.Fatalf("LevelDescription(Pushups, Wall) = %+v, %v, want a summary and cues", desc, ok)
+	}
+	cue := desc.Cues[0]
+	desc.Cues[0] = "changed"
+	if again, _ := LevelDescription("Pushups", "Wall"); again.Cues[0] != cue {
+		t.Errorf("the dataset's first cue is now %q, want %q", again.Cues[0], cue)
+	}
+}
+
+func TestPlannedDay(t *testing.T) {
+	for _, day := range DayLetters() {
+		for _, exercise := range DayPlan(day) {
+			if got, ok := PlannedDay(exercise); got != day || !ok {
+				t.Errorf("PlannedDay(%q) = %q, %v; want %q", exercise, got, ok, day)
+			}
+		}
+	}
+	for _, exercise := range []string{"Bridge Hold", "Dips", ""} {
+		if got, ok := PlannedDay(exercise); ok {
+			t.Errorf("PlannedDay(%q) = %q", exercise, got)
+		}
+	}
+}
@@ -0,0 +1,480 @@
+package calio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+// fakeSpreadsheetID is the ID the fake spreadsheet answers to.
+const fakeSpreadsheetID = "1FakeSpreadsheetForCaliTests0123456789abcdef"
+
+// fakeBlankRows is how many empty rows each tab's grid has below its
+// values, as a new tab has its first 1000.
+const fakeBlankRows = 20
+
+// fakeSheets is an in-memory spreadsheet behind an http.RoundTripper,
+// answering the Sheets API calls SheetsStorage makes: reading and writing
+// values, appending rows, and adding tabs, deleting rows and updating cells
+// in batch updates. Values are kept as the strings the API returns them as.
+type fakeSheets struct {
+	mu   sync.Mutex
+	tabs []*fakeTab
+	// calls counts the requests served, by method and path suffix, such
+	// as "GET values" or "POST batchUpdate".
+	calls map[string]int
+}
+
+type fakeTab struct {
+	id    int64
+	title string
+	rows  [][]string
+	// text are the columns formatted as plain text, in the order asked.
+	text []int64
+}
+
+// fakeSheetIDs numbers the tabs of every fake spreadsheet, which share
+// fakeSpreadsheetID, so the tabs SheetsStorage recognizes as a cali log (see
+// checkTab) are never taken for those of another test.
+var fakeSheetIDs atomic.Int64
+
+// newFakeSheets returns a spreadsheet with the given tabs, empty.
+func newFakeSheets(titles ...string) *fakeSheets {
+	f := &fakeSheets{calls: map[string]int{}}
+	for _, title := range titles {
+		f.addTab(title)
+	}
+	return f
+}
+
+func (f *fakeSheets) addTab(title string) *fakeTab {
+	tab := &fakeTab{id: fakeSheetIDs.Add(1), title: title}
+	f.tabs = append(f.tabs, tab)
+	return tab
+}
+
+func (f *fakeSheets) tab(title string) *fakeTab {
+	for _, tab := range f.tabs {
+		if tab.title == title {
+			return tab
+		}
+	}
+	return nil
+}
+
+// setRows replaces the rows of tab, which is created when missing.
+func (f *fakeSheets) setRows(title string, rows ...[]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tab := f.tab(title)
+	if tab == nil {
+		tab = f.addTab(title)
+	}
+	tab.rows = rows
+}
+
+// insertRow inserts row before the 0-based row at of tab, as someone
+// editing the sheet in the browser would.
+func (f *fakeSheets) insertRow(title string, at int, row []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tab := f.tab(title)
+	tab.rows = append(tab.rows[:at], append([][]string{row}, tab.rows[at:]...)...)
+}
+
+// rows returns a copy of the rows of tab.
+func (f *fakeSheets) rows(title string) [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var rows [][]string
+	for _, row := range f.tab(title).rows {
+		rows = append(rows, append([]string(nil), row...))
+	}
+	return rows
+}
+
+// storage opens a SheetsStorage on the fake with cfg, which needn't name
+// the spreadsheet.
+func (f *fakeSheets) storage(ctx context.Context, cfg SheetsConfig) (*SheetsStorage, error) {
+	cfg.SpreadsheetID = fakeSpreadsheetID
+	cfg.ClientOptions = []option.ClientOption{
+		option.WithHTTPClient(&http.Client{Transport: f}),
+		option.WithEndpoint("https://sheets.test/"),
+	}
+	return NewSheetsStorage(ctx, cfg)
+}
+
+// mustStorage is storage for tests that only need it opened.
+func (f *fakeSheets) mustStorage(t *testing.T, cfg SheetsConfig) *SheetsStorage {
+	t.Helper()
+	s, err := f.storage(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func (f *fakeSheets) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	path, ok := strings.CutPrefix(req.URL.Path, "/v4/spreadsheets/"+fakeSpreadsheetID)
+	if !ok {
+		return fakeResponse(http.StatusNotFound, map[string]any{"error": map[string]any{"code": 404, "message": "no such spreadsheet"}})
+	}
+	var body map[string]any
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result any
+	var err error
+	switch {
+	case path == "" && req.Method == http.MethodGet:
+		f.calls["GET spreadsheet"]++
+		result = f.metadata()
+	case path == ":batchUpdate":
+		f.calls["POST batchUpdate"]++
+		result, err = f.batchUpdate(body)
+	case path == "/values:batchGet":
+		f.calls["GET batchGet"]++
+		var ranges []any
+		for _, a1 := range req.URL.Query()["ranges"] {
+			var values any
+			if values, err = f.get(a1); err != nil {
+				break
+			}
+			ranges = append(ranges, values)
+		}
+		result = map[string]any{"valueRanges": ranges}
+	case path == "/values:batchUpdate":
+		f.calls["POST values:batchUpdate"]++
+		data, _ := body["data"].([]any)
+		for _, item := range data {
+			update := item.(map[string]any)
+			if err = f.put(update["range"].(string), update["values"]); err != nil {
+				break
+			}
+		}
+		result = map[string]any{}
+	case strings.HasPrefix(path, "/values/") && strings.HasSuffix(path, ":append"):
+		f.calls["POST append"]++
+		result, err = f.append(strings.TrimSuffix(strings.TrimPrefix(path, "/values/"), ":append"), body["values"])
+	case strings.HasPrefix(path, "/values/") && req.Method == http.MethodGet:
+		f.calls["GET values"]++
+		result, err = f.get(strings.TrimPrefix(path, "/values/"))
+	case strings.HasPrefix(path, "/values/") && req.Method == http.MethodPut:
+		f.calls["PUT values"]++
+		err = f.put(strings.TrimPrefix(path, "/values/"), body["values"])
+		result = map[string]any{}
+	default:
+		err = fmt.Errorf("fake sheets: unexpected %s %s", req.Method, req.URL.Path)
+	}
+	if err != nil {
+		return fakeResponse(http.StatusBadRequest, map[string]any{"error": map[string]any{"code": 400, "message": err.Error()}})
+	}
+	return fakeResponse(http.StatusOK, result)
+}
+
+func fakeResponse(status int, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+	}, nil
+}
+
+func (f *fakeSheets) metadata() map[string]any {
+	var list []any
+	for _, tab := range f.tabs {
+		list = append(list, map[string]any{"properties": tab.properties()})
+	}
+	return map[string]any{"sheets": list}
+}
+
+func (t *fakeTab) properties() map[string]any {
+	return map[string]any{
+		"sheetId":        t.id,
+		"title":          t.title,
+		"gridProperties": map[string]any{"rowCount": len(t.rows) + fakeBlankRows, "columnCount": 26},
+	}
+}
+
+func (f *fakeSheets) batchUpdate(body map[string]any) (any, error) {
+	var replies []any
+	requests, _ := body["requests"].([]any)
+	for _, item := range requests {
+		request := item.(map[string]any)
+		reply := map[string]any{}
+		switch {
+		case request["addSheet"] != nil:
+			title := request["addSheet"].(map[string]any)["properties"].(map[string]any)["title"].(string)
+			if f.tab(title) != nil {
+				return nil, fmt.Errorf("a sheet named %q already exists", title)
+			}
+			reply["addSheet"] = map[string]any{"properties": f.addTab(title).properties()}
+		case request["deleteDimension"] != nil:
+			rng := request["deleteDimension"].(map[string]any)["range"].(map[string]any)
+			tab := f.tabByID(int64(number(rng["sheetId"])))
+			start, end := int(number(rng["startIndex"])), int(number(rng["endIndex"]))
+			if tab == nil || start >= end || end > len(tab.rows) {
+				return nil, fmt.Errorf("deleteDimension: invalid range %v", rng)
+			}
+			tab.rows = append(tab.rows[:start], tab.rows[end:]...)
+		case request["insertDimension"] != nil:
+			rng := request["insertDimension"].(map[string]any)["range"].(map[string]any)
+			tab := f.tabByID(int64(number(rng["sheetId"])))
+			start, end := int(number(rng["startIndex"])), int(number(rng["endIndex"]))
+			if tab == nil || start >= end || start > len(tab.rows) {
+				return nil, fmt.Errorf("insertDimension: invalid range %v", rng)
+			}
+			tab.rows = slices.Insert(tab.rows, start, make([][]string, end-start)...)
+		case request["repeatCell"] != nil:
+			repeat := request["repeatCell"].(map[string]any)
+			rng := repeat["range"].(map[string]any)
+			tab := f.tabByID(int64(number(rng["sheetId"])))
+			if tab == nil {
+				return nil, fmt.Errorf("repeatCell: invalid range %v", rng)
+			}
+			format, _ := repeat["cell"].(map[string]any)["userEnteredFormat"].(map[string]any)
+			if numberFormat, _ := format["numberFormat"].(map[string]any); numberFormat["type"] == "TEXT" {
+				tab.text = append(tab.text, int64(number(rng["startColumnIndex"])))
+			}
+		case request["updateCells"] != nil:
+			if err := f.updateCells(request["updateCells"].(map[string]any)); err != nil {
+				return nil, err
+			}
+		}
+		replies = append(replies, reply)
+	}
+	return map[string]any{"replies": replies}, nil
+}
+
+// updateCells writes the userEnteredValue of the rows from start or, without
+// rows, clears the values over range; cali only sends whole-tab ranges.
+func (f *fakeSheets) updateCells(request map[string]any) error {
+	if request["fields"] != "userEnteredValue" {
+		return fmt.Errorf("updateCells: unexpected fields %v", request["fields"])
+	}
+	rows, _ := request["rows"].([]any)
+	if rows == nil {
+		rng, _ := request["range"].(map[string]any)
+		tab := f.tabByID(int64(number(rng["sheetId"])))
+		if tab == nil {
+			return fmt.Errorf("updateCells: invalid range %v", rng)
+		}
+		tab.rows = nil
+		return nil
+	}
+	start, _ := request["start"].(map[string]any)
+	tab := f.tabByID(int64(number(start["sheetId"])))
+	if tab == nil {
+		return fmt.Errorf("updateCells: invalid start %v", start)
+	}
+	row0, col0 := int(number(start["rowIndex"])), int(number(start["columnIndex"]))
+	for i, item := range rows {
+		values, _ := item.(map[string]any)["values"].([]any)
+		for j, cell := range values {
+			value, _ := cell.(map[string]any)["userEnteredValue"].(map[string]any)
+			text := ""
+			switch {
+			case value["formulaValue"] != nil:
+				return fmt.Errorf("updateCells: a formula in row %d", row0+i+1)
+			case value["numberValue"] != nil:
+				text = strconv.FormatFloat(number(value["numberValue"]), 'f', -1, 64)
+			case value["stringValue"] != nil:
+				text = value["stringValue"].(string)
+			}
+			tab.set(row0+i, col0+j, text)
+		}
+	}
+	return nil
+}
+
+func (f *fakeSheets) tabByID(id int64) *fakeTab {
+	for _, tab := range f.tabs {
+		if tab.id == id {
+			return tab
+		}
+	}
+	return nil
+}
+
+func number(value any) float64 {
+	n, _ := value.(float64)
+	return n
+}
+
+// fakeRange is an A1 range, 0-based with inclusive ends; -1 leaves an end
+// open.
+type fakeRange struct {
+	tab                    *fakeTab
+	col0, row0, col1, row1 int
+}
+
+func (f *fakeSheets) parseRange(a1 string) (fakeRange, error) {
+	title, cells, found := strings.Cut(a1, "!")
+	if !found {
+		title, cells = a1, ""
+	}
+	if strings.HasPrefix(title, "'") {
+		title = strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(title, "'"), "'"), "''", "'")
+	}
+	r := fakeRange{tab: f.tab(title), col1: -1, row1: -1}
+	if r.tab == nil {
+		return r, fmt.Errorf("Unable to parse range: %s", a1)
+	}
+	if cells == "" {
+		return r, nil
+	}
+	start, end, found := strings.Cut(cells, ":")
+	var err error
+	r.col0, r.row0, err = parseCell(start, 0, 0)
+	if err != nil {
+		return r, err
+	}
+	if !found {
+		r.col1, r.row1 = r.col0, r.row0
+		return r, nil
+	}
+	r.col1, r.row1, err = parseCell(end, -1, -1)
+	return r, err
+}
+
+// parseCell reads "B7", "B" or "7" as a 0-based column and row, with
+// col and row standing for the parts left out.
+func parseCell(cell string, col, row int) (int, int, error) {
+	letters := strings.TrimRight(cell, "0123456789")
+	if letters != "" {
+		col = 0
+		for _, r := range letters {
+			if r < 'A' || r > 'Z' {
+				return 0, 0, fmt.Errorf("bad cell %q", cell)
+			}
+			col = col*26 + int(r-'A') + 1
+		}
+		col--
+	}
+	if digits := cell[len(letters):]; digits != "" {
+		n, err := strconv.Atoi(digits)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("bad cell %q", cell)
+		}
+		row = n - 1
+	}
+	return col, row, nil
+}
+
+// get returns the values of a1 as the API does: trailing empty cells and
+// rows left out.
+func (f *fakeSheets) get(a1 string) (any, error) {
+	r, err := f.parseRange(a1)
+	if err != nil {
+		return nil, err
+	}
+	var values [][]string
+	for i := r.row0; i < len(r.tab.rows) && (r.row1 < 0 || i <= r.row1); i++ {
+		var row []string
+		for j := r.col0; j < len(r.tab.rows[i]) && (r.col1 < 0 || j <= r.col1); j++ {
+			row = append(row, r.tab.rows[i][j])
+		}
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		values = append(values, row)
+	}
+	for len(values) > 0 && len(values[len(values)-1]) == 0 {
+		values = values[:len(values)-1]
+	}
+	result := map[string]any{"range": a1, "majorDimension": "ROWS"}
+	if len(values) > 0 {
+		result["values"] = values
+	}
+	return result, nil
+}
+
+// put writes values into a1 from its top-left cell.
+func (f *fakeSheets) put(a1 string, values any) error {
+	r, err := f.parseRange(a1)
+	if err != nil {
+		return err
+	}
+	rows, _ := values.([]any)
+	for i, row := range rows {
+		cells, _ := row.([]any)
+		for j, cell := range cells {
+			r.tab.set(r.row0+i, r.col0+j, fmt.Sprint(cell))
+		}
+	}
+	return nil
+}
+
+func (t *fakeTab) set(row, col int, value string) {
+	for len(t.rows) <= row {
+		t.rows = append(t.rows, nil)
+	}
+	for len(t.rows[row]) <= col {
+		t.rows[row] = append(t.rows[row], "")
+	}
+	t.rows[row][col] = value
+}
+
+// append inserts values after the last row of a1's tab holding anything,
+// as INSERT_ROWS does, and reports the range written.
+func (f *fakeSheets) append(a1 string, values any) (any, error) {
+	r, err := f.parseRange(a1)
+	if err != nil {
+		return nil, err
+	}
+	at := len(r.tab.rows)
+	for at > 0 && blankCells(r.tab.rows[at-1]) {
+		at--
+	}
+	rows, _ := values.([]any)
+	inserted := make([][]string, len(rows))
+	width := 0
+	for i, row := range rows {
+		cells, _ := row.([]any)
+		for _, cell := range cells {
+			inserted[i] = append(inserted[i], fmt.Sprint(cell))
+		}
+		width = max(width, len(cells))
+	}
+	r.tab.rows = append(r.tab.rows[:at], append(inserted, r.tab.rows[at:]...)...)
+	updated := fmt.Sprintf("'%s'!A%d:%s%d", r.tab.title, at+1, columnName(max(width, 1)-1), at+len(rows))
+	return map[string]any{"updates": map[string]any{"updatedRange": updated, "updatedRows": len(rows)}}, nil
+}
+
+func blankCells(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
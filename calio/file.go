@@ -0,0 +1,482 @@
+package calio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// parseLogLine reads a log line. Marked lines (see SchemaVersion) have every
+// field at a fixed position; unmarked ones grew over time, the workout type
+// and category fields coming later, so seven fields are straight-set
+// strength work logged without a user.
+func parseLogLine(line string) (WorkoutEntry, bool) {
+	parts := strings.Split(line, "|")
+	if len(parts) < 7 {
+		return WorkoutEntry{}, false
+	}
+	entry := WorkoutEntry{
+		Date:     parts[0],
+		Day:      parts[1],
+		Exercise: parts[2],
+		Level:    parts[3],
+		RepsSets: parts[4],
+		Goal:     parts[5],
+		Comment:  parts[6],
+		Type:     TypeStraightSets,
+		Category: CategoryStrength,
+	}
+	if len(parts) > schemaField {
+		if schema, writer, ok := parseSchemaMarker(parts[schemaField]); ok {
+			// Schema 1 fields; newer schemas only add fields after the marker.
+			entry.Type = NormalizeWorkoutType(parts[7])
+			entry.Category = NormalizeCategory(parts[8])
+			entry.User = strings.TrimSpace(parts[9])
+			entry.Schema, entry.Writer = schema, writer
+			if schema >= 2 && len(parts) > durationField {
+				entry.Duration = parseDuration(parts[durationField])
+			}
+			if schema >= 3 && len(parts) > loadField {
+				entry.Load = strings.TrimSpace(parts[loadField])
+			}
+			if schema >= 4 && len(parts) > loggedField {
+				entry.LoggedAt = strings.TrimSpace(parts[loggedField])
+			}
+			return entry, true
+		}
+	}
+	if len(parts) > 7 {
+		entry.Type = NormalizeWorkoutType(parts[7])
+	}
+	if len(parts) > 8 {
+		entry.Category = NormalizeCategory(parts[8])
+	}
+	if len(parts) > 9 {
+		entry.User = strings.TrimSpace(parts[9])
+	}
+	return entry, true
+}
+
+// serializeLogEntry writes a marked line. Older versions read the fields they
+// know and ignore the marker and what follows it, and an empty user field
+// reads as no user. A comment of any length stays on its line and in its
+// field: separators and line breaks in it are replaced.
+func serializeLogEntry(entry WorkoutEntry) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s\n",
+		entry.Date, entry.Day, entry.Exercise, entry.Level, entry.RepsSets, entry.Goal, restFields.Replace(entry.Comment),
+		NormalizeWorkoutType(entry.Type), NormalizeCategory(entry.Category), entry.User, schemaMarker(entry),
+		formatDuration(entry.Duration), restFields.Replace(entry.Load), restFields.Replace(entry.LoggedAt))
+}
+
+// FileStorage keeps the log in plain text files, one per year
+// (workout-2026.log), one pipe-separated entry per line. Within a process,
+// writes exclude each other and reads, so a read never sees a file halfway
+// through a rewrite; other processes writing the same directory are not
+// coordinated with. Rewrites of whole files are journaled, so one stopped
+// by a crash is finished by the next (see Recover).
+type FileStorage struct {
+	logDir string
+	mu     sync.RWMutex
+
+	// Now returns the current time, used for the current year and to ignore
+	// future-dated entries. NewFileStorage sets it to time.Now.
+	Now func() time.Time
+	// Writer names the program in the schema marker of appended lines, e.g.
+	// "cali/1.4.0"; empty leaves it out.
+	Writer string
+}
+
+// NewFileStorage returns a FileStorage keeping its year files in dir, which
+// is created on the first write.
+func NewFileStorage(dir string) *FileStorage {
+	return &FileStorage{logDir: dir, Now: time.Now}
+}
+
+// Dir returns the directory holding the year files.
+func (f *FileStorage) Dir() string {
+	return f.logDir
+}
+
+func (f *FileStorage) now() time.Time {
+	if f.Now == nil {
+		return time.Now()
+	}
+	return f.Now()
+}
+
+// FileFor returns the year file that holds entries dated date.
+func (f *FileStorage) FileFor(date string) string {
+	return filepath.Join(f.logDir, fmt.Sprintf("workout-%d.log", yearFromDate(date, f.now)))
+}
+
+// Append adds entry to the file for its year. RowIndex of the result is the
+// line it was written to. A rewrite left interrupted is finished first, so
+// its renames can't drop the new line.
+func (f *FileStorage) Append(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return WorkoutEntry{}, err
+	}
+	if _, err := f.recover(); err != nil {
+		return WorkoutEntry{}, err
+	}
+	entry = stamped(entry, f.Writer, f.now())
+	logFile := f.FileFor(entry.Date)
+
+	if err := os.MkdirAll(f.logDir, 0755); err != nil {
+		return WorkoutEntry{}, err
+	}
+
+	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return WorkoutEntry{}, err
+	}
+	defer file.Close()
+
+	existing, err := io.ReadAll(file)
+	if err != nil {
+		return WorkoutEntry{}, err
+	}
+	line := serializeLogEntry(entry)
+	if len(existing) > 0 && existing[len(existing)-1] != '\n' {
+		// Don't run on from a last line missing its newline.
+		line = "\n" + line
+		existing = append(existing, '\n')
+	}
+	if _, err := file.WriteString(line); err != nil {
+		return WorkoutEntry{}, err
+	}
+	return storedAt(entry, int64(bytes.Count(existing, []byte("\n")))), nil
+}
+
+// AppendBatch adds entries to their year files all-or-nothing: each affected
+// file is copied with the new lines, and the copies replace the originals
+// through the rewrite journal (see rewriteFiles), so even a batch spanning
+// several years is never left half applied.
+func (f *FileStorage) AppendBatch(ctx context.Context, entries []WorkoutEntry) ([]WorkoutEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(f.logDir, 0755); err != nil {
+		return nil, err
+	}
+
+	entries = slices.Clone(entries)
+	var files []string
+	lines := map[string][]string{}
+	for i, entry := range entries {
+		entry = stamped(entry, f.Writer, f.now())
+		entries[i] = entry
+		logFile := f.FileFor(entry.Date)
+		if _, ok := lines[logFile]; !ok {
+			files = append(files, logFile)
+		}
+		lines[logFile] = append(lines[logFile], serializeLogEntry(entry))
+	}
+
+	contents := map[string]string{}
+	next := map[string]int64{} // line the file's next new entry goes to
+	for _, logFile := range files {
+		text, first, err := appendedContents(logFile, lines[logFile])
+		if err != nil {
+			return nil, err
+		}
+		contents[logFile] = text
+		next[logFile] = first
+	}
+	if err := f.rewriteFiles(ctx, files, contents); err != nil {
+		return nil, err
+	}
+
+	stored := make([]WorkoutEntry, len(entries))
+	for i, entry := range entries {
+		logFile := f.FileFor(entry.Date)
+		stored[i] = storedAt(entry, next[logFile])
+		next[logFile]++
+	}
+	return stored, nil
+}
+
+// appendedContents returns the contents of logFile followed by lines and
+// the line the first of lines lands on.
+func appendedContents(logFile string, lines []string) (string, int64, error) {
+	existing, err := os.ReadFile(logFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", 0, err
+	}
+	if len(existing) > 0 && existing[len(existing)-1] != '\n' {
+		existing = append(existing, '\n')
+	}
+	first := int64(bytes.Count(existing, []byte("\n")))
+	return string(existing) + strings.Join(lines, ""), first, nil
+}
+
+// Recent returns up to limit of the latest entries in the current year's
+// file, topped up from last year's when this year's has fewer, as the
+// sheet's per-year tabs are read.
+func (f *FileStorage) Recent(ctx context.Context, limit int) ([]WorkoutEntry, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	year := f.now().Year()
+	entries := []WorkoutEntry{}
+	for _, y := range []int{year, year - 1} {
+		if len(entries) >= limit {
+			break
+		}
+		older, err := f.readYear(y)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(older, entries...)
+	}
+
+	if len(entries) <= limit {
+		return entries, nil
+	}
+	return entries[len(entries)-limit:], nil
+}
+
+// readYear reads the entries of year's file; a missing file has none.
+func (f *FileStorage) readYear(year int) ([]WorkoutEntry, error) {
+	file, err := os.Open(filepath.Join(f.logDir, fmt.Sprintf("workout-%d.log", year)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	return scanLogEntries(file, "")
+}
+
+// All returns every entry across all year files.
+func (f *FileStorage) All(ctx context.Context) ([]WorkoutEntry, error) {
+	return f.Range(ctx, "", "")
+}
+
+// yearFiles returns the year files overlapping [since, until], oldest first.
+func (f *FileStorage) yearFiles(since, until string) ([]string, error) {
+	logFiles, err := filepath.Glob(filepath.Join(f.logDir, "workout-*.log"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(logFiles)
+
+	var selected []string
+	for _, logFile := range logFiles {
+		year := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(logFile), "workout-"), ".log")
+		if (since != "" && year < since[:4]) || (until != "" && year > until[:4]) {
+			continue
+		}
+		selected = append(selected, logFile)
+	}
+	return selected, nil
+}
+
+// Range reads only the year files overlapping [since, until]; empty bounds
+// are open.
+func (f *FileStorage) Range(ctx context.Context, since, until string) ([]WorkoutEntry, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	selected, err := f.yearFiles(since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := readLogFiles(ctx, selected)
+	if err != nil {
+		return nil, err
+	}
+	return filterRange(entries, since, until), nil
+}
+
+func readLogFile(logFile string) ([]WorkoutEntry, error) {
+	file, err := os.Open(logFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries, err := scanLogEntries(file, "")
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// SearchByDate returns the entries logged on date, reading only its year's file.
+func (f *FileStorage) SearchByDate(ctx context.Context, date string) ([]WorkoutEntry, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	year := date[:4]
+	logFile := filepath.Join(f.logDir, fmt.Sprintf("workout-%s.log", year))
+
+	file, err := os.Open(logFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []WorkoutEntry{}, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	return scanLogEntries(file, date)
+}
+
+// RemoveByDateIndex rewrites date's year file without the index-th entry
+// logged on date, through the rewrite journal.
+func (f *FileStorage) RemoveByDateIndex(ctx context.Context, date string, index int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	year := date[:4]
+	logFile := filepath.Join(f.logDir, fmt.Sprintf("workout-%s.log", year))
+
+	data, err := os.ReadFile(logFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkIndex(date, index, 0)
+	}
+	if err != nil {
+		return err
+	}
+	// Count entries as GetEntriesByDate reads them, so index names the same
+	// one: indented lines count, malformed ones do not.
+	entries, err := scanLogEntries(strings.NewReader(string(data)), date)
+	if err != nil {
+		return err
+	}
+	if err := checkIndex(date, index, len(entries)); err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	row := entries[index].RowIndex
+	lines = append(lines[:row], lines[row+1:]...)
+	text := strings.Join(lines, "\n")
+	if text != "" {
+		text += "\n"
+	}
+	return f.rewriteFiles(ctx, []string{logFile}, map[string]string{logFile: text})
+}
+
+// LastTrainingDay looks only at the current year's file.
+func (f *FileStorage) LastTrainingDay(ctx context.Context) (string, string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return "", "", err
+	}
+	year := f.now().Year()
+	logFile := filepath.Join(f.logDir, fmt.Sprintf("workout-%d.log", year))
+
+	file, err := os.Open(logFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	defer file.Close()
+
+	entries, err := scanLogEntries(file, "")
+	if err != nil {
+		return "", "", err
+	}
+
+	day, date := LastStrengthDay(WithoutFuture(entries, f.now()))
+	return day, date, nil
+}
+
+// maxLogLineSize caps a single log line. bufio.Scanner's default of 64KB made
+// reads fail on entries with very long comments.
+const maxLogLineSize = 16 * 1024 * 1024
+
+// maxParallelReads bounds how many year files are parsed at once.
+var maxParallelReads = min(runtime.NumCPU(), 4)
+
+func newLogScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	return scanner
+}
+
+// scanLogEntries parses the lines of a year file that start with prefix, all
+// of them for "". RowIndex is the line each entry is on.
+func scanLogEntries(r io.Reader, prefix string) ([]WorkoutEntry, error) {
+	var entries []WorkoutEntry
+	scanner := newLogScanner(r)
+	for line := int64(0); scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(text, prefix) {
+			continue
+		}
+		if entry, ok := parseLogLine(text); ok {
+			entry.RowIndex = line
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// readLogFiles parses the given files concurrently and returns their entries
+// concatenated in the order of logFiles, regardless of which read finished
+// first. The first error (by file order) is returned. Once ctx is done the
+// remaining files are skipped.
+func readLogFiles(ctx context.Context, logFiles []string) ([]WorkoutEntry, error) {
+	results := make([][]WorkoutEntry, len(logFiles))
+	errs := make([]error, len(logFiles))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(maxParallelReads, len(logFiles)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if errs[i] = ctx.Err(); errs[i] != nil {
+					continue
+				}
+				results[i], errs[i] = readLogFile(logFiles[i])
+			}
+		}()
+	}
+	for i := range logFiles {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var entries []WorkoutEntry
+	for i := range logFiles {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		entries = append(entries, results[i]...)
+	}
+	return entries, nil
+}
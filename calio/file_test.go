@@ -0,0 +1,153 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// largeLog returns a log of years of training ending in 2026, three
+// sessions a week of a strength and a mobility pair, one year file each.
+func largeLog(tb testing.TB, years int) *FileStorage {
+	tb.Helper()
+	f := NewFileStorage(tb.TempDir())
+	hold := WorkoutEntry{Exercise: "L-Sit", Level: "Tuck", RepsSets: "30s", Category: CategoryMobility}
+	for year := 2026 - years + 1; year <= 2026; year++ {
+		var batch []WorkoutEntry
+		day := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		for ; day.Year() == year; day = day.AddDate(0, 0, 1) {
+			if day.Weekday()%2 == 0 {
+				continue
+			}
+			date := day.Format(DateLayout)
+			batch = append(batch, withDate(pushups, date), withDate(squats, date), withDate(hold, date), withDate(hold, date))
+		}
+		if _, err := f.AppendBatch(context.Background(), batch); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return f
+}
+
+// BenchmarkAppendSession logs a session of four entries to a ten-year log,
+// as one batch and as one append each. The batch copies the year file to
+// replace it through the rewrite journal, so it grows with the year file;
+// its gain is all-or-nothing, and one request on Sheets.
+func BenchmarkAppendSession(b *testing.B) {
+	session := []WorkoutEntry{pushups, squats, withDate(pushups, "2026-03-05"), withDate(squats, "2026-03-05")}
+	ctx := context.Background()
+	b.Run("AppendBatch", func(b *testing.B) {
+		f := largeLog(b, 10)
+		b.ResetTimer()
+		for range b.N {
+			if _, err := f.AppendBatch(ctx, session); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Append", func(b *testing.B) {
+		f := largeLog(b, 10)
+		b.ResetTimer()
+		for range b.N {
+			for _, entry := range session {
+				if _, err := f.Append(ctx, entry); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+// TestReadLogFilesOrder reads a ten-year log with different numbers of
+// parallel reads, many times over, and checks every read returns the
+// entries in file order.
+func TestReadLogFilesOrder(t *testing.T) {
+	f := largeLog(t, 10)
+	files, err := f.yearFiles("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []WorkoutEntry
+	for _, file := range files {
+		entries, err := readLogFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, entries...)
+	}
+
+	saved := maxParallelReads
+	t.Cleanup(func() { maxParallelReads = saved })
+	for _, parallel := range []int{1, 3, 16} {
+		maxParallelReads = parallel
+		for range 10 {
+			got, err := f.All(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("%d parallel reads: All differs from reading the files in order", parallel)
+			}
+		}
+	}
+
+	// The error of the first failing file, by order, wins.
+	missing := []string{files[0], files[1] + ".gone", files[2] + ".gone", files[3]}
+	for range 20 {
+		if _, err := readLogFiles(context.Background(), missing); !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), missing[1]) {
+			t.Fatalf("readLogFiles = %v, want the error of %s", err, missing[1])
+		}
+	}
+}
+
+// BenchmarkAll reads a ten-year log one year file at a time and four at a
+// time, the most maxParallelReads allows.
+func BenchmarkAll(b *testing.B) {
+	f := largeLog(b, 10)
+	saved := maxParallelReads
+	b.Cleanup(func() { maxParallelReads = saved })
+	for _, parallel := range []int{1, 4} {
+		b.Run(fmt.Sprintf("parallel=%d", parallel), func(b *testing.B) {
+			maxParallelReads = parallel
+			for range b.N {
+				if _, err := f.All(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestFileRecentYears checks Recent tops this year's entries up from last
+// year's, as early in January, and reads no further back.
+func TestFileRecentYears(t *testing.T) {
+	ctx := context.Background()
+	f := NewFileStorage(t.TempDir())
+	f.Now = func() time.Time { return time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC) }
+	for _, date := range []string{"2024-12-30", "2025-12-29", "2025-12-31", "2026-01-02"} {
+		if _, err := f.Append(ctx, withDate(pushups, date)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dates := func(entries []WorkoutEntry) string {
+		var got []string
+		for _, entry := range entries {
+			got = append(got, entry.Date)
+		}
+		return strings.Join(got, " ")
+	}
+	for limit, want := range map[int]string{
+		1:  "2026-01-02",
+		2:  "2025-12-31 2026-01-02",
+		10: "2025-12-29 2025-12-31 2026-01-02",
+	} {
+		if got, err := f.Recent(ctx, limit); err != nil || dates(got) != want {
+			t.Errorf("Recent(%d) = %s, %v; want %s", limit, dates(got), err, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package calio
+
+import "time"
+
+// FutureSlackDays is how far ahead of the clock an entry may be dated and
+// still count: tomorrow is fine, since logging across timezones can land on
+// the next date. Anything later usually means a machine with a wrong clock.
+const FutureSlackDays = 1
+
+// FutureDated reports whether date lies more than FutureSlackDays after
+// now's calendar date in now's location. Unparseable dates are not
+// future-dated.
+func FutureDated(date string, now time.Time) bool {
+	day, err := time.ParseInLocation(DateLayout, date, now.Location())
+	if err != nil {
+		return false
+	}
+	year, month, d := now.Date()
+	today := time.Date(year, month, d, 0, 0, 0, 0, now.Location())
+	return day.After(today.AddDate(0, 0, FutureSlackDays))
+}
+
+// WithoutFuture returns entries minus the future-dated ones, keeping order.
+// Every analytic goes through it so one bad date can't skew the rotation or
+// the stats.
+func WithoutFuture(entries []WorkoutEntry, now time.Time) []WorkoutEntry {
+	kept := make([]WorkoutEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !FutureDated(entry.Date, now) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// FutureEntries returns only the future-dated entries.
+func FutureEntries(entries []WorkoutEntry, now time.Time) []WorkoutEntry {
+	var future []WorkoutEntry
+	for _, entry := range entries {
+		if FutureDated(entry.Date, now) {
+			future = append(future, entry)
+		}
+	}
+	return future
+}
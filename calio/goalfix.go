@@ -0,0 +1,172 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// GoalFix sets the Goal stored with Entry, as it was read, to Goal.
+type GoalFix struct {
+	Entry WorkoutEntry
+	Goal  string
+}
+
+// GoalRewriter is implemented by backends that can correct the goal stored
+// with entries in place, e.g. after a goal in the dataset changed. Every row
+// is checked to still hold its entry first; if any doesn't, nothing is
+// written.
+type GoalRewriter interface {
+	RewriteGoals(ctx context.Context, fixes []GoalFix) error
+}
+
+// ErrNoGoalRewriter is returned when the storage can't rewrite stored goals.
+var ErrNoGoalRewriter = errors.New("this storage can't rewrite stored goals")
+
+// errChanged reports a row that no longer holds the entry it was read with.
+func errChanged(entry WorkoutEntry) error {
+	return fmt.Errorf("the log changed since it was read (%s %s - %s is no longer on row %d); nothing was changed",
+		entry.Date, entry.Exercise, entry.Level, entry.RowIndex+1)
+}
+
+// goalColumn is the index of the Goal field in a log line and of column F.
+const goalColumn = 5
+
+// RewriteGoals rewrites the Goal field of each fix's line in its year file.
+// The year files are replaced together through the rewrite journal, once
+// every line is checked.
+func (f *FileStorage) RewriteGoals(ctx context.Context, fixes []GoalFix) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	byFile := map[string][]GoalFix{}
+	var files []string
+	for _, fix := range fixes {
+		file := f.FileFor(fix.Entry.Date)
+		if _, ok := byFile[file]; !ok {
+			files = append(files, file)
+		}
+		byFile[file] = append(byFile[file], fix)
+	}
+	contents := map[string]string{}
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		text, err := rewriteGoalLines(file, byFile[file])
+		if err != nil {
+			return err
+		}
+		contents[file] = text
+	}
+	return f.rewriteFiles(ctx, files, contents)
+}
+
+// rewriteGoalLines returns the contents of logFile with fixes applied.
+func rewriteGoalLines(logFile string, fixes []GoalFix) (string, error) {
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for _, fix := range fixes {
+		row := fix.Entry.RowIndex
+		if row < 0 || row >= int64(len(lines)) {
+			return "", errChanged(fix.Entry)
+		}
+		entry, ok := parseLogLine(strings.TrimSpace(lines[row]))
+		entry.RowIndex = row
+		if !ok || !sameEntry(entry, fix.Entry) {
+			return "", errChanged(fix.Entry)
+		}
+		parts := strings.Split(lines[row], "|")
+		parts[goalColumn] = restFields.Replace(fix.Goal)
+		lines[row] = strings.Join(parts, "|")
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// goalFixBatch caps the ranges sent per Values.BatchUpdate request.
+const goalFixBatch = 500
+
+// goalUpdates returns the single-cell ranges setting the Goal column of
+// layout (F unless moved) in each fix's row of tab, after checking against
+// current, the tab as read now, that the rows still hold the entries.
+func goalUpdates(tab string, layout columnLayout, fixes []GoalFix, current []WorkoutEntry) ([]*sheets.ValueRange, error) {
+	byRow := map[int64]WorkoutEntry{}
+	for _, entry := range current {
+		byRow[entry.RowIndex] = entry
+	}
+	var updates []*sheets.ValueRange
+	for _, fix := range fixes {
+		entry, ok := byRow[fix.Entry.RowIndex]
+		if !ok || !sameEntry(entry, fix.Entry) {
+			return nil, errChanged(fix.Entry)
+		}
+		updates = append(updates, &sheets.ValueRange{
+			Range:  a1Range(tab, fmt.Sprintf("%s%d", columnName(layout[fieldGoal]), fix.Entry.RowIndex+1)),
+			Values: [][]interface{}{{fix.Goal}},
+		})
+	}
+	return updates, nil
+}
+
+// RewriteGoals re-reads the tabs of the fixes, checks them and sets the Goal
+// cells in batches of goalFixBatch. RAW keeps goals such as "5x2" as text.
+func (s *SheetsStorage) RewriteGoals(ctx context.Context, fixes []GoalFix) error {
+	byTab := map[string][]GoalFix{}
+	var tabs []string
+	for _, fix := range fixes {
+		tab := s.TabFor(fix.Entry.Date)
+		if _, ok := byTab[tab]; !ok {
+			tabs = append(tabs, tab)
+		}
+		byTab[tab] = append(byTab[tab], fix)
+	}
+
+	var updates []*sheets.ValueRange
+	for _, tab := range tabs {
+		current, err := s.readTabs(ctx, []string{tab})
+		if err != nil {
+			return fmt.Errorf("re-reading sheet: %w", err)
+		}
+		layout, err := s.layoutFor(ctx, tab)
+		if err != nil {
+			return err
+		}
+		tabUpdates, err := goalUpdates(tab, layout, byTab[tab], current)
+		if err != nil {
+			return err
+		}
+		updates = append(updates, tabUpdates...)
+	}
+
+	started := time.Now()
+	for len(updates) > 0 {
+		batch := updates[:min(goalFixBatch, len(updates))]
+		updates = updates[len(batch):]
+		_, err := s.svc.Spreadsheets.Values.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateValuesRequest{
+			ValueInputOption: "RAW",
+			Data:             batch,
+		}).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+	}
+	s.logf("Rewrote %d goal cell(s) in %s\n", len(fixes), time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// RewriteGoals forwards to the underlying storage; fixes come from entries
+// read through u, so they only touch the entries it shows.
+func (u *UserStorage) RewriteGoals(ctx context.Context, fixes []GoalFix) error {
+	rewriter, ok := u.Storage.(GoalRewriter)
+	if !ok {
+		return ErrNoGoalRewriter
+	}
+	return rewriter.RewriteGoals(ctx, fixes)
+}
@@ -0,0 +1,147 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGoalUpdates(t *testing.T) {
+	current := []WorkoutEntry{readAt(pushups, 1), readAt(squats, 2), readAt(withDate(pushups, "2026-03-05"), 3)}
+	fixes := []GoalFix{{Entry: current[2], Goal: "25x2"}, {Entry: current[0], Goal: "25x2"}}
+	updates, err := goalUpdates("Log's", standardLayout, fixes, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, update := range updates {
+		got = append(got, fmt.Sprintf("%s=%v", update.Range, update.Values))
+	}
+	if want := "'Log''s'!F4=[[25x2]] 'Log''s'!F2=[[25x2]]"; strings.Join(got, " ") != want {
+		t.Errorf("the updates are %s, want %s", strings.Join(got, " "), want)
+	}
+
+	// A tab with the goal moved to column B by its header.
+	moved := standardLayout
+	moved[fieldGoal], moved[fieldDay] = fieldDay, fieldGoal
+	if updates, err := goalUpdates("Log", moved, fixes[1:], current); err != nil || updates[0].Range != "'Log'!B2" {
+		t.Errorf("with the goal in column B the update is %v, %v", updates, err)
+	}
+
+	stale := current[0]
+	stale.RepsSets = "15x2"
+	for _, fix := range []GoalFix{{Entry: stale}, {Entry: readAt(pushups, 9)}} {
+		if _, err := goalUpdates("Log", standardLayout, []GoalFix{fixes[0], fix}, current); err == nil || !strings.Contains(err.Error(), "nothing was changed") {
+			t.Errorf("a fix for a row that changed gave %v", err)
+		}
+	}
+}
+
+// TestSheetsRewriteGoals rewrites more goals than fit one request and
+// leaves every other cell alone.
+func TestSheetsRewriteGoals(t *testing.T) {
+	ctx := context.Background()
+	stale := pushups
+	stale.Goal = "25x2"
+	rows := [][]string{logRow(squats)}
+	for range goalFixBatch*2 + 1 {
+		rows = append(rows, logRow(stale))
+	}
+	f := newFakeSheets()
+	f.setRows("Log", rows...)
+	s := f.mustStorage(t, SheetsConfig{})
+	all, err := s.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixes []GoalFix
+	for _, entry := range all[1:] {
+		fixes = append(fixes, GoalFix{Entry: entry, Goal: pushups.Goal})
+	}
+	clear(f.calls)
+	if err := s.RewriteGoals(ctx, fixes); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.calls["POST values:batchUpdate"]; n != 3 {
+		t.Errorf("%d goals were sent in %d requests, want 3", len(fixes), n)
+	}
+	after := f.rows("Log")
+	if strings.Join(after[0], "|") != strings.Join(logRow(squats), "|") {
+		t.Errorf("the row without a fix is now %q", after[0])
+	}
+	for i, row := range after[1:] {
+		if strings.Join(row, "|") != strings.Join(logRow(pushups), "|") {
+			t.Fatalf("row %d is %q after the rewrite, want only its goal changed", i+2, row)
+		}
+	}
+}
+
+func TestSheetsRewriteGoalsAfterEdit(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeSheets()
+	f.setRows("Log", logRow(squats), logRow(pushups))
+	s := f.mustStorage(t, SheetsConfig{})
+	all, err := s.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.insertRow("Log", 0, logRow(withDate(squats, "2026-03-03")))
+	clear(f.calls)
+	err = s.RewriteGoals(ctx, []GoalFix{{Entry: all[0], Goal: "40x2"}, {Entry: all[1], Goal: "25x2"}})
+	if err == nil || f.calls["POST values:batchUpdate"] != 0 {
+		t.Errorf("rewriting after a row was inserted gave %v and wrote %d time(s)", err, f.calls["POST values:batchUpdate"])
+	}
+}
+
+func TestFileRewriteGoals(t *testing.T) {
+	ctx := context.Background()
+	f := NewFileStorage(t.TempDir())
+	stale := pushups
+	stale.Goal = "25x2"
+	for _, entry := range []WorkoutEntry{stale, squats, withDate(stale, "2025-12-30")} {
+		if _, err := f.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := fileContents(t, f)
+	all, err := f.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edited := all[1]
+	edited.RepsSets = "10x2"
+	err = f.RewriteGoals(ctx, []GoalFix{{Entry: all[0], Goal: "20x2"}, {Entry: edited, Goal: "20x2"}})
+	if err == nil || !sameContents(fileContents(t, f), before) {
+		t.Fatalf("a fix for a changed line gave %v, want the files left as they were", err)
+	}
+
+	if err := f.RewriteGoals(ctx, []GoalFix{{Entry: all[0], Goal: "20x2"}, {Entry: all[1], Goal: "20x | 2"}}); err != nil {
+		t.Fatal(err)
+	}
+	after, err := f.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after[0].Goal != "20x2" || after[1].Goal != "20x / 2" || after[2].Goal != squats.Goal {
+		t.Errorf("the goals are %q, %q and %q after the rewrite", after[0].Goal, after[1].Goal, after[2].Goal)
+	}
+	for i := range after {
+		after[i].Goal = all[i].Goal
+		if !sameEntry(after[i], all[i]) {
+			t.Errorf("the rewrite changed more than the goal: %+v, was %+v", after[i], all[i])
+		}
+	}
+	if leftover := leftovers(t, f); len(leftover) != 0 {
+		t.Errorf("the rewrite left %v behind", leftover)
+	}
+}
+
+func TestUserStorageRewriteGoals(t *testing.T) {
+	u := &UserStorage{Storage: struct{ Storage }{NewFileStorage(t.TempDir())}}
+	if err := u.RewriteGoals(context.Background(), nil); !errors.Is(err, ErrNoGoalRewriter) {
+		t.Errorf("RewriteGoals over storage without it = %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// GoalOverride replaces the built-in goal of one level, e.g. a lower Pushups
+// goal while a shoulder heals. An empty Goal removes the override.
+type GoalOverride struct {
+	Exercise string
+	Level    string
+	Goal     string
+	User     string // as WorkoutEntry.User
+}
+
+// GoalStore is implemented by backends that keep goal overrides with the
+// data, in goals.log next to the year files or a "<SheetName> Goals" tab.
+// Both are append-only: each SetGoal adds a record and the latest record per
+// user, exercise and level wins, so the history of a goal stays readable.
+type GoalStore interface {
+	// SetGoal records override; an empty Goal unsets it.
+	SetGoal(ctx context.Context, override GoalOverride) error
+	// GoalOverrides returns the overrides in effect, in exercise and level
+	// order.
+	GoalOverrides(ctx context.Context) ([]GoalOverride, error)
+}
+
+// ErrNoGoalStore is returned when the storage can't keep goal overrides.
+var ErrNoGoalStore = errors.New("this storage can't keep goal overrides")
+
+// effectiveGoals folds records, oldest first, into the overrides in effect.
+func effectiveGoals(records []GoalOverride) []GoalOverride {
+	type key struct{ user, exercise, level string }
+	latest := map[key]GoalOverride{}
+	for _, record := range records {
+		latest[key{record.User, record.Exercise, record.Level}] = record
+	}
+
+	var overrides []GoalOverride
+	for _, override := range latest {
+		if override.Goal != "" {
+			overrides = append(overrides, override)
+		}
+	}
+	exerciseIndex := func(name string) int {
+		if i := slices.Index(exercises, name); i >= 0 {
+			return i
+		}
+		return len(exercises) + slices.Index(mobilityExercises, name)
+	}
+	slices.SortFunc(overrides, func(a, b GoalOverride) int {
+		if c := exerciseIndex(a.Exercise) - exerciseIndex(b.Exercise); c != 0 {
+			return c
+		}
+		if c := slices.Index(levelOrder[a.Exercise], a.Level) - slices.Index(levelOrder[b.Exercise], b.Level); c != 0 {
+			return c
+		}
+		return strings.Compare(a.User, b.User)
+	})
+	return overrides
+}
+
+func (f *FileStorage) goalFile() string {
+	return filepath.Join(f.logDir, "goals.log")
+}
+
+// SetGoal appends override to goals.log as "exercise|level|goal|user".
+func (f *FileStorage) SetGoal(ctx context.Context, override GoalOverride) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.logDir, 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.goalFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(file, "%s|%s|%s|%s\n", override.Exercise, override.Level,
+		restFields.Replace(override.Goal), restFields.Replace(override.User))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// GoalOverrides reads goals.log; a missing file has none.
+func (f *FileStorage) GoalOverrides(ctx context.Context) ([]GoalOverride, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(f.goalFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []GoalOverride
+	scanner := newLogScanner(file)
+	for scanner.Scan() {
+		parts := strings.Split(strings.TrimSpace(scanner.Text()), "|")
+		if len(parts) < 3 || parts[0] == "" {
+			continue
+		}
+		record := GoalOverride{
+			Exercise: strings.TrimSpace(parts[0]),
+			Level:    strings.TrimSpace(parts[1]),
+			Goal:     strings.TrimSpace(parts[2]),
+		}
+		if len(parts) > 3 {
+			record.User = strings.TrimSpace(parts[3])
+		}
+		records = append(records, record)
+	}
+	return effectiveGoals(records), scanner.Err()
+}
+
+// goalHeader is the first row of the goals tab.
+var goalHeader = []interface{}{"Exercise", "Level", "Goal", "User"}
+
+func (s *SheetsStorage) goalTab() string {
+	return s.sheetName + " Goals"
+}
+
+// SetGoal appends override to the goals tab, creating the tab on first use.
+func (s *SheetsStorage) SetGoal(ctx context.Context, override GoalOverride) error {
+	tab := s.goalTab()
+	if _, err := s.addTab(ctx, tab, goalHeader); err != nil {
+		return err
+	}
+
+	// RAW keeps goals such as "5x2" from being read as something else.
+	row := []interface{}{override.Exercise, override.Level, override.Goal, override.User}
+	_, err := s.svc.Spreadsheets.Values.Append(
+		s.spreadsheetID,
+		a1Range(tab, "A:D"),
+		&sheets.ValueRange{Values: [][]interface{}{row}},
+	).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+	return err
+}
+
+// GoalOverrides reads the goals tab in one request. A missing tab has none.
+func (s *SheetsStorage) GoalOverrides(ctx context.Context) ([]GoalOverride, error) {
+	tab := s.goalTab()
+	if _, ok := s.meta.tab(tab); !ok {
+		return nil, nil
+	}
+	resp, err := s.svc.Spreadsheets.Values.Get(s.spreadsheetID, a1Range(tab, "A:D")).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	var records []GoalOverride
+	for i, row := range resp.Values {
+		record := GoalOverride{
+			Exercise: strings.TrimSpace(valueAt(row, 0)),
+			Level:    strings.TrimSpace(valueAt(row, 1)),
+			Goal:     strings.TrimSpace(valueAt(row, 2)),
+			User:     strings.TrimSpace(valueAt(row, 3)),
+		}
+		if record.Exercise == "" || (i == 0 && strings.EqualFold(record.Exercise, "exercise")) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return effectiveGoals(records), nil
+}
+
+// SetGoal stamps override with Author and records it in the underlying
+// storage.
+func (u *UserStorage) SetGoal(ctx context.Context, override GoalOverride) error {
+	goals, ok := u.Storage.(GoalStore)
+	if !ok {
+		return ErrNoGoalStore
+	}
+	if override.User == "" {
+		override.User = u.Author
+	}
+	return goals.SetGoal(ctx, override)
+}
+
+// GoalOverrides returns Show's overrides, with the same rule for overrides
+// without a user as entries.
+func (u *UserStorage) GoalOverrides(ctx context.Context) ([]GoalOverride, error) {
+	goals, ok := u.Storage.(GoalStore)
+	if !ok {
+		return nil, ErrNoGoalStore
+	}
+	overrides, err := goals.GoalOverrides(ctx)
+	if err != nil || u.Show == "" {
+		return overrides, err
+	}
+	var visible []GoalOverride
+	for _, override := range overrides {
+		if override.User == u.Show || (u.Unattributed && override.User == "") {
+			visible = append(visible, override)
+		}
+	}
+	return visible, nil
+}
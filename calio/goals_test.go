@@ -0,0 +1,77 @@
+package calio
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestEffectiveGoals(t *testing.T) {
+	records := []GoalOverride{
+		{Exercise: "Squats", Level: "Full", Goal: "25x2"},
+		{Exercise: "Pushups", Level: "Full", Goal: "15x2"},
+		{Exercise: "Pushups", Level: "Half", Goal: "20x2"},
+		{Exercise: "Pushups", Level: "Full", Goal: "12x2"}, // the latest wins
+		{Exercise: "Pushups", Level: "Half", Goal: ""},     // unset
+		{Exercise: "Pushups", Level: "Full", Goal: "18x2", User: "sam"},
+		{Exercise: "L-Sit", Level: "Tuck", Goal: "45s"},
+		{Exercise: "Pushups", Level: "Wall", Goal: "40x3"},
+	}
+	want := []GoalOverride{
+		{Exercise: "Pushups", Level: "Wall", Goal: "40x3"},
+		{Exercise: "Pushups", Level: "Full", Goal: "12x2"},
+		{Exercise: "Pushups", Level: "Full", Goal: "18x2", User: "sam"},
+		{Exercise: "Squats", Level: "Full", Goal: "25x2"},
+		{Exercise: "L-Sit", Level: "Tuck", Goal: "45s"},
+	}
+	if got := effectiveGoals(records); !slices.Equal(got, want) {
+		t.Errorf("effectiveGoals = %+v, want %+v", got, want)
+	}
+}
+
+// TestGoalStore sets, changes and unsets goals on both backends and reads
+// them back from a storage opened afresh.
+func TestGoalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	fake := newFakeSheets("Log")
+	backends := []struct {
+		name string
+		open func() Storage
+	}{
+		{"file", func() Storage { return NewFileStorage(dir) }},
+		{"sheets", func() Storage { return fake.mustStorage(t, SheetsConfig{}) }},
+	}
+	for _, backend := range backends {
+		goals := backend.open().(GoalStore)
+		for _, override := range []GoalOverride{
+			{Exercise: "Pushups", Level: "Full", Goal: "15x2"},
+			{Exercise: "Pushups", Level: "Full", Goal: "12x2"},
+			{Exercise: "Squats", Level: "Full", Goal: "25x2", User: "sam"},
+			{Exercise: "Pullups", Level: "Full", Goal: "8x2"},
+			{Exercise: "Pullups", Level: "Full"},
+		} {
+			if err := goals.SetGoal(ctx, override); err != nil {
+				t.Fatalf("%s: %v", backend.name, err)
+			}
+		}
+		got, err := backend.open().(GoalStore).GoalOverrides(ctx)
+		want := []GoalOverride{{Exercise: "Pushups", Level: "Full", Goal: "12x2"}, {Exercise: "Squats", Level: "Full", Goal: "25x2", User: "sam"}}
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("%s: GoalOverrides = %+v, %v, want %+v", backend.name, got, err, want)
+		}
+
+		ziad := &UserStorage{Storage: backend.open(), Author: "ziad", Show: "ziad", Unattributed: true}
+		if err := ziad.SetGoal(ctx, GoalOverride{Exercise: "Bridges", Level: "Full", Goal: "10x2"}); err != nil {
+			t.Fatal(err)
+		}
+		got, err = ziad.GoalOverrides(ctx)
+		want = []GoalOverride{{Exercise: "Pushups", Level: "Full", Goal: "12x2"}, {Exercise: "Bridges", Level: "Full", Goal: "10x2", User: "ziad"}}
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("%s: ziad's GoalOverrides = %+v, %v, want %+v", backend.name, got, err, want)
+		}
+	}
+	if entries, err := NewFileStorage(dir).All(ctx); err != nil || len(entries) != 0 {
+		t.Errorf("goals.log reads as workouts: %+v, %v", entries, err)
+	}
+}
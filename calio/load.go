@@ -0,0 +1,86 @@
+package calio
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Units a Load is given in.
+const (
+	LoadKg = "kg"
+	LoadLb = "lb"
+)
+
+// kgPerLb converts pounds to kilograms.
+const kgPerLb = 0.45359237
+
+// loadUnits maps the unit spellings ParseLoad accepts to LoadKg and LoadLb.
+var loadUnits = map[string]string{
+	"kg":        LoadKg,
+	"kgs":       LoadKg,
+	"kilo":      LoadKg,
+	"kilos":     LoadKg,
+	"kilogram":  LoadKg,
+	"kilograms": LoadKg,
+	"lb":        LoadLb,
+	"lbs":       LoadLb,
+	"pound":     LoadLb,
+	"pounds":    LoadLb,
+}
+
+// Load is weight added to a bodyweight exercise, such as a vest or a belt
+// for dips. The zero Load is no load.
+type Load struct {
+	Amount float64
+	Unit   string // LoadKg or LoadLb
+}
+
+// ParseLoad reads a load as people type it: "+10kg", "10 kg", "22.5lbs" or
+// "7,5 kilos". A bare number is in defaultUnit. An empty value, "0" and
+// "none" are no load. ok is false for anything else, negative amounts
+// included: assisted work isn't a load.
+func ParseLoad(value, defaultUnit string) (load Load, ok bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "none" {
+		return Load{}, true
+	}
+	value = strings.TrimSpace(strings.TrimPrefix(value, "+"))
+	split := strings.IndexFunc(value, func(r rune) bool { return unicode.IsLetter(r) })
+	number, unit := value, defaultUnit
+	if split >= 0 {
+		number, unit = strings.TrimSpace(value[:split]), loadUnits[value[split:]]
+		if unit == "" {
+			return Load{}, false
+		}
+	}
+	amount, err := strconv.ParseFloat(strings.Replace(number, ",", ".", 1), 64)
+	if err != nil || amount < 0 {
+		return Load{}, false
+	}
+	if amount == 0 {
+		return Load{}, true
+	}
+	if unit != LoadLb {
+		unit = LoadKg
+	}
+	return Load{Amount: amount, Unit: unit}, true
+}
+
+// String renders the load as it is stored, e.g. "+10kg" or "+22.5lb", and
+// no load as "".
+func (l Load) String() string {
+	if l.Amount <= 0 {
+		return ""
+	}
+	return "+" + strconv.FormatFloat(l.Amount, 'f', -1, 64) + l.Unit
+}
+
+// Kilograms returns the load in kilograms, whatever unit it was given in, so
+// loads logged in different units compare.
+func (l Load) Kilograms() float64 {
+	if l.Unit == LoadLb {
+		return l.Amount * kgPerLb
+	}
+	return l.Amount
+}
@@ -0,0 +1,103 @@
+package calio
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestParseLoad(t *testing.T) {
+	tests := []struct {
+		value, unit string
+		want        Load
+		ok          bool
+	}{
+		{"+10kg", LoadKg, Load{10, LoadKg}, true},
+		{"10 kg", LoadLb, Load{10, LoadKg}, true},
+		{"22.5lbs", LoadKg, Load{22.5, LoadLb}, true},
+		{"7,5 kilos", LoadKg, Load{7.5, LoadKg}, true},
+		{" + 20 Pounds ", LoadKg, Load{20, LoadLb}, true},
+		// A bare number is in the configured unit.
+		{"10", LoadKg, Load{10, LoadKg}, true},
+		{"10", LoadLb, Load{10, LoadLb}, true},
+		{"10", "", Load{10, LoadKg}, true},
+		// No load.
+		{"", LoadKg, Load{}, true},
+		{"none", LoadKg, Load{}, true},
+		{"0", LoadKg, Load{}, true},
+		{"+0kg", LoadKg, Load{}, true},
+		// Assistance, other units and junk aren't loads.
+		{"-10kg", LoadKg, Load{}, false},
+		{"10 stone", LoadKg, Load{}, false},
+		{"vest", LoadKg, Load{}, false},
+		{"10kg vest", LoadKg, Load{}, false},
+		{"1.2.3kg", LoadKg, Load{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseLoad(tt.value, tt.unit)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseLoad(%q, %q) = %+v, %v; want %+v, %v", tt.value, tt.unit, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLoadString(t *testing.T) {
+	tests := []struct {
+		load Load
+		want string
+		kg   float64
+	}{
+		{Load{}, "", 0},
+		{Load{10, LoadKg}, "+10kg", 10},
+		{Load{22.5, LoadLb}, "+22.5lb", 22.5 * kgPerLb},
+		{Load{Amount: 5}, "+5", 5},
+	}
+	for _, tt := range tests {
+		if got := tt.load.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.load, got, tt.want)
+		}
+		if got := tt.load.Kilograms(); math.Abs(got-tt.kg) > 1e-9 {
+			t.Errorf("%+v.Kilograms() = %v, want %v", tt.load, got, tt.kg)
+		}
+	}
+	// What is stored parses back to the same load.
+	for _, value := range []string{"+10kg", "+22.5lb", "+0.5kg"} {
+		load, ok := ParseLoad(value, LoadKg)
+		if !ok || load.String() != value {
+			t.Errorf("%q reads back as %q", value, load.String())
+		}
+	}
+}
+
+// TestLoadRoundTrip checks the load is kept by both backends, and entries
+// without one read as unloaded.
+func TestLoadRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSheets(DefaultSheetName)
+	backends := map[string]Storage{
+		"file":   NewFileStorage(t.TempDir()),
+		"sheets": fake.mustStorage(t, SheetsConfig{}),
+	}
+	for name, storage := range backends {
+		loaded := squats
+		loaded.Load = "+10kg"
+		for _, entry := range []WorkoutEntry{loaded, pushups} {
+			if _, err := storage.Append(ctx, entry); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		entries, err := storage.All(ctx)
+		if err != nil || len(entries) != 2 {
+			t.Fatalf("%s: All = %+v, %v", name, entries, err)
+		}
+		if entries[0].Load != "+10kg" || entries[1].Load != "" {
+			t.Errorf("%s: loads read back as %q and %q", name, entries[0].Load, entries[1].Load)
+		}
+	}
+	for _, row := range fake.rows(DefaultSheetName) {
+		if !slices.Contains(row, "+10kg") && row[0] == squats.Date && row[2] == squats.Exercise {
+			t.Errorf("the sheet row %q has no load", row)
+		}
+	}
+}
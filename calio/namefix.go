@@ -0,0 +1,183 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// NameFix sets the exercise and level stored with Entry, as it was read, to
+// Exercise and Level.
+type NameFix struct {
+	Entry    WorkoutEntry
+	Exercise string
+	Level    string
+}
+
+// NameFixFor returns the fix that stores entry's names as Canonical spells
+// them; ok is false when they already are.
+func NameFixFor(entry WorkoutEntry) (fix NameFix, ok bool) {
+	canonical := Canonical(entry)
+	if canonical.Exercise == entry.Exercise && canonical.Level == entry.Level {
+		return NameFix{}, false
+	}
+	return NameFix{Entry: entry, Exercise: canonical.Exercise, Level: canonical.Level}, true
+}
+
+// NameRewriter is implemented by backends that can correct the exercise and
+// level names stored with entries in place, e.g. rows typed by hand as
+// "FULL" rather than "Full". Every row is checked to still hold its entry
+// first; if any doesn't, nothing is written.
+type NameRewriter interface {
+	RewriteNames(ctx context.Context, fixes []NameFix) error
+}
+
+// ErrNoNameRewriter is returned when the storage can't rewrite stored names.
+var ErrNoNameRewriter = errors.New("this storage can't rewrite stored names")
+
+// RewriteNames rewrites the Exercise and Level fields of each fix's line in
+// its year file. The year files are replaced together through the rewrite
+// journal, once every line is checked.
+func (f *FileStorage) RewriteNames(ctx context.Context, fixes []NameFix) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	byFile := map[string][]NameFix{}
+	var files []string
+	for _, fix := range fixes {
+		file := f.FileFor(fix.Entry.Date)
+		if _, ok := byFile[file]; !ok {
+			files = append(files, file)
+		}
+		byFile[file] = append(byFile[file], fix)
+	}
+	contents := map[string]string{}
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		text, err := rewriteNameLines(file, byFile[file])
+		if err != nil {
+			return err
+		}
+		contents[file] = text
+	}
+	return f.rewriteFiles(ctx, files, contents)
+}
+
+// rewriteNameLines returns the contents of logFile with fixes applied.
+func rewriteNameLines(logFile string, fixes []NameFix) (string, error) {
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for _, fix := range fixes {
+		row := fix.Entry.RowIndex
+		if row < 0 || row >= int64(len(lines)) {
+			return "", errChanged(fix.Entry)
+		}
+		entry, ok := parseLogLine(strings.TrimSpace(lines[row]))
+		entry.RowIndex = row
+		if !ok || !sameEntry(entry, fix.Entry) {
+			return "", errChanged(fix.Entry)
+		}
+		parts := strings.Split(lines[row], "|")
+		parts[fieldExercise] = fix.Exercise
+		parts[fieldLevel] = fix.Level
+		lines[row] = strings.Join(parts, "|")
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// nameUpdates returns the single-cell ranges setting the Exercise and Level
+// columns of layout that change in each fix's row of tab, after checking
+// against current, the tab as read now, that the rows still hold the
+// entries.
+func nameUpdates(tab string, layout columnLayout, fixes []NameFix, current []WorkoutEntry) ([]*sheets.ValueRange, error) {
+	byRow := map[int64]WorkoutEntry{}
+	for _, entry := range current {
+		byRow[entry.RowIndex] = entry
+	}
+	cell := func(field int, row int64, value string) *sheets.ValueRange {
+		return &sheets.ValueRange{
+			Range:  a1Range(tab, fmt.Sprintf("%s%d", columnName(layout[field]), row+1)),
+			Values: [][]interface{}{{value}},
+		}
+	}
+	var updates []*sheets.ValueRange
+	for _, fix := range fixes {
+		entry, ok := byRow[fix.Entry.RowIndex]
+		if !ok || !sameEntry(entry, fix.Entry) {
+			return nil, errChanged(fix.Entry)
+		}
+		if fix.Exercise != entry.Exercise {
+			updates = append(updates, cell(fieldExercise, fix.Entry.RowIndex, fix.Exercise))
+		}
+		if fix.Level != entry.Level {
+			updates = append(updates, cell(fieldLevel, fix.Entry.RowIndex, fix.Level))
+		}
+	}
+	return updates, nil
+}
+
+// RewriteNames re-reads the tabs of the fixes, checks them and sets the
+// changed cells in batches of goalFixBatch.
+func (s *SheetsStorage) RewriteNames(ctx context.Context, fixes []NameFix) error {
+	byTab := map[string][]NameFix{}
+	var tabs []string
+	for _, fix := range fixes {
+		tab := s.TabFor(fix.Entry.Date)
+		if _, ok := byTab[tab]; !ok {
+			tabs = append(tabs, tab)
+		}
+		byTab[tab] = append(byTab[tab], fix)
+	}
+
+	var updates []*sheets.ValueRange
+	for _, tab := range tabs {
+		current, err := s.readTabs(ctx, []string{tab})
+		if err != nil {
+			return fmt.Errorf("re-reading sheet: %w", err)
+		}
+		layout, err := s.layoutFor(ctx, tab)
+		if err != nil {
+			return err
+		}
+		tabUpdates, err := nameUpdates(tab, layout, byTab[tab], current)
+		if err != nil {
+			return err
+		}
+		updates = append(updates, tabUpdates...)
+	}
+
+	started := time.Now()
+	cells := len(updates)
+	for len(updates) > 0 {
+		batch := updates[:min(goalFixBatch, len(updates))]
+		updates = updates[len(batch):]
+		_, err := s.svc.Spreadsheets.Values.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateValuesRequest{
+			ValueInputOption: "RAW",
+			Data:             batch,
+		}).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+	}
+	s.logf("Rewrote %d name cell(s) in %s\n", cells, time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// RewriteNames forwards to the underlying storage; fixes come from entries
+// read through u, so they only touch the entries it shows.
+func (u *UserStorage) RewriteNames(ctx context.Context, fixes []NameFix) error {
+	rewriter, ok := u.Storage.(NameRewriter)
+	if !ok {
+		return ErrNoNameRewriter
+	}
+	return rewriter.RewriteNames(ctx, fixes)
+}
@@ -0,0 +1,179 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	for _, tc := range []struct{ exercise, level, wantExercise, wantLevel string }{
+		{"Pushups", "Full", "Pushups", "Full"},
+		{"pushups", "full", "Pushups", "Full"},
+		{" PUSHUPS ", "FULL ", "Pushups", "Full"},
+		{"leg raises", "knee tuck", "Leg Raises", "Knee Tuck"},
+		{"bridge hold", "straight", "Bridge Hold", "Straight"},
+		{"pushups", "diamond", "Pushups", "diamond"},
+		{"burpees", "full", "burpees", "full"},
+	} {
+		got := Canonical(WorkoutEntry{Exercise: tc.exercise, Level: tc.level, RepsSets: "10x2"})
+		if got.Exercise != tc.wantExercise || got.Level != tc.wantLevel || got.RepsSets != "10x2" {
+			t.Errorf("Canonical(%q, %q) = %q, %q, want %q, %q", tc.exercise, tc.level, got.Exercise, got.Level, tc.wantExercise, tc.wantLevel)
+		}
+	}
+	if _, ok := CanonicalLevel("pushups", "Full"); ok {
+		t.Errorf("CanonicalLevel took an exercise not spelled as the dataset does")
+	}
+
+	fix, ok := NameFixFor(WorkoutEntry{Exercise: "pushups", Level: "Full"})
+	if !ok || fix.Exercise != "Pushups" || fix.Level != "Full" || fix.Entry.Exercise != "pushups" {
+		t.Errorf("NameFixFor = %+v, %v", fix, ok)
+	}
+	for _, entry := range []WorkoutEntry{{Exercise: "Pushups", Level: "Full"}, {Exercise: "Burpees", Level: "FULL"}} {
+		if fix, ok := NameFixFor(entry); ok {
+			t.Errorf("NameFixFor(%s %s) = %+v", entry.Exercise, entry.Level, fix)
+		}
+	}
+}
+
+// TestAppendCanonical checks every write of both backends stores the
+// dataset's spelling, and leaves names it doesn't know as typed.
+func TestAppendCanonical(t *testing.T) {
+	ctx := context.Background()
+	typed := pushups
+	typed.Exercise, typed.Level = " pushups", "FULL "
+	unknown := squats
+	unknown.Exercise, unknown.Level = "Burpees", "any"
+	f := newFakeSheets("Log")
+	for name, storage := range map[string]Storage{
+		"file":   NewFileStorage(t.TempDir()),
+		"sheets": f.mustStorage(t, SheetsConfig{}),
+	} {
+		if _, err := storage.Append(ctx, typed); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := storage.AppendBatch(ctx, []WorkoutEntry{withDate(typed, "2026-03-05"), unknown}); err != nil {
+			t.Fatal(err)
+		}
+		all, err := storage.All(ctx)
+		if err != nil || len(all) != 3 {
+			t.Fatalf("%s: All = %+v, %v", name, all, err)
+		}
+		var got []string
+		for _, entry := range all {
+			got = append(got, entry.Exercise+"/"+entry.Level)
+		}
+		if want := "Pushups/Full Pushups/Full Burpees/any"; strings.Join(got, " ") != want {
+			t.Errorf("%s stored %s, want %s", name, strings.Join(got, " "), want)
+		}
+	}
+}
+
+// handTyped is a log with names typed by hand: two to fix, one already
+// right and two cali doesn't know.
+var handTyped = []WorkoutEntry{
+	{Date: "2026-03-04", Day: "A", Exercise: "pushups", Level: "FULL", RepsSets: "20x2", Goal: "20x2"},
+	{Date: "2026-03-04", Day: "A", Exercise: "Squats", Level: "Half", RepsSets: "35x2", Goal: "50x2"},
+	{Date: "2026-03-04", Day: "A", Exercise: "Squats", Level: "half", RepsSets: "30x2", Goal: "50x2"},
+	{Date: "2026-03-05", Day: "B", Exercise: "burpees", Level: "any", RepsSets: "10x2"},
+	{Date: "2026-03-05", Day: "B", Exercise: "Pullups", Level: "archer", RepsSets: "5x2"},
+}
+
+func nameFixes(entries []WorkoutEntry) []NameFix {
+	var fixes []NameFix
+	for _, entry := range entries {
+		if fix, ok := NameFixFor(entry); ok {
+			fixes = append(fixes, fix)
+		}
+	}
+	return fixes
+}
+
+func TestFileRewriteNames(t *testing.T) {
+	ctx := context.Background()
+	f := NewFileStorage(t.TempDir())
+	var log strings.Builder
+	for _, entry := range handTyped {
+		fmt.Fprintf(&log, "%s|%s|%s|%s|%s|%s|\n", entry.Date, entry.Day, entry.Exercise, entry.Level, entry.RepsSets, entry.Goal)
+	}
+	if err := os.WriteFile(f.FileFor("2026-03-04"), []byte(log.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	all, err := f.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixes := nameFixes(all)
+	if len(fixes) != 2 {
+		t.Fatalf("%d fixes for the hand-typed log, want 2", len(fixes))
+	}
+
+	before := fileContents(t, f)
+	edited := fixes[1]
+	edited.Entry.RepsSets = "31x2"
+	if err := f.RewriteNames(ctx, []NameFix{fixes[0], edited}); err == nil || !sameContents(fileContents(t, f), before) {
+		t.Fatalf("a fix for a changed line gave %v, want the file left as it was", err)
+	}
+
+	if err := f.RewriteNames(ctx, fixes); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(strings.Replace(log.String(), "|pushups|FULL|", "|Pushups|Full|", 1), "|half|30x2", "|Half|30x2", 1)
+	if got, err := os.ReadFile(f.FileFor("2026-03-04")); err != nil || string(got) != want {
+		t.Errorf("after the rewrite the log is\n%s\nwant\n%s", got, want)
+	}
+	if leftover := leftovers(t, f); len(leftover) != 0 {
+		t.Errorf("the rewrite left %v behind", leftover)
+	}
+}
+
+// TestSheetsRewriteNames fixes the hand-typed rows of a sheet in one batch
+// that sets only the cells that change.
+func TestSheetsRewriteNames(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeSheets()
+	typedRows := func() [][]string {
+		var rows [][]string
+		for _, entry := range handTyped {
+			rows = append(rows, logRow(entry))
+		}
+		return rows
+	}
+	f.setRows("Log", typedRows()...)
+	s := f.mustStorage(t, SheetsConfig{})
+	all, err := s.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixes := nameFixes(all)
+
+	f.insertRow("Log", 0, logRow(squats))
+	clear(f.calls)
+	if err := s.RewriteNames(ctx, fixes); err == nil || f.calls["POST values:batchUpdate"] != 0 {
+		t.Fatalf("rewriting after a row was inserted gave %v and wrote %d time(s)", err, f.calls["POST values:batchUpdate"])
+	}
+	f.setRows("Log", typedRows()...)
+
+	clear(f.calls)
+	if err := s.RewriteNames(ctx, fixes); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.calls["POST values:batchUpdate"]; n != 1 {
+		t.Errorf("the fixes were sent in %d requests, want 1", n)
+	}
+	after := f.rows("Log")
+	for i, row := range after {
+		want := logRow(Canonical(handTyped[i]))
+		if strings.Join(row, "|") != strings.Join(want, "|") {
+			t.Errorf("row %d is %q after the rewrite, want %q", i+1, row, want)
+		}
+	}
+
+	nameless := &UserStorage{Storage: struct{ Storage }{NewFileStorage(t.TempDir())}}
+	if err := nameless.RewriteNames(ctx, nil); !errors.Is(err, ErrNoNameRewriter) {
+		t.Errorf("RewriteNames over storage without it = %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// LevelPin fixes the working level of an exercise, which otherwise follows
+// the latest logged session; an easier variation done as a warm-up or a
+// retest of an older step would move it. An empty Level removes the pin.
+type LevelPin struct {
+	Exercise string
+	Level    string
+	User     string // as WorkoutEntry.User
+}
+
+// LevelPinStore is implemented by backends that keep level pins with the
+// data, in pins.log next to the year files or a "<SheetName> Pins" tab.
+// Like goal overrides, both are append-only and the latest record per user
+// and exercise wins.
+type LevelPinStore interface {
+	// SetLevelPin records pin; an empty Level unpins the exercise.
+	SetLevelPin(ctx context.Context, pin LevelPin) error
+	// LevelPins returns the pins in effect, in exercise order.
+	LevelPins(ctx context.Context) ([]LevelPin, error)
+}
+
+// ErrNoLevelPinStore is returned when the storage can't keep level pins.
+var ErrNoLevelPinStore = errors.New("this storage can't keep level pins")
+
+// effectivePins folds records, oldest first, into the pins in effect.
+func effectivePins(records []LevelPin) []LevelPin {
+	type key struct{ user, exercise string }
+	latest := map[key]LevelPin{}
+	for _, record := range records {
+		latest[key{record.User, record.Exercise}] = record
+	}
+
+	var pins []LevelPin
+	for _, pin := range latest {
+		if pin.Level != "" {
+			pins = append(pins, pin)
+		}
+	}
+	exerciseIndex := func(name string) int {
+		if i := slices.Index(exercises, name); i >= 0 {
+			return i
+		}
+		return len(exercises) + slices.Index(mobilityExercises, name)
+	}
+	slices.SortFunc(pins, func(a, b LevelPin) int {
+		if c := exerciseIndex(a.Exercise) - exerciseIndex(b.Exercise); c != 0 {
+			return c
+		}
+		return strings.Compare(a.User, b.User)
+	})
+	return pins
+}
+
+func (f *FileStorage) pinFile() string {
+	return filepath.Join(f.logDir, "pins.log")
+}
+
+// SetLevelPin appends pin to pins.log as "exercise|level|user".
+func (f *FileStorage) SetLevelPin(ctx context.Context, pin LevelPin) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.logDir, 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.pinFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(file, "%s|%s|%s\n", pin.Exercise, pin.Level, restFields.Replace(pin.User))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// LevelPins reads pins.log; a missing file has none.
+func (f *FileStorage) LevelPins(ctx context.Context) ([]LevelPin, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(f.pinFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []LevelPin
+	scanner := newLogScanner(file)
+	for scanner.Scan() {
+		parts := strings.Split(strings.TrimSpace(scanner.Text()), "|")
+		if len(parts) < 2 || parts[0] == "" {
+			continue
+		}
+		record := LevelPin{
+			Exercise: strings.TrimSpace(parts[0]),
+			Level:    strings.TrimSpace(parts[1]),
+		}
+		if len(parts) > 2 {
+			record.User = strings.TrimSpace(parts[2])
+		}
+		records = append(records, record)
+	}
+	return effectivePins(records), scanner.Err()
+}
+
+// pinHeader is the first row of the pins tab.
+var pinHeader = []interface{}{"Exercise", "Level", "User"}
+
+func (s *SheetsStorage) pinTab() string {
+	return s.sheetName + " Pins"
+}
+
+// SetLevelPin appends pin to the pins tab, creating the tab on first use.
+func (s *SheetsStorage) SetLevelPin(ctx context.Context, pin LevelPin) error {
+	tab := s.pinTab()
+	if _, err := s.addTab(ctx, tab, pinHeader); err != nil {
+		return err
+	}
+
+	_, err := s.svc.Spreadsheets.Values.Append(
+		s.spreadsheetID,
+		a1Range(tab, "A:C"),
+		&sheets.ValueRange{Values: [][]interface{}{{pin.Exercise, pin.Level, pin.User}}},
+	).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+	return err
+}
+
+// LevelPins reads the pins tab in one request. A missing tab has none.
+func (s *SheetsStorage) LevelPins(ctx context.Context) ([]LevelPin, error) {
+	tab := s.pinTab()
+	if _, ok := s.meta.tab(tab); !ok {
+		return nil, nil
+	}
+	resp, err := s.svc.Spreadsheets.Values.Get(s.spreadsheetID, a1Range(tab, "A:C")).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	var records []LevelPin
+	for i, row := range resp.Values {
+		record := LevelPin{
+			Exercise: strings.TrimSpace(valueAt(row, 0)),
+			Level:    strings.TrimSpace(valueAt(row, 1)),
+			User:     strings.TrimSpace(valueAt(row, 2)),
+		}
+		if record.Exercise == "" || (i == 0 && strings.EqualFold(record.Exercise, "exercise")) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return effectivePins(records), nil
+}
+
+// SetLevelPin stamps pin with Author and records it in the underlying
+// storage.
+func (u *UserStorage) SetLevelPin(ctx context.Context, pin LevelPin) error {
+	pins, ok := u.Storage.(LevelPinStore)
+	if !ok {
+		return ErrNoLevelPinStore
+	}
+	if pin.User == "" {
+		pin.User = u.Author
+	}
+	return pins.SetLevelPin(ctx, pin)
+}
+
+// LevelPins returns Show's pins, with the same rule for pins without a user
+// as entries.
+func (u *UserStorage) LevelPins(ctx context.Context) ([]LevelPin, error) {
+	pins, ok := u.Storage.(LevelPinStore)
+	if !ok {
+		return nil, ErrNoLevelPinStore
+	}
+	all, err := pins.LevelPins(ctx)
+	if err != nil || u.Show == "" {
+		return all, err
+	}
+	var visible []LevelPin
+	for _, pin := range all {
+		if pin.User == u.Show || (u.Unattributed && pin.User == "") {
+			visible = append(visible, pin)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,81 @@
+package calio
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestEffectivePins(t *testing.T) {
+	records := []LevelPin{
+		{Exercise: "Squats", Level: "Half"},
+		{Exercise: "Pullups", Level: "Full"},
+		{Exercise: "Pullups", Level: "Jackknife"}, // the latest wins
+		{Exercise: "Pushups", Level: "Full"},
+		{Exercise: "Pushups", Level: ""}, // unpinned
+		{Exercise: "Pullups", Level: "Full", User: "sam"},
+		{Exercise: "L-Sit", Level: "Tuck"},
+	}
+	want := []LevelPin{
+		{Exercise: "Squats", Level: "Half"},
+		{Exercise: "Pullups", Level: "Jackknife"},
+		{Exercise: "Pullups", Level: "Full", User: "sam"},
+		{Exercise: "L-Sit", Level: "Tuck"},
+	}
+	if got := effectivePins(records); !slices.Equal(got, want) {
+		t.Errorf("effectivePins = %+v, want %+v", got, want)
+	}
+}
+
+// TestLevelPinStore pins, repins and unpins on both backends and reads the
+// pins back from a storage opened afresh.
+func TestLevelPinStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	fake := newFakeSheets("Log")
+	backends := []struct {
+		name string
+		open func() Storage
+	}{
+		{"file", func() Storage { return NewFileStorage(dir) }},
+		{"sheets", func() Storage { return fake.mustStorage(t, SheetsConfig{}) }},
+	}
+	for _, backend := range backends {
+		pins := backend.open().(LevelPinStore)
+		if got, err := pins.LevelPins(ctx); err != nil || len(got) != 0 {
+			t.Errorf("%s: LevelPins before any = %+v, %v", backend.name, got, err)
+		}
+		for _, pin := range []LevelPin{
+			{Exercise: "Pullups", Level: "Full"},
+			{Exercise: "Pullups", Level: "Jackknife"},
+			{Exercise: "Squats", Level: "Half", User: "sam"},
+			{Exercise: "Pushups", Level: "Full"},
+			{Exercise: "Pushups"},
+		} {
+			if err := pins.SetLevelPin(ctx, pin); err != nil {
+				t.Fatalf("%s: %v", backend.name, err)
+			}
+		}
+		got, err := backend.open().(LevelPinStore).LevelPins(ctx)
+		want := []LevelPin{{Exercise: "Squats", Level: "Half", User: "sam"}, {Exercise: "Pullups", Level: "Jackknife"}}
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("%s: LevelPins = %+v, %v, want %+v", backend.name, got, err, want)
+		}
+
+		ziad := &UserStorage{Storage: backend.open(), Author: "ziad", Show: "ziad", Unattributed: true}
+		if err := ziad.SetLevelPin(ctx, LevelPin{Exercise: "Bridges", Level: "Short"}); err != nil {
+			t.Fatal(err)
+		}
+		got, err = ziad.LevelPins(ctx)
+		want = []LevelPin{{Exercise: "Pullups", Level: "Jackknife"}, {Exercise: "Bridges", Level: "Short", User: "ziad"}}
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("%s: ziad's LevelPins = %+v, %v, want %+v", backend.name, got, err, want)
+		}
+	}
+	if entries, err := NewFileStorage(dir).All(ctx); err != nil || len(entries) != 0 {
+		t.Errorf("pins.log reads as workouts: %+v, %v", entries, err)
+	}
+	if fake.tab("Log Pins") == nil {
+		t.Errorf("the spreadsheet has no pins tab")
+	}
+}
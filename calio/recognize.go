@@ -0,0 +1,120 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// recognizeRows is how many rows from the top of a tab recognizeTab gets
+// to go by.
+const recognizeRows = 20
+
+// recognizedTabs remembers, for the life of the process, which tabs were
+// found to be a cali log, keyed by spreadsheet and sheet ID so a renamed
+// tab isn't read again and two storages on one spreadsheet share it.
+var recognizedTabs = struct {
+	mu   sync.Mutex
+	tabs map[recognizedKey]bool
+}{tabs: map[recognizedKey]bool{}}
+
+type recognizedKey struct {
+	spreadsheetID string
+	sheetID       int64
+}
+
+func tabRecognized(key recognizedKey) (recognized, known bool) {
+	recognizedTabs.mu.Lock()
+	defer recognizedTabs.mu.Unlock()
+	recognized, known = recognizedTabs.tabs[key]
+	return recognized, known
+}
+
+func rememberTab(key recognizedKey, recognized bool) {
+	recognizedTabs.mu.Lock()
+	defer recognizedTabs.mu.Unlock()
+	recognizedTabs.tabs[key] = recognized
+}
+
+// recognizeTab reports whether rows, the top of a tab, look like a cali
+// log: nothing but blank rows, a first row heading the Date, Exercise and
+// RepsxSets columns, or a row reading as an entry, with a known date and
+// either a schema marker or an exercise of the dataset. The last covers
+// tabs from before cali wrote headers. A header naming only a date and a
+// category, as a budget has, isn't enough.
+func recognizeTab(rows [][]interface{}) bool {
+	layout, _ := detectLayout(firstValues(rows))
+	blank := true
+	for i, row := range rows {
+		if blankRow(row) {
+			continue
+		}
+		blank = false
+		if i == 0 && logHeader(row) {
+			return true
+		}
+		entry := entryFromRow(row, int64(i), layout)
+		if UnreadableDate(entry) {
+			continue
+		}
+		if _, ok := CanonicalExercise(entry.Exercise); ok || entry.Schema > 0 {
+			return true
+		}
+	}
+	return blank
+}
+
+// logHeader reports whether row heads the columns no cali log goes
+// without.
+func logHeader(row []interface{}) bool {
+	headed := map[int]bool{}
+	for column := range row {
+		if field, ok := headingField(strings.TrimSpace(valueAt(row, column))); ok {
+			headed[field] = true
+		}
+	}
+	return headed[fieldDate] && headed[fieldExercise] && headed[fieldRepsSets]
+}
+
+func blankRow(row []interface{}) bool {
+	for column := range row {
+		if strings.TrimSpace(valueAt(row, column)) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTab refuses writes to a tab that doesn't look like a cali log (see
+// recognizeTab), such as a tab of another spreadsheet CALI_SHEET_ID points
+// to by mistake, unless SheetsConfig.ForceUnrecognized is set. The top of
+// the tab is read once per process; the layout is learned from it on the
+// way, so the header needn't be read again.
+func (s *SheetsStorage) checkTab(ctx context.Context, tab string) error {
+	if s.force {
+		return nil
+	}
+	sheetID, ok := s.meta.tab(tab)
+	if !ok {
+		// Tabs are created with cali's header; see ensureTab.
+		return nil
+	}
+	key := recognizedKey{spreadsheetID: s.spreadsheetID, sheetID: sheetID}
+	recognized, known := tabRecognized(key)
+	if !known {
+		resp, err := s.svc.Spreadsheets.Values.Get(s.spreadsheetID, a1Range(tab, fmt.Sprintf("1:%d", recognizeRows))).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("reading the top of %q: %w", tab, err)
+		}
+		s.learnLayout(tab, firstValues(resp.Values))
+		recognized = recognizeTab(resp.Values)
+		rememberTab(key, recognized)
+		s.logf("Sheet tab %q recognized as a cali log: %v\n", tab, recognized)
+	}
+	if !recognized {
+		return fmt.Errorf("sheet tab %q of spreadsheet %s %w: it has no Date, Exercise and RepsxSets header and none of its first %d rows reads as a workout",
+			tab, s.spreadsheetID, ErrUnrecognizedTab, recognizeRows)
+	}
+	return nil
+}
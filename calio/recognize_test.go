@@ -0,0 +1,138 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// budget is a tab of another spreadsheet: dated rows, but not workouts.
+var budget = [][]string{
+	{"Date", "Category", "Amount", "Note"},
+	{"2026-03-01", "Groceries", "54.20", "weekly shop"},
+	{"2026-03-02", "Rent", "950", ""},
+	{"2026-03-04", "Sport", "30", "climbing gym"},
+}
+
+// legacyLog is a tab cali wrote before it added headers and schema markers.
+var legacyLog = [][]string{
+	{"2024-01-08", "A", "Pushups", "Full", "15x2", "20x2", "first week"},
+	{"2024-01-08", "A", "Squats", "Half", "30x2", "50x2"},
+}
+
+// values converts rows to the values the Sheets API returns.
+func values(rows [][]string) [][]interface{} {
+	out := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		out[i] = cells(row)
+	}
+	return out
+}
+
+func TestRecognizeTab(t *testing.T) {
+	custom := logRow(stamped(pushups, "", time.Time{}))
+	custom[2] = "Muscle-ups"
+	tests := []struct {
+		name string
+		rows [][]string
+		want bool
+	}{
+		{"empty", nil, true},
+		{"blank rows", [][]string{{}, {"", " "}}, true},
+		{"header only", [][]string{{"Date", "Day", "Exercise", "Level", "RepsxSets", "Goal"}}, true},
+		{"header in another order", [][]string{{"exercise", "DATE", "Notes", "Reps x Sets"}, {"someday", "?"}}, true},
+		{"cali log", [][]string{{"Date", "Day", "Exercise"}, logRow(pushups)}, true},
+		{"legacy log without a header", legacyLog, true},
+		{"legacy log below a title", append([][]string{{"My training"}, {}}, legacyLog...), true},
+		{"schema marker, exercise of its own", [][]string{custom}, true},
+		{"budget", budget, false},
+		{"budget without a header", budget[1:], false},
+		{"header without RepsxSets", [][]string{{"Date", "Exercise", "Minutes"}, {"2026-03-01", "Running", "30"}}, false},
+		{"known exercise, no date", [][]string{{"Exercises to try"}, {"", "", "Pushups"}, {"soon", "", "Squats"}}, false},
+		{"header below the first row", [][]string{{"Budget 2026"}, {"Date", "Exercise", "RepsxSets"}}, false},
+	}
+	for _, tt := range tests {
+		if got := recognizeTab(values(tt.rows)); got != tt.want {
+			t.Errorf("%s: recognizeTab = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestSheetsRefuseForeignTab points the storage at a budget tab: writes are
+// refused, once read, without another read later or from another storage on
+// the spreadsheet; --force-unrecognized writes anyway.
+func TestSheetsRefuseForeignTab(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSheets(DefaultSheetName)
+	fake.setRows(DefaultSheetName, budget...)
+	s := fake.mustStorage(t, SheetsConfig{})
+
+	if _, err := s.Append(ctx, pushups); !errors.Is(err, ErrUnrecognizedTab) {
+		t.Fatalf("Append to a budget = %v, want ErrUnrecognizedTab", err)
+	}
+	if _, _, err := s.Restore(ctx, withRow(pushups, 2)); !errors.Is(err, ErrUnrecognizedTab) {
+		t.Errorf("Restore into a budget = %v, want ErrUnrecognizedTab", err)
+	}
+	if got := fake.rows(DefaultSheetName); len(got) != len(budget) {
+		t.Errorf("the budget holds %d rows, want %d", len(got), len(budget))
+	}
+	if fake.calls["GET values"] != 1 {
+		t.Errorf("the tab was read %d times, want once", fake.calls["GET values"])
+	}
+
+	clear(fake.calls)
+	again := fake.mustStorage(t, SheetsConfig{})
+	if _, err := again.AppendBatch(ctx, []WorkoutEntry{pushups, squats}); !errors.Is(err, ErrUnrecognizedTab) {
+		t.Errorf("AppendBatch from a second storage = %v", err)
+	}
+	if fake.calls["GET values"] != 0 || fake.calls["POST append"] != 0 {
+		t.Errorf("a second storage made calls %v, want only the metadata", fake.calls)
+	}
+
+	forced := fake.mustStorage(t, SheetsConfig{ForceUnrecognized: true})
+	if _, err := forced.Append(ctx, pushups); err != nil {
+		t.Fatalf("a forced Append = %v", err)
+	}
+	if got := fake.rows(DefaultSheetName); len(got) != len(budget)+1 {
+		t.Errorf("a forced Append left %d rows, want %d", len(got), len(budget)+1)
+	}
+}
+
+// TestSheetsAcceptLogTabs checks a legacy tab without a header and an empty
+// tab are written to, at the cost of one read of their top.
+func TestSheetsAcceptLogTabs(t *testing.T) {
+	ctx := context.Background()
+	for name, rows := range map[string][][]string{"legacy": legacyLog, "empty": nil} {
+		fake := newFakeSheets(DefaultSheetName)
+		fake.setRows(DefaultSheetName, rows...)
+		s := fake.mustStorage(t, SheetsConfig{})
+		for range 2 {
+			if _, err := s.Append(ctx, pushups); err != nil {
+				t.Fatalf("%s: Append = %v", name, err)
+			}
+		}
+		if got := fake.rows(DefaultSheetName); len(got) != len(rows)+2 {
+			t.Errorf("%s: the tab holds %d rows, want %d", name, len(got), len(rows)+2)
+		}
+		if fake.calls["GET values"] != 1 {
+			t.Errorf("%s: the tab was read %d times, want once", name, fake.calls["GET values"])
+		}
+	}
+
+	// A tab cali creates needs no check.
+	fake := newFakeSheets()
+	s := fake.mustStorage(t, SheetsConfig{PerYear: true})
+	if _, err := s.Append(ctx, pushups); err != nil {
+		t.Fatal(err)
+	}
+	if fake.calls["GET values"] != 0 {
+		t.Errorf("a new tab was read %d times", fake.calls["GET values"])
+	}
+}
+
+// withRow returns entry as read from row.
+func withRow(entry WorkoutEntry, row int64) WorkoutEntry {
+	entry.RowIndex = row
+	return entry
+}
@@ -0,0 +1,96 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+)
+
+// EntryRemover is implemented by backends that can remove an entry as a
+// read returned it, rather than by its position among the entries of its
+// date: a position read before a pause (a prompt, say) means another entry
+// once rows are added or removed meanwhile.
+type EntryRemover interface {
+	// RemoveEntry deletes entry, found at its RowIndex when that still
+	// holds the same content, else by content alone. It fails with
+	// ErrEntryChanged when entry is gone or identical entries moved, and
+	// then removes nothing.
+	RemoveEntry(ctx context.Context, entry WorkoutEntry) error
+}
+
+// RemoveEntry removes entry, as a read of storage returned it, with
+// EntryRemover when storage implements it. Other storages have the entries
+// of its date read again and the position of entry among them removed with
+// RemoveByDateIndex, which leaves only the time between the two calls for
+// the log to change.
+func RemoveEntry(ctx context.Context, storage Storage, entry WorkoutEntry) error {
+	if remover, ok := storage.(EntryRemover); ok {
+		return remover.RemoveEntry(ctx, entry)
+	}
+	entries, err := storage.SearchByDate(ctx, entry.Date)
+	if err != nil {
+		return err
+	}
+	index, err := locateEntry(entries, entry)
+	if err != nil {
+		return err
+	}
+	return storage.RemoveByDateIndex(ctx, entry.Date, index)
+}
+
+// RemoveEntry rewrites entry's year file without its line, checked and
+// replaced through the rewrite journal while no other write runs.
+func (f *FileStorage) RemoveEntry(ctx context.Context, entry WorkoutEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	logFile := f.FileFor(entry.Date)
+	data, err := os.ReadFile(logFile)
+	if errors.Is(err, os.ErrNotExist) {
+		_, err = locateEntry(nil, entry)
+	}
+	if err != nil {
+		return err
+	}
+	entries, err := scanLogEntries(strings.NewReader(string(data)), entry.Date)
+	if err != nil {
+		return err
+	}
+	index, err := locateEntry(entries, entry)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	row := entries[index].RowIndex
+	lines = append(lines[:row], lines[row+1:]...)
+	text := strings.Join(lines, "\n")
+	if text != "" {
+		text += "\n"
+	}
+	return f.rewriteFiles(ctx, []string{logFile}, map[string]string{logFile: text})
+}
+
+// RemoveEntry checks the row entry was read from, finds the entry again by
+// content when other edits moved it, and deletes that row.
+func (s *SheetsStorage) RemoveEntry(ctx context.Context, entry WorkoutEntry) error {
+	tab := s.TabFor(entry.Date)
+	if _, ok := s.meta.tab(tab); !ok {
+		_, err := locateEntry(nil, entry)
+		return err
+	}
+	row, err := s.confirmRow(ctx, tab, entry)
+	if err != nil {
+		return err
+	}
+	return s.deleteRow(ctx, tab, row)
+}
+
+// RemoveEntry removes entry from the underlying storage, which counts
+// everyone's entries; entry keeps its User, so the content check holds.
+func (u *UserStorage) RemoveEntry(ctx context.Context, entry WorkoutEntry) error {
+	return RemoveEntry(ctx, u.Storage, entry)
+}
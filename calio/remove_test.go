@@ -0,0 +1,267 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// removeBackend opens an empty log as a storage to remove from, and the
+// storage under it that holds every user's entries.
+type removeBackend struct {
+	name string
+	open func(t *testing.T) (storage, raw Storage)
+}
+
+var removeBackends = []removeBackend{
+	{"file", func(t *testing.T) (Storage, Storage) {
+		s := NewFileStorage(t.TempDir())
+		return s, s
+	}},
+	{"sheets", func(t *testing.T) (Storage, Storage) {
+		s := newFakeSheets("Log").mustStorage(t, SheetsConfig{})
+		return s, s
+	}},
+	{"user", func(t *testing.T) (Storage, Storage) {
+		s := NewFileStorage(t.TempDir())
+		return &UserStorage{Storage: s, Author: "ziad", Show: "ziad"}, s
+	}},
+	// A storage without EntryRemover falls back to RemoveByDateIndex.
+	{"by date index", func(t *testing.T) (Storage, Storage) {
+		s := NewFileStorage(t.TempDir())
+		return struct{ Storage }{s}, s
+	}},
+}
+
+// TestRemoveEntryAfterInterleavedWrites removes an entry read before another
+// write went in, as cali -r does when a second session or the browser
+// writes while it waits at its prompt.
+func TestRemoveEntryAfterInterleavedWrites(t *testing.T) {
+	later := squats
+	later.Comment = "second round"
+	sams := pushups
+	sams.User = "sam"
+	tests := []struct {
+		name       string
+		interleave func(ctx context.Context, storage, raw Storage) error
+		want       []string // what the log holds afterwards
+		wantErr    bool
+	}{
+		{"nothing in between", func(context.Context, Storage, Storage) error { return nil },
+			[]string{"Squats"}, false},
+		{"another entry appended", func(ctx context.Context, storage, _ Storage) error {
+			_, err := storage.Append(ctx, later)
+			return err
+		}, []string{"Squats", "Squats second round"}, false},
+		{"identical entry appended", func(ctx context.Context, storage, _ Storage) error {
+			_, err := storage.Append(ctx, pushups)
+			return err
+		}, []string{"Squats", "Pushups"}, false},
+		{"another user's identical entry appended", func(ctx context.Context, _, raw Storage) error {
+			_, err := raw.Append(ctx, sams)
+			return err
+		}, []string{"Squats", "Pushups sam"}, false},
+		{"entry removed", func(ctx context.Context, _, raw Storage) error {
+			return raw.RemoveByDateIndex(ctx, pushups.Date, 1)
+		}, []string{"Squats"}, true},
+		{"entry removed and another appended", func(ctx context.Context, storage, raw Storage) error {
+			if err := raw.RemoveByDateIndex(ctx, pushups.Date, 1); err != nil {
+				return err
+			}
+			_, err := storage.Append(ctx, later)
+			return err
+		}, []string{"Squats", "Squats second round"}, true},
+	}
+
+	ctx := context.Background()
+	for _, backend := range removeBackends {
+		for _, tt := range tests {
+			name := backend.name + ": " + tt.name
+			storage, raw := backend.open(t)
+			for _, entry := range []WorkoutEntry{squats, pushups} {
+				if _, err := storage.Append(ctx, entry); err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+			}
+			read, err := storage.SearchByDate(ctx, pushups.Date)
+			if err != nil || len(read) != 2 || read[1].Exercise != "Pushups" {
+				t.Fatalf("%s: SearchByDate = %+v, %v", name, read, err)
+			}
+			if err := tt.interleave(ctx, storage, raw); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			err = RemoveEntry(ctx, storage, read[1])
+			switch {
+			case tt.wantErr && !errors.Is(err, ErrEntryChanged):
+				t.Errorf("%s: RemoveEntry = %v, want ErrEntryChanged", name, err)
+			case !tt.wantErr && err != nil:
+				t.Errorf("%s: RemoveEntry = %v", name, err)
+			}
+			if got := describe(t, raw); !slices.Equal(got, tt.want) {
+				t.Errorf("%s: log holds %q, want %q", name, got, tt.want)
+			}
+		}
+	}
+}
+
+// describe lists the log's entries of pushups.Date as exercise, user and
+// comment.
+func describe(t *testing.T, storage Storage) []string {
+	t.Helper()
+	entries, err := storage.SearchByDate(context.Background(), pushups.Date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var described []string
+	for _, entry := range entries {
+		text := entry.Exercise
+		if entry.User != "" && entry.User != "ziad" {
+			text += " " + entry.User
+		}
+		if entry.Comment != "" {
+			text += " " + entry.Comment
+		}
+		described = append(described, text)
+	}
+	return described
+}
+
+// TestRemoveEntryAfterHandEdit removes an entry whose line moved because
+// a line was added above it, which only an edit by hand does to a year file.
+func TestRemoveEntryAfterHandEdit(t *testing.T) {
+	ctx := context.Background()
+	for _, wrap := range []struct {
+		name string
+		wrap func(*FileStorage) Storage
+	}{
+		{"file", func(s *FileStorage) Storage { return s }},
+		{"by date index", func(s *FileStorage) Storage { return struct{ Storage }{s} }},
+	} {
+		file := NewFileStorage(t.TempDir())
+		storage := wrap.wrap(file)
+		for _, entry := range []WorkoutEntry{squats, pushups} {
+			if _, err := storage.Append(ctx, entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+		read, err := storage.SearchByDate(ctx, pushups.Date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moved := squats
+		moved.Comment = "added by hand"
+		if err := prependLine(file, moved); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := RemoveEntry(ctx, storage, read[1]); err != nil {
+			t.Errorf("%s: RemoveEntry = %v", wrap.name, err)
+		}
+		if got, want := describe(t, file), []string{"Squats added by hand", "Squats"}; !slices.Equal(got, want) {
+			t.Errorf("%s: log holds %q, want %q", wrap.name, got, want)
+		}
+	}
+}
+
+// TestRemoveEntryAmongOddLines removes an entry from a year file that also
+// holds an indented line and a malformed one of its date: reads count the
+// first and skip the second, and so must the removal.
+func TestRemoveEntryAmongOddLines(t *testing.T) {
+	ctx := context.Background()
+	for _, wrap := range []struct {
+		name string
+		wrap func(*FileStorage) Storage
+	}{
+		{"file", func(s *FileStorage) Storage { return s }},
+		{"by date index", func(s *FileStorage) Storage { return struct{ Storage }{s} }},
+	} {
+		file := NewFileStorage(t.TempDir())
+		storage := wrap.wrap(file)
+		indented := squats
+		indented.Comment = "indented"
+		for _, entry := range []WorkoutEntry{indented, pushups, squats} {
+			if _, err := storage.Append(ctx, entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+		data, err := os.ReadFile(file.FileFor(pushups.Date))
+		if err != nil {
+			t.Fatal(err)
+		}
+		malformed := pushups.Date + " | not an entry\n"
+		odd := "  " + malformed + "  " + string(data)
+		if err := os.WriteFile(file.FileFor(pushups.Date), []byte(odd), 0644); err != nil {
+			t.Fatal(err)
+		}
+		read, err := storage.SearchByDate(ctx, pushups.Date)
+		if err != nil || len(read) != 3 || read[1].Exercise != "Pushups" {
+			t.Fatalf("%s: SearchByDate = %+v, %v", wrap.name, read, err)
+		}
+
+		if err := RemoveEntry(ctx, storage, read[1]); err != nil {
+			t.Errorf("%s: RemoveEntry = %v", wrap.name, err)
+		}
+		if got, want := describe(t, file), []string{"Squats indented", "Squats"}; !slices.Equal(got, want) {
+			t.Errorf("%s: log holds %q, want %q", wrap.name, got, want)
+		}
+		if data, _ := os.ReadFile(file.FileFor(pushups.Date)); !strings.Contains(string(data), malformed) {
+			t.Errorf("%s: the malformed line is gone:\n%s", wrap.name, data)
+		}
+	}
+}
+
+// prependLine writes entry as the first line of its year file.
+func prependLine(f *FileStorage, entry WorkoutEntry) error {
+	scratch := NewFileStorage(f.Dir() + "-scratch")
+	if _, err := scratch.Append(context.Background(), entry); err != nil {
+		return err
+	}
+	line, err := os.ReadFile(scratch.FileFor(entry.Date))
+	if err != nil {
+		return err
+	}
+	text, err := os.ReadFile(f.FileFor(entry.Date))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.FileFor(entry.Date), append(line, text...), 0644)
+}
+
+// TestRemoveSecondOfThree lists three entries of one date as cali -s does
+// and removes the second both ways cali -r can: the entry listed, and its
+// index. Only the middle entry goes, on every backend.
+func TestRemoveSecondOfThree(t *testing.T) {
+	ctx := context.Background()
+	first, middle, last := pushups, squats, withDate(pushups, pushups.Date)
+	middle.Comment, last.Comment = "the middle one", "the last one"
+	for _, backend := range removeBackends {
+		for _, byIndex := range []bool{false, true} {
+			storage, _ := backend.open(t)
+			for _, entry := range []WorkoutEntry{first, middle, last} {
+				if _, err := storage.Append(ctx, entry); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listed, err := storage.SearchByDate(ctx, pushups.Date)
+			if err != nil || len(listed) != 3 || listed[1].Comment != middle.Comment {
+				t.Fatalf("%s: SearchByDate = %+v, %v", backend.name, listed, err)
+			}
+			if byIndex {
+				err = storage.RemoveByDateIndex(ctx, pushups.Date, 1)
+			} else {
+				err = RemoveEntry(ctx, storage, listed[1])
+			}
+			if err != nil {
+				t.Fatalf("%s: removing [2] (by index %v): %v", backend.name, byIndex, err)
+			}
+			left, err := storage.SearchByDate(ctx, pushups.Date)
+			if err != nil || len(left) != 2 || left[0].Comment != "" || left[1].Comment != last.Comment {
+				t.Errorf("%s: after removing [2] (by index %v) the date holds %+v, %v", backend.name, byIndex, left, err)
+			}
+		}
+	}
+}
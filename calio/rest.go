@@ -0,0 +1,185 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// RestDay is a day deliberately taken off training. A workout logged on the
+// same date counts over it, so a marker set by mistake is harmless.
+type RestDay struct {
+	Date   string
+	Reason string // e.g. "travel"; may be empty
+	User   string // as WorkoutEntry.User
+}
+
+// RestLog is implemented by backends that keep rest days. They are stored
+// apart from the workout entries, in rest.log next to the year files or a
+// "<SheetName> Rest Days" tab, so versions that predate rest days never read
+// them as workouts.
+type RestLog interface {
+	// AddRest records day.
+	AddRest(ctx context.Context, day RestDay) error
+	// RestDays returns the rest days dated within [since, until], oldest
+	// first; an empty bound is open.
+	RestDays(ctx context.Context, since, until string) ([]RestDay, error)
+}
+
+// ErrNoRestLog is returned when the storage can't keep rest days.
+var ErrNoRestLog = errors.New("this storage can't keep rest days")
+
+func sortRestDays(days []RestDay) []RestDay {
+	sort.SliceStable(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
+
+// restFields keeps free text, such as reasons and comments, on one line and
+// free of the field separator.
+var restFields = strings.NewReplacer("|", "/", "\n", " ", "\r", " ")
+
+func (f *FileStorage) restFile() string {
+	return filepath.Join(f.logDir, "rest.log")
+}
+
+// AddRest appends day to rest.log as "date|reason|user".
+func (f *FileStorage) AddRest(ctx context.Context, day RestDay) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.logDir, 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.restFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(file, "%s|%s|%s\n", day.Date, restFields.Replace(day.Reason), restFields.Replace(day.User))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// RestDays reads rest.log; a missing file has none.
+func (f *FileStorage) RestDays(ctx context.Context, since, until string) ([]RestDay, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(f.restFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var days []RestDay
+	scanner := newLogScanner(file)
+	for scanner.Scan() {
+		parts := strings.Split(strings.TrimSpace(scanner.Text()), "|")
+		if parts[0] == "" || !InRange(parts[0], since, until) {
+			continue
+		}
+		day := RestDay{Date: parts[0]}
+		if len(parts) > 1 {
+			day.Reason = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			day.User = strings.TrimSpace(parts[2])
+		}
+		days = append(days, day)
+	}
+	return sortRestDays(days), scanner.Err()
+}
+
+// restHeader is the first row of the rest tab.
+var restHeader = []interface{}{"Date", "Reason", "User"}
+
+func (s *SheetsStorage) restTab() string {
+	return s.sheetName + " Rest Days"
+}
+
+// AddRest appends day to the rest tab, creating the tab on first use.
+func (s *SheetsStorage) AddRest(ctx context.Context, day RestDay) error {
+	tab := s.restTab()
+	if _, err := s.addTab(ctx, tab, restHeader); err != nil {
+		return err
+	}
+
+	_, err := s.svc.Spreadsheets.Values.Append(
+		s.spreadsheetID,
+		a1Range(tab, "A:C"),
+		&sheets.ValueRange{Values: [][]interface{}{{day.Date, day.Reason, day.User}}},
+	).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+	return err
+}
+
+// RestDays reads the rest tab in one request; it stays small. A missing tab
+// has none.
+func (s *SheetsStorage) RestDays(ctx context.Context, since, until string) ([]RestDay, error) {
+	tab := s.restTab()
+	if _, ok := s.meta.tab(tab); !ok {
+		return nil, nil
+	}
+	resp, err := s.svc.Spreadsheets.Values.Get(s.spreadsheetID, a1Range(tab, "A:C")).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	var days []RestDay
+	for _, row := range resp.Values {
+		day := RestDay{
+			Date:   strings.TrimSpace(valueAt(row, 0)),
+			Reason: strings.TrimSpace(valueAt(row, 1)),
+			User:   strings.TrimSpace(valueAt(row, 2)),
+		}
+		if day.Date == "" || strings.EqualFold(day.Date, "date") || !InRange(day.Date, since, until) {
+			continue
+		}
+		days = append(days, day)
+	}
+	return sortRestDays(days), nil
+}
+
+// AddRest stamps day with Author and records it in the underlying storage.
+func (u *UserStorage) AddRest(ctx context.Context, day RestDay) error {
+	rest, ok := u.Storage.(RestLog)
+	if !ok {
+		return ErrNoRestLog
+	}
+	if day.User == "" {
+		day.User = u.Author
+	}
+	return rest.AddRest(ctx, day)
+}
+
+// RestDays returns Show's rest days, with the same rule for days without a
+// user as entries.
+func (u *UserStorage) RestDays(ctx context.Context, since, until string) ([]RestDay, error) {
+	rest, ok := u.Storage.(RestLog)
+	if !ok {
+		return nil, ErrNoRestLog
+	}
+	days, err := rest.RestDays(ctx, since, until)
+	if err != nil || u.Show == "" {
+		return days, err
+	}
+	var visible []RestDay
+	for _, day := range days {
+		if day.User == u.Show || (u.Unattributed && day.User == "") {
+			visible = append(visible, day)
+		}
+	}
+	return visible, nil
+}
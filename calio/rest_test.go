@@ -0,0 +1,93 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestRestLog records rest days on both backends and checks they read back
+// in date order, apart from the workout entries.
+func TestRestLog(t *testing.T) {
+	ctx := context.Background()
+	backends := []struct {
+		name string
+		open func(t *testing.T) Storage
+	}{
+		{"file", func(t *testing.T) Storage { return NewFileStorage(t.TempDir()) }},
+		{"sheets", func(t *testing.T) Storage { return newFakeSheets("Log").mustStorage(t, SheetsConfig{}) }},
+	}
+	for _, backend := range backends {
+		storage := backend.open(t)
+		rest := storage.(RestLog)
+		if days, err := rest.RestDays(ctx, "", ""); err != nil || len(days) != 0 {
+			t.Errorf("%s: RestDays before any = %+v, %v", backend.name, days, err)
+		}
+		if _, err := storage.Append(ctx, pushups); err != nil {
+			t.Fatal(err)
+		}
+		for _, day := range []RestDay{
+			{Date: "2026-03-06", Reason: "travel | flight", User: "ziad"},
+			{Date: "2026-03-05"},
+			{Date: "2026-04-01", Reason: "sick"},
+		} {
+			if err := rest.AddRest(ctx, day); err != nil {
+				t.Fatalf("%s: %v", backend.name, err)
+			}
+		}
+
+		days, err := rest.RestDays(ctx, "2026-03-01", "2026-03-31")
+		if err != nil {
+			t.Fatalf("%s: %v", backend.name, err)
+		}
+		want := []RestDay{{Date: "2026-03-05"}, {Date: "2026-03-06", Reason: "travel | flight", User: "ziad"}}
+		if backend.name == "file" {
+			want[1].Reason = "travel / flight"
+		}
+		if !slices.Equal(days, want) {
+			t.Errorf("%s: RestDays in March = %+v, want %+v", backend.name, days, want)
+		}
+		if entries, err := storage.All(ctx); err != nil || len(entries) != 1 {
+			t.Errorf("%s: the workouts are %+v, %v, want the one entry", backend.name, entries, err)
+		}
+	}
+}
+
+func TestUserRestDays(t *testing.T) {
+	ctx := context.Background()
+	raw := NewFileStorage(t.TempDir())
+	ziad := &UserStorage{Storage: raw, Author: "ziad", Show: "ziad", Unattributed: true}
+	sam := &UserStorage{Storage: raw, Author: "sam", Show: "sam"}
+	for _, add := range []func() error{
+		func() error { return raw.AddRest(ctx, RestDay{Date: "2026-03-04"}) },
+		func() error { return ziad.AddRest(ctx, RestDay{Date: "2026-03-05"}) },
+		func() error { return sam.AddRest(ctx, RestDay{Date: "2026-03-06"}) },
+	} {
+		if err := add(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dates := func(s *UserStorage) []string {
+		days, err := s.RestDays(ctx, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dates []string
+		for _, day := range days {
+			dates = append(dates, day.Date+" "+day.User)
+		}
+		return dates
+	}
+	if got := dates(ziad); !slices.Equal(got, []string{"2026-03-04 ", "2026-03-05 ziad"}) {
+		t.Errorf("ziad's rest days = %q", got)
+	}
+	if got := dates(sam); !slices.Equal(got, []string{"2026-03-06 sam"}) {
+		t.Errorf("sam's rest days = %q", got)
+	}
+
+	noRest := &UserStorage{Storage: struct{ Storage }{raw}}
+	if err := noRest.AddRest(ctx, RestDay{Date: "2026-03-07"}); !errors.Is(err, ErrNoRestLog) {
+		t.Errorf("AddRest on a storage without rest days = %v, want ErrNoRestLog", err)
+	}
+}
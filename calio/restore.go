@@ -0,0 +1,141 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// EntryRestorer is implemented by backends that can undo a removal by
+// putting the entry back where it was.
+type EntryRestorer interface {
+	// Restore writes entry, as a read returned it before it was removed,
+	// back at its RowIndex when the log still reaches that far, so the
+	// entries around it keep their order. Otherwise, or when RowIndex is
+	// unknown, it is appended. inPlace reports which happened.
+	Restore(ctx context.Context, entry WorkoutEntry) (stored WorkoutEntry, inPlace bool, err error)
+}
+
+// ErrNoRestorer is returned when the storage can't put an entry back.
+var ErrNoRestorer = errors.New("this storage can't restore removed entries")
+
+// reinsertionRow returns where a removed entry read at rowIndex goes back
+// into a tab or file now holding rows rows (header included): at rowIndex
+// while that is at most rows, since the rows before it are then still
+// there. ok is false when rowIndex is unknown (negative) or past the end,
+// and the entry should be appended instead.
+func reinsertionRow(rowIndex, rows int64) (row int64, ok bool) {
+	if rowIndex < 0 || rowIndex > rows {
+		return rows, false
+	}
+	return rowIndex, true
+}
+
+// Restore inserts the line of entry back into its year file, which is
+// replaced through the rewrite journal. Entries read from lines without a schema marker
+// are stamped as new ones.
+func (f *FileStorage) Restore(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return WorkoutEntry{}, false, err
+	}
+	if entry.Schema == 0 {
+		entry = stamped(entry, f.Writer, time.Time{})
+	}
+	logFile := f.FileFor(entry.Date)
+	data, err := os.ReadFile(logFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return WorkoutEntry{}, false, err
+	}
+	var lines []string
+	if text := strings.TrimSuffix(string(data), "\n"); text != "" {
+		lines = strings.Split(text, "\n")
+	}
+	row, inPlace := reinsertionRow(entry.RowIndex, int64(len(lines)))
+	lines = slices.Insert(lines, int(row), strings.TrimSuffix(serializeLogEntry(entry), "\n"))
+
+	text := strings.Join(lines, "\n") + "\n"
+	if err := f.rewriteFiles(ctx, []string{logFile}, map[string]string{logFile: text}); err != nil {
+		return WorkoutEntry{}, false, err
+	}
+	return storedAt(entry, row), inPlace, nil
+}
+
+// Restore inserts a row back into the tab for entry's date with an
+// InsertDimension and writes entry into it. How far the tab reaches is read
+// from its Date column first; past that, or in a tab that no longer
+// exists, entry is appended instead. Entries read from rows without a
+// schema marker are stamped as new ones.
+func (s *SheetsStorage) Restore(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, bool, error) {
+	if entry.Schema == 0 {
+		entry = stamped(entry, s.writer, time.Time{})
+	}
+	tab := s.TabFor(entry.Date)
+	sheetID, ok := s.meta.tab(tab)
+	if !ok {
+		stored, err := s.Append(ctx, entry)
+		return stored, false, err
+	}
+	if err := s.checkTab(ctx, tab); err != nil {
+		return WorkoutEntry{}, false, err
+	}
+	layout, err := s.layoutFor(ctx, tab)
+	if err != nil {
+		return WorkoutEntry{}, false, err
+	}
+	dates := columnName(layout[fieldDate])
+	resp, err := s.svc.Spreadsheets.Values.Get(s.spreadsheetID, a1Range(tab, dates+":"+dates)).Context(ctx).Do()
+	if err != nil {
+		return WorkoutEntry{}, false, fmt.Errorf("reading %q: %w", tab, err)
+	}
+	row, ok := reinsertionRow(entry.RowIndex, int64(len(resp.Values)))
+	if !ok {
+		stored, err := s.Append(ctx, entry)
+		return stored, false, err
+	}
+
+	_, err = s.svc.Spreadsheets.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			InsertDimension: &sheets.InsertDimensionRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:    sheetID,
+					Dimension:  "ROWS",
+					StartIndex: row,
+					EndIndex:   row + 1,
+				},
+				InheritFromBefore: row > 0,
+			},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return WorkoutEntry{}, false, fmt.Errorf("inserting row %d of %q: %w", row+1, tab, err)
+	}
+	s.meta.grow(tab, 1)
+	cells := fmt.Sprintf("A%d:%s%d", row+1, layout.lastColumn(), row+1)
+	_, err = s.svc.Spreadsheets.Values.Update(
+		s.spreadsheetID,
+		a1Range(tab, cells),
+		&sheets.ValueRange{Values: [][]interface{}{s.rowValues(entry, layout)}},
+	).ValueInputOption("RAW").Context(ctx).Do()
+	if err != nil {
+		return WorkoutEntry{}, false, fmt.Errorf("writing row %d of %q: %w", row+1, tab, err)
+	}
+	s.logf("Restored row %d of %q\n", row+1, tab)
+	return storedAt(entry, row), true, nil
+}
+
+// Restore forwards to the underlying storage; entry keeps its User.
+func (u *UserStorage) Restore(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, bool, error) {
+	restorer, ok := u.Storage.(EntryRestorer)
+	if !ok {
+		return WorkoutEntry{}, false, ErrNoRestorer
+	}
+	return restorer.Restore(ctx, entry)
+}
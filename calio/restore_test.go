@@ -0,0 +1,145 @@
+package calio
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReinsertionRow(t *testing.T) {
+	tests := []struct {
+		rowIndex, rows int64
+		want           int64
+		ok             bool
+	}{
+		{1, 3, 1, true},
+		{0, 0, 0, true},
+		// The last row: the rows before it are all still there.
+		{3, 3, 3, true},
+		{4, 3, 3, false},
+		{-1, 3, 3, false},
+	}
+	for _, tt := range tests {
+		if got, ok := reinsertionRow(tt.rowIndex, tt.rows); got != tt.want || ok != tt.ok {
+			t.Errorf("reinsertionRow(%d, %d) = %d, %v, want %d, %v", tt.rowIndex, tt.rows, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestRestore removes entries and puts them back on every backend that can:
+// a middle or last entry goes back where it was, one whose place is gone or
+// unknown is appended.
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	first, third := pushups, pushups
+	first.Comment, third.Comment = "first", "third"
+	for _, backend := range removeBackends {
+		storage, raw := backend.open(t)
+		restorer, ok := storage.(EntryRestorer)
+		if !ok {
+			continue
+		}
+		for _, entry := range []WorkoutEntry{first, squats, third} {
+			if _, err := storage.Append(ctx, entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+		entries, err := storage.SearchByDate(ctx, pushups.Date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"Pushups first", "Squats", "Pushups third"}
+
+		// restore removes entry and puts it back, checking where it went.
+		restore := func(name string, entry WorkoutEntry, wantInPlace bool, want []string) {
+			t.Helper()
+			stored, inPlace, err := restorer.Restore(ctx, entry)
+			if err != nil {
+				t.Fatalf("%s: %s: %v", backend.name, name, err)
+			}
+			if inPlace != wantInPlace {
+				t.Errorf("%s: %s: in place %v, want %v", backend.name, name, inPlace, wantInPlace)
+			}
+			if got := describe(t, raw); !slices.Equal(got, want) {
+				t.Errorf("%s: %s: log holds %q, want %q", backend.name, name, got, want)
+			}
+			if back, err := storage.SearchByDate(ctx, pushups.Date); err != nil || !slices.ContainsFunc(back, func(e WorkoutEntry) bool {
+				return e.RowIndex == stored.RowIndex && e.Comment == entry.Comment && e.Exercise == entry.Exercise
+			}) {
+				t.Errorf("%s: %s: restored at row %d, not found there in %+v", backend.name, name, stored.RowIndex, back)
+			}
+		}
+		remove := func(entry WorkoutEntry) {
+			t.Helper()
+			if err := storage.(EntryRemover).RemoveEntry(ctx, entry); err != nil {
+				t.Fatalf("%s: %v", backend.name, err)
+			}
+		}
+
+		remove(entries[1])
+		restore("middle", entries[1], true, want)
+		remove(entries[2])
+		restore("last", entries[2], true, want)
+
+		// The first entry is gone too by the time the last is put back: its
+		// row is past the end, so it is appended.
+		remove(entries[2])
+		remove(entries[0])
+		restore("shrunk", entries[2], false, []string{"Squats", "Pushups third"})
+
+		unknown := entries[0]
+		unknown.RowIndex = -1
+		restore("unknown row", unknown, false, []string{"Squats", "Pushups third", "Pushups first"})
+	}
+}
+
+// TestSheetsRestore checks an entry goes back into its row with a row insert
+// and one write, and that one whose year tab is missing is appended, which
+// creates the tab.
+func TestSheetsRestore(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSheets(DefaultSheetName)
+	fake.setRows(DefaultSheetName,
+		[]string{"Date", "Day", "Exercise"},
+		logRow(withDate(pushups, "2026-03-02")),
+		logRow(squats),
+		logRow(pushups),
+	)
+	s := fake.mustStorage(t, SheetsConfig{})
+	entries, err := s.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveEntry(ctx, entries[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	clear(fake.calls)
+	stored, inPlace, err := s.Restore(ctx, entries[1])
+	if err != nil || !inPlace || stored.RowIndex != 2 {
+		t.Fatalf("Restore = %+v, %v, %v", stored, inPlace, err)
+	}
+	// A row read without a schema marker is written back stamped.
+	if got, want := fake.rows(DefaultSheetName)[2], logRow(stamped(squats, "", time.Time{})); !slices.Equal(got, want) {
+		t.Errorf("row 3 = %q, want %q", got, want)
+	}
+	if len(fake.rows(DefaultSheetName)) != 4 {
+		t.Errorf("the tab holds %d rows, want 4", len(fake.rows(DefaultSheetName)))
+	}
+	if fake.calls["POST batchUpdate"] != 1 || fake.calls["PUT values"] != 1 || fake.calls["POST append"] != 0 {
+		t.Errorf("restoring made calls %v", fake.calls)
+	}
+
+	perYear := newFakeSheets(DefaultSheetName)
+	y := perYear.mustStorage(t, SheetsConfig{PerYear: true})
+	legacy := squats
+	legacy.RowIndex = 5
+	stored, inPlace, err = y.Restore(ctx, legacy)
+	if err != nil || inPlace || stored.Schema != SchemaVersion {
+		t.Fatalf("Restore into a missing tab = %+v, %v, %v", stored, inPlace, err)
+	}
+	if back, err := y.SearchByDate(ctx, squats.Date); err != nil || len(back) != 1 || back[0].Exercise != "Squats" {
+		t.Errorf("read back %+v, %v", back, err)
+	}
+}
@@ -0,0 +1,336 @@
+package calio
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Rewrites of whole log files (removals, restores, batch appends, name and
+// goal fixes) go through a journal, so one stopped halfway, by a crash or a
+// kill, leaves every file either as it was or as intended:
+//
+//  1. each file's new contents are written to a temporary file next to it;
+//  2. rewriteJournalName records, per file, the checksum of the contents
+//     being replaced, of the new ones, and the rename to make;
+//  3. the temporary files are renamed over the originals;
+//  4. the journal is removed.
+//
+// A journal found later means step 3 may be partly done: Recover finishes
+// it. Without a journal nothing was replaced, and stray temporary files are
+// removed once they are old enough not to be another process's staging.
+
+// rewriteJournalName is the journal of the rewrite in progress, in the log
+// directory.
+const rewriteJournalName = "rewrite.journal"
+
+// rewriteTempSuffix ends the temporary files of a rewrite.
+const rewriteTempSuffix = ".rewrite"
+
+// rewriteTempMaxAge is how old a temporary file found without a journal
+// must be to count as left by a crash. Younger ones may be another cali
+// process's rewrite between steps 1 and 2.
+const rewriteTempMaxAge = 10 * time.Minute
+
+// ErrRewriteStuck means an interrupted rewrite can be neither finished nor
+// undone without a look by hand; the journal stays until someone removes
+// it. Writes fail with it until then, reads are unaffected.
+var ErrRewriteStuck = errors.New("an interrupted rewrite needs a look by hand")
+
+// rewriteStep, when set, is called after each step of a rewrite; an error
+// stops the rewrite there as a crash would, leaving what is on disk as is.
+// Only for tests.
+var rewriteStep func(step string) error
+
+// rewriteJournal is the journal of a rewrite: the files it replaces, in
+// the order they are renamed.
+type rewriteJournal struct {
+	Files []journaledFile `json:"files"`
+}
+
+type journaledFile struct {
+	Path   string `json:"path"`
+	Temp   string `json:"temp"`
+	Source string `json:"source"` // checksum of the contents replaced; "" for a new file
+	Result string `json:"result"` // checksum of the new contents
+}
+
+// RewriteRecovery describes a rewrite Recover found interrupted.
+type RewriteRecovery struct {
+	Files     []string // the files the rewrite covered
+	Completed bool     // the rewrite was finished; otherwise it was undone
+}
+
+// checksum returns the SHA-256 of the file at path, or "" when there is
+// none.
+func checksum(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return checksumOf(data), nil
+}
+
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeSynced writes data to a new temporary file named like path and
+// flushes it to disk.
+func writeSynced(path string, data []byte) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*"+rewriteTempSuffix)
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// syncDir flushes the renames in dir to disk where the system allows it.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// journalPath returns the path of the rewrite journal.
+func (f *FileStorage) journalPath() string {
+	return filepath.Join(f.logDir, rewriteJournalName)
+}
+
+// rewriteFiles replaces the files of paths with contents, in order, through
+// the journal. It first finishes any rewrite left interrupted. The caller
+// holds f.mu.
+func (f *FileStorage) rewriteFiles(ctx context.Context, paths []string, contents map[string]string) error {
+	if _, err := f.recover(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.logDir, 0755); err != nil {
+		return err
+	}
+	var journal rewriteJournal
+	staged := true
+	defer func() {
+		if staged {
+			for _, file := range journal.Files {
+				os.Remove(file.Temp)
+			}
+		}
+	}()
+	for _, path := range paths {
+		source, err := checksum(path)
+		if err != nil {
+			return err
+		}
+		data := []byte(contents[path])
+		tmp, err := writeSynced(path, data)
+		if err != nil {
+			return err
+		}
+		journal.Files = append(journal.Files, journaledFile{Path: path, Temp: tmp, Source: source, Result: checksumOf(data)})
+	}
+	if err := f.step("staged"); err != nil {
+		staged = false // a crash leaves the temporary files behind
+		return err
+	}
+	// Last chance to back out: nothing is replaced before the journal.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := writeSynced(f.journalPath(), data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, f.journalPath()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	syncDir(f.logDir)
+	// From here on the journal owns the temporary files: an error leaves
+	// the rewrite for recover to finish.
+	staged = false
+	if err := f.step("journaled"); err != nil {
+		return err
+	}
+
+	for i, file := range journal.Files {
+		if err := os.Rename(file.Temp, file.Path); err != nil {
+			return fmt.Errorf("replacing %s (the next write finishes it): %w", filepath.Base(file.Path), err)
+		}
+		if err := f.step(fmt.Sprintf("renamed %d", i+1)); err != nil {
+			return err
+		}
+	}
+	syncDir(f.logDir)
+	if err := os.Remove(f.journalPath()); err != nil {
+		return err
+	}
+	return f.step("done")
+}
+
+// ReplaceFiles replaces whole year files of the log, such as a backup's,
+// through the rewrite journal: an interrupted replace leaves every file
+// either as it was or as given. files maps the base name of each year file
+// to its new contents; year files not named stay as they are.
+func (f *FileStorage) ReplaceFiles(ctx context.Context, files map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	names := slices.Sorted(maps.Keys(files))
+	paths := make([]string, 0, len(names))
+	contents := map[string]string{}
+	for _, name := range names {
+		if name != filepath.Base(name) || !strings.HasPrefix(name, "workout-") || !strings.HasSuffix(name, ".log") {
+			return fmt.Errorf("%q is not the name of a year file", name)
+		}
+		path := filepath.Join(f.logDir, name)
+		paths = append(paths, path)
+		contents[path] = files[name]
+	}
+	return f.rewriteFiles(ctx, paths, contents)
+}
+
+func (f *FileStorage) step(name string) error {
+	if rewriteStep == nil {
+		return nil
+	}
+	return rewriteStep(name)
+}
+
+// Recover finishes a rewrite of the log files that was interrupted, by a
+// crash or a kill, after its journal was written, and removes the
+// temporary files of one interrupted before. It returns nil when there was
+// none. Rewrites call it first themselves; the cali command calls it when
+// it opens the log, to say what it found.
+//
+// A rewrite is finished when every file it covers either holds its new
+// contents or still has them staged. When the staged contents are gone
+// before any file was replaced, it is undone instead. Anything else, such
+// as a file changed since by another program, is an error, and the journal
+// is kept for a look by hand; the error then wraps ErrRewriteStuck.
+func (f *FileStorage) Recover(ctx context.Context) (*RewriteRecovery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.recover()
+}
+
+// recover is Recover with f.mu held.
+func (f *FileStorage) recover() (*RewriteRecovery, error) {
+	data, err := os.ReadFile(f.journalPath())
+	if errors.Is(err, os.ErrNotExist) {
+		f.removeStrayTemps()
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var journal rewriteJournal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("reading the rewrite journal %s: %w", f.journalPath(), err)
+	}
+
+	recovery := &RewriteRecovery{}
+	var pending, lost []journaledFile
+	replaced := 0
+	for _, file := range journal.Files {
+		recovery.Files = append(recovery.Files, file.Path)
+		current, err := checksum(file.Path)
+		if err != nil {
+			return nil, err
+		}
+		if current == file.Result {
+			replaced++
+			continue
+		}
+		staged, err := checksum(file.Temp)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case current != file.Source:
+			return nil, fmt.Errorf("%w: %s changed since the rewrite began; compare it with %s and remove that journal when done",
+				ErrRewriteStuck, file.Path, f.journalPath())
+		case staged == file.Result:
+			pending = append(pending, file)
+		default:
+			lost = append(lost, file)
+		}
+	}
+
+	switch {
+	case len(lost) == 0:
+		for _, file := range pending {
+			if err := os.Rename(file.Temp, file.Path); err != nil {
+				return nil, err
+			}
+		}
+		recovery.Completed = true
+	case replaced == 0:
+		for _, file := range journal.Files {
+			os.Remove(file.Temp)
+		}
+	default:
+		return nil, fmt.Errorf("%w: the new contents of %s are missing and other files were replaced; see %s",
+			ErrRewriteStuck, lost[0].Path, f.journalPath())
+	}
+	syncDir(f.logDir)
+	if err := os.Remove(f.journalPath()); err != nil {
+		return nil, err
+	}
+	return recovery, nil
+}
+
+// removeStrayTemps removes the temporary files of a rewrite stopped before
+// its journal was written; the files they were for are unchanged. Those
+// younger than rewriteTempMaxAge are left alone.
+func (f *FileStorage) removeStrayTemps() {
+	entries, err := os.ReadDir(f.logDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), rewriteTempSuffix) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) >= rewriteTempMaxAge {
+			os.Remove(filepath.Join(f.logDir, entry.Name()))
+		}
+	}
+}
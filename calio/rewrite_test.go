@@ -0,0 +1,329 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errCrash = errors.New("crash")
+
+// crashAt makes rewrites stop after step, as if the process died there.
+func crashAt(t *testing.T, step string) {
+	t.Helper()
+	rewriteStep = func(name string) error {
+		if name == step {
+			return errCrash
+		}
+		return nil
+	}
+	t.Cleanup(func() { rewriteStep = nil })
+}
+
+// twoYearLog returns a log with an entry in each of 2025 and 2026, and the
+// contents of its two files.
+func twoYearLog(t *testing.T) (*FileStorage, map[string]string) {
+	t.Helper()
+	f := NewFileStorage(t.TempDir())
+	for _, entry := range []WorkoutEntry{withDate(pushups, "2025-12-30"), withDate(squats, "2026-01-02")} {
+		if _, err := f.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f, fileContents(t, f)
+}
+
+func withDate(entry WorkoutEntry, date string) WorkoutEntry {
+	entry.Date = date
+	return entry
+}
+
+// fileContents returns the year files of f and what they hold.
+func fileContents(t *testing.T, f *FileStorage) map[string]string {
+	t.Helper()
+	contents := map[string]string{}
+	for _, date := range []string{"2025-01-01", "2026-01-01"} {
+		data, err := os.ReadFile(f.FileFor(date))
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		contents[filepath.Base(f.FileFor(date))] = string(data)
+	}
+	return contents
+}
+
+// leftovers lists the files of f's directory other than year files.
+func leftovers(t *testing.T, f *FileStorage) []string {
+	t.Helper()
+	entries, err := os.ReadDir(f.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "workout-") || !strings.HasSuffix(entry.Name(), ".log") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+func sameContents(a, b map[string]string) bool {
+	return len(a) == len(b) && a["workout-2025.log"] == b["workout-2025.log"] && a["workout-2026.log"] == b["workout-2026.log"]
+}
+
+// TestRewriteCrashAtEachStep stops a batch append over two year files after
+// each step of its rewrite, then recovers the log as the next cali run
+// would: the files are as before or as intended, never a mix.
+func TestRewriteCrashAtEachStep(t *testing.T) {
+	batch := []WorkoutEntry{withDate(squats, "2025-12-31"), withDate(pushups, "2026-01-03")}
+	tests := []struct {
+		step      string
+		applied   bool // the batch is in the files after Recover
+		recovered bool // Recover reports a rewrite it finished
+	}{
+		{"staged", false, false},
+		{"journaled", true, true},
+		{"renamed 1", true, true},
+		{"renamed 2", true, true},
+		{"done", true, false},
+	}
+	for _, tt := range tests {
+		f, before := twoYearLog(t)
+		want := fileContents(t, f)
+		if tt.applied {
+			applied, _ := twoYearLog(t)
+			if _, err := applied.AppendBatch(context.Background(), batch); err != nil {
+				t.Fatal(err)
+			}
+			want = fileContents(t, applied)
+		}
+
+		crashAt(t, tt.step)
+		if _, err := f.AppendBatch(context.Background(), batch); !errors.Is(err, errCrash) {
+			t.Fatalf("%s: AppendBatch = %v, want the crash", tt.step, err)
+		}
+		rewriteStep = nil
+
+		recovery, err := NewFileStorage(f.Dir()).Recover(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Recover = %v", tt.step, err)
+		}
+		if (recovery != nil) != tt.recovered {
+			t.Errorf("%s: Recover reported %+v, want a recovery: %v", tt.step, recovery, tt.recovered)
+		}
+		if recovery != nil && (!recovery.Completed || len(recovery.Files) != 2) {
+			t.Errorf("%s: Recover reported %+v, want both files finished", tt.step, recovery)
+		}
+		if got := fileContents(t, f); !sameContents(got, want) {
+			t.Errorf("%s: files after Recover = %q, want %q (before the batch: %q)", tt.step, got, want, before)
+		}
+		if names := leftovers(t, f); tt.step != "staged" && len(names) > 0 {
+			t.Errorf("%s: left %q behind", tt.step, names)
+		}
+	}
+}
+
+// TestReplaceFilesCrashAtEachStep stops a restore of both year files from
+// a backup after each step of its rewrite: after Recover the log is either
+// the one before the restore or the backup, never a mix.
+func TestReplaceFilesCrashAtEachStep(t *testing.T) {
+	backup := NewFileStorage(t.TempDir())
+	for _, entry := range []WorkoutEntry{withDate(squats, "2025-06-01"), withDate(pushups, "2026-06-01")} {
+		if _, err := backup.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := fileContents(t, backup)
+
+	tests := []struct {
+		step      string
+		restored  bool // the backup is in the files after Recover
+		recovered bool // Recover reports a rewrite it finished
+	}{
+		{"staged", false, false},
+		{"journaled", true, true},
+		{"renamed 1", true, true},
+		{"renamed 2", true, true},
+		{"done", true, false},
+	}
+	for _, tt := range tests {
+		f, before := twoYearLog(t)
+		want := before
+		if tt.restored {
+			want = files
+		}
+
+		crashAt(t, tt.step)
+		if err := f.ReplaceFiles(context.Background(), files); !errors.Is(err, errCrash) {
+			t.Fatalf("%s: ReplaceFiles = %v, want the crash", tt.step, err)
+		}
+		rewriteStep = nil
+
+		recovery, err := NewFileStorage(f.Dir()).Recover(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Recover = %v", tt.step, err)
+		}
+		if (recovery != nil) != tt.recovered {
+			t.Errorf("%s: Recover reported %+v, want a recovery: %v", tt.step, recovery, tt.recovered)
+		}
+		if got := fileContents(t, f); !sameContents(got, want) {
+			t.Errorf("%s: files after Recover = %q, want %q", tt.step, got, want)
+		}
+		if names := leftovers(t, f); tt.step != "staged" && len(names) > 0 {
+			t.Errorf("%s: left %q behind", tt.step, names)
+		}
+	}
+}
+
+// TestReplaceFilesOnlyYearFiles checks ReplaceFiles refuses names that
+// aren't year files of the log, before touching anything.
+func TestReplaceFilesOnlyYearFiles(t *testing.T) {
+	f, before := twoYearLog(t)
+	for _, name := range []string{"../workout-2025.log", "notes.txt", rewriteJournalName} {
+		if err := f.ReplaceFiles(context.Background(), map[string]string{name: "x\n"}); err == nil {
+			t.Errorf("ReplaceFiles(%q) = nil, want an error", name)
+		}
+	}
+	if got := fileContents(t, f); !sameContents(got, before) {
+		t.Errorf("files = %q, want them unchanged: %q", got, before)
+	}
+	if names := leftovers(t, f); len(names) > 0 {
+		t.Errorf("left %q behind", names)
+	}
+}
+
+// TestRecoverLeavesYoungTemps checks Recover only removes the temporary
+// files of a crash once they are too old to be another process's staging.
+func TestRecoverLeavesYoungTemps(t *testing.T) {
+	f, before := twoYearLog(t)
+	crashAt(t, "staged")
+	f.AppendBatch(context.Background(), []WorkoutEntry{withDate(squats, "2025-12-31")})
+	rewriteStep = nil
+
+	if _, err := f.Recover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	temps := leftovers(t, f)
+	if len(temps) != 1 || !strings.HasSuffix(temps[0], rewriteTempSuffix) {
+		t.Fatalf("after Recover of a fresh crash: %q, want its temporary file kept", temps)
+	}
+
+	old := time.Now().Add(-rewriteTempMaxAge - time.Minute)
+	if err := os.Chtimes(filepath.Join(f.Dir(), temps[0]), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Recover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if names := leftovers(t, f); len(names) > 0 {
+		t.Errorf("after Recover of an old crash: %q left", names)
+	}
+	if got := fileContents(t, f); !sameContents(got, before) {
+		t.Errorf("files = %q, want them unchanged: %q", got, before)
+	}
+}
+
+// TestRecoverUndoesLostStaging checks a rewrite whose staged contents are
+// gone before anything was replaced is undone.
+func TestRecoverUndoesLostStaging(t *testing.T) {
+	f, before := twoYearLog(t)
+	crashAt(t, "journaled")
+	f.AppendBatch(context.Background(), []WorkoutEntry{withDate(squats, "2025-12-31"), withDate(pushups, "2026-01-03")})
+	rewriteStep = nil
+	removeTemps(t, f, 1)
+
+	recovery, err := f.Recover(context.Background())
+	if err != nil || recovery == nil || recovery.Completed {
+		t.Fatalf("Recover = %+v, %v; want the rewrite undone", recovery, err)
+	}
+	if got := fileContents(t, f); !sameContents(got, before) {
+		t.Errorf("files = %q, want them unchanged: %q", got, before)
+	}
+	if names := leftovers(t, f); len(names) > 0 {
+		t.Errorf("%q left", names)
+	}
+}
+
+// removeTemps deletes the first n temporary files of f's directory.
+func removeTemps(t *testing.T, f *FileStorage, n int) {
+	t.Helper()
+	var temps []string
+	for _, name := range leftovers(t, f) {
+		if strings.HasSuffix(name, rewriteTempSuffix) {
+			temps = append(temps, name)
+		}
+	}
+	slices.Sort(temps)
+	for _, name := range temps[:n] {
+		if err := os.Remove(filepath.Join(f.Dir(), name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestRecoverStuck covers the two rewrites Recover leaves for a look by
+// hand. Until the journal goes, reads work and writes fail.
+func TestRecoverStuck(t *testing.T) {
+	batch := []WorkoutEntry{withDate(squats, "2025-12-31"), withDate(pushups, "2026-01-03")}
+	tests := []struct {
+		name  string
+		crash string
+		then  func(t *testing.T, f *FileStorage)
+	}{
+		{"file edited since", "journaled", func(t *testing.T, f *FileStorage) {
+			if err := os.WriteFile(f.FileFor("2025-01-01"), []byte("edited by hand\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"staging lost after a rename", "renamed 1", func(t *testing.T, f *FileStorage) {
+			removeTemps(t, f, 1)
+		}},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		f, _ := twoYearLog(t)
+		crashAt(t, tt.crash)
+		f.AppendBatch(ctx, batch)
+		rewriteStep = nil
+		tt.then(t, f)
+		stuck := fileContents(t, f)
+
+		if _, err := f.Recover(ctx); !errors.Is(err, ErrRewriteStuck) {
+			t.Errorf("%s: Recover = %v, want ErrRewriteStuck", tt.name, err)
+		}
+		read, err := f.SearchByDate(ctx, "2026-01-02")
+		if err != nil || len(read) != 1 {
+			t.Fatalf("%s: SearchByDate = %v, %v; want reads to work", tt.name, read, err)
+		}
+		if _, err := f.Append(ctx, withDate(pushups, "2026-01-04")); !errors.Is(err, ErrRewriteStuck) {
+			t.Errorf("%s: Append = %v, want ErrRewriteStuck", tt.name, err)
+		}
+		if err := f.RemoveEntry(ctx, read[0]); !errors.Is(err, ErrRewriteStuck) {
+			t.Errorf("%s: RemoveEntry = %v, want ErrRewriteStuck", tt.name, err)
+		}
+		if got := fileContents(t, f); !sameContents(got, stuck) {
+			t.Errorf("%s: files = %q, want them as left: %q", tt.name, got, stuck)
+		}
+
+		if err := os.Remove(f.journalPath()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Append(ctx, withDate(pushups, "2026-01-04")); err != nil {
+			t.Errorf("%s: Append once the journal is gone = %v", tt.name, err)
+		}
+	}
+}
+
+func TestRecoverCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewFileStorage(t.TempDir()).Recover(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Recover = %v, want context.Canceled", err)
+	}
+}
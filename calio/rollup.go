@@ -0,0 +1,113 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SummaryTab is the tab WriteRollup keeps month summaries in.
+const SummaryTab = "Summary"
+
+// RollupMarker starts the first cell of the row heading each month's
+// summary block, followed by the month: "[cali rollup] 2026-10". It is how
+// WriteRollup finds a block again, and rows starting with it are never
+// read as entries, wherever they are copied to.
+const RollupMarker = "[cali rollup]"
+
+// isRollupRow reports whether row heads a summary block.
+func isRollupRow(row []any) bool {
+	return len(row) > 0 && strings.HasPrefix(strings.TrimSpace(fmt.Sprint(row[0])), RollupMarker)
+}
+
+// rollupMonth returns the month named by the heading row of a summary
+// block.
+func rollupMonth(row []any) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(fmt.Sprint(row[0])), RollupMarker))
+}
+
+// isBlankRow reports whether every cell of row is empty.
+func isBlankRow(row []any) bool {
+	for _, value := range row {
+		if value != nil && strings.TrimSpace(fmt.Sprint(value)) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// upsertRollup returns the rows of SummaryTab with the block of month set
+// to block, headed by its marker row: replaced when rows already hold a
+// block for month, added otherwise. Blocks are ordered newest month first,
+// one empty row apart; rows above the first block, typed there by hand,
+// stay on top. The result depends only on the arguments.
+func upsertRollup(rows [][]any, month string, block [][]any) [][]any {
+	var preamble [][]any
+	blocks := map[string][][]any{}
+	current, duplicate := "", false
+	for _, row := range rows {
+		if isRollupRow(row) {
+			current = rollupMonth(row)
+			// A month found twice, from an edit by hand, keeps its first
+			// block; the others are dropped with the rewrite.
+			_, duplicate = blocks[current]
+		}
+		switch {
+		case duplicate:
+		case current == "":
+			preamble = append(preamble, row)
+		default:
+			blocks[current] = append(blocks[current], row)
+		}
+	}
+	blocks[month] = append([][]any{{RollupMarker + " " + month}}, block...)
+
+	trim := func(rows [][]any) [][]any {
+		for len(rows) > 0 && isBlankRow(rows[len(rows)-1]) {
+			rows = rows[:len(rows)-1]
+		}
+		return rows
+	}
+	out := trim(preamble)
+	months := make([]string, 0, len(blocks))
+	for month := range blocks {
+		months = append(months, month)
+	}
+	slices.Sort(months)
+	slices.Reverse(months)
+	for _, month := range months {
+		if len(out) > 0 {
+			out = append(out, []any{})
+		}
+		out = append(out, trim(blocks[month])...)
+	}
+	return out
+}
+
+// WriteRollup puts block, the summary of month (YYYY-MM), in SummaryTab,
+// creating the tab when the spreadsheet has none. Running it again for the
+// same month replaces that month's block in place of adding another; the
+// blocks of other months are kept. Cells are converted as in
+// WriteDashboard. The tab is read and rewritten whole, so values typed
+// into it by hand survive but formatting inside the blocks may not.
+func (s *SheetsStorage) WriteRollup(ctx context.Context, month string, block [][]any) error {
+	if s.sheetName == SummaryTab {
+		return fmt.Errorf("the log tab is named %q; the summary would overwrite it", SummaryTab)
+	}
+	sheetID, err := s.sideTab(ctx, SummaryTab)
+	if err != nil {
+		return err
+	}
+	resp, err := s.svc.Spreadsheets.Values.Get(s.spreadsheetID, a1Range(SummaryTab, "A:ZZ")).
+		ValueRenderOption("UNFORMATTED_VALUE").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("reading the %s tab: %w", SummaryTab, err)
+	}
+	rows := upsertRollup(resp.Values, month, block)
+	if err := s.replaceTab(ctx, sheetID, rows); err != nil {
+		return fmt.Errorf("writing the %s tab: %w", SummaryTab, err)
+	}
+	s.logf("Wrote the %s summary, %d row(s) in the %s tab\n", month, len(block)+1, SummaryTab)
+	return nil
+}
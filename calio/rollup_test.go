@@ -0,0 +1,158 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// rollupRows renders rows as upsertRollup returns them, one line per row
+// and cells separated by "|", so tables of them read like the tab.
+func rollupRows(rows [][]any) string {
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = fmt.Sprint(cell)
+		}
+		lines[i] = strings.Join(cells, "|")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TestUpsertRollup checks a month's block is added in month order, newest
+// first, or replaced in place of being added twice, and that rows typed
+// above the first block stay on top.
+func TestUpsertRollup(t *testing.T) {
+	march := [][]any{{"Sessions", 12}}
+	for _, test := range []struct {
+		name string
+		rows [][]any
+		want string
+	}{
+		{"empty tab", nil, "[cali rollup] 2026-03\nSessions|12"},
+		{
+			"older months below",
+			[][]any{{"[cali rollup] 2026-02"}, {"Sessions", 9}, {}, {"[cali rollup] 2026-01"}, {"Sessions", 8}},
+			"[cali rollup] 2026-03\nSessions|12\n\n[cali rollup] 2026-02\nSessions|9\n\n[cali rollup] 2026-01\nSessions|8",
+		},
+		{
+			"newer month above",
+			[][]any{{"[cali rollup] 2026-04"}, {"Sessions", 3}},
+			"[cali rollup] 2026-04\nSessions|3\n\n[cali rollup] 2026-03\nSessions|12",
+		},
+		{
+			"same month replaced",
+			[][]any{{"[cali rollup] 2026-03"}, {"Sessions", 5}, {"Squats", 4}, {}, {"[cali rollup] 2026-02"}, {"Sessions", 9}},
+			"[cali rollup] 2026-03\nSessions|12\n\n[cali rollup] 2026-02\nSessions|9",
+		},
+		{
+			"preamble kept",
+			[][]any{{"My summaries"}, {}, {}, {"[cali rollup] 2026-02"}, {"Sessions", 9}},
+			"My summaries\n\n[cali rollup] 2026-03\nSessions|12\n\n[cali rollup] 2026-02\nSessions|9",
+		},
+		{
+			"duplicate block dropped",
+			[][]any{{"[cali rollup] 2026-02"}, {"Sessions", 9}, {}, {" [cali rollup] 2026-02 "}, {"Sessions", 1}},
+			"[cali rollup] 2026-03\nSessions|12\n\n[cali rollup] 2026-02\nSessions|9",
+		},
+		{
+			"trailing blank rows trimmed",
+			[][]any{{"[cali rollup] 2026-02"}, {"Sessions", 9}, {}, {"", nil}, {}},
+			"[cali rollup] 2026-03\nSessions|12\n\n[cali rollup] 2026-02\nSessions|9",
+		},
+	} {
+		if got := rollupRows(upsertRollup(test.rows, "2026-03", march)); got != test.want {
+			t.Errorf("%s:\n%s\nwant:\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+// TestWriteRollup checks the Summary tab is created on first use and that
+// writing a month again replaces its block, keeping the other months.
+func TestWriteRollup(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSheets(DefaultSheetName)
+	s := fake.mustStorage(t, SheetsConfig{})
+
+	if err := s.WriteRollup(ctx, "2026-02", [][]any{{"Sessions", 9}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteRollup(ctx, "2026-03", [][]any{{"Sessions", 5}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteRollup(ctx, "2026-03", [][]any{{"Sessions", 12}, {"Squats", 7}}); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"[cali rollup] 2026-03"}, {"Sessions", "12"}, {"Squats", "7"},
+		nil,
+		{"[cali rollup] 2026-02"}, {"Sessions", "9"},
+	}
+	if got := fake.rows(SummaryTab); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("summary rows = %q, want %q", got, want)
+	}
+	if n := strings.Count(strings.Join(tabTitles(fake), ","), SummaryTab); n != 1 {
+		t.Errorf("%d summary tabs", n)
+	}
+
+	logTab := newFakeSheets(SummaryTab).mustStorage(t, SheetsConfig{SheetName: SummaryTab})
+	if err := logTab.WriteRollup(ctx, "2026-03", [][]any{{"Sessions", 1}}); err == nil {
+		t.Error("a log tab named Summary was overwritten")
+	}
+}
+
+// TestReadSkipsRollup checks a summary block copied into the log tab is not
+// read as entries, even rows of it that look like one, and that entries
+// after the blank row closing the block are.
+func TestReadSkipsRollup(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSheets(DefaultSheetName)
+	fake.setRows(DefaultSheetName,
+		logRow(withDate(pushups, "2026-02-27")),
+		[]string{"[cali rollup] 2026-02"},
+		[]string{"Sessions", "9"},
+		logRow(withDate(squats, "2026-02-28")),
+		nil,
+		logRow(withDate(squats, "2026-03-02")),
+		[]string{" [cali rollup] 2026-03", "copied"},
+		nil,
+		logRow(withDate(pushups, "2026-03-04")),
+	)
+	s := fake.mustStorage(t, SheetsConfig{})
+
+	want := []string{"2026-02-27 Pushups row 0", "2026-03-02 Squats row 5", "2026-03-04 Pushups row 8"}
+	describe := func(entries []WorkoutEntry) []string {
+		var got []string
+		for _, entry := range entries {
+			got = append(got, fmt.Sprintf("%s %s row %d", entry.Date, entry.Exercise, entry.RowIndex))
+		}
+		return got
+	}
+	all, err := s.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := describe(all); !slices.Equal(got, want) {
+		t.Errorf("All = %q, want %q", got, want)
+	}
+	inRange, err := s.Range(ctx, "2026-02-01", "2026-03-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := describe(inRange); !slices.Equal(got, want) {
+		t.Errorf("Range = %q, want %q", got, want)
+	}
+	var each []WorkoutEntry
+	if err := s.ForEach(ctx, "", "", func(entry WorkoutEntry) error {
+		each = append(each, entry)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := describe(each); !slices.Equal(got, want) {
+		t.Errorf("ForEach = %q, want %q", got, want)
+	}
+}
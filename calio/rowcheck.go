@@ -0,0 +1,81 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+)
+
+// sameEntry compares the stored columns of two entries, ignoring RowIndex.
+func sameEntry(a, b WorkoutEntry) bool {
+	a.RowIndex, b.RowIndex = 0, 0
+	return a == b
+}
+
+// rowHolds reports whether the values fetched for a single row, laid out as
+// layout, still hold target.
+func rowHolds(values [][]interface{}, target WorkoutEntry, layout columnLayout) bool {
+	if len(values) != 1 {
+		return false
+	}
+	return sameEntry(entryFromRow(values[0], target.RowIndex, layout), target)
+}
+
+// locateEntry returns the position of target, as a read returned it, in a
+// fresh read: the entry still on target's row when it holds the same
+// content, else the one entry holding it elsewhere. It fails with
+// ErrEntryChanged when the entry is gone or when more than one moved entry
+// matches, since deleting by guess could remove the wrong workout.
+func locateEntry(entries []WorkoutEntry, target WorkoutEntry) (int, error) {
+	var found []int
+	for i, entry := range entries {
+		if !sameEntry(entry, target) {
+			continue
+		}
+		if entry.RowIndex == target.RowIndex {
+			return i, nil
+		}
+		found = append(found, i)
+	}
+
+	switch len(found) {
+	case 1:
+		return found[0], nil
+	case 0:
+		return 0, fmt.Errorf("%w and the entry (%s %s - %s %s) is no longer there; nothing was removed",
+			ErrEntryChanged, target.Date, target.Exercise, target.Level, target.RepsSets)
+	default:
+		return 0, fmt.Errorf("%w and %d identical entries (%s %s - %s %s) match; nothing was removed, retry cali -r",
+			ErrEntryChanged, len(found), target.Date, target.Exercise, target.Level, target.RepsSets)
+	}
+}
+
+// confirmRow re-reads the row of tab that target was read from and returns
+// the row to delete. If other edits moved the entry, it is located again by
+// content.
+func (s *SheetsStorage) confirmRow(ctx context.Context, tab string, target WorkoutEntry) (int64, error) {
+	layout, err := s.layoutFor(ctx, tab)
+	if err != nil {
+		return 0, err
+	}
+	row := target.RowIndex + 1
+	resp, err := s.svc.Spreadsheets.Values.Get(
+		s.spreadsheetID,
+		a1Range(tab, fmt.Sprintf("%d:%d", row, row)),
+	).Context(ctx).Do()
+	if err != nil {
+		return 0, fmt.Errorf("verifying row %d: %w", row, err)
+	}
+	if rowHolds(resp.Values, target, layout) {
+		return target.RowIndex, nil
+	}
+
+	entries, err := s.readTabs(ctx, []string{tab})
+	if err != nil {
+		return 0, fmt.Errorf("re-reading sheet: %w", err)
+	}
+	i, err := locateEntry(entries, target)
+	if err != nil {
+		return 0, err
+	}
+	return entries[i].RowIndex, nil
+}
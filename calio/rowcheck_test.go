@@ -0,0 +1,140 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// logRow lays entry out as cali writes it, as the strings a read returns.
+func logRow(entry WorkoutEntry) []string {
+	var row []string
+	for _, value := range (&SheetsStorage{}).rowValues(entry, standardLayout) {
+		row = append(row, value.(string))
+	}
+	return row
+}
+
+func cells(row []string) []interface{} {
+	values := make([]interface{}, len(row))
+	for i, value := range row {
+		values[i] = value
+	}
+	return values
+}
+
+var (
+	pushups = WorkoutEntry{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2", Category: CategoryStrength}
+	squats  = WorkoutEntry{Date: "2026-03-04", Day: "A", Exercise: "Squats", Level: "Half", RepsSets: "35x2", Goal: "50x2", Category: CategoryStrength}
+)
+
+// readAt is entry as a read finds it on the 0-based row.
+func readAt(entry WorkoutEntry, row int64) WorkoutEntry {
+	return entryFromRow(cells(logRow(entry)), row, standardLayout)
+}
+
+func TestRowHolds(t *testing.T) {
+	target := readAt(pushups, 3)
+	tests := []struct {
+		name   string
+		values [][]interface{}
+		want   bool
+	}{
+		{"row still holds the entry", [][]interface{}{cells(logRow(pushups))}, true},
+		{"another entry shifted in", [][]interface{}{cells(logRow(squats))}, false},
+		{"row now empty", nil, false},
+		{"comment edited", [][]interface{}{cells(logRow(WorkoutEntry{Date: pushups.Date, Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2", Comment: "easy"}))}, false},
+		{"more than one row", [][]interface{}{cells(logRow(pushups)), cells(logRow(pushups))}, false},
+	}
+	for _, tt := range tests {
+		if got := rowHolds(tt.values, target, standardLayout); got != tt.want {
+			t.Errorf("%s: rowHolds = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLocateEntry(t *testing.T) {
+	target := readAt(pushups, 2)
+	tests := []struct {
+		name    string
+		entries []WorkoutEntry
+		want    int
+		wantErr bool
+	}{
+		{"row still holds the entry", []WorkoutEntry{readAt(squats, 1), readAt(pushups, 2)}, 1, false},
+		{"entry shifted by an insert", []WorkoutEntry{readAt(squats, 1), readAt(squats, 2), readAt(pushups, 3)}, 2, false},
+		{"entry shifted up by a delete", []WorkoutEntry{readAt(pushups, 1)}, 0, false},
+		{"entry removed", []WorkoutEntry{readAt(squats, 1), readAt(squats, 2)}, 0, true},
+		{"nothing left", nil, 0, true},
+		{"two identical shifted entries", []WorkoutEntry{readAt(squats, 1), readAt(pushups, 3), readAt(pushups, 4)}, 0, true},
+		{"identical entries, one still in place", []WorkoutEntry{readAt(pushups, 1), readAt(pushups, 2), readAt(pushups, 3)}, 1, false},
+	}
+	for _, tt := range tests {
+		got, err := locateEntry(tt.entries, target)
+		switch {
+		case tt.wantErr && !errors.Is(err, ErrEntryChanged):
+			t.Errorf("%s: locateEntry = %d, %v; want ErrEntryChanged", tt.name, got, err)
+		case !tt.wantErr && (err != nil || got != tt.want):
+			t.Errorf("%s: locateEntry = %d, %v; want %d", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+// TestConfirmRow runs confirmRow against a sheet changed in the browser
+// between reading an entry and removing it.
+func TestConfirmRow(t *testing.T) {
+	header := []string{"Date", "Day", "Exercise", "Level", "RepsxSets", "Goal", "Comment", "Type", "Category"}
+	tests := []struct {
+		name    string
+		edit    func(f *fakeSheets)
+		want    int64
+		wantErr bool
+	}{
+		{"row still holds the entry", func(*fakeSheets) {}, 2, false},
+		{"entry shifted by an insert", func(f *fakeSheets) { f.insertRow("Log", 1, logRow(squats)) }, 3, false},
+		{"entry removed", func(f *fakeSheets) { f.setRows("Log", header, logRow(squats)) }, 0, true},
+		{"two identical shifted entries", func(f *fakeSheets) {
+			f.insertRow("Log", 1, logRow(squats))
+			f.insertRow("Log", 4, logRow(pushups))
+		}, 0, true},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		f := newFakeSheets()
+		f.setRows("Log", header, logRow(squats), logRow(pushups))
+		s := f.mustStorage(t, SheetsConfig{})
+		read, err := s.SearchByDate(ctx, pushups.Date)
+		if err != nil || len(read) != 2 {
+			t.Fatalf("%s: SearchByDate = %d entries, %v", tt.name, len(read), err)
+		}
+		tt.edit(f)
+
+		got, err := s.confirmRow(ctx, "Log", read[1])
+		switch {
+		case tt.wantErr && !errors.Is(err, ErrEntryChanged):
+			t.Errorf("%s: confirmRow = %d, %v; want ErrEntryChanged", tt.name, got, err)
+		case !tt.wantErr && (err != nil || got != tt.want):
+			t.Errorf("%s: confirmRow = %d, %v; want row %d", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestSheetsRemoveEntryDeletesTheRowRead(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeSheets()
+	f.setRows("Log", logRow(squats), logRow(pushups))
+	s := f.mustStorage(t, SheetsConfig{})
+	read, err := s.SearchByDate(ctx, pushups.Date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.insertRow("Log", 0, logRow(squats))
+
+	if err := s.RemoveEntry(ctx, read[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.rows("Log"), [][]string{logRow(squats), logRow(squats)}; !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("rows left = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package calio
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseSchemaMarker(t *testing.T) {
+	tests := []struct {
+		value  string
+		schema int
+		writer string
+		ok     bool
+	}{
+		{"v1", 1, "", true},
+		{" v4 cali/v1.4.0 ", 4, "cali/v1.4.0", true},
+		{"v12 cali/dev", 12, "cali/dev", true},
+		{"", 0, "", false},
+		{"v0", 0, "", false},
+		{"4 cali", 0, "", false},
+		{"version", 0, "", false},
+	}
+	for _, tt := range tests {
+		schema, writer, ok := parseSchemaMarker(tt.value)
+		if schema != tt.schema || writer != tt.writer || ok != tt.ok {
+			t.Errorf("parseSchemaMarker(%q) = %d, %q, %v", tt.value, schema, writer, ok)
+		}
+	}
+	entry := WorkoutEntry{Schema: 4, Writer: "cali/v1.4.0"}
+	if schema, writer, _ := parseSchemaMarker(schemaMarker(entry)); schema != 4 || writer != "cali/v1.4.0" {
+		t.Errorf("the marker of %+v reads as %d, %q", entry, schema, writer)
+	}
+}
+
+// schemaFixtures are the same entry as each schema wrote it, plus a future
+// schema with fields this package doesn't know. Fields a schema doesn't
+// have are left alone even when something is in their place.
+var schemaFixtures = []struct {
+	name  string
+	row   []string
+	want  WorkoutEntry
+	newer bool
+}{
+	{"seven fields, unmarked",
+		[]string{"2026-03-04", "A", "Pushups", "Full", "20x2", "20x2", "solid"},
+		WorkoutEntry{Type: TypeStraightSets, Category: CategoryStrength}, false},
+	{"v1",
+		[]string{"2026-03-04", "A", "Pushups", "Full", "20x2", "20x2", "solid", "straight-sets", "strength", "sam", "v1 cali/v1.0.0", "45"},
+		WorkoutEntry{Type: TypeStraightSets, Category: CategoryStrength, User: "sam", Schema: 1, Writer: "cali/v1.0.0"}, false},
+	{"v4",
+		[]string{"2026-03-04", "A", "Pushups", "Full", "20x2", "20x2", "solid", "straight-sets", "strength", "", "v4 cali/v1.4.0", "45", "10kg", "2026-03-04T18:00:00Z"},
+		WorkoutEntry{Type: TypeStraightSets, Category: CategoryStrength, Schema: 4, Writer: "cali/v1.4.0", Duration: 45, Load: "10kg", LoggedAt: "2026-03-04T18:00:00Z"}, false},
+	{"future v9",
+		[]string{"2026-03-04", "A", "Pushups", "Full", "20x2", "20x2", "solid", "straight-sets", "strength", "", "v9 cali/v3.0.0", "45", "10kg", "2026-03-04T18:00:00Z", "RPE 8", "tempo 3-1-1"},
+		WorkoutEntry{Type: TypeStraightSets, Category: CategoryStrength, Schema: 9, Writer: "cali/v3.0.0", Duration: 45, Load: "10kg", LoggedAt: "2026-03-04T18:00:00Z"}, true},
+}
+
+// fixtureEntry fills in the fields every fixture has.
+func fixtureEntry(want WorkoutEntry) WorkoutEntry {
+	want.Date, want.Day, want.Exercise, want.Level, want.RepsSets, want.Goal, want.Comment = "2026-03-04", "A", "Pushups", "Full", "20x2", "20x2", "solid"
+	return want
+}
+
+func TestSchemaFixturesFile(t *testing.T) {
+	f := NewFileStorage(t.TempDir())
+	var lines []string
+	for _, fixture := range schemaFixtures {
+		lines = append(lines, strings.Join(fixture.row, "|"))
+	}
+	if err := os.WriteFile(f.FileFor("2026-03-04"), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := f.All(context.Background())
+	if err != nil || len(entries) != len(schemaFixtures) {
+		t.Fatalf("All = %d entries, %v", len(entries), err)
+	}
+	for i, fixture := range schemaFixtures {
+		want := fixtureEntry(fixture.want)
+		want.RowIndex = int64(i)
+		if entries[i] != want || NewerSchema(entries[i]) != fixture.newer {
+			t.Errorf("%s reads as %+v, want %+v", fixture.name, entries[i], want)
+		}
+	}
+}
+
+func TestSchemaFixturesSheets(t *testing.T) {
+	f := newFakeSheets()
+	rows := [][]string{{"Date", "Day", "Exercise", "Level", "RepsxSets", "Goal", "Comment", "Type", "Category", "% of goal", "User", "Schema", "Minutes", "Load", "Logged"}}
+	for _, fixture := range schemaFixtures {
+		// Sheets keep column J for the goal percentage.
+		row := append([]string(nil), fixture.row...)
+		if len(row) > 9 {
+			row = append(row[:9], append([]string{""}, row[9:]...)...)
+		}
+		rows = append(rows, row)
+	}
+	f.setRows("Log", rows...)
+	entries, err := f.mustStorage(t, SheetsConfig{}).All(context.Background())
+	if err != nil || len(entries) != len(schemaFixtures) {
+		t.Fatalf("All = %d entries, %v", len(entries), err)
+	}
+	for i, fixture := range schemaFixtures {
+		want := fixtureEntry(fixture.want)
+		want.RowIndex = int64(i + 1)
+		if entries[i] != want || NewerSchema(entries[i]) != fixture.newer {
+			t.Errorf("%s reads as %+v, want %+v", fixture.name, entries[i], want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// caliRuleTag marks the conditional format rules cali owns. Rules carry no
+// name or metadata, so the tag lives in the formula as N("…"), which is 0.
+const caliRuleTag = "cali:goal-met"
+
+// sheetColumnWidths are the pixel widths of columns A:I.
+var sheetColumnWidths = []int64{100, 50, 130, 150, 110, 90, 260, 110, 100}
+
+var (
+	goalMetColor    = &sheets.Color{Red: 0.85, Green: 0.94, Blue: 0.83}
+	headerBandColor = &sheets.Color{Red: 0.85, Green: 0.85, Blue: 0.85}
+	firstBandColor  = &sheets.Color{Red: 1, Green: 1, Blue: 1}
+	secondBandColor = &sheets.Color{Red: 0.95, Green: 0.95, Blue: 0.95}
+)
+
+// goalMetFormula is true for rows whose RepsxSets (E) reaches the Goal (F) in
+// total reps, for the plain "<reps>x<sets>" form both columns mostly use.
+// Rows with other forms (holds, ranges, per-set lists) stay unhighlighted.
+var goalMetFormula = `=AND(N("` + caliRuleTag + `")=0,` +
+	`REGEXMATCH(LOWER($E2),"^\d+x\d+$"),REGEXMATCH(LOWER($F2),"^\d+x\d+$"),` +
+	`PRODUCT(SPLIT(LOWER($E2),"x"))>=PRODUCT(SPLIT(LOWER($F2),"x")))`
+
+// Format styles the log tab, or every year tab in per-year mode, for reading
+// in the browser: a dropdown limiting Day to A/B/C, goal-met rows in green,
+// alternating row colors and column widths, with the schema marker column
+// hidden and the work columns as plain text (see textColumnRequests), all in
+// one BatchUpdate. It returns the tabs it formatted, none when per-year mode has no tabs yet.
+// Running it again replaces what it added before instead of stacking
+// duplicates.
+func (s *SheetsStorage) Format(ctx context.Context) ([]string, error) {
+	titles := s.readTabsFor("", "")
+	resp, err := s.svc.Spreadsheets.Get(s.spreadsheetID).
+		Fields("sheets(properties(sheetId,title),conditionalFormats,bandedRanges)").
+		Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("reading sheet formatting: %w", err)
+	}
+
+	existing := map[string]*sheets.Sheet{}
+	for _, sh := range resp.Sheets {
+		if sh.Properties != nil {
+			existing[sh.Properties.Title] = sh
+		}
+	}
+
+	var requests []*sheets.Request
+	var formatted []string
+	for _, title := range titles {
+		sh, ok := existing[title]
+		if !ok {
+			continue
+		}
+		layout, err := s.layoutFor(ctx, title)
+		if err != nil {
+			return nil, err
+		}
+		if layout != standardLayout {
+			return nil, fmt.Errorf("tab %q has reordered columns (%s); sheet format only handles cali's column order", title, describeLayout(layout))
+		}
+		requests = append(requests, clearFormatRequests(sh)...)
+		requests = append(requests, formatRequests(sh.Properties.SheetId)...)
+		formatted = append(formatted, title)
+	}
+	if len(formatted) == 0 {
+		return nil, nil
+	}
+
+	_, err = s.svc.Spreadsheets.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: requests,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	s.logf("Sent %d formatting request(s)\n", len(requests))
+	return formatted, nil
+}
+
+// logColumns is the range cali formats on a tab: columns A:I below the
+// header row, open-ended downwards.
+func logColumns(sheetID int64, startColumn, endColumn int64) *sheets.GridRange {
+	return &sheets.GridRange{
+		SheetId:          sheetID,
+		StartRowIndex:    1,
+		StartColumnIndex: startColumn,
+		EndColumnIndex:   endColumn,
+		ForceSendFields:  []string{"SheetId", "StartColumnIndex"},
+	}
+}
+
+// isCaliBanding recognizes the banding formatRequests adds by where it
+// starts, A1: banded ranges can't be tagged, and Sheets stores them with the
+// tab's current size rather than open-ended. Any other banding at A1 would
+// make AddBanding fail anyway, since bandings can't overlap.
+func isCaliBanding(banded *sheets.BandedRange) bool {
+	r := banded.Range
+	return r != nil && r.StartRowIndex == 0 && r.StartColumnIndex == 0
+}
+
+// clearFormatRequests deletes what an earlier cali sheet format added to sh.
+// Conditional rules are deleted from the highest index down so the indexes
+// of the remaining rules don't shift.
+func clearFormatRequests(sh *sheets.Sheet) []*sheets.Request {
+	sheetID := sh.Properties.SheetId
+	var requests []*sheets.Request
+
+	var tagged []int64
+	for i, rule := range sh.ConditionalFormats {
+		if rule.BooleanRule == nil || rule.BooleanRule.Condition == nil {
+			continue
+		}
+		for _, value := range rule.BooleanRule.Condition.Values {
+			if strings.Contains(value.UserEnteredValue, caliRuleTag) {
+				tagged = append(tagged, int64(i))
+				break
+			}
+		}
+	}
+	sort.Slice(tagged, func(i, j int) bool { return tagged[i] > tagged[j] })
+	for _, index := range tagged {
+		requests = append(requests, &sheets.Request{
+			DeleteConditionalFormatRule: &sheets.DeleteConditionalFormatRuleRequest{
+				SheetId:         sheetID,
+				Index:           index,
+				ForceSendFields: []string{"SheetId", "Index"},
+			},
+		})
+	}
+
+	for _, banded := range sh.BandedRanges {
+		if isCaliBanding(banded) {
+			requests = append(requests, &sheets.Request{
+				DeleteBanding: &sheets.DeleteBandingRequest{BandedRangeId: banded.BandedRangeId, ForceSendFields: []string{"BandedRangeId"}},
+			})
+		}
+	}
+	return requests
+}
+
+// formatRequests builds the requests that format one tab. Data validation and
+// column widths replace whatever is there, so only the conditional rule and
+// the banding need clearing first.
+func formatRequests(sheetID int64) []*sheets.Request {
+	columns := int64(len(sheetColumnWidths))
+	requests := []*sheets.Request{
+		{
+			SetDataValidation: &sheets.SetDataValidationRequest{
+				Range: logColumns(sheetID, 1, 2),
+				Rule: &sheets.DataValidationRule{
+					Condition: &sheets.BooleanCondition{
+						Type: "ONE_OF_LIST",
+						Values: []*sheets.ConditionValue{
+							{UserEnteredValue: "A"},
+							{UserEnteredValue: "B"},
+							{UserEnteredValue: "C"},
+						},
+					},
+					InputMessage: "Training day: A, B or C (empty for mobility)",
+					Strict:       true,
+					ShowCustomUi: true,
+				},
+			},
+		},
+		{
+			AddConditionalFormatRule: &sheets.AddConditionalFormatRuleRequest{
+				Index: 0,
+				Rule: &sheets.ConditionalFormatRule{
+					Ranges: []*sheets.GridRange{logColumns(sheetID, 0, columns)},
+					BooleanRule: &sheets.BooleanRule{
+						Condition: &sheets.BooleanCondition{
+							Type:   "CUSTOM_FORMULA",
+							Values: []*sheets.ConditionValue{{UserEnteredValue: goalMetFormula}},
+						},
+						Format: &sheets.CellFormat{BackgroundColor: goalMetColor},
+					},
+				},
+				ForceSendFields: []string{"Index"},
+			},
+		},
+		{
+			AddBanding: &sheets.AddBandingRequest{
+				BandedRange: &sheets.BandedRange{
+					Range: &sheets.GridRange{
+						SheetId:         sheetID,
+						EndColumnIndex:  columns,
+						ForceSendFields: []string{"SheetId"},
+					},
+					RowProperties: &sheets.BandingProperties{
+						HeaderColor:     headerBandColor,
+						FirstBandColor:  firstBandColor,
+						SecondBandColor: secondBandColor,
+					},
+				},
+			},
+		},
+	}
+
+	for i, width := range sheetColumnWidths {
+		requests = append(requests, &sheets.Request{
+			UpdateDimensionProperties: &sheets.UpdateDimensionPropertiesRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:         sheetID,
+					Dimension:       "COLUMNS",
+					StartIndex:      int64(i),
+					EndIndex:        int64(i + 1),
+					ForceSendFields: []string{"SheetId", "StartIndex"},
+				},
+				Properties: &sheets.DimensionProperties{PixelSize: width},
+				Fields:     "pixelSize",
+			},
+		})
+	}
+	requests = append(requests, &sheets.Request{
+		UpdateDimensionProperties: &sheets.UpdateDimensionPropertiesRequest{
+			Range: &sheets.DimensionRange{
+				SheetId:         sheetID,
+				Dimension:       "COLUMNS",
+				StartIndex:      fieldSchema,
+				EndIndex:        fieldSchema + 1,
+				ForceSendFields: []string{"SheetId"},
+			},
+			Properties: &sheets.DimensionProperties{HiddenByUser: true},
+			Fields:     "hiddenByUser",
+		},
+	})
+	return append(requests, textColumnRequests(sheetID, standardLayout)...)
+}
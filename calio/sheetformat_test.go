@@ -0,0 +1,83 @@
+package calio
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// TestClearFormatRequests checks a second cali sheet format removes only
+// the rules and banding the first one added, highest rule first.
+func TestClearFormatRequests(t *testing.T) {
+	rule := func(formula string) *sheets.ConditionalFormatRule {
+		return &sheets.ConditionalFormatRule{BooleanRule: &sheets.BooleanRule{Condition: &sheets.BooleanCondition{
+			Type: "CUSTOM_FORMULA", Values: []*sheets.ConditionValue{{UserEnteredValue: formula}},
+		}}}
+	}
+	sh := &sheets.Sheet{
+		Properties: &sheets.SheetProperties{SheetId: 7},
+		ConditionalFormats: []*sheets.ConditionalFormatRule{
+			rule(`=$E2=""`),
+			rule(goalMetFormula),
+			{GradientRule: &sheets.GradientRule{}},
+			rule(goalMetFormula),
+		},
+		BandedRanges: []*sheets.BandedRange{
+			{BandedRangeId: 11, Range: &sheets.GridRange{StartRowIndex: 0, StartColumnIndex: 0}},
+			{BandedRangeId: 12, Range: &sheets.GridRange{StartRowIndex: 0, StartColumnIndex: 12}},
+		},
+	}
+
+	requests := clearFormatRequests(sh)
+	if len(requests) != 3 {
+		t.Fatalf("clearFormatRequests made %d requests, want 3", len(requests))
+	}
+	for i, index := range []int64{3, 1} {
+		del := requests[i].DeleteConditionalFormatRule
+		if del == nil || del.SheetId != 7 || del.Index != index {
+			t.Errorf("request %d = %+v, want rule %d of sheet 7 deleted", i, requests[i], index)
+		}
+	}
+	if del := requests[2].DeleteBanding; del == nil || del.BandedRangeId != 11 {
+		t.Errorf("request 2 = %+v, want banding 11 deleted", requests[2])
+	}
+}
+
+func TestFormatRequests(t *testing.T) {
+	requests := formatRequests(7)
+	validation := requests[0].SetDataValidation
+	if validation == nil || validation.Range.StartColumnIndex != 1 || validation.Range.EndColumnIndex != 2 {
+		t.Fatalf("first request = %+v, want the Day column's validation", requests[0])
+	}
+	var days []string
+	for _, value := range validation.Rule.Condition.Values {
+		days = append(days, value.UserEnteredValue)
+	}
+	if strings.Join(days, ",") != "A,B,C" || !validation.Rule.Strict {
+		t.Errorf("Day validation allows %q, strict %v", days, validation.Rule.Strict)
+	}
+
+	// The goal-met rule carries the tag clearFormatRequests looks for.
+	added := requests[1].AddConditionalFormatRule
+	if added == nil || !strings.Contains(added.Rule.BooleanRule.Condition.Values[0].UserEnteredValue, caliRuleTag) {
+		t.Errorf("second request = %+v, want the tagged goal-met rule", requests[1])
+	}
+	if !isCaliBanding(requests[2].AddBanding.BandedRange) {
+		t.Error("the banding added isn't one isCaliBanding recognizes")
+	}
+
+	widths := 0
+	hidden := false
+	for _, request := range requests {
+		if update := request.UpdateDimensionProperties; update != nil {
+			if update.Properties.PixelSize > 0 {
+				widths++
+			}
+			hidden = hidden || update.Properties.HiddenByUser && update.Range.StartIndex == fieldSchema
+		}
+	}
+	if widths != len(sheetColumnWidths) || !hidden {
+		t.Errorf("%d column widths set, schema column hidden %v; want %d and hidden", widths, hidden, len(sheetColumnWidths))
+	}
+}
@@ -0,0 +1,84 @@
+package calio
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// exampleSpreadsheetID is shown when a value isn't a spreadsheet ID.
+const exampleSpreadsheetID = "1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms"
+
+// isSpreadsheetID reports whether s is made of the characters spreadsheet
+// IDs use: letters, digits, '-' and '_'.
+func isSpreadsheetID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSpreadsheetID returns the spreadsheet ID in value, which is either
+// the ID itself or a URL of the spreadsheet as copied from the browser:
+//
+//	https://docs.google.com/spreadsheets/d/<id>/edit#gid=0
+//	https://docs.google.com/spreadsheets/u/1/d/<id>/edit?usp=sharing
+//	docs.google.com/spreadsheets/d/<id>/
+//	https://docs.google.com/spreadsheet/ccc?key=<id>#gid=0
+//	https://drive.google.com/open?id=<id>
+//
+// Spaces and quotes around value are ignored. URLs of a published copy
+// (/spreadsheets/d/e/...) name no spreadsheet the API can open and are an
+// error, as is anything else that is neither an ID nor such a URL.
+func ParseSpreadsheetID(value string) (string, error) {
+	raw := value
+	value = strings.Trim(strings.TrimSpace(value), `"'<>`)
+	if isSpreadsheetID(value) {
+		return value, nil
+	}
+	invalid := func(why string) error {
+		return fmt.Errorf("%q is not a spreadsheet ID or URL (%s); an ID looks like %s, the part of the spreadsheet's URL after /d/",
+			raw, why, exampleSpreadsheetID)
+	}
+
+	if !strings.ContainsAny(value, "./") {
+		return "", invalid("IDs are letters, digits, '-' and '_' only")
+	}
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return "", invalid("unreadable URL")
+	}
+	host := strings.ToLower(u.Hostname())
+	if host != "google.com" && !strings.HasSuffix(host, ".google.com") {
+		return "", invalid("not a Google URL")
+	}
+
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	for i, segment := range segments {
+		if segment != "d" || i+1 >= len(segments) {
+			continue
+		}
+		id := segments[i+1]
+		if id == "e" {
+			return "", invalid("the URL of a published copy; use the URL from the address bar while editing")
+		}
+		if isSpreadsheetID(id) {
+			return id, nil
+		}
+	}
+	query := u.Query()
+	for _, name := range []string{"key", "id"} {
+		if id := query.Get(name); isSpreadsheetID(id) {
+			return id, nil
+		}
+	}
+	return "", invalid("no ID in the URL")
+}
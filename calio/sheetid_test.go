@@ -0,0 +1,103 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+func TestParseSpreadsheetID(t *testing.T) {
+	const id = "1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms"
+	valid := []string{
+		id,
+		"  " + id + "\n",
+		`"` + id + `"`,
+		"<" + id + ">",
+		"https://docs.google.com/spreadsheets/d/" + id + "/edit#gid=0",
+		"https://docs.google.com/spreadsheets/d/" + id + "/edit?usp=sharing",
+		"https://docs.google.com/spreadsheets/d/" + id + "/edit?gid=123456789#gid=123456789",
+		"https://docs.google.com/spreadsheets/d/" + id,
+		"https://docs.google.com/spreadsheets/d/" + id + "/",
+		"https://docs.google.com/spreadsheets/d/" + id + "/view",
+		"https://docs.google.com/spreadsheets/d/" + id + "/htmlview?pli=1",
+		"https://docs.google.com/spreadsheets/u/1/d/" + id + "/edit",
+		"https://docs.google.com/a/example.com/spreadsheets/d/" + id + "/edit",
+		"docs.google.com/spreadsheets/d/" + id + "/edit",
+		"http://docs.google.com/spreadsheets/d/" + id + "/copy",
+		"https://DOCS.GOOGLE.COM/spreadsheets/d/" + id + "/edit",
+		"https://docs.google.com/spreadsheet/ccc?key=" + id + "#gid=0",
+		"https://spreadsheets.google.com/ccc?key=" + id + "&hl=en",
+		"https://drive.google.com/open?id=" + id,
+		"https://docs.google.com/spreadsheets/d/" + id + "/edit#gid=0 ",
+	}
+	for _, value := range valid {
+		if got, err := ParseSpreadsheetID(value); got != id || err != nil {
+			t.Errorf("ParseSpreadsheetID(%q) = %q, %v", value, got, err)
+		}
+	}
+
+	invalid := []struct {
+		value, why string
+	}{
+		{"", "letters, digits"},
+		{"my sheet", "letters, digits"},
+		{"https://docs.google.com/spreadsheets/d/e/2PACX-1vQ/pubhtml", "published copy"},
+		{"https://example.com/spreadsheets/d/" + id + "/edit", "not a Google URL"},
+		{"https://docs.google.com.evil.example/spreadsheets/d/" + id, "not a Google URL"},
+		{"https://docs.google.com/spreadsheets/", "no ID"},
+		{"https://docs.google.com/spreadsheets/d/", "no ID"},
+		{"https://docs.google.com/document/d/bad!id/edit", "no ID"},
+		{"http://%zz", "unreadable"},
+	}
+	for _, tt := range invalid {
+		got, err := ParseSpreadsheetID(tt.value)
+		if err == nil {
+			t.Errorf("ParseSpreadsheetID(%q) = %q, want an error", tt.value, got)
+			continue
+		}
+		// The error shows what was given and what an ID looks like.
+		msg := err.Error()
+		if !strings.Contains(msg, tt.why) || !strings.Contains(msg, exampleSpreadsheetID) || !strings.Contains(msg, fmt.Sprintf("%q", tt.value)) {
+			t.Errorf("ParseSpreadsheetID(%q): %v", tt.value, err)
+		}
+	}
+}
+
+// TestSheetsStorageURL opens the fake spreadsheet by its URL, and checks a
+// malformed ID is a configuration error that never reaches the API.
+func TestSheetsStorageURL(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSheets(DefaultSheetName)
+	var logged strings.Builder
+	cfg := SheetsConfig{
+		SpreadsheetID: "https://docs.google.com/spreadsheets/d/" + fakeSpreadsheetID + "/edit#gid=0",
+		ClientOptions: []option.ClientOption{
+			option.WithHTTPClient(&http.Client{Transport: fake}),
+			option.WithEndpoint("https://sheets.test/"),
+		},
+		Logf: func(format string, args ...any) { fmt.Fprintf(&logged, format, args...) },
+	}
+	s, err := NewSheetsStorage(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Append(ctx, pushups); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logged.String(), "Spreadsheet ID "+fakeSpreadsheetID+" taken from") {
+		t.Errorf("logged %q", logged.String())
+	}
+
+	clear(fake.calls)
+	cfg.SpreadsheetID = "docs.google.com/spreadsheets/d/e/2PACX/pubhtml"
+	_, err = NewSheetsStorage(ctx, cfg)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Name != "SpreadsheetID" || len(fake.calls) != 0 {
+		t.Errorf("a published copy's URL: %v after %v", err, fake.calls)
+	}
+}
@@ -0,0 +1,148 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// sheetMeta is what a SheetsStorage knows about the spreadsheet's tabs:
+// their sheet IDs, row counts and column layouts. Requests running at the
+// same time share it, so every access goes through its methods, which hold
+// mu only for the lookup itself, never across an API call.
+type sheetMeta struct {
+	mu      sync.Mutex
+	tabs    map[string]int64 // tab title -> sheet ID
+	rows    map[string]int64 // tab title -> row count, read up to
+	layouts map[string]tabLayout
+	texted  map[string]bool // tabs whose work columns were made plain text
+}
+
+func newSheetMeta(tabs, rows map[string]int64) *sheetMeta {
+	return &sheetMeta{tabs: tabs, rows: rows, layouts: map[string]tabLayout{}, texted: map[string]bool{}}
+}
+
+// tab returns the sheet ID of title; ok is false when the spreadsheet has
+// no such tab.
+func (m *sheetMeta) tab(title string) (sheetID int64, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sheetID, ok = m.tabs[title]
+	return sheetID, ok
+}
+
+// titles returns the title of every tab, in no particular order.
+func (m *sheetMeta) titles() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	titles := make([]string, 0, len(m.tabs))
+	for title := range m.tabs {
+		titles = append(titles, title)
+	}
+	return titles
+}
+
+// addTab records a tab just created, with rows rows when the API said how
+// many it has.
+func (m *sheetMeta) addTab(title string, sheetID int64, properties *sheets.GridProperties) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tabs[title] = sheetID
+	if properties != nil {
+		m.rows[title] = properties.RowCount
+	}
+}
+
+// rowCount returns how many rows of title reads go up to.
+func (m *sheetMeta) rowCount(title string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rows[title]
+}
+
+// grow counts n rows appended to title.
+func (m *sheetMeta) grow(title string, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rows[title] += n
+}
+
+// layout returns the layout learned for title, if any.
+func (m *sheetMeta) layout(title string) (tabLayout, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	layout, ok := m.layouts[title]
+	return layout, ok
+}
+
+// columns returns the column layout learned for title; the zero layout
+// when none is.
+func (m *sheetMeta) columns(title string) columnLayout {
+	layout, _ := m.layout(title)
+	return layout.columns
+}
+
+// learn records layout for title unless one is known already, and returns
+// the one in effect with whether it is the one given.
+func (m *sheetMeta) learn(title string, layout tabLayout) (tabLayout, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if known, ok := m.layouts[title]; ok {
+		return known, false
+	}
+	m.layouts[title] = layout
+	return layout, true
+}
+
+// markTexted records that title's work columns are being made plain text,
+// and reports whether they weren't yet.
+func (m *sheetMeta) markTexted(title string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.texted[title] {
+		return false
+	}
+	m.texted[title] = true
+	return true
+}
+
+// addTab returns the sheet ID of title, creating the tab with header as its
+// first row (none when nil) when the spreadsheet has none. Creation is
+// serialized, so two requests needing the same new tab add it once.
+func (s *SheetsStorage) addTab(ctx context.Context, title string, header []interface{}) (int64, error) {
+	if sheetID, ok := s.meta.tab(title); ok {
+		return sheetID, nil
+	}
+	s.creating.Lock()
+	defer s.creating.Unlock()
+	if sheetID, ok := s.meta.tab(title); ok {
+		return sheetID, nil
+	}
+
+	resp, err := s.svc.Spreadsheets.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: title}},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return 0, fmt.Errorf("creating sheet tab %q: %w", title, err)
+	}
+	properties := resp.Replies[0].AddSheet.Properties
+	// The tab is only made known once its header is in place: a row
+	// appended before would land where the header then goes.
+	defer s.meta.addTab(title, properties.SheetId, properties.GridProperties)
+	if header != nil {
+		_, err = s.svc.Spreadsheets.Values.Update(
+			s.spreadsheetID,
+			a1Range(title, "A1:"+columnName(len(header)-1)+"1"),
+			&sheets.ValueRange{Values: [][]interface{}{header}},
+		).ValueInputOption("RAW").Context(ctx).Do()
+		if err != nil {
+			return 0, fmt.Errorf("writing header to %q: %w", title, err)
+		}
+	}
+	s.logf("Created sheet tab %q\n", title)
+	return properties.SheetId, nil
+}
@@ -0,0 +1,207 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Large tabs are read in pages of SheetsConfig.PageSize rows rather than as
+// one range, which gets slow and can time out after a few thousand rows.
+// Pages are bounded by each tab's row count, known from the spreadsheet
+// metadata and kept up to date as rows are appended, so blank rows in the
+// middle of a tab don't end a read early.
+
+// DefaultPageSize is how many rows the Sheets backend reads per request when
+// SheetsConfig.PageSize is not set.
+const DefaultPageSize = 1000
+
+// pageRange is the A1 range of the rows [first, last] of tab, 1-based. It
+// spans whole rows, as columns may have been moved (see detectLayout).
+func pageRange(tab string, first, last int64) string {
+	return a1Range(tab, fmt.Sprintf("%d:%d", first, last))
+}
+
+// readTabs reads the given tabs in full and merges their entries in the
+// order given. Tabs that don't exist yet read as empty. RowIndex is relative
+// to the entry's own tab.
+func (s *SheetsStorage) readTabs(ctx context.Context, titles []string) ([]WorkoutEntry, error) {
+	return s.readPages(ctx, titles, nil)
+}
+
+// readPages reads titles from the top, one page of every unfinished tab per
+// BatchGet. After each page, more is called with the tab's entries so far
+// and stops reading that tab when it returns false; nil reads everything.
+// The entries of all tabs are returned in the order of titles.
+func (s *SheetsStorage) readPages(ctx context.Context, titles []string, more func(entries []WorkoutEntry) bool) ([]WorkoutEntry, error) {
+	type tabRead struct {
+		title   string
+		rows    int64
+		entries []WorkoutEntry
+		done    bool
+	}
+	var reads []*tabRead
+	var pages int
+	for _, title := range titles {
+		if _, ok := s.meta.tab(title); ok {
+			rows := s.meta.rowCount(title)
+			reads = append(reads, &tabRead{title: title, rows: rows})
+			pages = max(pages, int((rows+s.pageSize-1)/s.pageSize))
+		}
+	}
+	if len(reads) == 0 {
+		return nil, nil
+	}
+
+	s.progress.Start("Reading workout history…")
+	defer s.progress.Finish()
+
+	started := time.Now()
+	requests, rows := 0, 0
+	for first := int64(1); ; first += s.pageSize {
+		var ranges []string
+		var active []*tabRead
+		for _, read := range reads {
+			if !read.done && first <= read.rows {
+				ranges = append(ranges, pageRange(read.title, first, min(first+s.pageSize-1, read.rows)))
+				active = append(active, read)
+			}
+		}
+		if len(ranges) == 0 {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		resp, err := s.svc.Spreadsheets.Values.BatchGet(s.spreadsheetID).Ranges(ranges...).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		requests++
+		if pages > 1 {
+			s.progress.Step(requests, pages)
+		}
+		for i, valueRange := range resp.ValueRanges {
+			if i >= len(active) {
+				break
+			}
+			read := active[i]
+			rows += len(valueRange.Values)
+			if first == 1 {
+				s.learnLayout(read.title, firstValues(valueRange.Values))
+			}
+			layout := s.meta.columns(read.title)
+			read.entries = append(read.entries, entriesFromRows(valueRange.Values, first-1, layout)...)
+			if more != nil && !more(read.entries) {
+				read.done = true
+			}
+		}
+	}
+
+	var entries []WorkoutEntry
+	for _, read := range reads {
+		entries = append(entries, read.entries...)
+	}
+	s.logf("Read %d sheet row(s) from %s in %d request(s), %s\n",
+		rows, strings.Join(titles, ", "), requests, time.Since(started).Round(time.Millisecond))
+	return entries, nil
+}
+
+// readTail reads titles, given oldest first, from the bottom up a page at a
+// time and stops as soon as enough reports that the entries read so far,
+// which always end with the latest row, are all the caller needs.
+func (s *SheetsStorage) readTail(ctx context.Context, titles []string, enough func(entries []WorkoutEntry) bool) ([]WorkoutEntry, error) {
+	s.progress.Start("Reading workout history…")
+	defer s.progress.Finish()
+
+	started := time.Now()
+	var entries []WorkoutEntry
+	requests, rows := 0, 0
+	defer func() {
+		s.logf("Read %d sheet row(s) from the end of %s in %d request(s), %s\n",
+			rows, strings.Join(titles, ", "), requests, time.Since(started).Round(time.Millisecond))
+	}()
+	// A log with no tab yet makes no request to fail on.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i := len(titles) - 1; i >= 0; i-- {
+		title := titles[i]
+		if _, ok := s.meta.tab(title); !ok {
+			continue
+		}
+		for last := s.meta.rowCount(title); last >= 1; last -= s.pageSize {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			first := max(1, last-s.pageSize+1)
+			if first > 1 {
+				if _, err := s.layoutFor(ctx, title); err != nil {
+					return nil, err
+				}
+			}
+			resp, err := s.svc.Spreadsheets.Values.Get(s.spreadsheetID, pageRange(title, first, last)).Context(ctx).Do()
+			if err != nil {
+				return nil, err
+			}
+			requests++
+			rows += len(resp.Values)
+			if first == 1 {
+				s.learnLayout(title, firstValues(resp.Values))
+			}
+			layout := s.meta.columns(title)
+			entries = append(entriesFromRows(resp.Values, first-1, layout), entries...)
+			if enough(entries) {
+				return entries, nil
+			}
+		}
+	}
+	return entries, nil
+}
+
+// firstValues returns the first row of values read from the top of a tab.
+func firstValues(values [][]interface{}) []interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// pastDate reports whether entries, read from the top of a tab, are in date
+// order and already run past date, so a date-ordered tab holds no more
+// entries on date further down. Tabs that aren't in order are read in full;
+// rows with unreadable dates don't count either way.
+func pastDate(entries []WorkoutEntry, date string) bool {
+	var dated []WorkoutEntry
+	for _, entry := range entries {
+		if !UnreadableDate(entry) {
+			dated = append(dated, entry)
+		}
+	}
+	for i := 1; i < len(dated); i++ {
+		if dated[i].Date < dated[i-1].Date {
+			return false
+		}
+	}
+	return len(dated) > 0 && dated[len(dated)-1].Date > date
+}
+
+// searchTab returns the entries on date in tab, reading only as many pages
+// as pastDate requires.
+func (s *SheetsStorage) searchTab(ctx context.Context, tab, date string) ([]WorkoutEntry, error) {
+	entries, err := s.readPages(ctx, []string{tab}, func(entries []WorkoutEntry) bool {
+		return !pastDate(entries, date)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results []WorkoutEntry
+	for _, entry := range entries {
+		if entry.Date == date {
+			results = append(results, entry)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,117 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// pagedSheet is a Log tab of a header and thirty entries, one a day from
+// 2026-03-01, read eleven rows a page: with the blank rows below them, five
+// pages.
+func pagedSheet(t *testing.T) (*fakeSheets, *SheetsStorage) {
+	t.Helper()
+	rows := [][]string{{"Date", "Day", "Exercise", "Level", "RepsxSets", "Goal", "Comment", "Type", "Category"}}
+	for i := range 30 {
+		rows = append(rows, logRow(withDate(pushups, fmt.Sprintf("2026-03-%02d", i+1))))
+	}
+	f := newFakeSheets()
+	f.setRows("Log", rows...)
+	s := f.mustStorage(t, SheetsConfig{PageSize: 11})
+	clear(f.calls)
+	return f, s
+}
+
+func TestReadPages(t *testing.T) {
+	ctx := context.Background()
+	f, s := pagedSheet(t)
+	entries, err := s.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 30 {
+		t.Fatalf("All read %d entries, want 30", len(entries))
+	}
+	for i, entry := range entries {
+		if want := fmt.Sprintf("2026-03-%02d", i+1); entry.Date != want || entry.RowIndex != int64(i+1) {
+			t.Errorf("entry %d = %s at row %d, want %s at row %d", i, entry.Date, entry.RowIndex, want, i+1)
+		}
+	}
+	if f.calls["GET batchGet"] != 5 {
+		t.Errorf("All made %d batchGet requests, want 5 pages", f.calls["GET batchGet"])
+	}
+
+	// Every index read matches the row a second read finds it in.
+	again, err := s.SearchByDate(ctx, "2026-03-10")
+	if err != nil || len(again) != 1 || again[0].RowIndex != entries[9].RowIndex {
+		t.Errorf("SearchByDate(2026-03-10) = %+v, %v, want row %d", again, err, entries[9].RowIndex)
+	}
+}
+
+// TestReadPagesEarlyExit checks readers that need only part of the tab stop
+// paging once they have it.
+func TestReadPagesEarlyExit(t *testing.T) {
+	ctx := context.Background()
+
+	f, s := pagedSheet(t)
+	recent, err := s.Recent(ctx, 2)
+	if err != nil || len(recent) != 2 || recent[1].Date != "2026-03-30" || recent[1].RowIndex != 30 {
+		t.Errorf("Recent(2) = %+v, %v", recent, err)
+	}
+	// The blank rows at the end, the last entries and the header, rather
+	// than all five pages.
+	if pages := f.calls["GET values"]; pages != 3 {
+		t.Errorf("Recent(2) read %d pages, want 3", pages)
+	}
+
+	f, s = pagedSheet(t)
+	if _, date, err := s.LastTrainingDay(ctx); err != nil || date != "2026-03-30" {
+		t.Errorf("LastTrainingDay = %s, %v", date, err)
+	}
+	if pages := f.calls["GET values"]; pages != 3 {
+		t.Errorf("LastTrainingDay read %d pages, want 3", pages)
+	}
+
+	// A sorted tab is read until the dates pass the one searched for.
+	f, s = pagedSheet(t)
+	found, err := s.SearchByDate(ctx, "2026-03-02")
+	if err != nil || len(found) != 1 || found[0].RowIndex != 2 {
+		t.Errorf("SearchByDate(2026-03-02) = %+v, %v", found, err)
+	}
+	if f.calls["GET batchGet"] != 1 {
+		t.Errorf("SearchByDate early in a sorted tab read %d pages, want 1", f.calls["GET batchGet"])
+	}
+
+	// A tab found out of order is read in full.
+	f, _ = pagedSheet(t)
+	f.insertRow("Log", 1, logRow(withDate(squats, "2026-03-05")))
+	f.insertRow("Log", 12, logRow(withDate(squats, "2026-03-02")))
+	s = f.mustStorage(t, SheetsConfig{PageSize: 11})
+	clear(f.calls)
+	found, err = s.SearchByDate(ctx, "2026-03-02")
+	if err != nil || len(found) != 2 || found[0].RowIndex != 3 || found[1].Exercise != "Squats" || found[1].RowIndex != 12 {
+		t.Errorf("SearchByDate in an unsorted tab = %+v, %v", found, err)
+	}
+	if f.calls["GET batchGet"] != 5 {
+		t.Errorf("SearchByDate in an unsorted tab read %d pages, want all 5", f.calls["GET batchGet"])
+	}
+}
+
+func TestPastDate(t *testing.T) {
+	entry := func(date string) WorkoutEntry { return WorkoutEntry{Date: date} }
+	tests := []struct {
+		entries []WorkoutEntry
+		want    bool
+	}{
+		{nil, false},
+		{[]WorkoutEntry{entry("2026-03-01"), entry("2026-03-02")}, false},
+		{[]WorkoutEntry{entry("2026-03-01"), entry("2026-03-03")}, true},
+		{[]WorkoutEntry{entry("2026-03-04"), entry("2026-03-03")}, false},
+		{[]WorkoutEntry{entry("2026-03-01"), entry("someday"), entry("2026-03-05")}, true},
+	}
+	for _, tt := range tests {
+		if got := pastDate(tt.entries, "2026-03-02"); got != tt.want {
+			t.Errorf("pastDate(%v, 2026-03-02) = %v, want %v", tt.entries, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,521 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// SheetsConfig configures NewSheetsStorage.
+type SheetsConfig struct {
+	// SpreadsheetID is the ID from the spreadsheet's URL, or the URL itself
+	// (see ParseSpreadsheetID). Required.
+	SpreadsheetID string
+	// SheetName is the tab holding the log, or the tab name prefix when
+	// PerYear is set. Defaults to DefaultSheetName.
+	SheetName string
+	// CredentialsFile is the path to a service account JSON key. It may be
+	// left empty when ClientOptions supply credentials.
+	CredentialsFile string
+	// ClientOptions are passed to the Sheets client after the credentials,
+	// e.g. option.WithHTTPClient.
+	ClientOptions []option.ClientOption
+
+	// PerYear keeps one tab per year, named "<SheetName> <year>", created
+	// with a header row the first time that year is written.
+	PerYear bool
+	// GoalPercent, when set, fills the extra column J on append with the
+	// logged work as a percentage of the goal; ok=false leaves it empty.
+	// The column is never read back.
+	GoalPercent func(entry WorkoutEntry) (percent int, ok bool)
+	// PageSize is how many rows each read request fetches. Defaults to
+	// DefaultPageSize.
+	PageSize int
+	// Writer names the program in the schema marker of appended rows, e.g.
+	// "cali/1.4.0"; empty leaves it out.
+	Writer string
+
+	// Progress reports slow calls; nil means silent. It is called from
+	// every request, so a storage shared by concurrent callers needs one
+	// that copes with that.
+	Progress Progress
+	// Logf receives diagnostics such as row counts and API timing; nil
+	// discards them.
+	Logf func(format string, args ...any)
+	// Now returns the current time, used to pick the current year's tab and
+	// to ignore future-dated entries. Defaults to time.Now.
+	Now func() time.Time
+	// ForceUnrecognized writes to tabs that don't look like a cali log,
+	// which are otherwise refused with ErrUnrecognizedTab.
+	ForceUnrecognized bool
+}
+
+// SheetsStorage keeps the log in a Google Sheets spreadsheet, one entry per
+// row in columns A:I: Date, Day, Exercise, Level, RepsxSets, Goal, Comment,
+// Type, Category. Column J holds the optional % of goal, K the user of a
+// shared log, L the schema marker (see SchemaVersion), M the session
+// minutes, N the load and O when the entry was logged. A header row is
+// allowed; with one, columns are found by their heading, so they can be
+// reordered in the browser (see detectLayout).
+type SheetsStorage struct {
+	svc           *sheets.Service
+	spreadsheetID string
+	sheetName     string // the tab, or the tab name prefix in per-year mode
+	perYear       bool
+	goalPercent   func(WorkoutEntry) (int, bool)
+	meta          *sheetMeta
+	creating      sync.Mutex // held while adding a tab, see addTab
+	pageSize      int64
+	writer        string
+	progress      Progress
+	logf          func(format string, args ...any)
+	now           func() time.Time
+	force         bool // write to unrecognized tabs, see checkTab
+}
+
+// NewSheetsStorage connects to the spreadsheet and reads its tab list. The
+// log tab must exist unless cfg.PerYear is set. ctx bounds the connection
+// itself; each method of the returned storage takes its own.
+func NewSheetsStorage(ctx context.Context, cfg SheetsConfig) (*SheetsStorage, error) {
+	if cfg.SpreadsheetID == "" {
+		return nil, &ConfigError{Name: "SpreadsheetID"}
+	}
+	if cfg.SheetName == "" {
+		cfg.SheetName = DefaultSheetName
+	}
+	if cfg.Progress == nil {
+		cfg.Progress = noProgress{}
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(string, ...any) {}
+	}
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = DefaultPageSize
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	id, err := ParseSpreadsheetID(cfg.SpreadsheetID)
+	if err != nil {
+		return nil, &ConfigError{Name: "SpreadsheetID", Reason: "SpreadsheetID: " + err.Error()}
+	}
+	if id != cfg.SpreadsheetID {
+		cfg.Logf("Spreadsheet ID %s taken from %q\n", id, cfg.SpreadsheetID)
+		cfg.SpreadsheetID = id
+	}
+
+	cfg.Progress.Start("Connecting to Google Sheets…")
+	defer cfg.Progress.Finish()
+
+	opts := []option.ClientOption{option.WithScopes(sheets.SpreadsheetsScope)}
+	if cfg.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(cfg.CredentialsFile))
+	}
+	svc, err := sheets.NewService(ctx, append(opts, cfg.ClientOptions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("creating sheets service: %w", err)
+	}
+
+	resp, err := svc.Spreadsheets.Get(cfg.SpreadsheetID).Fields("sheets.properties").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("reading spreadsheet metadata: %w", err)
+	}
+
+	tabs := map[string]int64{}
+	rows := map[string]int64{}
+	var titles []string
+	for _, sh := range resp.Sheets {
+		if sh.Properties != nil {
+			titles = append(titles, sh.Properties.Title)
+			tabs[sh.Properties.Title] = sh.Properties.SheetId
+			if sh.Properties.GridProperties != nil {
+				rows[sh.Properties.Title] = sh.Properties.GridProperties.RowCount
+			}
+		}
+	}
+	if _, ok := tabs[cfg.SheetName]; !ok && !cfg.PerYear {
+		return nil, unknownTabError(cfg.SheetName, titles)
+	}
+
+	return &SheetsStorage{
+		svc:           svc,
+		spreadsheetID: cfg.SpreadsheetID,
+		sheetName:     cfg.SheetName,
+		perYear:       cfg.PerYear,
+		goalPercent:   cfg.GoalPercent,
+		meta:          newSheetMeta(tabs, rows),
+		pageSize:      int64(cfg.PageSize),
+		writer:        cfg.Writer,
+		progress:      cfg.Progress,
+		logf:          cfg.Logf,
+		now:           cfg.Now,
+		force:         cfg.ForceUnrecognized,
+	}, nil
+}
+
+// unknownTabError reports a missing log tab along with the tabs the
+// spreadsheet has, in their order, so a typo is easy to spot.
+func unknownTabError(name string, titles []string) error {
+	quoted := make([]string, len(titles))
+	for i, title := range titles {
+		quoted[i] = strconv.Quote(title)
+	}
+	return fmt.Errorf("sheet tab %q %w in spreadsheet (its tabs: %s)", name, ErrNotFound, strings.Join(quoted, ", "))
+}
+
+// SpreadsheetID returns the ID of the spreadsheet.
+func (s *SheetsStorage) SpreadsheetID() string {
+	return s.spreadsheetID
+}
+
+// SheetName returns the log tab, or the tab name prefix in per-year mode.
+func (s *SheetsStorage) SheetName() string {
+	return s.sheetName
+}
+
+// PerYear reports whether the log is split into one tab per year.
+func (s *SheetsStorage) PerYear() bool {
+	return s.perYear
+}
+
+// Append writes one row; see AppendBatch.
+func (s *SheetsStorage) Append(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, error) {
+	stored, err := s.AppendBatch(ctx, []WorkoutEntry{entry})
+	if err != nil {
+		return WorkoutEntry{}, err
+	}
+	return stored[0], nil
+}
+
+// AppendBatch writes all entries with a single Values.Append call per tab,
+// so a tab gets either every one of its rows or none of them. Only per-year
+// mode splits a batch, when it spans a new year. RowIndex of the results
+// comes from the range the API reports as written.
+func (s *SheetsStorage) AppendBatch(ctx context.Context, entries []WorkoutEntry) ([]WorkoutEntry, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	s.progress.Start(fmt.Sprintf("Saving %d workout(s)…", len(entries)))
+	defer s.progress.Finish()
+
+	var order []string
+	byTab := map[string][]WorkoutEntry{}
+	withUser := map[string]bool{}
+	tabOf := make([]string, len(entries))
+	entries = slices.Clone(entries)
+	for i, entry := range entries {
+		entry = stamped(entry, s.writer, s.now())
+		entries[i] = entry
+		tab := s.tabFor(yearFromDate(entry.Date, s.now))
+		tabOf[i] = tab
+		if _, ok := byTab[tab]; !ok {
+			order = append(order, tab)
+		}
+		if entry.User != "" {
+			withUser[tab] = true
+		}
+		byTab[tab] = append(byTab[tab], entry)
+	}
+
+	next := map[string]int64{} // row the tab's next new entry went to
+	for _, tab := range order {
+		if err := s.ensureTab(ctx, tab, withUser[tab]); err != nil {
+			return nil, err
+		}
+		if err := s.checkTab(ctx, tab); err != nil {
+			return nil, err
+		}
+		layout, err := s.layoutFor(ctx, tab)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([][]interface{}, len(byTab[tab]))
+		for i, entry := range byTab[tab] {
+			rows[i] = s.rowValues(entry, layout)
+		}
+		started := time.Now()
+		resp, err := s.svc.Spreadsheets.Values.Append(
+			s.spreadsheetID,
+			a1Range(tab, "A:"+layout.lastColumn()),
+			&sheets.ValueRange{Values: rows},
+		).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		// INSERT_ROWS grows the tab by exactly the rows written.
+		s.meta.grow(tab, int64(len(byTab[tab])))
+		// The rows are written either way; without a range they are only
+		// reported as stored at an unknown row.
+		next[tab] = -1
+		if resp.Updates != nil {
+			if first, ok := firstRow(resp.Updates.UpdatedRange); ok {
+				next[tab] = first
+			}
+			s.logf("Appended %s in %s\n", resp.Updates.UpdatedRange, time.Since(started).Round(time.Millisecond))
+		}
+		s.keepTyped(ctx, tab, layout)
+	}
+
+	stored := make([]WorkoutEntry, len(entries))
+	for i, entry := range entries {
+		row := next[tabOf[i]]
+		if row >= 0 {
+			next[tabOf[i]]++
+		}
+		stored[i] = storedAt(entry, row)
+	}
+	return stored, nil
+}
+
+// SheetsCellLimit is the most characters Google Sheets keeps in a cell.
+const SheetsCellLimit = 50000
+
+// fitCell cuts value to SheetsCellLimit characters, ending it with "…" when
+// cut; the API rejects the whole write otherwise.
+func fitCell(value string) string {
+	if utf8.RuneCountInString(value) <= SheetsCellLimit {
+		return value
+	}
+	return string([]rune(value)[:SheetsCellLimit-1]) + "…"
+}
+
+// rowValues lays entry out in the columns of layout. Columns cali doesn't
+// know are left empty.
+func (s *SheetsStorage) rowValues(entry WorkoutEntry, layout columnLayout) []interface{} {
+	row := make([]interface{}, layout.width())
+	for i := range row {
+		row[i] = ""
+	}
+	set := func(field int, value interface{}) { row[layout[field]] = value }
+	set(fieldDate, entry.Date)
+	set(fieldDay, entry.Day)
+	set(fieldExercise, entry.Exercise)
+	set(fieldLevel, entry.Level)
+	set(fieldRepsSets, entry.RepsSets)
+	set(fieldGoal, entry.Goal)
+	set(fieldComment, fitCell(entry.Comment))
+	set(fieldType, NormalizeWorkoutType(entry.Type))
+	set(fieldCategory, NormalizeCategory(entry.Category))
+	if s.goalPercent != nil {
+		// A number rather than text, so the sheet can chart it.
+		if p, ok := s.goalPercent(entry); ok {
+			set(fieldPercent, p)
+		}
+	}
+	set(fieldUser, entry.User)
+	set(fieldSchema, schemaMarker(entry))
+	set(fieldDuration, formatDuration(entry.Duration))
+	set(fieldLoad, entry.Load)
+	set(fieldLogged, entry.LoggedAt)
+	return row
+}
+
+// firstRow returns the 0-based first row of an A1 range such as
+// "'Log 2026'!A1002:K1003".
+func firstRow(a1 string) (int64, bool) {
+	cells := a1[strings.LastIndex(a1, "!")+1:]
+	start, _, _ := strings.Cut(cells, ":")
+	row, err := strconv.ParseInt(strings.TrimLeft(start, "ABCDEFGHIJKLMNOPQRSTUVWXYZ"), 10, 64)
+	if err != nil || row < 1 {
+		return 0, false
+	}
+	return row - 1, true
+}
+
+// TabFor returns the tab holding entries dated date.
+func (s *SheetsStorage) TabFor(date string) string {
+	return s.tabFor(yearFromDate(date, s.now))
+}
+
+// RowURL links to the row of entry, as returned by Append or a read, in the
+// spreadsheet's web UI.
+func (s *SheetsStorage) RowURL(entry WorkoutEntry) string {
+	row := entry.RowIndex + 1
+	sheetID, _ := s.meta.tab(s.TabFor(entry.Date))
+	return fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/edit#gid=%d&range=%d:%d",
+		s.spreadsheetID, sheetID, row, row)
+}
+
+// Recent returns up to limit of the latest entries, reading pages from the
+// bottom of the log until it has them. In per-year mode it reads only this
+// year's and last year's tabs.
+func (s *SheetsStorage) Recent(ctx context.Context, limit int) ([]WorkoutEntry, error) {
+	entries, err := s.readTail(ctx, s.recentTabs(), func(entries []WorkoutEntry) bool {
+		return len(entries) >= limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) <= limit {
+		return entries, nil
+	}
+	return entries[len(entries)-limit:], nil
+}
+
+// All reads every tab the log uses.
+func (s *SheetsStorage) All(ctx context.Context) ([]WorkoutEntry, error) {
+	return s.readAllEntries(ctx)
+}
+
+// Range reads only the tabs overlapping [since, until]; empty bounds are open.
+func (s *SheetsStorage) Range(ctx context.Context, since, until string) ([]WorkoutEntry, error) {
+	entries, err := s.readTabs(ctx, s.readTabsFor(since, until))
+	if err != nil {
+		return nil, err
+	}
+	return filterRange(entries, since, until), nil
+}
+
+// SearchByDate reads only the tab for date's year, and stops at the first
+// page past date while the rows are in date order, as cali appends them.
+func (s *SheetsStorage) SearchByDate(ctx context.Context, date string) ([]WorkoutEntry, error) {
+	return s.searchTab(ctx, s.tabFor(yearFromDate(date, s.now)), date)
+}
+
+// RemoveByDateIndex deletes the row of the index-th entry logged on date.
+func (s *SheetsStorage) RemoveByDateIndex(ctx context.Context, date string, index int) error {
+	tab := s.tabFor(yearFromDate(date, s.now))
+	matches, err := s.searchTab(ctx, tab, date)
+	if err != nil {
+		return err
+	}
+
+	if err := checkIndex(date, index, len(matches)); err != nil {
+		return err
+	}
+
+	targetRow, err := s.confirmRow(ctx, tab, matches[index])
+	if err != nil {
+		return err
+	}
+	return s.deleteRow(ctx, tab, targetRow)
+}
+
+// deleteRow deletes row (from 0) of tab.
+func (s *SheetsStorage) deleteRow(ctx context.Context, tab string, targetRow int64) error {
+	sheetID, _ := s.meta.tab(tab)
+	req := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{
+				DeleteDimension: &sheets.DeleteDimensionRequest{
+					Range: &sheets.DimensionRange{
+						SheetId:    sheetID,
+						Dimension:  "ROWS",
+						StartIndex: targetRow,
+						EndIndex:   targetRow + 1,
+					},
+				},
+			},
+		},
+	}
+
+	s.progress.Start("Removing entry…")
+	defer s.progress.Finish()
+	started := time.Now()
+	_, err := s.svc.Spreadsheets.BatchUpdate(s.spreadsheetID, req).Context(ctx).Do()
+	if err == nil {
+		s.logf("Deleted row %d of %q in %s\n", targetRow+1, tab, time.Since(started).Round(time.Millisecond))
+	}
+	return err
+}
+
+// LastTrainingDay reads the same tabs as Recent, from the bottom up until
+// it reaches a strength entry that isn't dated in the future.
+func (s *SheetsStorage) LastTrainingDay(ctx context.Context) (string, string, error) {
+	now := s.now()
+	entries, err := s.readTail(ctx, s.recentTabs(), func(entries []WorkoutEntry) bool {
+		_, date := LastStrengthDay(WithoutFuture(entries, now))
+		return date != ""
+	})
+	if err != nil {
+		return "", "", err
+	}
+	day, date := LastStrengthDay(WithoutFuture(entries, now))
+	return day, date, nil
+}
+
+func (s *SheetsStorage) readAllEntries(ctx context.Context) ([]WorkoutEntry, error) {
+	return s.readTabs(ctx, s.readTabsFor("", ""))
+}
+
+// recentTabs are the tabs Recent and LastTrainingDay read, oldest first: the
+// log tab, or this year's and last year's tabs in per-year mode so early
+// January still sees December.
+func (s *SheetsStorage) recentTabs() []string {
+	if !s.perYear {
+		return []string{s.sheetName}
+	}
+	year := s.now().Year()
+	return []string{s.tabFor(year - 1), s.tabFor(year)}
+}
+
+// entriesFromRows converts sheet values laid out as layout, skipping blank
+// rows, the header and summary blocks: a row marked with RollupMarker and
+// the rows below it up to the next blank one. The first row is row offset
+// (0-based) of the tab.
+func entriesFromRows(values [][]interface{}, offset int64, layout columnLayout) []WorkoutEntry {
+	var entries []WorkoutEntry
+	inRollup := false
+	for i, row := range values {
+		if inRollup = isRollupRow(row) || (inRollup && !isBlankRow(row)); inRollup {
+			continue
+		}
+		entry := entryFromRow(row, offset+int64(i), layout)
+		if entry.Date == "" {
+			continue
+		}
+		if strings.EqualFold(entry.Date, "date") {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// entryFromRow reads a row laid out as layout, normalizing its date. Fields
+// keep their column in every schema, newer ones only add columns after the
+// marker, so the marker sets the entry's Schema and Writer and says which of
+// those columns to read.
+func entryFromRow(row []interface{}, rowIndex int64, layout columnLayout) WorkoutEntry {
+	field := func(field int) string { return valueAt(row, layout[field]) }
+	entry := WorkoutEntry{
+		Day:      field(fieldDay),
+		Exercise: field(fieldExercise),
+		Level:    field(fieldLevel),
+		RepsSets: field(fieldRepsSets),
+		Goal:     field(fieldGoal),
+		Comment:  field(fieldComment),
+		Type:     NormalizeWorkoutType(field(fieldType)),
+		Category: NormalizeCategory(field(fieldCategory)),
+		User:     strings.TrimSpace(field(fieldUser)),
+		RowIndex: rowIndex,
+	}
+	if schema, writer, ok := parseSchemaMarker(field(fieldSchema)); ok {
+		entry.Schema, entry.Writer = schema, writer
+		if schema >= 2 {
+			entry.Duration = parseDuration(field(fieldDuration))
+		}
+		if schema >= 3 {
+			entry.Load = strings.TrimSpace(field(fieldLoad))
+		}
+		if schema >= 4 {
+			entry.LoggedAt = strings.TrimSpace(field(fieldLogged))
+		}
+	}
+	return withNormalizedDate(entry, field(fieldDate))
+}
+
+func valueAt(row []interface{}, idx int) string {
+	if idx < 0 || idx >= len(row) {
+		return ""
+	}
+	return fmt.Sprint(row[idx])
+}
@@ -0,0 +1,42 @@
+package calio
+
+import (
+	"context"
+	"testing"
+)
+
+// TestGoalPercentColumn checks appends fill column J with GoalPercent, as
+// a number, leave it blank where it has none, and reads ignore it.
+func TestGoalPercentColumn(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeSheets("Log")
+	percent := func(entry WorkoutEntry) (int, bool) {
+		if entry.Exercise == "Squats" {
+			return 0, false
+		}
+		return 75, true
+	}
+	s := f.mustStorage(t, SheetsConfig{GoalPercent: percent})
+	if _, err := s.AppendBatch(ctx, []WorkoutEntry{pushups, squats}); err != nil {
+		t.Fatal(err)
+	}
+	rows := f.rows("Log")
+	if len(rows) != 2 || rows[0][fieldPercent] != "75" || rows[1][fieldPercent] != "" {
+		t.Errorf("the log holds %q, want 75 in column J of the first row only", rows)
+	}
+
+	// A storage without GoalPercent reads the rows the same.
+	entries, err := f.mustStorage(t, SheetsConfig{}).All(ctx)
+	if err != nil || len(entries) != 2 || entries[0].RepsSets != pushups.RepsSets || entries[0].Comment != "" {
+		t.Errorf("All = %+v, %v", entries, err)
+	}
+
+	// Per-year tabs get the column's heading.
+	y := newFakeSheets("Log")
+	if _, err := y.mustStorage(t, SheetsConfig{PerYear: true, GoalPercent: percent}).Append(ctx, pushups); err != nil {
+		t.Fatal(err)
+	}
+	if header := y.rows("Log 2026")[0]; header[fieldPercent] != goalPercentHeader {
+		t.Errorf("the new tab's header is %q, want %q in column J", header, goalPercentHeader)
+	}
+}
@@ -0,0 +1,126 @@
+package calio
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// In per-year mode (SheetsConfig.PerYear) the Sheets backend keeps one tab
+// per year, named "<SheetName> <year>" (e.g. "Log 2026"), so each tab stays
+// small. Tabs are created with a header row the first time a year is written.
+
+// sheetHeader is the first row of tabs cali creates.
+var sheetHeader = []interface{}{"Date", "Day", "Exercise", "Level", "RepsxSets", "Goal", "Comment", "Type", "Category"}
+
+// goalPercentHeader heads the optional column J (see SheetsConfig.GoalPercent).
+const goalPercentHeader = "% of goal"
+
+// Headings of columns K (the user of a shared log), L (the schema marker),
+// M (the session minutes of schema 2), N (the load of schema 3) and O (the
+// time logged of schema 4); see fieldUser and the fields after it.
+const (
+	userHeader     = "User"
+	schemaHeader   = "Schema"
+	durationHeader = "Minutes"
+	loadHeader     = "Load"
+	loggedHeader   = "Logged"
+)
+
+func yearTabName(prefix string, year int) string {
+	return fmt.Sprintf("%s %d", prefix, year)
+}
+
+// yearTab is a per-year tab found in the spreadsheet.
+type yearTab struct {
+	Year  int
+	Title string
+}
+
+// yearTabs picks the "<prefix> <year>" tabs out of titles, oldest first.
+func yearTabs(titles []string, prefix string) []yearTab {
+	var tabs []yearTab
+	for _, title := range titles {
+		rest, ok := strings.CutPrefix(title, prefix+" ")
+		if !ok || len(rest) != 4 {
+			continue
+		}
+		year, err := strconv.Atoi(rest)
+		if err != nil {
+			continue
+		}
+		tabs = append(tabs, yearTab{Year: year, Title: title})
+	}
+	sort.Slice(tabs, func(i, j int) bool { return tabs[i].Year < tabs[j].Year })
+	return tabs
+}
+
+// tabsInRange keeps the tabs whose year overlaps [since, until]; empty bounds
+// are open.
+func tabsInRange(tabs []yearTab, since, until string) []string {
+	var titles []string
+	for _, tab := range tabs {
+		if since != "" && tab.Year < yearFromDate(since, time.Now) {
+			continue
+		}
+		if until != "" && tab.Year > yearFromDate(until, time.Now) {
+			continue
+		}
+		titles = append(titles, tab.Title)
+	}
+	return titles
+}
+
+// a1Range builds an A1 range on tab, quoting the name so titles with spaces
+// ("Log 2026") or quotes work.
+func a1Range(tab, cells string) string {
+	return "'" + strings.ReplaceAll(tab, "'", "''") + "'!" + cells
+}
+
+// tabFor returns the tab holding entries dated in year.
+func (s *SheetsStorage) tabFor(year int) string {
+	if !s.perYear {
+		return s.sheetName
+	}
+	return yearTabName(s.sheetName, year)
+}
+
+// readTabsFor returns the tabs to read for [since, until].
+func (s *SheetsStorage) readTabsFor(since, until string) []string {
+	if !s.perYear {
+		return []string{s.sheetName}
+	}
+	return tabsInRange(yearTabs(s.meta.titles(), s.sheetName), since, until)
+}
+
+// ensureTab creates a missing per-year tab with the header row, including
+// the User heading when the rows about to be written name a user. The
+// schema marker and duration columns are always headed.
+func (s *SheetsStorage) ensureTab(ctx context.Context, title string, withUser bool) error {
+	if _, ok := s.meta.tab(title); ok {
+		return nil
+	}
+	if !s.perYear {
+		return fmt.Errorf("sheet tab %q %w in spreadsheet", title, ErrNotFound)
+	}
+
+	percentHeader, user := "", ""
+	if s.goalPercent != nil {
+		percentHeader = goalPercentHeader
+	}
+	if withUser {
+		user = userHeader
+	}
+	header := append(slices.Clip(sheetHeader), percentHeader, user, schemaHeader, durationHeader, loadHeader, loggedHeader)
+	sheetID, err := s.addTab(ctx, title, header)
+	if err != nil {
+		return err
+	}
+	s.meta.learn(title, tabLayout{columns: standardLayout})
+	rememberTab(recognizedKey{spreadsheetID: s.spreadsheetID, sheetID: sheetID}, true)
+	return nil
+}
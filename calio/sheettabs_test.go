@@ -0,0 +1,64 @@
+package calio
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestYearTabs(t *testing.T) {
+	titles := []string{"Log 2026", "Log", "Log 2024", "Logs 2025", "Log 25", "Log 2025x", "Notes", "Log 2025"}
+	tabs := yearTabs(titles, "Log")
+	want := []yearTab{{2024, "Log 2024"}, {2025, "Log 2025"}, {2026, "Log 2026"}}
+	if !slices.Equal(tabs, want) {
+		t.Fatalf("yearTabs = %+v, want %+v", tabs, want)
+	}
+	tests := []struct {
+		since, until string
+		want         []string
+	}{
+		{"", "", []string{"Log 2024", "Log 2025", "Log 2026"}},
+		{"2025-06-01", "", []string{"Log 2025", "Log 2026"}},
+		{"", "2025-01-01", []string{"Log 2024", "Log 2025"}},
+		{"2025-01-01", "2025-12-31", []string{"Log 2025"}},
+		{"2027-01-01", "", nil},
+	}
+	for _, tt := range tests {
+		if got := tabsInRange(tabs, tt.since, tt.until); !slices.Equal(got, tt.want) {
+			t.Errorf("tabsInRange(%q, %q) = %q, want %q", tt.since, tt.until, got, tt.want)
+		}
+	}
+	if got := a1Range("Ziad's Log 2026", "A:I"); got != "'Ziad''s Log 2026'!A:I" {
+		t.Errorf("a1Range = %q", got)
+	}
+}
+
+// TestPerYearRouting appends entries of two years and checks each went to
+// its year's tab, created with the header row, and a range read only
+// returns the entries of its years.
+func TestPerYearRouting(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeSheets("Log")
+	s := f.mustStorage(t, SheetsConfig{PerYear: true})
+	if _, err := s.AppendBatch(ctx, []WorkoutEntry{withDate(pushups, "2025-12-30"), withDate(squats, "2026-01-02")}); err != nil {
+		t.Fatal(err)
+	}
+	for title, exercise := range map[string]string{"Log 2025": "Pushups", "Log 2026": "Squats"} {
+		rows := f.rows(title)
+		if len(rows) != 2 || rows[0][0] != "Date" || rows[1][2] != exercise {
+			t.Errorf("%s holds %q, want the header and the %s entry", title, rows, exercise)
+		}
+	}
+	if rows := f.rows("Log"); len(rows) != 0 {
+		t.Errorf("the Log tab holds %q, want nothing", rows)
+	}
+
+	entries, err := s.Range(ctx, "2026-01-01", "")
+	if err != nil || len(entries) != 1 || entries[0].Exercise != "Squats" {
+		t.Errorf("Range(2026-01-01, \"\") = %+v, %v, want the Squats entry", entries, err)
+	}
+	all, err := s.All(ctx)
+	if err != nil || len(all) != 2 || all[0].Date != "2025-12-30" {
+		t.Errorf("All = %+v, %v, want both entries, oldest first", all, err)
+	}
+}
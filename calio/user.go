@@ -0,0 +1,137 @@
+package calio
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// UserStorage lets several people share one log. It tags the entries it
+// appends with Author and limits what it reads back to Show's entries, so
+// LastTrainingDay and everything built on the reads only see one person's
+// training. Removal indexes count the visible entries only.
+type UserStorage struct {
+	Storage
+
+	// Author is stamped on appended entries that have no User.
+	Author string
+	// Show limits reads to this user's entries; "" shows everyone's.
+	Show string
+	// Unattributed also shows entries without a User, e.g. rows logged
+	// before the log was shared.
+	Unattributed bool
+	// Now returns the current time, used to ignore future-dated entries in
+	// LastTrainingDay. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// FilterUser returns the entries logged by user, plus those without a user
+// when unattributed is set.
+func FilterUser(entries []WorkoutEntry, user string, unattributed bool) []WorkoutEntry {
+	var filtered []WorkoutEntry
+	for _, entry := range entries {
+		if entry.User == user || (unattributed && entry.User == "") {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+func (u *UserStorage) visible(entries []WorkoutEntry) []WorkoutEntry {
+	if u.Show == "" {
+		return entries
+	}
+	return FilterUser(entries, u.Show, u.Unattributed)
+}
+
+func (u *UserStorage) stamp(entry WorkoutEntry) WorkoutEntry {
+	if entry.User == "" {
+		entry.User = u.Author
+	}
+	return entry
+}
+
+// Append stamps entry with Author before writing it.
+func (u *UserStorage) Append(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, error) {
+	return u.Storage.Append(ctx, u.stamp(entry))
+}
+
+// AppendBatch stamps entries with Author before writing them.
+func (u *UserStorage) AppendBatch(ctx context.Context, entries []WorkoutEntry) ([]WorkoutEntry, error) {
+	stamped := make([]WorkoutEntry, len(entries))
+	for i, entry := range entries {
+		stamped[i] = u.stamp(entry)
+	}
+	return u.Storage.AppendBatch(ctx, stamped)
+}
+
+// Recent returns up to limit of Show's latest entries among those the
+// underlying Recent covers.
+func (u *UserStorage) Recent(ctx context.Context, limit int) ([]WorkoutEntry, error) {
+	entries, err := u.Storage.Recent(ctx, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	entries = u.visible(entries)
+	if len(entries) <= limit {
+		return entries, nil
+	}
+	return entries[len(entries)-limit:], nil
+}
+
+// All returns every entry of Show.
+func (u *UserStorage) All(ctx context.Context) ([]WorkoutEntry, error) {
+	entries, err := u.Storage.All(ctx)
+	return u.visible(entries), err
+}
+
+// Range returns Show's entries dated within [since, until].
+func (u *UserStorage) Range(ctx context.Context, since, until string) ([]WorkoutEntry, error) {
+	entries, err := u.Storage.Range(ctx, since, until)
+	return u.visible(entries), err
+}
+
+// SearchByDate returns Show's entries logged on date.
+func (u *UserStorage) SearchByDate(ctx context.Context, date string) ([]WorkoutEntry, error) {
+	entries, err := u.Storage.SearchByDate(ctx, date)
+	return u.visible(entries), err
+}
+
+// RemoveByDateIndex removes the index-th of Show's entries on date, by
+// translating index to its position among everyone's entries.
+func (u *UserStorage) RemoveByDateIndex(ctx context.Context, date string, index int) error {
+	if u.Show == "" {
+		return u.Storage.RemoveByDateIndex(ctx, date, index)
+	}
+	entries, err := u.Storage.SearchByDate(ctx, date)
+	if err != nil {
+		return err
+	}
+	var positions []int
+	for i, entry := range entries {
+		if len(FilterUser([]WorkoutEntry{entry}, u.Show, u.Unattributed)) == 1 {
+			positions = append(positions, i)
+		}
+	}
+	if err := checkIndex(date, index, len(positions)); err != nil {
+		return err
+	}
+	return u.Storage.RemoveByDateIndex(ctx, date, positions[index])
+}
+
+// LastTrainingDay returns the day and date of Show's latest strength entry.
+func (u *UserStorage) LastTrainingDay(ctx context.Context) (string, string, error) {
+	if u.Show == "" {
+		return u.Storage.LastTrainingDay(ctx)
+	}
+	entries, err := u.Storage.Recent(ctx, math.MaxInt)
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now
+	if u.Now != nil {
+		now = u.Now
+	}
+	day, date := LastStrengthDay(WithoutFuture(u.visible(entries), now()))
+	return day, date, nil
+}
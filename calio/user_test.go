@@ -0,0 +1,99 @@
+package calio
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseLogLineUser(t *testing.T) {
+	tests := []struct {
+		line, user string
+	}{
+		{"2026-03-04|A|Pushups|Full|20x2|20x2|", ""},
+		{"2026-03-04|A|Pushups|Full|20x2|20x2||straight_sets|strength", ""},
+		{"2026-03-04|A|Pushups|Full|20x2|20x2||straight_sets|strength| sam ", "sam"},
+		{serializeLogEntry(WorkoutEntry{Date: "2026-03-04", Exercise: "Pushups", User: "ziad"}), "ziad"},
+	}
+	for _, tt := range tests {
+		entry, ok := parseLogLine(tt.line)
+		if !ok || entry.User != tt.user {
+			t.Errorf("parseLogLine(%q) = user %q, %v, want %q", tt.line, entry.User, ok, tt.user)
+		}
+	}
+}
+
+// sharedBackends open a log holding one entry written before it was shared,
+// without a user, in the layout of each backend.
+var sharedBackends = []struct {
+	name string
+	open func(t *testing.T) Storage
+}{
+	{"file", func(t *testing.T) Storage {
+		s := NewFileStorage(t.TempDir())
+		if err := os.WriteFile(s.FileFor("2026-03-02"), []byte("2026-03-02|A|Pushups|Full|10x2|20x2|\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}},
+	{"sheets", func(t *testing.T) Storage {
+		f := newFakeSheets()
+		f.setRows("Log",
+			[]string{"Date", "Day", "Exercise", "Level", "RepsxSets", "Goal", "Comment", "Type", "Category"},
+			[]string{"2026-03-02", "A", "Pushups", "Full", "10x2", "20x2", "", "straight_sets", "strength"})
+		return f.mustStorage(t, SheetsConfig{})
+	}},
+}
+
+// TestUserStorageMixedRows shares a log that has an untagged entry between
+// two users and checks each only sees, removes and rotates on their own.
+func TestUserStorageMixedRows(t *testing.T) {
+	ctx := context.Background()
+	now := func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC) }
+	for _, backend := range sharedBackends {
+		raw := backend.open(t)
+		ziad := &UserStorage{Storage: raw, Author: "ziad", Show: "ziad", Unattributed: true, Now: now}
+		sam := &UserStorage{Storage: raw, Author: "sam", Show: "sam", Now: now}
+		if _, err := ziad.Append(ctx, withDate(squats, "2026-03-04")); err != nil {
+			t.Fatalf("%s: %v", backend.name, err)
+		}
+		if _, err := sam.AppendBatch(ctx, []WorkoutEntry{withDate(pushups, "2026-03-04"), withDate(squats, "2026-03-06")}); err != nil {
+			t.Fatalf("%s: %v", backend.name, err)
+		}
+
+		users := func(s Storage) []string {
+			entries, err := s.All(ctx)
+			if err != nil {
+				t.Fatalf("%s: %v", backend.name, err)
+			}
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Date+" "+entry.User)
+			}
+			return names
+		}
+		if got, want := users(ziad), []string{"2026-03-02 ", "2026-03-04 ziad"}; !slices.Equal(got, want) {
+			t.Errorf("%s: ziad sees %q, want %q", backend.name, got, want)
+		}
+		if got, want := users(sam), []string{"2026-03-04 sam", "2026-03-06 sam"}; !slices.Equal(got, want) {
+			t.Errorf("%s: sam sees %q, want %q", backend.name, got, want)
+		}
+		if got := users(&UserStorage{Storage: raw, Author: "ziad"}); len(got) != 4 {
+			t.Errorf("%s: everyone's entries are %q, want all 4", backend.name, got)
+		}
+
+		if _, date, err := ziad.LastTrainingDay(ctx); err != nil || date != "2026-03-04" {
+			t.Errorf("%s: ziad's last training day = %s, %v, want 2026-03-04", backend.name, date, err)
+		}
+
+		// Sam's first entry of the 4th is the second row of that date.
+		if err := sam.RemoveByDateIndex(ctx, "2026-03-04", 0); err != nil {
+			t.Fatalf("%s: %v", backend.name, err)
+		}
+		if got, want := users(&UserStorage{Storage: raw}), []string{"2026-03-02 ", "2026-03-04 ziad", "2026-03-06 sam"}; !slices.Equal(got, want) {
+			t.Errorf("%s: after sam's removal the log holds %q, want %q", backend.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrStopWalk is returned by a ForEach callback to stop early; ForEach then
+// returns nil.
+var ErrStopWalk = errors.New("stop walking the log")
+
+// EntryWalker is implemented by backends that can hand out entries one at a
+// time instead of reading the whole log into a slice first. Range and All
+// stay for callers that want every entry at once.
+type EntryWalker interface {
+	// ForEach calls fn with each entry dated within [since, until] (empty
+	// bounds are open), in the order Range returns them, holding at most a
+	// file's line or a sheet page in memory. fn returning ErrStopWalk stops
+	// the walk and ForEach returns nil; any other error from fn, from
+	// reading, or from ctx stops it and is returned. fn must not call the
+	// storage being walked: FileStorage holds its read lock meanwhile.
+	ForEach(ctx context.Context, since, until string, fn func(WorkoutEntry) error) error
+}
+
+// ForEach walks the entries of storage dated within [since, until], by
+// streaming when storage is an EntryWalker and from Range otherwise, with
+// the same rules for fn as EntryWalker.ForEach.
+func ForEach(ctx context.Context, storage Storage, since, until string, fn func(WorkoutEntry) error) error {
+	if walker, ok := storage.(EntryWalker); ok {
+		return walker.ForEach(ctx, since, until, fn)
+	}
+	entries, err := storage.Range(ctx, since, until)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return stopped(err)
+		}
+	}
+	return nil
+}
+
+// stopped turns ErrStopWalk into a clean end of the walk.
+func stopped(err error) error {
+	if errors.Is(err, ErrStopWalk) {
+		return nil
+	}
+	return err
+}
+
+// ForEach reads the year files one line at a time, oldest file first.
+func (f *FileStorage) ForEach(ctx context.Context, since, until string, fn func(WorkoutEntry) error) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	logFiles, err := f.yearFiles(since, until)
+	if err != nil {
+		return err
+	}
+	for _, logFile := range logFiles {
+		if err := walkLogFile(ctx, logFile, since, until, fn); err != nil {
+			return stopped(err)
+		}
+	}
+	return nil
+}
+
+// walkLogFile calls fn with the entries of logFile within [since, until].
+// An ErrStopWalk from fn is passed back for the caller to end the walk.
+func walkLogFile(ctx context.Context, logFile, since, until string, fn func(WorkoutEntry) error) error {
+	file, err := os.Open(logFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := newLogScanner(file)
+	for line := int64(0); scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry, ok := parseLogLine(strings.TrimSpace(scanner.Text()))
+		if !ok || !InRange(entry.Date, since, until) {
+			continue
+		}
+		entry.RowIndex = line
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// ForEach reads the tabs overlapping [since, until] a page at a time, one
+// tab after another, so only one page of rows is held at once.
+func (s *SheetsStorage) ForEach(ctx context.Context, since, until string, fn func(WorkoutEntry) error) error {
+	started := time.Now()
+	requests, rows := 0, 0
+	for _, title := range s.readTabsFor(since, until) {
+		if _, ok := s.meta.tab(title); !ok {
+			continue
+		}
+		count := s.meta.rowCount(title)
+		for first := int64(1); first <= count; first += s.pageSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			last := min(first+s.pageSize-1, count)
+			resp, err := s.svc.Spreadsheets.Values.Get(s.spreadsheetID, pageRange(title, first, last)).Context(ctx).Do()
+			if err != nil {
+				return err
+			}
+			requests++
+			rows += len(resp.Values)
+			if first == 1 {
+				s.learnLayout(title, firstValues(resp.Values))
+			}
+			for _, entry := range entriesFromRows(resp.Values, first-1, s.meta.columns(title)) {
+				if !InRange(entry.Date, since, until) {
+					continue
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := fn(entry); err != nil {
+					return stopped(err)
+				}
+			}
+		}
+	}
+	s.logf("Walked %d sheet row(s) in %d request(s), %s\n", rows, requests, time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// ForEach walks Show's entries of the underlying storage.
+func (u *UserStorage) ForEach(ctx context.Context, since, until string, fn func(WorkoutEntry) error) error {
+	return ForEach(ctx, u.Storage, since, until, func(entry WorkoutEntry) error {
+		if u.Show != "" && entry.User != u.Show && !(u.Unattributed && entry.User == "") {
+			return nil
+		}
+		return fn(entry)
+	})
+}
@@ -0,0 +1,150 @@
+package calio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+)
+
+// rangeOnly hides a backend's ForEach, for the fallback over Range.
+type rangeOnly struct{ Storage }
+
+// walked collects the entries ForEach hands fn, stopping with stop after
+// the n-th when n > 0.
+func walked(ctx context.Context, storage Storage, since, until string, n int, stop error) ([]WorkoutEntry, error) {
+	var entries []WorkoutEntry
+	err := ForEach(ctx, storage, since, until, func(entry WorkoutEntry) error {
+		entries = append(entries, entry)
+		if len(entries) == n {
+			return stop
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// TestForEach walks a local log, a sheet and the fallback over Range, and
+// checks each hands out what Range returns, in its order, and that an early
+// stop, an error from fn and a cancelled context each end the walk right
+// away.
+func TestForEach(t *testing.T) {
+	ctx := context.Background()
+	_, sheet := pagedSheet(t)
+	local := largeLog(t, 3)
+	for name, storage := range map[string]Storage{
+		"file":   local,
+		"sheets": sheet,
+		"range":  rangeOnly{local},
+		"user":   &UserStorage{Storage: local},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, bounds := range [][2]string{{"", ""}, {"2026-03-05", "2026-03-20"}, {"2025-12-01", ""}, {"2030-01-01", ""}} {
+				want, err := storage.Range(ctx, bounds[0], bounds[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := walked(ctx, storage, bounds[0], bounds[1], 0, nil)
+				if err != nil || !slices.Equal(got, want) {
+					t.Errorf("ForEach(%q, %q) walked %d entries, %v; Range returns %d", bounds[0], bounds[1], len(got), err, len(want))
+				}
+			}
+
+			if got, err := walked(ctx, storage, "", "", 3, ErrStopWalk); err != nil || len(got) != 3 {
+				t.Errorf("ForEach stopped with ErrStopWalk = %d entries, %v; want 3 and nil", len(got), err)
+			}
+			failed := errors.New("disk full")
+			if got, err := walked(ctx, storage, "", "", 5, failed); !errors.Is(err, failed) || len(got) != 5 {
+				t.Errorf("ForEach with fn failing = %d entries, %v; want 5 and fn's error", len(got), err)
+			}
+
+			cancelled, cancel := context.WithCancel(ctx)
+			defer cancel()
+			var calls int
+			err := ForEach(cancelled, storage, "", "", func(WorkoutEntry) error {
+				if calls++; calls == 2 {
+					cancel()
+				}
+				return nil
+			})
+			if !errors.Is(err, context.Canceled) || calls != 2 {
+				t.Errorf("ForEach cancelled after 2 entries = %d calls, %v", calls, err)
+			}
+		})
+	}
+}
+
+// TestForEachPages checks a sheet is read a page at a time and a walk that
+// stops early doesn't read the pages after it.
+func TestForEachPages(t *testing.T) {
+	ctx := context.Background()
+	f, s := pagedSheet(t)
+	if got, err := walked(ctx, s, "", "", 0, nil); err != nil || len(got) != 30 || got[29].RowIndex != 30 {
+		t.Fatalf("ForEach = %d entries, %v", len(got), err)
+	}
+	if f.calls["GET values"] != 5 {
+		t.Errorf("ForEach made %d reads, want 5 pages of 11 rows", f.calls["GET values"])
+	}
+	clear(f.calls)
+	if _, err := walked(ctx, s, "", "", 5, ErrStopWalk); err != nil || f.calls["GET values"] != 1 {
+		t.Errorf("ForEach stopped on the first page made %d reads, %v", f.calls["GET values"], err)
+	}
+}
+
+// TestForEachReadError checks an unreadable year file fails the walk after
+// the entries of the files before it.
+func TestForEachReadError(t *testing.T) {
+	f := largeLog(t, 2)
+	if err := os.Remove(f.FileFor("2026-01-01")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(f.FileFor("2026-01-01"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	got, err := walked(context.Background(), f, "", "", 0, nil)
+	if err == nil || len(got) == 0 || got[len(got)-1].Date >= "2026" {
+		t.Errorf("ForEach over a broken 2026 file = %d entries, %v; want 2025's and an error", len(got), err)
+	}
+}
+
+// BenchmarkForEach counts the strength entries of a synthetic 50,000-entry
+// local log by reading it all with All and by walking it with ForEach; run
+// with -benchmem, the walk allocates a fraction of what All holds at once.
+func BenchmarkForEach(b *testing.B) {
+	f := largeLog(b, 80)
+	ctx := context.Background()
+	if entries, err := f.All(ctx); err != nil || len(entries) < 49000 {
+		b.Fatalf("the log has %d entries, %v", len(entries), err)
+	}
+	b.Run("All", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			entries, err := f.All(ctx)
+			if err != nil {
+				b.Fatal(err)
+			}
+			count := 0
+			for _, entry := range entries {
+				if entry.Category == CategoryStrength {
+					count++
+				}
+			}
+		}
+	})
+	b.Run("ForEach", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			count := 0
+			err := f.ForEach(ctx, "", "", func(entry WorkoutEntry) error {
+				if entry.Category == CategoryStrength {
+					count++
+				}
+				return nil
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
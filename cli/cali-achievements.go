@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// achievementMark is added to the comment of the entry that first reaches
+// the progression standard of its level, so the day shows in the sheet too.
+const achievementMark = "⭑ progression standard reached"
+
+// reachesGoal reports whether entry counts towards completing its level:
+// a working session of set work that meets the goal stored with it.
+// Deload sessions and intervals never do.
+func reachesGoal(entry WorkoutEntry) bool {
+	return !isDeload(entry) && !isInterval(entry) && meetsGoal(entry.RepsSets, entry.Goal)
+}
+
+// achievements returns, per exercise and level, the earliest entry that
+// reached the goal, ordered by date. History is what counts, not the mark:
+// re-tests and backdated entries don't add a level twice, and entries
+// logged before marks existed still show.
+func achievements(entries []WorkoutEntry) []WorkoutEntry {
+	first := map[exerciseLevel]WorkoutEntry{}
+	var order []exerciseLevel
+	for _, entry := range entries {
+		if !reachesGoal(entry) {
+			continue
+		}
+		canonical := calio.Canonical(entry)
+		key := exerciseLevel{canonical.Exercise, canonical.Level}
+		earlier, seen := first[key]
+		if !seen {
+			order = append(order, key)
+		}
+		if !seen || entry.Date < earlier.Date {
+			first[key] = canonical
+		}
+	}
+	reached := make([]WorkoutEntry, len(order))
+	for i, key := range order {
+		reached[i] = first[key]
+	}
+	slices.SortStableFunc(reached, func(a, b WorkoutEntry) int { return cmp.Compare(a.Date, b.Date) })
+	return reached
+}
+
+// markAchievements adds achievementMark to the entries about to be saved
+// that reach a level's goal for the first time: no entry in storage, on any
+// date, nor earlier in entries reached it before. It reads the log only
+// when one of them reaches its goal; if that fails the entries are saved
+// unmarked.
+func markAchievements(ctx context.Context, storage Storage, entries []WorkoutEntry) []WorkoutEntry {
+	if !slices.ContainsFunc(entries, reachesGoal) {
+		return entries
+	}
+	reached := map[exerciseLevel]bool{}
+	err := calio.ForEach(ctx, storage, "", "", func(entry WorkoutEntry) error {
+		if reachesGoal(entry) {
+			entry = calio.Canonical(entry)
+			reached[exerciseLevel{entry.Exercise, entry.Level}] = true
+		}
+		return nil
+	})
+	if err != nil {
+		detail("Not checking for a completed level: %v\n", err)
+		return entries
+	}
+	marked := slices.Clone(entries)
+	for i, entry := range marked {
+		canonical := calio.Canonical(entry)
+		key := exerciseLevel{canonical.Exercise, canonical.Level}
+		if !reachesGoal(entry) || reached[key] {
+			continue
+		}
+		reached[key] = true
+		marked[i].Comment = strings.TrimSpace(entry.Comment + " " + achievementMark)
+	}
+	return marked
+}
+
+// isAchievement reports whether entry carries achievementMark.
+func isAchievement(entry WorkoutEntry) bool {
+	return strings.Contains(entry.Comment, achievementMark)
+}
+
+// achievementsOptions are the flags of cali achievements.
+type achievementsOptions struct {
+	Markdown bool
+}
+
+// newAchievementsFlagSet declares the flags of cali achievements into opts.
+func newAchievementsFlagSet(opts *achievementsOptions) *flag.FlagSet {
+	fs := newFlagSet("achievements")
+	fs.BoolVar(&opts.Markdown, "markdown", false, "print the timeline as Markdown")
+	return fs
+}
+
+// runAchievements lists the levels completed, per exercise in dataset
+// order, oldest first: the timeline of reaching each progression standard.
+func runAchievements(ctx context.Context, args []string) error {
+	var opts achievementsOptions
+	fs := newAchievementsFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	selected := append(calio.Exercises(), calio.MobilityExercises()...)
+	if fs.NArg() > 0 {
+		exercise, ok := normalizeExercise(strings.Join(fs.Args(), " "))
+		if !ok {
+			return usageError("%s", msg("error.unknown_exercise", strings.Join(fs.Args(), " ")))
+		}
+		selected = []string{exercise}
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	entries, err := storage.All(ctx)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	byExercise := map[string][]WorkoutEntry{}
+	for _, entry := range achievements(calio.WithoutFuture(entries, currentTime())) {
+		byExercise[entry.Exercise] = append(byExercise[entry.Exercise], entry)
+	}
+
+	var b strings.Builder
+	shown := 0
+	for _, exercise := range selected {
+		reached := byExercise[exercise]
+		if len(reached) == 0 {
+			continue
+		}
+		if shown > 0 {
+			b.WriteString("\n")
+		}
+		shown++
+		if opts.Markdown {
+			fmt.Fprintf(&b, "### %s\n\n", exercise)
+		} else {
+			fmt.Fprintf(&b, "%s:\n", exercise)
+		}
+		for _, entry := range reached {
+			if opts.Markdown {
+				fmt.Fprintf(&b, "- %s: %s (%s)\n", displayDate(entry.Date), entry.Level, entry.RepsSets)
+			} else {
+				fmt.Fprintf(&b, "  %s  %-30s %s\n", displayDate(entry.Date), entry.Level, entry.RepsSets)
+			}
+		}
+	}
+	if shown == 0 {
+		fmt.Println(msg("achievements.none"))
+		return errNoResults
+	}
+	_, err = io.WriteString(os.Stdout, b.String())
+	return err
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// pushupSession is a Pushups entry on date with the default goal of its level.
+func pushupSession(date, level, reps, comment string) WorkoutEntry {
+	return WorkoutEntry{Date: date, Day: "A", Exercise: "Pushups", Level: level, RepsSets: reps, Goal: resolveGoal("Pushups", level), Comment: comment}
+}
+
+// TestAchievements checks the timeline keeps the earliest session that met
+// each level's goal, whatever order the log holds them in.
+func TestAchievements(t *testing.T) {
+	withGoalOverrides(t, nil)
+	history := []WorkoutEntry{
+		pushupSession("2026-03-10", "Full", "20x2", "test"),
+		pushupSession("2026-03-12", "Full", "22x2", "re-test"),
+		pushupSession("2026-02-01", "Half", "25x2", ""),
+		pushupSession("2026-02-20", "Full", "20x2", "#deload"),
+		pushupSession("2026-03-01", "Incline", "10x1", "short of the goal"),
+		// Backdated after the test above, and under another spelling.
+		{Date: "2026-03-05", Day: "A", Exercise: "pushups", Level: "full", RepsSets: "21x2", Goal: resolveGoal("Pushups", "Full")},
+	}
+	var got []string
+	for _, entry := range achievements(history) {
+		got = append(got, entry.Date+" "+entry.Exercise+" - "+entry.Level+" "+entry.RepsSets)
+	}
+	want := []string{"2026-02-01 Pushups - Half 25x2", "2026-03-05 Pushups - Full 21x2"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("achievements = %q, want %q", got, want)
+	}
+	if reached := achievements([]WorkoutEntry{pushupSession("2026-03-10", "Full", "30x3", "#deload")}); len(reached) != 0 {
+		t.Errorf("a deload session completed %+v", reached)
+	}
+}
+
+// TestMarkAchievements checks only the entry that first meets a level's
+// goal gets the mark: not re-tests, deloads, entries short of the goal, nor
+// backdated entries for a level already reached later on.
+func TestMarkAchievements(t *testing.T) {
+	withGoalOverrides(t, nil)
+	ctx := context.Background()
+	storage := &memoryStorage{entries: []WorkoutEntry{
+		pushupSession("2026-03-10", "Full", "20x2", ""),
+		pushupSession("2026-03-01", "Half", "10x2", "not there yet"),
+	}}
+	for _, test := range []struct {
+		name  string
+		entry WorkoutEntry
+		want  bool
+	}{
+		{"first time", pushupSession("2026-03-12", "Half", "25x2", "good day"), true},
+		{"re-test", pushupSession("2026-03-12", "Full", "22x2", ""), false},
+		{"backdated", pushupSession("2026-02-01", "Full", "20x2", ""), false},
+		{"short of the goal", pushupSession("2026-03-12", "Kneeling", "5x1", ""), false},
+		{"deload", pushupSession("2026-03-12", "Kneeling", "30x3", "#deload"), false},
+		{"misspelled re-test", WorkoutEntry{Date: "2026-03-12", Exercise: "PUSHUPS", Level: "full", RepsSets: "20x2", Goal: "20x2"}, false},
+	} {
+		marked := markAchievements(ctx, storage, []WorkoutEntry{test.entry})[0]
+		if isAchievement(marked) != test.want {
+			t.Errorf("%s: marked %v (%q), want %v", test.name, isAchievement(marked), marked.Comment, test.want)
+		}
+		if isAchievement(test.entry) {
+			t.Errorf("%s: the entry passed in was changed", test.name)
+		}
+	}
+	if marked := markAchievements(ctx, storage, []WorkoutEntry{pushupSession("2026-03-12", "Half", "25x2", "good day")})[0]; marked.Comment != "good day "+achievementMark {
+		t.Errorf("comment = %q", marked.Comment)
+	}
+
+	// Two sessions of the same level in one save: only the first is marked.
+	batch := markAchievements(ctx, storage, []WorkoutEntry{
+		pushupSession("2026-03-12", "Kneeling", "30x3", ""),
+		pushupSession("2026-03-12", "Kneeling", "31x3", ""),
+	})
+	if !isAchievement(batch[0]) || isAchievement(batch[1]) {
+		t.Errorf("batch marks: %q, %q", batch[0].Comment, batch[1].Comment)
+	}
+}
+
+// TestAchievementsCommand logs sessions through the cli and checks the mark
+// is saved once, and that cali achievements and the Markdown report list
+// the level.
+func TestAchievementsCommand(t *testing.T) {
+	withGoalOverrides(t, nil)
+	home := t.TempDir()
+	if stdout, _, code := runCLIIn(t, home, "", "achievements"); code != 0 || !strings.Contains(stdout, msg("achievements.none")) {
+		t.Errorf("empty log: exit %d, %q", code, stdout)
+	}
+
+	yesterday := currentTime().AddDate(0, 0, -1).Format(calio.DateLayout)
+	for _, args := range [][]string{
+		{"log", "--day", "A", "--exercise", "Pushups", "--level", "Full", "--reps", "15x2", "--comment", "-"},
+		{"log", "--day", "A", "--exercise", "Pushups", "--level", "Full", "--reps", "20x2", "--comment", "good day"},
+		{"log", "--day", "A", "--exercise", "Pushups", "--level", "Full", "--reps", "22x2", "--comment", "-"},
+		{"log", "--deload", "--day", "A", "--exercise", "Pushups", "--level", "Full", "--reps", "25x2", "--comment", "-"},
+	} {
+		stdout, stderr, code := runCLIIn(t, home, "", args...)
+		if code != 0 {
+			t.Fatalf("cali %s exited %d: %s", strings.Join(args, " "), code, stderr)
+		}
+		announced := strings.Contains(stdout+stderr, "progression standard reached for the first time")
+		if want := args[len(args)-1] == "good day"; announced != want {
+			t.Errorf("cali %s announced the level: %v", strings.Join(args, " "), announced)
+		}
+	}
+	storage := calio.NewFileStorage(home + "/log")
+	var marked []string
+	for _, entry := range logged(t, storage) {
+		if isAchievement(entry) {
+			marked = append(marked, entry.Comment)
+		}
+	}
+	if len(marked) != 1 || marked[0] != "good day "+achievementMark {
+		t.Errorf("marked comments: %q", marked)
+	}
+
+	// A session imported afterwards, from the day before, is when the level
+	// was completed.
+	rows := filepath.Join(t.TempDir(), "import.csv")
+	if err := os.WriteFile(rows, []byte(yesterday+",A,Pushups,Full,21x2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, code := runCLIIn(t, home, "y\n", "import", rows); code != 0 {
+		t.Fatalf("cali import exited %d: %s", code, stderr)
+	}
+
+	stdout, stderr, code := runCLIIn(t, home, "", "achievements")
+	if want := "Pushups:\n  " + displayDate(yesterday) + "  Full"; code != 0 || !strings.HasPrefix(stdout, want) || strings.Count(stdout, "Full") != 1 {
+		t.Errorf("cali achievements exited %d: %q%s", code, stdout, stderr)
+	}
+	stdout, _, _ = runCLIIn(t, home, "", "achievements", "--markdown", "pushups")
+	if want := "### Pushups\n\n- " + displayDate(yesterday) + ": Full (21x2)\n"; stdout != want {
+		t.Errorf("cali achievements --markdown = %q, want %q", stdout, want)
+	}
+	if _, _, code := runCLIIn(t, home, "", "achievements", "squats"); code != 0 {
+		t.Errorf("an exercise without levels exited %d", code)
+	}
+	if _, _, code := runCLIIn(t, home, "", "achievements", "nope"); code != exitUsage {
+		t.Errorf("an unknown exercise exited %d", code)
+	}
+
+	stdout, _, _ = runCLIIn(t, home, "", "report", "--markdown", "--since", "1w")
+	if !strings.Contains(stdout, msg("report.completed")) || !strings.Contains(stdout, "Pushups - Full") {
+		t.Errorf("the report doesn't list the level:\n%s", stdout)
+	}
+}
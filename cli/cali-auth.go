@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// Sheets settings can live in the OS keyring (macOS Keychain, Windows
+// Credential Manager, the Secret Service on Linux) instead of the shell
+// environment. Environment variables still win when both are set.
+const keyringService = "cali-logger"
+
+const (
+	keySheetID     = "sheet-id"
+	keySheetName   = "sheet-name"
+	keyCredentials = "credentials"
+)
+
+// secretStore is the part of the keyring cali uses.
+type secretStore interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+type osKeyring struct{}
+
+func (osKeyring) Get(key string) (string, error) { return keyring.Get(keyringService, key) }
+func (osKeyring) Set(key, value string) error    { return keyring.Set(keyringService, key, value) }
+func (osKeyring) Delete(key string) error        { return keyring.Delete(keyringService, key) }
+
+var keyringStore secretStore = osKeyring{}
+
+// sheetsSetting is one resolved setting and where it came from ("env",
+// "keyring" or "default"); Source is empty when the setting is unset.
+type sheetsSetting struct {
+	Value  string
+	Source string
+	// Raw is the value as set when Value was extracted from it, such as
+	// the spreadsheet URL pasted in place of its ID; empty otherwise.
+	Raw string
+}
+
+type sheetsConfig struct {
+	SpreadsheetID sheetsSetting
+	SheetName     sheetsSetting
+	Credentials   sheetsSetting
+	// KeyringErr is set when the keyring was needed but couldn't be read,
+	// e.g. on headless Linux without a Secret Service.
+	KeyringErr error
+}
+
+// loadSheetsConfig resolves each setting from the environment first, then
+// the keyring. The keyring is only queried for settings the environment
+// leaves empty.
+func loadSheetsConfig(getenv func(string) string, store secretStore) sheetsConfig {
+	var cfg sheetsConfig
+	lookup := func(key string, envNames ...string) sheetsSetting {
+		for _, name := range envNames {
+			if value := strings.TrimSpace(getenv(name)); value != "" {
+				return sheetsSetting{Value: value, Source: "env"}
+			}
+		}
+		if cfg.KeyringErr != nil {
+			return sheetsSetting{}
+		}
+		value, err := store.Get(key)
+		if err != nil {
+			if !errors.Is(err, keyring.ErrNotFound) {
+				cfg.KeyringErr = err
+			}
+			return sheetsSetting{}
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			return sheetsSetting{Value: value, Source: "keyring"}
+		}
+		return sheetsSetting{}
+	}
+
+	cfg.SpreadsheetID = lookup(keySheetID, "CALI_SHEET_ID")
+	// A URL is replaced by the ID in it; a malformed value is kept for
+	// newSheetsStorage to report.
+	if id, err := calio.ParseSpreadsheetID(cfg.SpreadsheetID.Value); err == nil && id != cfg.SpreadsheetID.Value {
+		cfg.SpreadsheetID.Raw, cfg.SpreadsheetID.Value = cfg.SpreadsheetID.Value, id
+	}
+	cfg.SheetName = lookup(keySheetName, "CALI_SHEET_NAME")
+	if cfg.SheetName.Value == "" {
+		cfg.SheetName = sheetsSetting{Value: calio.DefaultSheetName, Source: "default"}
+	}
+	cfg.Credentials = lookup(keyCredentials, "CALI_GOOGLE_CREDENTIALS_JSON", "GOOGLE_APPLICATION_CREDENTIALS")
+	return cfg
+}
+
+// withSheet returns cfg with the tab named by --sheet, which wins over the
+// environment and the keyring. An empty sheet keeps the configured tab.
+func (cfg sheetsConfig) withSheet(sheet string) sheetsConfig {
+	if sheet != "" {
+		cfg.SheetName = sheetsSetting{Value: sheet, Source: "flag"}
+	}
+	return cfg
+}
+
+// missing explains an unset required setting, mentioning the keyring when it
+// couldn't be consulted.
+func (cfg sheetsConfig) missing(name, what string) error {
+	if cfg.KeyringErr != nil {
+		return &calio.ConfigError{Name: name, Reason: fmt.Sprintf("%s (the OS keyring is unavailable: %v; use the environment variables instead)", what, cfg.KeyringErr)}
+	}
+	return &calio.ConfigError{Name: name, Reason: fmt.Sprintf("%s, or save it with cali auth store", what)}
+}
+
+func runAuth(args []string) error {
+	if len(args) < 1 {
+		return usageError("usage: cali auth store|show|clear")
+	}
+	switch args[0] {
+	case "store":
+		return storeAuth(args[1:])
+	case "show":
+		showAuth()
+		return nil
+	case "clear":
+		return clearAuth()
+	}
+	return usageError("unknown auth command %q (use store, show or clear)", args[0])
+}
+
+// authOptions are the flags of cali auth store.
+type authOptions struct {
+	SheetID     string
+	SheetName   string
+	Credentials string
+}
+
+// newAuthFlagSet declares the flags of cali auth store into opts.
+func newAuthFlagSet(opts *authOptions) *flag.FlagSet {
+	fs := newFlagSet("auth store")
+	fs.StringVar(&opts.SheetID, "sheet-id", "", "spreadsheet ID")
+	fs.StringVar(&opts.SheetName, "sheet-name", "", "sheet tab name (default "+calio.DefaultSheetName+")")
+	fs.StringVar(&opts.Credentials, "credentials", "", "path to the service account JSON file")
+	return fs
+}
+
+// storeAuth saves the Sheets settings in the keyring, asking for any not
+// given as flags.
+func storeAuth(args []string) error {
+	var opts authOptions
+	fs := newAuthFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	ask := func(label, current string) string {
+		if current != "" {
+			return current
+		}
+		prompt(label)
+		input, _ := reader.ReadString('\n')
+		return strings.TrimSpace(input)
+	}
+
+	id := ask(msg("auth.sheet_id_prompt"), strings.TrimSpace(opts.SheetID))
+	if id == "" {
+		return usageError("%s", msg("auth.sheet_id_required"))
+	}
+	id, err := calio.ParseSpreadsheetID(id)
+	if err != nil {
+		return usageError("%v", err)
+	}
+	name := ask(msg("auth.sheet_name_prompt", calio.DefaultSheetName), strings.TrimSpace(opts.SheetName))
+	if name == "" {
+		name = calio.DefaultSheetName
+	}
+	credPath := ask(msg("auth.credentials_prompt"), strings.TrimSpace(opts.Credentials))
+	if credPath == "" {
+		return usageError("%s", msg("auth.credentials_required"))
+	}
+	credPath, err = filepath.Abs(credPath)
+	if err != nil {
+		return usageError("%v", err)
+	}
+	if _, err := os.Stat(credPath); err != nil {
+		return usageError("%v", err)
+	}
+
+	for _, setting := range []struct{ key, value string }{
+		{keySheetID, id},
+		{keySheetName, name},
+		{keyCredentials, credPath},
+	} {
+		if err := keyringStore.Set(setting.key, setting.value); err != nil {
+			return storageError("saving to the OS keyring", err)
+		}
+	}
+	fmt.Println(msg("auth.stored"))
+	return nil
+}
+
+func showAuth() {
+	cfg := loadSheetsConfig(os.Getenv, keyringStore)
+	for _, row := range []struct {
+		label   string
+		setting sheetsSetting
+	}{
+		{"Sheet ID", cfg.SpreadsheetID},
+		{"Sheet tab", cfg.SheetName},
+		{"Credentials", cfg.Credentials},
+	} {
+		if row.setting.Source == "" {
+			fmt.Printf("%-12s %s\n", row.label+":", msg("auth.unset"))
+			continue
+		}
+		fmt.Printf("%-12s %s (%s)\n", row.label+":", row.setting.Value, row.setting.Source)
+	}
+	if cfg.KeyringErr != nil {
+		fmt.Fprint(os.Stderr, msg("auth.keyring_unavailable", cfg.KeyringErr))
+	}
+}
+
+func clearAuth() error {
+	for _, key := range []string{keySheetID, keySheetName, keyCredentials} {
+		if err := keyringStore.Delete(key); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return storageError("clearing the OS keyring", err)
+		}
+	}
+	fmt.Println(msg("auth.cleared"))
+	return nil
+}
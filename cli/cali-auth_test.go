@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// fakeKeyring is a secretStore in memory. err, when set, is what every
+// call fails with, as on a system without a keyring.
+type fakeKeyring struct {
+	values map[string]string
+	err    error
+	gets   []string
+}
+
+func (k *fakeKeyring) Get(key string) (string, error) {
+	k.gets = append(k.gets, key)
+	if k.err != nil {
+		return "", k.err
+	}
+	value, ok := k.values[key]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return value, nil
+}
+
+func (k *fakeKeyring) Set(key, value string) error {
+	if k.err != nil {
+		return k.err
+	}
+	if k.values == nil {
+		k.values = map[string]string{}
+	}
+	k.values[key] = value
+	return nil
+}
+
+func (k *fakeKeyring) Delete(key string) error {
+	if k.err != nil {
+		return k.err
+	}
+	if _, ok := k.values[key]; !ok {
+		return keyring.ErrNotFound
+	}
+	delete(k.values, key)
+	return nil
+}
+
+func envOf(values map[string]string) func(string) string {
+	return func(name string) string { return values[name] }
+}
+
+const testSheetID = "1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms"
+
+func TestLoadSheetsConfigPrecedence(t *testing.T) {
+	stored := map[string]string{keySheetID: "keyring-id", keySheetName: "Keyring tab", keyCredentials: "/keyring/key.json"}
+	tests := []struct {
+		name                 string
+		env                  map[string]string
+		keyring              map[string]string
+		id, tab, credentials sheetsSetting
+	}{
+		{
+			name:        "environment wins over the keyring",
+			env:         map[string]string{"CALI_SHEET_ID": "env-id", "CALI_SHEET_NAME": "Env tab", "CALI_GOOGLE_CREDENTIALS_JSON": "/env/key.json"},
+			keyring:     stored,
+			id:          sheetsSetting{Value: "env-id", Source: "env"},
+			tab:         sheetsSetting{Value: "Env tab", Source: "env"},
+			credentials: sheetsSetting{Value: "/env/key.json", Source: "env"},
+		},
+		{
+			name:        "keyring fills what the environment leaves empty",
+			env:         map[string]string{"CALI_SHEET_ID": "env-id", "CALI_SHEET_NAME": "  "},
+			keyring:     stored,
+			id:          sheetsSetting{Value: "env-id", Source: "env"},
+			tab:         sheetsSetting{Value: "Keyring tab", Source: "keyring"},
+			credentials: sheetsSetting{Value: "/keyring/key.json", Source: "keyring"},
+		},
+		{
+			name:        "GOOGLE_APPLICATION_CREDENTIALS before the keyring",
+			env:         map[string]string{"GOOGLE_APPLICATION_CREDENTIALS": "/adc/key.json"},
+			keyring:     stored,
+			id:          sheetsSetting{Value: "keyring-id", Source: "keyring"},
+			tab:         sheetsSetting{Value: "Keyring tab", Source: "keyring"},
+			credentials: sheetsSetting{Value: "/adc/key.json", Source: "env"},
+		},
+		{
+			name:        "CALI_GOOGLE_CREDENTIALS_JSON before GOOGLE_APPLICATION_CREDENTIALS",
+			env:         map[string]string{"CALI_GOOGLE_CREDENTIALS_JSON": "/cali/key.json", "GOOGLE_APPLICATION_CREDENTIALS": "/adc/key.json"},
+			credentials: sheetsSetting{Value: "/cali/key.json", Source: "env"},
+			tab:         sheetsSetting{Value: "Log", Source: "default"},
+		},
+		{
+			name:    "default tab when neither has one",
+			keyring: map[string]string{keySheetID: "keyring-id", keySheetName: " "},
+			id:      sheetsSetting{Value: "keyring-id", Source: "keyring"},
+			tab:     sheetsSetting{Value: "Log", Source: "default"},
+		},
+		{
+			name: "spreadsheet URL reduced to its ID",
+			env:  map[string]string{"CALI_SHEET_ID": "https://docs.google.com/spreadsheets/d/" + testSheetID + "/edit#gid=0"},
+			id:   sheetsSetting{Value: testSheetID, Source: "env", Raw: "https://docs.google.com/spreadsheets/d/" + testSheetID + "/edit#gid=0"},
+			tab:  sheetsSetting{Value: "Log", Source: "default"},
+		},
+	}
+	for _, tt := range tests {
+		cfg := loadSheetsConfig(envOf(tt.env), &fakeKeyring{values: tt.keyring})
+		if cfg.SpreadsheetID != tt.id || cfg.SheetName != tt.tab || cfg.Credentials != tt.credentials || cfg.KeyringErr != nil {
+			t.Errorf("%s: got %+v, want id %+v, tab %+v, credentials %+v", tt.name, cfg, tt.id, tt.tab, tt.credentials)
+		}
+	}
+}
+
+func TestLoadSheetsConfigOnlyAsksTheKeyringForWhatIsMissing(t *testing.T) {
+	store := &fakeKeyring{}
+	loadSheetsConfig(envOf(map[string]string{"CALI_SHEET_ID": "id", "CALI_SHEET_NAME": "Log", "GOOGLE_APPLICATION_CREDENTIALS": "/key.json"}), store)
+	if len(store.gets) > 0 {
+		t.Errorf("keyring read for %q although the environment sets everything", store.gets)
+	}
+	loadSheetsConfig(envOf(map[string]string{"CALI_SHEET_ID": "id"}), store)
+	if strings.Join(store.gets, ",") != keySheetName+","+keyCredentials {
+		t.Errorf("keyring read for %q, want the tab and credentials only", store.gets)
+	}
+}
+
+func TestLoadSheetsConfigUnavailableKeyring(t *testing.T) {
+	store := &fakeKeyring{err: errors.New("no secret service")}
+	cfg := loadSheetsConfig(envOf(map[string]string{"CALI_SHEET_NAME": "Log"}), store)
+	if cfg.KeyringErr == nil || cfg.SpreadsheetID.Source != "" || cfg.Credentials.Source != "" {
+		t.Fatalf("got %+v, want the keyring error and the ID and credentials unset", cfg)
+	}
+	if len(store.gets) != 1 {
+		t.Errorf("keyring read %d times after failing, want once", len(store.gets))
+	}
+	if err := cfg.missing("CALI_SHEET_ID", "set CALI_SHEET_ID"); !strings.Contains(err.Error(), "no secret service") {
+		t.Errorf("missing = %v, want it to name the keyring error", err)
+	}
+
+	// Only a missing key is not an error.
+	cfg = loadSheetsConfig(envOf(nil), &fakeKeyring{})
+	if cfg.KeyringErr != nil {
+		t.Errorf("empty keyring: KeyringErr = %v", cfg.KeyringErr)
+	}
+	if err := cfg.missing("CALI_SHEET_ID", "set CALI_SHEET_ID"); !strings.Contains(err.Error(), "cali auth store") {
+		t.Errorf("missing = %v, want it to suggest cali auth store", err)
+	}
+}
+
+func TestStoreAndClearAuth(t *testing.T) {
+	store := &fakeKeyring{}
+	saved := keyringStore
+	keyringStore = store
+	t.Cleanup(func() { keyringStore = saved })
+	key := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(key, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storeAuth([]string{"--sheet-id", testSheetID, "--sheet-name", "Training", "--credentials", key}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := loadSheetsConfig(envOf(map[string]string{"CALI_SHEET_NAME": "Env tab"}), store)
+	if cfg.SpreadsheetID.Value != testSheetID || cfg.SheetName.Value != "Env tab" || cfg.Credentials != (sheetsSetting{Value: key, Source: "keyring"}) {
+		t.Errorf("after cali auth store: %+v", cfg)
+	}
+
+	if err := storeAuth([]string{"--sheet-id", "not an id!", "--sheet-name", "Training", "--credentials", key}); exitCode(err) != exitUsage {
+		t.Errorf("storing a bad ID: %v, want a usage error", err)
+	}
+	if err := clearAuth(); err != nil {
+		t.Fatal(err)
+	}
+	if err := clearAuth(); err != nil {
+		t.Errorf("clearing twice: %v", err)
+	}
+	if len(store.values) > 0 {
+		t.Errorf("keyring holds %v after cali auth clear", store.values)
+	}
+}
+
+// TestSheetIDURL checks a pasted spreadsheet URL is stored and used as its
+// ID, and a malformed CALI_SHEET_ID is reported with the value given.
+func TestSheetIDURL(t *testing.T) {
+	store := &fakeKeyring{}
+	saved := keyringStore
+	keyringStore = store
+	t.Cleanup(func() { keyringStore = saved })
+	key := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(key, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	url := "https://docs.google.com/spreadsheets/u/0/d/" + testSheetID + "/edit?usp=sharing"
+	if err := storeAuth([]string{"--sheet-id", url, "--sheet-name", "Log", "--credentials", key}); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.values[keySheetID]; got != testSheetID {
+		t.Errorf("cali auth store kept %q", got)
+	}
+
+	t.Setenv("CALI_GOOGLE_CREDENTIALS_JSON", "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	for value, want := range map[string]string{
+		"https://docs.google.com/spreadsheets/d/e/2PACX-1vQ/pubhtml": "published copy",
+		"my training sheet": "an ID looks like",
+	} {
+		t.Setenv("CALI_SHEET_ID", value)
+		_, err := newSheetsStorage(context.Background(), "")
+		if err == nil || !strings.Contains(err.Error(), "invalid CALI_SHEET_ID (from env)") || !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), value) {
+			t.Errorf("CALI_SHEET_ID=%q: %v", value, err)
+		}
+	}
+	// A URL gets as far as the credentials.
+	t.Setenv("CALI_SHEET_ID", url)
+	if _, err := newSheetsStorage(context.Background(), ""); err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Errorf("CALI_SHEET_ID as a URL: %v", err)
+	}
+}
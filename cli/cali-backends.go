@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// defaultBackend is the backend used when CALI_STORAGE is unset.
+const defaultBackend = "sheets"
+
+// The built-in backends. Others register from the program calling Run (see
+// calio.RegisterBackend) and are selected the same way, with CALI_STORAGE.
+func init() {
+	calio.RegisterBackend("sheets", openSheetsBackend)
+	calio.RegisterBackend("local", openLocalBackend)
+}
+
+// openSheetsBackend opens the spreadsheet of CALI_SHEET_ID, with the
+// credentials and tab resolved as cali auth shows them.
+func openSheetsBackend(ctx context.Context, cfg calio.BackendConfig) (calio.Storage, error) {
+	sheet, err := newSheetsStorage(ctx, cfg.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	if sheet.PerYear() {
+		cfg.Logf("Storage: sheets (spreadsheet %s, tabs %q)\n", sheet.SpreadsheetID(), sheet.SheetName()+" <year>")
+	} else {
+		cfg.Logf("Storage: sheets (spreadsheet %s, tab %q)\n", sheet.SpreadsheetID(), sheet.SheetName())
+	}
+	return sheet, nil
+}
+
+// openLocalBackend opens the log directory (see localLogDir), or the
+// directory named by --sheet inside it.
+func openLocalBackend(ctx context.Context, cfg calio.BackendConfig) (calio.Storage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	storage, err := newFileStorage(ctx, cfg.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	storage.Now, storage.Writer = cfg.Now, cfg.Writer
+	cfg.Logf("Storage: local (%s)\n", storage.Dir())
+	return storage, nil
+}
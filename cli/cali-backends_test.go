@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+var (
+	registerDummy sync.Once
+	dummyLog      *memoryStorage      // what the dummy backend stores in
+	dummyConfig   calio.BackendConfig // what it was last opened with
+)
+
+// dummyBackend makes runCLI store through a backend registered the way a
+// program calling Run registers its own: by name, with a constructor that
+// takes its setting from the config.
+func dummyBackend(t *testing.T) *memoryStorage {
+	registerDummy.Do(func() {
+		calio.RegisterBackend("dummy", func(ctx context.Context, cfg calio.BackendConfig) (calio.Storage, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if cfg.Getenv("CALI_DUMMY_DSN") == "" {
+				return nil, &calio.ConfigError{Name: "CALI_DUMMY_DSN"}
+			}
+			dummyConfig = cfg
+			return dummyLog, nil
+		})
+	})
+	saved := testBackend
+	testBackend, dummyLog, dummyConfig = "DUMMY", &memoryStorage{}, calio.BackendConfig{}
+	t.Cleanup(func() { testBackend = saved })
+	t.Setenv("CALI_DUMMY_DSN", "postgres://homelab/cali")
+	return dummyLog
+}
+
+// TestRegisteredBackend logs and lists entries through a registered
+// backend, and checks an unknown or misconfigured one is reported.
+func TestRegisteredBackend(t *testing.T) {
+	storage := dummyBackend(t)
+	withGoalOverrides(t, nil)
+	for _, args := range [][]string{
+		{"log", "--day", "A", "--exercise", "Pushups", "--level", "Full", "--reps", "18x2", "--comment", "-"},
+		{"q", "squats half 40x2"},
+	} {
+		if _, stderr, code := runCLI(t, "y\ny\n", args...); code != 0 {
+			t.Fatalf("cali %s exited %d: %s", strings.Join(args, " "), code, stderr)
+		}
+	}
+	if len(storage.entries) != 2 || storage.entries[0].Exercise != "Pushups" || storage.entries[1].RepsSets != "40x2" {
+		t.Fatalf("the backend holds %+v", storage.entries)
+	}
+	if dummyConfig.Name != "dummy" || !strings.HasPrefix(dummyConfig.Writer, "cali") || dummyConfig.Now == nil {
+		t.Errorf("the backend was opened with %+v", dummyConfig)
+	}
+
+	stdout, stderr, code := runCLI(t, "", "history")
+	if code != 0 || !strings.Contains(stdout, "Pushups - Full | 18x2") || !strings.Contains(stdout, "Squats - Half | 40x2") {
+		t.Errorf("cali history exited %d:\n%s%s", code, stdout, stderr)
+	}
+	if _, _, code := runCLI(t, "", "--sheet", "legs", "history"); code != 0 || dummyConfig.Sheet != "legs" {
+		t.Errorf("cali --sheet legs history exited %d and opened %q", code, dummyConfig.Sheet)
+	}
+
+	t.Setenv("CALI_DUMMY_DSN", "")
+	if _, stderr, code := runCLI(t, "", "history"); code == 0 || !strings.Contains(stderr, "CALI_DUMMY_DSN") {
+		t.Errorf("without its setting, cali history exited %d: %s", code, stderr)
+	}
+	testBackend = "postgres"
+	if _, stderr, code := runCLI(t, "", "history"); code != exitStorage || !strings.Contains(stderr, `unknown storage backend "postgres" (available: `) || !strings.Contains(stderr, "dummy, ") {
+		t.Errorf("an unknown backend exited %d: %s", code, stderr)
+	}
+}
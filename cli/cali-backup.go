@@ -0,0 +1,482 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// defaultAutoBackups is how many daily snapshots are kept when
+// CALI_AUTO_BACKUPS is unset.
+const defaultAutoBackups = 7
+
+// sheetsSnapshotName is the file a Sheets snapshot is written to; local
+// snapshots are copies of the year files.
+const sheetsSnapshotName = "sheets.csv"
+
+// restoreSnapshotSuffix ends the names of the snapshots taken before a
+// restore, so cali restore --latest-auto doesn't take one for the newest
+// daily snapshot and undo the restore it was taken for.
+const restoreSnapshotSuffix = "-before-restore"
+
+// snapshotHeader heads the CSV of a Sheets snapshot.
+var snapshotHeader = []string{"Date", "Day", "Exercise", "Level", "RepsxSets", "Goal", "Comment", "Type", "Category", "User", "Minutes", "Load", "Logged"}
+
+// autoBackup keeps the snapshots taken before commands change or remove
+// stored entries, one directory per day under dir, named by date, and
+// those taken before restores, named by date and restoreSnapshotSuffix.
+// Either kind keeps its newest keep. now is currentTime outside tests.
+type autoBackup struct {
+	dir  string
+	keep int
+	now  func() time.Time
+}
+
+func newAutoBackup() (*autoBackup, error) {
+	paths, err := userPaths()
+	if err != nil {
+		return nil, err
+	}
+	keep, err := autoBackupsToKeep()
+	if err != nil {
+		return nil, err
+	}
+	return &autoBackup{
+		dir:  filepath.Join(paths.DataDir(), "backups", "auto"),
+		keep: keep,
+		now:  currentTime,
+	}, nil
+}
+
+// autoBackupsToKeep returns CALI_AUTO_BACKUPS, the number of daily snapshots
+// to keep; 0 turns them off.
+func autoBackupsToKeep() (int, error) {
+	raw := strings.TrimSpace(os.Getenv("CALI_AUTO_BACKUPS"))
+	if raw == "" {
+		return defaultAutoBackups, nil
+	}
+	keep, err := strconv.Atoi(raw)
+	if err != nil || keep < 0 {
+		return 0, fmt.Errorf("invalid CALI_AUTO_BACKUPS %q (use a number of snapshots, 0 for none)", raw)
+	}
+	return keep, nil
+}
+
+// Snapshot copies the data of backend into today's directory unless a
+// snapshot was already taken today, then prunes old ones. It returns the
+// directory of a snapshot it took, and "" otherwise.
+func (b *autoBackup) Snapshot(ctx context.Context, backend Storage) (string, error) {
+	return b.snapshot(ctx, backend, "")
+}
+
+// SnapshotBeforeRestore is Snapshot for the snapshots taken before a
+// restore, which Latest never returns.
+func (b *autoBackup) SnapshotBeforeRestore(ctx context.Context, backend Storage) (string, error) {
+	return b.snapshot(ctx, backend, restoreSnapshotSuffix)
+}
+
+// snapshot takes the snapshots named by date and suffix.
+func (b *autoBackup) snapshot(ctx context.Context, backend Storage, suffix string) (string, error) {
+	if b.keep == 0 {
+		return "", nil
+	}
+	day := filepath.Join(b.dir, b.now().Format(calio.DateLayout)+suffix)
+	if _, err := os.Stat(day); err == nil {
+		detail("Already backed up today: %s\n", day)
+		return "", nil
+	}
+
+	// Work in a temporary directory so a failed snapshot doesn't count as
+	// today's.
+	if err := os.MkdirAll(b.dir, 0700); err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp(b.dir, ".partial-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	switch backend := backend.(type) {
+	case *calio.FileStorage:
+		err = snapshotFiles(backend.Dir(), tmp)
+	default:
+		err = snapshotEntries(ctx, backend, filepath.Join(tmp, sheetsSnapshotName))
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, day); err != nil {
+		return "", err
+	}
+	return day, b.prune(suffix)
+}
+
+// snapshots returns the names of the snapshot directories ending in
+// suffix, oldest first.
+func (b *autoBackup) snapshots(suffix string) ([]string, error) {
+	items, err := os.ReadDir(b.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var days []string
+	for _, item := range items {
+		date, ok := strings.CutSuffix(item.Name(), suffix)
+		if _, err := time.Parse(calio.DateLayout, date); ok && err == nil && item.IsDir() {
+			days = append(days, item.Name())
+		}
+	}
+	slices.Sort(days)
+	return days, nil
+}
+
+// prune removes all but the newest keep snapshots ending in suffix.
+func (b *autoBackup) prune(suffix string) error {
+	days, err := b.snapshots(suffix)
+	if err != nil {
+		return err
+	}
+	for len(days) > b.keep {
+		if err := os.RemoveAll(filepath.Join(b.dir, days[0])); err != nil {
+			return err
+		}
+		days = days[1:]
+	}
+	return nil
+}
+
+// Latest returns the directory of the newest daily snapshot.
+func (b *autoBackup) Latest() (string, error) {
+	days, err := b.snapshots("")
+	if err != nil {
+		return "", err
+	}
+	if len(days) == 0 {
+		return "", errors.New(msg("restore.none", b.dir))
+	}
+	return filepath.Join(b.dir, days[len(days)-1]), nil
+}
+
+// snapshotFiles copies the year files of a local log.
+func snapshotFiles(logDir, dest string) error {
+	files, err := filepath.Glob(filepath.Join(logDir, "workout-*.log"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := copyFile(file, filepath.Join(dest, filepath.Base(file))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// readSnapshotFiles returns the year files of a local snapshot in dir, by
+// base name.
+func readSnapshotFiles(dir string) (map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "workout-*.log"))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Base(path)] = string(data)
+	}
+	return files, nil
+}
+
+// snapshotEntries writes every entry of backend to a CSV file.
+func snapshotEntries(ctx context.Context, backend Storage, path string) error {
+	entries, err := backend.All(ctx)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(file)
+	w.Write(snapshotHeader)
+	for _, entry := range entries {
+		w.Write([]string{entry.Date, entry.Day, entry.Exercise, entry.Level, entry.RepsSets, entry.Goal,
+			entry.Comment, entry.Type, entry.Category, entry.User, strconv.Itoa(entry.Duration), entry.Load, entry.LoggedAt})
+	}
+	w.Flush()
+	err = w.Error()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// readSnapshotEntries reads a CSV written by snapshotEntries. Snapshots
+// taken before loads or log times were recorded lack those last columns.
+func readSnapshotEntries(path string) ([]WorkoutEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var entries []WorkoutEntry
+	for i, row := range rows {
+		if len(row) < len(snapshotHeader)-2 || len(row) > len(snapshotHeader) {
+			return nil, fmt.Errorf("%s: line %d has %d fields, want %d", path, i+1, len(row), len(snapshotHeader))
+		}
+		if i == 0 {
+			continue
+		}
+		duration, _ := strconv.Atoi(row[10])
+		entry := WorkoutEntry{
+			Date: row[0], Day: row[1], Exercise: row[2], Level: row[3], RepsSets: row[4], Goal: row[5],
+			Comment: row[6], Type: row[7], Category: row[8], User: row[9], Duration: duration,
+		}
+		if len(row) > 11 {
+			entry.Load = row[11]
+		}
+		if len(row) > 12 {
+			entry.LoggedAt = row[12]
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// backendOf returns the backend under a shared log's user scope, whose data
+// a snapshot covers in full.
+func backendOf(storage Storage) Storage {
+	if shared, ok := storage.(*calio.UserStorage); ok {
+		return shared.Storage
+	}
+	return storage
+}
+
+// beforeMutation snapshots the stored data before a command removes,
+// rewrites or adds entries in bulk. A failed snapshot only warns, so the
+// command still runs.
+func beforeMutation(ctx context.Context, storage Storage) {
+	takeSnapshot(ctx, storage, (*autoBackup).Snapshot)
+}
+
+// beforeRestore is beforeMutation for cali restore.
+func beforeRestore(ctx context.Context, storage Storage) {
+	takeSnapshot(ctx, storage, (*autoBackup).SnapshotBeforeRestore)
+}
+
+func takeSnapshot(ctx context.Context, storage Storage, snapshot func(*autoBackup, context.Context, Storage) (string, error)) {
+	backup, err := newAutoBackup()
+	if err == nil {
+		var dir string
+		if dir, err = snapshot(backup, ctx, backendOf(storage)); err == nil && dir != "" {
+			say(msg("backup.taken", dir))
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: automatic backup failed: %v\n", err)
+	}
+}
+
+// restoreOptions are the flags of cali restore.
+type restoreOptions struct {
+	LatestAuto bool
+	DryRun     bool
+}
+
+// newRestoreFlagSet declares the flags of cali restore into opts.
+func newRestoreFlagSet(opts *restoreOptions) *flag.FlagSet {
+	fs := newFlagSet("restore")
+	fs.BoolVar(&opts.LatestAuto, "latest-auto", false, "restore the newest automatic backup")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "show what would change without writing anything")
+	return fs
+}
+
+func runRestore(ctx context.Context, args []string) error {
+	var opts restoreOptions
+	fs := newRestoreFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if !opts.LatestAuto || fs.NArg() > 0 {
+		return usageError("usage: cali restore --latest-auto [--dry-run]")
+	}
+	storage, err := newBackend(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	backup, err := newAutoBackup()
+	if err != nil {
+		return err
+	}
+	dir, err := backup.Latest()
+	if err != nil {
+		return usageError("%v", err)
+	}
+	_, isLocal := storage.(*calio.FileStorage)
+	if _, err := os.Stat(filepath.Join(dir, sheetsSnapshotName)); (err == nil) == isLocal {
+		return usageError("%s", msg("restore.other_backend", dir))
+	}
+	if opts.DryRun {
+		diff, err := restoreDiff(ctx, storage, dir)
+		if err != nil {
+			return err
+		}
+		printDryRun(diff)
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	release, err := guardSession(reader)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	prompt(msg("restore.confirm", dir))
+	answer, _ := reader.ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	if !slices.Contains(strings.Split(msg("answer.yes"), ","), answer) {
+		promptln(msg("remove.cancelled"))
+		return errCancelled
+	}
+
+	beforeRestore(ctx, storage)
+	if local, ok := storage.(*calio.FileStorage); ok {
+		files, err := readSnapshotFiles(dir)
+		if err != nil {
+			return storageError("reading the snapshot", err)
+		}
+		if err := local.ReplaceFiles(ctx, files); err != nil {
+			return storageError("restoring the year files", err)
+		}
+		fmt.Print(msg("restore.files", len(files), local.Dir()))
+		return nil
+	}
+
+	missing, _, err := sheetsRestore(ctx, storage, dir)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		if _, err := storage.AppendBatch(ctx, missing); err != nil {
+			return storageError("restoring entries", err)
+		}
+	}
+	fmt.Print(msg("restore.entries", len(missing)))
+	return nil
+}
+
+// sheetsRestore reads the Sheets snapshot in dir and returns the entries it
+// would add back, along with the entries stored now.
+func sheetsRestore(ctx context.Context, storage Storage, dir string) (missing, current []WorkoutEntry, err error) {
+	saved, err := readSnapshotEntries(filepath.Join(dir, sheetsSnapshotName))
+	if err != nil {
+		return nil, nil, storageError("reading the snapshot", err)
+	}
+	current, err = storage.All(ctx)
+	if err != nil {
+		return nil, nil, storageError("reading workout history", err)
+	}
+	return missingEntries(saved, current), current, nil
+}
+
+// restoreDiff works out what restoring the snapshot in dir would change,
+// reading but not writing storage. Locally the snapshot's year files replace
+// the current ones and other years stay; on Sheets the missing entries are
+// added back.
+func restoreDiff(ctx context.Context, storage Storage, dir string) (entryDiff, error) {
+	local, ok := storage.(*calio.FileStorage)
+	if !ok {
+		missing, current, err := sheetsRestore(ctx, storage, dir)
+		if err != nil {
+			return entryDiff{}, err
+		}
+		return diffEntries(current, append(slices.Clip(current), missing...)), nil
+	}
+
+	current, err := local.All(ctx)
+	if err != nil {
+		return entryDiff{}, storageError("reading workout history", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "workout-*.log"))
+	if err != nil {
+		return entryDiff{}, err
+	}
+	replaced := map[string]bool{}
+	for _, file := range files {
+		replaced[filepath.Base(file)] = true
+	}
+	snapshot := calio.NewFileStorage(dir)
+	snapshot.Now = currentTime
+	proposed, err := snapshot.All(ctx)
+	if err != nil {
+		return entryDiff{}, storageError("reading the snapshot", err)
+	}
+	for _, entry := range current {
+		if !replaced[filepath.Base(local.FileFor(entry.Date))] {
+			proposed = append(proposed, entry)
+		}
+	}
+	return diffEntries(current, proposed), nil
+}
+
+// missingEntries returns the entries of saved that current doesn't have, as
+// many times as saved has them more often.
+func missingEntries(saved, current []WorkoutEntry) []WorkoutEntry {
+	key := func(e WorkoutEntry) string {
+		return strings.Join([]string{e.Date, e.Day, e.Exercise, e.Level, e.RepsSets, e.Goal, e.Comment,
+			calio.NormalizeWorkoutType(e.Type), calio.NormalizeCategory(e.Category), e.User, e.Load}, "|")
+	}
+	have := map[string]int{}
+	for _, entry := range current {
+		have[key(entry)]++
+	}
+	var missing []WorkoutEntry
+	for _, entry := range saved {
+		if have[key(entry)] > 0 {
+			have[key(entry)]--
+			continue
+		}
+		missing = append(missing, entry)
+	}
+	return missing
+}
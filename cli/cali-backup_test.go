@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// testBackup returns an autoBackup keeping keep snapshots in a temporary
+// directory, whose clock the returned time drives.
+func testBackup(t *testing.T, keep int) (*autoBackup, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	return &autoBackup{dir: t.TempDir(), keep: keep, now: func() time.Time { return now }}, &now
+}
+
+// localLog returns a local log holding entries.
+func localLog(t *testing.T, entries ...WorkoutEntry) *calio.FileStorage {
+	t.Helper()
+	storage := calio.NewFileStorage(t.TempDir())
+	for _, entry := range entries {
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return storage
+}
+
+func backupEntry(date string) WorkoutEntry {
+	return WorkoutEntry{Date: date, Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Goal: "20x2"}
+}
+
+// backupNames lists the directories under b.dir.
+func backupNames(t *testing.T, b *autoBackup) []string {
+	t.Helper()
+	items, err := os.ReadDir(b.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name())
+	}
+	return names
+}
+
+func TestSnapshotOncePerDay(t *testing.T) {
+	quiet(t)
+	ctx := context.Background()
+	b, now := testBackup(t, 7)
+	storage := localLog(t, backupEntry("2026-09-30"))
+	before := readFile(t, storage.FileFor("2026-09-30"))
+
+	dir, err := b.Snapshot(ctx, storage)
+	if err != nil || dir != filepath.Join(b.dir, "2026-10-01") {
+		t.Fatalf("first Snapshot = %q, %v", dir, err)
+	}
+	if _, err := storage.Append(ctx, backupEntry("2026-10-01")); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(14 * time.Hour) // 23:00, still the same day
+	if dir, err := b.Snapshot(ctx, storage); err != nil || dir != "" {
+		t.Errorf("second Snapshot the same day = %q, %v, want none", dir, err)
+	}
+	if got := readFile(t, filepath.Join(b.dir, "2026-10-01", "workout-2026.log")); got != before {
+		t.Errorf("the day's snapshot holds %q, want the log before the day's first change: %q", got, before)
+	}
+
+	*now = now.Add(2 * time.Hour)
+	if dir, err := b.Snapshot(ctx, storage); err != nil || dir != filepath.Join(b.dir, "2026-10-02") {
+		t.Errorf("Snapshot the next day = %q, %v", dir, err)
+	}
+	if leftover := slices.ContainsFunc(backupNames(t, b), func(name string) bool { return strings.HasPrefix(name, ".partial-") }); leftover {
+		t.Errorf("left a partial snapshot: %q", backupNames(t, b))
+	}
+}
+
+func TestSnapshotRetention(t *testing.T) {
+	quiet(t)
+	ctx := context.Background()
+	b, now := testBackup(t, 3)
+	storage := localLog(t, backupEntry("2026-09-30"))
+	for day := range 5 {
+		if _, err := b.Snapshot(ctx, storage); err != nil {
+			t.Fatal(err)
+		}
+		if day < 2 {
+			if _, err := b.SnapshotBeforeRestore(ctx, storage); err != nil {
+				t.Fatal(err)
+			}
+		}
+		*now = now.AddDate(0, 0, 1)
+	}
+	want := []string{"2026-10-01-before-restore", "2026-10-02-before-restore", "2026-10-03", "2026-10-04", "2026-10-05"}
+	if got := backupNames(t, b); !slices.Equal(got, want) {
+		t.Errorf("kept %q, want %q", got, want)
+	}
+
+	// A restore snapshot a day counts against its own kind only.
+	for range 3 {
+		if _, err := b.SnapshotBeforeRestore(ctx, storage); err != nil {
+			t.Fatal(err)
+		}
+		*now = now.AddDate(0, 0, 1)
+	}
+	want = []string{"2026-10-03", "2026-10-04", "2026-10-05", "2026-10-06-before-restore", "2026-10-07-before-restore", "2026-10-08-before-restore"}
+	if got := backupNames(t, b); !slices.Equal(got, want) {
+		t.Errorf("kept %q, want %q", got, want)
+	}
+}
+
+func TestSnapshotTurnedOff(t *testing.T) {
+	b, _ := testBackup(t, 0)
+	if dir, err := b.Snapshot(context.Background(), localLog(t, backupEntry("2026-09-30"))); err != nil || dir != "" {
+		t.Errorf("Snapshot = %q, %v, want none", dir, err)
+	}
+	if names := backupNames(t, b); len(names) > 0 {
+		t.Errorf("wrote %q", names)
+	}
+}
+
+func TestLatestSkipsRestoreSnapshots(t *testing.T) {
+	quiet(t)
+	ctx := context.Background()
+	b, now := testBackup(t, 7)
+	if _, err := b.Latest(); err == nil {
+		t.Error("Latest without snapshots = nil, want an error")
+	}
+	storage := localLog(t, backupEntry("2026-09-30"))
+	if _, err := b.Snapshot(ctx, storage); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.AddDate(0, 0, 1)
+	if _, err := b.SnapshotBeforeRestore(ctx, storage); err != nil {
+		t.Fatal(err)
+	}
+	if latest, err := b.Latest(); err != nil || latest != filepath.Join(b.dir, "2026-10-01") {
+		t.Errorf("Latest = %q, %v, want the daily snapshot", latest, err)
+	}
+}
+
+// TestImportAndRestoreBackUp imports rows, which takes the day's snapshot
+// first, then restores it, which snapshots the log it replaces under its
+// own name: restoring again finds the same daily snapshot, not that one.
+func TestImportAndRestoreBackUp(t *testing.T) {
+	home := t.TempDir()
+	logDir := filepath.Join(home, "log")
+	t.Setenv("CALI_LOG_DIR", logDir)
+	storage := calio.NewFileStorage(logDir)
+	today := time.Now().Format(calio.DateLayout)
+	if _, err := storage.Append(context.Background(), backupEntry(today)); err != nil {
+		t.Fatal(err)
+	}
+	original := readFile(t, storage.FileFor(today))
+
+	rows := filepath.Join(home, "rows.csv")
+	if err := os.WriteFile(rows, []byte(today+",B,Squats,Full,10x2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code := runCLIIn(t, home, "", "import", rows)
+	if code != 0 || !strings.Contains(stdout, "Backed up your log") {
+		t.Fatalf("import exited %d: %s%s", code, stdout, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 2 {
+		t.Fatalf("after the import: %+v", entries)
+	}
+
+	backups := filepath.Join(home, ".local", "share", appDirName, "backups", "auto")
+	for range 2 {
+		stdout, stderr, code = runCLIIn(t, home, "y\n", "restore", "--latest-auto")
+		if code != 0 {
+			t.Fatalf("restore exited %d: %s%s", code, stdout, stderr)
+		}
+		if got := readFile(t, storage.FileFor(today)); got != original {
+			t.Errorf("restored %q, want the log before the import: %q", got, original)
+		}
+	}
+	saved := readFile(t, filepath.Join(backups, today+restoreSnapshotSuffix, filepath.Base(storage.FileFor(today))))
+	if strings.Count(saved, "\n") != 2 {
+		t.Errorf("the snapshot before the restore holds %q, want the imported log", saved)
+	}
+}
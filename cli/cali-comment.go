@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultCommentLimit is how long a comment gets before cali offers to
+// shorten it, unless CALI_COMMENT_LIMIT says otherwise.
+const defaultCommentLimit = 1000
+
+// historyCommentWidth is how much of a comment history shows without --full.
+const historyCommentWidth = 60
+
+// commentLimit returns CALI_COMMENT_LIMIT, the soft limit on comment length
+// in characters; 0 turns the check off.
+func commentLimit() (int, error) {
+	raw := strings.TrimSpace(os.Getenv("CALI_COMMENT_LIMIT"))
+	if raw == "" {
+		return defaultCommentLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid CALI_COMMENT_LIMIT %q (use a number of characters, 0 for no limit)", raw)
+	}
+	return limit, nil
+}
+
+// truncateText cuts text to at most width characters, ending it with "…"
+// when it was cut.
+func truncateText(text string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	return string([]rune(text)[:width-1]) + "…"
+}
+
+// oneLineComment renders comment on a single line of at most width
+// characters for lists; width 0 shows all of it.
+func oneLineComment(comment string, width int) string {
+	return truncateText(strings.Join(strings.Fields(comment), " "), width)
+}
+
+// checkCommentLength warns about a comment over the soft limit and, when
+// ask is set, offers to cut it to the limit. It returns the comment to save.
+func checkCommentLength(reader *bufio.Reader, comment string, ask bool) (string, error) {
+	limit, err := commentLimit()
+	if err != nil {
+		return "", usageError("%v", err)
+	}
+	length := utf8.RuneCountInString(comment)
+	if limit == 0 || length <= limit {
+		return comment, nil
+	}
+	fmt.Fprint(os.Stderr, msg("comment.too_long", length, limit))
+	if !ask {
+		return comment, nil
+	}
+	prompt(msg("comment.truncate", limit))
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		return "", inputClosed()
+	}
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	if answer == "" || slices.Contains(strings.Split(msg("answer.yes"), ","), answer) {
+		return truncateText(comment, limit), nil
+	}
+	return comment, nil
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateText(t *testing.T) {
+	for _, tc := range []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"felt strong", 20, "felt strong"},
+		{"felt strong", 11, "felt strong"},
+		{"felt strong", 6, "felt …"},
+		{"ääääää", 4, "äää…"},
+		{"felt strong", 0, "felt strong"},
+	} {
+		if got := truncateText(tc.text, tc.width); got != tc.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tc.text, tc.width, got, tc.want)
+		}
+	}
+	if got := oneLineComment("felt\nstrong \t then  weak", 0); got != "felt strong then weak" {
+		t.Errorf("oneLineComment = %q", got)
+	}
+	if got := oneLineComment("felt\nstrong then weak", 10); got != "felt stro…" {
+		t.Errorf("oneLineComment cut to 10 = %q", got)
+	}
+}
+
+func TestCheckCommentLength(t *testing.T) {
+	quiet(t)
+	long := strings.Repeat("a", defaultCommentLimit+1)
+	for _, tc := range []struct {
+		name, limit, comment, answer string
+		ask, warn                    bool
+		want                         int // characters kept
+	}{
+		{"short", "", "easy", "", true, false, 4},
+		{"at the limit", "", long[1:], "", true, false, defaultCommentLimit},
+		{"cut on Enter", "", long, "\n", true, true, defaultCommentLimit},
+		{"cut on yes", "", long, "y\n", true, true, defaultCommentLimit},
+		{"kept on no", "", long, "n\n", true, true, defaultCommentLimit + 1},
+		{"kept without asking", "", long, "", false, true, defaultCommentLimit + 1},
+		{"own limit", "10", "a comment of 24 letters", "\n", true, true, 10},
+		{"no limit", "0", long, "", true, false, defaultCommentLimit + 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CALI_COMMENT_LIMIT", tc.limit)
+			var got string
+			var err error
+			stderr := captureOutput(t, &os.Stderr, func() {
+				got, err = checkCommentLength(bufio.NewReader(strings.NewReader(tc.answer)), tc.comment, tc.ask)
+			})
+			if err != nil || utf8.RuneCountInString(got) != tc.want {
+				t.Errorf("checkCommentLength kept %d characters, %v; want %d", utf8.RuneCountInString(got), err, tc.want)
+			}
+			if warned := strings.Contains(stderr, "Warning: the comment is"); warned != tc.warn {
+				t.Errorf("checkCommentLength printed %q", stderr)
+			}
+		})
+	}
+
+	t.Setenv("CALI_COMMENT_LIMIT", "lots")
+	captureOutput(t, &os.Stderr, func() {
+		if _, err := checkCommentLength(bufio.NewReader(strings.NewReader("")), "easy", true); exitCode(err) != exitUsage {
+			t.Errorf("checkCommentLength with CALI_COMMENT_LIMIT=lots = %v", err)
+		}
+	})
+	t.Setenv("CALI_COMMENT_LIMIT", "")
+	captureOutput(t, &os.Stderr, func() {
+		if _, err := checkCommentLength(bufio.NewReader(strings.NewReader("")), long, true); err == nil {
+			t.Errorf("checkCommentLength at the end of input kept going")
+		}
+	})
+}
+
+// TestLongCommentCommand logs a 100KB comment with cali q and lists it:
+// cut to what fits on one line by default, in full with --full.
+func TestLongCommentCommand(t *testing.T) {
+	storage := pipedLog(t)
+	comment := strings.Repeat("went well, ", 100*1024/11+1)
+	comment = strings.TrimSpace(comment)
+
+	_, stderr, code := runCLI(t, "", "q", "--yes", "pushups full 12x2 "+comment)
+	if code != 0 || !strings.Contains(stderr, "over the limit of 1000") {
+		t.Fatalf("cali q --yes with a long comment exited %d: %.200s", code, stderr)
+	}
+	entries := logged(t, storage)
+	if len(entries) != 1 || entries[0].Comment != comment || entries[0].RepsSets != "12x2" {
+		t.Fatalf("cali q logged %d entries, the first with a comment of %d bytes", len(entries), len(entries[0].Comment))
+	}
+
+	stdout, _, code := runCLI(t, "", "history")
+	if code != 0 || len(stdout) > 1000 || !strings.Contains(stdout, "| 12x2 → 20x2 | went well, went well,") || !strings.Contains(stdout, "…\n") {
+		t.Errorf("cali history exited %d, printed %.300q", code, stdout)
+	}
+	if stdout, _, code := runCLI(t, "", "history", "--full"); code != 0 || !strings.Contains(strings.Join(strings.Fields(stdout), " "), comment) {
+		t.Errorf("cali history --full exited %d without the comment", code)
+	}
+
+	// Asked, the comment is cut to the limit before the entry is saved.
+	if _, stderr, code := runCLI(t, "y\ny\n", "q", "squats full 12x2 "+comment); code != 0 {
+		t.Fatalf("cali q exited %d: %.200s", code, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 2 || entries[1].Comment != truncateText(comment, defaultCommentLimit) {
+		t.Errorf("cali q saved a comment of %d characters, want it cut to %d", utf8.RuneCountInString(entries[len(entries)-1].Comment), defaultCommentLimit)
+	}
+}
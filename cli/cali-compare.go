@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// Baselines cali compare measures the current window against.
+const (
+	againstPrevious = "previous"              // the window right before
+	againstLastYear = "same-period-last-year" // the same dates a year earlier
+)
+
+// metricDelta is one row of a comparison. Exercise is set on per-exercise
+// counts, whose Metric is "exercise".
+type metricDelta struct {
+	Metric   string  `json:"metric"`
+	Exercise string  `json:"exercise,omitempty"`
+	Previous float64 `json:"previous"`
+	Current  float64 `json:"current"`
+	Delta    float64 `json:"delta"`
+}
+
+// comparison is the result of cali compare. Comparing two users of a shared
+// log, both windows are the same and the users are named, the other one as
+// Previous, so an up arrow means the local user did more.
+type comparison struct {
+	Current      dateRange     `json:"current"`
+	Previous     dateRange     `json:"previous"`
+	CurrentUser  string        `json:"current_user,omitempty"`
+	PreviousUser string        `json:"previous_user,omitempty"`
+	Metrics      []metricDelta `json:"metrics"`
+}
+
+// compareWindows returns the current window, ending today, and the one it is
+// compared against. Windows are whole days and equally long in calendar
+// terms: 4w is the 28 days up to and including today.
+func compareWindows(today time.Time, window relativeDuration, against string) (current, previous dateRange) {
+	today = truncateToDate(today)
+	start := window.before(today).AddDate(0, 0, 1)
+	current = dateRange{Since: start.Format(calio.DateLayout), Until: today.Format(calio.DateLayout)}
+
+	switch against {
+	case againstLastYear:
+		previous = dateRange{
+			Since: start.AddDate(-1, 0, 0).Format(calio.DateLayout),
+			Until: today.AddDate(-1, 0, 0).Format(calio.DateLayout),
+		}
+	default:
+		end := start.AddDate(0, 0, -1)
+		previous = dateRange{
+			Since: window.before(end).AddDate(0, 0, 1).Format(calio.DateLayout),
+			Until: end.Format(calio.DateLayout),
+		}
+	}
+	return current, previous
+}
+
+// windowMetrics are the figures compared for one window.
+type windowMetrics struct {
+	Sessions int // distinct strength training dates
+	Stats    trainingStats
+}
+
+func measureWindow(entries []WorkoutEntry, rng dateRange, today time.Time) windowMetrics {
+	inWindow := filterByRange(entries, rng)
+	strength, _ := splitByCategory(inWindow)
+	dates := map[string]bool{}
+	for _, entry := range strength {
+		dates[entry.Date] = true
+	}
+	return windowMetrics{Sessions: len(dates), Stats: computeStats(inWindow, today)}
+}
+
+// compare computes each metric in both windows and the change between them.
+func compare(entries []WorkoutEntry, current, previous dateRange, today time.Time) comparison {
+	return comparison{
+		Current:  current,
+		Previous: previous,
+		Metrics:  metricDeltas(measureWindow(entries, previous, today), measureWindow(entries, current, today)),
+	}
+}
+
+// compareUsers computes each metric for me and them over the same window of
+// a shared log. Untagged entries count as mine, as they do everywhere else.
+func compareUsers(entries []WorkoutEntry, window dateRange, me, them string, today time.Time) comparison {
+	mine := measureWindow(calio.FilterUser(entries, me, true), window, today)
+	theirs := measureWindow(calio.FilterUser(entries, them, false), window, today)
+	return comparison{
+		Current:      window,
+		Previous:     window,
+		CurrentUser:  me,
+		PreviousUser: them,
+		Metrics:      metricDeltas(theirs, mine),
+	}
+}
+
+func metricDeltas(prev, cur windowMetrics) []metricDelta {
+	row := func(metric string, p, c float64) metricDelta {
+		return metricDelta{Metric: metric, Previous: p, Current: c, Delta: c - p}
+	}
+	minutes := func(h holdTime) float64 {
+		return math.Round(h.minutes()*10) / 10
+	}
+	metrics := []metricDelta{
+		row("sessions", float64(prev.Sessions), float64(cur.Sessions)),
+		row("workouts", float64(prev.Stats.Total), float64(cur.Stats.Total)),
+		row("total_reps", float64(prev.Stats.TotalReps), float64(cur.Stats.TotalReps)),
+		row("hold_minutes", minutes(prev.Stats.HoldTime), minutes(cur.Stats.HoldTime)),
+		row("goals_met", float64(prev.Stats.GoalsMet), float64(cur.Stats.GoalsMet)),
+		row("mobility_sessions", float64(prev.Stats.Mobility), float64(cur.Stats.Mobility)),
+	}
+	// Readiness only shows once scores are recorded, next to the volume it
+	// can explain.
+	if len(prev.Stats.Readiness) > 0 || len(cur.Stats.Readiness) > 0 {
+		average := row("avg_readiness", averageReadiness(prev.Stats.Readiness), averageReadiness(cur.Stats.Readiness))
+		average.Delta = roundTenth(average.Delta)
+		metrics = append(metrics, average,
+			row("low_readiness_days", float64(lowReadinessDays(prev.Stats.Readiness)), float64(lowReadinessDays(cur.Stats.Readiness))))
+	}
+
+	// Per-exercise counts for any exercise trained in either window.
+	either := trainingStats{PerExercise: map[string]int{}}
+	for exercise, count := range prev.Stats.PerExercise {
+		either.PerExercise[exercise] += count
+	}
+	for exercise, count := range cur.Stats.PerExercise {
+		either.PerExercise[exercise] += count
+	}
+	for _, exercise := range statsExercises(either) {
+		delta := row("exercise", float64(prev.Stats.PerExercise[exercise]), float64(cur.Stats.PerExercise[exercise]))
+		delta.Exercise = exercise
+		metrics = append(metrics, delta)
+	}
+	return metrics
+}
+
+func formatMetric(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// deltaText renders a change with an arrow, e.g. "↑ 3", "↓ 1.5" or "= 0".
+func deltaText(delta float64) string {
+	switch {
+	case delta > 0:
+		return "↑ " + formatMetric(delta)
+	case delta < 0:
+		return "↓ " + formatMetric(-delta)
+	}
+	return "= 0"
+}
+
+func (c comparison) writeTable(w io.Writer) error {
+	var b strings.Builder
+	previous, current := msg("compare.previous"), msg("compare.current")
+	if c.PreviousUser != "" {
+		previous, current = c.PreviousUser, c.CurrentUser
+		if current == "" {
+			current = msg("compare.you")
+		}
+		b.WriteString(msg("compare.users_header", displayDate(c.Current.Since), displayDate(c.Current.Until)))
+	} else {
+		b.WriteString(msg("compare.header",
+			displayDate(c.Previous.Since), displayDate(c.Previous.Until),
+			displayDate(c.Current.Since), displayDate(c.Current.Until)))
+	}
+	fmt.Fprintf(&b, "%-22s %10s %10s  %s\n", "", previous, current, msg("compare.change"))
+	perExercise := false
+	for _, m := range c.Metrics {
+		label := msg("compare." + m.Metric)
+		if m.Metric == "exercise" {
+			if !perExercise {
+				b.WriteString(msg("compare.per_exercise") + "\n")
+				perExercise = true
+			}
+			label = "  " + m.Exercise
+		}
+		fmt.Fprintf(&b, "%-22s %10s %10s  %s\n", label, formatMetric(m.Previous), formatMetric(m.Current), deltaText(m.Delta))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// compareOptions are the flags of cali compare.
+type compareOptions struct {
+	Window  string
+	Against string
+	JSON    bool
+}
+
+// newCompareFlagSet declares the flags of cali compare into opts.
+func newCompareFlagSet(opts *compareOptions) *flag.FlagSet {
+	fs := newFlagSet("compare")
+	fs.StringVar(&opts.Window, "window", "4w", "window length, e.g. 4w, 30d or 3m")
+	fs.StringVar(&opts.Against, "against", againstPrevious, "baseline: previous or same-period-last-year")
+	fs.BoolVar(&opts.JSON, "json", false, "print the comparison as JSON")
+	return fs
+}
+
+func runCompare(ctx context.Context, args []string) error {
+	var opts compareOptions
+	fs := newCompareFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError("usage: cali compare [--window 4w] [--against previous|same-period-last-year] [--user <name>] [--json]")
+	}
+	window, err := parseRelativeDuration(opts.Window)
+	if err != nil {
+		return usageError("--window: %v", err)
+	}
+	if window == (relativeDuration{}) {
+		return usageError("--window must be longer than 0 days")
+	}
+	if opts.Against != againstPrevious && opts.Against != againstLastYear {
+		return usageError("unknown --against %q (use previous or same-period-last-year)", opts.Against)
+	}
+
+	// --user someone else compares the two people over the same window.
+	them := selectedUser.User
+	if them == localUser() {
+		them = ""
+	}
+	if them != "" && opts.Against != againstPrevious {
+		return usageError("--against can't be combined with --user; both users are compared over the same window")
+	}
+
+	storage, err := newBackend(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	today := currentTime()
+	current, previous := compareWindows(today, window, opts.Against)
+	since := previous.Since
+	if them != "" {
+		since = current.Since
+	}
+	entries, err := storage.Range(ctx, since, current.Until)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	entries = calio.WithoutFuture(entries, today)
+
+	var result comparison
+	switch {
+	case them != "":
+		result = compareUsers(entries, current, localUser(), them, today)
+	case localUser() != "" && !selectedUser.All:
+		result = compare(calio.FilterUser(entries, localUser(), true), current, previous, today)
+	default:
+		result = compare(entries, current, previous, today)
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	return result.writeTable(os.Stdout)
+}
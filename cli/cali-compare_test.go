@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestCompareWindows(t *testing.T) {
+	today := time.Date(2026, 3, 28, 21, 30, 0, 0, time.UTC)
+	tests := []struct {
+		window, against   string
+		current, previous dateRange
+	}{
+		{"4w", againstPrevious, dateRange{"2026-03-01", "2026-03-28"}, dateRange{"2026-02-01", "2026-02-28"}},
+		{"7d", againstPrevious, dateRange{"2026-03-22", "2026-03-28"}, dateRange{"2026-03-15", "2026-03-21"}},
+		{"1m", againstPrevious, dateRange{"2026-03-01", "2026-03-28"}, dateRange{"2026-01-29", "2026-02-28"}},
+		{"4w", againstLastYear, dateRange{"2026-03-01", "2026-03-28"}, dateRange{"2025-03-01", "2025-03-28"}},
+	}
+	for _, tt := range tests {
+		window, err := parseRelativeDuration(tt.window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		current, previous := compareWindows(today, window, tt.against)
+		if current != tt.current || previous != tt.previous {
+			t.Errorf("compareWindows(%s, %s) = %v, %v, want %v, %v", tt.window, tt.against, current, previous, tt.current, tt.previous)
+		}
+	}
+}
+
+// TestCompare compares a week of three sessions against one of one, with
+// deltas that can be counted by hand.
+func TestCompare(t *testing.T) {
+	entry := func(date, exercise, work, goal string) WorkoutEntry {
+		return WorkoutEntry{Date: date, Day: "A", Exercise: exercise, Level: "Full", RepsSets: work, Goal: goal}
+	}
+	entries := []WorkoutEntry{
+		entry("2026-03-10", "Pushups", "10x2", "20x2"),
+		entry("2026-03-16", "Pushups", "20x2", "20x2"),
+		entry("2026-03-16", "Squats", "15x3", "20x2"),
+		entry("2026-03-18", "Pushups", "20x2", "20x2"),
+		entry("2026-03-20", "Squats", "20x2", "20x2"),
+		{Date: "2026-03-20", Exercise: "Pancake", RepsSets: "60s", Category: calio.CategoryMobility},
+		entry("2026-03-21", "Pushups", "50x2", "20x2"), // after the window
+	}
+	today := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	result := compare(entries, dateRange{"2026-03-14", "2026-03-20"}, dateRange{"2026-03-07", "2026-03-13"}, today)
+
+	got := map[string][2]float64{}
+	for _, m := range result.Metrics {
+		name := m.Metric
+		if m.Exercise != "" {
+			name = m.Exercise
+		}
+		if m.Delta != m.Current-m.Previous {
+			t.Errorf("%s: delta %v, want %v", name, m.Delta, m.Current-m.Previous)
+		}
+		got[name] = [2]float64{m.Previous, m.Current}
+	}
+	want := map[string][2]float64{
+		"sessions":          {1, 3},
+		"workouts":          {1, 4},
+		"total_reps":        {20, 165},
+		"hold_minutes":      {0, 0},
+		"goals_met":         {0, 3},
+		"mobility_sessions": {0, 1},
+		"Pushups":           {1, 2},
+		"Squats":            {0, 2},
+	}
+	if len(got) != len(want) {
+		t.Errorf("compare gave metrics %v, want %v", got, want)
+	}
+	for name, values := range want {
+		if got[name] != values {
+			t.Errorf("%s = %v, want %v", name, got[name], values)
+		}
+	}
+}
+
+func TestDeltaText(t *testing.T) {
+	for delta, want := range map[float64]string{3: "↑ 3", -1.5: "↓ 1.5", 0: "= 0"} {
+		if got := deltaText(delta); got != want {
+			t.Errorf("deltaText(%v) = %q, want %q", delta, got, want)
+		}
+	}
+}
+
+func TestCompareCommand(t *testing.T) {
+	storage := pipedLog(t)
+	today := currentTime()
+	for _, days := range []int{0, 2, 9} {
+		entry := WorkoutEntry{Date: today.AddDate(0, 0, -days).Format(calio.DateLayout), Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Goal: "20x2"}
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout, stderr, code := runCLI(t, "", "compare", "--window", "7d", "--json")
+	if code != 0 {
+		t.Fatalf("cali compare --json exited %d: %s", code, stderr)
+	}
+	var result comparison
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("cali compare --json printed %q: %v", stdout, err)
+	}
+	i := slices.IndexFunc(result.Metrics, func(m metricDelta) bool { return m.Metric == "sessions" })
+	if i < 0 || result.Metrics[i].Previous != 1 || result.Metrics[i].Current != 2 || result.Metrics[i].Delta != 1 {
+		t.Errorf("cali compare --json sessions = %+v", result.Metrics)
+	}
+
+	stdout, _, _ = runCLI(t, "", "compare", "--window", "7d")
+	if !strings.Contains(stdout, "↑ 1") {
+		t.Errorf("cali compare printed %q, want an up arrow", stdout)
+	}
+	for _, args := range [][]string{{"--window", "0d"}, {"--window", "soon"}, {"--against", "yesterday"}, {"extra"}} {
+		if _, _, code := runCLI(t, "", append([]string{"compare"}, args...)...); code != exitUsage {
+			t.Errorf("cali compare %q exited %d, want %d", args, code, exitUsage)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// configFileName is the file cali reads settings from when the environment
+// doesn't set them, in the same KEY=value form as remind.env. The first-run
+// wizard writes it.
+const configFileName = "config.env"
+
+// configPath returns where the config file lives.
+func configPath() (string, error) {
+	paths, err := userPaths()
+	if err != nil {
+		return "", err
+	}
+	return paths.ConfigFile(configFileName), nil
+}
+
+// loadConfigFile sets the variables in the config file that the environment
+// leaves unset, so every setting is still read from the environment and a
+// variable set in the shell wins. Only the variables cali reads are taken
+// (see reminderEnvVars), workout templates (CALI_TEMPLATE_<name>) and extra
+// prompts (CALI_PROMPT_<key>). A missing file is not an error.
+func loadConfigFile(path string, getenv func(string) string, setenv func(key, value string) error) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(text, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		known := slices.Contains(reminderEnvVars, name) ||
+			(strings.HasPrefix(name, templatePrefix) && len(name) > len(templatePrefix)) ||
+			(strings.HasPrefix(name, promptPrefix) && len(name) > len(promptPrefix))
+		if !ok || !known {
+			return fmt.Errorf("%s:%d: expected one of cali's settings as NAME=value, got %q", path, line, text)
+		}
+		if getenv(name) != "" {
+			continue
+		}
+		if err := setenv(name, value); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// writeConfigFile replaces the config file with settings. It is only
+// readable by the user, since it can name a credentials file.
+func writeConfigFile(path string, settings [][2]string) error {
+	if err := makeDirFor(path); err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString("# Written by cali. Environment variables override these settings.\n")
+	for _, setting := range settings {
+		fmt.Fprintf(&b, "%s=%s\n", setting[0], setting[1])
+	}
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+// configFile is the config file path, or "" when the OS has no config
+// directory.
+var configFile, _ = configPath()
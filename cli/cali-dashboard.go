@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// dashboardMonths is how many months, the current one included, the
+// Dashboard tab counts sessions for.
+const dashboardMonths = 12
+
+// buildDashboard lays out the Dashboard tab as rows of cells, in labeled
+// blocks separated by an empty row: when it was written, sessions (days
+// with any entry) per month, per exercise the last day trained and the
+// current level, then the goals met and the current streak. Cells are
+// plain values for calio.SheetsStorage.WriteDashboard; nil leaves a cell
+// empty. entries must not hold future-dated ones.
+func buildDashboard(entries []WorkoutEntry, rest []calio.RestDay, now time.Time, perWeek int) [][]any {
+	trained := map[string]bool{}
+	lastTrained := map[string]string{}
+	for _, entry := range entries {
+		trained[entry.Date] = true
+		exercise := calio.Canonical(entry).Exercise
+		lastTrained[exercise] = max(lastTrained[exercise], entry.Date)
+	}
+	sessions := map[string]int{}
+	for date := range trained {
+		if len(date) >= 7 {
+			sessions[date[:7]]++
+		}
+	}
+
+	grid := [][]any{
+		{msg("dashboard.title"), msg("dashboard.updated"), now.Format("2006-01-02 15:04")},
+		{},
+		{msg("dashboard.months")},
+		{msg("dashboard.month"), msg("dashboard.sessions")},
+	}
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	for i := dashboardMonths - 1; i >= 0; i-- {
+		key := month.AddDate(0, -i, 0).Format("2006-01")
+		grid = append(grid, []any{key, sessions[key]})
+	}
+
+	strength, _ := splitByCategory(entries)
+	levels := map[string]string{}
+	for _, key := range currentLevels(strength) {
+		levels[key.Exercise] = key.Level
+	}
+	grid = append(grid,
+		[]any{},
+		[]any{msg("dashboard.exercises")},
+		[]any{msg("dashboard.exercise"), msg("dashboard.last_trained"), msg("dashboard.level")},
+	)
+	for _, exercise := range calio.Exercises() {
+		row := []any{exercise, nil, nil}
+		if date, ok := lastTrained[exercise]; ok {
+			row[1] = displayDate(date)
+		}
+		if level, ok := levels[exercise]; ok {
+			row[2] = level
+		}
+		grid = append(grid, row)
+	}
+
+	stats := computeStats(entries, now)
+	grid = append(grid,
+		[]any{},
+		[]any{msg("dashboard.totals")},
+		[]any{msg("dashboard.goals_met"), stats.GoalsMet},
+		[]any{msg("dashboard.streak"), currentStreak(trained, rest, now, perWeek).days()},
+	)
+	return grid
+}
+
+// refreshDashboard reads the log and rewrites the Dashboard tab with
+// buildDashboard.
+func refreshDashboard(ctx context.Context) error {
+	perWeek, err := restPerWeek()
+	if err != nil {
+		return usageError("%v", err)
+	}
+	backend, err := newSheetsStorage(ctx, "")
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	storage := withUser(backend)
+	loadLevelPins(ctx, storage)
+
+	now := currentTime()
+	entries, err := storage.All(ctx)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	rest, err := readRestDays(ctx, storage, dateRange{}, now.Format(calio.DateLayout))
+	if err != nil {
+		return storageError("reading rest days", err)
+	}
+	grid := buildDashboard(calio.WithoutFuture(entries, now), rest, now, perWeek)
+	if err := backend.WriteDashboard(ctx, grid); err != nil {
+		return storageError("writing the dashboard", err)
+	}
+	say(msg("sheet.dashboard", calio.DashboardTab))
+	return nil
+}
+
+// buildRollup lays out the summary block of the month starting at month
+// for calio.SheetsStorage.WriteRollup: when it was written, then sessions
+// (days with working sets), working sets and goals met, then working sets
+// per exercise. Warm-ups and mobility work are left out, as in stats.
+// entries must be those of the month.
+func buildRollup(entries []WorkoutEntry, month, now time.Time) [][]any {
+	sessions := map[string]bool{}
+	for _, entry := range entries {
+		if classifyEntry(entry) == kindWorking {
+			sessions[calio.Canonical(entry).Date] = true
+		}
+	}
+	stats := computeStats(entries, now)
+	block := [][]any{
+		{msg("dashboard.updated"), now.Format("2006-01-02 15:04")},
+		{msg("dashboard.sessions"), len(sessions)},
+		{msg("rollup.entries"), stats.Total},
+		{msg("dashboard.goals_met"), stats.GoalsMet},
+		{msg("dashboard.exercise"), msg("rollup.entries")},
+	}
+	for _, exercise := range statsExercises(stats) {
+		block = append(block, []any{exercise, stats.PerExercise[exercise]})
+	}
+	return block
+}
+
+// writeRollup summarizes a month, given as YYYY-MM or the current month
+// when empty, into the Summary tab with buildRollup.
+func writeRollup(ctx context.Context, value string) error {
+	now := currentTime()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if value != "" {
+		parsed, err := time.ParseInLocation("2006-01", value, now.Location())
+		if err != nil {
+			return usageError("invalid month %q (use YYYY-MM, e.g. %s)", value, now.Format("2006-01"))
+		}
+		month = parsed
+	}
+	backend, err := newSheetsStorage(ctx, "")
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	storage := withUser(backend)
+
+	last := month.AddDate(0, 1, -1)
+	entries, err := storage.Range(ctx, month.Format(calio.DateLayout), last.Format(calio.DateLayout))
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	key := month.Format("2006-01")
+	if err := backend.WriteRollup(ctx, key, buildRollup(calio.WithoutFuture(entries, now), month, now)); err != nil {
+		return storageError("writing the summary", err)
+	}
+	say(msg("sheet.rollup", key, calio.SummaryTab))
+	return nil
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// dashboardText renders a grid one row per line, text quoted so the golden
+// file shows which cells are numbers and which are empty.
+func dashboardText(grid [][]any) string {
+	var b strings.Builder
+	for _, row := range grid {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			switch v := cell.(type) {
+			case nil:
+			case string:
+				cells[i] = fmt.Sprintf("%q", v)
+			default:
+				cells[i] = fmt.Sprint(v)
+			}
+		}
+		b.WriteString(strings.TrimRight(strings.Join(cells, " | "), " ") + "\n")
+	}
+	return b.String()
+}
+
+// TestDashboardGolden lays out a dashboard of a log spanning more than a
+// year, with a level moved up, a misspelled exercise, a deload and a rest
+// day in the current streak.
+func TestDashboardGolden(t *testing.T) {
+	withGoalOverrides(t, nil)
+	withLevelPins(t, nil)
+	now := time.Date(2026, 3, 4, 18, 30, 0, 0, time.Local)
+	entry := func(date, exercise, level, reps, comment string) WorkoutEntry {
+		canonical := calio.Canonical(WorkoutEntry{Exercise: exercise, Level: level})
+		return WorkoutEntry{Date: date, Day: "A", Exercise: exercise, Level: level, RepsSets: reps, Goal: resolveGoal(canonical.Exercise, canonical.Level), Comment: comment, Category: "strength"}
+	}
+	entries := []WorkoutEntry{
+		// Before the twelve months shown.
+		entry("2025-02-27", "Pushups", "Incline", "10x2", ""),
+		entry("2025-03-02", "Pushups", "Kneeling", "10x2", ""),
+		entry("2025-03-02", "Squats", "Half", "20x2", ""),
+		entry("2025-11-15", "Pushups", "Half", "25x2", ""),
+		entry("2026-02-27", "Pushups", "Full", "12x2", ""),
+		entry("2026-02-28", "Squats", "Full", "8x2", "#deload"),
+		entry("2026-03-02", "pushups", "full", "20x2", ""),
+		entry("2026-03-02", "Leg Raises", "Knee Tuck", "10x2", ""),
+		entry("2026-03-04", "Pushups", "Full", "21x2", ""),
+	}
+	rest := []calio.RestDay{{Date: "2026-03-01"}, {Date: "2026-03-03", Reason: "travel"}}
+	checkGolden(t, "dashboard.txt", dashboardText(buildDashboard(entries, rest, now, 2)))
+
+	empty := buildDashboard(nil, nil, now, 2)
+	if got := dashboardText(empty); !strings.Contains(got, "\"2026-03\" | 0\n") || !strings.Contains(got, "\"Pushups\" |  |\n") {
+		t.Errorf("an empty log lays out:\n%s", got)
+	}
+	if len(empty) != len(buildDashboard(entries, rest, now, 2)) {
+		t.Error("the layout depends on the log; a refresh could leave rows behind")
+	}
+}
+
+// TestBuildRollup checks a month's summary counts sessions as days with
+// working sets, and leaves warm-ups and mobility work out of the counts.
+func TestBuildRollup(t *testing.T) {
+	withGoalOverrides(t, nil)
+	now := time.Date(2026, 3, 31, 20, 0, 0, 0, time.Local)
+	month := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	entries := []WorkoutEntry{
+		{Date: "2026-03-02", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2"},
+		{Date: "2026-03-02", Day: "A", Exercise: "Squats", Level: "Half", RepsSets: "20x2", Goal: "50x2"},
+		{Date: "2026-03-02", Day: "A", Exercise: "Squats", Level: "Half", RepsSets: "10x1", Comment: "#warmup"},
+		{Date: "2026-03-04", Day: "B", Exercise: "pushups", Level: "full", RepsSets: "21x2", Goal: "20x2"},
+		// Days with only warm-ups or mobility work are not sessions.
+		{Date: "2026-03-05", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x1", Comment: "#warmup"},
+		{Date: "2026-03-06", Exercise: "Bridge Hold", RepsSets: "30s", Category: calio.CategoryMobility},
+	}
+	got := dashboardText(buildRollup(entries, month, now))
+	want := dashboardText([][]any{
+		{msg("dashboard.updated"), "2026-03-31 20:00"},
+		{msg("dashboard.sessions"), 2},
+		{msg("rollup.entries"), 3},
+		{msg("dashboard.goals_met"), 2},
+		{msg("dashboard.exercise"), msg("rollup.entries")},
+		{"Pushups", 2},
+		{"Squats", 1},
+	})
+	if got != want {
+		t.Errorf("rollup:\n%swant:\n%s", got, want)
+	}
+}
+
+// TestRollupMonth checks cali sheet rollup rejects a month it can't read
+// before it needs the spreadsheet.
+func TestRollupMonth(t *testing.T) {
+	for _, args := range [][]string{
+		{"sheet", "rollup", "March"},
+		{"sheet", "rollup", "2026-13"},
+		{"sheet", "rollup", "2026-03", "2026-04"},
+	} {
+		if _, stderr, code := runCLI(t, "", args...); code != exitUsage {
+			t.Errorf("cali %s exited %d: %s", strings.Join(args, " "), code, stderr)
+		}
+	}
+}
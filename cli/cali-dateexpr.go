@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// Date expressions let search and remove take "yesterday", "last tue",
+// "3d ago" or "jan-20" besides YYYY-MM-DD. Every form resolves to a date on
+// or before today, in the configured timezone.
+
+var monthNames = map[string]time.Month{
+	"jan": time.January, "january": time.January,
+	"feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"may": time.May,
+	"jun": time.June, "june": time.June,
+	"jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+// resolveDateExpr turns input into a YYYY-MM-DD date relative to today:
+//
+//	2026-01-20          as is
+//	today, yesterday
+//	tue, last tuesday   the most recent such day before today
+//	3d ago, 2 weeks ago counted back as --since counts
+//	jan-20, 20 jan      the most recent Jan 20 up to today
+//
+// Anything else, including a month-day that doesn't exist such as feb-30,
+// is an error.
+func resolveDateExpr(input string, today time.Time) (string, error) {
+	value := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	today = truncateToDate(today)
+	invalid := fmt.Errorf("%s", msg("error.invalid_date", strings.TrimSpace(input)))
+
+	if date, err := time.Parse(calio.DateLayout, value); err == nil {
+		return date.Format(calio.DateLayout), nil
+	}
+	switch value {
+	case "today":
+		return today.Format(calio.DateLayout), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1).Format(calio.DateLayout), nil
+	}
+
+	if weekday, ok := parseWeekday(strings.TrimPrefix(value, "last ")); ok {
+		back := (int(today.Weekday()) - int(weekday) + 7) % 7
+		if back == 0 {
+			back = 7
+		}
+		return today.AddDate(0, 0, -back).Format(calio.DateLayout), nil
+	}
+
+	if span, ok := strings.CutSuffix(value, " ago"); ok {
+		offset, err := parseRelativeDuration(span)
+		if err != nil {
+			return "", invalid
+		}
+		return offset.before(today).Format(calio.DateLayout), nil
+	}
+
+	if month, day, ok := parseMonthDay(value); ok {
+		for year := today.Year(); year > today.Year()-8; year-- {
+			date := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+			if date.Month() == month && !date.After(today) {
+				return date.Format(calio.DateLayout), nil
+			}
+		}
+	}
+	return "", invalid
+}
+
+// parseMonthDay reads "jan-20", "jan 20", "january 20" and "20 jan". The day
+// is only range-checked; resolveDateExpr rejects days the month lacks.
+func parseMonthDay(value string) (time.Month, int, bool) {
+	first, second, found := strings.Cut(strings.ReplaceAll(value, "-", " "), " ")
+	if !found {
+		return 0, 0, false
+	}
+	second = strings.TrimSpace(second)
+	month, ok := monthNames[first]
+	dayText := second
+	if !ok {
+		month, ok = monthNames[second]
+		dayText = first
+	}
+	if !ok {
+		return 0, 0, false
+	}
+	day, err := strconv.Atoi(dayText)
+	if err != nil || day < 1 || day > 31 {
+		return 0, 0, false
+	}
+	return month, day, true
+}
+
+// userDate resolves a date typed on the command line or at a prompt against
+// the current day.
+func userDate(input string) (string, error) {
+	date, err := resolveDateExpr(input, currentTime())
+	if err != nil {
+		return "", usageError("%s", err)
+	}
+	return date, nil
+}
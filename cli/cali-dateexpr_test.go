@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveDateExpr(t *testing.T) {
+	// A Wednesday evening.
+	today := time.Date(2026, 3, 4, 21, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input string
+		want  string // "" for an error
+	}{
+		{"2026-01-20", "2026-01-20"},
+		{"2027-01-20", "2027-01-20"}, // dates are taken as typed
+		{"today", "2026-03-04"},
+		{" Today ", "2026-03-04"},
+		{"yesterday", "2026-03-03"},
+		{"tue", "2026-03-03"},
+		{"tuesday", "2026-03-03"},
+		{"last tuesday", "2026-03-03"},
+		{"mon", "2026-03-02"},
+		{"last   Thu", "2026-02-26"},
+		{"wed", "2026-02-25"}, // today's weekday is a week back
+		{"sunday", "2026-03-01"},
+		{"3d ago", "2026-03-01"},
+		{"3 days ago", "2026-03-01"},
+		{"2 weeks ago", "2026-02-18"},
+		{"1m ago", "2026-02-04"},
+		{"1y ago", "2025-03-04"},
+		{"0d ago", "2026-03-04"},
+		{"jan-20", "2026-01-20"},
+		{"Jan 20", "2026-01-20"},
+		{"20 jan", "2026-01-20"},
+		{"january 20", "2026-01-20"},
+		{"mar-4", "2026-03-04"},
+		{"mar-5", "2025-03-05"}, // not yet this year
+		{"dec 31", "2025-12-31"},
+		{"sept 1", "2025-09-01"},
+		{"feb-29", "2024-02-29"}, // the last leap year
+		{"feb-30", ""},
+		{"jan-32", ""},
+		{"jan-0", ""},
+		{"jan", ""},
+		{"20", ""},
+		{"foo 20", ""},
+		{"last", ""},
+		{"next tuesday", ""},
+		{"3 fortnights ago", ""},
+		{"ago", ""},
+		{"2026-13-01", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := resolveDateExpr(tt.input, today)
+		if tt.want == "" {
+			if err == nil || !strings.Contains(err.Error(), "YYYY-MM-DD") {
+				t.Errorf("resolveDateExpr(%q) = %q, %v, want the invalid date error", tt.input, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("resolveDateExpr(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+// TestResolveDateExprTimezone checks dates count from today where the user
+// is: past midnight in Tokyo it is already the next day there.
+func TestResolveDateExprTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	now := time.Date(2026, 3, 4, 16, 30, 0, 0, time.UTC).In(tokyo)
+	for input, want := range map[string]string{"today": "2026-03-05", "yesterday": "2026-03-04", "wed": "2026-03-04", "mar-5": "2026-03-05"} {
+		if got, err := resolveDateExpr(input, now); err != nil || got != want {
+			t.Errorf("resolveDateExpr(%q) at %v = %q, %v, want %q", input, now, got, err, want)
+		}
+	}
+}
+
+func TestUserDate(t *testing.T) {
+	var ce *cliError
+	if _, err := userDate("someday"); !errors.As(err, &ce) || ce.code != exitUsage {
+		t.Errorf("userDate(someday) = %v, want a usage error", err)
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// dayChoice is what the user does about an exercise the day plan puts on
+// another day than the one entered.
+type dayChoice int
+
+const (
+	dayKeep     dayChoice = iota // log it under the day entered anyway
+	daySwitch                    // log it under the planned day
+	dayReselect                  // choose another exercise
+)
+
+// offPlanDay returns the day the plan puts exercise on when that isn't day.
+// The check only applies to plan days: a day entered as something other
+// than a plan letter, or an exercise no day plans, is never off plan.
+func offPlanDay(day, exercise string) (planned string, off bool) {
+	isLetter := false
+	for _, letter := range calio.DayLetters() {
+		isLetter = isLetter || strings.EqualFold(day, letter)
+	}
+	planned, ok := calio.PlannedDay(exercise)
+	if !isLetter || !ok || strings.EqualFold(day, planned) {
+		return "", false
+	}
+	return planned, true
+}
+
+// parseDayChoice reads the answer to msg("log.day_mismatch_prompt"): empty
+// keeps the day, "s" or the planned letter switches to it and "r" chooses
+// another exercise. ok is false for anything else.
+func parseDayChoice(answer, planned string) (choice dayChoice, ok bool) {
+	switch answer = strings.ToLower(strings.TrimSpace(answer)); {
+	case answer == "" || answer == "k" || answer == "keep":
+		return dayKeep, true
+	case answer == "s" || answer == "switch" || strings.EqualFold(answer, planned):
+		return daySwitch, true
+	case answer == "r" || answer == "reselect":
+		return dayReselect, true
+	}
+	return dayKeep, false
+}
+
+// askDayChoice warns that exercise is normally trained on planned and asks
+// what to do. Off-plan training takes a single Enter; so does a closed
+// input.
+func askDayChoice(reader *bufio.Reader, exercise, planned string) dayChoice {
+	promptln(msg("log.day_mismatch", exercise, planned))
+	for {
+		prompt(msg("log.day_mismatch_prompt", planned))
+		answer, err := reader.ReadString('\n')
+		if choice, ok := parseDayChoice(answer, planned); ok {
+			return choice
+		}
+		if err != nil {
+			return dayKeep
+		}
+	}
+}
+
+// chooseExerciseForDay asks for one of exercises and, while the choice is
+// off plan for day, what to do about it. It returns the exercise and the
+// day to log it under.
+func chooseExerciseForDay(reader *bufio.Reader, exercises []string, day string) (string, string) {
+	for {
+		exercise := chooseExercise(reader, exercises)
+		planned, off := offPlanDay(day, exercise)
+		if !off {
+			return exercise, day
+		}
+		switch askDayChoice(reader, exercise, planned) {
+		case daySwitch:
+			return exercise, planned
+		case dayKeep:
+			return exercise, day
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestOffPlanDay(t *testing.T) {
+	tests := []struct {
+		day, exercise, planned string
+		off                    bool
+	}{
+		{"A", "Pushups", "", false},
+		{"a", "Squats", "", false},
+		{"A", "Pullups", "B", true},
+		{"c", "Leg Raises", "B", true},
+		{"B", "Handstand Push-ups", "C", true},
+		// Days outside the plan's letters and exercises no day plans.
+		{"Mon", "Pullups", "", false},
+		{"", "Pullups", "", false},
+		{"A", "Bridge Hold", "", false},
+	}
+	for _, tt := range tests {
+		planned, off := offPlanDay(tt.day, tt.exercise)
+		if planned != tt.planned || off != tt.off {
+			t.Errorf("offPlanDay(%q, %q) = %q, %v; want %q, %v", tt.day, tt.exercise, planned, off, tt.planned, tt.off)
+		}
+	}
+}
+
+func TestParseDayChoice(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   dayChoice
+		ok     bool
+	}{
+		{"\n", dayKeep, true},
+		{"k", dayKeep, true},
+		{" Keep ", dayKeep, true},
+		{"s", daySwitch, true},
+		{"SWITCH", daySwitch, true},
+		{"b", daySwitch, true},
+		{"B\n", daySwitch, true},
+		{"r", dayReselect, true},
+		{"reselect", dayReselect, true},
+		{"c", dayKeep, false},
+		{"yes", dayKeep, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseDayChoice(tt.answer, "B"); got != tt.want || ok != tt.ok {
+			t.Errorf("parseDayChoice(%q) = %v, %v; want %v, %v", tt.answer, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestChooseExerciseForDay scripts the exercise menu of Day A: an exercise
+// on plan goes through without a question, one off plan takes a single
+// Enter to keep, can move to its own day or be chosen again.
+func TestChooseExerciseForDay(t *testing.T) {
+	exercises := calio.Exercises()
+	number := func(exercise string) string {
+		for i, name := range exercises {
+			if name == exercise {
+				return strconv.Itoa(i + 1)
+			}
+		}
+		t.Fatalf("%s isn't in the menu", exercise)
+		return ""
+	}
+	tests := []struct {
+		name, script  string
+		exercise, day string
+		warning       string // the exercise warned about, if any
+		asked         int
+	}{
+		{"on plan", number("Squats") + "\n", "Squats", "A", "", 0},
+		{"off plan, kept", number("Pullups") + "\n\n", "Pullups", "A", "Pullups", 1},
+		{"off plan, switched", number("Pullups") + "\ns\n", "Pullups", "B", "Pullups", 1},
+		{"off plan, switched by letter", number("Leg Raises") + "\nb\n", "Leg Raises", "B", "Leg Raises", 1},
+		{"off plan, chosen again", number("Pullups") + "\nr\n" + number("Pushups") + "\n", "Pushups", "A", "Pullups", 1},
+		{"an unknown answer asks again", number("Bridges") + "\nmaybe\ns\n", "Bridges", "C", "Bridges", 2},
+		// Closed input logs it anyway rather than losing the session.
+		{"off plan, input closed", number("Pullups") + "\n", "Pullups", "A", "Pullups", 1},
+	}
+	for _, tt := range tests {
+		var exercise, day string
+		out := captureOutput(t, &os.Stdout, func() {
+			exercise, day = chooseExerciseForDay(bufio.NewReader(strings.NewReader(tt.script)), exercises, "A")
+		})
+		if exercise != tt.exercise || day != tt.day {
+			t.Errorf("%s: logged %s on Day %s, want %s on Day %s", tt.name, exercise, day, tt.exercise, tt.day)
+		}
+		warnings := strings.Count(out, "is normally a Day")
+		asked := strings.Count(out, "[s] switch to Day")
+		if tt.warning == "" {
+			if warnings != 0 || asked != 0 {
+				t.Errorf("%s: warned about an exercise on plan:\n%s", tt.name, out)
+			}
+			continue
+		}
+		planned, _ := calio.PlannedDay(tt.warning)
+		if warnings != 1 || asked != tt.asked || !strings.Contains(out, msg("log.day_mismatch", tt.warning, planned)) {
+			t.Errorf("%s: warned %d and asked %d time(s), want a warning about %s and %d question(s):\n%s", tt.name, warnings, asked, tt.warning, tt.asked, out)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// Delimited output formats of cali export, history and search.
+const (
+	formatCSV = "csv"
+	formatTSV = "tsv"
+)
+
+// delimitedFormat reports whether format is one writeDelimited writes.
+func delimitedFormat(format string) bool {
+	return format == formatCSV || format == formatTSV
+}
+
+// checkListFormat rejects a --format history and search don't take: only
+// the delimited ones, or "" for the usual list.
+func checkListFormat(format string) error {
+	if format != "" && !delimitedFormat(format) {
+		return usageError("unknown format %q (use %s or %s)", format, formatCSV, formatTSV)
+	}
+	return nil
+}
+
+// printDelimited prints entries to stdout for history and search with
+// --format, and nothing else; like their lists, it returns errNoResults when
+// there are none.
+func printDelimited(format string, entries []WorkoutEntry, header bool) error {
+	if err := writeDelimited(os.Stdout, format, entries, header); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errNoResults
+	}
+	return nil
+}
+
+// writeDelimited writes entries as rows of columns A to I of the sheet
+// (see calio.LogRow), comma- or tab-separated by format, after a header row
+// when header is set. Fields holding the separator, a quote or a line break
+// are quoted as in RFC 4180, for both formats, so a comment survives a
+// paste into a spreadsheet whole.
+func writeDelimited(w io.Writer, format string, entries []WorkoutEntry, header bool) error {
+	out := csv.NewWriter(w)
+	switch format {
+	case formatCSV:
+	case formatTSV:
+		out.Comma = '\t'
+	default:
+		return fmt.Errorf("unknown delimited format %q", format)
+	}
+	if header {
+		if err := out.Write(calio.LogColumns()); err != nil {
+			return err
+		}
+	}
+	for _, entry := range entries {
+		if err := out.Write(calio.LogRow(entry)); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// readDelimited reads rows as writeDelimited writes them, for cali import:
+// columns A to I by position, of which Date to RepsxSets are required, with
+// or without the header row. Exercise and level names match as in cali q and
+// are stored as cali spells them; a missing Category follows the exercise.
+// Goals are left as given, so an empty one is for the caller to fill in.
+// Errors name the line of the row.
+func readDelimited(r io.Reader, format string) ([]WorkoutEntry, error) {
+	in := csv.NewReader(r)
+	in.FieldsPerRecord = -1
+	if format == formatTSV {
+		in.Comma = '\t'
+	}
+	columns := calio.LogColumns()
+	var entries []WorkoutEntry
+	for first := true; ; first = false {
+		row, err := in.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first && strings.EqualFold(strings.TrimSpace(row[0]), columns[0]) {
+			continue
+		}
+		line, _ := in.FieldPos(0)
+		if len(row) == 1 && strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+		if len(row) < 5 || len(row) > len(columns) {
+			return nil, fmt.Errorf("line %d: %d fields, want 5 to %d (%s)", line, len(row), len(columns), strings.Join(columns, ", "))
+		}
+		entry, err := delimitedEntry(row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// delimitedEntry reads one row of readDelimited.
+func delimitedEntry(row []string) (WorkoutEntry, error) {
+	row = append(row, make([]string, len(calio.LogColumns())-len(row))...)
+	for i := range row {
+		row[i] = strings.TrimSpace(row[i])
+	}
+	date, ok := calio.NormalizeDate(row[0])
+	if !ok {
+		return WorkoutEntry{}, fmt.Errorf("date %q isn't YYYY-MM-DD", row[0])
+	}
+	exercise, ok := matchExercise(row[2])
+	if !ok {
+		return WorkoutEntry{}, fmt.Errorf("unknown exercise %q", row[2])
+	}
+	level, ok := matchLevel(exercise, row[3])
+	if !ok {
+		return WorkoutEntry{}, fmt.Errorf("%q is not a %s level", row[3], exercise)
+	}
+	if _, ok := parseRepsSets(row[4]); !ok {
+		return WorkoutEntry{}, fmt.Errorf("%q isn't reps, a hold or a timed protocol", row[4])
+	}
+	entry := WorkoutEntry{
+		Date:     date,
+		Day:      strings.ToUpper(row[1]),
+		Exercise: exercise,
+		Level:    level,
+		RepsSets: row[4],
+		Goal:     row[5],
+		Comment:  row[6],
+		Type:     calio.NormalizeWorkoutType(row[7]),
+		Category: calio.NormalizeCategory(row[8]),
+	}
+	if row[8] == "" && slices.Contains(calio.MobilityExercises(), exercise) {
+		entry.Category = calio.CategoryMobility
+	}
+	if entry.Day != "" && !slices.Contains(calio.DayLetters(), entry.Day) {
+		return WorkoutEntry{}, fmt.Errorf("unknown day %q (use %s)", row[1], strings.Join(calio.DayLetters(), ", "))
+	}
+	return entry, nil
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// awkwardComment holds every character delimited output has to quote.
+const awkwardComment = "felt \"strong\"\tthen\nform broke, stopped"
+
+// TestCSVRoundTrip prints an entry with an awkward comment as --format csv
+// prints it and reads the output back with encoding/csv.
+func TestCSVRoundTrip(t *testing.T) {
+	entry := WorkoutEntry{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2", Comment: awkwardComment, Type: calio.TypeStraightSets, Category: calio.CategoryStrength}
+	stdout := captureOutput(t, &os.Stdout, func() {
+		if err := printDelimited(formatCSV, []WorkoutEntry{entry}, true); err != nil {
+			t.Error(err)
+		}
+	})
+	rows, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+	if err != nil {
+		t.Fatalf("reading %q: %v", stdout, err)
+	}
+	if len(rows) != 2 || !slices.Equal(rows[0], calio.LogColumns()) {
+		t.Fatalf("rows = %q, want the header and one entry", rows)
+	}
+	if want := calio.LogRow(entry); !slices.Equal(rows[1], want) {
+		t.Errorf("row = %q, want %q", rows[1], want)
+	}
+}
+
+// TestHistoryFormatCSV runs cali history --format csv on a local log. Its
+// lines can't hold a line break, so the comment's comes back as a space.
+func TestHistoryFormatCSV(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "log")
+	t.Setenv("CALI_LOG_DIR", dir)
+	entry := WorkoutEntry{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2", Comment: awkwardComment, Type: calio.TypeStraightSets, Category: calio.CategoryStrength}
+	if _, err := calio.NewFileStorage(dir).Append(context.Background(), entry); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code := runCLI(t, "", "history", "--format", "csv")
+	if code != 0 {
+		t.Fatalf("cali history exited %d: %s", code, stderr)
+	}
+	rows, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+	if err != nil {
+		t.Fatalf("reading %q: %v", stdout, err)
+	}
+	entry.Comment = strings.ReplaceAll(awkwardComment, "\n", " ")
+	if want := calio.LogRow(entry); len(rows) != 1 || !slices.Equal(rows[0], want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+// TestDelimitedRoundTrip writes entries and reads them back in both formats.
+func TestDelimitedRoundTrip(t *testing.T) {
+	entries := []WorkoutEntry{
+		{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2", Comment: awkwardComment, Type: calio.TypeStraightSets, Category: calio.CategoryStrength},
+		{Date: "2026-03-04", Exercise: "L-Sit", Level: "Tuck", RepsSets: "30s", Goal: "1min", Comment: "tab\there, comma, there", Type: calio.TypeStraightSets, Category: calio.CategoryMobility},
+	}
+	for _, format := range []string{formatCSV, formatTSV} {
+		for _, header := range []bool{false, true} {
+			var out strings.Builder
+			if err := writeDelimited(&out, format, entries, header); err != nil {
+				t.Fatal(err)
+			}
+			read, err := readDelimited(strings.NewReader(out.String()), format)
+			if err != nil {
+				t.Fatalf("%s header %v: %v\n%s", format, header, err, out.String())
+			}
+			if len(read) != len(entries) {
+				t.Fatalf("%s header %v: read %d entries, want %d", format, header, len(read), len(entries))
+			}
+			for i := range entries {
+				if got := calio.LogRow(read[i]); !slices.Equal(got, calio.LogRow(entries[i])) {
+					t.Errorf("%s header %v: entry %d read back as %q, want %q", format, header, i+1, got, calio.LogRow(entries[i]))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	deloadTag            = "#deload"
+	defaultDeloadPercent = 60
+)
+
+func deloadPercent() int {
+	raw := strings.TrimSpace(os.Getenv("CALI_DELOAD_PERCENT"))
+	if raw == "" {
+		return defaultDeloadPercent
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(raw, "%"))
+	if err != nil || percent < 1 || percent > 100 {
+		fmt.Fprintf(os.Stderr, "Warning: invalid CALI_DELOAD_PERCENT %q, using %d\n", raw, defaultDeloadPercent)
+		return defaultDeloadPercent
+	}
+	return percent
+}
+
+func isDeload(entry WorkoutEntry) bool {
+	for _, word := range strings.Fields(strings.ToLower(entry.Comment)) {
+		if word == deloadTag {
+			return true
+		}
+	}
+	return false
+}
+
+func addDeloadTag(comment string) string {
+	if isDeload(WorkoutEntry{Comment: comment}) {
+		return comment
+	}
+	if comment == "" {
+		return deloadTag
+	}
+	return comment + " " + deloadTag
+}
+
+// workingEntries drops deload sessions so they don't skew records and trends.
+func workingEntries(entries []WorkoutEntry) []WorkoutEntry {
+	var working []WorkoutEntry
+	for _, entry := range entries {
+		if !isDeload(entry) {
+			working = append(working, entry)
+		}
+	}
+	return working
+}
+
+// scaleRepsSets scales every set (or the hold time) of a Reps×Sets value to
+// percent of its size, rounding to the nearest rep and never below one.
+func scaleRepsSets(value string, percent int) (string, bool) {
+	parsed, ok := parseRepsSets(value)
+	if !ok {
+		return "", false
+	}
+
+	scale := func(n int) int {
+		scaled := int(math.Round(float64(n) * float64(percent) / 100))
+		if scaled < 1 {
+			return 1
+		}
+		return scaled
+	}
+
+	for i, hold := range parsed.Holds {
+		parsed.Holds[i] = holdTime(scale(int(hold)))
+	}
+	for i, reps := range parsed.Sets {
+		parsed.Sets[i] = scale(reps)
+	}
+	return formatRepsSets(parsed), true
+}
+
+// formatRepsSets renders a parsed value back into the canonical text form.
+func formatRepsSets(r repsSets) string {
+	if r.interval() {
+		return formatInterval(r)
+	}
+	if r.timed() {
+		if uniform(holdSeconds(r.Holds)) {
+			if len(r.Holds) == 1 {
+				return r.Holds[0].String()
+			}
+			return fmt.Sprintf("%s x%d", r.Holds[0], len(r.Holds))
+		}
+		parts := make([]string, len(r.Holds))
+		for i, hold := range r.Holds {
+			parts[i] = hold.String()
+		}
+		return strings.Join(parts, ",")
+	}
+
+	if uniform(r.Sets) && len(r.Sets) > 0 {
+		return fmt.Sprintf("%dx%d", r.Sets[0], len(r.Sets))
+	}
+
+	parts := make([]string, len(r.Sets))
+	for i, reps := range r.Sets {
+		parts[i] = strconv.Itoa(reps)
+	}
+	return strings.Join(parts, ",")
+}
+
+func uniform(values []int) bool {
+	for _, v := range values {
+		if v != values[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// lastWorkingEntry returns the most recent non-deload entry for the
+// exercise and level.
+func lastWorkingEntry(entries []WorkoutEntry, exercise, level string) (WorkoutEntry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if sameName(entry.Exercise, exercise) && sameName(entry.Level, level) && !isDeload(entry) {
+			return entry, true
+		}
+	}
+	return WorkoutEntry{}, false
+}
+
+func printDeloadTarget(ctx context.Context, storage Storage, exercise, level string) {
+	entries, err := storage.All(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read history for deload target: %v\n", err)
+		return
+	}
+
+	last, ok := lastWorkingEntry(entries, exercise, level)
+	if !ok {
+		return
+	}
+	percent := deloadPercent()
+	if target, ok := scaleRepsSets(last.RepsSets, percent); ok {
+		prompt(msg("log.deload_target", target, percent, last.RepsSets, displayDate(last.Date)))
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScaleRepsSets(t *testing.T) {
+	tests := []struct {
+		value   string
+		percent int
+		want    string
+	}{
+		{"20x3", 60, "12x3"},
+		{"10,8,6", 50, "5,4,3"},
+		{"3x2", 10, "1x2"}, // never below one rep
+		{"60s", 50, "30s"},
+		{"45s x 3", 60, "27s x3"},
+		{"20x2", 100, "20x2"},
+	}
+	for _, tt := range tests {
+		got, ok := scaleRepsSets(tt.value, tt.percent)
+		if !ok || got != tt.want {
+			t.Errorf("scaleRepsSets(%q, %d) = %q, %v, want %q", tt.value, tt.percent, got, ok, tt.want)
+		}
+	}
+	if got, ok := scaleRepsSets("a few", 60); ok {
+		t.Errorf("scaleRepsSets(\"a few\") = %q, want no value", got)
+	}
+}
+
+func TestDeloadPercent(t *testing.T) {
+	for raw, want := range map[string]int{"": 60, "70": 70, "45%": 45, "0": 60, "101": 60, "lots": 60} {
+		t.Setenv("CALI_DELOAD_PERCENT", raw)
+		if got := deloadPercent(); got != want {
+			t.Errorf("CALI_DELOAD_PERCENT=%q: deloadPercent() = %d, want %d", raw, got, want)
+		}
+	}
+}
+
+func TestDeloadTag(t *testing.T) {
+	for comment, want := range map[string]string{
+		"":                 "#deload",
+		"easy day":         "easy day #deload",
+		"easy #Deload day": "easy #Deload day",
+	} {
+		if got := addDeloadTag(comment); got != want {
+			t.Errorf("addDeloadTag(%q) = %q, want %q", comment, got, want)
+		}
+	}
+	if isDeload(WorkoutEntry{Comment: "#deloaded"}) {
+		t.Error("#deloaded counts as the #deload tag")
+	}
+}
+
+// TestDeloadLeavesRecordsAlone checks deload sessions count as deloads in
+// the stats and are skipped for the last working set and the records.
+func TestDeloadLeavesRecordsAlone(t *testing.T) {
+	entries := []WorkoutEntry{
+		{Date: "2026-10-10", Exercise: "Pushups", Level: "Full", RepsSets: "20x2"},
+		{Date: "2026-10-14", Exercise: "Pushups", Level: "Full", RepsSets: "12x2", Comment: "#deload"},
+		{Date: "2026-10-15", Exercise: "Squats", Level: "Full", RepsSets: "15x2", Comment: "legs tired #deload"},
+	}
+	if got := workingEntries(entries); len(got) != 1 || got[0].Date != "2026-10-10" {
+		t.Errorf("workingEntries = %+v, want the 2026-10-10 session", got)
+	}
+	if last, ok := lastWorkingEntry(entries, "pushups", "full"); !ok || last.RepsSets != "20x2" {
+		t.Errorf("lastWorkingEntry = %+v, %v, want the 20x2 session", last, ok)
+	}
+	if _, ok := lastWorkingEntry(entries, "Squats", "Full"); ok {
+		t.Error("lastWorkingEntry found a working Squats session among deloads")
+	}
+	stats := computeStats(entries, time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC))
+	if stats.Deloads != 2 {
+		t.Errorf("stats count %d deload days, want 2", stats.Deloads)
+	}
+}
+
+func TestPipedDeload(t *testing.T) {
+	storage := pipedLog(t)
+	_, stderr, code := runCLI(t, "", "log", "--exercise", "pushups", "--level", "full", "--reps", "12x2", "--comment", "easy", "--deload")
+	if code != 0 {
+		t.Fatalf("exited %d: %s", code, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 1 || entries[0].Comment != "easy #deload" {
+		t.Errorf("saved %+v, want one entry tagged #deload", entries)
+	}
+}
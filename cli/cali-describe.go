@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// levelDescription is the offline text for one progression step. The
+// built-in ones come with the exercise dataset; descriptions.json in the
+// config directory (see appPaths.ConfigFile) can override them per level.
+type levelDescription = calio.Description
+
+// resolveDescription returns the description for a level, preferring the
+// override file when it has one over the dataset's.
+func resolveDescription(exercise, level string) (levelDescription, bool) {
+	if desc, ok := descriptionOverrides()[exercise][level]; ok {
+		return desc, true
+	}
+	return calio.LevelDescription(exercise, level)
+}
+
+var loadedDescriptionOverrides map[string]map[string]levelDescription
+
+// descriptionOverrides reads descriptions.json from the config directory
+// once. A missing file means no overrides; an invalid one is reported and
+// ignored.
+func descriptionOverrides() map[string]map[string]levelDescription {
+	if loadedDescriptionOverrides != nil {
+		return loadedDescriptionOverrides
+	}
+	loadedDescriptionOverrides = map[string]map[string]levelDescription{}
+
+	paths, err := userPaths()
+	if err != nil {
+		return loadedDescriptionOverrides
+	}
+	path := paths.ConfigFile("descriptions.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read %s: %v\n", path, err)
+		}
+		return loadedDescriptionOverrides
+	}
+
+	var overrides map[string]map[string]levelDescription
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring %s: %v\n", path, err)
+		return loadedDescriptionOverrides
+	}
+	if err := validateDescriptions(overrides); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring %s: %v\n", path, err)
+		return loadedDescriptionOverrides
+	}
+	loadedDescriptionOverrides = overrides
+	return loadedDescriptionOverrides
+}
+
+func validateDescriptions(descs map[string]map[string]levelDescription) error {
+	for exercise, levels := range descs {
+		if !calio.IsExercise(exercise) {
+			return fmt.Errorf("unknown exercise key in descriptions: %q", exercise)
+		}
+		for level, desc := range levels {
+			if _, ok := calio.Goal(exercise, level); !ok {
+				return fmt.Errorf("unknown level key in descriptions: %q -> %q", exercise, level)
+			}
+			if strings.TrimSpace(desc.Summary) == "" {
+				return fmt.Errorf("empty description for %q -> %q", exercise, level)
+			}
+		}
+	}
+	return nil
+}
+
+func describeFromArgs(args []string) error {
+	if len(args) < 1 {
+		return usageError(`usage: cali describe <exercise> [level|step] (quote multi-word values, e.g. cali describe "Leg Raises" 3)`)
+	}
+	exercise, level, err := parseTutorialArgs(args)
+	if err != nil {
+		return err
+	}
+
+	levels := []string{level}
+	if level == "" {
+		levels = calio.Levels(exercise)
+	}
+
+	for i, lv := range levels {
+		if i > 0 {
+			fmt.Println()
+		}
+		printDescription(exercise, lv)
+	}
+	return nil
+}
+
+func printDescription(exercise, level string) {
+	step := 0
+	for i, lv := range calio.Levels(exercise) {
+		if lv == level {
+			step = i + 1
+		}
+	}
+	fmt.Print(msg("describe.header", exercise, step, level, resolveGoal(exercise, level)))
+
+	desc, ok := resolveDescription(exercise, level)
+	if !ok {
+		fmt.Println(msg("describe.none"))
+		return
+	}
+	fmt.Printf("  %s\n", desc.Summary)
+	for _, cue := range desc.Cues {
+		fmt.Printf("  • %s\n", cue)
+	}
+}
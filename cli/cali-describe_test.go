@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestValidateDescriptions(t *testing.T) {
+	valid := levelDescription{Summary: "Hands on the wall", Cues: []string{"Straight body"}}
+	tests := []struct {
+		name  string
+		descs map[string]map[string]levelDescription
+		err   string // part of the error, "" for none
+	}{
+		{"valid", map[string]map[string]levelDescription{"Pushups": {"Wall": valid}}, ""},
+		{"unknown exercise", map[string]map[string]levelDescription{"Burpees": {"Wall": valid}}, "unknown exercise"},
+		{"unknown level", map[string]map[string]levelDescription{"Pushups": {"Ceiling": valid}}, "unknown level"},
+		{"empty summary", map[string]map[string]levelDescription{"Pushups": {"Wall": {Summary: "  "}}}, "empty description"},
+	}
+	for _, tt := range tests {
+		err := validateDescriptions(tt.descs)
+		if (tt.err == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: validateDescriptions = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+// TestDescribeCommand prints one level by step number and every level of
+// an exercise, from the dataset alone.
+func TestDescribeCommand(t *testing.T) {
+	t.Cleanup(func() { loadedDescriptionOverrides = nil })
+	levels := calio.Levels("Leg Raises")
+	third, _ := calio.LevelDescription("Leg Raises", levels[2])
+
+	stdout, stderr, code := runCLI(t, "", "describe", "Leg Raises", "3")
+	if code != 0 {
+		t.Fatalf("cali describe exited %d: %s", code, stderr)
+	}
+	header := "Leg Raises - Step 3: " + levels[2] + " (goal: " + resolveGoal("Leg Raises", levels[2]) + ")"
+	if !strings.HasPrefix(stdout, header) || !strings.Contains(stdout, third.Summary) {
+		t.Errorf("cali describe \"Leg Raises\" 3 printed:\n%s\nwant %q and the summary", stdout, header)
+	}
+	for _, cue := range third.Cues {
+		if !strings.Contains(stdout, "• "+cue) {
+			t.Errorf("cue %q missing:\n%s", cue, stdout)
+		}
+	}
+
+	stdout, _, code = runCLI(t, "", "describe", "leg", "raises")
+	if code != 0 || strings.Count(stdout, "Leg Raises - Step") != len(levels) {
+		t.Errorf("cali describe leg raises exited %d, printed:\n%s\nwant all %d levels", code, stdout, len(levels))
+	}
+
+	if _, _, code := runCLI(t, "", "describe"); code != exitUsage {
+		t.Errorf("cali describe without an exercise exited %d, want %d", code, exitUsage)
+	}
+}
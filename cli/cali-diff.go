@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// diffPreviewLimit caps the entries a --dry-run preview lists.
+const diffPreviewLimit = 20
+
+// entryChange is an entry a bulk operation would rewrite in place.
+type entryChange struct {
+	Before, After WorkoutEntry
+}
+
+// entryDiff is what a bulk operation would do to the stored entries.
+type entryDiff struct {
+	Added    []WorkoutEntry
+	Removed  []WorkoutEntry
+	Modified []entryChange
+}
+
+func (d entryDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// entryContent is every stored field of an entry; entries with the same
+// content are the same workout wherever they are stored.
+func entryContent(e WorkoutEntry) string {
+	return strings.Join([]string{entryIdentity(e), e.Goal, e.Comment,
+		calio.NormalizeWorkoutType(e.Type), calio.NormalizeCategory(e.Category), fmt.Sprint(e.Duration), e.Load}, "|")
+}
+
+// entryIdentity is what makes an entry the same workout after a rewrite:
+// when and what was done, and by whom. Other fields can be changed in place.
+func entryIdentity(e WorkoutEntry) string {
+	return strings.Join([]string{e.Date, e.Day, e.Exercise, e.Level, e.RepsSets, e.User}, "|")
+}
+
+// diffEntries compares the entries stored now with those a bulk operation
+// would leave. Identical entries cancel out, counting duplicates; of the
+// rest, an entry removed and one added with the same identity are paired
+// as a modification. The result keeps the order of the inputs.
+func diffEntries(current, proposed []WorkoutEntry) entryDiff {
+	unmatched := map[string]int{}
+	for _, entry := range proposed {
+		unmatched[entryContent(entry)]++
+	}
+	var removed []WorkoutEntry
+	for _, entry := range current {
+		if key := entryContent(entry); unmatched[key] > 0 {
+			unmatched[key]--
+		} else {
+			removed = append(removed, entry)
+		}
+	}
+	var added []WorkoutEntry
+	for _, entry := range proposed {
+		if key := entryContent(entry); unmatched[key] > 0 {
+			unmatched[key]--
+			added = append(added, entry)
+		}
+	}
+
+	var diff entryDiff
+	byIdentity := map[string][]int{} // identity -> indexes into added
+	for i, entry := range added {
+		byIdentity[entryIdentity(entry)] = append(byIdentity[entryIdentity(entry)], i)
+	}
+	paired := make([]bool, len(added))
+	for _, entry := range removed {
+		candidates := byIdentity[entryIdentity(entry)]
+		if len(candidates) == 0 {
+			diff.Removed = append(diff.Removed, entry)
+			continue
+		}
+		i := candidates[0]
+		byIdentity[entryIdentity(entry)] = candidates[1:]
+		paired[i] = true
+		diff.Modified = append(diff.Modified, entryChange{Before: entry, After: added[i]})
+	}
+	for i, entry := range added {
+		if !paired[i] {
+			diff.Added = append(diff.Added, entry)
+		}
+	}
+	return diff
+}
+
+// changedFields describes what a modification rewrites, e.g.
+// `goal 10x2 → 20x2`.
+func changedFields(change entryChange) string {
+	var fields []string
+	compare := func(name, before, after string) {
+		if before != after {
+			fields = append(fields, fmt.Sprintf("%s %s → %s", name, quoteEmpty(before), quoteEmpty(after)))
+		}
+	}
+	b, a := change.Before, change.After
+	compare(msg("diff.field_exercise"), b.Exercise, a.Exercise)
+	compare(msg("diff.field_level"), b.Level, a.Level)
+	compare(msg("diff.field_goal"), b.Goal, a.Goal)
+	compare(msg("diff.field_comment"), b.Comment, a.Comment)
+	compare(msg("diff.field_type"), calio.NormalizeWorkoutType(b.Type), calio.NormalizeWorkoutType(a.Type))
+	compare(msg("diff.field_category"), calio.NormalizeCategory(b.Category), calio.NormalizeCategory(a.Category))
+	compare(msg("diff.field_duration"), fmt.Sprint(b.Duration), fmt.Sprint(a.Duration))
+	return strings.Join(fields, ", ")
+}
+
+func quoteEmpty(value string) string {
+	if value == "" {
+		return `""`
+	}
+	return value
+}
+
+// previewLines renders diff in the style of a diff: "+ " for entries that
+// would be added, "− " for removed and "~ " for modified ones, at most limit
+// of them followed by a count of the rest.
+func previewLines(diff entryDiff, limit int) []string {
+	var lines []string
+	row := func(entry WorkoutEntry) string {
+		return strings.TrimSuffix(msg("list.row", displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level,
+			workText(entry), entry.Comment), "\n")
+	}
+	for _, entry := range diff.Added {
+		lines = append(lines, "+ "+row(entry))
+	}
+	for _, entry := range diff.Removed {
+		lines = append(lines, "− "+row(entry))
+	}
+	for _, change := range diff.Modified {
+		lines = append(lines, fmt.Sprintf("~ %s  %s - %s: %s", displayDate(change.Before.Date),
+			change.Before.Exercise, change.Before.Level, changedFields(change)))
+	}
+	if len(lines) > limit {
+		more := len(lines) - limit
+		lines = append(lines[:limit], msg("diff.more", more))
+	}
+	return lines
+}
+
+// printDryRun prints the preview of diff and its summary for --dry-run.
+func printDryRun(diff entryDiff) {
+	for _, line := range previewLines(diff, diffPreviewLimit) {
+		fmt.Println(line)
+	}
+	if diff.empty() {
+		fmt.Println(msg("diff.nothing"))
+		return
+	}
+	fmt.Print(msg("diff.summary", len(diff.Added), len(diff.Removed), len(diff.Modified)))
+}
@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestDiffEntries(t *testing.T) {
+	entry := func(date, exercise, reps, goal string) WorkoutEntry {
+		return WorkoutEntry{Date: date, Day: "A", Exercise: exercise, Level: "Full", RepsSets: reps, Goal: goal}
+	}
+	kept := entry("2026-03-02", "Pushups", "10x2", "20x2")
+	twice := entry("2026-03-02", "Squats", "30x2", "40x2")
+	stale := entry("2026-03-03", "Pushups", "12x2", "25x2")
+	gone := entry("2026-03-03", "Pullups", "5x2", "8x2")
+	fixed := stale
+	fixed.Goal = "20x2"
+	fixed.RowIndex = 9 // where an entry is stored doesn't make it another
+	added := entry("2026-03-04", "Bridges", "20x2", "25x2")
+
+	diff := diffEntries(
+		[]WorkoutEntry{kept, twice, stale, twice, gone},
+		[]WorkoutEntry{added, kept, fixed, twice},
+	)
+	if !slices.Equal(diff.Added, []WorkoutEntry{added}) {
+		t.Errorf("added %+v", diff.Added)
+	}
+	if !slices.Equal(diff.Removed, []WorkoutEntry{twice, gone}) {
+		t.Errorf("removed %+v, want one of the duplicates and the pullups", diff.Removed)
+	}
+	if !slices.Equal(diff.Modified, []entryChange{{Before: stale, After: fixed}}) {
+		t.Errorf("modified %+v", diff.Modified)
+	}
+	if !diffEntries([]WorkoutEntry{kept, twice}, []WorkoutEntry{twice, kept}).empty() {
+		t.Errorf("the same entries in another order differ")
+	}
+}
+
+func TestPreviewLines(t *testing.T) {
+	entry := WorkoutEntry{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Goal: "20x2"}
+	renamed := entry
+	renamed.Exercise, renamed.Level, renamed.Goal, renamed.Comment = "Pushups", "Half", "25x2", "hard"
+	diff := entryDiff{
+		Added:    []WorkoutEntry{entry},
+		Removed:  []WorkoutEntry{entry},
+		Modified: []entryChange{{Before: entry, After: renamed}},
+	}
+	want := []string{
+		"+ 2026-03-04 | Day A | Pushups - Full | 10x2 → 20x2 | ",
+		"− 2026-03-04 | Day A | Pushups - Full | 10x2 → 20x2 | ",
+		`~ 2026-03-04  Pushups - Full: level Full → Half, goal 20x2 → 25x2, comment "" → hard`,
+	}
+	if got := previewLines(diff, 10); !slices.Equal(got, want) {
+		t.Errorf("previewLines =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := previewLines(diff, 2); !slices.Equal(got, append(want[:2:2], "…and 1 more")) {
+		t.Errorf("previewLines capped at 2 = %q", got)
+	}
+	if got := previewLines(entryDiff{}, 10); got != nil {
+		t.Errorf("previewLines of nothing = %q", got)
+	}
+}
+
+// recordingStorage is the local backend with every mutation it is asked for
+// recorded, so a test can tell a command wrote nothing. It implements the
+// optional interfaces commands write through.
+type recordingStorage struct {
+	*calio.FileStorage
+}
+
+var (
+	recordingMu sync.Mutex
+	mutations   []string
+)
+
+func record(format string, a ...any) {
+	recordingMu.Lock()
+	defer recordingMu.Unlock()
+	mutations = append(mutations, fmt.Sprintf(format, a...))
+}
+
+// recorded returns the mutations since the last call.
+func recorded() []string {
+	recordingMu.Lock()
+	defer recordingMu.Unlock()
+	got := mutations
+	mutations = nil
+	return got
+}
+
+func (r recordingStorage) Append(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, error) {
+	record("Append %s %s", entry.Date, entry.Exercise)
+	return r.FileStorage.Append(ctx, entry)
+}
+
+func (r recordingStorage) AppendBatch(ctx context.Context, entries []WorkoutEntry) ([]WorkoutEntry, error) {
+	record("AppendBatch %d", len(entries))
+	return r.FileStorage.AppendBatch(ctx, entries)
+}
+
+func (r recordingStorage) RemoveByDateIndex(ctx context.Context, date string, index int) error {
+	record("RemoveByDateIndex %s %d", date, index)
+	return r.FileStorage.RemoveByDateIndex(ctx, date, index)
+}
+
+func (r recordingStorage) RemoveEntry(ctx context.Context, entry WorkoutEntry) error {
+	record("RemoveEntry %s %s", entry.Date, entry.Exercise)
+	return r.FileStorage.RemoveEntry(ctx, entry)
+}
+
+func (r recordingStorage) Restore(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, bool, error) {
+	record("Restore %s %s", entry.Date, entry.Exercise)
+	return r.FileStorage.Restore(ctx, entry)
+}
+
+func (r recordingStorage) RewriteGoals(ctx context.Context, fixes []calio.GoalFix) error {
+	record("RewriteGoals %d", len(fixes))
+	return r.FileStorage.RewriteGoals(ctx, fixes)
+}
+
+func (r recordingStorage) RewriteNames(ctx context.Context, fixes []calio.NameFix) error {
+	record("RewriteNames %d", len(fixes))
+	return r.FileStorage.RewriteNames(ctx, fixes)
+}
+
+func (r recordingStorage) SetGoal(ctx context.Context, override calio.GoalOverride) error {
+	record("SetGoal %s", override.Exercise)
+	return r.FileStorage.SetGoal(ctx, override)
+}
+
+func (r recordingStorage) SetLevelPin(ctx context.Context, pin calio.LevelPin) error {
+	record("SetLevelPin %s", pin.Exercise)
+	return r.FileStorage.SetLevelPin(ctx, pin)
+}
+
+func (r recordingStorage) AddRest(ctx context.Context, day calio.RestDay) error {
+	record("AddRest %s", day.Date)
+	return r.FileStorage.AddRest(ctx, day)
+}
+
+var registerRecording sync.Once
+
+// recordingLog makes runCLI store through recordingStorage, over the local
+// log it returns.
+func recordingLog(t *testing.T) *calio.FileStorage {
+	registerRecording.Do(func() {
+		calio.RegisterBackend("recording", func(ctx context.Context, cfg calio.BackendConfig) (calio.Storage, error) {
+			return recordingStorage{calio.NewFileStorage(cfg.Getenv("CALI_LOG_DIR"))}, nil
+		})
+	})
+	saved := testBackend
+	testBackend = "recording"
+	t.Cleanup(func() { testBackend = saved })
+	recorded()
+	return pipedLog(t)
+}
+
+// TestDryRunWritesNothing runs every --dry-run there is over a log that
+// each of them would change, and checks none of them asked the storage for
+// a single write; the same commands without it do.
+func TestDryRunWritesNothing(t *testing.T) {
+	storage := recordingLog(t)
+	home := t.TempDir()
+	ctx := context.Background()
+	today := currentTime().Format(calio.DateLayout)
+	for _, entry := range []WorkoutEntry{
+		{Date: today, Day: "A", Exercise: "Pushups", Level: "Half", RepsSets: "20x2", Goal: "20x2"},
+		{Date: today, Day: "A", Exercise: "Squats", Level: "Half", RepsSets: "30x2", Goal: "50x2"},
+	} {
+		if _, err := storage.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Append spells names the way cali does; a row typed by hand doesn't.
+	typed := strings.Replace(readFile(t, storage.FileFor(today)), "|Squats|Half|", "|squats|HALF|", 1)
+	if err := os.WriteFile(storage.FileFor(today), []byte(typed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rows := filepath.Join(home, "rows.csv")
+	if err := os.WriteFile(rows, []byte(today+",B,Pullups,Full,5x2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := func() string { return readFile(t, storage.FileFor(today)) }
+	before := files()
+
+	dryRuns := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"doctor", "--fix-goals", "--dry-run"}, "~ "},
+		{[]string{"doctor", "--normalize", "--dry-run"}, "~ "},
+		{[]string{"import", "--dry-run", rows}, "Pullups"},
+	}
+	for _, run := range dryRuns {
+		stdout, stderr, code := runCLIIn(t, home, "y\n", run.args...)
+		if code != 0 || !strings.Contains(stdout, run.want) {
+			t.Errorf("cali %s exited %d, printed %q %s", strings.Join(run.args, " "), code, stdout, stderr)
+		}
+		if got := recorded(); len(got) != 0 {
+			t.Errorf("cali %s wrote %q", strings.Join(run.args, " "), got)
+		}
+	}
+	if files() != before {
+		t.Fatalf("a dry run changed the log")
+	}
+
+	// The goal fix backs up the log first, which gives restore something
+	// to restore.
+	if _, stderr, code := runCLIIn(t, home, "y\n", "doctor", "--fix-goals"); code != 0 {
+		t.Fatalf("cali doctor --fix-goals exited %d: %s", code, stderr)
+	}
+	if got := recorded(); !slices.Equal(got, []string{"RewriteGoals 1"}) {
+		t.Errorf("cali doctor --fix-goals wrote %q", got)
+	}
+	if err := storage.RemoveByDateIndex(ctx, today, 0); err != nil {
+		t.Fatal(err)
+	}
+	fixed := files()
+	stdout, stderr, code := runCLIIn(t, home, "", "restore", "--latest-auto", "--dry-run")
+	if code != 0 || !strings.Contains(stdout, "+ ") || !strings.Contains(stdout, "Would add 1") {
+		t.Errorf("cali restore --dry-run exited %d, printed %q %s", code, stdout, stderr)
+	}
+	if got := recorded(); len(got) != 0 || files() != fixed {
+		t.Errorf("cali restore --dry-run wrote %q", got)
+	}
+	if _, stderr, code := runCLIIn(t, home, "y\n", "restore", "--latest-auto"); code != 0 {
+		t.Fatalf("cali restore exited %d: %s", code, stderr)
+	}
+	if got := recorded(); !slices.Equal(got, []string{"AppendBatch 1"}) {
+		t.Errorf("cali restore wrote %q", got)
+	}
+}
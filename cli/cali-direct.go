@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// stdinArg is the value of --comment, and the file of cali import, that
+// reads stdin instead.
+const stdinArg = "-"
+
+// direct reports whether the flags describe the entry, so cali log saves it
+// without asking anything (see logFromFlags).
+func (o logOptions) direct() bool {
+	return o.Day != "" || o.Exercise != "" || o.Level != "" || o.Reps != "" || o.Comment != ""
+}
+
+// checkDirect rejects a direct log missing a flag it needs. Asking for the
+// rest instead would wait forever on a pipe no one answers, and stdin may
+// be the comment itself.
+func checkDirect(opts logOptions) error {
+	var missing []string
+	for _, f := range []struct{ name, value string }{
+		{"--exercise", opts.Exercise}, {"--level", opts.Level}, {"--reps", opts.Reps},
+	} {
+		if f.value == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return usageError("logging with flags needs %s too (or none of --day, --exercise, --level, --reps and --comment to be asked)",
+			strings.Join(missing, ", "))
+	}
+	if opts.Comment == stdinArg && isTerminal(os.Stdin) {
+		return usageError("--comment - reads the comment from stdin; pipe it in, e.g. echo \"felt strong\" | cali log ... --comment -")
+	}
+	return nil
+}
+
+// readStdin reads the rest of stdin as one value, without the line breaks
+// that end it; those inside it are kept.
+func readStdin(what string) (string, error) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("reading %s from stdin: %w", what, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// logFromFlags saves the entry described by the flags of cali log, as cali q
+// does its line: names match loosely, the day defaults to the one being
+// trained, and extra prompts are only answered with --extra. Nothing is
+// read from stdin but a comment given as "-". The session lock isn't taken,
+// as there is no dialogue for another session to interleave with.
+func logFromFlags(ctx context.Context, storage Storage, opts logOptions) error {
+	loadGoalOverrides(ctx, storage)
+	exercise, ok := matchExercise(opts.Exercise)
+	if !ok {
+		return usageError("unknown exercise %q", opts.Exercise)
+	}
+	category := calio.CategoryStrength
+	if slices.Contains(calio.MobilityExercises(), exercise) {
+		category = calio.CategoryMobility
+	} else if opts.Category == calio.CategoryMobility {
+		return usageError("%s isn't a mobility exercise", exercise)
+	}
+	level, ok := matchLevel(exercise, opts.Level)
+	if !ok {
+		return usageError("%q is not a %s level", opts.Level, exercise)
+	}
+
+	workoutType := calio.TypeStraightSets
+	var repsSets string
+	if opts.Interval {
+		parsed, ok := parseRepsSets(opts.Reps)
+		if !ok || !parsed.interval() {
+			return usageError("--interval: %q isn't a timed protocol (e.g. \"EMOM 10min @ 12\")", opts.Reps)
+		}
+		workoutType, repsSets = calio.TypeInterval, formatInterval(parsed)
+	} else {
+		reps, err := quickReps(exercise, level, opts.Reps)
+		if err != nil {
+			return usageError("--reps: %v", err)
+		}
+		repsSets = reps
+	}
+
+	date := currentTime().Format(calio.DateLayout)
+	day := strings.ToUpper(strings.TrimSpace(opts.Day))
+	switch {
+	case category == calio.CategoryMobility:
+		day = ""
+	case day == "":
+		day = quickDay(ctx, storage, date)
+	case !slices.Contains(calio.DayLetters(), day):
+		return usageError("unknown day %q (use %s)", opts.Day, strings.Join(calio.DayLetters(), ", "))
+	}
+
+	comment := opts.Comment
+	if comment == stdinArg {
+		var err error
+		if comment, err = readStdin("the comment"); err != nil {
+			return err
+		}
+	}
+	comment, err := checkCommentLength(nil, strings.TrimSpace(comment), false)
+	if err != nil {
+		return err
+	}
+	if opts.Deload {
+		comment = addDeloadTag(comment)
+	}
+	answers, err := checkExtras(promptsFor(extraPrompts, exercise), exercise, opts.Extras)
+	if err != nil {
+		return usageError("%v", err)
+	}
+	comment = addCommentFlags(addReadiness(addExtras(comment, answers), opts.Readiness), opts.Flags)
+
+	return saveEntry(ctx, storage, WorkoutEntry{
+		Date:     date,
+		Day:      day,
+		Exercise: exercise,
+		Level:    level,
+		RepsSets: repsSets,
+		Goal:     resolveGoal(exercise, level),
+		Comment:  comment,
+		Type:     workoutType,
+		Category: category,
+		Load:     opts.Load,
+	})
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// pipedLog points CALI_LOG_DIR at a new directory and returns the storage
+// on it, to see what runCLI saved.
+func pipedLog(t *testing.T) *calio.FileStorage {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "log")
+	t.Setenv("CALI_LOG_DIR", dir)
+	return calio.NewFileStorage(dir)
+}
+
+func logged(t *testing.T, storage *calio.FileStorage) []WorkoutEntry {
+	t.Helper()
+	entries, err := storage.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+// TestPipedStdinWithoutFlags checks cali doesn't take piped text for the
+// answers to its questions.
+func TestPipedStdinWithoutFlags(t *testing.T) {
+	storage := pipedLog(t)
+	for _, args := range [][]string{nil, {"log"}, {"--deload"}} {
+		_, stderr, code := runCLI(t, "x\n", args...)
+		if code != exitUsage || !strings.Contains(stderr, "stdin isn't one") {
+			t.Errorf("cali %q with piped stdin exited %d: %s", args, code, stderr)
+		}
+	}
+	if entries := logged(t, storage); len(entries) != 0 {
+		t.Errorf("saved %+v", entries)
+	}
+}
+
+func TestPipedComment(t *testing.T) {
+	storage := pipedLog(t)
+	_, stderr, code := runCLI(t, "long dictated note\nsecond line\n\n",
+		"log", "--day", "B", "--exercise", "pullups", "--level", "full", "--reps", "8 x 2", "--comment", "-")
+	if code != 0 {
+		t.Fatalf("exited %d: %s", code, stderr)
+	}
+	entries := logged(t, storage)
+	if len(entries) != 1 {
+		t.Fatalf("saved %d entries, want 1", len(entries))
+	}
+	got := entries[0]
+	// The local file joins the comment's lines.
+	if got.Day != "B" || got.Exercise != "Pullups" || got.Level != "Full" || got.RepsSets != "8x2" ||
+		!strings.HasPrefix(got.Comment, "long dictated note second line") {
+		t.Errorf("saved %+v", got)
+	}
+
+	_, stderr, code = runCLI(t, "note", "log", "--exercise", "pullups", "--comment", "-")
+	if code != exitUsage || !strings.Contains(stderr, "--level, --reps") {
+		t.Errorf("missing flags: exited %d: %s", code, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 1 {
+		t.Errorf("%d entries after a refused log, want 1", len(entries))
+	}
+}
+
+func TestPipedImport(t *testing.T) {
+	storage := pipedLog(t)
+	rows := "Date,Day,Exercise,Level,RepsxSets,Goal,Comment,Type,Category\n" +
+		"2026-03-04,A,push ups,full,20x2,,\"felt \"\"strong\"\"\",,\n" +
+		"2026-03-04,,l-sit,tuck,30s,,,,\n"
+
+	stdout, stderr, code := runCLI(t, rows, "import", "--dry-run", "-")
+	if code != 0 || !strings.Contains(stdout, "Pushups") {
+		t.Fatalf("import --dry-run exited %d: %s%s", code, stdout, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 0 {
+		t.Fatalf("--dry-run saved %d entries", len(entries))
+	}
+
+	if _, stderr, code := runCLI(t, rows, "import", "-"); code != 0 {
+		t.Fatalf("import exited %d: %s", code, stderr)
+	}
+	entries := logged(t, storage)
+	if len(entries) != 2 {
+		t.Fatalf("imported %d entries, want 2", len(entries))
+	}
+	if got := entries[0]; got.Exercise != "Pushups" || got.Level != "Full" || got.Comment != `felt "strong"` || got.Goal == "" {
+		t.Errorf("first entry = %+v", got)
+	}
+	if got := entries[1]; got.Exercise != "L-Sit" || got.Category != calio.CategoryMobility {
+		t.Errorf("second entry = %+v", got)
+	}
+
+	_, stderr, code = runCLI(t, "2026-03-04,A,rows,full,8x2\n", "import", "-")
+	if code != exitUsage || !strings.Contains(stderr, "stdin: line 1") {
+		t.Errorf("bad row: exited %d: %s", code, stderr)
+	}
+}
+
+// TestPipedImportFutureDates checks rows piped to cali import - are held
+// to the same future-date rule as a file's.
+func TestPipedImportFutureDates(t *testing.T) {
+	storage := pipedLog(t)
+	now := currentTime()
+	rows := fmt.Sprintf("%s,A,Pushups,Full,10x2\n%s,B,Squats,Full,10x2\n",
+		now.Format(calio.DateLayout), now.AddDate(1, 0, 0).Format(calio.DateLayout))
+
+	_, stderr, code := runCLI(t, rows, "import", "-")
+	if code != exitUsage || !strings.Contains(stderr, now.AddDate(1, 0, 0).Format(calio.DateLayout)) || !strings.Contains(stderr, "--allow-future") {
+		t.Errorf("import exited %d: %s", code, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 0 {
+		t.Fatalf("saved %+v", entries)
+	}
+
+	if _, stderr, code := runCLI(t, rows, "import", "--allow-future", "-"); code != 0 {
+		t.Fatalf("import --allow-future exited %d: %s", code, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 2 {
+		t.Errorf("saved %d entries, want 2", len(entries))
+	}
+}
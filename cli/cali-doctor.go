@@ -0,0 +1,352 @@
+package cli
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// futureMark flags future-dated entries in listings. They stay in storage
+// and still show, but every analytic drops them (see calio.WithoutFuture).
+// Entries whose date can't be read get the same mark.
+const futureMark = "⚠ "
+
+// doctorOptions are the flags of cali doctor.
+type doctorOptions struct {
+	Goals     bool
+	FixGoals  bool
+	Normalize bool
+	DryRun    bool
+}
+
+// newDoctorFlagSet declares the flags of cali doctor into opts.
+func newDoctorFlagSet(opts *doctorOptions) *flag.FlagSet {
+	fs := newFlagSet("doctor")
+	fs.BoolVar(&opts.Goals, "goals", false, "compare the stored goals with the current ones instead")
+	fs.BoolVar(&opts.FixGoals, "fix-goals", false, "like --goals, then rewrite the stored goals that differ")
+	fs.BoolVar(&opts.Normalize, "normalize", false, "rewrite exercise and level names not spelled as cali spells them, after a preview")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "with --fix-goals or --normalize, show the rewrite without writing anything")
+	return fs
+}
+
+// runDoctor checks the stored log for entries the analytics ignore, for
+// date cells in another format or none cali knows, for work and load cells
+// the spreadsheet turned into dates or times, for rows written with a
+// newer schema than this build reads in full, and for sheet columns it
+// can't map by their heading. keyWarned says checkKeyAge already reported a
+// problem.
+func runDoctor(ctx context.Context, storage Storage, keyWarned bool) error {
+	// The log is walked once, keeping only counts and the entries to list.
+	now := currentTime()
+	schemas := map[int]int{}
+	var future, reformatted, unreadable, coerced, newer []WorkoutEntry
+	newest := 0
+	writers := map[string]bool{}
+	err := calio.ForEach(ctx, storage, "", "", func(entry WorkoutEntry) error {
+		schemas[entry.Schema]++
+		if calio.FutureDated(entry.Date, now) {
+			future = append(future, entry)
+		}
+		switch {
+		case calio.UnreadableDate(entry):
+			unreadable = append(unreadable, entry)
+		case entry.RawDate != "":
+			reformatted = append(reformatted, entry)
+		}
+		if len(calio.CoercedCells(entry)) > 0 {
+			coerced = append(coerced, entry)
+		}
+		if calio.NewerSchema(entry) {
+			newer = append(newer, entry)
+			newest = max(newest, entry.Schema)
+			writers[cmp.Or(entry.Writer, msg("doctor.writer_unknown"))] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	if len(schemas) > 0 {
+		fmt.Print(msg("doctor.schemas", schemaSummary(schemas)))
+	}
+	if paths, err := userPaths(); err == nil && paths.Legacy() {
+		say("%s", paths.legacyHint())
+	}
+
+	problems := keyWarned
+	if checker, ok := storage.(calio.ColumnChecker); ok {
+		moved, warnings, err := checker.CheckColumns(ctx)
+		if err != nil && !errors.Is(err, calio.ErrNoColumnChecker) {
+			return storageError("checking the sheet columns", err)
+		}
+		for _, tab := range moved {
+			fmt.Print(msg("doctor.columns_moved", tab))
+		}
+		if len(warnings) > 0 {
+			problems = true
+			fmt.Print(msg("doctor.column_warnings", len(warnings)))
+			for _, warning := range warnings {
+				fmt.Printf("  %s\n", warning)
+			}
+			sayln(msg("doctor.column_hint"))
+		}
+	}
+	if len(future) > 0 {
+		problems = true
+		fmt.Print(msg("doctor.future_header", len(future)))
+		sayln(separatorRule())
+		for _, entry := range future {
+			fmt.Print(futureMark + msg("list.row",
+				displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level, workText(entry), entry.Comment))
+		}
+		sayln(separatorRule())
+		sayln(msg("doctor.future_hint"))
+	}
+
+	if len(reformatted) > 0 {
+		fmt.Print(msg("doctor.reformatted_dates", len(reformatted)))
+		for _, entry := range reformatted {
+			fmt.Printf("  %q → %s  %s - %s\n", entry.RawDate, entry.Date, entry.Exercise, entry.Level)
+		}
+	}
+	if len(unreadable) > 0 {
+		problems = true
+		fmt.Print(msg("doctor.unreadable_dates", len(unreadable)))
+		sayln(separatorRule())
+		for _, entry := range unreadable {
+			fmt.Print(futureMark + msg("list.row",
+				fmt.Sprintf("%q", cmp.Or(entry.RawDate, entry.Date)), entry.Day, entry.Exercise, entry.Level, workText(entry), entry.Comment))
+			if location := entryLocation(storage, entry); location != "" {
+				fmt.Print("  " + location)
+			}
+		}
+		sayln(separatorRule())
+		sayln(msg("doctor.date_hint"))
+	}
+
+	if len(coerced) > 0 {
+		problems = true
+		fmt.Print(msg("doctor.coerced", len(coerced)))
+		for _, entry := range coerced {
+			fmt.Printf("  %s  %s - %s: %s\n", displayDate(entry.Date), entry.Exercise, entry.Level,
+				strings.Join(calio.CoercedCells(entry), ", "))
+			if location := entryLocation(storage, entry); location != "" {
+				fmt.Print("    " + location)
+			}
+		}
+		sayln(msg("doctor.coerced_hint"))
+	}
+
+	if len(newer) > 0 {
+		problems = true
+		fmt.Print(msg("doctor.newer_schema", len(newer), newest,
+			strings.Join(slices.Sorted(maps.Keys(writers)), ", "), calio.SchemaVersion))
+		sayln(msg("doctor.newer_hint"))
+	}
+
+	if !problems {
+		fmt.Println(msg("doctor.ok"))
+	}
+	return nil
+}
+
+// schemaSummary describes the count of entries per schema, e.g. "120
+// unmarked (read as v1), 35 v1".
+func schemaSummary(counts map[int]int) string {
+	var parts []string
+	for _, schema := range slices.Sorted(maps.Keys(counts)) {
+		if schema == 0 {
+			parts = append(parts, msg("doctor.schema_unmarked", counts[schema]))
+			continue
+		}
+		parts = append(parts, msg("doctor.schema_count", counts[schema], schema))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// goalMismatch is an entry whose stored goal isn't the level's goal now.
+type goalMismatch struct {
+	Entry WorkoutEntry
+	Want  string
+}
+
+// goalMismatches compares the goal stored with each entry against
+// resolveGoal, the user's own goal or the built-in one. Intervals, which
+// store no goal to compare, are skipped; entries of levels the dataset
+// doesn't know are returned separately, since there is no goal to fix them
+// to.
+func goalMismatches(entries []WorkoutEntry) (mismatches []goalMismatch, unknown []WorkoutEntry) {
+	for _, entry := range entries {
+		if isInterval(entry) {
+			continue
+		}
+		if _, ok := calio.Goal(entry.Exercise, entry.Level); !ok {
+			unknown = append(unknown, entry)
+			continue
+		}
+		if want := resolveGoal(entry.Exercise, entry.Level); entry.Goal != want {
+			mismatches = append(mismatches, goalMismatch{entry, want})
+		}
+	}
+	return mismatches, unknown
+}
+
+// checkGoals lists the entries whose stored goal differs from the current
+// one and, with fix, rewrites them after asking. With dryRun it previews the
+// rewrite instead and writes nothing. Unknown levels are only listed.
+func checkGoals(ctx context.Context, storage Storage, fix, dryRun bool) error {
+	loadGoalOverrides(ctx, storage)
+	entries, err := storage.All(ctx)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	mismatches, unknown := goalMismatches(entries)
+
+	if len(mismatches) > 0 {
+		fmt.Print(msg("doctor.goal_mismatches", len(mismatches)))
+		for _, m := range mismatches {
+			fmt.Printf("  %s  %-34s %s → %s\n", displayDate(m.Entry.Date),
+				m.Entry.Exercise+" - "+m.Entry.Level, cmp.Or(m.Entry.Goal, `""`), m.Want)
+		}
+	}
+	if len(unknown) > 0 {
+		fmt.Print(msg("doctor.goal_unknown", len(unknown)))
+		for _, entry := range unknown {
+			fmt.Printf("  %s  %s - %s (%s)\n", displayDate(entry.Date), entry.Exercise, entry.Level, cmp.Or(entry.Goal, `""`))
+		}
+	}
+	if len(mismatches) == 0 && len(unknown) == 0 {
+		fmt.Println(msg("doctor.goals_ok"))
+		return nil
+	}
+	if !fix || len(mismatches) == 0 {
+		if len(mismatches) > 0 {
+			sayln(msg("doctor.goal_fix_hint"))
+		}
+		return nil
+	}
+
+	rewriter, ok := storage.(calio.GoalRewriter)
+	if !ok {
+		return storageError("rewriting goals", calio.ErrNoGoalRewriter)
+	}
+	if dryRun {
+		var diff entryDiff
+		for _, m := range mismatches {
+			fixed := m.Entry
+			fixed.Goal = m.Want
+			diff.Modified = append(diff.Modified, entryChange{Before: m.Entry, After: fixed})
+		}
+		fmt.Println()
+		printDryRun(diff)
+		return nil
+	}
+	prompt(msg("doctor.goal_fix_confirm", len(mismatches)))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	if !slices.Contains(strings.Split(msg("answer.yes"), ","), answer) {
+		promptln(msg("remove.cancelled"))
+		return errCancelled
+	}
+
+	fixes := make([]calio.GoalFix, len(mismatches))
+	for i, m := range mismatches {
+		fixes[i] = calio.GoalFix{Entry: m.Entry, Goal: m.Want}
+	}
+	beforeMutation(ctx, storage)
+	if err := rewriter.RewriteGoals(ctx, fixes); err != nil {
+		return storageError("rewriting goals", err)
+	}
+	fmt.Print(msg("doctor.goals_fixed", len(fixes)))
+	return nil
+}
+
+// nameMismatches returns the fixes that store each entry's exercise and
+// level as the dataset spells them, e.g. "full" or "Full " as "Full".
+// Entries whose exercise or level matches nothing known are returned
+// separately, since there is no spelling to fix them to.
+func nameMismatches(entries []WorkoutEntry) (fixes []calio.NameFix, unknown []WorkoutEntry) {
+	for _, entry := range entries {
+		exercise, ok := calio.CanonicalExercise(entry.Exercise)
+		if ok {
+			_, ok = calio.CanonicalLevel(exercise, entry.Level)
+		}
+		if !ok {
+			unknown = append(unknown, entry)
+		}
+		if fix, ok := calio.NameFixFor(entry); ok {
+			fixes = append(fixes, fix)
+		}
+	}
+	return fixes, unknown
+}
+
+// normalizeNames previews the entries whose exercise or level isn't spelled
+// canonically and rewrites them after asking. With dryRun it shows the
+// rewrite and writes nothing. Names cali doesn't know are only listed.
+func normalizeNames(ctx context.Context, storage Storage, dryRun bool) error {
+	entries, err := storage.All(ctx)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	fixes, unknown := nameMismatches(entries)
+
+	if len(fixes) > 0 {
+		fmt.Print(msg("doctor.name_mismatches", len(fixes)))
+		for _, fix := range fixes {
+			fmt.Printf("  %s  %q - %q → %s - %s\n", displayDate(fix.Entry.Date),
+				fix.Entry.Exercise, fix.Entry.Level, fix.Exercise, fix.Level)
+		}
+	}
+	if len(unknown) > 0 {
+		fmt.Print(msg("doctor.name_unknown", len(unknown)))
+		for _, entry := range unknown {
+			fmt.Printf("  %s  %q - %q\n", displayDate(entry.Date), entry.Exercise, entry.Level)
+		}
+	}
+	if len(fixes) == 0 {
+		if len(unknown) == 0 {
+			fmt.Println(msg("doctor.names_ok"))
+		}
+		return nil
+	}
+
+	rewriter, ok := storage.(calio.NameRewriter)
+	if !ok {
+		return storageError("rewriting names", calio.ErrNoNameRewriter)
+	}
+	if dryRun {
+		var diff entryDiff
+		for _, fix := range fixes {
+			fixed := fix.Entry
+			fixed.Exercise, fixed.Level = fix.Exercise, fix.Level
+			diff.Modified = append(diff.Modified, entryChange{Before: fix.Entry, After: fixed})
+		}
+		fmt.Println()
+		printDryRun(diff)
+		return nil
+	}
+	prompt(msg("doctor.name_fix_confirm", len(fixes)))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	if !slices.Contains(strings.Split(msg("answer.yes"), ","), answer) {
+		promptln(msg("remove.cancelled"))
+		return errCancelled
+	}
+
+	beforeMutation(ctx, storage)
+	if err := rewriter.RewriteNames(ctx, fixes); err != nil {
+		return storageError("rewriting names", err)
+	}
+	fmt.Print(msg("doctor.names_fixed", len(fixes)))
+	return nil
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestSchemaSummary(t *testing.T) {
+	if got := schemaSummary(map[int]int{4: 35, 0: 120, 9: 1}); got != "120 unmarked (read as v1), 35 v4, 1 v9" {
+		t.Errorf("schemaSummary = %q", got)
+	}
+}
+
+// TestDoctorSchemas checks cali doctor counts the schemas in the log and
+// flags rows of a schema newer than this build.
+func TestDoctorSchemas(t *testing.T) {
+	storage := pipedLog(t)
+	if _, err := storage.Append(context.Background(), WorkoutEntry{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2"}); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code := runCLI(t, "", "doctor")
+	if code != 0 || !strings.Contains(stdout, fmt.Sprintf("Row schemas: 1 v%d\n", calio.SchemaVersion)) || !strings.Contains(stdout, "No problems found") {
+		t.Errorf("cali doctor on a current log exited %d, printed %q %s", code, stdout, stderr)
+	}
+
+	file, err := os.OpenFile(storage.FileFor("2026-03-04"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(file, "2026-03-04|A|Squats|Full|30x2|30x2|||||")
+	fmt.Fprintln(file, "2026-03-04|A|Squats|Full|30x2|30x2||straight-sets|strength||v9 cali/v3.0.0|||||RPE 8")
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, _ = runCLI(t, "", "doctor")
+	want := fmt.Sprintf("Row schemas: 1 unmarked (read as v1), 1 v%d, 1 v9\n", calio.SchemaVersion)
+	if !strings.Contains(stdout, want) || !strings.Contains(stdout, "1 row(s) use schema up to v9, written by cali/v3.0.0") {
+		t.Errorf("cali doctor printed %q, want the schemas and the newer row flagged", stdout)
+	}
+}
+
+func TestGoalMismatches(t *testing.T) {
+	withGoalOverrides(t, map[string]map[string]string{"Squats": {"Half": "40x2"}})
+	entries := []WorkoutEntry{
+		{Date: "2026-03-02", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Goal: "20x2"},
+		{Date: "2026-03-02", Exercise: "Pushups", Level: "Half", RepsSets: "20x2", Goal: "20x2"},
+		{Date: "2026-03-02", Exercise: "Squats", Level: "Half", RepsSets: "35x2", Goal: "50x2"},
+		{Date: "2026-03-03", Exercise: "Squats", Level: "Half", RepsSets: "35x2", Goal: "40x2"},
+		{Date: "2026-03-03", Exercise: "Pullups", Level: "Full", RepsSets: "EMOM 10min @ 5", Type: calio.TypeInterval},
+		{Date: "2026-03-04", Exercise: "Burpees", Level: "Any", RepsSets: "10x3", Goal: "20x3"},
+		{Date: "2026-03-04", Exercise: "Pushups", Level: "Archer", RepsSets: "5x2"},
+	}
+	mismatches, unknown := goalMismatches(entries)
+	var got []string
+	for _, m := range mismatches {
+		got = append(got, m.Entry.Exercise+" "+m.Entry.Level+" "+m.Entry.Goal+"→"+m.Want)
+	}
+	if want := "Pushups Half 20x2→25x2, Squats Half 50x2→40x2"; strings.Join(got, ", ") != want {
+		t.Errorf("the mismatches are %s, want %s", strings.Join(got, ", "), want)
+	}
+	if len(unknown) != 2 || unknown[0].Exercise != "Burpees" || unknown[1].Level != "Archer" {
+		t.Errorf("the unknown entries are %+v", unknown)
+	}
+}
+
+// TestDoctorGoals reports a stale goal, previews and declines the fix, and
+// then rewrites it, leaving the unknown level alone.
+func TestDoctorGoals(t *testing.T) {
+	storage := pipedLog(t)
+	for _, entry := range []WorkoutEntry{
+		{Date: "2026-03-02", Day: "A", Exercise: "Pushups", Level: "Half", RepsSets: "20x2", Goal: "20x2"},
+		{Date: "2026-03-02", Day: "A", Exercise: "Burpees", Level: "Any", RepsSets: "10x3", Goal: "20x3"},
+	} {
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stdout, stderr, code := runCLI(t, "", "doctor", "--goals")
+	if code != 0 || !strings.Contains(stdout, "20x2 → 25x2") || !strings.Contains(stdout, "Burpees - Any (20x3)") || !strings.Contains(stdout+stderr, "--fix-goals") {
+		t.Errorf("cali doctor --goals exited %d, printed %q %s", code, stdout, stderr)
+	}
+	before := readFile(t, storage.FileFor("2026-03-02"))
+
+	stdout, _, code = runCLI(t, "", "doctor", "--fix-goals", "--dry-run")
+	if code != 0 || !strings.Contains(stdout, "25x2") || readFile(t, storage.FileFor("2026-03-02")) != before {
+		t.Errorf("cali doctor --fix-goals --dry-run exited %d, printed %q, or wrote", code, stdout)
+	}
+	if _, _, code := runCLI(t, "n\n", "doctor", "--fix-goals"); code != exitCancelled || readFile(t, storage.FileFor("2026-03-02")) != before {
+		t.Errorf("declining the fix exited %d, or wrote", code)
+	}
+
+	if _, stderr, code := runCLI(t, "y\n", "doctor", "--fix-goals"); code != 0 {
+		t.Fatalf("cali doctor --fix-goals exited %d: %s", code, stderr)
+	}
+	entries := logged(t, storage)
+	if len(entries) != 2 || entries[0].Goal != "25x2" || entries[1].Goal != "20x3" {
+		t.Errorf("after the fix the log holds %+v", entries)
+	}
+	if stdout, _, _ := runCLI(t, "", "doctor", "--goals"); strings.Contains(stdout, "→") {
+		t.Errorf("cali doctor --goals after the fix printed %q", stdout)
+	}
+}
+
+func TestNameMismatches(t *testing.T) {
+	fixes, unknown := nameMismatches([]WorkoutEntry{
+		{Exercise: "pushups", Level: "FULL"},
+		{Exercise: "Pushups", Level: "Full"},
+		{Exercise: "Squats", Level: "half "},
+		{Exercise: "burpees", Level: "any"},
+		{Exercise: "PULLUPS", Level: "archer"},
+	})
+	var got []string
+	for _, fix := range fixes {
+		got = append(got, fix.Entry.Exercise+"/"+fix.Entry.Level+"→"+fix.Exercise+"/"+fix.Level)
+	}
+	// An unknown level of a known exercise still gets the exercise fixed.
+	if want := "pushups/FULL→Pushups/Full, Squats/half →Squats/Half, PULLUPS/archer→Pullups/archer"; strings.Join(got, ", ") != want {
+		t.Errorf("the fixes are %s, want %s", strings.Join(got, ", "), want)
+	}
+	if len(unknown) != 2 || unknown[0].Exercise != "burpees" || unknown[1].Level != "archer" {
+		t.Errorf("the unknown entries are %+v", unknown)
+	}
+}
+
+// TestDoctorNormalize previews the fixes of a hand-edited log, declines
+// them, then rewrites exactly the misspelled lines; the unknown names are
+// reported and left alone.
+func TestDoctorNormalize(t *testing.T) {
+	storage := pipedLog(t)
+	typed := "2026-03-02|A|pushups|FULL|20x2|20x2|\n" +
+		"2026-03-02|A|Squats|Half|35x2|50x2|\n" +
+		"2026-03-02|A|squats|half|30x2|50x2|\n" +
+		"2026-03-03|B|Burpees|Any|10x3||\n"
+	if err := os.MkdirAll(os.Getenv("CALI_LOG_DIR"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(storage.FileFor("2026-03-02"), []byte(typed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runCLI(t, "n\n", "doctor", "--normalize")
+	for _, want := range []string{`"pushups" - "FULL" → Pushups - Full`, `"squats" - "half" → Squats - Half`, `"Burpees" - "Any"`} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("cali doctor --normalize printed\n%s%s\nwant %q", stdout, stderr, want)
+		}
+	}
+	if strings.Contains(stdout, `"Squats" - "Half"`) || code != exitCancelled || readFile(t, storage.FileFor("2026-03-02")) != typed {
+		t.Errorf("declining cali doctor --normalize exited %d, or wrote", code)
+	}
+
+	if _, stderr, code := runCLI(t, "y\n", "doctor", "--normalize"); code != 0 {
+		t.Fatalf("cali doctor --normalize exited %d: %s", code, stderr)
+	}
+	want := "2026-03-02|A|Pushups|Full|20x2|20x2|\n" +
+		"2026-03-02|A|Squats|Half|35x2|50x2|\n" +
+		"2026-03-02|A|Squats|Half|30x2|50x2|\n" +
+		"2026-03-03|B|Burpees|Any|10x3||\n"
+	if got := readFile(t, storage.FileFor("2026-03-02")); got != want {
+		t.Errorf("after cali doctor --normalize the log is\n%s\nwant\n%s", got, want)
+	}
+	if stdout, _, code := runCLI(t, "", "doctor", "--normalize"); code != 0 || strings.Contains(stdout, "→") {
+		t.Errorf("cali doctor --normalize after the fix exited %d, printed %q", code, stdout)
+	}
+}
+
+// TestWritesCanonical logs names typed in any case through every command
+// that writes, and checks they are stored as cali spells them and counted
+// as one exercise by the stats.
+func TestWritesCanonical(t *testing.T) {
+	storage := pipedLog(t)
+	rows := filepath.Join(t.TempDir(), "import.csv")
+	date := currentTime().AddDate(0, 0, -1).Format(calio.DateLayout)
+	if err := os.WriteFile(rows, []byte(date+",A,PUSHUPS,full,10x2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"log", "--day", "A", "--exercise", "pushUPS ", "--level", "FULL", "--reps", "12x2", "--comment", "-"},
+		{"q", "--yes", "A PushUps FuLL 11x2"},
+		{"import", rows},
+	} {
+		if _, stderr, code := runCLI(t, "y\n", args...); code != 0 {
+			t.Fatalf("cali %s exited %d: %s", strings.Join(args, " "), code, stderr)
+		}
+	}
+	entries := logged(t, storage)
+	if len(entries) != 3 {
+		t.Fatalf("logged %+v", entries)
+	}
+	for _, entry := range entries {
+		if entry.Exercise != "Pushups" || entry.Level != "Full" {
+			t.Errorf("stored %q - %q", entry.Exercise, entry.Level)
+		}
+	}
+
+	// Names typed into the file by hand still count as the same exercise.
+	typed := readFile(t, storage.FileFor(date)) + date + "|A|pushups|full |9x2|20x2|\n"
+	if err := os.WriteFile(storage.FileFor(date), []byte(typed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stats := computeStats(logged(t, storage), currentTime())
+	if stats.PerExercise["Pushups"] != 4 || len(stats.PerExercise) != 1 {
+		t.Errorf("the stats count %v", stats.PerExercise)
+	}
+	if records := personalRecords(logged(t, storage)); len(records) != 1 {
+		t.Errorf("the records are kept under %d names", len(records))
+	}
+}
+
+// TestDoctorCoerced checks cali doctor lists rows whose work or load the
+// spreadsheet turned into a date, with where they are.
+func TestDoctorCoerced(t *testing.T) {
+	storage := pipedLog(t)
+	for _, entry := range []WorkoutEntry{
+		{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10-30x2", Goal: "20x2", Load: "+10kg"},
+		{Date: "2026-03-04", Day: "A", Exercise: "Squats", Level: "Full", RepsSets: "10/30/2026", Goal: "30x2"},
+		{Date: "2026-03-06", Day: "B", Exercise: "Pullups", Level: "Half", RepsSets: "8x2", Goal: "0.0625", Load: "Jan-10"},
+	} {
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stdout, _, code := runCLI(t, "", "doctor")
+	want := msg("doctor.coerced", 2) +
+		"  " + displayDate("2026-03-04") + "  Squats - Full: RepsxSets \"10/30/2026\"\n" +
+		"    " + msg("log.saved_line", 2, storage.FileFor("2026-03-04")) +
+		"  " + displayDate("2026-03-06") + "  Pullups - Half: Goal \"0.0625\", Load \"Jan-10\"\n"
+	if code != 0 || !strings.Contains(stdout, want) || !strings.Contains(stdout, msg("doctor.coerced_hint")) || strings.Contains(stdout, "Pushups") {
+		t.Errorf("cali doctor exited %d, printed %q, want %q", code, stdout, want)
+	}
+}
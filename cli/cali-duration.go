@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"math"
+	"time"
+)
+
+// Measured sessions shorter or longer than these are taken for a flow that
+// was abandoned and picked up again, or one that never waited on a workout,
+// and not recorded.
+const (
+	minSessionDuration = time.Minute
+	maxSessionDuration = 4 * time.Hour
+)
+
+// sessionClock times the interactive log flow, from its start to the
+// confirmation of the entry, so the entry records how long the session took.
+// now is time.Now outside tests.
+type sessionClock struct {
+	now     func() time.Time
+	started time.Time
+}
+
+func startSessionClock(now func() time.Time) *sessionClock {
+	return &sessionClock{now: now, started: now()}
+}
+
+// minutes returns the time since the start in whole minutes, or 0 when that
+// isn't plausible for a session. A nil clock measures nothing.
+func (c *sessionClock) minutes() int {
+	if c == nil {
+		return 0
+	}
+	elapsed := c.now().Sub(c.started)
+	if elapsed < minSessionDuration || elapsed > maxSessionDuration {
+		return 0
+	}
+	return int(math.Round(elapsed.Minutes()))
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSessionClock runs sessions of various lengths on a clock the test
+// moves forward.
+func TestSessionClock(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		minutes int
+	}{
+		{40 * time.Minute, 40},
+		{40*time.Minute + 29*time.Second, 40},
+		{40*time.Minute + 30*time.Second, 41},
+		{minSessionDuration, 1},
+		{minSessionDuration - time.Second, 0},
+		{maxSessionDuration, 240},
+		{maxSessionDuration + time.Second, 0},
+		{0, 0},
+		{-time.Hour, 0},
+	}
+	for _, tt := range tests {
+		now := time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC)
+		clock := startSessionClock(func() time.Time { return now })
+		now = now.Add(tt.elapsed)
+		if got := clock.minutes(); got != tt.minutes {
+			t.Errorf("a session of %v measures %d minutes, want %d", tt.elapsed, got, tt.minutes)
+		}
+	}
+	var off *sessionClock
+	if got := off.minutes(); got != 0 {
+		t.Errorf("a session with --no-duration measures %d minutes", got)
+	}
+}
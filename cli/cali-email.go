@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// How the SMTP connection is secured (CALI_SMTP_SECURITY).
+const (
+	smtpStartTLS = "starttls" // plain connection upgraded with STARTTLS, usually port 587
+	smtpTLS      = "tls"      // implicit TLS from the first byte, usually port 465
+	smtpNone     = "none"     // no encryption, for a relay on localhost
+)
+
+const smtpTimeout = 30 * time.Second
+
+// smtpConfig holds the CALI_SMTP_* settings. Password is never printed, not
+// even with --verbose.
+type smtpConfig struct {
+	Host     string
+	Port     string
+	Security string
+	Username string
+	Password string // password or app token
+	From     string
+	To       []string
+}
+
+func (c smtpConfig) addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+// loadSMTPConfig reads the mail settings from the environment (for the
+// reminder timer, the remind.env file). Port and security default to each
+// other: 465 means implicit TLS, anything else STARTTLS on 587.
+func loadSMTPConfig(getenv func(string) string) (smtpConfig, error) {
+	cfg := smtpConfig{
+		Host:     strings.TrimSpace(getenv("CALI_SMTP_HOST")),
+		Port:     strings.TrimSpace(getenv("CALI_SMTP_PORT")),
+		Security: strings.ToLower(strings.TrimSpace(getenv("CALI_SMTP_SECURITY"))),
+		Username: strings.TrimSpace(getenv("CALI_SMTP_USER")),
+		Password: getenv("CALI_SMTP_PASSWORD"),
+		From:     strings.TrimSpace(getenv("CALI_SMTP_FROM")),
+	}
+	for _, to := range strings.Split(getenv("CALI_SMTP_TO"), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			cfg.To = append(cfg.To, to)
+		}
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+
+	switch {
+	case cfg.Host == "":
+		return smtpConfig{}, &calio.ConfigError{Name: "CALI_SMTP_HOST"}
+	case len(cfg.To) == 0:
+		return smtpConfig{}, &calio.ConfigError{Name: "CALI_SMTP_TO"}
+	case cfg.From == "":
+		return smtpConfig{}, &calio.ConfigError{Name: "CALI_SMTP_FROM", Reason: "CALI_SMTP_FROM (or CALI_SMTP_USER) is required"}
+	}
+	for _, address := range append([]string{cfg.From}, cfg.To...) {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return smtpConfig{}, fmt.Errorf("invalid email address %q: %w", address, err)
+		}
+	}
+
+	switch cfg.Security {
+	case "":
+		cfg.Security = smtpStartTLS
+		if cfg.Port == "465" {
+			cfg.Security = smtpTLS
+		}
+	case smtpStartTLS, smtpTLS, smtpNone:
+	default:
+		return smtpConfig{}, fmt.Errorf("unknown CALI_SMTP_SECURITY %q (use starttls, tls or none)", cfg.Security)
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+		if cfg.Security == smtpTLS {
+			cfg.Port = "465"
+		}
+	}
+	return cfg, nil
+}
+
+// buildMail assembles a multipart/alternative message with a plain-text body
+// and its HTML rendering, both UTF-8 and quoted-printable.
+func buildMail(cfg smtpConfig, subject, text, html string, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, alt := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {alt.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := io.WriteString(qp, alt.content); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&message, "%s: %s\r\n", name, value)
+	}
+	to := make([]string, len(cfg.To))
+	for i, address := range cfg.To {
+		to[i] = headerAddress(address)
+	}
+	header("From", headerAddress(cfg.From))
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary()))
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+// sendMail delivers message to cfg.To. Cancelling ctx closes the connection.
+func sendMail(ctx context.Context, cfg smtpConfig, message []byte) error {
+	detail("SMTP: %s (%s) as %q, from %s to %s\n", cfg.addr(), cfg.Security, cfg.Username, cfg.From, strings.Join(cfg.To, ", "))
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if cfg.Security == smtpTLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: cfg.Host}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", cfg.addr())
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", cfg.addr())
+	}
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if cfg.Security == smtpStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("the server does not offer STARTTLS (set CALI_SMTP_SECURITY=tls for port 465)")
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		// PlainAuth refuses to send the password unencrypted except to localhost.
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("authenticating as %q: %w", cfg.Username, err)
+		}
+	}
+	if err := client.Mail(envelopeAddress(cfg.From)); err != nil {
+		return err
+	}
+	for _, to := range cfg.To {
+		if err := client.Rcpt(envelopeAddress(to)); err != nil {
+			return fmt.Errorf("recipient %s: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// headerAddress formats an address for a header, encoding a non-ASCII
+// display name.
+func headerAddress(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		return parsed.String()
+	}
+	return address
+}
+
+// envelopeAddress strips a display name ("Ziad <z@example.com>") for the
+// MAIL FROM and RCPT TO commands.
+func envelopeAddress(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		return parsed.Address
+	}
+	return address
+}
@@ -0,0 +1,375 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// smtpServer speaks just enough SMTP on a local port to receive one
+// message per connection: EHLO, AUTH PLAIN, MAIL, RCPT, DATA and QUIT.
+type smtpServer struct {
+	listener   net.Listener
+	extensions []string // EHLO keywords offered besides AUTH PLAIN
+	rejectRcpt string   // a recipient answered with 550
+
+	mu       sync.Mutex
+	auth     string // the decoded AUTH PLAIN response
+	from     string
+	to       []string
+	data     string
+	commands []string
+}
+
+func startSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost test SMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		verb = strings.ToUpper(verb)
+		s.mu.Lock()
+		s.commands = append(s.commands, verb)
+		s.mu.Unlock()
+		switch verb {
+		case "EHLO", "HELO":
+			text.PrintfLine("250-localhost")
+			for _, extension := range s.extensions {
+				text.PrintfLine("250-%s", extension)
+			}
+			text.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			_, response, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(response)
+			s.mu.Lock()
+			s.auth = string(decoded)
+			s.mu.Unlock()
+			text.PrintfLine("235 accepted")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = arg
+			s.mu.Unlock()
+			text.PrintfLine("250 ok")
+		case "RCPT":
+			if s.rejectRcpt != "" && strings.Contains(arg, s.rejectRcpt) {
+				text.PrintfLine("550 no such user")
+				continue
+			}
+			s.mu.Lock()
+			s.to = append(s.to, arg)
+			s.mu.Unlock()
+			text.PrintfLine("250 ok")
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) received() (auth, from string, to []string, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.auth, s.from, s.to, s.data
+}
+
+func (s *smtpServer) config() smtpConfig {
+	return smtpConfig{
+		Host:     "127.0.0.1",
+		Port:     s.port(),
+		Security: smtpNone,
+		Username: "ziad@example.com",
+		Password: "app-token-secret",
+		From:     "Zïad <ziad@example.com>",
+		To:       []string{"coach@example.com", "Me <me@example.com>"},
+	}
+}
+
+func TestLoadSMTPConfig(t *testing.T) {
+	base := map[string]string{"CALI_SMTP_HOST": "smtp.example.com", "CALI_SMTP_TO": "a@example.com, b@example.com", "CALI_SMTP_USER": "me@example.com"}
+	with := func(extra map[string]string) func(string) string {
+		env := map[string]string{}
+		for k, v := range base {
+			env[k] = v
+		}
+		for k, v := range extra {
+			env[k] = v
+		}
+		return envOf(env)
+	}
+	tests := []struct {
+		extra          map[string]string
+		port, security string
+	}{
+		{nil, "587", smtpStartTLS},
+		{map[string]string{"CALI_SMTP_PORT": "465"}, "465", smtpTLS},
+		{map[string]string{"CALI_SMTP_SECURITY": "TLS"}, "465", smtpTLS},
+		{map[string]string{"CALI_SMTP_SECURITY": "none", "CALI_SMTP_PORT": "25"}, "25", smtpNone},
+		{map[string]string{"CALI_SMTP_PORT": "2525"}, "2525", smtpStartTLS},
+	}
+	for _, tt := range tests {
+		cfg, err := loadSMTPConfig(with(tt.extra))
+		if err != nil || cfg.Port != tt.port || cfg.Security != tt.security {
+			t.Errorf("%v: port %s, security %s, %v; want %s, %s", tt.extra, cfg.Port, cfg.Security, err, tt.port, tt.security)
+		}
+		if cfg.From != "me@example.com" || len(cfg.To) != 2 {
+			t.Errorf("%v: from %q to %q", tt.extra, cfg.From, cfg.To)
+		}
+	}
+
+	for _, bad := range []map[string]string{
+		{"CALI_SMTP_HOST": ""},
+		{"CALI_SMTP_TO": " , "},
+		{"CALI_SMTP_USER": ""},
+		{"CALI_SMTP_TO": "not an address"},
+		{"CALI_SMTP_SECURITY": "ssl"},
+	} {
+		if _, err := loadSMTPConfig(with(bad)); err == nil {
+			t.Errorf("%v: no error", bad)
+		}
+	}
+}
+
+func TestBuildMail(t *testing.T) {
+	cfg := smtpConfig{From: "Zïad <ziad@example.com>", To: []string{"coach@example.com", "Me <me@example.com>"}}
+	text := "Week of 2026-10-05: 4 sessions — " + strings.Repeat("Pushups Full 20x2, ", 8) + "\n"
+	html := `<p>Week of 2026-10-05: <b>4</b> sessions — "great" week</p>`
+	message, err := buildMail(cfg, "Cali recap — week 41", text, html, time.Date(2026, 10, 12, 7, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body, _ := strings.Cut(string(message), "\r\n\r\n")
+	for _, line := range strings.SplitAfter(body, "\r\n") {
+		if len(line) > 76+2 {
+			t.Errorf("body line longer than 76 characters: %q", line)
+		}
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(message)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder := new(mime.WordDecoder)
+	if subject, err := decoder.DecodeHeader(parsed.Header.Get("Subject")); err != nil || subject != "Cali recap — week 41" {
+		t.Errorf("Subject = %q, %v", subject, err)
+	}
+	if from, err := parsed.Header.AddressList("From"); err != nil || from[0].Name != "Zïad" || from[0].Address != "ziad@example.com" {
+		t.Errorf("From = %v, %v", from, err)
+	}
+	if to, err := parsed.Header.AddressList("To"); err != nil || len(to) != 2 || to[1].Address != "me@example.com" {
+		t.Errorf("To = %v, %v", to, err)
+	}
+	if date, err := parsed.Header.Date(); err != nil || !date.Equal(time.Date(2026, 10, 12, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v, %v", date, err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %s, %v", mediaType, err)
+	}
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, want := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		part, err := parts.NextRawPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if part.Header.Get("Content-Type") != want.contentType || part.Header.Get("Content-Transfer-Encoding") != "quoted-printable" {
+			t.Errorf("part headers = %v", part.Header)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil || strings.ReplaceAll(string(body), "\r\n", "\n") != want.body {
+			t.Errorf("%s body = %q, %v; want %q", want.contentType, body, err, want.body)
+		}
+	}
+	if _, err := parts.NextPart(); err != io.EOF {
+		t.Errorf("more than two parts: %v", err)
+	}
+}
+
+func TestSendMail(t *testing.T) {
+	server := startSMTPServer(t)
+	cfg := server.config()
+	message, err := buildMail(cfg, "recap", "plain\n.leading dot\n", "<p>html</p>", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sendMail(context.Background(), cfg, message); err != nil {
+		t.Fatal(err)
+	}
+	auth, from, to, data := server.received()
+	if auth != "\x00ziad@example.com\x00app-token-secret" {
+		t.Errorf("AUTH PLAIN = %q", auth)
+	}
+	if from != "FROM:<ziad@example.com>" || strings.Join(to, " ") != "TO:<coach@example.com> TO:<me@example.com>" {
+		t.Errorf("envelope from %q to %q", from, to)
+	}
+	if data != strings.ReplaceAll(string(message), "\r\n", "\n") {
+		t.Errorf("message arrived as\n%s\nwant\n%s", data, message)
+	}
+}
+
+func TestSendMailErrors(t *testing.T) {
+	server := startSMTPServer(t)
+	cfg := server.config()
+	cfg.Security = smtpStartTLS
+	if err := sendMail(context.Background(), cfg, []byte("x")); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("server without STARTTLS: %v", err)
+	}
+
+	server.rejectRcpt = "me@example.com"
+	cfg.Security = smtpNone
+	if err := sendMail(context.Background(), cfg, []byte("x")); err == nil || !strings.Contains(err.Error(), "recipient Me <me@example.com>") {
+		t.Errorf("rejected recipient: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sendMail(ctx, server.config(), []byte("x")); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: %v", err)
+	}
+}
+
+// TestSendMailKeepsThePasswordOutOfVerboseOutput runs a send with
+// --verbose and checks what it printed.
+func TestSendMailKeepsThePasswordOutOfVerboseOutput(t *testing.T) {
+	server := startSMTPServer(t)
+	saved := outputLevel
+	outputLevel = levelVerbose
+	t.Cleanup(func() { outputLevel = saved })
+	cfg := server.config()
+	stderr := captureOutput(t, &os.Stderr, func() {
+		if err := sendMail(context.Background(), cfg, []byte("Subject: x\r\n\r\nx\r\n")); err != nil {
+			t.Error(err)
+		}
+	})
+	if !strings.Contains(stderr, "SMTP: 127.0.0.1") {
+		t.Errorf("no SMTP detail line in %q", stderr)
+	}
+	if strings.Contains(stderr, cfg.Password) {
+		t.Errorf("verbose output shows the password: %q", stderr)
+	}
+}
+
+func TestEmailReport(t *testing.T) {
+	server := startSMTPServer(t)
+	storage := calio.NewFileStorage(t.TempDir())
+	week := dateRange{Since: "2026-10-05", Until: "2026-10-11"}
+	quiet(t)
+
+	stdout := captureOutput(t, &os.Stdout, func() {
+		if err := emailReport(context.Background(), storage, server.config(), week, reportOptions{Email: true}); !errors.Is(err, errNoResults) {
+			t.Errorf("empty week: %v, want errNoResults", err)
+		}
+	})
+	if !strings.Contains(stdout, "2026") {
+		t.Errorf("empty week logged %q, want a line naming the dates", stdout)
+	}
+	if _, _, _, data := server.received(); data != "" {
+		t.Fatal("an empty week was sent")
+	}
+
+	if _, err := storage.Append(context.Background(), WorkoutEntry{Date: "2026-10-06", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := emailReport(context.Background(), storage, server.config(), week, reportOptions{Email: true}); err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, data := server.received()
+	parsed, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(parsed.Body)
+	if !strings.Contains(string(body), "Pushups") {
+		t.Errorf("report doesn't mention the week's workout:\n%s", body)
+	}
+
+	server.rejectRcpt = "coach@example.com"
+	if err := emailReport(context.Background(), storage, server.config(), week, reportOptions{Email: true}); exitCode(err) != exitStorage {
+		t.Errorf("failed send: %v, want exit code %d", err, exitStorage)
+	}
+}
+
+// captureOutput returns what fn writes to *file, os.Stdout or os.Stderr,
+// swapped for a pipe while it runs.
+func captureOutput(t *testing.T, file **os.File, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := *file
+	*file = w
+	done := make(chan string)
+	go func() {
+		var out strings.Builder
+		io.Copy(&out, bufio.NewReader(r))
+		done <- out.String()
+	}()
+	defer func() {
+		*file = saved
+		w.Close()
+		r.Close()
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
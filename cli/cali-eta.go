@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// Limits of the goal estimate in cali progress.
+const (
+	etaSessions  = 8 // latest sessions at a level the trend is fitted to
+	etaMinPoints = 3 // fewer sessions than this give no estimate
+	etaHorizon   = 6 // months; estimates further out are not shown
+	etaSteadyFit = 0.7
+)
+
+// Why a goalETA has a date, or why it has none. Each has a progress.eta_*
+// message.
+const (
+	etaSteady  = "steady"   // estimate from a close fit
+	etaRough   = "rough"    // estimate from a noisy fit
+	etaReached = "reached"  // the latest session met the goal
+	etaTooFew  = "too_few"  // fewer than etaMinPoints sessions
+	etaNoTrend = "no_trend" // flat or falling
+	etaTooFar  = "too_far"  // beyond etaHorizon
+)
+
+// goalETA estimates when a level's goal will be met at the current rate.
+type goalETA struct {
+	Date     time.Time // zero unless Note is etaSteady or etaRough
+	Note     string
+	PerWeek  float64 // fitted gain per week, in reps or seconds held
+	Timed    bool    // the goal is a hold, so PerWeek is in seconds
+	Sessions int     // training days the fit used
+}
+
+// etaPoint is the best total logged on one day.
+type etaPoint struct {
+	Date time.Time
+	Have int
+}
+
+// estimateGoalDate fits a line through the best total of each of the last
+// etaSessions training days in entries, which should all be one exercise and
+// level, and solves it for goal. today bounds the estimate: dates already
+// past become today, and dates more than etaHorizon months ahead are
+// reported as etaTooFar. Deloads, intervals and values that don't measure
+// the same thing as goal are skipped. The result depends only on the inputs.
+func estimateGoalDate(entries []WorkoutEntry, goal string, today time.Time) goalETA {
+	today = truncateToDate(today)
+	target, _ := parseRepsSets(goal)
+	eta := goalETA{Timed: target.timed()}
+
+	best := map[string]int{}
+	var want int
+	var latest WorkoutEntry
+	for _, entry := range workingEntries(entries) {
+		if parsed, ok := parseRepsSets(entry.RepsSets); !ok || parsed.interval() {
+			continue
+		}
+		have, w, ok := goalAmounts(entry.RepsSets, goal)
+		if !ok {
+			continue
+		}
+		want = w
+		if current, seen := best[entry.Date]; !seen || have > current {
+			best[entry.Date] = have
+		}
+		if entry.Date >= latest.Date {
+			latest = entry
+		}
+	}
+
+	var points []etaPoint
+	for date, have := range best {
+		if parsed, err := time.ParseInLocation(calio.DateLayout, date, today.Location()); err == nil {
+			points = append(points, etaPoint{parsed, have})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+	if len(points) > etaSessions {
+		points = points[len(points)-etaSessions:]
+	}
+	eta.Sessions = len(points)
+
+	if len(points) > 0 && meetsGoal(latest.RepsSets, goal) {
+		eta.Note = etaReached
+		return eta
+	}
+	if len(points) < etaMinPoints {
+		eta.Note = etaTooFew
+		return eta
+	}
+
+	// Least squares over days since the first point.
+	var sumX, sumY float64
+	for _, p := range points {
+		sumX += float64(daysBetween(points[0].Date, p.Date))
+		sumY += float64(p.Have)
+	}
+	n := float64(len(points))
+	meanX, meanY := sumX/n, sumY/n
+	var sxx, sxy, syy float64
+	for _, p := range points {
+		dx := float64(daysBetween(points[0].Date, p.Date)) - meanX
+		dy := float64(p.Have) - meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 || sxy <= 0 {
+		eta.Note = etaNoTrend
+		return eta
+	}
+	slope := sxy / sxx
+	eta.PerWeek = math.Round(slope*7*10) / 10
+
+	days := int(math.Ceil((float64(want) - (meanY - slope*meanX)) / slope))
+	date := points[0].Date.AddDate(0, 0, days)
+	if date.Before(today) {
+		date = today
+	}
+	if date.After(today.AddDate(0, etaHorizon, 0)) {
+		eta.Note = etaTooFar
+		return eta
+	}
+	eta.Date = date
+	eta.Note = etaRough
+	if sxy*sxy/(sxx*syy) >= etaSteadyFit {
+		eta.Note = etaSteady
+	}
+	return eta
+}
+
+// rateText renders PerWeek, e.g. "+1.5 reps/week" or "+4 s/week".
+func (e goalETA) rateText() string {
+	if e.Timed {
+		return msg("progress.rate_hold", formatMetric(e.PerWeek))
+	}
+	return msg("progress.rate_reps", formatMetric(e.PerWeek))
+}
+
+// currentLevels returns, per exercise in dataset order, its pinned level
+// (see cali levels set), else the level of its latest working session.
+func currentLevels(strength []WorkoutEntry) []exerciseLevel {
+	latest := map[string]WorkoutEntry{}
+	for _, entry := range workingEntries(strength) {
+		entry = calio.Canonical(entry)
+		if previous, seen := latest[entry.Exercise]; !seen || entry.Date >= previous.Date {
+			latest[entry.Exercise] = entry
+		}
+	}
+	var levels []exerciseLevel
+	for _, exercise := range calio.Exercises() {
+		if level, ok := levelPin(exercise); ok {
+			levels = append(levels, exerciseLevel{exercise, level})
+		} else if entry, ok := latest[exercise]; ok {
+			levels = append(levels, exerciseLevel{exercise, entry.Level})
+		}
+	}
+	return levels
+}
+
+func runProgress(ctx context.Context, args []string, rng dateRange) error {
+	var opts progressOptions
+	args, err := parseInterspersed(newProgressFlagSet(&opts), args)
+	if err != nil {
+		return flagError(err)
+	}
+	if opts.Notes {
+		if len(args) == 0 {
+			return usageError("usage: cali progress <exercise> --notes [--grep <text>] [--since <date>]")
+		}
+		exercise, ok := normalizeExercise(strings.Join(args, " "))
+		if !ok {
+			return usageError("%s", msg("error.unknown_exercise", strings.Join(args, " ")))
+		}
+		return showNotes(ctx, exercise, rng, opts.Grep)
+	}
+	if opts.Grep != "" {
+		return usageError("--grep only applies to cali progress --notes")
+	}
+	if rng.isSet() {
+		return usageError("usage: cali progress [exercise]")
+	}
+	var only string
+	if len(args) > 0 {
+		exercise, ok := normalizeExercise(strings.Join(args, " "))
+		if !ok {
+			return usageError("%s", msg("error.unknown_exercise", strings.Join(args, " ")))
+		}
+		only = exercise
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	entries, err := storage.All(ctx)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	loadGoalOverrides(ctx, storage)
+	loadLevelPins(ctx, storage)
+	now := currentTime()
+	strength, _ := splitByCategory(calio.WithoutFuture(entries, now))
+
+	var shown int
+	for _, key := range currentLevels(strength) {
+		if only != "" && key.Exercise != only {
+			continue
+		}
+		goal := resolveGoal(key.Exercise, key.Level)
+		if goal == "-" {
+			continue
+		}
+		var atLevel []WorkoutEntry
+		for _, entry := range strength {
+			if sameName(entry.Exercise, key.Exercise) && sameName(entry.Level, key.Level) {
+				atLevel = append(atLevel, entry)
+			}
+		}
+		if shown == 0 {
+			sayln(msg("progress.header"))
+		}
+		shown++
+
+		eta := estimateGoalDate(atLevel, goal, now)
+		fmt.Print(msg("progress.level", key.Exercise, key.Level, goal))
+		switch eta.Note {
+		case etaSteady, etaRough:
+			fmt.Print(msg("progress.eta", goal, key.Level, displayDate(eta.Date.Format(calio.DateLayout)),
+				msg("progress.eta_"+eta.Note), eta.rateText(), eta.Sessions))
+		case etaReached:
+			next := msg("progress.next_none")
+			if levels := calio.Levels(key.Exercise); levels[len(levels)-1] != key.Level {
+				next = msg("progress.next", levels[slices.Index(levels, key.Level)+1])
+			}
+			fmt.Print(msg("progress.eta_reached", next))
+		case etaTooFew:
+			fmt.Print(msg("progress.eta_too_few", eta.Sessions, etaMinPoints))
+		case etaNoTrend:
+			fmt.Print(msg("progress.eta_no_trend", eta.Sessions))
+		case etaTooFar:
+			fmt.Print(msg("progress.eta_too_far", etaHorizon, eta.rateText()))
+		}
+	}
+	if shown == 0 {
+		fmt.Println(msg("history.empty"))
+		return errNoResults
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// weekly returns Full Pushups sessions a week apart from 2026-01-05, one per
+// value of work.
+func weekly(work ...string) []WorkoutEntry {
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	var entries []WorkoutEntry
+	for i, w := range work {
+		entries = append(entries, WorkoutEntry{
+			Date: start.AddDate(0, 0, 7*i).Format(calio.DateLayout), Day: "A",
+			Exercise: "Pushups", Level: "Full", RepsSets: w, Goal: "21x2",
+		})
+	}
+	return entries
+}
+
+func TestEstimateGoalDate(t *testing.T) {
+	today := time.Date(2026, 1, 26, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		entries []WorkoutEntry
+		goal    string
+		note    string
+		date    string // "" when no date is estimated
+		perWeek float64
+	}{
+		// 20, 24, 28, 32 reps: 4 a week, 42 reached 38.5 days after the first.
+		{"improving", weekly("10x2", "12x2", "14x2", "16x2"), "21x2", etaSteady, "2026-02-13", 4},
+		{"noisy", weekly("10x2", "15x2", "12x2", "17x2", "14x2", "19x2"), "21x2", etaRough, "2026-02-26", 2.7},
+		{"flat", weekly("10x2", "10x2", "10x2", "10x2"), "21x2", etaNoTrend, "", 0},
+		{"falling", weekly("16x2", "14x2", "12x2", "10x2"), "21x2", etaNoTrend, "", 0},
+		{"two sessions", weekly("10x2", "16x2"), "21x2", etaTooFew, "", 0},
+		{"no sessions", nil, "21x2", etaTooFew, "", 0},
+		{"goal met last", weekly("10x2", "16x2", "21x2"), "21x2", etaReached, "", 0},
+		{"half a rep a week", weekly("10x2", "10x2", "21x1"), "21x2", etaTooFar, "", 0.5},
+		{"holds", weekly("20s", "30s", "40s"), "60s", etaSteady, "2026-02-02", 10},
+	}
+	for _, tt := range tests {
+		eta := estimateGoalDate(tt.entries, tt.goal, today)
+		date := ""
+		if !eta.Date.IsZero() {
+			date = eta.Date.Format(calio.DateLayout)
+		}
+		if eta.Note != tt.note || date != tt.date || eta.PerWeek != tt.perWeek {
+			t.Errorf("%s: estimateGoalDate = %s %q at %v/week, want %s %q at %v/week",
+				tt.name, eta.Note, date, eta.PerWeek, tt.note, tt.date, tt.perWeek)
+		}
+	}
+}
+
+// TestEstimateGoalDateInputs checks what of the entries the fit uses.
+func TestEstimateGoalDateInputs(t *testing.T) {
+	today := time.Date(2026, 1, 26, 0, 0, 0, 0, time.UTC)
+	want := estimateGoalDate(weekly("10x2", "12x2", "14x2", "16x2"), "21x2", today)
+
+	// Order doesn't matter.
+	shuffled := weekly("10x2", "12x2", "14x2", "16x2")
+	slices.Reverse(shuffled)
+	if got := estimateGoalDate(shuffled, "21x2", today); got != want {
+		t.Errorf("reversed entries estimate %+v, want %+v", got, want)
+	}
+
+	// A deload, an interval, a weaker second set of the day and work that
+	// isn't in reps are all skipped.
+	extra := weekly("10x2", "12x2", "14x2", "16x2")
+	last := extra[3]
+	for _, entry := range []WorkoutEntry{
+		{Date: last.Date, RepsSets: "20x2", Comment: "easy week #deload"},
+		{Date: last.Date, RepsSets: "EMOM 10min @ 12", Type: calio.TypeInterval},
+		{Date: last.Date, RepsSets: "8x2"},
+		{Date: "2026-01-27", RepsSets: "45s"},
+	} {
+		entry.Exercise, entry.Level, entry.Goal = "Pushups", "Full", "21x2"
+		extra = append(extra, entry)
+	}
+	if got := estimateGoalDate(extra, "21x2", today); got != want {
+		t.Errorf("with entries to skip the estimate is %+v, want %+v", got, want)
+	}
+
+	// Only the latest etaSessions sessions count: two early outliers don't
+	// flatten the trend.
+	long := weekly("20x2", "20x2", "10x2", "10x2", "11x2", "11x2", "12x2", "12x2", "13x2", "13x2")
+	if got := estimateGoalDate(long, "21x2", today.AddDate(0, 0, 63)); got.Sessions != etaSessions || got.Note == etaNoTrend {
+		t.Errorf("ten sessions estimate %+v, want a trend over %d", got, etaSessions)
+	}
+
+	// A date already past is today.
+	if got := estimateGoalDate(weekly("10x2", "12x2", "14x2", "16x2"), "21x2", today.AddDate(0, 1, 0)); !got.Date.Equal(truncateToDate(today.AddDate(0, 1, 0))) {
+		t.Errorf("a goal due before today is estimated at %v", got.Date)
+	}
+}
+
+func TestRateText(t *testing.T) {
+	if got := (goalETA{PerWeek: 1.5}).rateText(); got != "+1.5 reps/week" {
+		t.Errorf("rateText = %q", got)
+	}
+	if got := (goalETA{PerWeek: 4, Timed: true}).rateText(); got != "+4 s/week" {
+		t.Errorf("rateText of a hold = %q", got)
+	}
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// Exit codes returned by Run, so wrapper scripts can tell failures apart.
+const (
+	exitOK        = 0
+	exitInternal  = 1   // anything not classified below
+	exitUsage     = 2   // bad flags, arguments or typed input
+	exitStorage   = 3   // storage configuration, auth or I/O failure
+	exitNotFound  = 4   // empty results, only with --fail-empty
+	exitCancelled = 130 // cancelled at a prompt or input closed
+)
+
+// cliError attaches an exit code to an error. A nil err means the command
+// already told the user what happened, so nothing more is printed.
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *cliError) Unwrap() error { return e.err }
+
+var (
+	// errNoResults is returned by listings that found nothing. Run treats
+	// it as success unless --fail-empty was given.
+	errNoResults = &cliError{code: exitNotFound}
+	errCancelled = &cliError{code: exitCancelled}
+)
+
+// cancelGrace is how long a command gets to return after Ctrl-C cancels its
+// context before the process exits anyway, e.g. while blocked at a prompt.
+const cancelGrace = 500 * time.Millisecond
+
+var (
+	forcedExitMu    sync.Mutex
+	forcedExitHooks []func()
+)
+
+// onForcedExit registers cleanup for the exit commandContext forces after
+// Ctrl-C, which skips deferred calls. Hooks must be safe to run even when the
+// command already cleaned up.
+func onForcedExit(hook func()) {
+	forcedExitMu.Lock()
+	defer forcedExitMu.Unlock()
+	forcedExitHooks = append(forcedExitHooks, hook)
+}
+
+func runForcedExitHooks() {
+	forcedExitMu.Lock()
+	defer forcedExitMu.Unlock()
+	for _, hook := range forcedExitHooks {
+		hook()
+	}
+}
+
+// commandContext returns the context a command passes to every storage call.
+// Ctrl-C cancels it, so in-flight Sheets requests and long reads stop and the
+// command returns; a second Ctrl-C, or a command that doesn't return within
+// cancelGrace, exits with exitCancelled as before. Call finish once the
+// command has returned.
+func commandContext() (ctx context.Context, finish func()) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+		stop() // restore the default, so a second Ctrl-C kills the process
+		select {
+		case <-done:
+		case <-time.After(cancelGrace):
+			runForcedExitHooks()
+			fmt.Fprintln(os.Stderr)
+			os.Exit(exitCancelled)
+		}
+	}()
+	return ctx, func() {
+		close(done)
+		stop()
+	}
+}
+
+// inputClosed stops a re-prompting loop once stdin is exhausted.
+func inputClosed() error {
+	return &cliError{code: exitCancelled, err: errors.New(msg("log.input_closed"))}
+}
+
+func usageError(format string, a ...any) error {
+	return &cliError{code: exitUsage, err: fmt.Errorf(format, a...)}
+}
+
+// storageError wraps a failure from newStorage or a Storage method.
+func storageError(action string, err error) error {
+	return &cliError{code: exitStorage, err: fmt.Errorf("%s: %w", action, err)}
+}
+
+// flagError classifies the error from a FlagSet's Parse. The flag package has
+// already printed the problem and the usage; -h stops the command but is not
+// a failure.
+func flagError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return &cliError{code: exitOK}
+	}
+	return &cliError{code: exitUsage, err: err}
+}
+
+// extractFailEmpty removes the global --fail-empty flag from args.
+func extractFailEmpty(args []string) ([]string, bool) {
+	var rest []string
+	failEmpty := false
+	for _, arg := range args {
+		if arg == "--fail-empty" {
+			failEmpty = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, failEmpty
+}
+
+// exitCode reports err on stderr and returns the code main should exit with.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprint(os.Stderr, msg("error.interrupted"))
+		return exitCancelled
+	}
+	if errors.Is(err, calio.ErrUnrecognizedTab) {
+		defer fmt.Fprint(os.Stderr, msg("error.unrecognized_tab"))
+	}
+	if calio.AuthRefused(err) {
+		defer fmt.Fprint(os.Stderr, keyRefusedHint(sheetsKey, currentTime()))
+	}
+	var ce *cliError
+	if !errors.As(err, &ce) {
+		fmt.Fprint(os.Stderr, msg("error.prefix", err))
+		return exitInternal
+	}
+	if ce.err != nil {
+		fmt.Fprint(os.Stderr, msg("error.prefix", err))
+	}
+	return ce.code
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   int
+		stderr string // part of what is printed; "" when nothing is
+	}{
+		{"success", nil, exitOK, ""},
+		{"unclassified", errors.New("boom"), exitInternal, "boom"},
+		{"usage", usageError("bad %s", "day"), exitUsage, "bad day"},
+		{"storage", storageError("reading workouts", os.ErrPermission), exitStorage, "reading workouts: permission denied"},
+		{"wrapped storage", fmt.Errorf("saving: %w", storageError("appending", os.ErrClosed)), exitStorage, "saving: appending"},
+		{"no results", errNoResults, exitNotFound, ""},
+		{"help", flagError(flag.ErrHelp), exitOK, ""},
+		{"bad flag", flagError(errors.New("flag provided but not defined: -x")), exitUsage, "-x"},
+		{"input closed", inputClosed(), exitCancelled, "input closed"},
+		{"interrupted", storageError("reading workouts", context.Canceled), exitCancelled, "Interrupted"},
+	}
+	for _, tt := range tests {
+		var code int
+		stderr := captureOutput(t, &os.Stderr, func() { code = exitCode(tt.err) })
+		if code != tt.code {
+			t.Errorf("%s: exitCode = %d, want %d", tt.name, code, tt.code)
+		}
+		if tt.stderr == "" && stderr != "" || !strings.Contains(stderr, tt.stderr) {
+			t.Errorf("%s: printed %q, want %q", tt.name, stderr, tt.stderr)
+		}
+	}
+}
+
+// TestRunExitCodes checks the codes the whole command exits with.
+func TestRunExitCodes(t *testing.T) {
+	pipedLog(t)
+	if _, _, code := runCLI(t, "", "nonsense"); code != exitUsage {
+		t.Errorf("cali nonsense exited %d, want %d", code, exitUsage)
+	}
+	if _, _, code := runCLI(t, "", "today"); code != exitOK {
+		t.Errorf("cali today on an empty log exited %d, want %d", code, exitOK)
+	}
+	if _, _, code := runCLI(t, "", "today", "--fail-empty"); code != exitNotFound {
+		t.Errorf("cali today --fail-empty on an empty log exited %d, want %d", code, exitNotFound)
+	}
+
+	// A log directory that is a file can't be read.
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CALI_LOG_DIR", notDir)
+	if _, stderr, code := runCLI(t, "", "-s", "today"); code != exitStorage {
+		t.Errorf("cali -s today with a file for a log directory exited %d, want %d: %s", code, exitStorage, stderr)
+	}
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+const (
+	// Google Fit activity type for calisthenics.
+	gfitActivityCalisthenics = 21
+
+	defaultSessionLength = 45 * time.Minute
+	defaultSessionStart  = "18:00"
+)
+
+// gfitSession mirrors the Session resource of the Google Fit REST API
+// (users.sessions). Millisecond timestamps are strings as in the API.
+type gfitSession struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Description     string          `json:"description"`
+	StartTimeMillis string          `json:"startTimeMillis"`
+	EndTimeMillis   string          `json:"endTimeMillis"`
+	ActivityType    int             `json:"activityType"`
+	Application     gfitApplication `json:"application"`
+}
+
+type gfitApplication struct {
+	Name string `json:"name"`
+}
+
+type gfitExport struct {
+	Session []gfitSession `json:"session"`
+}
+
+// exportOptions are the flags of cali export.
+type exportOptions struct {
+	Format         string
+	Header         bool
+	Start          string
+	SessionLength  time.Duration
+	IncludePrivate bool
+}
+
+// newExportFlagSet declares the flags of cali export into opts.
+func newExportFlagSet(opts *exportOptions) *flag.FlagSet {
+	fs := newFlagSet("export")
+	fs.StringVar(&opts.Format, "format", "", "output format (gfit-json, csv or tsv)")
+	fs.BoolVar(&opts.Header, "header", false, "with csv or tsv, start with a row of column headings")
+	fs.StringVar(&opts.Start, "start", defaultSessionStart, "session start time (HH:MM) for dates logged without a time")
+	fs.DurationVar(&opts.SessionLength, "session-length", sessionLengthFromEnv(), "session length when none was measured")
+	fs.BoolVar(&opts.IncludePrivate, "include-private", false, "keep comments marked private instead of showing [redacted]")
+	return fs
+}
+
+func runExport(ctx context.Context, storage Storage, args []string, rng dateRange) error {
+	var opts exportOptions
+	fs := newExportFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+
+	if opts.SessionLength <= 0 {
+		return usageError("--session-length must be positive")
+	}
+
+	switch opts.Format {
+	case "gfit-json":
+		var days gfitDays
+		err := calio.ForEach(ctx, storage, rng.Since, rng.Until, func(entry WorkoutEntry) error {
+			if classifyEntry(entry) == kindWarmup {
+				return nil
+			}
+			days.add(shareable(entry, opts.IncludePrivate))
+			return nil
+		})
+		if err != nil {
+			return storageError("exporting workouts", err)
+		}
+		sessions, err := days.sessions(opts.Start, opts.SessionLength, configuredLocation())
+		if err != nil {
+			return usageError("%v", err)
+		}
+		return writeGfitJSON(os.Stdout, sessions)
+	case formatCSV, formatTSV:
+		var entries []WorkoutEntry
+		err := calio.ForEach(ctx, storage, rng.Since, rng.Until, func(entry WorkoutEntry) error {
+			entries = append(entries, shareable(entry, opts.IncludePrivate))
+			return nil
+		})
+		if err != nil {
+			return storageError("exporting workouts", err)
+		}
+		return writeDelimited(os.Stdout, opts.Format, entries, opts.Header)
+	case "":
+		return usageError("usage: cali export --format gfit-json|csv|tsv [--header] [--since <date>] [--until <date>] [--session-length 45m]")
+	default:
+		return usageError("unknown export format %q (use gfit-json, csv or tsv)", opts.Format)
+	}
+}
+
+func sessionLengthFromEnv() time.Duration {
+	raw := strings.TrimSpace(os.Getenv("CALI_SESSION_LENGTH"))
+	if raw == "" {
+		return defaultSessionLength
+	}
+	if length, err := time.ParseDuration(raw); err == nil && length > 0 {
+		return length
+	}
+	if minutes, err := strconv.Atoi(raw); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return defaultSessionLength
+}
+
+// gfitDays collects what an export needs of each logged date, one entry at
+// a time, so the log can be streamed rather than read whole.
+type gfitDays struct {
+	order  []string // dates in the order first seen
+	byDate map[string]*gfitDay
+}
+
+type gfitDay struct {
+	Day     string   // day letter of the date's first entry
+	Parts   []string // "Exercise Level RepsSets" per entry
+	Logged  []string // LoggedAt per entry
+	Minutes []int    // Duration per entry
+}
+
+func (g *gfitDays) add(entry WorkoutEntry) {
+	if g.byDate == nil {
+		g.byDate = map[string]*gfitDay{}
+	}
+	day, ok := g.byDate[entry.Date]
+	if !ok {
+		day = &gfitDay{Day: entry.Day}
+		g.byDate[entry.Date] = day
+		g.order = append(g.order, entry.Date)
+	}
+	day.Parts = append(day.Parts, strings.TrimSpace(fmt.Sprintf("%s %s %s", entry.Exercise, entry.Level, entry.RepsSets)))
+	day.Logged = append(day.Logged, entry.LoggedAt)
+	day.Minutes = append(day.Minutes, entry.Duration)
+}
+
+// span returns when the session of g, dated day in loc, began and ended.
+// With log times it runs from the earliest start they give (see
+// loggedSpan) to the last entry logged; without, it begins at the start
+// clock. It lasts at least the longest session minutes recorded, or length
+// when none are.
+func (g *gfitDay) span(day, startClock time.Time, length time.Duration, loc *time.Location) (begin, end time.Time) {
+	timed := false
+	longest := 0
+	for i, at := range g.Logged {
+		longest = max(longest, g.Minutes[i])
+		start, logged, ok := loggedSpan(WorkoutEntry{LoggedAt: at, Duration: g.Minutes[i]}, day, loc)
+		if !ok {
+			continue
+		}
+		if !timed || start.Before(begin) {
+			begin = start
+		}
+		if !timed || logged.After(end) {
+			end = logged
+		}
+		timed = true
+	}
+	if !timed {
+		begin = time.Date(day.Year(), day.Month(), day.Day(), startClock.Hour(), startClock.Minute(), 0, 0, loc)
+		end = begin
+	}
+	if longest > 0 {
+		length = time.Duration(longest) * time.Minute
+	}
+	return begin, maxTime(end, begin.Add(length))
+}
+
+// sessions returns one session per logged date, timed by its entries' log
+// times and session minutes where they were recorded and by start and
+// length otherwise (see gfitDay.span). Session IDs are derived from the
+// date, so re-exporting updates rather than duplicates sessions on the
+// Google Fit side.
+func (g *gfitDays) sessions(start string, length time.Duration, loc *time.Location) ([]gfitSession, error) {
+	startClock, err := time.Parse("15:04", start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session start time %q (use HH:MM)", start)
+	}
+
+	var sessions []gfitSession
+	for _, date := range g.order {
+		day, err := time.ParseInLocation(calio.DateLayout, date, loc)
+		if err != nil {
+			continue
+		}
+		begin, end := g.byDate[date].span(day, startClock, length, loc)
+
+		name := "Calisthenics"
+		if letter := g.byDate[date].Day; letter != "" {
+			name = fmt.Sprintf("Calisthenics Day %s", letter)
+		}
+
+		sessions = append(sessions, gfitSession{
+			ID:              "cali-" + date,
+			Name:            name,
+			Description:     strings.Join(g.byDate[date].Parts, "; "),
+			StartTimeMillis: strconv.FormatInt(begin.UnixMilli(), 10),
+			EndTimeMillis:   strconv.FormatInt(end.UnixMilli(), 10),
+			ActivityType:    gfitActivityCalisthenics,
+			Application:     gfitApplication{Name: "cali"},
+		})
+	}
+	return sessions, nil
+}
+
+func writeGfitJSON(w io.Writer, sessions []gfitSession) error {
+	if sessions == nil {
+		sessions = []gfitSession{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(gfitExport{Session: sessions})
+}
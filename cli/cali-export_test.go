@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportDays(entries ...WorkoutEntry) *gfitDays {
+	var days gfitDays
+	for _, entry := range entries {
+		days.add(entry)
+	}
+	return &days
+}
+
+// TestGfitJSONSchema checks the export against the Session resource of the
+// Google Fit REST API: a "session" list whose items have exactly the fields
+// below, with millisecond timestamps as strings of digits.
+func TestGfitJSONSchema(t *testing.T) {
+	days := exportDays(
+		WorkoutEntry{Date: "2026-10-14", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2"},
+		WorkoutEntry{Date: "2026-10-14", Day: "A", Exercise: "Squats", Level: "Half", RepsSets: "35x2"},
+		WorkoutEntry{Date: "2026-10-15", Exercise: "Pullups", Level: "Half", RepsSets: "8x3"},
+	)
+	sessions, err := days.sessions(defaultSessionStart, defaultSessionLength, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := writeGfitJSON(&out, sessions); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string][]map[string]any
+	if err := json.Unmarshal([]byte(out.String()), &doc); err != nil {
+		t.Fatalf("export isn't a session list: %v\n%s", err, out.String())
+	}
+	if len(doc) != 1 || len(doc["session"]) != 2 {
+		t.Fatalf("want one \"session\" list of 2, got %s", out.String())
+	}
+	fields := map[string]string{
+		"id": "string", "name": "string", "description": "string",
+		"startTimeMillis": "millis", "endTimeMillis": "millis",
+		"activityType": "number", "application": "object",
+	}
+	for i, session := range doc["session"] {
+		if len(session) != len(fields) {
+			t.Errorf("session %d has fields %v, want %v", i, session, fields)
+		}
+		for name, kind := range fields {
+			value, ok := session[name]
+			if !ok {
+				t.Errorf("session %d has no %s", i, name)
+				continue
+			}
+			switch kind {
+			case "string":
+				_, ok = value.(string)
+			case "millis":
+				var s string
+				if s, ok = value.(string); ok {
+					_, err := strconv.ParseInt(s, 10, 64)
+					ok = err == nil
+				}
+			case "number":
+				_, ok = value.(float64)
+			case "object":
+				var app map[string]any
+				app, ok = value.(map[string]any)
+				ok = ok && app["name"] == "cali"
+			}
+			if !ok {
+				t.Errorf("session %d: %s = %#v, want a %s", i, name, value, kind)
+			}
+		}
+		if session["activityType"] != float64(21) {
+			t.Errorf("session %d: activityType = %v, want 21 (calisthenics)", i, session["activityType"])
+		}
+	}
+
+	first := sessions[0]
+	if first.Name != "Calisthenics Day A" || sessions[1].Name != "Calisthenics" {
+		t.Errorf("names = %q, %q", first.Name, sessions[1].Name)
+	}
+	if want := "Pushups Full 20x2; Squats Half 35x2"; first.Description != want {
+		t.Errorf("description = %q, want %q", first.Description, want)
+	}
+	if want := strconv.FormatInt(time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC).UnixMilli(), 10); first.StartTimeMillis != want {
+		t.Errorf("start = %s, want %s", first.StartTimeMillis, want)
+	}
+}
+
+func TestGfitSessionIDsAreStable(t *testing.T) {
+	entry := WorkoutEntry{Date: "2026-10-14", Exercise: "Pushups", Level: "Full", RepsSets: "20x2"}
+	once, err := exportDays(entry).sessions("18:00", time.Hour, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	later := WorkoutEntry{Date: "2026-10-13", Exercise: "Squats", Level: "Full", RepsSets: "30x2"}
+	again, err := exportDays(later, entry).sessions("07:30", 30*time.Minute, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if once[0].ID != "cali-2026-10-14" || again[1].ID != once[0].ID {
+		t.Errorf("IDs = %q then %q, want cali-2026-10-14 both times", once[0].ID, again[1].ID)
+	}
+}
+
+func TestGfitDefaultSessionLength(t *testing.T) {
+	t.Setenv("CALI_SESSION_LENGTH", "")
+	if got := sessionLengthFromEnv(); got != 45*time.Minute {
+		t.Errorf("default session length = %v, want 45m", got)
+	}
+	for value, want := range map[string]time.Duration{"1h": time.Hour, "30": 30 * time.Minute, "soon": defaultSessionLength, "-5m": defaultSessionLength} {
+		t.Setenv("CALI_SESSION_LENGTH", value)
+		if got := sessionLengthFromEnv(); got != want {
+			t.Errorf("CALI_SESSION_LENGTH=%q: length = %v, want %v", value, got, want)
+		}
+	}
+
+	sessions, err := exportDays(WorkoutEntry{Date: "2026-10-14", Exercise: "Pushups"}).sessions(defaultSessionStart, defaultSessionLength, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, _ := strconv.ParseInt(sessions[0].StartTimeMillis, 10, 64)
+	end, _ := strconv.ParseInt(sessions[0].EndTimeMillis, 10, 64)
+	if time.Duration(end-start)*time.Millisecond != 45*time.Minute {
+		t.Errorf("session lasts %v, want 45m", time.Duration(end-start)*time.Millisecond)
+	}
+}
+
+func TestGfitBadStart(t *testing.T) {
+	if _, err := exportDays().sessions("6pm", time.Hour, time.UTC); err == nil {
+		t.Error("start 6pm: no error")
+	}
+}
+
+func TestGfitEmptyExport(t *testing.T) {
+	var out strings.Builder
+	if err := writeGfitJSON(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "{\n  \"session\": []\n}" {
+		t.Errorf("empty export = %s", got)
+	}
+}
+
+// TestGfitJSONGolden exports a day logged with times and session minutes,
+// which time its session, next to days logged without them, which fall
+// back to --start and --session-length.
+func TestGfitJSONGolden(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	days := exportDays(
+		// Logged 19:10 and 19:40 CEST after 25 and 55 minutes: 18:45 to 19:40.
+		WorkoutEntry{Date: "2026-10-14", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", LoggedAt: "2026-10-14T17:10:00Z", Duration: 25},
+		WorkoutEntry{Date: "2026-10-14", Day: "A", Exercise: "Squats", Level: "Half", RepsSets: "35x2", LoggedAt: "2026-10-14T17:40:00Z", Duration: 55},
+		// Logged 07:05 CEST without minutes: from then, for the default length.
+		WorkoutEntry{Date: "2026-10-15", Day: "B", Exercise: "Pullups", Level: "Half", RepsSets: "8x3", LoggedAt: "2026-10-15T05:05:00Z"},
+		// Minutes without a log time: from --start, for those minutes.
+		WorkoutEntry{Date: "2026-10-16", Day: "C", Exercise: "Bridges", Level: "Full", RepsSets: "10x2", Duration: 30},
+		// Neither, and a log time from another day, which is ignored.
+		WorkoutEntry{Date: "2026-10-17", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2"},
+		WorkoutEntry{Date: "2026-10-18", Day: "B", Exercise: "Pullups", Level: "Half", RepsSets: "8x3", LoggedAt: "2026-10-20T10:00:00Z"},
+	)
+	sessions, err := days.sessions(defaultSessionStart, defaultSessionLength, berlin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := writeGfitJSON(&out, sessions); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "export.gfit.json", out.String())
+
+	clock := func(millis string) string {
+		ms, _ := strconv.ParseInt(millis, 10, 64)
+		return time.UnixMilli(ms).In(berlin).Format("15:04")
+	}
+	want := [][2]string{{"18:45", "19:40"}, {"07:05", "07:50"}, {"18:00", "18:30"}, {"18:00", "18:45"}, {"18:00", "18:45"}}
+	for i, session := range sessions {
+		if got := [2]string{clock(session.StartTimeMillis), clock(session.EndTimeMillis)}; got != want[i] {
+			t.Errorf("%s: %s to %s, want %s to %s", session.ID, got[0], got[1], want[i][0], want[i][1])
+		}
+	}
+}
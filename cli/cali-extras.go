@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// promptPrefix starts the settings that add questions to the log for some
+// exercises: CALI_PROMPT_KNEE asks the question whose answer is stored as
+// knee=<answer>.
+const promptPrefix = "CALI_PROMPT_"
+
+// extraPrompt is a question asked after the comment when logging one of
+// Exercises.
+type extraPrompt struct {
+	Key       string // as stored, e.g. "knee"
+	Exercises []string
+	Question  string
+	Values    []string // the answers allowed; empty for any one word
+}
+
+// extraPrompts are the configured prompts, set when cali starts.
+var extraPrompts []extraPrompt
+
+// extraAnswer is the answer to an extra prompt. It is kept at the end of
+// the comment as "key=value", a form cali grep and progress --grep match
+// exactly (see matchesText).
+type extraAnswer struct {
+	Key   string
+	Value string
+}
+
+func (a extraAnswer) String() string {
+	return a.Key + "=" + a.Value
+}
+
+// extraKey reports whether s can be the key of an answer: letters, digits,
+// '-' and '_', starting with a letter.
+func extraKey(s string) bool {
+	if s == "" || !unicode.IsLetter(rune(s[0])) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// extraValue reports whether s can be an answer: one word without '=' or
+// the characters flags use.
+func extraValue(s string) bool {
+	return s != "" && !strings.ContainsFunc(s, unicode.IsSpace) && !strings.ContainsAny(s, "=[]|")
+}
+
+// parsePrompt reads the value of a prompt setting: the exercises it is asked
+// for, the question and optionally the answers allowed, separated by ';',
+// e.g. "Squats; Knee tracking; good, ok, poor".
+func parsePrompt(name, spec string) (extraPrompt, error) {
+	key := strings.ToLower(name)
+	if !extraKey(key) {
+		return extraPrompt{}, fmt.Errorf("%q can't name an answer (use letters, digits, - and _)", name)
+	}
+	fields := strings.Split(spec, ";")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	if len(fields) < 2 || len(fields) > 3 || fields[0] == "" || fields[1] == "" {
+		return extraPrompt{}, fmt.Errorf("%q: expected <exercises>; <question>[; <allowed answers>]", spec)
+	}
+	p := extraPrompt{Key: key, Question: fields[1]}
+	for _, name := range strings.Split(fields[0], ",") {
+		exercise, ok := matchExercise(strings.TrimSpace(name))
+		if !ok {
+			return extraPrompt{}, fmt.Errorf("%q: unknown exercise %q", spec, strings.TrimSpace(name))
+		}
+		p.Exercises = append(p.Exercises, exercise)
+	}
+	if len(fields) == 3 {
+		for _, value := range strings.Split(fields[2], ",") {
+			value = strings.ToLower(strings.TrimSpace(value))
+			if !extraValue(value) {
+				return extraPrompt{}, fmt.Errorf("%q: answer %q must be one word", spec, value)
+			}
+			p.Values = append(p.Values, value)
+		}
+	}
+	return p, nil
+}
+
+// configuredPrompts returns the prompts set by CALI_PROMPT_<key> variables,
+// by key.
+func configuredPrompts(environ []string) ([]extraPrompt, error) {
+	var prompts []extraPrompt
+	for _, variable := range environ {
+		key, value, _ := strings.Cut(variable, "=")
+		name, ok := strings.CutPrefix(key, promptPrefix)
+		if !ok || name == "" || strings.TrimSpace(value) == "" {
+			continue
+		}
+		p, err := parsePrompt(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		prompts = append(prompts, p)
+	}
+	slices.SortFunc(prompts, func(a, b extraPrompt) int { return strings.Compare(a.Key, b.Key) })
+	return prompts, nil
+}
+
+// promptsFor returns the prompts asked when logging exercise.
+func promptsFor(prompts []extraPrompt, exercise string) []extraPrompt {
+	var asked []extraPrompt
+	for _, p := range prompts {
+		if slices.Contains(p.Exercises, exercise) {
+			asked = append(asked, p)
+		}
+	}
+	return asked
+}
+
+// extraList collects repeated --extra values.
+type extraList []extraAnswer
+
+func (l *extraList) String() string {
+	parts := make([]string, len(*l))
+	for i, a := range *l {
+		parts[i] = a.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+func (l *extraList) Set(value string) error {
+	key, answer, found := strings.Cut(value, "=")
+	key = strings.ToLower(strings.TrimSpace(key))
+	answer = strings.TrimSpace(answer)
+	if !found || !extraKey(key) || !extraValue(answer) {
+		return fmt.Errorf("invalid answer %q (use key=value with a one-word value, e.g. knee=good)", value)
+	}
+	*l = append(*l, extraAnswer{Key: key, Value: answer})
+	return nil
+}
+
+// checkExtras rejects answers given with --extra that none of prompts asks
+// for, or that its prompt doesn't allow; exercise, when known, names what
+// prompts were chosen for. Allowed answers are stored as configured, so
+// "Poor" is kept as "poor".
+func checkExtras(prompts []extraPrompt, exercise string, answers []extraAnswer) ([]extraAnswer, error) {
+	checked := make([]extraAnswer, 0, len(answers))
+	for _, answer := range answers {
+		i := slices.IndexFunc(prompts, func(p extraPrompt) bool { return p.Key == answer.Key })
+		switch {
+		case i < 0 && exercise != "":
+			return nil, fmt.Errorf("--extra %s: %s%s isn't asked for %s", answer, promptPrefix, strings.ToUpper(answer.Key), exercise)
+		case i < 0:
+			return nil, fmt.Errorf("--extra %s: no %s%s is set", answer, promptPrefix, strings.ToUpper(answer.Key))
+		}
+		value, ok := allowedAnswer(prompts[i], answer.Value)
+		if !ok {
+			return nil, fmt.Errorf("--extra %s: %s", answer, msg("log.extra_invalid", strings.Join(prompts[i].Values, ", ")))
+		}
+		checked = append(checked, extraAnswer{Key: answer.Key, Value: value})
+	}
+	return checked, nil
+}
+
+// allowedAnswer returns input as p stores it, and whether p allows it.
+func allowedAnswer(p extraPrompt, input string) (string, bool) {
+	if len(p.Values) == 0 {
+		return input, extraValue(input)
+	}
+	value := strings.ToLower(input)
+	return value, slices.Contains(p.Values, value)
+}
+
+// askExtras asks the prompts of exercise that given doesn't answer, until
+// each gets an allowed answer or Enter skips it. It returns the answers of
+// given that apply to exercise, followed by those typed; the others are
+// dropped with a warning, as the exercise is only chosen after --extra was
+// checked (see checkExtras).
+func askExtras(reader *bufio.Reader, prompts []extraPrompt, exercise string, given []extraAnswer) ([]extraAnswer, error) {
+	asked := promptsFor(prompts, exercise)
+	var answers []extraAnswer
+	for _, answer := range given {
+		if slices.ContainsFunc(asked, func(p extraPrompt) bool { return p.Key == answer.Key }) {
+			answers = append(answers, answer)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: --extra %s isn't asked for %s; not saved\n", answer, exercise)
+		}
+	}
+	for _, p := range asked {
+		if slices.ContainsFunc(given, func(a extraAnswer) bool { return a.Key == p.Key }) {
+			continue
+		}
+		for {
+			if len(p.Values) > 0 {
+				prompt(msg("log.extra_choice", p.Question, strings.Join(p.Values, "/")))
+			} else {
+				prompt(msg("log.extra_prompt", p.Question))
+			}
+			input, err := reader.ReadString('\n')
+			if err != nil && input == "" {
+				return nil, inputClosed()
+			}
+			input = strings.TrimSpace(input)
+			if input == "" {
+				break
+			}
+			if value, ok := allowedAnswer(p, input); ok {
+				answers = append(answers, extraAnswer{Key: p.Key, Value: value})
+				break
+			}
+			if len(p.Values) > 0 {
+				promptln(msg("log.extra_invalid", strings.Join(p.Values, ", ")))
+			} else {
+				promptln(msg("log.extra_one_word"))
+			}
+		}
+	}
+	return answers, nil
+}
+
+// addExtras appends answers to comment as "key=value" words.
+func addExtras(comment string, answers []extraAnswer) string {
+	parts := []string{}
+	if comment = strings.TrimSpace(comment); comment != "" {
+		parts = append(parts, comment)
+	}
+	for _, answer := range answers {
+		parts = append(parts, answer.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// commentExtras returns the "key=value" words of comment.
+func commentExtras(comment string) []extraAnswer {
+	var answers []extraAnswer
+	for _, word := range strings.Fields(comment) {
+		key, value, found := strings.Cut(word, "=")
+		if found && extraKey(key) && extraValue(value) {
+			answers = append(answers, extraAnswer{Key: strings.ToLower(key), Value: value})
+		}
+	}
+	return answers
+}
@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// kneeSpec is a physio's CALI_PROMPT_KNEE, and kneePrompt what it reads as.
+const kneeSpec = "Squats, bridges; Knee tracking; Good, ok, poor"
+
+var kneePrompt = extraPrompt{Key: "knee", Exercises: []string{"Squats", "Bridges"}, Question: "Knee tracking", Values: []string{"good", "ok", "poor"}}
+
+func TestParsePrompt(t *testing.T) {
+	if got, err := parsePrompt("KNEE", kneeSpec); err != nil || !equalPrompts(got, kneePrompt) {
+		t.Errorf("parsePrompt(KNEE) = %+v, %v", got, err)
+	}
+	if got, err := parsePrompt("Grip_2", " pullups ; How was the grip? "); err != nil || got.Key != "grip_2" || got.Question != "How was the grip?" || got.Values != nil {
+		t.Errorf("parsePrompt(Grip_2) = %+v, %v", got, err)
+	}
+	for _, tt := range []struct{ name, spec, err string }{
+		{"2KNEE", kneeSpec, "can't name an answer"},
+		{"KNEE.L", kneeSpec, "can't name an answer"},
+		{"KNEE", "Squats", "expected <exercises>; <question>"},
+		{"KNEE", "; Knee tracking", "expected <exercises>; <question>"},
+		{"KNEE", "Squats;", "expected <exercises>; <question>"},
+		{"KNEE", "Squats; Knee; good; extra", "expected <exercises>; <question>"},
+		{"KNEE", "Squats, Curls; Knee tracking", `unknown exercise "Curls"`},
+		{"KNEE", "Squats; Knee tracking; good, so so", `answer "so so" must be one word`},
+		{"KNEE", "Squats; Knee tracking; good, , poor", `answer "" must be one word`},
+		{"KNEE", "Squats; Knee tracking; good, a=b", `answer "a=b" must be one word`},
+	} {
+		if _, err := parsePrompt(tt.name, tt.spec); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("parsePrompt(%s, %q) = %v, want %q", tt.name, tt.spec, err, tt.err)
+		}
+	}
+}
+
+func equalPrompts(a, b extraPrompt) bool {
+	return a.Key == b.Key && a.Question == b.Question && slices.Equal(a.Exercises, b.Exercises) && slices.Equal(a.Values, b.Values)
+}
+
+// TestConfiguredPrompts reads prompts from the environment, sorted by key,
+// and from the config file, where the shell's settings win.
+func TestConfiguredPrompts(t *testing.T) {
+	prompts, err := configuredPrompts([]string{
+		"CALI_PROMPT_KNEE=" + kneeSpec,
+		"HOME=/home/someone",
+		"CALI_PROMPT_GRIP=Pullups; Grip",
+		"CALI_PROMPT_=Squats; Nothing",
+		"CALI_PROMPT_OFF= ",
+	})
+	if err != nil || len(prompts) != 2 || prompts[0].Key != "grip" || !equalPrompts(prompts[1], kneePrompt) {
+		t.Errorf("configuredPrompts = %+v, %v", prompts, err)
+	}
+	if _, err := configuredPrompts([]string{"CALI_PROMPT_KNEE=Squats"}); err == nil || !strings.HasPrefix(err.Error(), "invalid CALI_PROMPT_KNEE: ") {
+		t.Errorf("an invalid prompt gave %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.env")
+	if err := os.WriteFile(path, []byte("CALI_PROMPT_KNEE = "+kneeSpec+"\nCALI_PROMPT_GRIP=Pullups; From the file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"CALI_PROMPT_GRIP": "Pullups; From the shell"}
+	err = loadConfigFile(path, func(name string) string { return env[name] }, func(name, value string) error {
+		env[name] = value
+		return nil
+	})
+	if err != nil || env["CALI_PROMPT_KNEE"] != kneeSpec || env["CALI_PROMPT_GRIP"] != "Pullups; From the shell" {
+		t.Errorf("the config file set %q, %v", env, err)
+	}
+}
+
+func TestPromptsFor(t *testing.T) {
+	grip := extraPrompt{Key: "grip", Exercises: []string{"Pullups", "Squats"}, Question: "Grip"}
+	prompts := []extraPrompt{grip, kneePrompt}
+	if got := promptsFor(prompts, "Squats"); len(got) != 2 {
+		t.Errorf("Squats is asked %+v", got)
+	}
+	if got := promptsFor(prompts, "Bridges"); len(got) != 1 || got[0].Key != "knee" {
+		t.Errorf("Bridges is asked %+v", got)
+	}
+	if got := promptsFor(prompts, "Pushups"); got != nil {
+		t.Errorf("Pushups is asked %+v", got)
+	}
+}
+
+func TestExtraListSet(t *testing.T) {
+	var l extraList
+	for _, value := range []string{"knee=poor", " Grip = Weak "} {
+		if err := l.Set(value); err != nil {
+			t.Errorf("Set(%q) = %v", value, err)
+		}
+	}
+	if l.String() != "knee=poor grip=Weak" {
+		t.Errorf("--extra holds %q", l.String())
+	}
+	for _, value := range []string{"knee", "=poor", "knee=", "knee=very poor", "knee=a=b", "1knee=poor"} {
+		if err := l.Set(value); err == nil {
+			t.Errorf("Set(%q) was accepted", value)
+		}
+	}
+}
+
+func TestCheckExtras(t *testing.T) {
+	grip := extraPrompt{Key: "grip", Exercises: []string{"Pullups"}, Question: "Grip"}
+	prompts := []extraPrompt{grip, kneePrompt}
+	got, err := checkExtras(prompts, "", []extraAnswer{{"knee", "Poor"}, {"grip", "Weak"}})
+	if err != nil || !slices.Equal(got, []extraAnswer{{"knee", "poor"}, {"grip", "Weak"}}) {
+		t.Errorf("checkExtras = %v, %v", got, err)
+	}
+	for _, tt := range []struct {
+		exercise string
+		answer   extraAnswer
+		err      string
+	}{
+		{"", extraAnswer{"knee", "bad"}, "--extra knee=bad: " + msg("log.extra_invalid", "good, ok, poor")},
+		{"", extraAnswer{"hip", "ok"}, "--extra hip=ok: no CALI_PROMPT_HIP is set"},
+		{"Pushups", extraAnswer{"knee", "ok"}, "--extra knee=ok: CALI_PROMPT_KNEE isn't asked for Pushups"},
+	} {
+		asked := prompts
+		if tt.exercise != "" {
+			asked = promptsFor(prompts, tt.exercise)
+		}
+		if _, err := checkExtras(asked, tt.exercise, []extraAnswer{tt.answer}); err == nil || err.Error() != tt.err {
+			t.Errorf("checkExtras(%s, %s) = %v, want %q", tt.exercise, tt.answer, err, tt.err)
+		}
+	}
+}
+
+// TestAskExtras answers the prompts of an exercise: wrong answers are asked
+// again, Enter skips, --extra answers ahead and a closed input stops.
+func TestAskExtras(t *testing.T) {
+	grip := extraPrompt{Key: "grip", Exercises: []string{"Squats"}, Question: "Grip"}
+	prompts := []extraPrompt{grip, kneePrompt}
+	ask := func(input string, given ...extraAnswer) ([]extraAnswer, string, error) {
+		var answers []extraAnswer
+		var err error
+		out := captureOutput(t, &os.Stdout, func() {
+			answers, err = askExtras(bufio.NewReader(strings.NewReader(input)), prompts, "Squats", given)
+		})
+		return answers, out, err
+	}
+
+	answers, out, err := ask("two words\nfirm\nbad\nPOOR\n")
+	if err != nil || !slices.Equal(answers, []extraAnswer{{"grip", "firm"}, {"knee", "poor"}}) {
+		t.Errorf("askExtras = %v, %v", answers, err)
+	}
+	want := msg("log.extra_prompt", "Grip") + msg("log.extra_one_word") + "\n" + msg("log.extra_prompt", "Grip") +
+		msg("log.extra_choice", "Knee tracking", "good/ok/poor") + msg("log.extra_invalid", "good, ok, poor") + "\n" +
+		msg("log.extra_choice", "Knee tracking", "good/ok/poor")
+	if out != want {
+		t.Errorf("askExtras printed %q, want %q", out, want)
+	}
+
+	if answers, _, err := ask("\n\n"); err != nil || answers != nil {
+		t.Errorf("skipping both = %v, %v", answers, err)
+	}
+	stderr := captureOutput(t, &os.Stderr, func() {
+		answers, out, err = ask("\n", extraAnswer{"knee", "ok"}, extraAnswer{"hip", "ok"})
+	})
+	if err != nil || !slices.Equal(answers, []extraAnswer{{"knee", "ok"}}) || strings.Contains(out, "Knee") {
+		t.Errorf("with --extra knee=ok: %v, %v, asked %q", answers, err, out)
+	}
+	if !strings.Contains(stderr, "--extra hip=ok isn't asked for Squats") {
+		t.Errorf("an answer for another exercise warned %q", stderr)
+	}
+	if _, _, err := ask("firm\n"); err == nil {
+		t.Error("a closed input gave no error")
+	}
+}
+
+func TestCommentExtras(t *testing.T) {
+	answers := []extraAnswer{{"knee", "poor"}, {"grip", "ok"}}
+	for _, tt := range []struct{ comment, want string }{
+		{"", "knee=poor grip=ok"},
+		{"  sore  ", "sore knee=poor grip=ok"},
+	} {
+		if got := addExtras(tt.comment, answers); got != tt.want {
+			t.Errorf("addExtras(%q) = %q, want %q", tt.comment, got, tt.want)
+		}
+	}
+	if got := addExtras("sore", nil); got != "sore" {
+		t.Errorf("addExtras without answers = %q", got)
+	}
+	got := commentExtras("sore Knee=poor x=y=z 2=3 grip=ok, a = b")
+	if !slices.Equal(got, []extraAnswer{{"knee", "poor"}, {"grip", "ok,"}}) {
+		t.Errorf("commentExtras = %v", got)
+	}
+}
+
+func TestMatchesExtras(t *testing.T) {
+	tests := []struct {
+		comment, filter string
+		want            bool
+	}{
+		{"sore knee=poor", "knee=poor", true},
+		{"sore KNEE=Poor", " knee=poor ", true},
+		{"knee=okay", "knee=ok", false},
+		{"knee=ok", "knee=okay", false},
+		{"hip=poor", "knee=poor", false},
+		{"sore knee=poor", "knee=po", false},
+		{"sore knee=poor", "sore knee", true},
+	}
+	for _, tt := range tests {
+		if got := matchesText(tt.comment, tt.filter); got != tt.want {
+			t.Errorf("matchesText(%q, %q) = %v, want %v", tt.comment, tt.filter, got, tt.want)
+		}
+	}
+}
+
+// TestExtrasCommand answers a prompt with --extra on cali log and cali q,
+// checks a wrong answer and one for another exercise are refused, and finds
+// the answers with cali grep.
+func TestExtrasCommand(t *testing.T) {
+	storage := pipedLog(t)
+	withGoalOverrides(t, nil)
+	t.Setenv("CALI_PROMPT_KNEE", kneeSpec)
+	if _, stderr, code := runCLI(t, "", "log", "--exercise", "squats", "--level", "full", "--reps", "20x2", "--comment", "sore", "--extra", "knee=Poor"); code != 0 {
+		t.Fatalf("cali log --extra knee=Poor exited %d: %s", code, stderr)
+	}
+	for _, args := range [][]string{
+		{"q", "--yes", "--extra", "knee=okay", "squats full 22x2"},
+		{"q", "--yes", "--extra", "knee=ok", "pushups full 20x2"},
+		{"log", "--exercise", "bridges", "--level", "short", "--reps", "20x2", "--extra", "knee=bad"},
+	} {
+		if _, _, code := runCLI(t, "", args...); code != exitUsage {
+			t.Errorf("cali %s exited %d", strings.Join(args, " "), code)
+		}
+	}
+	if entries := logged(t, storage); len(entries) != 1 || entries[0].Comment != "sore knee=poor" {
+		t.Fatalf("the log holds %+v", entries)
+	}
+	if _, stderr, code := runCLI(t, "", "q", "--yes", "--extra", "knee=ok", "squats full 22x2 better"); code != 0 {
+		t.Fatalf("cali q --extra knee=ok exited %d: %s", code, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 2 || entries[1].Comment != "better knee=ok" {
+		t.Fatalf("the log holds %+v", entries)
+	}
+
+	stdout, _, code := runCLI(t, "", "grep", "knee=poor")
+	if code != 0 || !strings.Contains(stdout, "sore knee=poor") || strings.Contains(stdout, "knee=ok") {
+		t.Errorf("cali grep knee=poor exited %d:\n%s", code, stdout)
+	}
+	if _, _, code := runCLI(t, "", "--fail-empty", "grep", "knee=good"); code != exitNotFound {
+		t.Errorf("cali grep knee=good exited %d", code)
+	}
+	if _, _, code := runCLI(t, "", "grep"); code != exitUsage {
+		t.Errorf("cali grep without text exited %d", code)
+	}
+
+	t.Setenv("CALI_PROMPT_KNEE", "Squats")
+	if _, stderr, code := runCLI(t, "", "history"); code != exitUsage || !strings.Contains(stderr, "invalid CALI_PROMPT_KNEE") {
+		t.Errorf("an invalid prompt exited %d: %s", code, stderr)
+	}
+}
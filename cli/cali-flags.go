@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// entryFlag is a structured note such as pain or an injury. Flags are stored
+// at the start of the comment as "[kind: note]" so both backends keep the
+// same columns.
+type entryFlag struct {
+	Kind string
+	Note string
+}
+
+func (f entryFlag) String() string {
+	return fmt.Sprintf("[%s: %s]", f.Kind, f.Note)
+}
+
+// parseFlagSpec parses the --flag value "kind:note", e.g. pain:"left shoulder".
+func parseFlagSpec(spec string) (entryFlag, error) {
+	kind, note, found := strings.Cut(spec, ":")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	note = strings.TrimSpace(note)
+	if !found || kind == "" || note == "" || strings.ContainsAny(kind, " []") || strings.ContainsAny(note, "[]|") {
+		return entryFlag{}, fmt.Errorf("invalid flag %q (use kind:note, e.g. pain:\"left shoulder\")", spec)
+	}
+	return entryFlag{Kind: kind, Note: note}, nil
+}
+
+// flagList collects repeated --flag values.
+type flagList []entryFlag
+
+func (l *flagList) String() string {
+	parts := make([]string, len(*l))
+	for i, f := range *l {
+		parts[i] = f.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+func (l *flagList) Set(value string) error {
+	f, err := parseFlagSpec(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, f)
+	return nil
+}
+
+// splitCommentFlags separates leading "[kind: note]" flags from the rest of
+// the comment. Colons elsewhere in the comment are left alone.
+func splitCommentFlags(comment string) ([]entryFlag, string) {
+	var flags []entryFlag
+	rest := strings.TrimSpace(comment)
+	for strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			break
+		}
+		kind, note, found := strings.Cut(rest[1:end], ":")
+		kind = strings.TrimSpace(kind)
+		if !found || kind == "" || strings.Contains(kind, " ") {
+			break
+		}
+		flags = append(flags, entryFlag{Kind: strings.ToLower(kind), Note: strings.TrimSpace(note)})
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return flags, rest
+}
+
+func addCommentFlags(comment string, flags []entryFlag) string {
+	if len(flags) == 0 {
+		return comment
+	}
+	existing, rest := splitCommentFlags(comment)
+	parts := make([]string, 0, len(existing)+len(flags)+1)
+	for _, f := range append(existing, flags...) {
+		parts = append(parts, f.String())
+	}
+	if rest != "" {
+		parts = append(parts, rest)
+	}
+	return strings.Join(parts, " ")
+}
+
+func hasFlagKind(entry WorkoutEntry, kind string) bool {
+	flags, _ := splitCommentFlags(entry.Comment)
+	for _, f := range flags {
+		if strings.EqualFold(f.Kind, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterByFlag(entries []WorkoutEntry, kind string) []WorkoutEntry {
+	var flagged []WorkoutEntry
+	for _, entry := range entries {
+		if hasFlagKind(entry, kind) {
+			flagged = append(flagged, entry)
+		}
+	}
+	return flagged
+}
+
+// printFlagNotes warns about flags on the most recent entry for exercise.
+func printFlagNotes(ctx context.Context, storage Storage, exercise string) {
+	var latest WorkoutEntry
+	found := false
+	err := calio.ForEach(ctx, storage, "", "", func(entry WorkoutEntry) error {
+		if sameName(entry.Exercise, exercise) {
+			latest, found = entry, true
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read history for flagged notes: %v\n", err)
+		return
+	}
+	if !found {
+		return
+	}
+	flags, _ := splitCommentFlags(latest.Comment)
+	for _, f := range flags {
+		prompt(msg("log.flag_note", displayDate(latest.Date), f.Note, f.Kind, latest.Exercise, latest.Level))
+	}
+}
+
+func searchFlagged(ctx context.Context, storage Storage, opts searchOptions, input string, rng dateRange) error {
+	kind := opts.Flag
+	var entries []WorkoutEntry
+	var err error
+	if input != "" {
+		dateStr, dateErr := userDate(input)
+		if dateErr != nil {
+			return dateErr
+		}
+		entries, err = storage.SearchByDate(ctx, dateStr)
+		entries = filterByFlag(filterByRange(entries, rng), kind)
+	} else {
+		// The whole log may be searched, so only the matches are kept.
+		err = calio.ForEach(ctx, storage, rng.Since, rng.Until, func(entry WorkoutEntry) error {
+			if hasFlagKind(entry, kind) {
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return storageError("searching workouts", err)
+	}
+
+	if opts.Format != "" {
+		return printDelimited(opts.Format, entries, opts.Header)
+	}
+	if len(entries) == 0 {
+		fmt.Print(msg("flagged.empty", kind))
+		return errNoResults
+	}
+
+	say(msg("flagged.header", kind))
+	sayln(separatorRule())
+	for _, entry := range entries {
+		fmt.Print(msg("list.row",
+			displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level, workText(entry), entry.Comment))
+	}
+	sayln(separatorRule())
+	say(msg("list.total", len(entries)))
+	return nil
+}
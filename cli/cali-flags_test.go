@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseFlagSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want entryFlag
+		ok   bool
+	}{
+		{"pain:left shoulder", entryFlag{Kind: "pain", Note: "left shoulder"}, true},
+		{" Injury : wrist ", entryFlag{Kind: "injury", Note: "wrist"}, true},
+		{"pain", entryFlag{}, false},
+		{"pain:", entryFlag{}, false},
+		{":wrist", entryFlag{}, false},
+		{"sore back:wrist", entryFlag{}, false},
+		{"pain:wrist | elbow", entryFlag{}, false},
+		{"pain:[wrist]", entryFlag{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseFlagSpec(tt.spec)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseFlagSpec(%q) = %+v, %v, want %+v", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestCommentFlags(t *testing.T) {
+	pain := entryFlag{Kind: "pain", Note: "left shoulder"}
+	comment := addCommentFlags("felt ok: mostly", []entryFlag{pain})
+	if comment != "[pain: left shoulder] felt ok: mostly" {
+		t.Fatalf("addCommentFlags = %q", comment)
+	}
+	// A second flag goes after the first, before the text.
+	comment = addCommentFlags(comment, []entryFlag{{Kind: "injury", Note: "wrist"}})
+	if comment != "[pain: left shoulder] [injury: wrist] felt ok: mostly" {
+		t.Fatalf("addCommentFlags = %q", comment)
+	}
+
+	flags, rest := splitCommentFlags(comment)
+	if !slices.Equal(flags, []entryFlag{pain, {Kind: "injury", Note: "wrist"}}) || rest != "felt ok: mostly" {
+		t.Errorf("splitCommentFlags = %+v, %q", flags, rest)
+	}
+	// Brackets that aren't a flag stay in the comment.
+	if flags, rest := splitCommentFlags("[two words: x] text"); len(flags) != 0 || rest != "[two words: x] text" {
+		t.Errorf("splitCommentFlags took %+v from a comment, left %q", flags, rest)
+	}
+	if !hasFlagKind(WorkoutEntry{Comment: comment}, "PAIN") || hasFlagKind(WorkoutEntry{Comment: "pain: none"}, "pain") {
+		t.Error("hasFlagKind doesn't go by the leading flags alone")
+	}
+}
+
+// TestSearchFlagged logs a flagged and a plain entry today and searches by
+// flag.
+func TestSearchFlagged(t *testing.T) {
+	storage := pipedLog(t)
+	for _, args := range [][]string{
+		{"log", "--exercise", "pushups", "--level", "full", "--reps", "10x2", "--flag", "pain:left shoulder"},
+		{"log", "--exercise", "squats", "--level", "full", "--reps", "20x2"},
+	} {
+		if _, stderr, code := runCLI(t, "", args...); code != 0 {
+			t.Fatalf("cali %q exited %d: %s", args, code, stderr)
+		}
+	}
+	if entries := logged(t, storage); len(entries) != 2 || !strings.HasPrefix(entries[0].Comment, "[pain: left shoulder]") {
+		t.Fatalf("saved %+v", entries)
+	}
+
+	stdout, stderr, code := runCLI(t, "", "-s", "--flag", "pain", "--format", "csv")
+	if code != 0 {
+		t.Fatalf("cali -s --flag pain exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Pushups") || strings.Contains(stdout, "Squats") {
+		t.Errorf("cali -s --flag pain printed:\n%s", stdout)
+	}
+	if stdout, _, _ := runCLI(t, "", "-s", "--flag", "injury"); !strings.Contains(stdout, "injury") {
+		t.Errorf("cali -s --flag injury with no match printed:\n%s", stdout)
+	}
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// allowFuture is set with the global --allow-future flag, to store entries
+// dated more than a day ahead anyway (see calio.FutureDated).
+var allowFuture bool
+
+// checkFutureDates refuses entries dated more than a day after now, as
+// every analytic would ignore them and the date is usually a typo or a
+// wrong clock, unless --allow-future was given. Commands writing new
+// entries call it before storing them; restore, sync and retry-unsaved
+// store what was stored or checked before, and don't.
+func checkFutureDates(entries []WorkoutEntry, now time.Time) error {
+	if allowFuture {
+		return nil
+	}
+	future := calio.FutureEntries(entries, now)
+	if len(future) == 0 {
+		return nil
+	}
+	return usageError("%s", msg("error.future_date", len(future), future[0].Date, now.Format(calio.DateLayout)))
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// withAllowFuture sets --allow-future for the test.
+func withAllowFuture(t *testing.T, allow bool) {
+	t.Helper()
+	saved := allowFuture
+	allowFuture = allow
+	t.Cleanup(func() { allowFuture = saved })
+}
+
+func TestCheckFutureDates(t *testing.T) {
+	withAllowFuture(t, false)
+	// 23:30 UTC on the 18th is already the 19th east of Greenwich, and
+	// still the 18th in the far west.
+	instant := time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC)
+	tests := []struct {
+		zone   string
+		date   string
+		future bool
+	}{
+		{"UTC", "2026-10-18", false},
+		{"UTC", "2026-10-19", false}, // tomorrow is fine
+		{"UTC", "2026-10-20", true},
+		{"UTC", "2030-01-01", true},
+		{"UTC", "2025-10-20", false},
+		{"UTC", "someday", false}, // not a date, so not future-dated
+		{"Pacific/Kiritimati", "2026-10-20", false},
+		{"Pacific/Kiritimati", "2026-10-21", true},
+		{"Etc/GMT+12", "2026-10-19", false},
+		{"Etc/GMT+12", "2026-10-20", true},
+	}
+	for _, tt := range tests {
+		loc, err := time.LoadLocation(tt.zone)
+		if err != nil {
+			t.Skip(err)
+		}
+		err = checkFutureDates([]WorkoutEntry{{Date: "2026-10-18"}, {Date: tt.date}}, instant.In(loc))
+		if (err != nil) != tt.future {
+			t.Errorf("%s in %s: checkFutureDates = %v, want an error: %v", tt.date, tt.zone, err, tt.future)
+		}
+		if err != nil && (!strings.Contains(err.Error(), tt.date) || !strings.Contains(err.Error(), "--allow-future")) {
+			t.Errorf("%s in %s: error %q doesn't name the date and --allow-future", tt.date, tt.zone, err)
+		}
+	}
+
+	withAllowFuture(t, true)
+	if err := checkFutureDates([]WorkoutEntry{{Date: "2030-01-01"}}, instant); err != nil {
+		t.Errorf("with --allow-future: %v", err)
+	}
+}
+
+// TestImportFutureDates imports a file holding a row dated two days ahead
+// in CALI_TZ: refused as a whole, then stored with --allow-future.
+func TestImportFutureDates(t *testing.T) {
+	storage := pipedLog(t)
+	t.Setenv("CALI_TZ", "Pacific/Kiritimati")
+	withAllowFuture(t, false)
+	now := currentTime()
+	rows := filepath.Join(t.TempDir(), "rows.csv")
+	text := fmt.Sprintf("%s,A,Pushups,Full,10x2\n%s,B,Squats,Full,10x2\n",
+		now.Format(calio.DateLayout), now.AddDate(0, 0, 2).Format(calio.DateLayout))
+	if err := os.WriteFile(rows, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"import", rows}, {"import", "--dry-run", rows}} {
+		_, stderr, code := runCLI(t, "", args...)
+		if code != exitUsage || !strings.Contains(stderr, "--allow-future") {
+			t.Errorf("cali %q exited %d: %s", args, code, stderr)
+		}
+	}
+	if entries := logged(t, storage); len(entries) != 0 {
+		t.Fatalf("saved %+v", entries)
+	}
+
+	for _, args := range [][]string{{"import", "--allow-future", rows}, {"--allow-future", "import", rows}} {
+		_, stderr, code := runCLI(t, "", args...)
+		if code != 0 {
+			t.Fatalf("cali %q exited %d: %s", args, code, stderr)
+		}
+	}
+	if entries := logged(t, storage); len(entries) != 4 {
+		t.Errorf("saved %d entries, want both rows twice", len(entries))
+	}
+}
+
+func TestServeFutureDates(t *testing.T) {
+	withAllowFuture(t, false)
+	server, storage := newTestServer(t)
+	tomorrow := currentTime().AddDate(0, 0, 1).Format(calio.DateLayout)
+	later := currentTime().AddDate(0, 0, 2).Format(calio.DateLayout)
+	entry := `{"Date": %q, "Exercise": "pushups", "Level": "full", "RepsSets": "10x2"}`
+
+	if status, _ := postEntries(t, server.URL, fmt.Sprintf(entry, tomorrow)); status != http.StatusCreated {
+		t.Errorf("POST dated tomorrow = %d, want 201", status)
+	}
+	if status, _ := postEntries(t, server.URL, "["+fmt.Sprintf(entry, tomorrow)+","+fmt.Sprintf(entry, later)+"]"); status != http.StatusBadRequest {
+		t.Errorf("POST dated the day after tomorrow = %d, want 400", status)
+	}
+	if entries := logged(t, storage); len(entries) != 1 {
+		t.Errorf("stored %d entries, want only tomorrow's", len(entries))
+	}
+
+	withAllowFuture(t, true)
+	if status, _ := postEntries(t, server.URL, fmt.Sprintf(entry, later)); status != http.StatusCreated {
+		t.Errorf("POST dated the day after tomorrow with --allow-future = %d, want 201", status)
+	}
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// goalMark follows a goal the user set with cali goal set.
+const goalMark = "*"
+
+// loadedGoalOverrides holds the overrides read by loadGoalOverrides, by
+// exercise and level.
+var loadedGoalOverrides map[string]map[string]string
+
+// loadGoalOverrides reads storage's goal overrides so resolveGoal prefers
+// them over the built-in goals. Without them, or when they can't be read,
+// the built-in goals apply.
+func loadGoalOverrides(ctx context.Context, storage Storage) {
+	loadedGoalOverrides = nil
+	goals, ok := storage.(calio.GoalStore)
+	if !ok {
+		return
+	}
+	overrides, err := goals.GoalOverrides(ctx)
+	if errors.Is(err, calio.ErrNoGoalStore) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read goal overrides, using the built-in goals: %v\n", err)
+		return
+	}
+	loadedGoalOverrides = map[string]map[string]string{}
+	for _, override := range overrides {
+		if loadedGoalOverrides[override.Exercise] == nil {
+			loadedGoalOverrides[override.Exercise] = map[string]string{}
+		}
+		loadedGoalOverrides[override.Exercise][override.Level] = override.Goal
+	}
+}
+
+// goalOverride returns the user's goal for a level, if they set one.
+func goalOverride(exercise, level string) (string, bool) {
+	goal, ok := loadedGoalOverrides[exercise][level]
+	return goal, ok
+}
+
+// hasGoalOverrides reports whether any of exercises has an overridden level.
+func hasGoalOverrides(exercises ...string) bool {
+	for _, exercise := range exercises {
+		if len(loadedGoalOverrides[exercise]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// tierSummary renders a level's standards, marking a progression goal the
+// user set.
+func tierSummary(exercise, level string) string {
+	tiers := resolveTiers(exercise, level)
+	if _, ok := goalOverride(exercise, level); ok {
+		tiers.Progression += goalMark
+	}
+	return tiers.String()
+}
+
+// validateGoal rejects goals the reps parser can't compare logged sets
+// against, interval protocols, which have no standards, and a hold for a
+// level counted in reps or the other way round, which would change what
+// logging asks for.
+func validateGoal(exercise, level, goal string) error {
+	parsed, ok := parseRepsSets(goal)
+	if !ok || parsed.interval() {
+		return errors.New(msg("goal.invalid", goal))
+	}
+	builtIn, _ := calio.Goal(exercise, level)
+	if standard, ok := parseRepsSets(builtIn); ok && standard.timed() != parsed.timed() {
+		return errors.New(msg("goal.kind_mismatch", goal, exercise, level, builtIn))
+	}
+	return nil
+}
+
+func runGoal(ctx context.Context, args []string) error {
+	const usage = `usage: cali goal set <exercise> <level> <goal> | cali goal unset <exercise> <level> | cali goal list [exercise]`
+	if len(args) == 0 {
+		return usageError(usage)
+	}
+	action, args := args[0], args[1:]
+	switch action {
+	case "set":
+		if len(args) < 3 {
+			return usageError(usage)
+		}
+	case "unset":
+		if len(args) < 2 {
+			return usageError(usage)
+		}
+	case "list":
+	default:
+		return usageError(usage)
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	goals, ok := storage.(calio.GoalStore)
+	if !ok {
+		return storageError("reading goal overrides", calio.ErrNoGoalStore)
+	}
+	if action == "list" {
+		return listGoals(ctx, storage, args)
+	}
+
+	var goal string
+	if action == "set" {
+		goal = normalizeRepsSets(args[len(args)-1])
+		args = args[:len(args)-1]
+	}
+	exercise, level, err := parseTutorialArgs(args)
+	if err != nil {
+		return err
+	}
+	if level == "" {
+		return usageError(usage)
+	}
+	if action == "set" {
+		if err := validateGoal(exercise, level, goal); err != nil {
+			return usageError("%s", err)
+		}
+	}
+
+	loadGoalOverrides(ctx, storage)
+	current, overridden := goalOverride(exercise, level)
+	if action == "unset" && !overridden {
+		sayln(msg("goal.not_set", exercise, level, resolveGoal(exercise, level)))
+		return nil
+	}
+	if action == "set" && overridden && current == goal {
+		sayln(msg("goal.unchanged", exercise, level, goal))
+		return nil
+	}
+
+	override := calio.GoalOverride{Exercise: exercise, Level: level, Goal: goal}
+	if err := goals.SetGoal(ctx, override); err != nil {
+		return storageError("saving the goal", err)
+	}
+	builtIn, _ := calio.Goal(exercise, level)
+	if action == "set" {
+		say(msg("goal.saved", exercise, level, goal, builtIn))
+	} else {
+		say(msg("goal.removed", exercise, level, builtIn))
+	}
+	return nil
+}
+
+// listGoals prints the effective goal of every level with its source.
+func listGoals(ctx context.Context, storage Storage, args []string) error {
+	selected := append(calio.Exercises(), calio.MobilityExercises()...)
+	if len(args) > 0 {
+		exercise, ok := normalizeExercise(strings.Join(args, " "))
+		if !ok {
+			return usageError("%s", msg("error.unknown_exercise", strings.Join(args, " ")))
+		}
+		selected = []string{exercise}
+	}
+
+	loadGoalOverrides(ctx, storage)
+	for i, exercise := range selected {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s:\n", exercise)
+		for step, level := range calio.Levels(exercise) {
+			source := msg("goal.source_builtin")
+			if _, ok := goalOverride(exercise, level); ok {
+				builtIn, _ := calio.Goal(exercise, level)
+				source = msg("goal.source_override", builtIn)
+			}
+			fmt.Printf("  %2d. %-18s %-10s %s\n", step+1, level, resolveGoal(exercise, level), source)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+// withGoalOverrides sets the overrides resolveGoal sees for the test.
+func withGoalOverrides(t *testing.T, overrides map[string]map[string]string) {
+	saved := loadedGoalOverrides
+	loadedGoalOverrides = overrides
+	t.Cleanup(func() { loadedGoalOverrides = saved })
+}
+
+func TestResolveGoalPrecedence(t *testing.T) {
+	withGoalOverrides(t, map[string]map[string]string{"Pushups": {"Full": "15x2"}})
+	tests := []struct {
+		exercise, level, want string
+	}{
+		{"Pushups", "Full", "15x2"},
+		{"Pushups", "Half", "25x2"},
+		{"Squats", "Full", "30x2"},
+		{"Pushups", "Floating", "-"},
+	}
+	for _, tt := range tests {
+		if got := resolveGoal(tt.exercise, tt.level); got != tt.want {
+			t.Errorf("resolveGoal(%s, %s) = %q, want %q", tt.exercise, tt.level, got, tt.want)
+		}
+	}
+	if !hasGoalOverrides("Squats", "Pushups") || hasGoalOverrides("Squats") {
+		t.Error("hasGoalOverrides doesn't match the overrides")
+	}
+	if got := tierSummary("Pushups", "Full"); !strings.Contains(got, "15x2"+goalMark) {
+		t.Errorf("tierSummary = %q, want the goal marked", got)
+	}
+}
+
+func TestValidateGoal(t *testing.T) {
+	tests := []struct {
+		exercise, level, goal string
+		ok                    bool
+	}{
+		{"Pushups", "Full", "15x2", true},
+		{"Pushups", "Full", "8,7,6", true},
+		{"Handstand Push-ups", "Crow", "45s", true},
+		{"Pushups", "Full", "fifteen", false},
+		{"Pushups", "Full", "EMOM 10min @ 12", false},
+		{"Pushups", "Full", "90s", false},             // a hold for a level in reps
+		{"Handstand Push-ups", "Crow", "10x2", false}, // and reps for a hold
+	}
+	for _, tt := range tests {
+		if err := validateGoal(tt.exercise, tt.level, tt.goal); (err == nil) != tt.ok {
+			t.Errorf("validateGoal(%s, %s, %q) = %v", tt.exercise, tt.level, tt.goal, err)
+		}
+	}
+}
+
+// TestGoalCommand sets a goal, logs against it and unsets it on a local log.
+func TestGoalCommand(t *testing.T) {
+	withGoalOverrides(t, nil)
+	storage := pipedLog(t)
+
+	stdout, stderr, code := runCLI(t, "", "goal", "set", "pushups", "full", "15x2")
+	if code != 0 || !strings.Contains(stdout, "Pushups Full goal set to 15x2 (built-in 20x2)") {
+		t.Fatalf("cali goal set exited %d, printed %q %s", code, stdout, stderr)
+	}
+	if stdout, _, _ := runCLI(t, "", "goal", "set", "pushups", "full", "15x2"); !strings.Contains(stdout, "already 15x2") {
+		t.Errorf("setting the same goal again printed %q", stdout)
+	}
+	stdout, _, _ = runCLI(t, "", "goal", "list", "pushups")
+	if !strings.Contains(stdout, "Full               15x2       yours (built-in 20x2)") || !strings.Contains(stdout, "Half               25x2       built-in") {
+		t.Errorf("cali goal list printed %q", stdout)
+	}
+
+	if _, stderr, code := runCLI(t, "", "log", "--exercise", "pushups", "--level", "full", "--reps", "15x2"); code != 0 {
+		t.Fatalf("cali log exited %d: %s", code, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 1 || entries[0].Goal != "15x2" {
+		t.Errorf("logged %+v, want the goal set", entries)
+	}
+
+	for _, goal := range []string{"90s", "lots"} {
+		if _, _, code := runCLI(t, "", "goal", "set", "pushups", "full", goal); code != exitUsage {
+			t.Errorf("cali goal set pushups full %s exited %d, want %d", goal, code, exitUsage)
+		}
+	}
+
+	if stdout, _, _ := runCLI(t, "", "goal", "unset", "pushups", "full"); !strings.Contains(stdout, "back to the built-in 20x2") {
+		t.Errorf("cali goal unset printed %q", stdout)
+	}
+	if stdout, _, _ := runCLI(t, "", "goal", "unset", "pushups", "full"); !strings.Contains(stdout, "has no goal of yours") {
+		t.Errorf("unsetting again printed %q", stdout)
+	}
+	for _, args := range [][]string{nil, {"set", "pushups", "full"}, {"unset", "pushups"}, {"drop"}} {
+		if _, _, code := runCLI(t, "", append([]string{"goal"}, args...)...); code != exitUsage {
+			t.Errorf("cali goal %q exited %d, want %d", args, code, exitUsage)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// Size of the cali graph chart.
+const (
+	graphHeight     = 10 // rows of the plot area
+	graphMinColumns = 21 // narrowest plot area: room for the first and last date
+)
+
+// graphPoint is one session: the best total of a training day, in reps or
+// seconds held.
+type graphPoint struct {
+	Date  time.Time
+	Value int
+}
+
+// plotGraph draws points, oldest first, as a scatter chart width characters
+// wide: values up the y-axis from 0, sessions along the x-axis. Sessions are
+// spread over the columns, and when there are more sessions than columns
+// neighbours share a column, which shows the best of them. A goal above 0
+// is drawn as a horizontal rule of '-'. The result depends only on the
+// arguments.
+func plotGraph(points []graphPoint, goal, width int) []string {
+	if len(points) == 0 {
+		return nil
+	}
+	top := goal
+	for _, point := range points {
+		top = max(top, point.Value)
+	}
+	top = max(top, 1)
+	labelWidth := len(strconv.Itoa(top))
+	columns := max(graphMinColumns, width-labelWidth-2)
+	if len(points) < columns {
+		// Gaps of more than a few columns per session stretch sparse data
+		// into a chart that's mostly empty.
+		columns = min(columns, max(graphMinColumns, (len(points)-1)*4+1))
+	}
+
+	rowOf := func(value int) int {
+		return graphHeight - 1 - (value*(graphHeight-1)+top/2)/top
+	}
+	grid := make([][]byte, graphHeight)
+	for row := range grid {
+		grid[row] = []byte(strings.Repeat(" ", columns))
+	}
+	goalRow := -1
+	if goal > 0 {
+		goalRow = rowOf(goal)
+		copy(grid[goalRow], strings.Repeat("-", columns))
+	}
+	best := make([]int, columns)
+	for i := range best {
+		best[i] = -1
+	}
+	for i, point := range points {
+		column := 0
+		if len(points) > 1 {
+			column = i * (columns - 1) / (len(points) - 1)
+		}
+		best[column] = max(best[column], point.Value)
+	}
+	for column, value := range best {
+		if value >= 0 {
+			grid[rowOf(value)][column] = '*'
+		}
+	}
+
+	lines := make([]string, 0, graphHeight+2)
+	for row := range grid {
+		label := ""
+		switch row {
+		case 0:
+			label = strconv.Itoa(top)
+		case goalRow:
+			label = strconv.Itoa(goal)
+		case graphHeight - 1:
+			label = "0"
+		}
+		lines = append(lines, strings.TrimRight(fmt.Sprintf("%*s |%s", labelWidth, label, grid[row]), " "))
+	}
+	lines = append(lines, strings.Repeat(" ", labelWidth)+" +"+strings.Repeat("-", columns))
+
+	first := points[0].Date.Format(time.DateOnly)
+	axis := strings.Repeat(" ", labelWidth+2) + first
+	if len(points) > 1 {
+		last := points[len(points)-1].Date.Format(time.DateOnly)
+		gap := labelWidth + 2 + columns - len(axis) - len(last)
+		axis += strings.Repeat(" ", max(1, gap)) + last
+	}
+	return append(lines, axis)
+}
+
+// graphPoints returns the best total per training day of entries, which
+// should be one exercise and level, oldest first, measured like goal: reps,
+// or seconds held for timed levels. skipped counts entries whose Reps×Sets
+// can't be measured that way. Deloads and intervals are left out.
+func graphPoints(entries []WorkoutEntry, goal string) (points []graphPoint, skipped int) {
+	target, _ := parseRepsSets(goal)
+	best := map[string]int{}
+	for _, entry := range workingEntries(entries) {
+		parsed, ok := parseRepsSets(entry.RepsSets)
+		if ok && parsed.interval() {
+			continue
+		}
+		if !ok || parsed.timed() != target.timed() {
+			skipped++
+			continue
+		}
+		value := parsed.totalReps()
+		if parsed.timed() {
+			value = int(parsed.totalHold())
+		}
+		if current, seen := best[entry.Date]; !seen || value > current {
+			best[entry.Date] = value
+		}
+	}
+	for date, value := range best {
+		day, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			skipped++
+			continue
+		}
+		points = append(points, graphPoint{Date: day, Value: value})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+	return points, skipped
+}
+
+func runGraph(ctx context.Context, args []string, rng dateRange) error {
+	const usage = `usage: cali graph <exercise> <level> [--since <date>] [--until <date>]`
+	if len(args) == 0 {
+		return usageError(usage)
+	}
+	exercise, level, err := parseTutorialArgs(args)
+	if err != nil {
+		return err
+	}
+	if level == "" {
+		return usageError(usage)
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	entries, err := storage.Range(ctx, rng.Since, rng.Until)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	loadGoalOverrides(ctx, storage)
+	var matching []WorkoutEntry
+	for _, entry := range calio.WithoutFuture(entries, currentTime()) {
+		if sameName(entry.Exercise, exercise) && sameName(entry.Level, level) {
+			matching = append(matching, entry)
+		}
+	}
+
+	goal := resolveGoal(exercise, level)
+	target, _ := parseRepsSets(goal)
+	points, skipped := graphPoints(matching, goal)
+	if len(points) == 0 {
+		fmt.Println(msg("graph.empty", exercise, level))
+		return errNoResults
+	}
+
+	goalValue := target.totalReps()
+	title := msg("graph.title_reps", exercise, level)
+	if target.timed() {
+		goalValue = int(target.totalHold())
+		title = msg("graph.title_hold", exercise, level)
+	}
+	fmt.Println(title)
+	fmt.Println()
+	for _, line := range plotGraph(points, goalValue, terminalWidth()) {
+		fmt.Println(line)
+	}
+	fmt.Println()
+	if goalValue > 0 {
+		fmt.Println(msg("graph.legend_goal", len(points), goal, goalValue))
+	} else {
+		fmt.Println(msg("graph.legend", len(points)))
+	}
+	if skipped > 0 {
+		fmt.Println(msg("graph.skipped", skipped))
+	}
+	return nil
+}
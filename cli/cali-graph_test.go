@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// series returns one point a day from 2026-01-05 with the given values.
+func series(values ...int) []graphPoint {
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	points := make([]graphPoint, len(values))
+	for i, value := range values {
+		points[i] = graphPoint{Date: start.AddDate(0, 0, i), Value: value}
+	}
+	return points
+}
+
+func TestPlotGraphGolden(t *testing.T) {
+	// Three months of sessions, more than fit the width, climbing with a
+	// dip every fourth one.
+	var dense []int
+	for i := range 90 {
+		value := 20 + i/2
+		if i%4 == 3 {
+			value -= 12
+		}
+		dense = append(dense, value)
+	}
+	tests := []struct {
+		golden string
+		points []graphPoint
+		goal   int
+		width  int
+	}{
+		{"graph.dense.txt", series(dense...), 60, 60},
+		{"graph.sparse.txt", series(30, 38), 50, 80},
+		{"graph.crossing.txt", series(20, 26, 31, 35, 44, 52, 58, 55, 61), 50, 80},
+		{"graph.nogoal.txt", series(12, 15, 9), 0, 80},
+		{"graph.single.txt", series(7), 40, 80},
+	}
+	for _, tt := range tests {
+		lines := plotGraph(tt.points, tt.goal, tt.width)
+		checkGolden(t, tt.golden, strings.Join(lines, "\n")+"\n")
+		for _, line := range lines {
+			if len(line) > max(tt.width, len("99 |")+graphMinColumns) {
+				t.Errorf("%s: line %q is wider than %d", tt.golden, line, tt.width)
+			}
+		}
+	}
+	if lines := plotGraph(nil, 50, 80); lines != nil {
+		t.Errorf("plotGraph of no points = %q", lines)
+	}
+}
+
+func TestGraphPoints(t *testing.T) {
+	entries := []WorkoutEntry{
+		{Date: "2026-03-04", RepsSets: "20x2"},
+		{Date: "2026-03-02", RepsSets: "15x2"},
+		{Date: "2026-03-02", RepsSets: "18x2"}, // the best of the day
+		{Date: "2026-03-03", RepsSets: "lots"},
+		{Date: "2026-03-03", RepsSets: "30sx2"},
+		{Date: "2026-03-05", RepsSets: "EMOM 10min @ 5", Type: "interval"},
+		{Date: "2026-03-06", RepsSets: "10x2", Comment: "#deload"},
+		{Date: "March 7", RepsSets: "25x2"},
+	}
+	points, skipped := graphPoints(entries, "25x2")
+	var got []string
+	for _, point := range points {
+		got = append(got, fmt.Sprintf("%s=%d", point.Date.Format(time.DateOnly), point.Value))
+	}
+	if strings.Join(got, " ") != "2026-03-02=36 2026-03-04=40" || skipped != 3 {
+		t.Errorf("graphPoints = %v, %d skipped", got, skipped)
+	}
+
+	holds, skipped := graphPoints([]WorkoutEntry{{Date: "2026-03-03", RepsSets: "30sx2"}, {Date: "2026-03-04", RepsSets: "10x2"}}, "60sx2")
+	if len(holds) != 1 || holds[0].Value != 60 || skipped != 1 {
+		t.Errorf("graphPoints of a timed level = %+v, %d skipped", holds, skipped)
+	}
+}
+
+func TestGraphCommand(t *testing.T) {
+	storage := pipedLog(t)
+	today := currentTime()
+	day := func(daysAgo int) string { return today.AddDate(0, 0, -daysAgo).Format("2006-01-02") }
+	for i, reps := range []string{"10x2", "12x2", "garbled", "15x2", "18x2"} {
+		entry := WorkoutEntry{Date: day(40 - 10*i), Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: reps, Goal: "20x2"}
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout, stderr, code := runCLI(t, "", "graph", "pushups", "full")
+	if code != 0 || !strings.Contains(stdout, day(40)) || !strings.Contains(stdout, day(0)) || !strings.Contains(stdout, "40 |") || !strings.Contains(stdout, "1 entr") {
+		t.Errorf("cali graph exited %d, printed\n%s%s", code, stdout, stderr)
+	}
+	stdout, _, _ = runCLI(t, "", "graph", "pushups", "full", "--since", day(15))
+	if strings.Contains(stdout, day(40)) || !strings.Contains(stdout, day(10)) || strings.Contains(stdout, "entr") {
+		t.Errorf("cali graph --since printed\n%s", stdout)
+	}
+	if stdout, _, code := runCLI(t, "", "graph", "squats", "full"); code != exitOK || strings.Contains(stdout, "|") {
+		t.Errorf("cali graph without sessions exited %d, printed\n%s", code, stdout)
+	}
+	if _, _, code := runCLI(t, "", "--fail-empty", "graph", "squats", "full"); code != exitNotFound {
+		t.Errorf("cali --fail-empty graph without sessions exited %d, want %d", code, exitNotFound)
+	}
+	for _, args := range [][]string{{"graph"}, {"graph", "pushups"}, {"graph", "pushups", "archer"}} {
+		if _, _, code := runCLI(t, "", args...); code != exitUsage {
+			t.Errorf("cali %s exited %d, want %d", strings.Join(args, " "), code, exitUsage)
+		}
+	}
+}
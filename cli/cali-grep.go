@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// runGrep lists the entries whose comment matches the text of args (see
+// matchesText), e.g. cali grep knee=poor for the answers to an extra
+// prompt.
+func runGrep(ctx context.Context, args []string, rng dateRange) error {
+	args, err := parseInterspersed(newFlagSet("grep"), args)
+	if err != nil {
+		return flagError(err)
+	}
+	text := strings.TrimSpace(strings.Join(args, " "))
+	if text == "" {
+		return usageError(`usage: cali grep <text> (e.g. cali grep "knee=poor")`)
+	}
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	// The whole log may be searched, so only the matches are kept.
+	var entries []WorkoutEntry
+	err = calio.ForEach(ctx, storage, rng.Since, rng.Until, func(entry WorkoutEntry) error {
+		if strings.TrimSpace(entry.Comment) != "" && matchesText(entry.Comment, text) {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return storageError("searching workouts", err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Print(msg("grep.empty", text))
+		return errNoResults
+	}
+	say(msg("grep.header", text))
+	sayln(separatorRule())
+	for _, entry := range entries {
+		fmt.Print(msg("list.row",
+			displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level, workText(entry), entry.Comment))
+	}
+	sayln(separatorRule())
+	say(msg("list.total", len(entries)))
+	return nil
+}
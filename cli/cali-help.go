@@ -0,0 +1,624 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// command is what cali help knows about a subcommand.
+type command struct {
+	Name     string   // as typed, e.g. "status" or "--tutorial"
+	Aliases  []string // other spellings, e.g. -p for history
+	Usage    []string // synopsis lines, without the leading "cali"
+	Summary  string   // one line for the index of cali --help
+	About    string   // a few sentences for cali help <name>
+	Examples []string
+	// Flags returns the command's flag sets, built by the same functions the
+	// command parses with, so its help lists exactly the flags it takes.
+	Flags func() []*flag.FlagSet
+}
+
+// commands lists every subcommand in the order cali --help shows them. It is
+// filled in init because the flag sets of its entries print help from it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{
+			Name: "log",
+			Usage: []string{"[log] [--deload | --interval | --category mobility] [--flag <kind:note>]... [--extra <key=value>]... [--load <weight>] [--readiness <1-5>] [--no-duration] [--all-levels] [--sheet <tab>]",
+				"log --exercise <exercise> --level <level> --reps <reps> [--day <day>] [--comment <text> | --comment -] [--deload | --interval] [--flag <kind:note>]... [--extra <key=value>]... [--load <weight>] [--readiness <1-5>]"},
+			Summary: "Log a new workout (what cali does without a command)",
+			About: `Asks for the day, exercise, level, reps and a comment, then saves the entry.
+--deload scales the suggested targets by CALI_DELOAD_PERCENT and tags the entry #deload.
+--load records weight added to the sets; with CALI_LOADED=1 cali asks for it after the reps.
+--readiness 1-5 stores how ready you are today; with CALI_READINESS=1 the first
+session of a day asks, and a score of 2 or below suggests deload targets.
+--all-levels also offers the levels hidden with CALI_HIDE_LEVELS.
+Before the reps, the last result at the level is shown with a target one step up
+(Enter takes it only with CALI_ACCEPT_SUGGESTION=1).
+CALI_PROMPT_<key> adds questions for some exercises; --extra answers one ahead.
+Mobility work (Trifecta holds) sits outside the A/B/C rotation.
+With --exercise, --level and --reps nothing is asked: names match as in cali q,
+and --comment - reads the comment from stdin. Missing one of the three is a usage
+error, never a prompt.`,
+			Examples: []string{"cali", `cali --deload --flag pain:"left shoulder"`,
+				`echo "long note" | cali log --day B --exercise Pullups --level Full --reps 8x2 --comment -`},
+			Flags: func() []*flag.FlagSet { return []*flag.FlagSet{newLogFlagSet(&logOptions{})} },
+		},
+		{
+			Name:     "q",
+			Usage:    []string{`q [--yes] [--load <weight>] [--extra <key=value>]... "[day] <exercise> <level> <reps> [comment]"`},
+			Summary:  "Log in one line, e.g. cali q \"B pullups full 8x2\"",
+			About:    "Reads the whole entry from one line; -- inside the text starts the comment.\nExtra prompts (CALI_PROMPT_<key>) aren't asked; --extra answers them.",
+			Examples: []string{`cali q "B pullups full 8x2 felt strong"`, `cali q --yes "squats half 20x2 -- knees fine"`},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newQuickFlagSet(&quickOptions{})} },
+		},
+		{
+			Name:    "template",
+			Usage:   []string{"template [name]"},
+			Summary: "Log a predefined session, or list the configured templates",
+			About: `Steps through the items of CALI_TEMPLATE_<name> from the config, e.g.
+CALI_TEMPLATE_A=Pushups, current, 12x2; Squats, current, 12x2; Bridges, current, 30s, optional
+Enter takes the planned value, - skips an item.`,
+			Examples: []string{"cali template", "cali template A"},
+		},
+		{
+			Name:    "retry-unsaved",
+			Usage:   []string{"retry-unsaved"},
+			Summary: "Save the entries a failed save kept in unsaved-entry.json",
+			About: `When saving fails at the end of a session (an expired token, a network blip),
+the entry is kept instead of lost. The next interactive cali run offers to save it;
+this saves the kept entries oldest first without asking.`,
+			Examples: []string{"cali retry-unsaved", "CALI_SHEET_NAME=Log cali retry-unsaved"},
+		},
+		{
+			Name:    "history",
+			Aliases: []string{"-p", "--print", "--history"},
+			Usage:   []string{"history [--full] [--include <kinds>] [--all-types] [--format csv|tsv [--header]] [--since <date>] [--until <date>]"},
+			Summary: "Show the last 10 workouts",
+			About: `Only working sets are listed: warm-ups (tagged #warmup), mobility entries and
+rest days are left out unless --include names them (warmups, mobility, rest) or
+--all-types is given; the limit of 10 counts listed entries only. Comments are cut
+to one line unless --full is given. With --since or --until every entry in the
+range is shown. Lines fit the terminal; the global --width <columns> overrides its
+width. --format csv or tsv prints the same entries as rows of the sheet's columns
+A to I instead, and nothing else, for pasting elsewhere.`,
+			Examples: []string{"cali -p --include warmups,rest", "cali history --since 2w --full", "cali -p --format tsv --header"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newHistoryFlagSet(&historyOptions{})} },
+		},
+		{
+			Name:    "search",
+			Aliases: []string{"-s", "--search"},
+			Usage:   []string{"search <date> [--format csv|tsv [--header]]", "search --flag <kind> [date] [--format csv|tsv [--header]]"},
+			Summary: "Find workouts by date, or entries carrying a flag",
+			About: `Dates are YYYY-MM-DD or forms like yesterday, "last tue", "3d ago" and jan-20.
+--flag lists the entries flagged with a kind such as pain.
+--format csv or tsv prints the entries found as rows, as history does.`,
+			Examples: []string{"cali -s 2026-01-24", "cali -s --flag pain --since 1m", "cali -s yesterday --format csv"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newSearchFlagSet(&searchOptions{})} },
+		},
+		{
+			Name:    "grep",
+			Usage:   []string{"grep <text> [--since <date>] [--until <date>]"},
+			Summary: "Find entries whose comment contains a text",
+			About: `Case is ignored. key=value matches the answer to an extra prompt exactly
+(see CALI_PROMPT_<key> in the README), so knee=ok doesn't find knee=okay.`,
+			Examples: []string{`cali grep "knee=poor"`, "cali grep elbow --since 3m"},
+		},
+		{
+			Name:     "today",
+			Usage:    []string{"today"},
+			Summary:  "Show today's entries and what's left of the day plan",
+			Examples: []string{"cali today", "cali today --fail-empty"},
+		},
+		{
+			Name:    "status",
+			Usage:   []string{"status [--short]"},
+			Summary: "Days since the last workout, next day and this week's sessions",
+			About: `--short prints one undecorated line for shell prompts and status bars, and
+prints "cali: unavailable" instead of failing when storage can't be read.`,
+			Examples: []string{"cali status", "cali status --short"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newStatusFlagSet(&statusOptions{})} },
+		},
+		{
+			Name:    "remove",
+			Aliases: []string{"-r", "--remove"},
+			Usage:   []string{"remove", "remove --date <date> --index <n> [--yes]"},
+			Summary: "Remove a workout entry (backed up first, once a day)",
+			About: `Without flags, asks for a date, lists its entries and removes the one picked; 0
+cancels. --date and --index remove the entry cali -s <date> lists with that number.
+The removed entry is printed in full; at a terminal, answering y to the undo
+offer within 15 seconds puts it back where it was (--yes skips the offer).`,
+			Examples: []string{"cali -r", "cali -r --date 2026-10-16 --index 2"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newRemoveFlagSet(&removeOptions{})} },
+		},
+		{
+			Name:     "restore",
+			Usage:    []string{"restore --latest-auto [--dry-run]"},
+			Summary:  "Restore the newest automatic backup",
+			About:    "--dry-run shows what would change without writing anything.",
+			Examples: []string{"cali restore --latest-auto --dry-run", "cali restore --latest-auto"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newRestoreFlagSet(&restoreOptions{})} },
+		},
+		{
+			Name:    "stats",
+			Aliases: []string{"--stats"},
+			Usage:   []string{"stats [--times] [--since <date>] [--until <date>]"},
+			Summary: "Show training statistics, records, plateaus, rest days and the streak",
+			About: `--times shows instead when you train: sessions by the 2-hour slot they started in,
+weekdays and weekends apart, in the timezone of CALI_TZ.`,
+			Examples: []string{"cali --stats", "cali stats --since 2026-01-01 --until 2026-03-31", "cali --stats --times --since 3m"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newStatsFlagSet(&statsOptions{})} },
+		},
+		{
+			Name:    "compliance",
+			Usage:   []string{"compliance"},
+			Summary: "Show how each frequency target was kept over the last 30 days",
+			About: `Targets are set in CALI_TARGETS, e.g. CALI_TARGETS=Pushups: every 6 days; B: 2x/week
+Day targets that are behind also pick the day cali suggests next.`,
+			Examples: []string{"cali compliance", "CALI_TARGETS='B: 2x/week' cali compliance"},
+		},
+		{
+			Name:    "rest",
+			Usage:   []string{"rest [--reason <text>] [--date <date>]", "rest --list [--since <date>] [--until <date>]"},
+			Summary: "Mark today as a planned rest day, or list rest days",
+			About: `Marks today, or the day --date names, as a planned rest day. CALI_REST_PER_WEEK
+(default 4) is how many rest days a week keep a streak going. Days marked ahead
+show in cali plan and count toward streaks once they come.`,
+			Examples: []string{"cali rest --reason travel", "cali rest --list --since 1m"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newRestFlagSet(&restOptions{})} },
+		},
+		{
+			Name:    "plan",
+			Usage:   []string{"plan [--week]"},
+			Summary: "Project the next seven days of the A/B/C rotation",
+			About: `Projects a session on each of CALI_SESSIONS_PER_WEEK (default 3) weekdays: the days
+you trained on most in the last 8 weeks, or Mon/Wed/Fri and the like without history.
+Letters follow the rotation from the suggested next day (see CALI_TARGETS); days marked
+with cali rest --date are rest. A projection only: logging differently shifts it.`,
+			Examples: []string{"cali plan --week", "CALI_SESSIONS_PER_WEEK=6 cali plan --week"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newPlanFlagSet(&planOptions{})} },
+		},
+		{
+			Name:    "goal",
+			Usage:   []string{"goal set <exercise> <level> <goal>", "goal unset <exercise> <level>", "goal list [exercise]"},
+			Summary: "Use your own goal for a level instead of the built-in one",
+			About: `Own goals are marked with * in levels and while logging. unset goes back to
+the built-in goal.`,
+			Examples: []string{"cali goal set Pushups Full 15x2", "cali goal list Pushups"},
+		},
+		{
+			Name:    "progress",
+			Usage:   []string{"progress [exercise]", "progress <exercise> --notes [--grep <text>]"},
+			Summary: "Estimate when each current level's progression standard is reached",
+			About: `--notes lists what you wrote on an exercise instead: every entry with a comment,
+oldest first, with its level and work and the comment wrapped to the terminal.
+--since and --until limit the dates, --grep the comments; #tags are highlighted.`,
+			Examples: []string{"cali progress", "cali progress Pullups", "cali progress Pullups --notes --grep elbow"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newProgressFlagSet(&progressOptions{})} },
+		},
+		{
+			Name:    "next",
+			Usage:   []string{"next"},
+			Summary: "Show how close each exercise is to its next level, closest first",
+			About: `One row per exercise at its current level (pinned or trained last): the goal,
+the best of the last 5 training days at that level and what is still missing.
+Once the standard is met, the next level is shown with its tutorial.`,
+			Examples: []string{"cali next", "cali next --user sam"},
+		},
+		{
+			Name:    "serve",
+			Usage:   []string{"serve [--addr host:port] [--cache 5s]"},
+			Summary: "Serve the log over HTTP: GET /entries to read, POST /entries to log",
+			About: `GET /entries takes ?since= and ?until= like --since and --until and returns a
+JSON array. POST /entries takes one entry or an array, checked and completed as
+cali q would, and returns them as stored. Requests run side by side on one
+storage; repeated GETs within --cache share one read, and POSTs arriving
+together are saved in one write.`,
+			Examples: []string{"cali serve", "cali serve --addr :8080 --cache 10s"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newServeFlagSet(&serveOptions{})} },
+		},
+		{
+			Name:     "graph",
+			Usage:    []string{"graph <exercise> <level> [--since <date>] [--until <date>]"},
+			Summary:  "Chart the total of each session over time against the goal",
+			Examples: []string{"cali graph Pullups Full", "cali graph Squats Half --since 3m"},
+		},
+		{
+			Name:     "compare",
+			Usage:    []string{"compare [--window 4w] [--against previous|same-period-last-year] [--json]", "compare --user <name> [--window 4w]"},
+			Summary:  "Compare the last window with the one before, a year earlier, or another user",
+			Examples: []string{"cali compare", "cali compare --window 3m --against same-period-last-year"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newCompareFlagSet(&compareOptions{})} },
+		},
+		{
+			Name:    "report",
+			Usage:   []string{"report [--email [--send-empty] | --markdown] [--include-private] [--since <date>] [--until <date>]"},
+			Summary: "Recap of last week, printed or sent by email",
+			About: `--email sends it via SMTP (CALI_SMTP_* settings), skipping weeks with nothing
+logged unless --send-empty is given. --markdown prints it as Markdown.`,
+			Examples: []string{"cali report", "cali report --email --since 2026-10-05 --until 2026-10-11"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newReportFlagSet(&reportOptions{})} },
+		},
+		{
+			Name:    "doctor",
+			Usage:   []string{"doctor", "doctor --goals | --fix-goals [--dry-run]", "doctor --normalize [--dry-run]"},
+			Summary: "List entries analytics skip, or entries whose stored goal is outdated",
+			About: `Without flags, lists entries dated more than a day in the future, rows
+written by a newer cali than this one and work the spreadsheet turned into a
+date or time (e.g. "10/30/2026" for 10-30), and warns when the Sheets service account
+key is older than CALI_KEY_MAX_AGE days (default 80) or about to expire. --fix-goals rewrites outdated goals after asking.
+--normalize rewrites exercise and level names such as "FULL" or "full " as cali
+spells them, after a preview.`,
+			Examples: []string{"cali doctor", "cali doctor --fix-goals --dry-run"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newDoctorFlagSet(&doctorOptions{})} },
+		},
+		{
+			Name:     "metrics",
+			Usage:    []string{"metrics [--since <date>] [--until <date>]"},
+			Summary:  "Print Prometheus metrics (node_exporter textfile format)",
+			Examples: []string{"cali metrics", "cali metrics > /var/lib/node_exporter/cali.prom"},
+		},
+		{
+			Name:    "import",
+			Usage:   []string{"import [--format csv|tsv] [--dry-run] <file>", "import [--format csv|tsv] [--dry-run] -"},
+			Summary: "Add the entries of a CSV or TSV file, or of stdin with -",
+			About: `Reads rows of the sheet's columns A to I, as cali export --format csv writes them,
+with or without the header row. Date, Exercise, Level and RepsxSets are needed;
+an empty Goal takes the level's goal. A bad row stops the import before anything is written.`,
+			Examples: []string{"cali import workouts.csv", "cali export --format tsv | ssh box cali import --format tsv -"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newImportFlagSet(&importOptions{})} },
+		},
+		{
+			Name:     "export",
+			Usage:    []string{"export --format gfit-json [--start HH:MM] [--session-length 45m] [--include-private] [--since <date>] [--until <date>]", "export --format csv|tsv [--header] [--include-private] [--since <date>] [--until <date>]"},
+			Summary:  "Export Google Fit sessions as JSON, or entries as CSV/TSV",
+			About:    "CALI_SESSION_LENGTH sets the default session length of gfit-json.\ncsv and tsv write every entry as a row of the sheet's columns A to I.",
+			Examples: []string{"cali export --format gfit-json", "cali export --format gfit-json --since 2026-01-01 --start 07:30", "cali export --format csv --header > log.csv"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newExportFlagSet(&exportOptions{})} },
+		},
+		{
+			Name:     "share",
+			Usage:    []string{"share export-static [--title <title>] [--out <file>] [--include-private] [--since <date>] [--until <date>]"},
+			Summary:  "Write the history, stats and level chart as one read-only HTML page",
+			About:    "Comments marked private ([private] or #private) show as [redacted] unless --include-private is given.",
+			Examples: []string{"cali share export-static --out log.html", `cali share export-static --title "Spring block" --since 2026-03-01`},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newShareFlagSet(&shareOptions{})} },
+		},
+		{
+			Name:    "remind",
+			Usage:   []string{"remind install [--at 18:00] [--days mon,wed,fri] [--force]", "remind uninstall|status", "remind --check"},
+			Summary: "Schedule a nudge for days when nothing is logged (systemd/launchd)",
+			About: `install writes a user timer that runs cali remind --check, which notifies when
+nothing has been logged today.`,
+			Examples: []string{"cali remind install --at 19:30 --days mon,wed,fri", "cali remind status"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{newRemindFlagSet(new(bool)), newInstallFlagSet(&installOptions{})}
+			},
+		},
+		{
+			Name:     "auth",
+			Usage:    []string{"auth store [--sheet-id <id>] [--sheet-name <tab>] [--credentials <file>]", "auth show|clear"},
+			Summary:  "Keep the Sheets ID, tab and credentials path in the OS keyring",
+			About:    "store asks for any value not given as a flag.",
+			Examples: []string{"cali auth store", "cali auth store --sheet-id 1AbC --credentials ~/cali-sa.json"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newAuthFlagSet(&authOptions{})} },
+		},
+		{
+			Name:    "sheet",
+			Usage:   []string{"sheet format", "sheet dashboard", "sheet rollup [YYYY-MM]"},
+			Summary: "Format the log tab, or write a Dashboard tab of summaries",
+			About: `format adds a Day dropdown, goal highlighting, banding and column widths, and
+makes the RepsxSets, Goal and Load columns plain text.
+dashboard creates or refreshes the Dashboard tab: sessions per month, each
+exercise's last day and current level, goals met and the current streak.
+rollup writes the month's totals (this month by default) as a block of the
+Summary tab, replacing the block an earlier run wrote for that month.`,
+			Examples: []string{"cali sheet dashboard", "cali sheet rollup 2026-09"},
+		},
+		{
+			Name:    "self",
+			Usage:   []string{"self install [--dir <path>] [--force]", "self uninstall [--dir <path>] [--force]"},
+			Summary: "Install this binary on the PATH, or remove it",
+			About: `The default directory is ~/.local/bin (%LOCALAPPDATA%\Programs\cali on Windows);
+install also checks that it is on PATH.`,
+			Examples: []string{"cali self install", "cali self uninstall --dir ~/bin"},
+			Flags: func() []*flag.FlagSet {
+				return []*flag.FlagSet{newSelfFlagSet("install", &selfOptions{}), newSelfFlagSet("uninstall", &selfOptions{})}
+			},
+		},
+		{
+			Name:    "achievements",
+			Usage:   []string{"achievements [exercise] [--markdown]"},
+			Summary: "Timeline of the levels whose progression standard you reached",
+			About: `Each level shows once, on the first day a working session met its goal; deload
+sessions, intervals and later re-tests don't count.`,
+			Examples: []string{"cali achievements", "cali achievements Pullups --markdown"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newAchievementsFlagSet(&achievementsOptions{})} },
+		},
+		{
+			Name:    "timer",
+			Usage:   []string{"timer [--no-notify] [duration]"},
+			Summary: "Count down the rest between sets and ring when it ends",
+			About: `Counts down 90s unless given a duration such as 2min or 1:30, then rings the
+terminal bell. CALI_NOTIFY=desktop,sound also shows a desktop notification and plays
+a sound; CALI_NOTIFY_COMMAND and CALI_NOTIFY_SOUND_COMMAND replace the commands.`,
+			Examples: []string{"cali timer", "CALI_NOTIFY=desktop cali timer 2min"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newTimerFlagSet(&timerOptions{})} },
+		},
+		{
+			Name:    "sync",
+			Usage:   []string{"sync --pull | --push [--dry-run]"},
+			Summary: "Copy entries missing on one side between the sheet and the local log",
+			About: `--pull copies sheet entries the local log lacks into it, --push the other way; both
+flags do both. Entries match on date, exercise, level, work, comment and user. One that
+differs from the other side only in its comment is reported, not copied. Prints nothing
+when nothing is missing, so it can run from cron or a timer.`,
+			Examples: []string{"cali sync --pull", "cali sync --pull --push --dry-run"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newSyncFlagSet(&syncOptions{})} },
+		},
+		{
+			Name:    "levels",
+			Usage:   []string{"levels [exercise]", "levels --matrix [--no-color] [--markdown]", "levels set <exercise> <level>", "levels unset <exercise>"},
+			Summary: "List levels with their standards, or pin your working level",
+			About: `--matrix prints all six ladders as one chart, marking your current level. set
+pins a level instead of taking the last one trained.`,
+			Examples: []string{"cali levels Pushups", "cali levels --matrix --markdown"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newLevelsFlagSet(&levelsOptions{})} },
+		},
+		{
+			Name:     "describe",
+			Usage:    []string{"describe <exercise> [level|step]"},
+			Summary:  "Show level descriptions and form cues offline",
+			Examples: []string{"cali describe Pushups", "cali describe Squats 3"},
+		},
+		{
+			Name:     "tutorials",
+			Usage:    []string{"tutorials [--unwatched] [exercise]"},
+			Summary:  "List tutorials, marking the ones already watched",
+			Examples: []string{"cali tutorials", "cali tutorials --unwatched Pullups"},
+			Flags:    func() []*flag.FlagSet { return []*flag.FlagSet{newTutorialsFlagSet(new(bool))} },
+		},
+		{
+			Name:     "--tutorial",
+			Usage:    []string{"--tutorial <exercise> [level]"},
+			Summary:  "Open the tutorial of a level, or the exercise playlist",
+			Examples: []string{`cali --tutorial Pushups Incline`, "cali --tutorial Bridges"},
+		},
+		{
+			Name:     "-yt",
+			Aliases:  []string{"--yt"},
+			Usage:    []string{"-yt"},
+			Summary:  "Open the Convicted Conditioning playlists",
+			Examples: []string{"cali -yt", "cali --yt"},
+		},
+		{
+			Name:     "open",
+			Aliases:  []string{"--template"},
+			Usage:    []string{"open workout-template", "--template"},
+			Summary:  "Open the workout template link",
+			Examples: []string{"cali open workout-template", "cali --template"},
+		},
+		{
+			Name:     "--version",
+			Usage:    []string{"--version"},
+			Summary:  "Show version, commit and build date",
+			Examples: []string{"cali --version", "~/.local/bin/cali --version"},
+		},
+		{
+			Name:     "--check-update",
+			Usage:    []string{"--check-update"},
+			Summary:  "Check GitHub for a newer release (never runs on its own)",
+			Examples: []string{"cali --check-update", "cali --check-update --verbose"},
+		},
+		{
+			Name:     "help",
+			Aliases:  []string{"-h", "--help"},
+			Usage:    []string{"help [command]", "<command> --help"},
+			Summary:  "Show this index, or a command's flags and examples",
+			About:    "cali help settings lists the environment variables and exit codes.",
+			Examples: []string{"cali help history", "cali compare --help"},
+		},
+	}
+}
+
+// helpSettings is the topic of cali help that isn't a command.
+const helpSettings = "settings"
+
+// findCommand looks name up among the commands and their aliases, then
+// again without leading dashes, so "stats" and "--stats" both find stats
+// while "--template" still finds open rather than template.
+func findCommand(name string) (command, bool) {
+	for _, trim := range []bool{false, true} {
+		for _, cmd := range commands {
+			for _, spelling := range append([]string{cmd.Name}, cmd.Aliases...) {
+				if spelling == name || (trim && strings.TrimLeft(name, "-") != "" &&
+					strings.TrimLeft(spelling, "-") == strings.TrimLeft(name, "-")) {
+					return cmd, true
+				}
+			}
+		}
+	}
+	return command{}, false
+}
+
+// suggestCommand returns the command name closest to name by edit
+// distance, or "" when none is close enough to be a likely typo.
+func suggestCommand(name string) string {
+	bare := strings.TrimLeft(name, "-")
+	best, bestDistance := "", 0
+	for _, cmd := range commands {
+		for _, spelling := range append([]string{cmd.Name}, cmd.Aliases...) {
+			spelling = strings.TrimLeft(spelling, "-")
+			distance := editDistance(bare, spelling)
+			if distance > 2 || distance >= len([]rune(spelling)) {
+				continue
+			}
+			if best == "" || distance < bestDistance {
+				best, bestDistance = cmd.Name, distance
+			}
+		}
+	}
+	return best
+}
+
+// editDistance is the number of single-character insertions, deletions,
+// substitutions and swaps of neighbours that turn a into b, so the common
+// typo "stauts" is one edit from "status".
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// unknownCommand is the usage error for a command cali doesn't have,
+// suggesting the closest one.
+func unknownCommand(name string) error {
+	if suggestion := suggestCommand(name); suggestion != "" {
+		return usageError("unknown command %q (did you mean %q? see cali --help)", name, suggestion)
+	}
+	return usageError("unknown command %q (see cali --help)", name)
+}
+
+// helpRequest reports whether args ask for help, and about what: "" for the
+// index. Any -h or --help before "--" asks about the command it follows.
+func helpRequest(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	if args[0] == "help" {
+		if len(args) > 1 {
+			return args[1], true
+		}
+		return "", true
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg != "-h" && arg != "--h" && arg != "-help" && arg != "--help" {
+			continue
+		}
+		if _, ok := findCommand(args[0]); ok {
+			return args[0], true
+		}
+		if strings.HasPrefix(args[0], "-") {
+			// A flag of the interactive log, or the help flag itself.
+			if acceptsSheet(args[:1]) {
+				return "log", true
+			}
+			return "", true
+		}
+		return args[0], true
+	}
+	return "", false
+}
+
+// runHelp prints the help asked for by helpRequest to stdout.
+func runHelp(name string) error {
+	switch name {
+	case "", "-h", "--h", "--help":
+		showHelp()
+		return nil
+	case helpSettings:
+		fmt.Print(msg("help.settings"))
+		return nil
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		return unknownCommand(name)
+	}
+	showCommandHelp(os.Stdout, cmd)
+	return nil
+}
+
+// showHelp prints the index of cali --help, one line per command.
+func showHelp() {
+	var lines strings.Builder
+	for _, cmd := range commands {
+		fmt.Fprintf(&lines, "  %-15s %s\n", cmd.Name, cmd.Summary)
+	}
+	fmt.Print(msg("help", lines.String()))
+}
+
+// showCommandHelp prints cmd's usage, description, flags and examples to w.
+func showCommandHelp(w io.Writer, cmd command) {
+	fmt.Fprintf(w, "cali %s - %s\n\nUsage:\n", cmd.Name, cmd.Summary)
+	for _, usage := range cmd.Usage {
+		fmt.Fprintf(w, "  cali %s\n", usage)
+	}
+	if len(cmd.Aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases: %s\n", strings.Join(cmd.Aliases, ", "))
+	}
+	if cmd.About != "" {
+		fmt.Fprintf(w, "\n%s\n", cmd.About)
+	}
+	if cmd.Flags != nil {
+		for _, fs := range cmd.Flags() {
+			if fs.Name() == cmd.Name {
+				fmt.Fprintln(w, "\nFlags:")
+			} else {
+				fmt.Fprintf(w, "\nFlags of cali %s:\n", fs.Name())
+			}
+			fs.SetOutput(w)
+			fs.PrintDefaults()
+		}
+	}
+	fmt.Fprintln(w, "\nExamples:")
+	for _, example := range cmd.Examples {
+		fmt.Fprintf(w, "  %s\n", example)
+	}
+}
+
+// newFlagSet returns the flag set of the command name, e.g. "remind install".
+// On a bad flag it prints the error and then the command's help to stderr.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		parent, _, _ := strings.Cut(name, " ")
+		if cmd, ok := findCommand(parent); ok {
+			showCommandHelp(fs.Output(), cmd)
+		}
+	}
+	return fs
+}
+
+// parseInterspersed parses the flags of fs wherever they are in args, so
+// they may follow a name as in "cali progress Pullups --notes", and
+// returns the other arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return rest, nil
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
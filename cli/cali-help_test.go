@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+// TestHelpIndexGolden pins the index cali --help prints; every way of
+// asking for it prints the same to stdout and exits 0.
+func TestHelpIndexGolden(t *testing.T) {
+	stdout, stderr, code := runCLI(t, "", "--help")
+	if code != 0 || stderr != "" {
+		t.Fatalf("cali --help exited %d: %s", code, stderr)
+	}
+	checkGolden(t, "help.txt", stdout)
+	for _, args := range [][]string{{"help"}, {"-h"}, {"help", "--help"}, {"--verbose", "--help"}} {
+		if again, stderr, code := runCLI(t, "", args...); code != 0 || again != stdout || stderr != "" {
+			t.Errorf("cali %s exited %d and printed another index: %s", strings.Join(args, " "), code, stderr)
+		}
+	}
+}
+
+// TestHistoryHelpGolden pins one command's help, flags included.
+func TestHistoryHelpGolden(t *testing.T) {
+	stdout, stderr, code := runCLI(t, "", "help", "history")
+	if code != 0 || stderr != "" {
+		t.Fatalf("cali help history exited %d: %s", code, stderr)
+	}
+	checkGolden(t, "help.history.txt", stdout)
+	for _, args := range [][]string{{"history", "--help"}, {"-p", "-h"}, {"help", "--print"}, {"history", "--since", "2w", "--help"}} {
+		if again, _, code := runCLI(t, "", args...); code != 0 || again != stdout {
+			t.Errorf("cali %s exited %d and printed other help", strings.Join(args, " "), code)
+		}
+	}
+}
+
+// TestCommandHelp asks every command for its help and checks it has the
+// usage, at least two examples and every flag the command parses with.
+func TestCommandHelp(t *testing.T) {
+	names := map[string]bool{}
+	for _, cmd := range commands {
+		for _, spelling := range append([]string{cmd.Name}, cmd.Aliases...) {
+			if names[spelling] {
+				t.Errorf("%q names two commands", spelling)
+			}
+			names[spelling] = true
+		}
+		if cmd.Summary == "" || len(cmd.Usage) == 0 || len(cmd.Examples) < 2 {
+			t.Errorf("cali %s has a summary of %q, %d usage line(s) and %d example(s)", cmd.Name, cmd.Summary, len(cmd.Usage), len(cmd.Examples))
+		}
+
+		stdout, stderr, code := runCLI(t, "", "help", cmd.Name)
+		if code != 0 || stderr != "" || !strings.HasPrefix(stdout, "cali "+cmd.Name+" - "+cmd.Summary+"\n\nUsage:\n") {
+			t.Errorf("cali help %s exited %d, printed %.100q %s", cmd.Name, code, stdout, stderr)
+			continue
+		}
+		for _, example := range cmd.Examples {
+			if !strings.Contains(stdout, "\nExamples:\n") || !strings.Contains(stdout, "  "+example+"\n") {
+				t.Errorf("cali help %s doesn't show the example %q", cmd.Name, example)
+			}
+		}
+		if cmd.Flags == nil {
+			continue
+		}
+		for _, fs := range cmd.Flags() {
+			fs.VisitAll(func(f *flag.Flag) {
+				if !strings.Contains(stdout, "  -"+f.Name) {
+					t.Errorf("cali help %s doesn't list -%s", cmd.Name, f.Name)
+				}
+			})
+		}
+	}
+	if !strings.Contains(msg("help", ""), "cali help settings") {
+		t.Errorf("the index doesn't point at cali help settings")
+	}
+	if stdout, _, code := runCLI(t, "", "help", helpSettings); code != 0 || !strings.Contains(stdout, "CALI_") {
+		t.Errorf("cali help settings exited %d", code)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"status", "status", 0},
+		{"stauts", "status", 1},
+		{"histroy", "history", 1},
+		{"hstory", "history", 1},
+		{"historyy", "history", 1},
+		{"stats", "status", 1},
+		{"rest", "reset", 1},
+		{"", "plan", 4},
+		{"übung", "ubung", 1},
+	} {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+		if got := editDistance(tc.b, tc.a); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.b, tc.a, got, tc.want)
+		}
+	}
+}
+
+func TestSuggestCommand(t *testing.T) {
+	for name, want := range map[string]string{
+		"stauts":   "status",
+		"histroy":  "history",
+		"--histry": "history",
+		"exprot":   "export",
+		"tutorail": "--tutorial",
+		"xyz":      "",
+		"q2":       "",
+		"":         "",
+	} {
+		if got := suggestCommand(name); got != want {
+			t.Errorf("suggestCommand(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestHelpRequest(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+		ok   bool
+	}{
+		{nil, "", false},
+		{[]string{"history"}, "", false},
+		{[]string{"help"}, "", true},
+		{[]string{"help", "grep"}, "grep", true},
+		{[]string{"--help"}, "--help", true},
+		{[]string{"grep", "--since", "2w", "-h"}, "grep", true},
+		{[]string{"grep", "--", "--help"}, "", false},
+		{[]string{"--stats", "--help"}, "--stats", true},
+		{[]string{"--deload", "--help"}, "log", true},
+		{[]string{"bogus", "--help"}, "bogus", true},
+	} {
+		got, ok := helpRequest(tc.args)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("helpRequest(%q) = %q, %v, want %q, %v", tc.args, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+// TestUsageErrors checks usage errors go to stderr with exit 2 and nothing
+// on stdout: an unknown command with and without a suggestion, help for
+// one, and a bad flag followed by the command's help.
+func TestUsageErrors(t *testing.T) {
+	t.Setenv("PATH", t.TempDir()) // no plugins to run instead
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"stauts"}, `Error: unknown command "stauts" (did you mean "status"? see cali --help)`},
+		{[]string{"frobnicate"}, `Error: unknown command "frobnicate" (see cali --help)`},
+		{[]string{"help", "histroy"}, `did you mean "history"?`},
+		{[]string{"history", "--bogus"}, "flag provided but not defined: -bogus"},
+	} {
+		stdout, stderr, code := runCLI(t, "", tc.args...)
+		if code != exitUsage || stdout != "" || !strings.Contains(stderr, tc.want) {
+			t.Errorf("cali %s exited %d, printed %q and %q", strings.Join(tc.args, " "), code, stdout, stderr)
+		}
+	}
+	if _, stderr, _ := runCLI(t, "", "history", "--bogus"); !strings.Contains(stderr, "cali history - Show the last 10 workouts") {
+		t.Errorf("a bad flag doesn't show the command's help: %s", stderr)
+	}
+}
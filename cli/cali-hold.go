@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// holdTime is the length of a timed hold in seconds.
+type holdTime int
+
+func (h holdTime) String() string {
+	return formatHold(int(h))
+}
+
+func (h holdTime) minutes() float64 {
+	return float64(h) / 60
+}
+
+// parseHold parses a single compacted, lower-cased hold: "90s", "45sec",
+// "2min", "2m" or "1:30" (minutes and seconds).
+func parseHold(value string) (holdTime, bool) {
+	if minutes, seconds, found := strings.Cut(value, ":"); found {
+		m, err := strconv.Atoi(minutes)
+		if err != nil || m < 0 || len(seconds) != 2 {
+			return 0, false
+		}
+		s, err := strconv.Atoi(seconds)
+		if err != nil || s < 0 || s >= 60 || m*60+s == 0 {
+			return 0, false
+		}
+		return holdTime(m*60 + s), true
+	}
+
+	units := []struct {
+		suffix     string
+		multiplier int
+	}{
+		{"min", 60},
+		{"m", 60},
+		{"sec", 1},
+		{"s", 1},
+	}
+	for _, unit := range units {
+		if !strings.HasSuffix(value, unit.suffix) {
+			continue
+		}
+		amount, err := strconv.Atoi(strings.TrimSuffix(value, unit.suffix))
+		if err != nil || amount <= 0 {
+			return 0, false
+		}
+		return holdTime(amount * unit.multiplier), true
+	}
+	return 0, false
+}
+
+func formatHold(seconds int) string {
+	if seconds%60 == 0 {
+		return fmt.Sprintf("%dmin", seconds/60)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+func holdSeconds(holds []holdTime) []int {
+	seconds := make([]int, len(holds))
+	for i, hold := range holds {
+		seconds[i] = int(hold)
+	}
+	return seconds
+}
+
+// parseHoldInput reads the answer to the "Hold time:" prompt. A bare number
+// is rejected instead of guessing whether it means seconds or minutes.
+func parseHoldInput(input string) (repsSets, error) {
+	value := strings.TrimSpace(input)
+	if _, err := strconv.Atoi(value); err == nil {
+		return repsSets{}, errors.New(msg("log.hold_ambiguous", value, value, value))
+	}
+	parsed, ok := parseRepsSets(value)
+	if !ok || !parsed.timed() {
+		return repsSets{}, errors.New(msg("log.hold_invalid", value))
+	}
+	return parsed, nil
+}
+
+// promptHoldTime reads a hold time; Enter takes suggested, unless it is "".
+func promptHoldTime(reader *bufio.Reader, suggested string) (string, error) {
+	for {
+		if suggested != "" {
+			prompt(msg("log.hold_prompt_default", suggested))
+		} else {
+			prompt(msg("log.hold_prompt"))
+		}
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			return "", inputClosed()
+		}
+		if strings.TrimSpace(input) == "" && suggested != "" {
+			return suggested, nil
+		}
+		parsed, parseErr := parseHoldInput(input)
+		if parseErr == nil {
+			return formatRepsSets(parsed), nil
+		}
+		promptln(parseErr)
+	}
+}
+
+// hasTimedGoal reports whether the level's goal is a hold.
+func hasTimedGoal(exercise, level string) bool {
+	parsed, ok := parseRepsSets(resolveGoal(exercise, level))
+	return ok && parsed.timed()
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeHolds(t *testing.T) {
+	for input, want := range map[string]string{
+		"90s":       "90s",
+		"1:30":      "90s",
+		"2 min":     "2min",
+		"2m":        "2min",
+		"45sec":     "45s",
+		"2min x2":   "2min x2",
+		"1:00 × 3":  "1min x3",
+		"90s,60s":   "90s,1min",
+		"60s/60s":   "1min x2",
+		"1:75":      "1:75", // not a time, kept as typed
+		"90s,8":     "90s,8",
+		"12":        "12x1",
+		"2min x0":   "2min x0",
+		"0:00":      "0:00",
+		"20x2":      "20x2",
+		"10-12 x 2": "10-12 x 2",
+	} {
+		if got := normalizeRepsSets(input); got != want {
+			t.Errorf("normalizeRepsSets(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestMeetsTimedGoal(t *testing.T) {
+	tests := []struct {
+		logged, goal string
+		want         bool
+	}{
+		{"2min", "2min", true},
+		{"90s,2min", "2min", true},
+		{"1:59", "2min", false},
+		{"2min x2", "1min x2", true},
+		{"2min", "1min x2", false},
+		{"120", "2min", false}, // reps never meet a hold
+		{"2min", "20x2", false},
+	}
+	for _, tt := range tests {
+		if got := meetsGoal(tt.logged, tt.goal); got != tt.want {
+			t.Errorf("meetsGoal(%q, %q) = %v, want %v", tt.logged, tt.goal, got, tt.want)
+		}
+	}
+	if percent, ok := goalPercent("90s", "2min"); !ok || percent != 75 {
+		t.Errorf("goalPercent(90s, 2min) = %d, %v, want 75", percent, ok)
+	}
+	if _, ok := goalPercent("90", "2min"); ok {
+		t.Error("goalPercent compares reps with a hold")
+	}
+}
+
+func TestPromptHoldTime(t *testing.T) {
+	quiet(t)
+	tests := []struct {
+		input, suggested string
+		want             string
+	}{
+		{"1:30\n", "", "90s"},
+		{"\n", "2min", "2min"},
+		{"90\n2min\n", "", "2min"}, // a bare number is asked again
+		{"soon\n45 sec\n", "", "45s"},
+	}
+	for _, tt := range tests {
+		got, err := promptHoldTime(bufio.NewReader(strings.NewReader(tt.input)), tt.suggested)
+		if err != nil || got != tt.want {
+			t.Errorf("promptHoldTime(%q, %q) = %q, %v, want %q", tt.input, tt.suggested, got, err, tt.want)
+		}
+	}
+	if _, err := promptHoldTime(bufio.NewReader(strings.NewReader("90\n")), ""); err == nil {
+		t.Error("promptHoldTime returned a value after its input closed")
+	}
+}
+
+func TestHasTimedGoal(t *testing.T) {
+	isolatedHome(t)
+	if hasTimedGoal("Pushups", "Full") {
+		t.Error("Pushups Full has a timed goal")
+	}
+	if !hasTimedGoal("Handstand Push-ups", "Wall Headstand") {
+		t.Error("Wall Headstand has no timed goal")
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// Message catalogs by locale. English is complete; other catalogs may leave
+// messages out, which then fall back to English. Only what people read is
+// translated: storage, flags, JSON, metrics and internal errors stay as-is.
+var catalogs = map[string]map[string]string{
+	"en": messagesEN,
+	"de": messagesDE,
+}
+
+const defaultLocale = "en"
+
+// locale and displayDateLayout are resolved once per run in Run.
+var (
+	locale            = defaultLocale
+	displayDateLayout = calio.DateLayout
+)
+
+// detectLocale picks the catalog from CALI_LANG, falling back to the usual
+// LC_ALL, LC_MESSAGES and LANG variables ("de_DE.UTF-8" selects "de").
+func detectLocale() string {
+	for _, name := range []string{"CALI_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := strings.TrimSpace(os.Getenv(name))
+		if value == "" {
+			continue
+		}
+		lang := strings.ToLower(value)
+		if i := strings.IndexAny(lang, "_.-@"); i >= 0 {
+			lang = lang[:i]
+		}
+		if _, ok := catalogs[lang]; ok {
+			return lang
+		}
+		return defaultLocale
+	}
+	return defaultLocale
+}
+
+// msg looks up a message in the current locale and formats it with a. An id
+// missing from every catalog is returned as-is so the gap is visible.
+func msg(id string, a ...any) string {
+	format, ok := catalogs[locale][id]
+	if !ok {
+		if format, ok = catalogs[defaultLocale][id]; !ok {
+			return id
+		}
+	}
+	if len(a) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, a...)
+}
+
+var formatVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+// validateCatalogs checks that translations only use known ids and take the
+// same format verbs as the English message.
+func validateCatalogs() error {
+	for lang, catalog := range catalogs {
+		if lang == defaultLocale {
+			continue
+		}
+		for id, format := range catalog {
+			english, ok := catalogs[defaultLocale][id]
+			if !ok {
+				return fmt.Errorf("unknown message id in %q catalog: %q", lang, id)
+			}
+			got := strings.Join(formatVerb.FindAllString(format, -1), " ")
+			want := strings.Join(formatVerb.FindAllString(english, -1), " ")
+			if got != want {
+				return fmt.Errorf("message %q in %q catalog uses verbs %q, English uses %q", id, lang, got, want)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveDisplayLayout returns the Go layout for showing dates.
+// CALI_DATE_FORMAT (e.g. DD.MM.YYYY) overrides the locale's default; dates
+// are still stored and typed as YYYY-MM-DD.
+func resolveDisplayLayout() string {
+	format := strings.TrimSpace(os.Getenv("CALI_DATE_FORMAT"))
+	if format == "" {
+		format = msg("date.format")
+	}
+	layout := strings.NewReplacer("YYYY", "2006", "MM", "01", "DD", "02").Replace(strings.ToUpper(format))
+	if strings.ContainsAny(layout, "YMD") || strings.Count(layout, "2006")+strings.Count(layout, "01")+strings.Count(layout, "02") != 3 {
+		fmt.Fprintf(os.Stderr, "Warning: invalid CALI_DATE_FORMAT %q, using YYYY-MM-DD\n", format)
+		return calio.DateLayout
+	}
+	return layout
+}
+
+// displayDate renders a stored YYYY-MM-DD date in the display format,
+// leaving anything unparseable untouched.
+func displayDate(date string) string {
+	t, err := time.Parse(calio.DateLayout, date)
+	if err != nil {
+		return date
+	}
+	return t.Format(displayDateLayout)
+}
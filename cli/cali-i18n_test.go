@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestCatalogs(t *testing.T) {
+	if err := validateCatalogs(); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := catalogs["de"]["today.nothing"]
+	t.Cleanup(func() { catalogs["de"]["today.nothing"] = saved })
+	catalogs["de"]["today.nothing"] = "Heute noch nichts eingetragen (%d)\n"
+	if err := validateCatalogs(); err == nil {
+		t.Error("validateCatalogs took a translation with other verbs")
+	}
+}
+
+func TestDetectLocale(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{}, "en"},
+		{map[string]string{"LANG": "de_DE.UTF-8"}, "de"},
+		{map[string]string{"LANG": "de_DE.UTF-8", "LC_ALL": "en_US.UTF-8"}, "en"},
+		{map[string]string{"LANG": "de_AT", "CALI_LANG": "en"}, "en"},
+		{map[string]string{"CALI_LANG": "DE"}, "de"},
+		{map[string]string{"LC_MESSAGES": "de@euro"}, "de"},
+		{map[string]string{"LANG": "fr_FR.UTF-8"}, "en"}, // no French catalog
+	}
+	for _, tt := range tests {
+		for _, name := range []string{"CALI_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+			t.Setenv(name, tt.env[name])
+		}
+		if got := detectLocale(); got != tt.want {
+			t.Errorf("detectLocale with %v = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestMessagesFallBack(t *testing.T) {
+	saved := locale
+	t.Cleanup(func() { locale = saved })
+	catalogs["en"]["test.only_english"] = "only %s"
+	t.Cleanup(func() { delete(catalogs["en"], "test.only_english") })
+
+	locale = "de"
+	if got := msg("today.nothing", "18.10.2026"); got != "Heute noch nichts eingetragen (18.10.2026)\n" {
+		t.Errorf("German today.nothing = %q", got)
+	}
+	if got := msg("test.only_english", "English"); got != "only English" {
+		t.Errorf("a message only in English = %q, want it in English", got)
+	}
+	if got := msg("test.nowhere"); got != "test.nowhere" {
+		t.Errorf("a message in no catalog = %q, want its id", got)
+	}
+}
+
+func TestDisplayDates(t *testing.T) {
+	saved, savedLayout := locale, displayDateLayout
+	t.Cleanup(func() { locale, displayDateLayout = saved, savedLayout })
+	tests := []struct {
+		locale, format string
+		want           string
+	}{
+		{"en", "", "2026-03-04"},
+		{"de", "", "04.03.2026"},
+		{"en", "DD/MM/YYYY", "04/03/2026"},
+		{"de", "mm-dd-yyyy", "03-04-2026"},
+		{"en", "DD.MM", "2026-03-04"}, // no year: invalid, stored form
+		{"en", "YYYY-MM-DD-DD", "2026-03-04"},
+	}
+	for _, tt := range tests {
+		locale = tt.locale
+		t.Setenv("CALI_DATE_FORMAT", tt.format)
+		captureOutput(t, &os.Stderr, func() { displayDateLayout = resolveDisplayLayout() })
+		if got := displayDate("2026-03-04"); got != tt.want {
+			t.Errorf("locale %s, CALI_DATE_FORMAT=%q: displayDate = %q, want %q", tt.locale, tt.format, got, tt.want)
+		}
+	}
+	displayDateLayout = calio.DateLayout
+	if got := displayDate("someday"); got != "someday" {
+		t.Errorf("displayDate(someday) = %q", got)
+	}
+}
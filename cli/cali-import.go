@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// importOptions are the flags of cali import.
+type importOptions struct {
+	Format string
+	DryRun bool
+}
+
+// newImportFlagSet declares the flags of cali import into opts.
+func newImportFlagSet(opts *importOptions) *flag.FlagSet {
+	fs := newFlagSet("import")
+	fs.StringVar(&opts.Format, "format", formatCSV, "how the rows are separated: csv or tsv")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "list the entries that would be added without writing anything")
+	return fs
+}
+
+// runImport appends the entries of a CSV or TSV file, as cali export writes
+// them (see readDelimited), in one write. "-" reads the rows from stdin,
+// which must then be a pipe or a file rather than a terminal. Every row is
+// checked before anything is written, so a bad row adds nothing.
+func runImport(ctx context.Context, args []string) error {
+	var opts importOptions
+	args, err := parseInterspersed(newImportFlagSet(&opts), args)
+	if err != nil {
+		return flagError(err)
+	}
+	if len(args) != 1 {
+		return usageError("usage: cali import [--format csv|tsv] [--dry-run] <file> (- reads stdin)")
+	}
+	if !delimitedFormat(opts.Format) {
+		return usageError("unknown format %q (use %s or %s)", opts.Format, formatCSV, formatTSV)
+	}
+
+	source := args[0]
+	var in io.Reader = os.Stdin
+	if source == stdinArg {
+		if isTerminal(os.Stdin) {
+			return usageError("cali import - reads the rows from stdin; pipe them in, e.g. cali import - < log.csv")
+		}
+		source = "stdin"
+	} else {
+		file, err := os.Open(source)
+		if err != nil {
+			return usageError("%v", err)
+		}
+		defer file.Close()
+		in = file
+	}
+	entries, err := readDelimited(in, opts.Format)
+	if err != nil {
+		return usageError("%s: %v", source, err)
+	}
+	if len(entries) == 0 {
+		fmt.Print(msg("import.empty", source))
+		return errNoResults
+	}
+	if err := checkFutureDates(entries, currentTime()); err != nil {
+		return err
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	loadGoalOverrides(ctx, storage)
+	for i, entry := range entries {
+		if strings.TrimSpace(entry.Goal) == "" {
+			entries[i].Goal = resolveGoal(entry.Exercise, entry.Level)
+		}
+	}
+
+	if opts.DryRun {
+		say(msg("import.dry_run", len(entries), source))
+		for _, entry := range entries {
+			fmt.Print(msg("list.row",
+				displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level, workText(entry), oneLineComment(entry.Comment, historyCommentWidth)))
+		}
+		return nil
+	}
+	beforeMutation(ctx, storage)
+	saved, err := storage.AppendBatch(ctx, entries)
+	if err != nil {
+		keepUnsaved(err, entries...)
+		return storageError("importing workouts", err)
+	}
+	journal.record(saved)
+	fmt.Print(msg("import.done", len(saved), source))
+	return nil
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// intervalProtocols lists the timed protocols in prompt order, in their
+// canonical spelling.
+var intervalProtocols = []string{"EMOM", "AMRAP", "Tabata"}
+
+const (
+	defaultTabataSeconds = 4 * 60
+	tabataRoundSeconds   = 30
+)
+
+func isInterval(entry WorkoutEntry) bool {
+	return calio.NormalizeWorkoutType(entry.Type) == calio.TypeInterval
+}
+
+// parseInterval parses a compacted, lower-cased interval value such as
+// "emom10min@12", "tabata4min@8" or "amrap12min@15x6". EMOM counts one round
+// per minute and Tabata one per 30 seconds; AMRAP has no fixed round length,
+// so the completed rounds follow the reps as "xN".
+func parseInterval(value string) (repsSets, bool) {
+	var protocol, rest string
+	for _, candidate := range intervalProtocols {
+		if strings.HasPrefix(value, strings.ToLower(candidate)) {
+			protocol = candidate
+			rest = strings.TrimPrefix(value, strings.ToLower(candidate))
+			break
+		}
+	}
+	if protocol == "" {
+		return repsSets{}, false
+	}
+
+	duration, perRound, found := strings.Cut(rest, "@")
+	if !found {
+		return repsSets{}, false
+	}
+	hold, ok := parseHold(duration)
+	if !ok {
+		return repsSets{}, false
+	}
+	seconds := int(hold)
+
+	roundsText := ""
+	if protocol == "AMRAP" {
+		if perRound, roundsText, found = strings.Cut(perRound, "x"); !found {
+			return repsSets{}, false
+		}
+	}
+	reps, err := strconv.Atoi(perRound)
+	if err != nil || reps < 1 {
+		return repsSets{}, false
+	}
+
+	var rounds int
+	switch protocol {
+	case "EMOM":
+		rounds = seconds / 60
+	case "Tabata":
+		rounds = seconds / tabataRoundSeconds
+	case "AMRAP":
+		if rounds, err = strconv.Atoi(roundsText); err != nil {
+			return repsSets{}, false
+		}
+	}
+	if rounds < 1 {
+		return repsSets{}, false
+	}
+
+	result := repsSets{Protocol: protocol, Duration: seconds, Sets: make([]int, rounds)}
+	for i := range result.Sets {
+		result.Sets[i] = reps
+	}
+	return result, true
+}
+
+// formatInterval renders an interval value as "EMOM 10min @ 12", with the
+// completed rounds appended for AMRAP ("AMRAP 12min @ 15 x6").
+func formatInterval(r repsSets) string {
+	text := fmt.Sprintf("%s %s @ %d", r.Protocol, formatHold(r.Duration), r.Sets[0])
+	if r.Protocol == "AMRAP" {
+		text += fmt.Sprintf(" x%d", len(r.Sets))
+	}
+	return text
+}
+
+// promptInterval asks for a timed protocol and returns it in the stored
+// Reps×Sets form. Invalid input is re-prompted since there's no sensible
+// fallback for a duration or rep count.
+func promptInterval(reader *bufio.Reader) (string, error) {
+	promptln(msg("log.choose_protocol"))
+	for i, protocol := range intervalProtocols {
+		prompt("  %d. %s\n", i+1, protocol)
+	}
+	prompt(msg("log.enter_number"))
+	input, _ := reader.ReadString('\n')
+	choice, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || choice < 1 || choice > len(intervalProtocols) {
+		promptln(msg("log.invalid_protocol"))
+		choice = 1
+	}
+	protocol := intervalProtocols[choice-1]
+
+	var seconds int
+	for {
+		if protocol == "Tabata" {
+			prompt(msg("log.duration_default", formatHold(defaultTabataSeconds)))
+		} else {
+			prompt(msg("log.duration_prompt"))
+		}
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			return "", inputClosed()
+		}
+		input = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(input), " ", ""))
+		if input == "" && protocol == "Tabata" {
+			seconds = defaultTabataSeconds
+			break
+		}
+		if hold, ok := parseHold(input); ok && int(hold) >= minIntervalSeconds(protocol) {
+			seconds = int(hold)
+			break
+		}
+		prompt(msg("log.invalid_duration", formatHold(minIntervalSeconds(protocol))))
+	}
+
+	reps, err := promptPositiveInt(reader, msg("log.reps_per_round"))
+	if err != nil {
+		return "", err
+	}
+	value := fmt.Sprintf("%s %s @ %d", protocol, formatHold(seconds), reps)
+	if protocol == "AMRAP" {
+		rounds, err := promptPositiveInt(reader, msg("log.rounds_completed"))
+		if err != nil {
+			return "", err
+		}
+		value += fmt.Sprintf(" x%d", rounds)
+	}
+	return value, nil
+}
+
+// minIntervalSeconds is the shortest duration holding one full round.
+func minIntervalSeconds(protocol string) int {
+	switch protocol {
+	case "EMOM":
+		return 60
+	case "Tabata":
+		return tabataRoundSeconds
+	}
+	return 1
+}
+
+func promptPositiveInt(reader *bufio.Reader, label string) (int, error) {
+	for {
+		prompt(label)
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			return 0, inputClosed()
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(input))
+		if err == nil && n > 0 {
+			return n, nil
+		}
+		promptln(msg("log.whole_number"))
+	}
+}
+
+// workText renders the logged work for listings. Straight sets show the goal
+// next to the result, with the load of loaded sets; intervals show the
+// protocol and their total volume.
+func workText(entry WorkoutEntry) string {
+	if isInterval(entry) {
+		if parsed, ok := parseRepsSets(entry.RepsSets); ok {
+			return fmt.Sprintf("⏱ %s (%d reps)", entry.RepsSets, parsed.totalReps())
+		}
+		return "⏱ " + entry.RepsSets
+	}
+	return fmt.Sprintf("%s → %s", loggedWork(entry), entry.Goal)
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		value string
+		want  repsSets
+		text  string // formatted back
+	}{
+		{"EMOM 10min @ 12", repsSets{Protocol: "EMOM", Duration: 600, Sets: slices.Repeat([]int{12}, 10)}, "EMOM 10min @ 12"},
+		{"emom 10 min @ 12", repsSets{Protocol: "EMOM", Duration: 600, Sets: slices.Repeat([]int{12}, 10)}, "EMOM 10min @ 12"},
+		{"Tabata 4min @ 8", repsSets{Protocol: "Tabata", Duration: 240, Sets: slices.Repeat([]int{8}, 8)}, "Tabata 4min @ 8"},
+		{"AMRAP 12min @ 15 x6", repsSets{Protocol: "AMRAP", Duration: 720, Sets: slices.Repeat([]int{15}, 6)}, "AMRAP 12min @ 15 x6"},
+		{"EMOM 90s @ 5", repsSets{Protocol: "EMOM", Duration: 90, Sets: []int{5}}, "EMOM 90s @ 5"},
+	}
+	for _, tt := range tests {
+		got, ok := parseRepsSets(tt.value)
+		if !ok || got.Protocol != tt.want.Protocol || got.Duration != tt.want.Duration || !slices.Equal(got.Sets, tt.want.Sets) {
+			t.Errorf("parseRepsSets(%q) = %+v, %v, want %+v", tt.value, got, ok, tt.want)
+			continue
+		}
+		if text := formatRepsSets(got); text != tt.text {
+			t.Errorf("formatRepsSets(parseRepsSets(%q)) = %q, want %q", tt.value, text, tt.text)
+		}
+	}
+
+	for _, value := range []string{
+		"EMOM 10min",       // no reps per round
+		"EMOM 30s @ 5",     // not a full minute
+		"AMRAP 12min @ 15", // no rounds
+		"EMOM 10min @ 0",
+		"Circuit 10min @ 5",
+	} {
+		if got, ok := parseRepsSets(value); ok {
+			t.Errorf("parseRepsSets(%q) = %+v, want no value", value, got)
+		}
+	}
+}
+
+func TestPromptInterval(t *testing.T) {
+	quiet(t)
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1\n10min\n12\n", "EMOM 10min @ 12"},
+		{"3\n\n8\n", "Tabata 4min @ 8"}, // Tabata's default duration
+		{"2\n12min\n15\n6\n", "AMRAP 12min @ 15 x6"},
+		{"1\n30s\n10min\nmany\n12\n", "EMOM 10min @ 12"}, // too short, then not a number
+		{"9\n5min\n10\n", "EMOM 5min @ 10"},              // an unknown protocol falls back to EMOM
+	}
+	for _, tt := range tests {
+		got, err := promptInterval(bufio.NewReader(strings.NewReader(tt.input)))
+		if err != nil || got != tt.want {
+			t.Errorf("promptInterval(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	_, err := promptInterval(bufio.NewReader(strings.NewReader("1\n10min\n")))
+	var ce *cliError
+	if !errors.As(err, &ce) || ce.code != exitCancelled {
+		t.Errorf("promptInterval with input closed early = %v, want a cancellation", err)
+	}
+}
+
+func TestWorkTextInterval(t *testing.T) {
+	entry := WorkoutEntry{RepsSets: "EMOM 10min @ 12", Type: "interval"}
+	if got := workText(entry); got != "⏱ EMOM 10min @ 12 (120 reps)" {
+		t.Errorf("workText = %q", got)
+	}
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// defaultJournalFormat is the journal line without CALI_JOURNAL_FORMAT, e.g.
+// "2026-01-24: Day B — Pullups Full 8x2 (goal 10x2), Leg Raises Flat 18x2 ✓".
+const defaultJournalFormat = `{{.Date}}: {{if .Day}}Day {{.Day}} — {{end}}` +
+	`{{range $i, $e := .Entries}}{{if $i}}, {{end}}{{$e.Exercise}} {{$e.Level}} {{$e.Work}}` +
+	`{{if $e.GoalMet}} ✓{{else if $e.Goal}} (goal {{$e.Goal}}){{end}}{{end}}`
+
+// journalSession is what a journal line template is executed with: one
+// session, a single entry for cali -l and cali q, every entry of a template
+// run.
+type journalSession struct {
+	Date     string // the date as displayed, see CALI_DATE_FORMAT
+	Day      string // day letter of the first entry; empty for mobility work
+	Entries  []journalEntry
+	Total    int // number of entries
+	GoalsMet int // entries that met their goal
+	Reps     int // reps of rep-based work, intervals as rounds × reps
+	Minutes  int // the session's recorded duration; 0 when untimed
+}
+
+// journalEntry is one entry of a journalSession.
+type journalEntry struct {
+	Exercise string
+	Level    string
+	Work     string // "8x2", "45s", "20x2 +10kg" or an interval's summary
+	Goal     string // empty for intervals
+	GoalMet  bool
+	Load     string
+	Comment  string
+	Category string // strength or mobility
+}
+
+// newJournalSession summarizes entries, all logged in one session, for the
+// journal line.
+func newJournalSession(entries []WorkoutEntry) journalSession {
+	session := journalSession{Total: len(entries)}
+	for i, entry := range entries {
+		if i == 0 {
+			session.Date, session.Day = displayDate(entry.Date), entry.Day
+		}
+		met := !isInterval(entry) && meetsGoal(entry.RepsSets, entry.Goal)
+		if met {
+			session.GoalsMet++
+		}
+		work, goal := loggedWork(entry), entry.Goal
+		if isInterval(entry) {
+			work, goal = workText(entry), ""
+		}
+		if parsed, ok := parseRepsSets(entry.RepsSets); ok {
+			session.Reps += parsed.totalReps()
+		}
+		session.Minutes += entry.Duration
+		session.Entries = append(session.Entries, journalEntry{
+			Exercise: entry.Exercise,
+			Level:    entry.Level,
+			Work:     work,
+			Goal:     goal,
+			GoalMet:  met,
+			Load:     entry.Load,
+			Comment:  entry.Comment,
+			Category: calio.NormalizeCategory(entry.Category),
+		})
+	}
+	return session
+}
+
+// journalHook appends a line per session to a journal file. The zero
+// journalHook is off.
+type journalHook struct {
+	path string
+	line *template.Template
+}
+
+// journal is the hook in effect, set at startup.
+var journal journalHook
+
+// sampleJournalSession is what a template is tried on when it is loaded,
+// so a mistake shows then rather than after a workout.
+var sampleJournalSession = journalSession{
+	Date: "2026-01-24", Day: "B", Total: 2, GoalsMet: 1, Reps: 52, Minutes: 40,
+	Entries: []journalEntry{
+		{Exercise: "Pullups", Level: "Full", Work: "8x2", Goal: "10x2", Category: calio.CategoryStrength},
+		{Exercise: "Leg Raises", Level: "Flat", Work: "18x2", Goal: "15x2", GoalMet: true, Category: calio.CategoryStrength},
+	},
+}
+
+// configuredJournal reads CALI_JOURNAL, the journal file (off when empty),
+// and CALI_JOURNAL_FORMAT, a text/template for its line (see
+// journalSession; defaultJournalFormat when empty). The template is parsed
+// and run on a sample session, so unknown fields and functions are errors
+// here, even while CALI_JOURNAL is unset.
+func configuredJournal(getenv func(string) string) (journalHook, error) {
+	format := getenv("CALI_JOURNAL_FORMAT")
+	if strings.TrimSpace(format) == "" {
+		format = defaultJournalFormat
+	}
+	line, err := template.New("CALI_JOURNAL_FORMAT").Option("missingkey=error").Parse(format)
+	if err != nil {
+		return journalHook{}, fmt.Errorf("invalid CALI_JOURNAL_FORMAT: %w", err)
+	}
+	if err := line.Execute(io.Discard, sampleJournalSession); err != nil {
+		return journalHook{}, fmt.Errorf("invalid CALI_JOURNAL_FORMAT: %w", err)
+	}
+	path := strings.TrimSpace(getenv("CALI_JOURNAL"))
+	if path == "" {
+		return journalHook{}, nil
+	}
+	path, err = expandHome(path)
+	if err != nil {
+		return journalHook{}, fmt.Errorf("invalid CALI_JOURNAL: %w", err)
+	}
+	return journalHook{path: path, line: line}, nil
+}
+
+// render returns the journal line of entries. Line breaks, from the
+// template or a comment, become spaces so a session is always one line.
+func (j journalHook) render(entries []WorkoutEntry) (string, error) {
+	var b strings.Builder
+	if err := j.line.Execute(&b, newJournalSession(entries)); err != nil {
+		return "", err
+	}
+	line := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(b.String())
+	return strings.TrimSpace(line) + "\n", nil
+}
+
+// record appends the line of a session to the journal, creating the file
+// and its directory when needed. The line goes out in a single append, so
+// it never interleaves with other writers. Failing only warns: the
+// workout is saved either way.
+func (j journalHook) record(entries []WorkoutEntry) {
+	if j.line == nil || len(entries) == 0 {
+		return
+	}
+	err := func() error {
+		line, err := j.render(entries)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return err
+		}
+		if _, err := file.WriteString(line); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: writing the journal %s: %v\n", j.path, err)
+		return
+	}
+	detail("Journal: appended to %s\n", j.path)
+}
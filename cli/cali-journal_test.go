@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// journalWith returns the journal hook for format writing to path.
+func journalWith(t *testing.T, path, format string) journalHook {
+	t.Helper()
+	hook, err := configuredJournal(envOf(map[string]string{"CALI_JOURNAL": path, "CALI_JOURNAL_FORMAT": format}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hook
+}
+
+// TestJournalLine renders sessions with the default line and with templates
+// using the totals and entry fields.
+func TestJournalLine(t *testing.T) {
+	pullups := WorkoutEntry{Date: "2026-01-24", Day: "B", Exercise: "Pullups", Level: "Full", RepsSets: "8x2", Goal: "10x2", Category: calio.CategoryStrength}
+	legRaises := WorkoutEntry{Date: "2026-01-24", Day: "B", Exercise: "Leg Raises", Level: "Flat", RepsSets: "18x2", Goal: "15x2", Category: calio.CategoryStrength}
+	loaded := WorkoutEntry{Date: "2026-01-24", Day: "B", Exercise: "Pullups", Level: "Full", RepsSets: "10x2", Goal: "10x2", Load: "+10kg", Comment: "heavy\nbut fine"}
+	twist := WorkoutEntry{Date: "2026-01-25", Exercise: "Twist", Level: "Full", RepsSets: "60s", Category: calio.CategoryMobility}
+
+	tests := []struct {
+		format  string
+		entries []WorkoutEntry
+		want    string
+	}{
+		{"", []WorkoutEntry{pullups, legRaises}, "2026-01-24: Day B — Pullups Full 8x2 (goal 10x2), Leg Raises Flat 18x2 ✓\n"},
+		{"", []WorkoutEntry{twist}, "2026-01-25: Twist Full 60s\n"},
+		{"", []WorkoutEntry{loaded}, "2026-01-24: Day B — Pullups Full 10x2 +10kg ✓\n"},
+		{
+			"{{.Date}} {{.Total}} sets, {{.GoalsMet}} at goal, {{.Reps}} reps, {{.Minutes}}min",
+			[]WorkoutEntry{pullups, legRaises, loaded},
+			"2026-01-24 3 sets, 2 at goal, 72 reps, 0min\n",
+		},
+		{
+			"{{range .Entries}}[{{.Exercise}}|{{.Load}}|{{.Comment}}|{{.Category}}]{{end}}\n\n",
+			[]WorkoutEntry{loaded, twist},
+			"[Pullups|+10kg|heavy but fine|strength][Twist|||mobility]\n",
+		},
+	}
+	for _, tt := range tests {
+		got, err := journalWith(t, "journal.txt", tt.format).render(tt.entries)
+		if err != nil || got != tt.want {
+			t.Errorf("render(%q) = %q, %v, want %q", tt.format, got, err, tt.want)
+		}
+	}
+}
+
+// TestConfiguredJournal checks mistakes in the template are errors when the
+// config is read, even with the journal off.
+func TestConfiguredJournal(t *testing.T) {
+	for _, format := range []string{
+		"{{.Date",
+		"{{.Weather}}",
+		"{{range .Entries}}{{.Reps}}{{end}}",
+		"{{.Entries.Exercise}}",
+		"{{upper .Date}}",
+	} {
+		for _, path := range []string{"", "journal.txt"} {
+			_, err := configuredJournal(envOf(map[string]string{"CALI_JOURNAL": path, "CALI_JOURNAL_FORMAT": format}))
+			if err == nil || !strings.Contains(err.Error(), "invalid CALI_JOURNAL_FORMAT") {
+				t.Errorf("CALI_JOURNAL_FORMAT=%q with CALI_JOURNAL=%q: %v", format, path, err)
+			}
+		}
+	}
+
+	if hook, err := configuredJournal(envOf(nil)); err != nil || hook.line != nil {
+		t.Errorf("without CALI_JOURNAL: %+v, %v", hook, err)
+	}
+	t.Setenv("HOME", "/home/someone")
+	if hook, err := configuredJournal(envOf(map[string]string{"CALI_JOURNAL": " ~/notes/journal.txt "})); err != nil || hook.path != filepath.Join("/home/someone", "notes", "journal.txt") {
+		t.Errorf("~ expanded to %q, %v", hook.path, err)
+	}
+}
+
+// TestJournalRecord checks lines are appended to the file, created with its
+// directory, and that a failed write only warns.
+func TestJournalRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes", "journal.txt")
+	hook := journalWith(t, path, "{{.Date}} {{.Total}}")
+	entry := WorkoutEntry{Date: "2026-01-24", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2"}
+	hook.record([]WorkoutEntry{entry})
+	hook.record(nil)
+	hook.record([]WorkoutEntry{entry, entry})
+	if got, want := readFile(t, path), "2026-01-24 1\n2026-01-24 2\n"; got != want {
+		t.Errorf("journal = %q, want %q", got, want)
+	}
+
+	(journalHook{}).record([]WorkoutEntry{entry})
+
+	blocked := journalWith(t, t.TempDir(), "")
+	stderr := captureOutput(t, &os.Stderr, func() { blocked.record([]WorkoutEntry{entry}) })
+	if !strings.Contains(stderr, "Warning: writing the journal") {
+		t.Errorf("writing to a directory warned %q", stderr)
+	}
+}
+
+// TestJournalCommand logs through the cli and checks the journal gets a
+// line per session, one for a whole template run, and that a bad template stops every command.
+func TestJournalCommand(t *testing.T) {
+	pipedLog(t)
+	path := filepath.Join(t.TempDir(), "journal.txt")
+	t.Setenv("CALI_JOURNAL", path)
+	if _, stderr, code := runCLI(t, "", "log", "--day", "A", "--exercise", "Pushups", "--level", "Full", "--reps", "18x2", "--comment", "-"); code != 0 {
+		t.Fatalf("cali log exited %d: %s", code, stderr)
+	}
+	want := displayDate(currentTime().Format(calio.DateLayout)) + ": Day A — Pushups Full 18x2 (goal " + resolveGoal("Pushups", "Full") + ")\n"
+	if got := readFile(t, path); got != want {
+		t.Errorf("journal = %q, want %q", got, want)
+	}
+
+	// A template run is one session, so one line.
+	t.Setenv("CALI_TEMPLATE_legs", "Squats, Full, 20x2; Pushups, Full, 20x2")
+	if _, stderr, code := runCLI(t, "18\n20\n", "template", "legs"); code != 0 {
+		t.Fatalf("cali template legs exited %d: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(readFile(t, path), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], "Squats Full 18x2 (goal "+resolveGoal("Squats", "Full")+"), Pushups Full 20x2 ✓") {
+		t.Errorf("journal after the template run: %q", lines)
+	}
+
+	t.Setenv("CALI_JOURNAL_FORMAT", "{{.Sets}}")
+	if _, stderr, code := runCLI(t, "", "history"); code != exitUsage || !strings.Contains(stderr, "CALI_JOURNAL_FORMAT") {
+		t.Errorf("a bad template exited %d: %s", code, stderr)
+	}
+}
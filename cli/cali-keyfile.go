@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultKeyMaxAge is how old a service account key gets before cali
+// doctor warns, unless CALI_KEY_MAX_AGE says otherwise: a little under the
+// 90 days organizations commonly rotate keys after.
+const defaultKeyMaxAge = 80
+
+// serviceAccountKey is what cali reads from the credentials file of the
+// Sheets backend, to explain a key Google refuses.
+type serviceAccountKey struct {
+	Path        string
+	ClientEmail string
+	KeyID       string
+	// Created and Expires are the key's validity, when the file records it
+	// (valid_after_time and valid_before_time, as the IAM API names them);
+	// Google's own downloads don't, so they are usually zero.
+	Created  time.Time
+	Expires  time.Time
+	Modified time.Time
+}
+
+// sheetsKey is the key of the Sheets storage opened last, or nil when its
+// credentials weren't a readable service account key.
+var sheetsKey *serviceAccountKey
+
+// inspectKeyFile reads the service account key at path. Only the file is
+// read; nothing is sent to Google.
+func inspectKeyFile(path string) (serviceAccountKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return serviceAccountKey{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return serviceAccountKey{}, err
+	}
+	var file struct {
+		Type            string `json:"type"`
+		ClientEmail     string `json:"client_email"`
+		PrivateKeyID    string `json:"private_key_id"`
+		ValidAfterTime  string `json:"valid_after_time"`
+		ValidBeforeTime string `json:"valid_before_time"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return serviceAccountKey{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if file.Type != "service_account" {
+		return serviceAccountKey{}, fmt.Errorf("%s is not a service account key (type %q)", path, file.Type)
+	}
+	key := serviceAccountKey{
+		Path:        path,
+		ClientEmail: file.ClientEmail,
+		KeyID:       file.PrivateKeyID,
+		Modified:    info.ModTime(),
+	}
+	// A time that doesn't parse is left out rather than failing the command.
+	key.Created, _ = time.Parse(time.RFC3339, file.ValidAfterTime)
+	key.Expires, _ = time.Parse(time.RFC3339, file.ValidBeforeTime)
+	return key, nil
+}
+
+// issued returns when the key was made, as far as the file tells: its
+// creation time, or else when the file was last changed.
+func (k serviceAccountKey) issued() time.Time {
+	if !k.Created.IsZero() {
+		return k.Created
+	}
+	return k.Modified
+}
+
+// ageDays returns how many whole days before now the key was issued.
+func (k serviceAccountKey) ageDays(now time.Time) int {
+	return int(now.Sub(k.issued()).Hours() / 24)
+}
+
+// keyMaxAge returns CALI_KEY_MAX_AGE, the age in days ("90" or "90d")
+// after which cali doctor warns about the key; 0 turns the warning off.
+func keyMaxAge(getenv func(string) string) (int, error) {
+	raw := strings.TrimSpace(getenv("CALI_KEY_MAX_AGE"))
+	if raw == "" {
+		return defaultKeyMaxAge, nil
+	}
+	days, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(raw), "d"))
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("invalid CALI_KEY_MAX_AGE %q (use a number of days, e.g. 80d, or 0 for no warning)", raw)
+	}
+	return days, nil
+}
+
+// keyRefusedHint explains an error for which calio.AuthRefused holds: which
+// key file was used, when it changed, and how to replace a rotated key.
+// key is nil when the credentials weren't a service account key file.
+func keyRefusedHint(key *serviceAccountKey, now time.Time) string {
+	if key == nil {
+		return msg("error.auth_refused")
+	}
+	hint := msg("error.key_refused", key.Path, key.ClientEmail, key.KeyID,
+		key.Modified.Format("2006-01-02 15:04"), key.ageDays(now))
+	if !key.Expires.IsZero() && !now.Before(key.Expires) {
+		hint = msg("error.key_expired", key.Path, key.Expires.Format("2006-01-02")) + hint
+	}
+	return hint
+}
+
+// keyWarnings returns what cali doctor says about key: that it expired or
+// expires soon, or is older than maxAge days.
+func keyWarnings(key serviceAccountKey, now time.Time, maxAge int) []string {
+	var warnings []string
+	switch {
+	case key.Expires.IsZero():
+	case !now.Before(key.Expires):
+		warnings = append(warnings, msg("doctor.key_expired", key.Path, key.Expires.Format("2006-01-02")))
+	case key.Expires.Sub(now) < 14*24*time.Hour:
+		warnings = append(warnings, msg("doctor.key_expires", key.Path, key.Expires.Format("2006-01-02")))
+	}
+	if age := key.ageDays(now); maxAge > 0 && age > maxAge {
+		warnings = append(warnings, msg("doctor.key_old", key.Path, age, maxAge))
+	}
+	return warnings
+}
+
+// checkKeyAge prints keyWarnings for the credentials the Sheets backend
+// would use, before cali doctor opens the log: a key too old to work would
+// fail that first. It reports whether it warned, and is silent for other
+// backends and for credentials that aren't a service account key file.
+func checkKeyAge(now time.Time) (warned bool, err error) {
+	backend := strings.TrimSpace(os.Getenv("CALI_STORAGE"))
+	if backend != "" && backend != defaultBackend {
+		return false, nil
+	}
+	maxAge, err := keyMaxAge(os.Getenv)
+	if err != nil {
+		return false, usageError("%v", err)
+	}
+	cfg := loadSheetsConfig(os.Getenv, keyringStore)
+	if cfg.Credentials.Value == "" {
+		return false, nil
+	}
+	// A missing file is reported when the log is opened.
+	key, err := inspectKeyFile(cfg.Credentials.Value)
+	if err != nil {
+		detail("Not checking the key's age: %v\n", err)
+		return false, nil
+	}
+	warnings := keyWarnings(key, now, maxAge)
+	for _, warning := range warnings {
+		fmt.Print(warning)
+	}
+	return len(warnings) > 0, nil
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/auth"
+)
+
+// keyFixture copies the fake key testdata/keys/name to a file last changed
+// at modified, and returns its path.
+func keyFixture(t *testing.T, name string, modified time.Time) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "keys", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// keyNow is when the tests below look at their keys: 84 days after the IAM
+// key was created, 6 days before it expires.
+var keyNow = time.Date(2026, 3, 30, 10, 0, 0, 0, time.UTC)
+
+func TestInspectKeyFile(t *testing.T) {
+	modified := keyNow.AddDate(0, 0, -10)
+	path := keyFixture(t, "console.json", modified)
+	key, err := inspectKeyFile(path)
+	want := serviceAccountKey{
+		Path:        path,
+		ClientEmail: "cali-logger@cali-test.iam.gserviceaccount.com",
+		KeyID:       "0123456789abcdef0123456789abcdef01234567",
+		Modified:    modified,
+	}
+	if err != nil || !key.Modified.Equal(want.Modified) || key.Path != want.Path || key.ClientEmail != want.ClientEmail ||
+		key.KeyID != want.KeyID || !key.Created.IsZero() || !key.Expires.IsZero() {
+		t.Errorf("a key from the console reads as %+v, %v", key, err)
+	}
+	if !key.issued().Equal(modified) || key.ageDays(keyNow) != 10 {
+		t.Errorf("a key from the console was issued %v, %d days ago", key.issued(), key.ageDays(keyNow))
+	}
+
+	key, err = inspectKeyFile(keyFixture(t, "iam.json", keyNow))
+	created := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	if err != nil || !key.Created.Equal(created) || !key.Expires.Equal(created.AddDate(0, 3, 0)) || key.ClientEmail != "rotated@cali-test.iam.gserviceaccount.com" {
+		t.Errorf("a key from the IAM API reads as %+v, %v", key, err)
+	}
+	if !key.issued().Equal(created) || key.ageDays(keyNow) != 84 {
+		t.Errorf("a key from the IAM API was issued %v, %d days ago", key.issued(), key.ageDays(keyNow))
+	}
+
+	for name, want := range map[string]string{
+		"user.json":      `is not a service account key (type "authorized_user")`,
+		"truncated.json": "reading ",
+	} {
+		if _, err := inspectKeyFile(keyFixture(t, name, keyNow)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: %v, want %q", name, err, want)
+		}
+	}
+	if _, err := inspectKeyFile(filepath.Join(t.TempDir(), "gone.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a missing key: %v", err)
+	}
+}
+
+func TestKeyMaxAge(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  int
+		err   bool
+	}{
+		{"", defaultKeyMaxAge, false},
+		{"90", 90, false},
+		{" 30D ", 30, false},
+		{"0", 0, false},
+		{"-1", 0, true},
+		{"ninety", 0, true},
+		{"90 days", 0, true},
+	} {
+		got, err := keyMaxAge(envOf(map[string]string{"CALI_KEY_MAX_AGE": tt.value}))
+		if got != tt.want || (err != nil) != tt.err {
+			t.Errorf("CALI_KEY_MAX_AGE=%q: %d, %v", tt.value, got, err)
+		}
+	}
+}
+
+// TestKeyWarnings checks keys from the console by the age of their file,
+// and keys from the IAM API by their validity, before and after it ends.
+func TestKeyWarnings(t *testing.T) {
+	fresh, err := inspectKeyFile(keyFixture(t, "console.json", keyNow.AddDate(0, 0, -10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := inspectKeyFile(keyFixture(t, "console.json", keyNow.AddDate(0, 0, -100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iam, err := inspectKeyFile(keyFixture(t, "iam.json", keyNow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		key    serviceAccountKey
+		now    time.Time
+		maxAge int
+		want   []string
+	}{
+		{"fresh", fresh, keyNow, 80, nil},
+		{"old", old, keyNow, 80, []string{msg("doctor.key_old", old.Path, 100, 80)}},
+		{"old, no limit", old, keyNow, 0, nil},
+		{"old, 120 days allowed", old, keyNow, 120, nil},
+		{"valid", iam, keyNow.AddDate(0, -2, 0), 80, nil},
+		{"expiring", iam, keyNow, 90, []string{msg("doctor.key_expires", iam.Path, "2026-04-05")}},
+		{"expiring and old", iam, keyNow, 80, []string{
+			msg("doctor.key_expires", iam.Path, "2026-04-05"),
+			msg("doctor.key_old", iam.Path, 84, 80),
+		}},
+		{"expired", iam, keyNow.AddDate(0, 0, 6), 0, []string{msg("doctor.key_expired", iam.Path, "2026-04-05")}},
+	}
+	for _, tt := range tests {
+		if got := keyWarnings(tt.key, tt.now, tt.maxAge); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: keyWarnings = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestKeyRefusedHint(t *testing.T) {
+	if got := keyRefusedHint(nil, keyNow); got != msg("error.auth_refused") {
+		t.Errorf("without a key file: %q", got)
+	}
+	modified := time.Date(2025, 12, 20, 8, 15, 0, 0, time.Local)
+	old, err := inspectKeyFile(keyFixture(t, "console.json", modified))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := msg("error.key_refused", old.Path, "cali-logger@cali-test.iam.gserviceaccount.com",
+		"0123456789abcdef0123456789abcdef01234567", "2025-12-20 08:15", old.ageDays(keyNow))
+	if got := keyRefusedHint(&old, keyNow); got != want || !strings.Contains(got, old.Path) {
+		t.Errorf("a refused key from the console: %q, want %q", got, want)
+	}
+
+	iam, err := inspectKeyFile(keyFixture(t, "iam.json", keyNow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	later := keyNow.AddDate(0, 0, 7)
+	got := keyRefusedHint(&iam, later)
+	if !strings.HasPrefix(got, msg("error.key_expired", iam.Path, "2026-04-05")) || !strings.HasSuffix(got, "(or run cali auth).\n") {
+		t.Errorf("an expired key: %q", got)
+	}
+	if got := keyRefusedHint(&iam, keyNow); strings.Contains(got, "expired") {
+		t.Errorf("a valid key: %q", got)
+	}
+}
+
+// TestCheckKeyAge checks cali doctor warns about the Sheets key only, and
+// says nothing when the credentials can't be read.
+func TestCheckKeyAge(t *testing.T) {
+	saved := keyringStore
+	keyringStore = &fakeKeyring{}
+	t.Cleanup(func() { keyringStore = saved })
+	old := keyFixture(t, "console.json", keyNow.AddDate(0, 0, -100))
+	tests := []struct {
+		name, storage, credentials, maxAge string
+		warned                             bool
+		out                                string
+	}{
+		{"old", "", old, "", true, msg("doctor.key_old", old, 100, defaultKeyMaxAge)},
+		{"old, on sheets", "sheets", old, "120d", false, ""},
+		{"local log", "local", old, "", false, ""},
+		{"no credentials", "", "", "", false, ""},
+		{"not a key", "", keyFixture(t, "user.json", keyNow), "", false, ""},
+		{"missing file", "", filepath.Join(t.TempDir(), "gone.json"), "", false, ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("CALI_STORAGE", tt.storage)
+		t.Setenv("CALI_GOOGLE_CREDENTIALS_JSON", tt.credentials)
+		t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+		t.Setenv("CALI_KEY_MAX_AGE", tt.maxAge)
+		var warned bool
+		var err error
+		out := captureOutput(t, &os.Stdout, func() { warned, err = checkKeyAge(keyNow) })
+		if err != nil || warned != tt.warned || out != tt.out {
+			t.Errorf("%s: checkKeyAge = %v, %v, printing %q", tt.name, warned, err, out)
+		}
+	}
+
+	t.Setenv("CALI_STORAGE", "")
+	t.Setenv("CALI_KEY_MAX_AGE", "soon")
+	if _, err := checkKeyAge(keyNow); exitCode(err) != exitUsage {
+		t.Errorf("an invalid CALI_KEY_MAX_AGE: %v", err)
+	}
+}
+
+// TestKeyRefusedExit checks a refused key is explained with the key the
+// storage was opened with, and a token server that is down isn't.
+func TestKeyRefusedExit(t *testing.T) {
+	key, err := inspectKeyFile(keyFixture(t, "console.json", keyNow.AddDate(0, 0, -95)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := sheetsKey
+	sheetsKey = &key
+	t.Cleanup(func() { sheetsKey = saved })
+
+	refused := storageError("reading workout history", &auth.Error{Response: &http.Response{StatusCode: http.StatusBadRequest}, Body: []byte(`{"error":"invalid_grant"}`)})
+	var code int
+	stderr := captureOutput(t, &os.Stderr, func() { code = exitCode(refused) })
+	if code != exitStorage || !strings.Contains(stderr, key.Path) || !strings.Contains(stderr, "cali-logger@cali-test.iam.gserviceaccount.com") {
+		t.Errorf("a refused key exited %d: %s", code, stderr)
+	}
+
+	down := storageError("reading workout history", &auth.Error{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}})
+	if stderr := captureOutput(t, &os.Stderr, func() { exitCode(down) }); strings.Contains(stderr, key.Path) {
+		t.Errorf("a token server that is down was explained as a refused key: %s", stderr)
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// warmupTag marks a warm-up set in the comment, e.g. an easier variation
+// done before the working sets. Warm-ups are kept in the log but don't count
+// as training: history hides them, stats and records leave them out.
+const warmupTag = "#warmup"
+
+// Kinds of rows classifyEntry and the history filter tell apart. Rest days
+// aren't entries; history lists them as rows of kindRest when asked to.
+const (
+	kindWorking  = "working"
+	kindWarmup   = "warmups"
+	kindMobility = "mobility"
+	kindRest     = "rest"
+)
+
+// includableKinds are the kinds --include widens history with.
+var includableKinds = []string{kindWarmup, kindMobility, kindRest}
+
+// isWarmup reports whether entry carries warmupTag.
+func isWarmup(entry WorkoutEntry) bool {
+	for _, word := range strings.Fields(strings.ToLower(entry.Comment)) {
+		if word == warmupTag {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyEntry says what kind of work entry is, the one definition
+// history, stats, records and exports share: mobility by its category,
+// a warm-up by its tag, and working sets otherwise, deloads and intervals
+// included. Entries written before categories existed are working sets.
+func classifyEntry(entry WorkoutEntry) string {
+	switch {
+	case calio.IsMobility(entry):
+		return kindMobility
+	case isWarmup(entry):
+		return kindWarmup
+	}
+	return kindWorking
+}
+
+// kindFilter is the set of kinds a listing shows; working sets always are.
+type kindFilter map[string]bool
+
+// allKinds shows every kind, as history did before it filtered.
+func allKinds() kindFilter {
+	filter := kindFilter{}
+	for _, kind := range includableKinds {
+		filter[kind] = true
+	}
+	return filter
+}
+
+// String and Set make kindFilter the value of a repeatable, comma-separated
+// --include flag.
+func (f kindFilter) String() string {
+	var kinds []string
+	for _, kind := range includableKinds {
+		if f[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+	return strings.Join(kinds, ",")
+}
+
+func (f kindFilter) Set(value string) error {
+	for _, kind := range strings.Split(value, ",") {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if kind == "warmup" {
+			kind = kindWarmup
+		}
+		if !slices.Contains(includableKinds, kind) {
+			return fmt.Errorf("unknown kind %q (use %s)", kind, strings.Join(includableKinds, ", "))
+		}
+		f[kind] = true
+	}
+	return nil
+}
+
+// shows reports whether the filter keeps entry.
+func (f kindFilter) shows(entry WorkoutEntry) bool {
+	kind := classifyEntry(entry)
+	return kind == kindWorking || f[kind]
+}
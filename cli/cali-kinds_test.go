@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestClassifyEntry(t *testing.T) {
+	tests := []struct {
+		entry WorkoutEntry
+		want  string
+	}{
+		{WorkoutEntry{Exercise: "Pushups", Category: calio.CategoryStrength}, kindWorking},
+		// Written before categories existed.
+		{WorkoutEntry{Exercise: "Pushups"}, kindWorking},
+		{WorkoutEntry{Exercise: "Pushups", Comment: "easy #WarmUp"}, kindWarmup},
+		{WorkoutEntry{Exercise: "Pushups", Comment: "#warmups done"}, kindWorking},
+		{WorkoutEntry{Exercise: "Pushups", Comment: "#deload"}, kindWorking},
+		{WorkoutEntry{Exercise: "Squats", Type: calio.TypeInterval, RepsSets: "emom10min@12"}, kindWorking},
+		{WorkoutEntry{Exercise: "Bridge Hold", Category: calio.CategoryMobility}, kindMobility},
+		{WorkoutEntry{Exercise: "Bridge Hold", Category: calio.CategoryMobility, Comment: "#warmup"}, kindMobility},
+	}
+	for _, tt := range tests {
+		if got := classifyEntry(tt.entry); got != tt.want {
+			t.Errorf("classifyEntry(%+v) = %q, want %q", tt.entry, got, tt.want)
+		}
+	}
+}
+
+func TestKindFilter(t *testing.T) {
+	filter := kindFilter{}
+	for _, value := range []string{"warmup", " Rest , mobility"} {
+		if err := filter.Set(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if filter.String() != "warmups,mobility,rest" || filter.String() != allKinds().String() {
+		t.Errorf("filter = %q", filter.String())
+	}
+	if err := (kindFilter{}).Set("warmups,deloads"); err == nil || !strings.Contains(err.Error(), "deloads") {
+		t.Errorf("an unknown kind: %v", err)
+	}
+	warmup := WorkoutEntry{Exercise: "Pushups", Comment: "#warmup"}
+	if (kindFilter{}).shows(warmup) || !(kindFilter{kindWarmup: true}).shows(warmup) || !(kindFilter{}).shows(WorkoutEntry{Exercise: "Pushups"}) {
+		t.Error("shows doesn't follow the kinds")
+	}
+}
+
+// historyComments returns the comments tagged wN, mN or uN in a history
+// listing, in order: which working sets, mobility sessions and warm-ups it
+// shows.
+var historyTag = regexp.MustCompile(`\b[wmu]\d+\b`)
+
+func historyComments(stdout string) string {
+	return strings.Join(historyTag.FindAllString(stdout, -1), " ")
+}
+
+// TestHistoryFilter checks history counts its limit of 10 after filtering,
+// so a run of warm-ups doesn't push working sets out, and what --include
+// and --all-types add.
+func TestHistoryFilter(t *testing.T) {
+	ctx := context.Background()
+	storage := pipedLog(t)
+	day := func(n int) string { return currentTime().AddDate(0, 0, n-15).Format(calio.DateLayout) }
+	var entries []WorkoutEntry
+	for i := 1; i <= 12; i++ {
+		entries = append(entries, WorkoutEntry{Date: day(i), Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Comment: fmt.Sprintf("w%d", i), Category: calio.CategoryStrength})
+	}
+	for i := 1; i <= 3; i++ {
+		entries = append(entries, WorkoutEntry{Date: day(12), Exercise: "Bridge Hold", Level: "Short", RepsSets: "30s", Comment: fmt.Sprintf("m%d", i), Category: calio.CategoryMobility})
+	}
+	// More warm-ups than history reads at first, after the last working set.
+	for i := 1; i <= 45; i++ {
+		entries = append(entries, WorkoutEntry{Date: day(13), Day: "A", Exercise: "Pushups", Level: "Kneeling", RepsSets: "10x1", Comment: fmt.Sprintf("u%d #warmup", i), Category: calio.CategoryStrength})
+	}
+	if _, err := storage.AppendBatch(ctx, entries); err != nil {
+		t.Fatal(err)
+	}
+	for _, date := range []string{day(5), day(14)} {
+		if err := storage.AddRest(ctx, calio.RestDay{Date: date, Reason: "travel"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	working := "w3 w4 w5 w6 w7 w8 w9 w10 w11 w12"
+	tests := []struct {
+		args []string
+		want string
+		rest int
+	}{
+		{[]string{"history"}, working, 0},
+		{[]string{"-p"}, working, 0},
+		{[]string{"history", "--include", "mobility"}, "w6 w7 w8 w9 w10 w11 w12 m1 m2 m3", 0},
+		{[]string{"history", "--include", "warmups"}, "u36 u37 u38 u39 u40 u41 u42 u43 u44 u45", 0},
+		{[]string{"history", "--all-types"}, "u36 u37 u38 u39 u40 u41 u42 u43 u44 u45", 1},
+		// Rest days don't count towards the limit.
+		{[]string{"history", "--include", "rest"}, working, 1},
+		{[]string{"history", "--since", day(10), "--until", day(12), "--include", "mobility,rest"}, "w10 w11 w12 m1 m2 m3", 0},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runCLI(t, "", tt.args...)
+		if code != 0 {
+			t.Errorf("cali %s exited %d: %s", strings.Join(tt.args, " "), code, stderr)
+			continue
+		}
+		if got := historyComments(stdout); got != tt.want {
+			t.Errorf("cali %s lists %q, want %q", strings.Join(tt.args, " "), got, tt.want)
+		}
+		if got := strings.Count(stdout, "| Rest |"); got != tt.rest {
+			t.Errorf("cali %s lists %d rest day(s), want %d:\n%s", strings.Join(tt.args, " "), got, tt.rest, stdout)
+		}
+	}
+	if _, _, code := runCLI(t, "", "history", "--include", "deloads"); code != exitUsage {
+		t.Errorf("an unknown kind exited %d", code)
+	}
+
+	// Stats agree with history on what counts.
+	stats := computeStats(logged(t, storage), currentTime())
+	if stats.Total != 12 || stats.Warmups != 45 || stats.Mobility != 3 {
+		t.Errorf("stats count %d working, %d warm-ups, %d mobility", stats.Total, stats.Warmups, stats.Mobility)
+	}
+}
+
+// TestHistoryLegacy checks --all-types lists a log written before
+// categories and the filter the way history always did: the last 10 rows,
+// whatever they are.
+func TestHistoryLegacy(t *testing.T) {
+	storage := pipedLog(t)
+	files := map[string]string{}
+	for i := 1; i <= 12; i++ {
+		comment := fmt.Sprintf("w%d", i)
+		if i%4 == 0 {
+			comment = fmt.Sprintf("u%d #warmup", i)
+		}
+		date := currentTime().AddDate(0, 0, i-13).Format(calio.DateLayout)
+		files[storage.FileFor(date)] += date + "|A|Pushups|Full|10x2|20x2|" + comment + "\n"
+	}
+	if err := os.MkdirAll(storage.Dir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, lines := range files {
+		if err := os.WriteFile(name, []byte(lines), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stdout, _, code := runCLI(t, "", "history", "--all-types")
+	if want := "w3 u4 w5 w6 w7 u8 w9 w10 w11 u12"; code != 0 || historyComments(stdout) != want {
+		t.Errorf("--all-types exited %d listing %q, want %q", code, historyComments(stdout), want)
+	}
+	stdout, _, _ = runCLI(t, "", "history")
+	if want := "w1 w2 w3 w5 w6 w7 w9 w10 w11"; historyComments(stdout) != want {
+		t.Errorf("history lists %q, want %q", historyComments(stdout), want)
+	}
+}
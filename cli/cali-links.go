@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OSC 8 hyperlinks: text between an opening sequence carrying the URL and
+// an empty one is a link in terminals that support them.
+const (
+	osc8Start      = "\033]8;;"
+	stringTerminal = "\033\\"
+)
+
+// hyperlink wraps text in an OSC 8 link to url.
+func hyperlink(text, url string) string {
+	return osc8Start + url + stringTerminal + text + osc8Start + stringTerminal
+}
+
+// Values of CALI_HYPERLINKS.
+const (
+	hyperlinksAuto   = "auto"
+	hyperlinksAlways = "always"
+	hyperlinksNever  = "never"
+)
+
+// hyperlinkSupport decides whether output to a terminal gets links, from
+// CALI_HYPERLINKS (auto, always or never; default auto) and, for auto, what
+// the terminal says about itself. terminal is whether the output is one.
+// Terminals don't advertise OSC 8 support, so auto goes by the ones known
+// to have it; the others would print the link's URL as garbage, or nothing.
+func hyperlinkSupport(getenv func(string) string, terminal bool) (bool, error) {
+	switch setting := strings.ToLower(strings.TrimSpace(getenv("CALI_HYPERLINKS"))); setting {
+	case hyperlinksAlways:
+		return true, nil
+	case hyperlinksNever:
+		return false, nil
+	case "", hyperlinksAuto:
+	default:
+		return false, fmt.Errorf("invalid CALI_HYPERLINKS %q (use %s, %s or %s)", setting, hyperlinksAuto, hyperlinksAlways, hyperlinksNever)
+	}
+	term := getenv("TERM")
+	if !terminal || term == "dumb" || getenv("CI") != "" {
+		return false, nil
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "Tabby", "rio":
+		return true, nil
+	}
+	if vte, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true, nil
+	}
+	for _, name := range []string{"WT_SESSION", "KONSOLE_VERSION", "KITTY_WINDOW_ID", "DOMTERM"} {
+		if getenv(name) != "" {
+			return true, nil
+		}
+	}
+	for _, known := range []string{"kitty", "alacritty", "foot", "ghostty", "wezterm"} {
+		if strings.Contains(term, known) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// linker renders tutorial links in a listing: as hyperlinks when enabled,
+// as the plain text otherwise.
+type linker struct {
+	enabled bool
+}
+
+// newLinker returns the linker for output to w. An invalid CALI_HYPERLINKS
+// only warns: the listing is still worth printing without links.
+func newLinker(w io.Writer) linker {
+	f, ok := w.(*os.File)
+	enabled, err := hyperlinkSupport(os.Getenv, ok && isTerminal(f))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return linker{enabled: enabled}
+}
+
+// link returns text linked to url, or text alone when links are off or
+// there is no url.
+func (l linker) link(text, url string) string {
+	if !l.enabled || url == "" {
+		return text
+	}
+	return hyperlink(text, url)
+}
+
+// tutorialLink returns the level name linked to its tutorial.
+func (l linker) tutorialLink(exercise, level string) string {
+	return l.link(level, resolveTutorial(exercise, level))
+}
+
+// stripEscapes removes terminal escape sequences from s: CSI sequences such
+// as colors ("\033[1;7m") and OSC sequences such as hyperlinks, ended by
+// BEL or ST. What remains is what the terminal shows.
+func stripEscapes(s string) string {
+	if !strings.Contains(s, "\033") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\033' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '[':
+			// Parameters and intermediates up to the final byte, @ to ~.
+			i += 2
+			for i < len(s) && (s[i] < '@' || s[i] > '~') {
+				i++
+			}
+		case ']':
+			i += 2
+			for i < len(s) && s[i] != '\a' && !(s[i] == '\033' && i+1 < len(s) && s[i+1] == '\\') {
+				i++
+			}
+			if i < len(s) && s[i] == '\033' {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	return b.String()
+}
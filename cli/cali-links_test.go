@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHyperlink(t *testing.T) {
+	got := hyperlink("Wall Headstand", "https://example.com/v")
+	if want := "\033]8;;https://example.com/v\033\\Wall Headstand\033]8;;\033\\"; got != want {
+		t.Errorf("hyperlink = %q, want %q", got, want)
+	}
+	if stripEscapes(got) != "Wall Headstand" {
+		t.Errorf("stripEscapes(%q) = %q", got, stripEscapes(got))
+	}
+	if got := (linker{}).link("Wall", "https://example.com/v"); got != "Wall" {
+		t.Errorf("a disabled linker returned %q", got)
+	}
+	if got := (linker{enabled: true}).link("Wall", ""); got != "Wall" {
+		t.Errorf("a level without a tutorial returned %q", got)
+	}
+}
+
+// TestHyperlinkSupport checks the setting overrides the guess, and that
+// auto goes by the terminal only when output is one.
+func TestHyperlinkSupport(t *testing.T) {
+	tests := []struct {
+		env      map[string]string
+		terminal bool
+		want     bool
+	}{
+		{nil, true, false},
+		{map[string]string{"TERM": "xterm-256color"}, true, false},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, true, true},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, false, false},
+		{map[string]string{"TERM_PROGRAM": "Apple_Terminal"}, true, false},
+		{map[string]string{"TERM": "xterm-kitty"}, true, true},
+		{map[string]string{"TERM": "dumb", "TERM_PROGRAM": "WezTerm"}, true, false},
+		{map[string]string{"VTE_VERSION": "7200"}, true, true},
+		{map[string]string{"VTE_VERSION": "4205"}, true, false},
+		{map[string]string{"WT_SESSION": "b7f3"}, true, true},
+		{map[string]string{"WT_SESSION": "b7f3", "CI": "true"}, true, false},
+		{map[string]string{"CALI_HYPERLINKS": "always"}, false, true},
+		{map[string]string{"CALI_HYPERLINKS": " Always "}, false, true},
+		{map[string]string{"CALI_HYPERLINKS": "never", "TERM_PROGRAM": "iTerm.app"}, true, false},
+		{map[string]string{"CALI_HYPERLINKS": "auto", "KITTY_WINDOW_ID": "1"}, true, true},
+	}
+	for _, tt := range tests {
+		got, err := hyperlinkSupport(envOf(tt.env), tt.terminal)
+		if err != nil || got != tt.want {
+			t.Errorf("hyperlinkSupport(%v, terminal %v) = %v, %v, want %v", tt.env, tt.terminal, got, err, tt.want)
+		}
+	}
+	if got, err := hyperlinkSupport(envOf(map[string]string{"CALI_HYPERLINKS": "yes", "TERM_PROGRAM": "iTerm.app"}), true); err == nil || got {
+		t.Errorf("CALI_HYPERLINKS=yes gave %v, %v", got, err)
+	}
+}
+
+func TestStripEscapes(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{"\033[1;7mFull\033[0m", "Full"},
+		{"\033]8;;https://example.com\aWall\033]8;;\a", "Wall"},
+		{"\033[7m" + hyperlink("Assisted One-Leg", "https://example.com") + "\033[0m*", "Assisted One-Leg*"},
+		// A sequence cut short hides the rest, as in a terminal.
+		{"Wall\033]8;;https://exa", "Wall"},
+		{"Wall\033", "Wall\033"},
+	}
+	for _, tt := range tests {
+		if got := stripEscapes(tt.in); got != tt.want {
+			t.Errorf("stripEscapes(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestLinkedCellWidth checks linked multi-word level names pad to the same
+// width as plain ones.
+func TestLinkedCellWidth(t *testing.T) {
+	links := linker{enabled: true}
+	for _, name := range []string{"Wall Headstand", "Half One-Arm", "Assisted One-Leg", "Ü-Kniebeuge"} {
+		linked := links.link(name, "https://www.youtube.com/watch?v=abc")
+		if cellWidth(linked) != cellWidth(name) {
+			t.Errorf("cellWidth(%q) = %d, want %d", linked, cellWidth(linked), cellWidth(name))
+		}
+		if got := stripEscapes(padCell(linked, 20)); got != padCell(name, 20) {
+			t.Errorf("padCell of linked %q = %q, want %q", name, got, padCell(name, 20))
+		}
+	}
+}
+
+// TestLevelMatrixLinks checks the linked charts are the plain ones once the
+// links are stripped, at full and narrow widths, and that the linked chart
+// itself is as golden.
+func TestLevelMatrixLinks(t *testing.T) {
+	for _, tt := range []struct {
+		golden  string
+		current map[string]string
+		color   bool
+		width   int
+	}{
+		{"matrix.txt", matrixCurrent, false, 200},
+		{"matrix.color.txt", matrixCurrent, true, 200},
+		{"matrix.narrow.txt", nil, false, 80},
+	} {
+		m := buildLevelMatrix(tt.current)
+		m.Links = linker{enabled: true}
+		var linked, plain strings.Builder
+		if err := m.writeText(&linked, tt.color, tt.width); err != nil {
+			t.Fatal(err)
+		}
+		m.Links = linker{}
+		if err := m.writeText(&plain, tt.color, tt.width); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(linked.String(), osc8Start+"https://") {
+			t.Errorf("%s: no links", tt.golden)
+		}
+		if tt.color {
+			// Colors are escapes too; compare with them in place.
+			if got, want := stripLinks(linked.String()), plain.String(); got != want {
+				t.Errorf("%s: the linked chart is not the plain one:\n%s\nwant:\n%s", tt.golden, got, want)
+			}
+			continue
+		}
+		if got, want := stripEscapes(linked.String()), plain.String(); got != want {
+			t.Errorf("%s: the linked chart is not the plain one:\n%s\nwant:\n%s", tt.golden, got, want)
+		}
+		if tt.golden == "matrix.txt" {
+			checkGolden(t, "matrix.links.txt", linked.String())
+		}
+	}
+}
+
+// stripLinks removes the OSC 8 sequences of hyperlink from s, keeping any
+// other escapes.
+func stripLinks(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, osc8Start)
+		if start < 0 {
+			return b.String() + s
+		}
+		b.WriteString(s[:start])
+		end := strings.Index(s[start:], stringTerminal)
+		s = s[start+end+len(stringTerminal):]
+	}
+}
+
+// TestChooseLevelLinks checks the level chooser links level names with
+// CALI_HYPERLINKS=always, and keeps its columns aligned.
+func TestChooseLevelLinks(t *testing.T) {
+	withLevelPins(t, nil)
+	menu := func(setting string) string {
+		t.Setenv("CALI_HYPERLINKS", setting)
+		return captureOutput(t, &os.Stdout, func() {
+			chooseLevel(bufio.NewReader(strings.NewReader("1\n")), "Squats", "", false, true)
+		})
+	}
+	linked, plain := menu("always"), menu("never")
+	if !strings.Contains(linked, hyperlink("Assisted One-Leg", resolveTutorial("Squats", "Assisted One-Leg"))) {
+		t.Errorf("the chooser doesn't link Assisted One-Leg:\n%q", linked)
+	}
+	if strings.Contains(plain, "\033") {
+		t.Errorf("CALI_HYPERLINKS=never printed escapes:\n%q", plain)
+	}
+	if stripEscapes(linked) != plain {
+		t.Errorf("the linked chooser is not the plain one:\n%s\nwant:\n%s", stripEscapes(linked), plain)
+	}
+}
+
+// TestTutorialsLinks checks cali tutorials links level names only when
+// asked to, since its output in tests is not a terminal, and warns about an
+// invalid setting.
+func TestTutorialsLinks(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CALI_HYPERLINKS", "always")
+	linked, stderr, code := runCLIIn(t, home, "", "tutorials", "Pushups")
+	if code != 0 {
+		t.Fatalf("cali tutorials exited %d: %s", code, stderr)
+	}
+	if want := hyperlink("Wall", resolveTutorial("Pushups", "Wall")); !strings.Contains(linked, want) {
+		t.Errorf("cali tutorials doesn't link Wall:\n%q", linked)
+	}
+
+	t.Setenv("CALI_HYPERLINKS", "")
+	plain, _, _ := runCLIIn(t, home, "", "tutorials", "Pushups")
+	if strings.Contains(plain, "\033") || stripEscapes(linked) != plain {
+		t.Errorf("piped output:\n%q\nwant the linked output without links", plain)
+	}
+
+	t.Setenv("CALI_HYPERLINKS", "sometimes")
+	stdout, stderr, code := runCLIIn(t, home, "", "tutorials", "Pushups")
+	if code != 0 || stdout != plain || !strings.Contains(stderr, "invalid CALI_HYPERLINKS") {
+		t.Errorf("CALI_HYPERLINKS=sometimes exited %d, printed %q, warned %q", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// loadedTrainingEnabled reports whether the interactive log asks for added
+// weight after the reps, for people who train with a vest or a dip belt.
+// Without it, only --load records one.
+func loadedTrainingEnabled() bool {
+	return envEnabled("CALI_LOADED")
+}
+
+// loadUnit returns CALI_LOAD_UNIT, the unit of a load typed without one:
+// kg (the default) or lb.
+func loadUnit() (string, error) {
+	raw := strings.ToLower(strings.TrimSpace(os.Getenv("CALI_LOAD_UNIT")))
+	switch raw {
+	case "", calio.LoadKg, "kgs":
+		return calio.LoadKg, nil
+	case calio.LoadLb, "lbs":
+		return calio.LoadLb, nil
+	}
+	return "", fmt.Errorf("invalid CALI_LOAD_UNIT %q (use kg or lb)", raw)
+}
+
+// parseLoadInput reads a load typed at the prompt or given to --load and
+// returns it as stored, e.g. "+10kg"; no load is "".
+func parseLoadInput(input string) (string, error) {
+	unit, err := loadUnit()
+	if err != nil {
+		return "", err
+	}
+	load, ok := calio.ParseLoad(input, unit)
+	if !ok {
+		return "", fmt.Errorf("%s", msg("log.load_invalid", strings.TrimSpace(input)))
+	}
+	return load.String(), nil
+}
+
+// promptLoad asks for the weight added to the set until it reads one;
+// Enter alone means none.
+func promptLoad(reader *bufio.Reader) (string, error) {
+	for {
+		prompt(msg("log.load_prompt"))
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			return "", inputClosed()
+		}
+		load, parseErr := parseLoadInput(input)
+		if parseErr == nil {
+			return load, nil
+		}
+		promptln(parseErr)
+	}
+}
+
+// entryLoad reads the load stored with entry. Loads typed into the sheet by
+// hand without a unit count in the configured one; anything unreadable
+// counts as no load.
+func entryLoad(entry WorkoutEntry) calio.Load {
+	unit, err := loadUnit()
+	if err != nil {
+		unit = calio.LoadKg
+	}
+	load, _ := calio.ParseLoad(entry.Load, unit)
+	return load
+}
+
+// loggedWork renders the work of entry with its load, e.g. "30x2 +10kg".
+func loggedWork(entry WorkoutEntry) string {
+	if entry.Load == "" {
+		return entry.RepsSets
+	}
+	return entry.RepsSets + " " + entry.Load
+}
@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestLoadUnit(t *testing.T) {
+	tests := []struct {
+		env, want string
+		ok        bool
+	}{
+		{"", calio.LoadKg, true},
+		{"KG", calio.LoadKg, true},
+		{"kgs", calio.LoadKg, true},
+		{" lb ", calio.LoadLb, true},
+		{"lbs", calio.LoadLb, true},
+		{"stone", "", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("CALI_LOAD_UNIT", tt.env)
+		got, err := loadUnit()
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("CALI_LOAD_UNIT=%q: loadUnit() = %q, %v", tt.env, got, err)
+		}
+	}
+}
+
+func TestParseLoadInput(t *testing.T) {
+	tests := []struct {
+		unit, input, want string
+		ok                bool
+	}{
+		{"", "10", "+10kg", true},
+		{"lb", "10", "+10lb", true},
+		{"lb", "+5kg", "+5kg", true},
+		{"", "  \n", "", true},
+		{"", "none", "", true},
+		{"", "-5", "", false},
+		{"stone", "10", "", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("CALI_LOAD_UNIT", tt.unit)
+		got, err := parseLoadInput(tt.input)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("CALI_LOAD_UNIT=%q: parseLoadInput(%q) = %q, %v", tt.unit, tt.input, got, err)
+		}
+	}
+
+	t.Setenv("CALI_LOAD_UNIT", "")
+	var got string
+	asked := captureOutput(t, &os.Stdout, func() {
+		var err error
+		got, err = promptLoad(bufio.NewReader(strings.NewReader("a vest\n12.5\n")))
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	if got != "+12.5kg" || !strings.Contains(asked, "a vest") {
+		t.Errorf("promptLoad = %q after asking:\n%s", got, asked)
+	}
+	captureOutput(t, &os.Stdout, func() {
+		if got, err := promptLoad(bufio.NewReader(strings.NewReader("\n"))); got != "" || err != nil {
+			t.Errorf("Enter alone: promptLoad = %q, %v", got, err)
+		}
+	})
+}
+
+// TestWorkRank pins down the record ordering: the work first, so more reps
+// or a longer hold always win; at the same work, the heavier load in
+// kilograms, whatever unit it was logged in.
+func TestWorkRank(t *testing.T) {
+	t.Setenv("CALI_LOAD_UNIT", "")
+	entry := func(reps, load string) WorkoutEntry {
+		return WorkoutEntry{Exercise: "Squats", Level: "Full", RepsSets: reps, Load: load}
+	}
+	tests := []struct {
+		name    string
+		a, b    WorkoutEntry
+		aBeatsB bool
+		bBeatsA bool
+	}{
+		{"loaded over unloaded", entry("20x2", "+10kg"), entry("20x2", ""), true, false},
+		{"heavier load", entry("20x2", "+15kg"), entry("20x2", "+10kg"), true, false},
+		{"more reps over any load", entry("21x2", ""), entry("20x2", "+50kg"), true, false},
+		{"the same work and load", entry("20x2", "+10kg"), entry("20x2", "+10kg"), false, false},
+		{"pounds converted", entry("20x2", "+25lb"), entry("20x2", "+10kg"), true, false},
+		{"bare number in the configured unit", entry("20x2", "10"), entry("20x2", "+10kg"), false, false},
+		{"unreadable load is none", entry("20x2", "vest"), entry("20x2", ""), false, false},
+		{"longer hold", entry("60s", ""), entry("45s", "+20kg"), true, false},
+	}
+	for _, tt := range tests {
+		a, okA := entryRank(tt.a)
+		b, okB := entryRank(tt.b)
+		if !okA || !okB {
+			t.Errorf("%s: no rank", tt.name)
+			continue
+		}
+		if a.beats(b) != tt.aBeatsB || b.beats(a) != tt.bBeatsA {
+			t.Errorf("%s: %+v beats %+v = %v, the other way %v", tt.name, a, b, a.beats(b), b.beats(a))
+		}
+	}
+	if _, ok := entryRank(entry("emom10min@12", "+10kg")); ok {
+		t.Error("an interval ranked")
+	}
+
+	t.Setenv("CALI_LOAD_UNIT", "lb")
+	bare, _ := entryRank(entry("20x2", "10"))
+	kilos, _ := entryRank(entry("20x2", "+10kg"))
+	if !kilos.beats(bare) {
+		t.Errorf("with CALI_LOAD_UNIT=lb, 10 ranks as %v kg", bare.load)
+	}
+}
+
+// TestLoadedRecords checks a loaded session is the record over the same
+// work unloaded, and counts as progress out of a plateau.
+func TestLoadedRecords(t *testing.T) {
+	t.Setenv("CALI_LOAD_UNIT", "")
+	withGoalOverrides(t, nil)
+	squat := func(date, reps, load string) WorkoutEntry {
+		return WorkoutEntry{Date: date, Day: "A", Exercise: "Squats", Level: "Full", RepsSets: reps, Goal: resolveGoal("Squats", "Full"), Load: load}
+	}
+	entries := []WorkoutEntry{
+		squat("2026-03-01", "20x2", ""),
+		squat("2026-03-02", "20x2", "+10kg"),
+		squat("2026-03-03", "20x2", "+10kg"),
+		squat("2026-03-04", "20x2", ""),
+	}
+	record := personalRecords(entries)[exerciseLevel{"Squats", "Full"}]
+	if record.Date != "2026-03-02" || record.Load != "+10kg" {
+		t.Errorf("the record is %+v, want the first loaded session", record)
+	}
+
+	stuck := append([]WorkoutEntry{squat("2026-02-20", "20x2", "")}, entries...)
+	stuck = append(stuck, squat("2026-03-05", "20x2", ""))
+	if got := plateaus(stuck); len(got) != 1 {
+		t.Errorf("plateaus = %v after three sessions short of the loaded best", got)
+	}
+	stuck[len(stuck)-1].Load = "+12.5kg"
+	if got := plateaus(stuck); len(got) != 0 {
+		t.Errorf("plateaus = %v after a heavier session", got)
+	}
+}
+
+// TestGoalIgnoresLoad checks the standards are judged on the work alone:
+// a load neither makes up for missing reps nor is needed to meet them.
+func TestGoalIgnoresLoad(t *testing.T) {
+	withGoalOverrides(t, nil)
+	goal := resolveGoal("Squats", "Full")
+	for _, tt := range []struct {
+		reps, load string
+		want       bool
+	}{
+		{goal, "", true},
+		{goal, "+20kg", true},
+		{"5x2", "+40kg", false},
+	} {
+		entry := WorkoutEntry{Exercise: "Squats", Level: "Full", RepsSets: tt.reps, Goal: goal, Load: tt.load}
+		if got := reachesGoal(entry); got != tt.want {
+			t.Errorf("%s %s: reachesGoal = %v, want %v", tt.reps, tt.load, got, tt.want)
+		}
+	}
+}
+
+// TestLoadCommand logs loaded sets with --load and checks what is stored,
+// what is said about the standards and how history shows the load.
+func TestLoadCommand(t *testing.T) {
+	storage := pipedLog(t)
+	base := []string{"log", "--day", "A", "--exercise", "Squats", "--level", "Full", "--reps", "30x2", "--comment", "-"}
+	stdout, stderr, code := runCLI(t, "", append(base, "--load", "10")...)
+	if code != 0 {
+		t.Fatalf("cali log --load exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout+stderr, "30x2 +10kg") || !strings.Contains(stdout+stderr, strings.TrimSpace(msg("log.load_not_counted"))) {
+		t.Errorf("cali log --load said:\n%s%s", stdout, stderr)
+	}
+	t.Setenv("CALI_LOAD_UNIT", "lb")
+	if _, stderr, code := runCLI(t, "", append(base, "--load", "22.5")...); code != 0 {
+		t.Fatalf("cali log --load exited %d: %s", code, stderr)
+	}
+	if _, stderr, code := runCLI(t, "", base...); code != 0 {
+		t.Fatalf("cali log exited %d: %s", code, stderr)
+	}
+	var loads []string
+	for _, entry := range logged(t, storage) {
+		loads = append(loads, entry.Load)
+	}
+	if strings.Join(loads, ",") != "+10kg,+22.5lb," {
+		t.Errorf("stored loads %q", loads)
+	}
+	stdout, _, _ = runCLI(t, "", "history")
+	if !strings.Contains(stdout, "30x2 +10kg") || !strings.Contains(stdout, "30x2 +22.5lb") {
+		t.Errorf("history doesn't show the loads:\n%s", stdout)
+	}
+
+	for _, args := range [][]string{
+		append(base, "--load", "a vest"),
+		append(base, "--load", "-10kg"),
+		{"log", "--interval", "--load", "10kg"},
+	} {
+		if _, _, code := runCLI(t, "", args...); code != exitUsage {
+			t.Errorf("cali %s exited %d, want %d", strings.Join(args, " "), code, exitUsage)
+		}
+	}
+	t.Setenv("CALI_LOAD_UNIT", "stone")
+	if _, _, code := runCLI(t, "", append(base, "--load", "10")...); code != exitUsage {
+		t.Errorf("an invalid CALI_LOAD_UNIT exited %d", code)
+	}
+	if n := len(logged(t, storage)); n != 3 {
+		t.Errorf("rejected loads left %d entries", n)
+	}
+}
@@ -0,0 +1,1357 @@
+// Package cli is the cali command line: every command, its flags and
+// output. The cali binary is a main calling Run; another module can do the
+// same after registering its own storage backends (see
+// calio.RegisterBackend) to get the whole CLI on top of them.
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// The log entry and the storage interface live in package calio, which
+// other programs can import to read the same log.
+type (
+	WorkoutEntry = calio.WorkoutEntry
+	Storage      = calio.Storage
+)
+
+// Run runs the command in args (os.Args[1:]) and returns its exit code (see
+// cali-exit.go) for main to exit with. It only exits itself when a
+// cancelled command fails to wind down (see commandContext).
+func Run(args []string) int {
+	if err := calio.ValidateDataset(); err != nil {
+		fmt.Fprintf(os.Stderr, "Exercise dataset error: %v\n", err)
+		return exitInternal
+	}
+	if err := validateStandards(); err != nil {
+		fmt.Fprintf(os.Stderr, "Progression standards error: %v\n", err)
+		return exitInternal
+	}
+	if err := validateCatalogs(); err != nil {
+		fmt.Fprintf(os.Stderr, "Message catalog error: %v\n", err)
+		return exitInternal
+	}
+
+	if configFile != "" {
+		if err := loadConfigFile(configFile, os.Getenv, os.Setenv); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: reading the config file: %v\n", err)
+		}
+	}
+	locale = detectLocale()
+	displayDateLayout = resolveDisplayLayout()
+	if _, err := configuredTargets(); err != nil {
+		fmt.Fprintf(os.Stderr, "Config error: %v\n", err)
+		return exitUsage
+	}
+	templates, err := configuredTemplates(os.Environ())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Config error: %v\n", err)
+		return exitUsage
+	}
+	if exerciseMenu, err = configuredMenu(os.Getenv, templates); err != nil {
+		fmt.Fprintf(os.Stderr, "Config error: %v\n", err)
+		return exitUsage
+	}
+	if extraPrompts, err = configuredPrompts(os.Environ()); err != nil {
+		fmt.Fprintf(os.Stderr, "Config error: %v\n", err)
+		return exitUsage
+	}
+	if journal, err = configuredJournal(os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "Config error: %v\n", err)
+		return exitUsage
+	}
+	// A plugin gets its arguments as given, cali's own flags included.
+	args, noPlugins := extractNoPlugins(args)
+	if name, rest, ok := pluginCommand(args); ok && !noPlugins {
+		if path, ok := findPlugin(name, exec.LookPath); ok {
+			return exitCode(runPlugin(path, rest))
+		}
+	}
+	args, outputLevel = extractOutputFlags(args)
+	args, failEmpty := extractFailEmpty(args)
+	ctx, finish := commandContext()
+	err = runCommand(ctx, args)
+	finish()
+	if errors.Is(err, errNoResults) && !failEmpty {
+		err = nil
+	}
+	return exitCode(err)
+}
+
+func runCommand(ctx context.Context, args []string) error {
+	rng, args, err := extractRangeFlags(args, currentTime())
+	if err != nil {
+		return usageError("%v", err)
+	}
+	if selectedUser, args, err = extractUserFlags(args); err != nil {
+		return usageError("%v", err)
+	}
+	if selectedSheet, args, err = extractSheetFlag(args); err != nil {
+		return usageError("%v", err)
+	}
+	if selectedWidth, args, err = extractWidthFlag(args); err != nil {
+		return usageError("%v", err)
+	}
+	args, forceUnrecognized = extractForceUnrecognized(args)
+	args, allowFuture = extractSwitch(args, "--allow-future")
+	if name, ok := helpRequest(args); ok {
+		return runHelp(name)
+	}
+	if selectedSheet != "" && !acceptsSheet(args) {
+		return usageError("--sheet only applies to logging, -p, -s, export and import")
+	}
+	if rng.isSet() && !acceptsRange(args) {
+		return usageError("--since and --until only apply to history, -s, grep, stats, metrics, rest --list, share, progress, graph, report and export")
+	}
+	offerUnsaved(ctx, args)
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "log":
+			// The same as no command; the word only makes the index read well.
+			args = args[1:]
+		case "open":
+			if len(args) < 2 {
+				return usageError("usage: cali open <workout-template>")
+			}
+			if err := openResource(args[1]); err != nil {
+				return fmt.Errorf("opening resource: %w", err)
+			}
+			return nil
+		case "--template":
+			if err := openResource("workout-template"); err != nil {
+				return fmt.Errorf("opening resource: %w", err)
+			}
+			return nil
+		case "-yt", "--yt":
+			if err := openURL(calio.PlaylistsURL); err != nil {
+				return fmt.Errorf("opening playlists: %w", err)
+			}
+			return nil
+		case "--tutorial":
+			return openTutorialFromArgs(args[1:])
+		case "describe":
+			return describeFromArgs(args[1:])
+		case "levels":
+			return listLevels(ctx, args[1:])
+		case "achievements":
+			return runAchievements(ctx, args[1:])
+		case "timer":
+			return runTimer(ctx, args[1:])
+		case "sync":
+			return runSync(ctx, args[1:])
+		case "tutorials":
+			return listTutorials(args[1:])
+		case "auth":
+			return runAuth(args[1:])
+		case "sheet":
+			return runSheet(ctx, args[1:])
+		case "self":
+			return runSelf(args[1:])
+		case "--version":
+			printVersion()
+			return nil
+		case "--check-update":
+			checkUpdate()
+			return nil
+		case "history", "-p", "--print", "--history":
+			storage, err := newStorage(ctx)
+			if err != nil {
+				return storageError("configuring storage", err)
+			}
+			var opts historyOptions
+			fs := newHistoryFlagSet(&opts)
+			if err := fs.Parse(args[1:]); err != nil {
+				return flagError(err)
+			}
+			if err := checkListFormat(opts.Format); err != nil {
+				return err
+			}
+			return showHistory(ctx, storage, rng, opts)
+		case "search", "-s", "--search":
+			var opts searchOptions
+			// Flags may follow the date, as in cali -s today --format tsv.
+			rest, err := parseInterspersed(newSearchFlagSet(&opts), args[1:])
+			if err != nil {
+				return flagError(err)
+			}
+			if err := checkListFormat(opts.Format); err != nil {
+				return err
+			}
+			var date string
+			if len(rest) > 0 {
+				date = rest[0]
+			}
+			if date == "" && opts.Flag == "" {
+				return usageError("usage: cali -s <date> or cali -s --flag <kind> [date] (e.g. cali -s 2026-01-24)")
+			}
+			storage, err := newStorage(ctx)
+			if err != nil {
+				return storageError("configuring storage", err)
+			}
+			if opts.Flag != "" {
+				return searchFlagged(ctx, storage, opts, date, rng)
+			}
+			return searchByDate(ctx, storage, opts, date, rng)
+		case "grep":
+			return runGrep(ctx, args[1:], rng)
+		case "import":
+			return runImport(ctx, args[1:])
+		case "remind":
+			return runRemind(ctx, args[1:])
+		case "doctor":
+			var opts doctorOptions
+			fs := newDoctorFlagSet(&opts)
+			if err := fs.Parse(args[1:]); err != nil {
+				return flagError(err)
+			}
+			if fs.NArg() > 0 || (opts.DryRun && !opts.FixGoals && !opts.Normalize) ||
+				(opts.Normalize && (opts.Goals || opts.FixGoals)) {
+				return usageError("usage: cali doctor [--goals | --fix-goals [--dry-run] | --normalize [--dry-run]]")
+			}
+			keyWarned, err := checkKeyAge(currentTime())
+			if err != nil {
+				return err
+			}
+			storage, err := newStorage(ctx)
+			if err != nil {
+				return storageError("configuring storage", err)
+			}
+			if opts.Normalize {
+				return normalizeNames(ctx, storage, opts.DryRun)
+			}
+			if opts.Goals || opts.FixGoals {
+				return checkGoals(ctx, storage, opts.FixGoals, opts.DryRun)
+			}
+			return runDoctor(ctx, storage, keyWarned)
+		case "status":
+			return runStatus(ctx, args[1:])
+		case "today":
+			storage, err := newStorage(ctx)
+			if err != nil {
+				return storageError("configuring storage", err)
+			}
+			return showToday(ctx, storage)
+		case "template":
+			return runTemplate(ctx, args[1:])
+		case "retry-unsaved":
+			return runRetryUnsaved(ctx, args[1:])
+		case "compliance":
+			return runCompliance(ctx, args[1:])
+		case "stats", "--stats":
+			return runStats(ctx, args[1:], rng)
+		case "metrics":
+			storage, err := newStorage(ctx)
+			if err != nil {
+				return storageError("configuring storage", err)
+			}
+			if err := printMetrics(ctx, storage, rng, os.Stdout); err != nil {
+				return storageError("computing metrics", err)
+			}
+			return nil
+		case "rest":
+			return runRest(ctx, args[1:], rng)
+		case "plan":
+			return runPlan(ctx, args[1:])
+		case "goal":
+			return runGoal(ctx, args[1:])
+		case "restore":
+			return runRestore(ctx, args[1:])
+		case "share":
+			return runShare(ctx, args[1:], rng)
+		case "q":
+			return runQuickLog(ctx, args[1:])
+		case "progress":
+			return runProgress(ctx, args[1:], rng)
+		case "next":
+			return runNext(ctx, args[1:])
+		case "serve":
+			return runServe(ctx, args[1:])
+		case "graph":
+			return runGraph(ctx, args[1:], rng)
+		case "compare":
+			return runCompare(ctx, args[1:])
+		case "report":
+			return runReport(ctx, args[1:], rng)
+		case "export":
+			storage, err := newStorage(ctx)
+			if err != nil {
+				return storageError("configuring storage", err)
+			}
+			return runExport(ctx, storage, args[1:], rng)
+		case "remove", "-r", "--remove":
+			return runRemove(ctx, args[1:])
+		}
+	}
+
+	opts, err := parseLogOptions(args)
+	if err != nil {
+		return err
+	}
+	// The questions would read their answers from whatever is piped in and
+	// save a row made of it.
+	if !opts.direct() && !isTerminal(os.Stdin) {
+		return usageError("cali asks its questions on a terminal, and stdin isn't one; log with flags instead, e.g. echo \"felt strong\" | cali log --exercise pullups --level full --reps 8x2 --comment -, or with cali q")
+	}
+	if !opts.NoWizard && !opts.direct() && isTerminal(os.Stdin) && firstRun() {
+		logNow, err := runSetupWizard(ctx, bufio.NewReader(os.Stdin), configFile)
+		if err != nil || !logNow {
+			return err
+		}
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+
+	if opts.direct() {
+		return logFromFlags(ctx, storage, opts)
+	}
+	return logWorkout(ctx, storage, opts)
+}
+
+type logOptions struct {
+	Deload   bool
+	Interval bool
+	Category string
+	Flags    flagList
+	// Extras answer extra prompts (see extraPrompt) without asking.
+	Extras extraList
+	// Load is the weight added to the set, as stored (see parseLoadInput).
+	Load string
+	// NoDuration turns off timing the session (see sessionClock).
+	NoDuration bool
+	// NoWizard skips the setup wizard of a first run.
+	NoWizard bool
+	// AllLevels offers the levels CALI_HIDE_LEVELS hides.
+	AllLevels bool
+	// Readiness is the score given with --readiness; 0 for none (see
+	// sessionReadiness).
+	Readiness int
+	// Day, Exercise, Level, Reps and Comment describe the entry instead of
+	// asking for it (see logFromFlags). Comment "-" reads stdin.
+	Day      string
+	Exercise string
+	Level    string
+	Reps     string
+	Comment  string
+}
+
+// newLogFlagSet declares the flags of the interactive log into opts.
+func newLogFlagSet(opts *logOptions) *flag.FlagSet {
+	fs := newFlagSet("log")
+	fs.BoolVar(&opts.Deload, "deload", false, "log a deload session")
+	fs.BoolVar(&opts.Interval, "interval", false, "log a timed protocol (EMOM/AMRAP/Tabata)")
+	fs.StringVar(&opts.Category, "category", calio.CategoryStrength, "session category (strength or mobility)")
+	fs.Var(&opts.Flags, "flag", `attach a flag to the entry, e.g. pain:"left shoulder" (repeatable)`)
+	fs.Var(&opts.Extras, "extra", "answer an extra prompt of the exercise, e.g. knee=good (repeatable)")
+	fs.StringVar(&opts.Load, "load", "", "weight added to the set, e.g. +10kg (see CALI_LOADED)")
+	fs.BoolVar(&opts.NoDuration, "no-duration", false, "don't record how long the session took")
+	fs.BoolVar(&opts.NoWizard, "no-wizard", false, "don't start the setup wizard when nothing is configured")
+	fs.BoolVar(&opts.AllLevels, "all-levels", false, "offer the levels hidden with CALI_HIDE_LEVELS too")
+	fs.IntVar(&opts.Readiness, "readiness", 0, "how ready you are today, 1 (poor sleep, sore) to 5 (see CALI_READINESS)")
+	fs.StringVar(&opts.Day, "day", "", "log without asking: the day letter (default: as cali q picks it)")
+	fs.StringVar(&opts.Exercise, "exercise", "", "log without asking: the exercise, e.g. pullups")
+	fs.StringVar(&opts.Level, "level", "", "log without asking: the level, by name or step number")
+	fs.StringVar(&opts.Reps, "reps", "", "log without asking: the reps or hold, e.g. 8x2 or 90s")
+	fs.StringVar(&opts.Comment, "comment", "", `log without asking: the comment, or "-" to read it from stdin`)
+	return fs
+}
+
+// historyOptions are the flags of cali -p.
+type historyOptions struct {
+	Full     bool
+	Include  kindFilter
+	AllTypes bool
+	// Format prints the entries as csv or tsv rows instead of a list.
+	Format string
+	Header bool
+}
+
+// newHistoryFlagSet declares the flags of cali -p into opts.
+func newHistoryFlagSet(opts *historyOptions) *flag.FlagSet {
+	if opts.Include == nil {
+		opts.Include = kindFilter{}
+	}
+	fs := newFlagSet("history")
+	fs.BoolVar(&opts.Full, "full", false, "show comments in full instead of one line each")
+	fs.Var(opts.Include, "include", "also list warmups, mobility and/or rest days, comma-separated")
+	fs.BoolVar(&opts.AllTypes, "all-types", false, "list every entry and rest day")
+	fs.StringVar(&opts.Format, "format", "", "print the entries as csv or tsv rows, and nothing else")
+	fs.BoolVar(&opts.Header, "header", false, "with --format, start with a row of column headings")
+	return fs
+}
+
+// searchOptions are the flags of cali -s.
+type searchOptions struct {
+	Flag   string
+	Format string
+	Header bool
+}
+
+// newSearchFlagSet declares the flags of cali -s into opts.
+func newSearchFlagSet(opts *searchOptions) *flag.FlagSet {
+	fs := newFlagSet("search")
+	fs.StringVar(&opts.Flag, "flag", "", "only entries carrying this flag kind (e.g. pain)")
+	fs.StringVar(&opts.Format, "format", "", "print the entries as csv or tsv rows, and nothing else")
+	fs.BoolVar(&opts.Header, "header", false, "with --format, start with a row of column headings")
+	return fs
+}
+
+func parseLogOptions(args []string) (logOptions, error) {
+	var opts logOptions
+	fs := newLogFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return logOptions{}, flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return logOptions{}, unknownCommand(fs.Arg(0))
+	}
+	if opts.Category != calio.CategoryStrength && opts.Category != calio.CategoryMobility {
+		return logOptions{}, usageError("unknown category %q (use strength or mobility)", opts.Category)
+	}
+	if opts.Readiness < 0 || opts.Readiness > readinessScale {
+		return logOptions{}, usageError("--readiness: %s", msg("log.readiness_invalid", strconv.Itoa(opts.Readiness), readinessScale))
+	}
+	if opts.Load != "" && opts.Interval {
+		return logOptions{}, usageError("--load can't be combined with --interval")
+	}
+	load, err := parseLoadInput(opts.Load)
+	if err != nil {
+		return logOptions{}, usageError("%v", err)
+	}
+	opts.Load = load
+	if opts.Extras, err = checkExtras(extraPrompts, "", opts.Extras); err != nil {
+		return logOptions{}, usageError("%v", err)
+	}
+	if opts.direct() {
+		if err := checkDirect(opts); err != nil {
+			return logOptions{}, err
+		}
+	}
+	return opts, nil
+}
+
+// promptRepsSets reads Reps×Sets; Enter takes suggested, unless it is "".
+func promptRepsSets(reader *bufio.Reader, suggested string) string {
+	if suggested != "" {
+		prompt(msg("log.reps_sets_prompt_default", suggested))
+	} else {
+		prompt(msg("log.reps_sets_prompt"))
+	}
+	repsSets, _ := reader.ReadString('\n')
+	if strings.TrimSpace(repsSets) == "" {
+		repsSets = suggested
+	}
+	return normalizeRepsSets(repsSets)
+}
+
+func logWorkout(ctx context.Context, storage Storage, opts logOptions) error {
+	reader := bufio.NewReader(os.Stdin)
+	release, err := guardSession(reader)
+	if err != nil {
+		return err
+	}
+	defer release()
+	loadGoalOverrides(ctx, storage)
+	loadLevelPins(ctx, storage)
+	// Opening a tutorial ends the flow without logging, so there is no
+	// tutorial time to take out of the measurement.
+	var clock *sessionClock
+	if !opts.NoDuration {
+		clock = startSessionClock(time.Now)
+	}
+	readiness, newReadiness, err := sessionReadiness(ctx, storage, reader, opts.Readiness, currentTime().Format(calio.DateLayout))
+	if err != nil {
+		return err
+	}
+
+	// Mobility work sits outside the A/B/C rotation, so it has no day.
+	var day string
+	if opts.Category != calio.CategoryMobility {
+		printDayPlan()
+
+		if day, date, err := storage.LastTrainingDay(ctx); err == nil && day != "" {
+			say(msg("log.previous_day", day, displayDate(date)))
+		}
+
+		prompt(msg("log.day_prompt"))
+		day, _ = reader.ReadString('\n')
+		day = strings.TrimSpace(day)
+	}
+
+	exercise, day := chooseExerciseForDay(reader, exercisesForCategory(opts.Category), day)
+	printFlagNotes(ctx, storage, exercise)
+	level := chooseLevel(reader, exercise, recentLevels(ctx, storage)[exercise], outputLevel >= levelVerbose, opts.AllLevels)
+	tutorialURL := resolveTutorial(exercise, level)
+	if tutorialURL != "" && promptOpenTutorial(reader, exercise, level) {
+		if err := openURL(tutorialURL); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to open tutorial: %v\n", err)
+		} else {
+			recordWatched(exercise, level)
+			sayln(msg("log.tutorial_opened"))
+			return nil
+		}
+	}
+
+	advised := false
+	if opts.Deload {
+		printDeloadTarget(ctx, storage, exercise, level)
+	} else if !opts.Interval {
+		advised = printReadinessAdvice(ctx, storage, exercise, level, readiness)
+	}
+
+	// The deload target, or deload targets advised for low readiness, stand
+	// in for the step up.
+	var suggested string
+	if !opts.Interval && !opts.Deload && !advised {
+		if hint, ok := lastResultHint(ctx, storage, exercise, level); ok {
+			printOverloadHint(hint)
+			if acceptSuggestionEnabled() {
+				suggested = hint.Target
+			}
+		}
+	}
+
+	workoutType := calio.TypeStraightSets
+	var repsSets string
+	if opts.Interval {
+		workoutType = calio.TypeInterval
+		if repsSets, err = promptInterval(reader); err != nil {
+			return err
+		}
+	} else if hasTimedGoal(exercise, level) {
+		if repsSets, err = promptHoldTime(reader, suggested); err != nil {
+			return err
+		}
+	} else {
+		repsSets = promptRepsSets(reader, suggested)
+	}
+	load := opts.Load
+	if load == "" && !opts.Interval && loadedTrainingEnabled() {
+		if load, err = promptLoad(reader); err != nil {
+			return err
+		}
+	}
+
+	prompt(msg("log.comment_prompt"))
+	comment, _ := reader.ReadString('\n')
+	comment, err = checkCommentLength(reader, strings.TrimSpace(comment), true)
+	if err != nil {
+		return err
+	}
+	if opts.Deload {
+		comment = addDeloadTag(comment)
+	}
+	answers, err := askExtras(reader, extraPrompts, exercise, opts.Extras)
+	if err != nil {
+		return err
+	}
+	comment = addExtras(comment, answers)
+	if newReadiness {
+		comment = addReadiness(comment, readiness)
+	}
+
+	flags := opts.Flags
+	if len(flags) == 0 {
+		prompt(msg("log.issue_prompt"))
+		issue, _ := reader.ReadString('\n')
+		if issue = strings.TrimSpace(issue); issue != "" {
+			flags = append(flags, entryFlag{Kind: "pain", Note: strings.Trim(issue, "[]|")})
+		}
+	}
+	comment = addCommentFlags(comment, flags)
+
+	goal := resolveGoal(exercise, level)
+	date := currentTime().Format(calio.DateLayout)
+
+	entry := WorkoutEntry{
+		Date:     date,
+		Day:      day,
+		Exercise: exercise,
+		Level:    level,
+		RepsSets: repsSets,
+		Goal:     goal,
+		Comment:  comment,
+		Type:     workoutType,
+		Category: opts.Category,
+		Duration: clock.minutes(),
+		Load:     load,
+	}
+
+	if err := saveEntry(ctx, storage, entry); err != nil {
+		return err
+	}
+	offerNextVideo(reader, entry)
+	return nil
+}
+
+// saveEntry appends entry and reports where it went and the highest standard
+// it met.
+func saveEntry(ctx context.Context, storage Storage, entry WorkoutEntry) error {
+	if err := checkFutureDates([]WorkoutEntry{entry}, currentTime()); err != nil {
+		return err
+	}
+	entry = markAchievements(ctx, storage, []WorkoutEntry{entry})[0]
+	saved, err := storage.Append(ctx, entry)
+	if err != nil {
+		keepUnsaved(err, entry)
+		return storageError("writing workout", err)
+	}
+	entry = saved
+	journal.record([]WorkoutEntry{entry})
+
+	sayln(msg("log.logged"))
+	fmt.Print(msg("log.saved", displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level, loggedWork(entry)))
+	if location := entryLocation(storage, entry); location != "" {
+		say(location)
+	}
+	if entry.Duration > 0 {
+		say(msg("log.duration", entry.Duration))
+	}
+	if !isInterval(entry) {
+		if tier := tierMet(entry.RepsSets, resolveTiers(entry.Exercise, entry.Level)); tier != "" {
+			say(msg("log.standard_met", msg("tier."+tier)))
+		}
+		if entry.Load != "" {
+			say(msg("log.load_not_counted"))
+		}
+	}
+	if isAchievement(entry) {
+		say(msg("log.achievement", entry.Exercise, entry.Level))
+	}
+	return nil
+}
+
+// entryLocation says where storage keeps entry: the sheet row with a link to
+// it, or the line of the local file. It is "" when that isn't known.
+func entryLocation(storage Storage, entry WorkoutEntry) string {
+	if shared, ok := storage.(*calio.UserStorage); ok {
+		storage = shared.Storage
+	}
+	if entry.RowIndex < 0 {
+		return ""
+	}
+	switch backend := storage.(type) {
+	case *calio.SheetsStorage:
+		return msg("log.saved_row", entry.RowIndex+1, backend.TabFor(entry.Date), backend.RowURL(entry))
+	case *calio.FileStorage:
+		return msg("log.saved_line", entry.RowIndex+1, backend.FileFor(entry.Date))
+	}
+	return ""
+}
+
+// newStorage returns the configured backend, scoped to the selected user of
+// a shared log (see withUser).
+func newStorage(ctx context.Context) (Storage, error) {
+	backend, err := newBackend(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return withUser(backend), nil
+}
+
+// newBackend opens the backend CALI_STORAGE names (see cali-backends.go),
+// showing every user's entries.
+func newBackend(ctx context.Context) (Storage, error) {
+	name := os.Getenv("CALI_STORAGE")
+	if strings.TrimSpace(name) == "" {
+		name = defaultBackend
+	}
+	return calio.OpenBackend(ctx, name, calio.BackendConfig{
+		Sheet:    selectedSheet,
+		Getenv:   os.Getenv,
+		Now:      currentTime,
+		Writer:   writerName(),
+		Progress: newProgress(),
+		Logf:     detail,
+	})
+}
+
+func chooseExercise(reader *bufio.Reader, exercises []string) string {
+	promptln(msg("log.choose_exercise"))
+	for i, ex := range exercises {
+		prompt("  %d. %s\n", i+1, ex)
+	}
+	prompt(msg("log.enter_number"))
+
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	choice, err := strconv.Atoi(input)
+
+	if err != nil || choice < 1 || choice > len(exercises) {
+		prompt(msg("log.invalid_exercise", exercises[0]))
+		return exercises[0]
+	}
+
+	return exercises[choice-1]
+}
+
+// chooseLevel asks for one of exercise's levels, without those hidden by
+// CALI_HIDE_LEVELS unless all is set. An empty answer takes current, the
+// pinned or last trained level, when there is one.
+func chooseLevel(reader *bufio.Reader, exercise, current string, verbose, all bool) string {
+	levels := exerciseMenu.levels(exercise, current, all)
+
+	links := newLinker(promptWriter())
+	prompt(msg("log.choose_level", exercise))
+	for i, lv := range levels {
+		mark := ""
+		if lv == current {
+			mark = currentLevelMark(exercise)
+		}
+		prompt("  %d. %s (%s)%s\n", i+1, padCell(links.tutorialLink(exercise, lv), 20), tierSummary(exercise, lv), mark)
+		if desc, ok := resolveDescription(exercise, lv); ok && verbose {
+			prompt("       %s\n", desc.Summary)
+		}
+	}
+	if hasGoalOverrides(exercise) {
+		promptln(msg("goal.legend"))
+	}
+	if slices.Contains(levels, current) {
+		prompt(msg("log.enter_number_default", slices.Index(levels, current)+1))
+	} else {
+		prompt(msg("log.enter_number"))
+	}
+
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" && slices.Contains(levels, current) {
+		return current
+	}
+	choice, err := strconv.Atoi(input)
+
+	if err != nil || choice < 1 || choice > len(levels) {
+		promptln(msg("log.invalid_level"))
+		return levels[0]
+	}
+
+	return levels[choice-1]
+}
+
+func printDayPlan() {
+	sayln(msg("log.day_plan"))
+	for _, day := range calio.DayLetters() {
+		say(msg("log.day_plan_day", day))
+		for _, exercise := range exerciseMenu.dayPlan(day) {
+			say("    - %s\n", exercise)
+		}
+	}
+	sayln()
+}
+
+func openResource(name string) error {
+	if name != "workout-template" {
+		return usageError("unknown resource %q (use workout-template)", name)
+	}
+
+	const templateURL = "https://drive.google.com/file/d/19zXstmNsSoT6hmseO-nU-h2NNiIK-X2R/view?usp=drive_link"
+	return openURL(templateURL)
+}
+
+// resolveGoal returns the user's goal for a level (see cali goal), else the
+// built-in one.
+func resolveGoal(exercise, level string) string {
+	if goal, ok := goalOverride(exercise, level); ok {
+		return goal
+	}
+	if goal, ok := calio.Goal(exercise, level); ok {
+		return goal
+	}
+	return "-"
+}
+
+func resolveTutorial(exercise, level string) string {
+	link, _ := calio.Tutorial(exercise, level)
+	return link
+}
+
+// lookupPlaylist finds the playlist mapped to an exercise; tests replace
+// it to map one.
+var lookupPlaylist = calio.Playlist
+
+func resolvePlaylist(exercise string) string {
+	link, _ := lookupPlaylist(exercise)
+	return link
+}
+
+func promptOpenTutorial(reader *bufio.Reader, exercise, level string) bool {
+	watched := ""
+	if store, err := newWatchedStore(); err == nil {
+		if at, ok := store.Watched(exercise, level); ok {
+			watched = msg("log.watched_on", at.Format(displayDateLayout))
+		}
+	}
+	prompt(msg("log.open_tutorial", exercise, level, watched))
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+	return input != "" && slices.Contains(strings.Split(msg("answer.yes"), ","), input)
+}
+
+// openURL opens target in the browser without waiting for it; tests
+// replace it to see what would be opened.
+var openURL = func(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Start()
+}
+
+func openTutorialFromArgs(args []string) error {
+	exercise, level, err := parseTutorialArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if level == "" {
+		link := resolvePlaylist(exercise)
+		if link == "" {
+			say(msg("tutorial.no_playlist", exercise))
+			link = calio.PlaylistsURL
+		} else {
+			say(msg("tutorial.opening_list", exercise))
+		}
+		fmt.Println(link)
+		return openURL(link)
+	}
+
+	link := resolveTutorial(exercise, level)
+	if link == "" {
+		return errors.New(msg("tutorial.not_mapped", exercise, level))
+	}
+
+	say(msg("tutorial.opening", exercise, level))
+	fmt.Println(link)
+	if err := openURL(link); err != nil {
+		return err
+	}
+	recordWatched(exercise, level)
+	return nil
+}
+
+// parseTutorialArgs resolves "<exercise> <level>" or just "<exercise>"; the
+// level is empty in the single-exercise form.
+func parseTutorialArgs(args []string) (string, string, error) {
+	if len(args) < 1 {
+		return "", "", usageError(`usage: cali --tutorial <exercise> [level] (quote multi-word values, e.g. cali --tutorial "Handstand Push-ups" "Wall Headstand")`)
+	}
+
+	if exercise, ok := normalizeExercise(strings.Join(args, " ")); ok {
+		return exercise, "", nil
+	}
+
+	for i := len(args) - 1; i >= 1; i-- {
+		exerciseCandidate := strings.Join(args[:i], " ")
+		levelCandidate := strings.Join(args[i:], " ")
+
+		exercise, ok := normalizeExercise(exerciseCandidate)
+		if !ok {
+			continue
+		}
+
+		level, ok := normalizeLevel(exercise, levelCandidate)
+		if !ok {
+			return "", "", usageError("%s", msg("error.unknown_level", levelCandidate, exercise))
+		}
+
+		return exercise, level, nil
+	}
+
+	name := strings.Join(args, " ")
+	if suggestion := suggestExercise(name); suggestion != "" {
+		return "", "", usageError("%s", msg("error.unknown_exercise_suggest", name, suggestion))
+	}
+	return "", "", usageError("%s", msg("error.unknown_exercise", name))
+}
+
+// suggestExercise returns the exercise closest to name by edit distance,
+// ignoring case, or "" when none is close enough to be a likely typo. The
+// first word alone is tried too, as it may be followed by a level.
+func suggestExercise(name string) string {
+	candidates := []string{strings.ToLower(strings.TrimSpace(name))}
+	if fields := strings.Fields(candidates[0]); len(fields) > 1 {
+		candidates = append(candidates, fields[0])
+	}
+	best, bestDistance := "", 0
+	for _, exercise := range append(calio.Exercises(), calio.MobilityExercises()...) {
+		for _, candidate := range candidates {
+			distance := editDistance(candidate, strings.ToLower(exercise))
+			if distance > 2 || distance >= len([]rune(exercise)) {
+				continue
+			}
+			if best == "" || distance < bestDistance {
+				best, bestDistance = exercise, distance
+			}
+		}
+	}
+	return best
+}
+
+// sameName reports whether two exercise or level names are the same once
+// case and surrounding spaces are ignored, as rows typed into the sheet by
+// hand may differ (see cali doctor --normalize).
+func sameName(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+func normalizeExercise(input string) (string, bool) {
+	return calio.CanonicalExercise(input)
+}
+
+// normalizeLevel matches a level by name or by its step number, so "3" and
+// "step 3" both resolve to the third level.
+func normalizeLevel(exercise, input string) (string, bool) {
+	if level, ok := calio.CanonicalLevel(exercise, input); ok {
+		return level, true
+	}
+
+	levels := calio.Levels(exercise)
+	step := strings.TrimSpace(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(input)), "step"))
+	if n, err := strconv.Atoi(step); err == nil && n >= 1 && n <= len(levels) {
+		return levels[n-1], true
+	}
+	return "", false
+}
+
+// showHistory lists the latest working sets, or those in rng, along with
+// the kinds opts includes (see classifyEntry); the limit of 10 counts only
+// the entries listed. Rest days don't count towards it either; they are
+// listed within the dates the entries span. Comments are cut to one line
+// unless opts.Full is set.
+func showHistory(ctx context.Context, storage Storage, rng dateRange, opts historyOptions) error {
+	filter := opts.Include
+	if opts.AllTypes {
+		filter = allKinds()
+	}
+	full := opts.Full
+	entries, err := recentInRange(ctx, storage, 10, rng, filter.shows)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	if opts.Format != "" {
+		return printDelimited(opts.Format, entries, opts.Header)
+	}
+
+	if len(entries) == 0 {
+		if opts.AllTypes {
+			fmt.Println(msg("history.empty"))
+		} else {
+			fmt.Println(msg("history.empty_filtered"))
+		}
+		return errNoResults
+	}
+
+	now := currentTime()
+	var rest []calio.RestDay
+	if filter[kindRest] {
+		span := dateRange{Since: calio.Canonical(entries[0]).Date, Until: rng.Until}
+		if rest, err = readRestDays(ctx, storage, span, now.Format(calio.DateLayout)); err != nil {
+			return storageError("reading rest days", err)
+		}
+	}
+	future, unreadable := 0, 0
+	var rows []listColumns
+	for _, entry := range entries {
+		date := calio.Canonical(entry).Date
+		for len(rest) > 0 && rest[0].Date <= date {
+			if rest[0].Date < date {
+				rows = append(rows, restColumns(rest[0]))
+			}
+			rest = rest[1:]
+		}
+		mark := ""
+		if calio.FutureDated(entry.Date, now) {
+			mark = futureMark
+			future++
+		} else if calio.UnreadableDate(entry) {
+			mark = futureMark
+			unreadable++
+		}
+		if !full {
+			entry.Comment = oneLineComment(entry.Comment, historyCommentWidth)
+		}
+		rows = append(rows, entryColumns(mark+msg("list.lead", displayDate(entry.Date), entry.Day), entry, full))
+	}
+	for _, day := range rest {
+		rows = append(rows, restColumns(day))
+	}
+	plan := planList(terminalWidth(), rows, full)
+	sayln(msg("history.header"))
+	sayln(plan.Rule)
+	for _, line := range plan.Lines {
+		fmt.Println(line)
+	}
+	sayln(plan.Rule)
+	say(msg("list.total", len(entries)))
+	if future > 0 {
+		fmt.Fprint(os.Stderr, msg("history.future_warning", future))
+	}
+	if unreadable > 0 {
+		fmt.Fprint(os.Stderr, msg("history.date_warning", unreadable))
+	}
+	return nil
+}
+
+func searchByDate(ctx context.Context, storage Storage, opts searchOptions, input string, rng dateRange) error {
+	dateStr, err := userDate(input)
+	if err != nil {
+		return err
+	}
+
+	entries, err := entriesOnDate(ctx, storage, dateStr)
+	if err != nil {
+		return storageError("searching workouts", err)
+	}
+	if !rng.contains(dateStr) {
+		entries = nil
+	}
+	if opts.Format != "" {
+		return writeNumbered(opts, entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Print(msg("search.empty", displayDate(dateStr)))
+		return errNoResults
+	}
+
+	plan := planNumbered(entries)
+	say(msg("search.header", displayDate(dateStr)))
+	sayln(plan.Rule)
+	for _, line := range plan.Lines {
+		fmt.Println(line)
+	}
+	sayln(plan.Rule)
+	say(msg("list.total", len(entries)))
+	say(msg("search.actions", dateStr))
+	return nil
+}
+
+// writeNumbered prints entries as rows in opts.Format (see printDelimited).
+func writeNumbered(opts searchOptions, entries []numberedEntry) error {
+	rows := make([]WorkoutEntry, len(entries))
+	for i, numbered := range entries {
+		rows[i] = numbered.Entry
+	}
+	return printDelimited(opts.Format, rows, opts.Header)
+}
+
+// numberedEntry is an entry of one date with the number cali shows for it.
+type numberedEntry struct {
+	Number int // from 1, the position SearchByDate returned Entry at
+	Entry  WorkoutEntry
+}
+
+// row renders the entry as search and remove list it, e.g. "[2] Day B | …".
+func (n numberedEntry) row() string {
+	return msg("list.numbered_row", n.Number, n.Entry.Day, n.Entry.Exercise, n.Entry.Level,
+		workText(n.Entry), n.Entry.Comment)
+}
+
+// planNumbered lays out entries of one date for the terminal, comments in
+// full.
+func planNumbered(entries []numberedEntry) listPlan {
+	rows := make([]listColumns, len(entries))
+	for i, numbered := range entries {
+		rows[i] = entryColumns(msg("list.numbered_lead", numbered.Number, numbered.Entry.Day), numbered.Entry, true)
+	}
+	return planList(terminalWidth(), rows, true)
+}
+
+// entriesOnDate numbers the entries of date in the order SearchByDate
+// returns them, the order RemoveByDateIndex counts in. cali -s, the
+// interactive cali -r and cali -r --index all number entries here, so a
+// number shown by one means the same entry to the others.
+func entriesOnDate(ctx context.Context, storage Storage, date string) ([]numberedEntry, error) {
+	entries, err := storage.SearchByDate(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	numbered := make([]numberedEntry, len(entries))
+	for i, entry := range entries {
+		numbered[i] = numberedEntry{Number: i + 1, Entry: entry}
+	}
+	return numbered, nil
+}
+
+// removeOptions are the flags of cali -r.
+type removeOptions struct {
+	Date  string
+	Index int
+	Yes   bool
+}
+
+// newRemoveFlagSet declares the flags of cali -r into opts.
+func newRemoveFlagSet(opts *removeOptions) *flag.FlagSet {
+	fs := newFlagSet("remove")
+	fs.StringVar(&opts.Date, "date", "", "date of the entry to remove, as cali -s takes it")
+	fs.IntVar(&opts.Index, "index", 0, "number of the entry as cali -s <date> lists it")
+	fs.BoolVar(&opts.Yes, "yes", false, "with --date and --index, remove without asking or offering undo")
+	return fs
+}
+
+func runRemove(ctx context.Context, args []string) error {
+	var opts removeOptions
+	fs := newRemoveFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	byIndex := opts.Date != "" || opts.Index != 0
+	if fs.NArg() > 0 || (byIndex && (opts.Date == "" || opts.Index < 1)) || (opts.Yes && !byIndex) {
+		return usageError("usage: cali -r, or cali -r --date <date> --index <n> [--yes]")
+	}
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	if byIndex {
+		return removeByIndex(ctx, storage, opts)
+	}
+	return removeEntry(ctx, storage)
+}
+
+// removeByIndex removes the entry cali -s lists as opts.Index on opts.Date,
+// showing it and asking first unless opts.Yes is set.
+func removeByIndex(ctx context.Context, storage Storage, opts removeOptions) error {
+	dateStr, err := userDate(opts.Date)
+	if err != nil {
+		return err
+	}
+	entries, err := entriesOnDate(ctx, storage, dateStr)
+	if err != nil {
+		return storageError("searching workouts", err)
+	}
+	if len(entries) == 0 {
+		fmt.Print(msg("search.empty", displayDate(dateStr)))
+		return errNoResults
+	}
+	if opts.Index > len(entries) {
+		return usageError("%s", msg("remove.no_index", opts.Index, displayDate(dateStr), len(entries)))
+	}
+
+	chosen := entries[opts.Index-1]
+	prompt(chosen.row())
+	reader := bufio.NewReader(os.Stdin)
+	if !opts.Yes {
+		prompt(msg("remove.confirm"))
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			return inputClosed()
+		}
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if !slices.Contains(strings.Split(msg("answer.yes"), ","), answer) {
+			promptln(msg("remove.cancelled"))
+			return errCancelled
+		}
+	}
+	if opts.Yes {
+		reader = nil
+	}
+	return removeNumbered(ctx, storage, dateStr, chosen, reader)
+}
+
+func removeEntry(ctx context.Context, storage Storage) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	prompt(msg("remove.date_prompt"))
+	input, _ := reader.ReadString('\n')
+	dateStr, err := userDate(input)
+	if err != nil {
+		return err
+	}
+
+	entries, err := entriesOnDate(ctx, storage, dateStr)
+	if err != nil {
+		return storageError("searching workouts", err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Print(msg("search.empty", displayDate(dateStr)))
+		return errNoResults
+	}
+
+	plan := planNumbered(entries)
+	prompt(msg("remove.header", displayDate(dateStr)))
+	promptln(plan.Rule)
+	for _, line := range plan.Lines {
+		promptln(line)
+	}
+	promptln(plan.Rule)
+
+	prompt(msg("remove.index_prompt"))
+	input, _ = reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	choice, err := strconv.Atoi(input)
+	if err != nil || choice < 0 || choice > len(entries) {
+		return usageError("%s", msg("error.invalid_choice", input))
+	}
+	if choice == 0 {
+		promptln(msg("remove.cancelled"))
+		return errCancelled
+	}
+	return removeNumbered(ctx, storage, dateStr, entries[choice-1], reader)
+}
+
+// removeNumbered removes the entry numbered on date by entriesOnDate, after
+// the daily backup, and prints it in full. The entry itself is removed, not
+// whatever holds its number by now: entries logged or removed while the
+// user was choosing don't shift it. With a reader on a terminal it
+// then offers to undo the removal; a nil reader (--yes) or piped input
+// skips the offer.
+func removeNumbered(ctx context.Context, storage Storage, dateStr string, numbered numberedEntry, reader *bufio.Reader) error {
+	beforeMutation(ctx, storage)
+	if err := calio.RemoveEntry(ctx, storage, numbered.Entry); err != nil {
+		if errors.Is(err, calio.ErrEntryChanged) || errors.Is(err, calio.ErrNoData) || errors.Is(err, calio.ErrInvalidIndex) {
+			// Another device removed entries between the listing and now.
+			return storageError("removing entry", errors.New(msg("remove.changed", displayDate(dateStr))))
+		}
+		return storageError("removing entry", err)
+	}
+
+	sayln()
+	fmt.Println(msg("remove.done"))
+	printRemoved(numbered.Entry)
+	if reader == nil || !isTerminal(os.Stdin) {
+		return nil
+	}
+	if !offerUndo(reader, removeUndoWindow, time.After) {
+		return nil
+	}
+	return undoRemove(ctx, storage, numbered.Entry)
+}
+
+// newFileStorage returns the local backend under localLogDir, or
+// the subdirectory named sheet of it, the local counterpart of a tab. A
+// rewrite of the log interrupted by a crash is finished first, with a
+// warning. One that needs a look by hand is only a warning too, so commands
+// that read still run; the storage refuses writes until it is resolved.
+func newFileStorage(ctx context.Context, sheet string) (*calio.FileStorage, error) {
+	dir, err := localLogDir()
+	if err != nil {
+		return nil, err
+	}
+	if sheet != "" {
+		if sheet == "." || sheet == ".." || strings.ContainsAny(sheet, `/\`) {
+			return nil, fmt.Errorf("--sheet %q can't name a local log directory (no slashes, . or ..)", sheet)
+		}
+		dir = filepath.Join(dir, sheet)
+	}
+	storage := calio.NewFileStorage(dir)
+	storage.Now = currentTime
+	storage.Writer = writerName()
+	recovery, err := storage.Recover(ctx)
+	if errors.Is(err, calio.ErrRewriteStuck) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return storage, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if recovery != nil {
+		names := make([]string, len(recovery.Files))
+		for i, file := range recovery.Files {
+			names[i] = filepath.Base(file)
+		}
+		action := "undid"
+		if recovery.Completed {
+			action = "finished"
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s an interrupted rewrite of %s\n", action, strings.Join(names, ", "))
+	}
+	return storage, nil
+}
+
+// localLogDir returns CALI_LOG_DIR, or the workout directory of the data
+// directory (see appPaths) when it is unset.
+func localLogDir() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv("CALI_LOG_DIR")); dir != "" {
+		return dir, nil
+	}
+	// The data directory is created private to the user before the backend
+	// creates the log directory in it.
+	dir, err := defaultLogDir()
+	if err != nil {
+		return "", err
+	}
+	return dir, makeDirFor(dir)
+}
+
+// defaultLogDir returns the workout directory of the data directory.
+func defaultLogDir() (string, error) {
+	paths, err := userPaths()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(paths.DataDir(), "workout"), nil
+}
+
+// newSheetsStorage connects to the spreadsheet configured in the environment
+// or the OS keyring. A non-empty sheet replaces the configured tab (--sheet).
+func newSheetsStorage(ctx context.Context, sheet string) (*calio.SheetsStorage, error) {
+	cfg := loadSheetsConfig(os.Getenv, keyringStore).withSheet(sheet)
+	if cfg.SpreadsheetID.Value == "" {
+		return nil, cfg.missing("CALI_SHEET_ID", "CALI_SHEET_ID is required (Google Sheets is default; set CALI_STORAGE=local to use local files)")
+	}
+	if _, err := calio.ParseSpreadsheetID(cfg.SpreadsheetID.Value); err != nil {
+		return nil, &calio.ConfigError{Name: "CALI_SHEET_ID", Reason: fmt.Sprintf("invalid CALI_SHEET_ID (from %s): %v", cfg.SpreadsheetID.Source, err)}
+	}
+	if cfg.SpreadsheetID.Raw != "" {
+		detail("Sheets settings: spreadsheet ID %s taken from the URL %q\n", cfg.SpreadsheetID.Value, cfg.SpreadsheetID.Raw)
+	}
+	if cfg.Credentials.Value == "" {
+		return nil, cfg.missing("CALI_GOOGLE_CREDENTIALS_JSON", "set CALI_GOOGLE_CREDENTIALS_JSON or GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	detail("Sheets settings: ID from %s, tab from %s, credentials from %s\n",
+		cfg.SpreadsheetID.Source, cfg.SheetName.Source, cfg.Credentials.Source)
+	// Read now so a refused key can be explained (see keyRefusedHint).
+	sheetsKey = nil
+	if key, err := inspectKeyFile(cfg.Credentials.Value); err == nil {
+		sheetsKey = &key
+		detail("Sheets credentials: %s, key %s, changed %s\n", key.ClientEmail, key.KeyID, key.Modified.Format(time.RFC3339))
+	} else {
+		detail("Not reading the credentials' key: %v\n", err)
+	}
+	pageSize, err := sheetPageSize()
+	if err != nil {
+		return nil, err
+	}
+
+	sheetsCfg := calio.SheetsConfig{
+		SpreadsheetID:     cfg.SpreadsheetID.Value,
+		SheetName:         cfg.SheetName.Value,
+		CredentialsFile:   cfg.Credentials.Value,
+		PerYear:           perYearTabsEnabled(),
+		PageSize:          pageSize,
+		Progress:          newProgress(),
+		Logf:              detail,
+		Now:               currentTime,
+		Writer:            writerName(),
+		ForceUnrecognized: forceUnrecognized,
+	}
+	if goalPercentEnabled() {
+		sheetsCfg.GoalPercent = func(entry WorkoutEntry) (int, bool) {
+			return goalPercent(entry.RepsSets, entry.Goal)
+		}
+	}
+	return calio.NewSheetsStorage(ctx, sheetsCfg)
+}
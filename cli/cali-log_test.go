@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// runCLI runs cali with args on a local log in a temporary directory, stdin
+// reading from the given text, and returns what it printed and its exit
+// code. Settings come from the environment only, never from a config file.
+func runCLI(t *testing.T, stdin string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	return runCLIIn(t, t.TempDir(), stdin, args...)
+}
+
+// testBackend is the CALI_STORAGE of runCLI: the local files, unless a
+// test records what a command does to them (see recordingLog).
+var testBackend = "local"
+
+// runCLIIn is runCLI with home as the home directory, so the backups,
+// state and log of one run are there for the next.
+func runCLIIn(t *testing.T, home, stdin string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	for name, value := range map[string]string{
+		"HOME":            home,
+		"XDG_CONFIG_HOME": filepath.Join(home, ".config"),
+		"XDG_DATA_HOME":   filepath.Join(home, ".local", "share"),
+		"XDG_STATE_HOME":  filepath.Join(home, ".local", "state"),
+		"CALI_STORAGE":    testBackend,
+		"CALI_LANG":       "en",
+	} {
+		t.Setenv(name, value)
+	}
+	if os.Getenv("CALI_LOG_DIR") == "" {
+		t.Setenv("CALI_LOG_DIR", filepath.Join(home, "log"))
+	}
+	savedConfig, savedLevel := configFile, outputLevel
+	configFile = ""
+	t.Cleanup(func() { configFile, outputLevel = savedConfig, savedLevel })
+
+	input := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(input, []byte(stdin), 0644); err != nil {
+		t.Fatal(err)
+	}
+	in, err := os.Open(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	savedStdin := os.Stdin
+	os.Stdin = in
+	defer func() { os.Stdin = savedStdin }()
+
+	stderr = captureOutput(t, &os.Stderr, func() {
+		stdout = captureOutput(t, &os.Stdout, func() {
+			code = Run(args)
+		})
+	})
+	return stdout, stderr, code
+}
+
+// fakeBrowser makes openURL record what it would open.
+func fakeBrowser(t *testing.T) *[]string {
+	t.Helper()
+	var opened []string
+	saved := openURL
+	openURL = func(target string) error {
+		opened = append(opened, target)
+		return nil
+	}
+	t.Cleanup(func() { openURL = saved })
+	return &opened
+}
+
+// withPlaylists maps the given playlists, and no others.
+func withPlaylists(t *testing.T, playlists map[string]string) {
+	t.Helper()
+	saved := lookupPlaylist
+	lookupPlaylist = func(exercise string) (string, bool) {
+		link, ok := playlists[exercise]
+		return link, ok
+	}
+	t.Cleanup(func() { lookupPlaylist = saved })
+}
+
+func TestParseTutorialArgs(t *testing.T) {
+	tests := []struct {
+		args            []string
+		exercise, level string
+		err             string // part of the error, "" for none
+	}{
+		{args: []string{"Pushups"}, exercise: "Pushups"},
+		{args: []string{"pushups "}, exercise: "Pushups"},
+		{args: []string{"Handstand Push-ups"}, exercise: "Handstand Push-ups"},
+		{args: []string{"Handstand", "Push-ups"}, exercise: "Handstand Push-ups"},
+		{args: []string{"Leg", "Raises"}, exercise: "Leg Raises"},
+		{args: []string{"Pushups", "Wall"}, exercise: "Pushups", level: "Wall"},
+		{args: []string{"Pushups", "3"}, exercise: "Pushups", level: "Kneeling"},
+		{args: []string{"Pushups", "step", "3"}, exercise: "Pushups", level: "Kneeling"},
+		{args: []string{"Handstand Push-ups", "Wall Headstand"}, exercise: "Handstand Push-ups", level: "Wall Headstand"},
+		{args: []string{"Handstand", "Push-ups", "Wall", "Headstand"}, exercise: "Handstand Push-ups", level: "Wall Headstand"},
+		{args: []string{"Leg", "Raises", "Hanging", "Knee"}, exercise: "Leg Raises", level: "Hanging Knee"},
+		{args: nil, err: "usage: cali --tutorial"},
+		{args: []string{"Pushups", "Planche"}, err: `unknown level "Planche" for Pushups`},
+		{args: []string{"Pushpus"}, err: `unknown exercise "Pushpus" (did you mean "Pushups"?)`},
+		{args: []string{"squat"}, err: `(did you mean "Squats"?)`},
+		{args: []string{"Pullup", "Full"}, err: `unknown exercise "Pullup Full" (did you mean "Pullups"?)`},
+		{args: []string{"Yoga"}, err: `unknown exercise "Yoga"`},
+	}
+	for _, tt := range tests {
+		exercise, level, err := parseTutorialArgs(tt.args)
+		if tt.err != "" {
+			var ce *cliError
+			if !errors.As(err, &ce) || ce.code != exitUsage || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseTutorialArgs(%q) = %v, want a usage error with %q", tt.args, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || exercise != tt.exercise || level != tt.level {
+			t.Errorf("parseTutorialArgs(%q) = %q, %q, %v, want %q, %q", tt.args, exercise, level, err, tt.exercise, tt.level)
+		}
+	}
+	if _, _, err := parseTutorialArgs([]string{"Yoga"}); strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("parseTutorialArgs(Yoga) = %v, want no suggestion", err)
+	}
+}
+
+// TestTutorialCommand checks cali --tutorial opens the level's video, the
+// exercise's playlist when the level is left out, and the channel's
+// playlists page for an exercise without one.
+func TestTutorialCommand(t *testing.T) {
+	const pushups = "https://www.youtube.com/playlist?list=PLpushups"
+	withPlaylists(t, map[string]string{"Pushups": pushups})
+	wall, _ := calio.Tutorial("Pushups", "Wall")
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--tutorial", "Pushups", "Wall"}, wall},
+		{[]string{"--tutorial", "Pushups"}, pushups},
+		{[]string{"--tutorial", "pushups"}, pushups},
+		{[]string{"--tutorial", "Squats"}, calio.PlaylistsURL},
+	}
+	for _, tt := range tests {
+		opened := fakeBrowser(t)
+		stdout, stderr, code := runCLI(t, "", tt.args...)
+		if code != 0 {
+			t.Fatalf("cali %q exited %d: %s", tt.args, code, stderr)
+		}
+		if !slices.Equal(*opened, []string{tt.want}) || !strings.Contains(stdout, tt.want) {
+			t.Errorf("cali %q opened %q and printed %q, want %q", tt.args, *opened, stdout, tt.want)
+		}
+	}
+
+	opened := fakeBrowser(t)
+	if _, stderr, code := runCLI(t, "", "--tutorial", "Pushpus"); code != exitUsage || !strings.Contains(stderr, `did you mean "Pushups"`) || len(*opened) > 0 {
+		t.Errorf("cali --tutorial Pushpus exited %d, opened %q: %s; want a suggestion and nothing opened", code, *opened, stderr)
+	}
+}
+
+// TestEntryLocation logs twice and checks each confirmation names the line
+// the entry was written to.
+func TestEntryLocation(t *testing.T) {
+	storage := pipedLog(t)
+	for line := 1; line <= 2; line++ {
+		stdout, stderr, code := runCLI(t, "", "log", "--exercise", "pushups", "--level", "full", "--reps", "10x2")
+		if code != 0 {
+			t.Fatalf("cali log exited %d: %s", code, stderr)
+		}
+		file := storage.FileFor(currentTime().Format(calio.DateLayout))
+		if want := fmt.Sprintf("Line %d of %s\n", line, file); !strings.Contains(stdout, want) {
+			t.Errorf("cali log printed %q, want %q", stdout, want)
+		}
+	}
+
+	shared := &calio.UserStorage{Storage: storage, Author: "ziad"}
+	if got := entryLocation(shared, WorkoutEntry{Date: "2026-03-04", RowIndex: 4}); got != "Line 5 of "+storage.FileFor("2026-03-04")+"\n" {
+		t.Errorf("entryLocation through a shared log = %q", got)
+	}
+	if got := entryLocation(storage, WorkoutEntry{Date: "2026-03-04", RowIndex: -1}); got != "" {
+		t.Errorf("entryLocation of an unknown row = %q", got)
+	}
+}
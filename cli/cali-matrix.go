@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// ANSI attributes for the highlighted cell of the terminal matrix.
+const (
+	matrixHighlight = "\033[1;7m" // bold, reverse video
+	matrixReset     = "\033[0m"
+)
+
+// levelMatrix is the progression chart of cali levels --matrix: the six
+// strength exercises as columns and their steps as rows. Ladders shorter than
+// the longest one leave their last cells empty.
+type levelMatrix struct {
+	Exercises []string
+	Levels    [][]string        // per exercise, in step order
+	Current   map[string]string // exercise -> level trained last; may be nil
+	Links     linker            // links level names to their tutorials
+}
+
+func buildLevelMatrix(current map[string]string) levelMatrix {
+	m := levelMatrix{Exercises: calio.Exercises(), Current: current}
+	for _, exercise := range m.Exercises {
+		m.Levels = append(m.Levels, calio.Levels(exercise))
+	}
+	return m
+}
+
+func (m levelMatrix) steps() int {
+	steps := 0
+	for _, levels := range m.Levels {
+		steps = max(steps, len(levels))
+	}
+	return steps
+}
+
+// cell returns the level and goal of exercise column col at step (from 0),
+// and whether the ladder has that step.
+func (m levelMatrix) cell(col, step int) (level, goal string, ok bool) {
+	if step >= len(m.Levels[col]) {
+		return "", "", false
+	}
+	level = m.Levels[col][step]
+	goal = resolveGoal(m.Exercises[col], level)
+	if _, ok := goalOverride(m.Exercises[col], level); ok {
+		goal += goalMark
+	}
+	return level, goal, true
+}
+
+func (m levelMatrix) isCurrent(col int, level string) bool {
+	return m.Current != nil && m.Current[m.Exercises[col]] == level
+}
+
+func (m levelMatrix) hasCurrent() bool {
+	return len(m.Current) > 0
+}
+
+// cellWidth counts runes rather than bytes, and none of the escape
+// sequences of colors and links, so padding stays aligned.
+func cellWidth(s string) int {
+	return utf8.RuneCountInString(stripEscapes(s))
+}
+
+func padCell(s string, w int) string {
+	return s + strings.Repeat(" ", max(0, w-cellWidth(s)))
+}
+
+// writeText renders the matrix for a terminal or printer width columns
+// wide. Each step takes two lines, the level name over its goal; the current
+// level is marked with "*" and, with color, shown in reverse video. When the
+// exercises don't fit side by side, they are split into tables of as many
+// as fit, one under the other.
+func (m levelMatrix) writeText(w io.Writer, color bool, width int) error {
+	stepWidth := max(cellWidth(msg("matrix.step")), 2)
+	var tables []string
+	for start := 0; start < len(m.Exercises); {
+		end, used := start+1, stepWidth+2+m.columnWidth(start)
+		for end < len(m.Exercises) && used+2+m.columnWidth(end) <= width {
+			used += 2 + m.columnWidth(end)
+			end++
+		}
+		part := levelMatrix{Exercises: m.Exercises[start:end], Levels: m.Levels[start:end], Current: m.Current, Links: m.Links}
+		tables = append(tables, part.table(color))
+		start = end
+	}
+	var legend []string
+	if m.hasCurrent() {
+		legend = append(legend, msg("matrix.legend"))
+	}
+	if hasGoalOverrides(m.Exercises...) {
+		legend = append(legend, msg("matrix.legend_goal"))
+	}
+	text := strings.Join(tables, "\n\n") + "\n"
+	if len(legend) > 0 {
+		text += "\n" + strings.Join(legend, "\n") + "\n"
+	}
+	_, err := io.WriteString(w, text)
+	return err
+}
+
+// columnWidth is the width of exercise column col: its widest header, level
+// name (with the current mark) or goal.
+func (m levelMatrix) columnWidth(col int) int {
+	width := cellWidth(m.Exercises[col])
+	for step := range m.Levels[col] {
+		level, goal, _ := m.cell(col, step)
+		name := level
+		if m.isCurrent(col, level) {
+			name += " *"
+		}
+		width = max(width, cellWidth(name), cellWidth(goal))
+	}
+	return width
+}
+
+// table renders the steps of every exercise of m as one table, without the
+// legend and the final line break.
+func (m levelMatrix) table(color bool) string {
+	stepHeader := msg("matrix.step")
+	stepWidth := max(cellWidth(stepHeader), 2)
+	widths := make([]int, len(m.Exercises))
+	for col := range m.Exercises {
+		widths[col] = m.columnWidth(col)
+	}
+
+	var b strings.Builder
+	row := func(first string, cells []string) {
+		b.WriteString(padCell(first, stepWidth))
+		for col, cell := range cells {
+			b.WriteString("  " + cell)
+			if col == len(cells)-1 {
+				break
+			}
+			// Highlighted cells are padded inside the escape codes.
+			if !strings.HasPrefix(cell, matrixHighlight) {
+				b.WriteString(strings.Repeat(" ", max(0, widths[col]-cellWidth(cell))))
+			}
+		}
+		b.WriteString("\n")
+	}
+	headers := make([]string, len(m.Exercises))
+	rules := make([]string, len(m.Exercises))
+	for col, exercise := range m.Exercises {
+		headers[col] = exercise
+		rules[col] = strings.Repeat("-", widths[col])
+	}
+	row(stepHeader, headers)
+	row(strings.Repeat("-", stepWidth), rules)
+
+	for step := 0; step < m.steps(); step++ {
+		if step > 0 {
+			b.WriteString("\n")
+		}
+		names := make([]string, len(m.Exercises))
+		goals := make([]string, len(m.Exercises))
+		for col := range m.Exercises {
+			level, goal, ok := m.cell(col, step)
+			if !ok {
+				continue
+			}
+			names[col], goals[col] = m.Links.tutorialLink(m.Exercises[col], level), goal
+			if m.isCurrent(col, level) {
+				names[col] += " *"
+				if color {
+					names[col] = matrixHighlight + padCell(names[col], widths[col]) + matrixReset
+					goals[col] = matrixHighlight + padCell(goals[col], widths[col]) + matrixReset
+				}
+			}
+		}
+		row(fmt.Sprintf("%*d", stepWidth, step+1), names)
+		row("", goals)
+	}
+
+	// Ragged rows leave blanks where their last columns would be.
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownEscaper keeps the goal mark from reading as emphasis.
+var markdownEscaper = strings.NewReplacer("*", `\*`)
+
+// writeMarkdown renders the matrix as a GitHub table, one step per row with
+// the goal under the level name; the current level is in bold.
+func (m levelMatrix) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| " + msg("matrix.step") + " |")
+	for _, exercise := range m.Exercises {
+		b.WriteString(" " + exercise + " |")
+	}
+	b.WriteString("\n| ---: |" + strings.Repeat(" --- |", len(m.Exercises)) + "\n")
+	for step := 0; step < m.steps(); step++ {
+		fmt.Fprintf(&b, "| %d |", step+1)
+		for col := range m.Exercises {
+			level, goal, ok := m.cell(col, step)
+			goal = markdownEscaper.Replace(goal)
+			switch {
+			case !ok:
+				b.WriteString("  |")
+			case m.isCurrent(col, level):
+				fmt.Fprintf(&b, " **%s**<br>**%s** |", level, goal)
+			default:
+				fmt.Fprintf(&b, " %s<br>%s |", level, goal)
+			}
+		}
+		b.WriteString("\n")
+	}
+	if m.hasCurrent() {
+		b.WriteString("\n" + msg("matrix.legend_markdown") + "\n")
+	}
+	if hasGoalOverrides(m.Exercises...) {
+		b.WriteString("\n" + markdownEscaper.Replace(msg("matrix.legend_goal")) + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// showLevelMatrix prints the chart with the user's goals, highlighting the
+// level trained last per exercise when the log can be read. Without it the
+// chart is still useful, so storage errors only show in --verbose.
+func showLevelMatrix(ctx context.Context, color, markdown bool) error {
+	current, err := matrixCurrentLevels(ctx)
+	if err != nil {
+		detail("Not highlighting current levels: %v\n", err)
+	}
+
+	m := buildLevelMatrix(current)
+	if markdown {
+		return m.writeMarkdown(os.Stdout)
+	}
+	m.Links = newLinker(os.Stdout)
+	return m.writeText(os.Stdout, color, terminalWidth())
+}
+
+// matrixCurrentLevels loads the goal overrides and returns the current level
+// per strength exercise: the pinned one or the one trained last.
+func matrixCurrentLevels(ctx context.Context) (map[string]string, error) {
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loadGoalOverrides(ctx, storage)
+	loadLevelPins(ctx, storage)
+	entries, err := storage.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	strength, _ := splitByCategory(calio.WithoutFuture(entries, currentTime()))
+	current := map[string]string{}
+	for _, key := range currentLevels(strength) {
+		current[key.Exercise] = key.Level
+	}
+	return current, nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+// matrixCurrent are the levels highlighted in the golden charts, including
+// the long "Assisted One-Leg" and the last step of the nine-step Leg Raises.
+var matrixCurrent = map[string]string{
+	"Pushups":    "Full",
+	"Squats":     "Assisted One-Leg",
+	"Leg Raises": "Hanging",
+}
+
+func TestLevelMatrixGolden(t *testing.T) {
+	tests := []struct {
+		golden  string
+		current map[string]string
+		render  func(m levelMatrix, w *strings.Builder) error
+	}{
+		{"matrix.txt", matrixCurrent, func(m levelMatrix, w *strings.Builder) error { return m.writeText(w, false, 200) }},
+		{"matrix.color.txt", matrixCurrent, func(m levelMatrix, w *strings.Builder) error { return m.writeText(w, true, 200) }},
+		// Too narrow for all six side by side.
+		{"matrix.narrow.txt", nil, func(m levelMatrix, w *strings.Builder) error { return m.writeText(w, false, 80) }},
+		{"matrix.md", matrixCurrent, func(m levelMatrix, w *strings.Builder) error { return m.writeMarkdown(w) }},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := tt.render(buildLevelMatrix(tt.current), &b); err != nil {
+			t.Fatal(err)
+		}
+		checkGolden(t, tt.golden, b.String())
+	}
+}
+
+// TestLevelMatrixWidth checks every line of the narrow chart fits its width.
+func TestLevelMatrixWidth(t *testing.T) {
+	var b strings.Builder
+	if err := buildLevelMatrix(matrixCurrent).writeText(&b, true, 80); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(b.String(), "\n") {
+		if cellWidth(line) > 80 {
+			t.Errorf("line %q is %d wide, over 80", line, cellWidth(line))
+		}
+	}
+}
+
+func TestLevelsMatrixCommand(t *testing.T) {
+	pipedLog(t)
+	stdout, stderr, code := runCLI(t, "", "levels", "--matrix", "--markdown")
+	if code != 0 || !strings.HasPrefix(stdout, "| Step | Pushups |") || strings.Contains(stdout, "**") {
+		t.Errorf("cali levels --matrix --markdown on an empty log exited %d, printed %q %s", code, stdout, stderr)
+	}
+	stdout, _, _ = runCLI(t, "", "levels", "--matrix", "--no-color")
+	if strings.Contains(stdout, "\033") || !strings.Contains(stdout, "Leg Raises") {
+		t.Errorf("cali levels --matrix --no-color printed %q", stdout)
+	}
+}
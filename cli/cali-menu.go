@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// menuSettings shape the exercise and level menus of cali -l to what the
+// user trains: CALI_EXERCISE_ORDER, CALI_HIDE_EXERCISES and
+// CALI_HIDE_LEVELS. They only change what is offered; hidden exercises and
+// levels stay valid everywhere else, so entries logged at them before read
+// and show as always.
+type menuSettings struct {
+	Order           []string // exercises listed first, in this order
+	HiddenExercises map[string]bool
+	HiddenLevels    map[exerciseLevel]bool
+}
+
+// exerciseMenu is the menu configuration in effect, set at startup.
+var exerciseMenu menuSettings
+
+// splitSetting splits a comma-separated setting, dropping empty items.
+func splitSetting(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseMenuSettings reads the menu settings:
+//
+//	CALI_EXERCISE_ORDER=Pullups, Pushups   listed first; the rest follow as usual
+//	CALI_HIDE_EXERCISES=Handstand Push-ups
+//	CALI_HIDE_LEVELS=Pushups: Wall, Pushups: Incline
+//
+// Names are matched as cali q matches them. Hiding every exercise of a
+// category, or every level of an exercise, is an error.
+func parseMenuSettings(getenv func(string) string) (menuSettings, error) {
+	menu := menuSettings{HiddenExercises: map[string]bool{}, HiddenLevels: map[exerciseLevel]bool{}}
+	for _, name := range splitSetting(getenv("CALI_EXERCISE_ORDER")) {
+		exercise, ok := matchExercise(name)
+		if !ok {
+			return menuSettings{}, fmt.Errorf("invalid CALI_EXERCISE_ORDER: unknown exercise %q", name)
+		}
+		if !slices.Contains(menu.Order, exercise) {
+			menu.Order = append(menu.Order, exercise)
+		}
+	}
+	for _, name := range splitSetting(getenv("CALI_HIDE_EXERCISES")) {
+		exercise, ok := matchExercise(name)
+		if !ok {
+			return menuSettings{}, fmt.Errorf("invalid CALI_HIDE_EXERCISES: unknown exercise %q", name)
+		}
+		menu.HiddenExercises[exercise] = true
+	}
+	for _, item := range splitSetting(getenv("CALI_HIDE_LEVELS")) {
+		name, levelName, ok := strings.Cut(item, ":")
+		if !ok {
+			return menuSettings{}, fmt.Errorf("invalid CALI_HIDE_LEVELS: %q is not <exercise>: <level>", item)
+		}
+		exercise, ok := matchExercise(strings.TrimSpace(name))
+		if !ok {
+			return menuSettings{}, fmt.Errorf("invalid CALI_HIDE_LEVELS: unknown exercise %q", strings.TrimSpace(name))
+		}
+		level, ok := matchLevel(exercise, strings.TrimSpace(levelName))
+		if !ok {
+			return menuSettings{}, fmt.Errorf("invalid CALI_HIDE_LEVELS: unknown level %q of %s", strings.TrimSpace(levelName), exercise)
+		}
+		menu.HiddenLevels[exerciseLevel{exercise, level}] = true
+	}
+
+	for _, exercises := range [][]string{calio.Exercises(), calio.MobilityExercises()} {
+		if len(menu.exercises(exercises)) == 0 {
+			return menuSettings{}, fmt.Errorf("CALI_HIDE_EXERCISES hides all of %s; leave at least one", strings.Join(exercises, ", "))
+		}
+	}
+	for _, exercise := range append(calio.Exercises(), calio.MobilityExercises()...) {
+		if len(menu.levels(exercise, "", false)) == 0 {
+			return menuSettings{}, fmt.Errorf("CALI_HIDE_LEVELS hides every level of %s; leave at least one", exercise)
+		}
+	}
+	return menu, nil
+}
+
+// checkTemplates rejects hiding what a workout template still names: the
+// template would offer an exercise or level the menus no longer do.
+func (m menuSettings) checkTemplates(templates []workoutTemplate) error {
+	for _, tmpl := range templates {
+		for _, item := range tmpl.Items {
+			if m.HiddenExercises[item.Exercise] {
+				return fmt.Errorf("CALI_HIDE_EXERCISES hides %s, which template %s still uses; remove it from %s%s first",
+					item.Exercise, tmpl.Name, templatePrefix, tmpl.Name)
+			}
+			if m.HiddenLevels[exerciseLevel{item.Exercise, item.Level}] {
+				return fmt.Errorf("CALI_HIDE_LEVELS hides %s - %s, which template %s still uses; remove it from %s%s first",
+					item.Exercise, item.Level, tmpl.Name, templatePrefix, tmpl.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// exercises returns the exercises of a menu as configured: those in Order
+// first, in that order, then the others in their usual order, without the
+// hidden ones.
+func (m menuSettings) exercises(all []string) []string {
+	var listed []string
+	for _, exercise := range m.Order {
+		if slices.Contains(all, exercise) && !m.HiddenExercises[exercise] {
+			listed = append(listed, exercise)
+		}
+	}
+	for _, exercise := range all {
+		if !slices.Contains(listed, exercise) && !m.HiddenExercises[exercise] {
+			listed = append(listed, exercise)
+		}
+	}
+	return listed
+}
+
+// levels returns the levels of exercise to offer, easiest first: every one
+// with all set, otherwise all but the hidden ones. current, the level
+// trained at, is always offered.
+func (m menuSettings) levels(exercise, current string, all bool) []string {
+	levels := calio.Levels(exercise)
+	if all {
+		return levels
+	}
+	return slices.DeleteFunc(levels, func(level string) bool {
+		return level != current && m.HiddenLevels[exerciseLevel{exercise, level}]
+	})
+}
+
+// dayPlan returns the exercises planned for day without the hidden ones,
+// in the configured order.
+func (m menuSettings) dayPlan(day string) []string {
+	return m.exercises(calio.DayPlan(day))
+}
+
+// configuredMenu reads the menu settings and checks them against the
+// workout templates.
+func configuredMenu(getenv func(string) string, templates []workoutTemplate) (menuSettings, error) {
+	menu, err := parseMenuSettings(getenv)
+	if err != nil {
+		return menuSettings{}, err
+	}
+	if err := menu.checkTemplates(templates); err != nil {
+		return menuSettings{}, err
+	}
+	return menu, nil
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// withMenu sets the menu configuration for a test.
+func withMenu(t *testing.T, env map[string]string) {
+	t.Helper()
+	menu, err := parseMenuSettings(envOf(env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := exerciseMenu
+	exerciseMenu = menu
+	t.Cleanup(func() { exerciseMenu = saved })
+}
+
+func TestParseMenuSettings(t *testing.T) {
+	menu, err := parseMenuSettings(envOf(map[string]string{
+		"CALI_EXERCISE_ORDER": " pullups, Pushups,, PULLUPS, l-sit",
+		"CALI_HIDE_EXERCISES": "handstand push-ups",
+		"CALI_HIDE_LEVELS":    "pushups: wall, Pushups:Incline",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Pullups", "Pushups", "L-Sit"}; !slices.Equal(menu.Order, want) {
+		t.Errorf("Order = %q, want %q", menu.Order, want)
+	}
+	if len(menu.HiddenExercises) != 1 || !menu.HiddenExercises["Handstand Push-ups"] {
+		t.Errorf("HiddenExercises = %v", menu.HiddenExercises)
+	}
+	if len(menu.HiddenLevels) != 2 || !menu.HiddenLevels[exerciseLevel{"Pushups", "Wall"}] || !menu.HiddenLevels[exerciseLevel{"Pushups", "Incline"}] {
+		t.Errorf("HiddenLevels = %v", menu.HiddenLevels)
+	}
+
+	for _, env := range []map[string]string{
+		{"CALI_EXERCISE_ORDER": "Pushups, Burpees"},
+		{"CALI_HIDE_EXERCISES": "Burpees"},
+		{"CALI_HIDE_LEVELS": "Pushups Wall"},
+		{"CALI_HIDE_LEVELS": "Burpees: Wall"},
+		{"CALI_HIDE_LEVELS": "Pushups: Diamond"},
+		{"CALI_HIDE_EXERCISES": "Bridge Hold, L-Sit, Twist"},
+		{"CALI_HIDE_EXERCISES": "Pushups, Squats, Pullups, Leg Raises, Bridges, Handstand Push-ups"},
+		{"CALI_HIDE_LEVELS": "Twist: " + strings.Join(levelsOf("Twist"), ", Twist: ")},
+	} {
+		if _, err := parseMenuSettings(envOf(env)); err == nil {
+			t.Errorf("parseMenuSettings(%v) accepted it", env)
+		}
+	}
+}
+
+// levelsOf returns the level names of exercise.
+func levelsOf(exercise string) []string {
+	return exerciseMenu.levels(exercise, "", true)
+}
+
+func TestMenuExercises(t *testing.T) {
+	withMenu(t, map[string]string{
+		"CALI_EXERCISE_ORDER": "Bridges, Pullups, Twist",
+		"CALI_HIDE_EXERCISES": "Handstand Push-ups, Squats",
+	})
+	for _, tt := range []struct {
+		got, want []string
+	}{
+		{exercisesForCategory(""), []string{"Bridges", "Pullups", "Pushups", "Leg Raises"}},
+		{exercisesForCategory("mobility"), []string{"Twist", "Bridge Hold", "L-Sit"}},
+		{exerciseMenu.dayPlan("A"), []string{"Pushups"}},
+		{exerciseMenu.dayPlan("B"), []string{"Pullups", "Leg Raises"}},
+		{exerciseMenu.dayPlan("C"), []string{"Bridges"}},
+	} {
+		if !slices.Equal(tt.got, tt.want) {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+
+	out := captureOutput(t, &os.Stdout, func() {
+		if got := chooseExercise(bufio.NewReader(strings.NewReader("2\n")), exercisesForCategory("")); got != "Pullups" {
+			t.Errorf("choice 2 = %q, want Pullups", got)
+		}
+	})
+	if strings.Contains(out, "Handstand") || !strings.Contains(out, "1. Bridges") {
+		t.Errorf("exercise menu:\n%s", out)
+	}
+}
+
+// TestMenuLevels checks hidden levels leave the level chooser unless they
+// are the current level or all levels are asked for.
+func TestMenuLevels(t *testing.T) {
+	withLevelPins(t, nil)
+	withMenu(t, map[string]string{"CALI_HIDE_LEVELS": "Pushups: Wall, Pushups: Incline, Squats: Jackknife"})
+	all := levelsOf("Pushups")
+	for _, tt := range []struct {
+		current string
+		all     bool
+		want    []string
+	}{
+		{"", false, all[2:]},
+		{"Full", false, all[2:]},
+		{"Incline", false, all[1:]},
+		{"", true, all},
+	} {
+		if got := exerciseMenu.levels("Pushups", tt.current, tt.all); !slices.Equal(got, tt.want) {
+			t.Errorf("levels(Pushups, %q, %v) = %q, want %q", tt.current, tt.all, got, tt.want)
+		}
+	}
+
+	var level string
+	out := captureOutput(t, &os.Stdout, func() {
+		level = chooseLevel(bufio.NewReader(strings.NewReader("1\n")), "Pushups", "", false, false)
+	})
+	if level != "Kneeling" || strings.Contains(out, "Wall") || strings.Contains(out, "Incline") {
+		t.Errorf("chose %q from:\n%s", level, out)
+	}
+	out = captureOutput(t, &os.Stdout, func() {
+		level = chooseLevel(bufio.NewReader(strings.NewReader("1\n")), "Pushups", "", false, true)
+	})
+	if level != "Wall" || !strings.Contains(out, "2. Incline") {
+		t.Errorf("with all levels, chose %q from:\n%s", level, out)
+	}
+}
+
+// TestMenuTemplates checks a template naming a hidden exercise or level is
+// a config error, and hiding what no template uses is not.
+func TestMenuTemplates(t *testing.T) {
+	templates, err := configuredTemplates([]string{"CALI_TEMPLATE_A=Pushups, Full, 20x2; Squats, current, 30x2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"CALI_HIDE_EXERCISES": "Handstand Push-ups", "CALI_HIDE_LEVELS": "Pushups: Wall"}, ""},
+		{map[string]string{"CALI_HIDE_EXERCISES": "squats"}, "hides Squats, which template A still uses"},
+		{map[string]string{"CALI_HIDE_LEVELS": "pushups: full"}, "hides Pushups - Full, which template A still uses"},
+	} {
+		_, err := configuredMenu(envOf(tt.env), templates)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("configuredMenu(%v) = %v, want %q", tt.env, err, tt.want)
+		}
+	}
+}
+
+// TestHiddenCommand checks hiding only trims the menus: entries at hidden
+// exercises and levels are still accepted and listed, and a template using
+// a hidden exercise stops cali.
+func TestHiddenCommand(t *testing.T) {
+	pipedLog(t)
+	t.Setenv("CALI_HIDE_EXERCISES", "Squats")
+	t.Setenv("CALI_HIDE_LEVELS", "Pushups: Wall")
+
+	stdout, stderr, code := runCLI(t, "", "today")
+	if code != 0 || !strings.Contains(stdout, "- Pushups") || strings.Contains(stdout, "Squats") {
+		t.Errorf("cali today exited %d: %q %s", code, stdout, stderr)
+	}
+	for _, args := range [][]string{
+		{"q", "squats full 30x2"},
+		{"q", "pushups wall 40x3"},
+	} {
+		if _, stderr, code := runCLI(t, "y\ny\n", args...); code != 0 {
+			t.Fatalf("cali %s exited %d: %s", strings.Join(args, " "), code, stderr)
+		}
+	}
+	stdout, _, _ = runCLI(t, "", "history")
+	if !strings.Contains(stdout, "Squats") || !strings.Contains(stdout, "Pushups - Wall") {
+		t.Errorf("cali history doesn't list the hidden exercise and level:\n%s", stdout)
+	}
+
+	t.Setenv("CALI_TEMPLATE_A", "Squats, current, 30x2")
+	if _, stderr, code := runCLI(t, "", "history"); code != exitUsage || !strings.Contains(stderr, "template A") {
+		t.Errorf("a template using a hidden exercise exited %d: %s", code, stderr)
+	}
+}
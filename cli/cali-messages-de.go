@@ -0,0 +1,468 @@
+package cli
+
+// German messages. Ids left out (such as the help text) fall back to English.
+var messagesDE = map[string]string{
+	"date.format": "DD.MM.YYYY",
+	"answer.yes":  "j,ja,y,yes",
+
+	// Logging dialogue
+	"log.day_plan":                 "Trainingsplan:",
+	"log.day_plan_day":             "  Tag %s\n",
+	"log.previous_day":             "Letzter Trainingstag: %s (%s)\n\n",
+	"log.day_prompt":               "Tag (A/B/C): ",
+	"log.day_mismatch":             "%s ist normalerweise eine Übung für Tag %s.",
+	"log.day_mismatch_prompt":      "[Enter] trotzdem eintragen, [s] zu Tag %s wechseln, [r] andere Übung wählen: ",
+	"log.choose_exercise":          "\nÜbung wählen:",
+	"log.choose_level":             "\nStufe für %s wählen:\n",
+	"log.enter_number":             "Nummer eingeben: ",
+	"log.enter_number_default":     "Nummer eingeben [%d]: ",
+	"log.invalid_exercise":         "Ungültige Auswahl, nehme %s\n",
+	"log.invalid_level":            "Ungültige Auswahl, nehme die erste Stufe",
+	"log.open_tutorial":            "Tutorial für %s - %s öffnen?%s (j/N): ",
+	"log.watched_on":               " (angesehen am %s)",
+	"log.tutorial_opened":          "Tutorial geöffnet. Beende ohne Eintrag.",
+	"log.next_playlist":            "Noch kein Tutorial für %[1]s - %[2]s. Playlist für %[1]s öffnen? (j/N): ",
+	"log.next_no_video":            "Noch kein Video für %s - %s; die Hinweise zeigt cali describe.\n",
+	"log.reps_sets_prompt":         "Wdh.×Sätze: ",
+	"log.reps_sets_prompt_default": "Wdh.×Sätze [%s]: ",
+	"log.hold_prompt_default":      "Haltezeit [%s]: ",
+	"log.overload_reps":            "Letztes Mal: %s am %s — versuch %s oder eine Pausen-Wiederholung\n",
+	"log.overload_hold":            "Letztes Mal: %s am %s — versuch %s\n",
+	"log.overload_next":            "Letztes Mal: %s am %s hat den Progressionsstandard erreicht — weiter mit %s\n",
+	"log.hold_prompt":              "Haltezeit: ",
+	"log.comment_prompt":           "Kommentar (optional): ",
+	"log.issue_prompt":             "Schmerzen/Probleme (optional): ",
+	"log.extra_prompt":             "%s (optional): ",
+	"log.extra_choice":             "%s (%s, optional): ",
+	"log.extra_invalid":            "Bitte antworte mit einem von: %s",
+	"log.extra_one_word":           "Bitte antworte mit einem Wort.",
+	"log.flag_note":                "Notiz vom %s: %s %s bei %s %s\n",
+	"log.deload_target":            "Deload-Ziel: %s (%d%% von %s am %s)\n",
+	"log.readiness_prompt":         "Bereitschaft heute, 1-%d (Schlaf, Muskelkater; Enter überspringt): ",
+	"log.readiness_invalid":        "%q ist kein Bereitschaftswert (1 bis %d)",
+	"log.readiness_low":            "Bereitschaft %d/%d — heute Deload-Ziele erwägen: %s ~%s statt %s (%d%%)\n",
+	"log.readiness_low_rest":       "Bereitschaft %d/%d — heute eine leichte Einheit oder einen Ruhetag erwägen\n",
+	"log.logged":                   "\n✓ Erfolgreich eingetragen",
+	"log.saved":                    "Gespeichert: %s | %s | %s - %s | %s\n",
+	"log.duration":                 "Trainingsdauer: %d Min.\n",
+	"log.saved_row":                "Zeile %d von %q: %s\n",
+	"log.saved_line":               "Zeile %d von %s\n",
+	"log.standard_met":             "Standard %s erreicht\n",
+	"log.load_prompt":              "Zusatzgewicht (z. B. +10kg, Enter für keins): ",
+	"log.load_invalid":             "Das Zusatzgewicht %q ist nicht lesbar (z. B. +10kg, 22.5lb oder 0 für keins)",
+	"log.load_not_counted":         "Standards zählen nur die Wiederholungen; das Zusatzgewicht wird nicht angerechnet\n",
+	"log.achievement":              "⭑ %s - %s: Progressionsstandard zum ersten Mal erreicht\n",
+	"achievements.none":            "Noch keine Stufe abgeschlossen.",
+	"sync.sheet":                   "Tabelle",
+	"sync.local":                   "lokales Log (%s)",
+	"sync.copied":                  "%d Einträge kopiert (%s → %s):\n",
+	"sync.would_copy":              "Würde %d Einträge kopieren (%s → %s, --dry-run):\n",
+	"sync.conflicts":               "%d Einträge unterscheiden sich nur im Kommentar (%s ↔ %s); unverändert gelassen:\n",
+	"sync.conflict":                "  %s %s - %s %s: %s: %s, %s: %s\n",
+	"timer.left":                   "Pause: %s",
+	"timer.done":                   "Pause vorbei, nächster Satz!",
+	"timer.invalid":                "Die Pausenzeit %q ist nicht lesbar (z. B. 90s, 2min oder 1:30)",
+	"timer.notify_title":           "cali",
+	"timer.notify_message":         "%s Pause vorbei: Zeit für den nächsten Satz",
+	"log.choose_protocol":          "\nProtokoll wählen:",
+	"log.invalid_protocol":         "Ungültige Auswahl, nehme EMOM",
+	"log.duration_default":         "Dauer (Standard %s): ",
+	"log.duration_prompt":          "Dauer (z. B. 10min): ",
+	"log.invalid_duration":         "Ungültige Dauer. Z. B. 10min oder 90s (mindestens %s)\n",
+	"log.reps_per_round":           "Wdh. pro Runde: ",
+	"log.rounds_completed":         "Geschaffte Runden: ",
+	"log.whole_number":             "Bitte eine ganze Zahl größer 0 eingeben",
+	"log.hold_ambiguous":           "%q ist mehrdeutig, bitte Einheit angeben (z. B. %ss oder %smin)",
+	"log.hold_invalid":             "ungültige Haltezeit %q (Beispiele: 90s, 1:30, 2min, 2min x2)",
+	"log.input_closed":             "Eingabe beendet, bevor das Training vollständig war",
+
+	// Session lock
+	"session.active":      "Eine andere cali-Eintragung scheint aktiv zu sein (PID %d, gestartet vor %s). Trotzdem fortfahren? (j/N): ",
+	"session.age_seconds": "%d Sekunde(n)",
+	"session.age_minutes": "%d Minute(n)",
+	"session.age_hours":   "%d Stunde(n)",
+
+	// Standards
+	"tier.beginner":     "Anfänger",
+	"tier.intermediate": "Fortgeschritten",
+	"tier.progression":  "Progression",
+	"tier.summary":      "Anfänger %s · Fortgeschritten %s · Progression %s",
+
+	// Listings
+	"list.total":             "Gesamt: %d Einheit(en)\n",
+	"list.row":               "%s | Tag %s | %s - %s | %s | %s\n",
+	"list.numbered_row":      "[%d] Tag %s | %s - %s | %s | %s\n",
+	"list.lead":              "%s | Tag %s",
+	"list.numbered_lead":     "[%d] Tag %s",
+	"history.empty":          "Noch keine Einheiten eingetragen",
+	"history.empty_filtered": "Noch keine Arbeitssätze eingetragen; --all-types zeigt auch Aufwärmsätze, Mobilität und Ruhetage",
+	"list.rest_lead":         "%s | Ruhetag",
+	"history.header":         "Letzte 10 Einheiten:",
+	"search.empty":           "Keine Einheiten am %s gefunden\n",
+	"search.header":          "Einheiten am %s:\n",
+	"search.actions":         "Einen löschen: cali -r --date %s --index <Nummer>\n",
+	"flagged.empty":          "Keine Einheiten mit Markierung %q\n",
+	"flagged.header":         "Einheiten mit Markierung %q:\n",
+	"grep.empty":             "Keine Einheiten mit einem Kommentar passend zu %q\n",
+	"grep.header":            "Einheiten mit einem Kommentar passend zu %q:\n",
+	"import.empty":           "Keine Zeilen zum Importieren in %s\n",
+	"import.dry_run":         "Würde %d Einheit(en) aus %s importieren (--dry-run):\n",
+	"import.done":            "%d Einheit(en) aus %s importiert\n",
+	"remove.date_prompt":     "Datum suchen (JJJJ-MM-TT, yesterday, mon, 3d ago, jan-20): ",
+	"remove.header":          "\nEinheiten am %s:\n",
+	"remove.index_prompt":    "\nNummer zum Löschen (0 bricht ab): ",
+	"remove.cancelled":       "Abgebrochen",
+	"remove.done":            "✓ Eintrag gelöscht",
+	"remove.removed":         "Gelöscht: %s | Tag %s | %s - %s | %s (Ziel %s)\n",
+	"remove.removed_comment": "Kommentar: %s\n",
+	"remove.undo_prompt":     "Rückgängig machen? (j/N, %d s): ",
+	"remove.undo_expired":    "Keine Antwort; der Eintrag bleibt gelöscht",
+	"remove.undone":          "✓ Eintrag an seiner alten Stelle wiederhergestellt",
+	"remove.undone_appended": "✓ Eintrag am Ende des Logs wiederhergestellt; seine alte Stelle ließ sich nicht zurückholen",
+	"remove.confirm":         "Diesen Eintrag löschen? (j/N): ",
+	"remove.no_index":        "es gibt keinen Eintrag %d am %s (cali -s listet %d)",
+	"remove.changed":         "die Einträge vom %s haben sich während der Auswahl geändert; nichts wurde gelöscht, cali -r erneut ausführen",
+
+	// Today
+	"today.nothing":         "Heute noch nichts eingetragen (%s)\n",
+	"today.suggested_after": "Vorschlag: Tag %s (zuletzt Tag %s am %s)\n",
+	"today.suggested":       "Vorschlag: Tag %s\n",
+	"today.header_day":      "Heute (%s) - Tag %s:\n",
+	"today.header":          "Heute (%s):\n",
+	"today.done":            "Erledigt: %s ✓",
+	"today.remaining":       "Offen: %s",
+	"today.all_done":        "Alle geplanten Übungen erledigt",
+	"today.also_logged":     "Außerdem: %s",
+
+	// Stats
+	"stats.header":            "Trainingsstatistik:",
+	"stats.total":             "Einheiten gesamt:    %d\n",
+	"stats.last_7_days":       "Letzte 7 Tage:       %d\n",
+	"stats.days_since":        "Tage seit letzter:   %d\n",
+	"stats.goals_met":         "Ziele erreicht:      %d\n",
+	"stats.total_reps":        "Wdh. gesamt:         %d\n",
+	"stats.hold_time":         "Haltezeit gesamt:    %.1f min\n",
+	"stats.intervals":         "Intervall-Einheiten: %d\n",
+	"stats.deloads":           "Deload-Einheiten:    %d\n",
+	"stats.readiness":         "Ø Bereitschaft:      %.1f/%d (%d Tag(e))\n",
+	"stats.warmups":           "Aufwärmsätze:        %d (oben nicht gezählt)\n",
+	"stats.rest_days":         "Geplante Ruhetage:   %d\n",
+	"stats.rest_days_reasons": "Geplante Ruhetage:   %d (%s)\n",
+	"stats.streak":            "Aktuelle Serie:      %d Tag(e) (%d trainiert, %d Ruhe)\n",
+	"stats.per_exercise":      "\nPro Übung:",
+	"stats.records":           "\nBestleistungen:",
+	"stats.plateaus":          "\nPlateaus (keine Steigerung in den letzten %d Einheiten):\n",
+	"stats.readiness_weeks":   "\nBereitschaft pro Woche (Durchschnitt):",
+	"stats.readiness_week":    "  Woche ab %s  %.1f (%d Tag(e))\n",
+	"stats.mobility":          "\nMobilität:",
+	"stats.sessions":          "Einheiten",
+	"stats.hold_label":        "Haltezeit",
+	"stats.times_header":      "Wann du trainierst (Einheiten nach Beginn, %d-Stunden-Abschnitte, %s):",
+	"stats.times_weekdays":    "Werktags",
+	"stats.times_weekends":    "Wochenende",
+	"stats.times_unknown":     "Zeit unbekannt: %d Einheit(en), erfasst bevor Zeiten gespeichert wurden oder an einem späteren Tag",
+
+	// Tutorials, levels and descriptions
+	"tutorial.no_playlist":           "Keine Playlist für %s hinterlegt, öffne die Kanal-Playlists...\n",
+	"tutorial.opening_list":          "Öffne Playlist für %s...\n",
+	"tutorial.opening":               "Öffne Tutorial für %s - %s...\n",
+	"tutorial.not_mapped":            "kein Tutorial für %s - %s hinterlegt",
+	"tutorials.all_watched":          "Alle Tutorials angesehen",
+	"tutorials.none":                 "Keine Tutorials hinterlegt",
+	"matrix.step":                    "Stufe",
+	"matrix.legend":                  "* zuletzt trainierte Stufe",
+	"matrix.legend_markdown":         "**Fett**: zuletzt trainierte Stufe",
+	"matrix.legend_goal":             "15x2* selbst gesetztes Ziel (cali goal list)",
+	"describe.header":                "%s - Stufe %d: %s (Ziel: %s)\n",
+	"describe.none":                  "  Keine Beschreibung vorhanden",
+	"error.future_date":              "%[1]d Eintrag/Einträge mehr als einen Tag nach heute (%[3]s) datiert, der erste %[2]s: Datum und Uhr dieses Geräts prüfen, oder mit --allow-future trotzdem speichern",
+	"error.unknown_exercise":         "unbekannte Übung %q",
+	"error.unknown_exercise_suggest": "unbekannte Übung %q (meintest du %q?)",
+	"error.unknown_level":            "unbekannte Stufe %q für %s",
+	"error.invalid_date":             "ungültiges Datum %q (JJJJ-MM-TT oder z. B. yesterday, last tue, 3d ago, jan-20)",
+	"error.invalid_choice":           "ungültige Auswahl %q",
+	"error.prefix":                   "Fehler: %v\n",
+	"error.interrupted":              "Abgebrochen\n",
+	"error.auth_refused":             "Google hat die Zugangsdaten abgelehnt. Wurde der Dienstkonto-Schlüssel rotiert, lade einen neuen JSON-Schlüssel herunter und setze CALI_GOOGLE_CREDENTIALS_JSON darauf (oder führe cali auth aus).\n",
+	"error.key_refused":              "Google hat den Dienstkonto-Schlüssel in %s abgelehnt (%s, Schlüssel-ID %s; Datei zuletzt geändert %s, Schlüssel %d Tage alt). Rotiert deine Organisation Schlüssel, wurde dieser wohl deaktiviert oder gelöscht: lade einen neuen JSON-Schlüssel für das Dienstkonto herunter und setze CALI_GOOGLE_CREDENTIALS_JSON darauf (oder führe cali auth aus).\n",
+	"error.key_expired":              "Der Schlüssel in %s ist am %s abgelaufen.\n",
+	"error.unrecognized_tab":         "Prüfe, ob CALI_SHEET_ID und --sheet auf dein Trainingsprotokoll zeigen. Um trotzdem in diesen Tab zu schreiben, gib --force-unrecognized an.\n",
+
+	// Keyring
+	"auth.sheet_id_prompt":      "Spreadsheet-ID: ",
+	"auth.sheet_name_prompt":    "Tabellenblatt (Standard %s): ",
+	"auth.credentials_prompt":   "Pfad zur Service-Account-JSON: ",
+	"auth.sheet_id_required":    "eine Spreadsheet-ID ist erforderlich",
+	"auth.credentials_required": "ein Pfad zu den Zugangsdaten ist erforderlich",
+	"auth.stored":               "✓ Sheets-Einstellungen im Schlüsselbund gespeichert",
+	"auth.cleared":              "✓ Sheets-Einstellungen aus dem Schlüsselbund entfernt",
+	"auth.unset":                "(nicht gesetzt)",
+	"auth.keyring_unavailable":  "Warnung: Schlüsselbund nicht verfügbar (%v); es werden nur Umgebungsvariablen genutzt\n",
+
+	// Einrichtungsassistent
+	"wizard.welcome":          "Willkommen bei cali! Noch ist nichts eingerichtet, also wähle, wo Workouts gespeichert werden.\n(Mit cali --no-wizard überspringen.)",
+	"wizard.backend_prompt":   "  1. Google Sheets\n  2. Lokale Dateien\nSpeicher (Standard 2): ",
+	"wizard.backend_invalid":  "%q ist keine der Möglichkeiten, gib 1 oder 2 ein.",
+	"wizard.sheets_intro":     "Du brauchst die ID der Tabelle (aus ihrer URL) und eine Dienstkonto-JSON-Datei mit Bearbeitungszugriff darauf.",
+	"wizard.checking":         "Zugriff auf die Tabelle wird geprüft…",
+	"wizard.check_failed":     "✗ %v",
+	"wizard.retry":            "Nochmal versuchen? (J/n): ",
+	"wizard.sheets_ok":        "✓ Mit der Tabelle verbunden",
+	"wizard.local_dir_prompt": "Log-Verzeichnis (Standard %s): ",
+	"wizard.saved":            "✓ Einstellungen in %s gespeichert",
+	"wizard.log_now":          "Jetzt das erste Workout eintragen? (J/n): ",
+
+	// Status
+	"status.last":      "Zuletzt trainiert: %s (vor %d Tag(en))\n",
+	"status.next":      "Als Nächstes: Tag %s\n",
+	"status.this_week": "Diese Woche: %d Einheit(en)\n",
+
+	// Reminders
+	"rest.saved":           "☾ %s als Ruhetag eingetragen\n",
+	"rest.already":         "Heute ist schon als Ruhetag eingetragen",
+	"rest.trained":         "Heute ist schon ein Training eingetragen; es zählt vor einem Ruhetag, daher wurde nichts gespeichert",
+	"rest.none":            "Keine Ruhetage eingetragen",
+	"rest.already_on":      "%s ist schon als Ruhetag eingetragen\n",
+	"rest.trained_on":      "Am %s ist schon ein Training eingetragen; es zählt vor einem Ruhetag, daher wurde nichts gespeichert\n",
+	"plan.header":          "Voraussichtliche Woche (aus deinem Protokoll und der Rotation hochgerechnet; eine Prognose, keine Verpflichtung):\n",
+	"plan.session":         "  %s  Tag %s\n",
+	"plan.logged":          "  %s  Tag %s (eingetragen)\n",
+	"plan.rest":            "  %s  Ruhetag\n",
+	"plan.marked_rest":     "  %s  Ruhetag %s\n",
+	"plan.basis":           "%d Einheit(en) pro Woche (CALI_SESSIONS_PER_WEEK) an %s, den Tagen, an denen du in den letzten %d Wochen am häufigsten trainiert hast.\n",
+	"plan.basis_spread":    "%d Einheit(en) pro Woche (CALI_SESSIONS_PER_WEEK), verteilt auf %s; keine Einheiten in den letzten %d Wochen, aus denen sich deine Tage ablesen ließen.\n",
+	"plan.shifts":          "Trainierst du einen anderen Tag oder an einem anderen Datum, verschiebt sich der Rest; cali rest --date trägt einen Ruhetag im Voraus ein.\n",
+	"rest.superseded":      "(trainiert, zählt als Training)",
+	"quick.empty":          "nichts einzutragen; z. B. \"B pullups full 8x2 gut gelaufen\"",
+	"quick.bad_exercise":   "%q (Wort %d) ist keine Übung; z. B. pushups, pullups, leg raises oder hspu",
+	"quick.no_level":       "Stufe für %s fehlt, z. B. full oder die Stufennummer",
+	"quick.bad_level":      "%q (Wort %d) ist keine Stufe von %s (siehe cali levels)",
+	"quick.no_reps":        "Wiederholungen nach %s %s fehlen, z. B. 8x2",
+	"quick.bad_reps":       "%q sind keine Wiederholungen (z. B. 8x2, 8,7,6 oder 12)",
+	"quick.word":           "Wort %d",
+	"quick.confirm":        "Speichern? (J/n): ",
+	"goal.saved":           "✓ Ziel für %s %s auf %s gesetzt (Standard %s)\n",
+	"goal.removed":         "✓ Ziel für %s %s wieder auf Standard %s\n",
+	"goal.unchanged":       "Ziel für %s %s ist schon %s",
+	"goal.not_set":         "%s %s hat kein eigenes Ziel; es gilt der Standard %s",
+	"goal.invalid":         "Ziel %q nicht lesbar (z. B. 15x2, 8,7,6, 90s oder 2min x2)",
+	"goal.kind_mismatch":   "Ziel %q passt nicht zu %s %s, das wie %s gemessen wird (Wiederholungen oder Haltezeit)",
+	"goal.legend":          "* eigenes Ziel (cali goal list)",
+	"goal.source_builtin":  "Standard",
+	"goal.source_override": "eigenes (Standard %s)",
+
+	"levels.pinned":          "✓ %s auf %s festgelegt; Erfassen und Fortschritt nutzen sie bis cali levels unset\n",
+	"levels.unpinned":        "✓ %s ist nicht mehr festgelegt; die aktuelle Stufe folgt wieder der letzten Einheit\n",
+	"levels.pin_unchanged":   "%s ist schon auf %s festgelegt",
+	"levels.not_pinned":      "%s ist nicht festgelegt; die aktuelle Stufe folgt der letzten Einheit",
+	"levels.current_pinned":  "← aktuell (festgelegt)",
+	"levels.current_derived": "← aktuell (zuletzt erfasst)",
+	"self.installed":         "✓ %s installiert\n",
+	"self.already":           "%s ist das laufende Programm; nichts zu installieren",
+	"self.uninstalled":       "✓ %s entfernt\n",
+	"self.not_installed":     "%s ist nicht installiert",
+	"self.not_cali":          "%s existiert und ist nicht cali; mit --force ersetzen oder entfernen",
+	"self.no_permission":     "kein Schreibzugriff auf %s; mit --dir einen anderen Ordner wählen oder mit erhöhten Rechten (z. B. sudo) ausführen",
+	"self.not_on_path":       "%s ist noch nicht im PATH. Hinzufügen (in %s), dann ein neues Terminal öffnen:\n",
+	"remind.nudge":           "Heute noch kein Training eingetragen. Als Nächstes: Tag %s",
+	"remind.installed":       "✓ Erinnerung eingerichtet (%v)\n",
+	"remind.uninstalled":     "✓ Erinnerung entfernt",
+	"remind.not_installed":   "Keine Erinnerung eingerichtet",
+	"remind.modified":        "%s wurde von Hand geändert; mit --force überschreiben",
+	"remind.windows_hint":    "Unter Windows gibt es keine automatische Einrichtung; diesen Befehl im Terminal ausführen:",
+	"remind.file_missing":    "nicht eingerichtet",
+	"remind.file_generated":  "eingerichtet",
+	"remind.file_edited":     "eingerichtet (von Hand geändert)",
+
+	// Doctor
+	"doctor.ok":                "Keine Probleme gefunden",
+	"doctor.key_old":           "Der Dienstkonto-Schlüssel %s ist %d Tage alt (mehr als %d, siehe CALI_KEY_MAX_AGE). Rotiert deine Organisation Schlüssel, ersetze ihn, bevor er nicht mehr funktioniert.\n",
+	"doctor.key_expires":       "Der Dienstkonto-Schlüssel %s läuft am %s ab; ersetze ihn vorher.\n",
+	"doctor.key_expired":       "Der Dienstkonto-Schlüssel %s ist am %s abgelaufen; lade einen neuen herunter.\n",
+	"doctor.future_header":     "%d Eintrag/Einträge mehr als einen Tag in der Zukunft; Statistik, Status und Tagesrotation ignorieren sie:\n",
+	"doctor.schemas":           "Zeilenschemas: %s\n",
+	"doctor.schema_unmarked":   "%d ohne Markierung (gelesen als v1)",
+	"doctor.schema_count":      "%d v%d",
+	"doctor.newer_schema":      "⚠ %d Zeile(n) mit Schema bis v%d, geschrieben von %s; dieses cali liest v%d und ignoriert unbekannte Felder\n",
+	"doctor.newer_hint":        "cali aktualisieren, um sie vollständig zu lesen (cali --check-update).",
+	"doctor.writer_unknown":    "einer unbekannten Version",
+	"doctor.reformatted_dates": "%d Zeile(n) mit Datum in einem anderen Format; cali liest sie als JJJJ-MM-TT:\n",
+	"doctor.unreadable_dates":  "%d Zeile(n) mit einem Datum, das cali nicht lesen kann; keine Suche, kein Zeitraum und keine Statistik enthält sie:\n",
+	"doctor.date_hint":         "Datum als JJJJ-MM-TT eingeben (Datumsspalte als Nur-Text formatieren, damit die Tabelle es so lässt).",
+	"doctor.coerced":           "%d Zeile(n) mit Leistung oder Gewicht, die die Tabelle in ein Datum, eine Uhrzeit oder eine Zahl verwandelt hat; keine Statistik zählt sie:\n",
+	"doctor.coerced_hint":      "So neu eingeben, wie cali sie schreibt, z. B. 10-30x2 oder 1:30. cali formatiert diese Spalten beim nächsten Schreiben in den Tab, oder mit cali sheet format, als Nur-Text, damit die Tabelle die Eingabe lässt.",
+	"doctor.goal_mismatches":   "%d Eintrag/Einträge speichern ein Ziel, das nicht mehr das der Stufe ist (gespeichert → aktuell):\n",
+	"doctor.goal_unknown":      "%d Eintrag/Einträge gehören zu einer Stufe, die cali nicht kennt; ihr Ziel bleibt unverändert:\n",
+	"doctor.goals_ok":          "Alle gespeicherten Ziele entsprechen den aktuellen",
+	"doctor.goal_fix_hint":     "Mit cali doctor --fix-goals werden die aktuellen Ziele gespeichert.",
+	"doctor.goal_fix_confirm":  "Ziel von %d Eintrag/Einträgen neu schreiben? (j/N): ",
+	"doctor.goals_fixed":       "✓ Ziel von %d Eintrag/Einträgen neu geschrieben\n",
+	"doctor.name_mismatches":   "%d Eintrag/Einträge schreiben Übung oder Stufe anders als cali (gespeichert → kanonisch):\n",
+	"doctor.name_unknown":      "%d Eintrag/Einträge nennen eine Übung oder Stufe, die cali nicht kennt; sie bleiben unverändert:\n",
+	"paths.legacy":             "Deine Daten liegen in %[1]s, wo ältere Versionen sie ablegten. Verschiebe die Dateien nach %[2]s und entferne %[1]s, um den Standardort zu nutzen.\n",
+	"paths.legacy_split":       "Deine Daten liegen in %[1]s, wo ältere Versionen sie ablegten. Verschiebe descriptions.json nach %[4]s, session.lock und tutorials-watched.json nach %[3]s, den Rest nach %[2]s, und entferne %[1]s, um die Standardorte zu nutzen.\n",
+	"doctor.names_ok":          "Alle Übungs- und Stufennamen sind so geschrieben wie in cali",
+	"doctor.name_fix_confirm":  "Namen von %d Eintrag/Einträgen neu schreiben? (j/N): ",
+	"doctor.names_fixed":       "✓ Namen von %d Eintrag/Einträgen neu geschrieben\n",
+	"doctor.future_hint":       "Uhr des Geräts prüfen, das sie eingetragen hat, dann die Zeilen korrigieren oder löschen (cali -r).",
+	"doctor.columns_moved":     "Spalten nach Überschrift gelesen: %s\n",
+	"doctor.column_warnings":   "⚠ %d Problem(e) beim Zuordnen der Tabellenspalten nach Überschrift:\n",
+	"doctor.column_hint":       "Gib jeder Log-Spalte in Zeile 1 genau eine Überschrift (Date, Day, Exercise, Level, RepsxSets, Goal, Comment, Type, Category).",
+	"history.date_warning":     "⚠ %d Eintrag/Einträge mit unlesbarem Datum erscheinen nur hier (siehe cali doctor)\n",
+	"history.future_warning":   "⚠ %d Eintrag/Einträge mit Datum in der Zukunft werden von Statistik und Tagesrotation ignoriert (siehe cali doctor)\n",
+
+	// Sheet formatting
+	"sheet.formatted":        "✓ %s formatiert\n",
+	"sheet.no_tabs":          "noch keine Jahres-Tabellenblätter zum Formatieren; zuerst ein Training eintragen",
+	"sheet.dashboard":        "✓ Tabellenblatt %s aktualisiert\n",
+	"sheet.rollup":           "✓ Zusammenfassung %s ins Tabellenblatt %s geschrieben\n",
+	"rollup.entries":         "Arbeitssätze",
+	"dashboard.title":        "cali-Übersicht",
+	"dashboard.updated":      "Aktualisiert",
+	"dashboard.months":       "Einheiten pro Monat",
+	"dashboard.month":        "Monat",
+	"dashboard.sessions":     "Einheiten",
+	"dashboard.exercises":    "Übungen",
+	"dashboard.exercise":     "Übung",
+	"dashboard.last_trained": "Zuletzt trainiert",
+	"dashboard.level":        "Aktuelle Stufe",
+	"dashboard.totals":       "Gesamt",
+	"dashboard.goals_met":    "Ziele erreicht",
+	"dashboard.streak":       "Aktuelle Serie (Tage)",
+
+	// Version
+	"version.line":         "cali %s (Commit %s, gebaut %s)\n",
+	"version.check_failed": "Warnung: Update-Prüfung fehlgeschlagen: %v\n",
+	"version.latest":       "Neuestes Release ist %s (diese Version: %s): %s\n",
+	"version.available":    "Update verfügbar: %s → %s\n%s\n",
+	"version.up_to_date":   "cali %s ist aktuell\n",
+
+	"compare.header":             "%s – %s vs. %s – %s\n",
+	"compare.previous":           "Vorher",
+	"compare.current":            "Jetzt",
+	"compare.change":             "Änderung",
+	"compare.sessions":           "Trainingstage",
+	"compare.workouts":           "Übungen",
+	"compare.total_reps":         "Wiederholungen",
+	"compare.hold_minutes":       "Haltezeit (min)",
+	"compare.goals_met":          "Ziele erreicht",
+	"compare.mobility_sessions":  "Mobility-Einheiten",
+	"compare.avg_readiness":      "Ø Bereitschaft (1-5)",
+	"compare.low_readiness_days": "Tage wenig Bereitschaft",
+	"compare.per_exercise":       "Pro Übung:",
+	"compare.users_header":       "%s – %s\n",
+	"compare.you":                "Du",
+
+	"backup.taken":          "Log vor der Änderung in %s gesichert\n",
+	"restore.none":          "noch keine automatischen Sicherungen in %s",
+	"restore.other_backend": "die Sicherung in %s stammt vom anderen Speicher",
+	"restore.confirm":       "Sicherung aus %s wiederherstellen? Lokale Jahresdateien werden ersetzt; in Sheets werden seitdem fehlende Einträge wieder angehängt (j/N): ",
+	"restore.files":         "✓ %d Jahresdatei(en) in %s wiederhergestellt\n",
+	"restore.entries":       "✓ %d Einträge wiederhergestellt\n",
+
+	"diff.more":           "…und %d weitere",
+	"diff.nothing":        "Es würde sich nichts ändern (--dry-run)",
+	"diff.summary":        "Würde %d Eintrag/Einträge hinzufügen, %d entfernen und %d ändern; nichts wurde geschrieben (--dry-run)\n",
+	"diff.field_exercise": "Übung",
+	"diff.field_level":    "Stufe",
+	"diff.field_goal":     "Ziel",
+	"diff.field_comment":  "Kommentar",
+	"diff.field_type":     "Art",
+	"diff.field_category": "Kategorie",
+	"diff.field_duration": "Minuten",
+
+	"share.title":          "Trainingslog",
+	"share.generated":      "Erstellt von cali am %s",
+	"share.range":          "Einträge von %s bis %s",
+	"share.summary":        "Übersicht",
+	"share.matrix":         "Progression",
+	"share.history":        "Verlauf",
+	"share.legend_current": "Hervorgehoben: zuletzt trainierte Stufe",
+	"share.col_date":       "Datum",
+	"share.col_day":        "Tag",
+	"share.col_level":      "Stufe",
+	"share.col_work":       "Wdh×Sätze",
+	"share.col_goal":       "Ziel",
+	"share.col_comment":    "Kommentar",
+	"share.written":        "✓ %[1]s geschrieben (%[2]d Einträge)\n",
+
+	"report.title":        "cali-Rückblick %s – %s",
+	"report.nothing":      "Keine Trainings eingetragen.",
+	"report.days":         "Trainingstage",
+	"report.workouts":     "Übungen",
+	"report.goals_met":    "Ziele erreicht",
+	"report.reps":         "Wiederholungen",
+	"report.hold":         "Haltezeit",
+	"report.mobility":     "Mobility-Einheiten",
+	"report.per_exercise": "Pro Übung",
+	"report.best":         "Beste Sätze",
+	"report.completed":    "Abgeschlossene Stufen",
+	"report.skipped":      "Von %s bis %s nichts eingetragen; kein Bericht gesendet",
+	"report.sent":         "✓ Bericht an %s gesendet\n",
+
+	// Progress
+	"notes.header":          "Notizen zu %s (%d):",
+	"notes.none":            "Keine Notizen zu %s erfasst.",
+	"notes.none_matching":   "Keine Notizen zu %s enthalten %q.",
+	"progress.header":       "Fortschritt zum Progressionsstandard:",
+	"progress.level":        "%s - %s (Ziel %s)\n",
+	"progress.eta":          "  Im aktuellen Tempo %s %s um den %s (%s, %s über %d Einheiten)\n",
+	"progress.eta_steady":   "gleichmäßiger Trend",
+	"progress.eta_rough":    "ungleichmäßiger Trend, grobe Schätzung",
+	"progress.eta_reached":  "  Standard erreicht; %s\n",
+	"progress.next":         "Zeit für %s",
+	"progress.next_none":    "das ist die letzte Stufe",
+	"progress.eta_too_few":  "  Noch kein Trend: %d Einheit(en) auf dieser Stufe, nötig sind %d\n",
+	"progress.eta_no_trend": "  Kein Trend: keine Verbesserung in den letzten %d Einheiten\n",
+	"progress.eta_too_far":  "  Im aktuellen Tempo mehr als %d Monate entfernt (%s)\n",
+	"progress.rate_reps":    "+%s Wdh./Woche",
+	"progress.rate_hold":    "+%s s/Woche",
+	"serve.listening":       "Das Log ist unter http://%s erreichbar (GET und POST /entries); Strg-C beendet",
+	"next.exercise":         "Übung",
+	"next.level":            "Stufe",
+	"next.goal":             "Ziel",
+	"next.best":             "Bestes zuletzt",
+	"next.gap":              "Fehlt",
+	"next.next":             "Nächste",
+	"next.gap_reps":         "%d Wdh.",
+	"next.gap_hold":         "%d s",
+	"next.final":            "letzte Stufe",
+	"next.tutorial":         "%s - %s: %s",
+
+	"graph.title_reps":  "%s - %s: Wiederholungen pro Einheit",
+	"graph.title_hold":  "%s - %s: gehaltene Sekunden pro Einheit",
+	"graph.legend":      "* beste Einheit eines Tages (%d gezeigt)",
+	"graph.legend_goal": "* beste Einheit eines Tages (%d gezeigt)   - Ziel %s (%d)",
+	"graph.skipped":     "%d Eintrag/Einträge nicht gezeigt: Wdh.×Sätze nicht lesbar oder anders gemessen",
+	"graph.empty":       "Keine Einheiten von %s - %s zum Zeichnen",
+
+	"targets.header":     "Trainingsfrequenz (letzte %d Tage):\n",
+	"targets.day":        "Tag %s",
+	"targets.average":    "Ø %.1f Tage bei %d Einheit(en)",
+	"targets.no_average": "zu wenige Einheiten für einen Schnitt",
+	"targets.compliant":  "im Plan",
+	"targets.behind":     "im Rückstand (zuletzt vor %d Tag(en))",
+	"targets.never":      "im Rückstand (zuletzt nicht trainiert)",
+	"targets.none":       "Keine Frequenzziele gesetzt. CALI_TARGETS in der Konfiguration setzen, z. B. CALI_TARGETS=Pushups: every 6 days; B: 2x/week",
+
+	"template.header":        "Vorlage %s: %d Übung(en) für %s. Enter übernimmt den Plan, - überspringt eine Übung.",
+	"template.item":          "%d/%d %s - %s [%s]: ",
+	"template.item_optional": "%d/%d %s - %s [%s, optional: Enter überspringt]: ",
+	"template.no_current":    "Noch keine aktuelle Stufe für %s; bitte wählen.",
+	"template.skipped":       "%s übersprungen",
+	"template.nothing":       "Alle Übungen übersprungen; nichts zu speichern.",
+	"template.confirm":       "Diese %d Eintrag/Einträge speichern? (J/n): ",
+	"template.list":          "Vorlagen:",
+	"template.list_row":      "  %-10s %d Übung(en)\n",
+	"template.unknown":       "Keine Vorlage %q. In der Konfiguration als %s anlegen.",
+
+	"comment.too_long": "Warnung: Der Kommentar hat %d Zeichen, mehr als das Limit von %d (CALI_COMMENT_LIMIT)\n",
+	"comment.truncate": "Auf %d Zeichen kürzen? (J/n): ",
+
+	"unsaved.kept":      "Speichern fehlgeschlagen; der Eintrag liegt in %s. Der nächste cali-Aufruf bietet an, ihn zu speichern, oder cali retry-unsaved ausführen.\n",
+	"unsaved.offer":     "%d Eintrag/Einträge einer früheren Sitzung wurden nicht gespeichert (liegen in %s). Jetzt speichern? (J/n): ",
+	"unsaved.later":     "Bleiben in %s; mit cali retry-unsaved speichern.\n",
+	"unsaved.failed":    "Warnung: Speichern der aufbewahrten Einträge fehlgeschlagen: %v\n",
+	"unsaved.remaining": "%d Eintrag/Einträge liegen weiter in %s.\n",
+	"unsaved.none":      "Keine ungespeicherten Einträge.",
+}
@@ -0,0 +1,548 @@
+package cli
+
+// English messages, keyed by id. Every id used with msg must be here; see
+// cali-i18n.go for lookup and fallback.
+var messagesEN = map[string]string{
+	// Dates are shown in this format unless CALI_DATE_FORMAT is set.
+	"date.format": "YYYY-MM-DD",
+	"answer.yes":  "y,yes",
+
+	// Logging dialogue
+	"log.day_plan":                 "Day plan:",
+	"log.day_plan_day":             "  Day %s\n",
+	"log.previous_day":             "Previous training day: %s (%s)\n\n",
+	"log.day_prompt":               "Day (A/B/C): ",
+	"log.day_mismatch":             "%s is normally a Day %s exercise.",
+	"log.day_mismatch_prompt":      "[Enter] log it anyway, [s] switch to Day %s, [r] choose another exercise: ",
+	"log.choose_exercise":          "\nChoose Exercise:",
+	"log.choose_level":             "\nChoose Level for %s:\n",
+	"log.enter_number":             "Enter number: ",
+	"log.enter_number_default":     "Enter number [%d]: ",
+	"log.invalid_exercise":         "Invalid choice, defaulting to %s\n",
+	"log.invalid_level":            "Invalid choice, defaulting to first level",
+	"log.open_tutorial":            "Open tutorial for %s - %s?%s (y/N): ",
+	"log.watched_on":               " (watched %s)",
+	"log.tutorial_opened":          "Tutorial opened. Exiting without logging.",
+	"log.next_playlist":            "No tutorial for %[1]s - %[2]s yet. Open the %[1]s playlist? (y/N): ",
+	"log.next_no_video":            "No video for %s - %s yet; see cali describe for its cues.\n",
+	"log.reps_sets_prompt":         "Reps×Sets: ",
+	"log.reps_sets_prompt_default": "Reps×Sets [%s]: ",
+	"log.hold_prompt_default":      "Hold time [%s]: ",
+	"log.overload_reps":            "Last time: %s on %s — try %s or add a pause rep\n",
+	"log.overload_hold":            "Last time: %s on %s — try %s\n",
+	"log.overload_next":            "Last time: %s on %s met the progression standard — move on to %s\n",
+	"log.hold_prompt":              "Hold time: ",
+	"log.comment_prompt":           "Comment (optional): ",
+	"log.issue_prompt":             "Pain/issue (optional): ",
+	"log.extra_prompt":             "%s (optional): ",
+	"log.extra_choice":             "%s (%s, optional): ",
+	"log.extra_invalid":            "Please answer one of: %s",
+	"log.extra_one_word":           "Please answer in one word.",
+	"log.flag_note":                "Note from %s: %s %s during %s %s\n",
+	"log.deload_target":            "Deload target: %s (%d%% of %s on %s)\n",
+	"log.readiness_prompt":         "Readiness today, 1-%d (sleep, soreness; Enter to skip): ",
+	"log.readiness_invalid":        "%q isn't a readiness score (use 1 to %d)",
+	"log.readiness_low":            "Readiness %d/%d — consider deload targets today: %s ~%s instead of %s (%d%%)\n",
+	"log.readiness_low_rest":       "Readiness %d/%d — consider an easy session or a rest day today\n",
+	"log.logged":                   "\n✓ Logged successfully",
+	"log.saved":                    "Saved: %s | %s | %s - %s | %s\n",
+	"log.duration":                 "Session time: %d min\n",
+	"log.saved_row":                "Row %d of %q: %s\n",
+	"log.saved_line":               "Line %d of %s\n",
+	"log.standard_met":             "%s standard met\n",
+	"log.load_prompt":              "Added load (e.g. +10kg, Enter for none): ",
+	"log.load_invalid":             "Can't read the load %q (e.g. +10kg, 22.5lb or 0 for none)",
+	"log.load_not_counted":         "Standards are judged on reps alone; the load isn't counted towards them\n",
+	"log.achievement":              "⭑ %s - %s: progression standard reached for the first time\n",
+	"achievements.none":            "No level completed yet.",
+	"sync.sheet":                   "the sheet",
+	"sync.local":                   "the local log (%s)",
+	"sync.copied":                  "Copied %d entr(ies) from %s to %s:\n",
+	"sync.would_copy":              "Would copy %d entr(ies) from %s to %s (--dry-run):\n",
+	"sync.conflicts":               "%d entr(ies) of %s differ from %s only in the comment; left as they are:\n",
+	"sync.conflict":                "  %s %s - %s %s: %s has %s, %s has %s\n",
+	"timer.left":                   "Rest: %s",
+	"timer.done":                   "Rest over, next set!",
+	"timer.invalid":                "Can't read the rest time %q (e.g. 90s, 2min or 1:30)",
+	"timer.notify_title":           "cali",
+	"timer.notify_message":         "%s rest over: time for the next set",
+	"log.choose_protocol":          "\nChoose Protocol:",
+	"log.invalid_protocol":         "Invalid choice, defaulting to EMOM",
+	"log.duration_default":         "Duration (default %s): ",
+	"log.duration_prompt":          "Duration (e.g. 10min): ",
+	"log.invalid_duration":         "Invalid duration. Use e.g. 10min or 90s (at least %s)\n",
+	"log.reps_per_round":           "Reps per round: ",
+	"log.rounds_completed":         "Rounds completed: ",
+	"log.whole_number":             "Enter a whole number above 0",
+	"log.hold_ambiguous":           "%q is ambiguous, add a unit (e.g. %ss or %smin)",
+	"log.hold_invalid":             "invalid hold time %q (examples: 90s, 1:30, 2min, 2min x2)",
+	"log.input_closed":             "input closed before the workout was complete",
+
+	// Session lock
+	"session.active":      "Another cali logging session appears active (pid %d, started %s ago). Continue anyway? (y/N): ",
+	"session.age_seconds": "%d second(s)",
+	"session.age_minutes": "%d minute(s)",
+	"session.age_hours":   "%d hour(s)",
+
+	// Standards
+	"tier.beginner":     "Beginner",
+	"tier.intermediate": "Intermediate",
+	"tier.progression":  "Progression",
+	"tier.summary":      "beginner %s · intermediate %s · progression %s",
+
+	// Listings
+	"list.total":             "Total: %d workout(s)\n",
+	"list.row":               "%s | Day %s | %s - %s | %s | %s\n",
+	"list.numbered_row":      "[%d] Day %s | %s - %s | %s | %s\n",
+	"list.lead":              "%s | Day %s",
+	"list.numbered_lead":     "[%d] Day %s",
+	"history.empty":          "No workouts logged yet",
+	"history.empty_filtered": "No working sets logged yet; --all-types also lists warm-ups, mobility and rest days",
+	"list.rest_lead":         "%s | Rest",
+	"history.header":         "Last 10 workouts:",
+	"search.empty":           "No workouts found for %s\n",
+	"search.header":          "Workouts for %s:\n",
+	"search.actions":         "Remove one with: cali -r --date %s --index <number>\n",
+	"flagged.empty":          "No workouts flagged %q\n",
+	"flagged.header":         "Workouts flagged %q:\n",
+	"grep.empty":             "No workouts with a comment matching %q\n",
+	"grep.header":            "Workouts with a comment matching %q:\n",
+	"import.empty":           "No rows to import in %s\n",
+	"import.dry_run":         "Would import %d workout(s) from %s (--dry-run):\n",
+	"import.done":            "Imported %d workout(s) from %s\n",
+	"remove.date_prompt":     "Enter date to search (YYYY-MM-DD, yesterday, mon, 3d ago, jan-20): ",
+	"remove.header":          "\nWorkouts for %s:\n",
+	"remove.index_prompt":    "\nEnter number to remove (0 to cancel): ",
+	"remove.cancelled":       "Cancelled",
+	"remove.done":            "✓ Entry removed successfully",
+	"remove.removed":         "Removed: %s | Day %s | %s - %s | %s (goal %s)\n",
+	"remove.removed_comment": "Comment: %s\n",
+	"remove.undo_prompt":     "Undo? (y/N, %ds): ",
+	"remove.undo_expired":    "No answer; the entry stays removed",
+	"remove.undone":          "✓ Entry restored where it was",
+	"remove.undone_appended": "✓ Entry restored at the end of the log; its old place couldn't be taken back",
+	"remove.confirm":         "Remove this entry? (y/N): ",
+	"remove.no_index":        "there is no entry %d on %s (%d listed by cali -s)",
+	"remove.changed":         "the entries for %s changed while you were choosing; nothing was removed, run cali -r again",
+
+	// Today
+	"today.nothing":         "Nothing logged yet today (%s)\n",
+	"today.suggested_after": "Suggested: Day %s (last was Day %s on %s)\n",
+	"today.suggested":       "Suggested: Day %s\n",
+	"today.header_day":      "Today (%s) - Day %s:\n",
+	"today.header":          "Today (%s):\n",
+	"today.done":            "Done: %s ✓",
+	"today.remaining":       "Remaining: %s",
+	"today.all_done":        "All planned exercises done",
+	"today.also_logged":     "Also logged: %s",
+
+	// Stats
+	"stats.header":            "Training stats:",
+	"stats.total":             "Total workouts:      %d\n",
+	"stats.last_7_days":       "Last 7 days:         %d\n",
+	"stats.days_since":        "Days since last:     %d\n",
+	"stats.goals_met":         "Goals met:           %d\n",
+	"stats.total_reps":        "Total reps:          %d\n",
+	"stats.hold_time":         "Total hold time:     %.1f min\n",
+	"stats.intervals":         "Interval sessions:   %d\n",
+	"stats.deloads":           "Deload sessions:     %d\n",
+	"stats.readiness":         "Avg readiness:       %.1f/%d (%d day(s))\n",
+	"stats.warmups":           "Warm-up sets:        %d (not counted above)\n",
+	"stats.rest_days":         "Planned rest days:   %d\n",
+	"stats.rest_days_reasons": "Planned rest days:   %d (%s)\n",
+	"stats.streak":            "Current streak:      %d day(s) (%d trained, %d rest)\n",
+	"stats.per_exercise":      "\nPer exercise:",
+	"stats.records":           "\nPersonal records:",
+	"stats.plateaus":          "\nPlateaus (no improvement in the last %d sessions):\n",
+	"stats.readiness_weeks":   "\nReadiness per week (average):",
+	"stats.readiness_week":    "  Week of %s  %.1f (%d day(s))\n",
+	"stats.mobility":          "\nMobility:",
+	"stats.sessions":          "Sessions",
+	"stats.hold_label":        "Hold time",
+	"stats.times_header":      "When you train (sessions by start, %d-hour slots, %s):",
+	"stats.times_weekdays":    "Weekdays",
+	"stats.times_weekends":    "Weekends",
+	"stats.times_unknown":     "Unknown time: %d session(s), logged before times were recorded or on a later day",
+
+	// Tutorials, levels and descriptions
+	"tutorial.no_playlist":           "No playlist mapped for %s, opening channel playlists...\n",
+	"tutorial.opening_list":          "Opening playlist for %s...\n",
+	"tutorial.opening":               "Opening tutorial for %s - %s...\n",
+	"tutorial.not_mapped":            "no tutorial mapped for %s - %s",
+	"tutorials.all_watched":          "All tutorials watched",
+	"tutorials.none":                 "No tutorials mapped",
+	"matrix.step":                    "Step",
+	"matrix.legend":                  "* level you trained last",
+	"matrix.legend_markdown":         "**Bold**: level you trained last",
+	"matrix.legend_goal":             "15x2* goal you set yourself (cali goal list)",
+	"describe.header":                "%s - Step %d: %s (goal: %s)\n",
+	"describe.none":                  "  No description available",
+	"error.future_date":              "%[1]d entr(ies) dated more than a day after today (%[3]s), the first %[2]s: check the date and this machine's clock, or pass --allow-future to store them anyway",
+	"error.unknown_exercise":         "unknown exercise %q",
+	"error.unknown_exercise_suggest": "unknown exercise %q (did you mean %q?)",
+	"error.unknown_level":            "unknown level %q for %s",
+	"error.invalid_date":             "invalid date %q (use YYYY-MM-DD or e.g. yesterday, last tue, 3d ago, jan-20)",
+	"error.invalid_choice":           "invalid choice %q",
+	"error.prefix":                   "Error: %v\n",
+	"error.interrupted":              "Interrupted\n",
+	"error.unrecognized_tab":         "Check CALI_SHEET_ID and --sheet point at your workout log. To write to this tab anyway, pass --force-unrecognized.\n",
+	"error.auth_refused":             "Google refused the credentials. If the service account key was rotated, download a new JSON key and point CALI_GOOGLE_CREDENTIALS_JSON at it (or run cali auth).\n",
+	"error.key_refused":              "Google refused the service account key in %s (%s, key ID %s; file last changed %s, key %d days old). If your organization rotates keys, this one was probably disabled or deleted: download a new JSON key for the service account and point CALI_GOOGLE_CREDENTIALS_JSON at it (or run cali auth).\n",
+	"error.key_expired":              "The key in %s expired on %s.\n",
+
+	// Keyring
+	"auth.sheet_id_prompt":      "Spreadsheet ID: ",
+	"auth.sheet_name_prompt":    "Sheet tab (default %s): ",
+	"auth.credentials_prompt":   "Service account JSON path: ",
+	"auth.sheet_id_required":    "a spreadsheet ID is required",
+	"auth.credentials_required": "a credentials path is required",
+	"auth.stored":               "✓ Sheets settings saved in the OS keyring",
+	"auth.cleared":              "✓ Sheets settings removed from the OS keyring",
+	"auth.unset":                "(not set)",
+	"auth.keyring_unavailable":  "Warning: the OS keyring is unavailable (%v); only environment variables are used\n",
+
+	// Setup wizard
+	"wizard.welcome":          "Welcome to cali! Nothing is set up yet, so let's choose where workouts are kept.\n(Run cali --no-wizard to skip this.)",
+	"wizard.backend_prompt":   "  1. Google Sheets\n  2. Local files\nStorage (default 2): ",
+	"wizard.backend_invalid":  "%q is not one of the choices, enter 1 or 2.",
+	"wizard.sheets_intro":     "You need the spreadsheet's ID (from its URL) and a service account JSON file with edit access to it.",
+	"wizard.checking":         "Checking access to the spreadsheet…",
+	"wizard.check_failed":     "✗ %v",
+	"wizard.retry":            "Try again? (Y/n): ",
+	"wizard.sheets_ok":        "✓ Connected to the spreadsheet",
+	"wizard.local_dir_prompt": "Log directory (default %s): ",
+	"wizard.saved":            "✓ Settings saved to %s",
+	"wizard.log_now":          "Log your first workout now? (Y/n): ",
+
+	// Status
+	"status.last":      "Last trained: %s (%d day(s) ago)\n",
+	"status.next":      "Next: Day %s\n",
+	"status.this_week": "This week: %d session(s)\n",
+
+	// Reminders
+	"rest.saved":           "☾ %s marked as a rest day\n",
+	"rest.already":         "Today is already marked as a rest day",
+	"rest.trained":         "You already logged a workout today; it counts over a rest day, so nothing was recorded",
+	"rest.none":            "No rest days recorded",
+	"rest.already_on":      "%s is already marked as a rest day\n",
+	"rest.trained_on":      "A workout is already logged on %s; it counts over a rest day, so nothing was recorded\n",
+	"plan.header":          "Projected week (worked out from your log and the rotation; a projection, not a commitment):\n",
+	"plan.session":         "  %s  Day %s\n",
+	"plan.logged":          "  %s  Day %s (logged)\n",
+	"plan.rest":            "  %s  rest\n",
+	"plan.marked_rest":     "  %s  rest %s\n",
+	"plan.basis":           "%d session(s) a week (CALI_SESSIONS_PER_WEEK) on %s, the days you trained on most in the last %d weeks.\n",
+	"plan.basis_spread":    "%d session(s) a week (CALI_SESSIONS_PER_WEEK) spread over %s; no sessions in the last %d weeks to learn your days from.\n",
+	"plan.shifts":          "Logging another day, or on another date, shifts what follows; cali rest --date marks a rest day ahead.\n",
+	"rest.superseded":      "(trained, counts as a workout)",
+	"quick.empty":          "nothing to log; write e.g. \"B pullups full 8x2 felt strong\"",
+	"quick.bad_exercise":   "%q (word %d) is not an exercise; try e.g. pushups, pullups, leg raises or hspu",
+	"quick.no_level":       "missing the %s level, e.g. full or its step number",
+	"quick.bad_level":      "%q (word %d) is not a %s level (see cali levels)",
+	"quick.no_reps":        "missing reps after %s %s, e.g. 8x2",
+	"quick.bad_reps":       "%q is not reps (e.g. 8x2, 8,7,6 or 12)",
+	"quick.word":           "word %d",
+	"quick.confirm":        "Save? (Y/n): ",
+	"goal.saved":           "✓ %s %s goal set to %s (built-in %s)\n",
+	"goal.removed":         "✓ %s %s goal back to the built-in %s\n",
+	"goal.unchanged":       "%s %s goal is already %s",
+	"goal.not_set":         "%s %s has no goal of yours; it uses the built-in %s",
+	"goal.invalid":         "can't read goal %q (use e.g. 15x2, 8,7,6, 90s or 2min x2)",
+	"goal.kind_mismatch":   "goal %q doesn't match %s %s, which is measured like %s (reps or hold time)",
+	"goal.legend":          "* your goal (cali goal list)",
+	"goal.source_builtin":  "built-in",
+	"goal.source_override": "yours (built-in %s)",
+
+	"levels.pinned":          "✓ %s pinned at %s; logging and progress use it until cali levels unset\n",
+	"levels.unpinned":        "✓ %s is no longer pinned; its current level follows the latest session again\n",
+	"levels.pin_unchanged":   "%s is already pinned at %s",
+	"levels.not_pinned":      "%s is not pinned; its current level follows the latest session",
+	"levels.current_pinned":  "← current (pinned)",
+	"levels.current_derived": "← current (last logged)",
+	"self.installed":         "✓ Installed %s\n",
+	"self.already":           "%s is the running binary; nothing to install",
+	"self.uninstalled":       "✓ Removed %s\n",
+	"self.not_installed":     "%s is not installed",
+	"self.not_cali":          "%s exists and isn't cali; rerun with --force to replace or remove it",
+	"self.no_permission":     "can't write to %s; pick another folder with --dir or rerun with elevated rights (e.g. sudo)",
+	"self.not_on_path":       "%s is not on your PATH yet. Add it (in %s), then open a new terminal:\n",
+	"remind.nudge":           "No workout logged yet today. Next up: Day %s",
+	"remind.installed":       "✓ Reminder scheduled (%v)\n",
+	"remind.uninstalled":     "✓ Reminder removed",
+	"remind.not_installed":   "No reminder installed",
+	"remind.modified":        "%s was edited by hand; rerun with --force to overwrite it",
+	"remind.windows_hint":    "Windows has no automatic install; run this in a terminal:",
+	"remind.file_missing":    "not installed",
+	"remind.file_generated":  "installed",
+	"remind.file_edited":     "installed (edited by hand)",
+
+	// Doctor
+	"doctor.ok":                "No problems found",
+	"doctor.key_old":           "The service account key %s is %d days old (over %d, see CALI_KEY_MAX_AGE). If your organization rotates keys, replace it before it stops working.\n",
+	"doctor.key_expires":       "The service account key %s expires on %s; replace it before then.\n",
+	"doctor.key_expired":       "The service account key %s expired on %s; download a new one.\n",
+	"doctor.future_header":     "%d entr(ies) dated more than a day in the future; stats, status and the day rotation ignore them:\n",
+	"doctor.schemas":           "Row schemas: %s\n",
+	"doctor.schema_unmarked":   "%d unmarked (read as v1)",
+	"doctor.schema_count":      "%d v%d",
+	"doctor.newer_schema":      "⚠ %d row(s) use schema up to v%d, written by %s; this cali reads v%d and ignores the fields it doesn't know\n",
+	"doctor.newer_hint":        "Upgrade cali to read them in full (cali --check-update).",
+	"doctor.writer_unknown":    "an unknown version",
+	"doctor.reformatted_dates": "%d row(s) have dates in another format; cali reads them as YYYY-MM-DD:\n",
+	"doctor.unreadable_dates":  "%d row(s) have a date cali can't read; no search, range or stat includes them:\n",
+	"doctor.date_hint":         "Type the dates as YYYY-MM-DD (format the Date column as plain text so the sheet keeps them).",
+	"doctor.coerced":           "%d row(s) have work or a load the spreadsheet turned into a date, time or number; no stat counts them:\n",
+	"doctor.coerced_hint":      "Retype them as cali writes them, e.g. 10-30x2 or 1:30. cali formats those columns as plain text the next time it writes to the tab, or with cali sheet format, so the sheet keeps what is typed.",
+	"doctor.goal_mismatches":   "%d entr(ies) store a goal that isn't the level's goal now (stored → current):\n",
+	"doctor.goal_unknown":      "%d entr(ies) are of a level cali doesn't know; their goal is left alone:\n",
+	"doctor.goals_ok":          "Every stored goal matches the current goals",
+	"doctor.goal_fix_hint":     "Run cali doctor --fix-goals to store the current goals with them.",
+	"doctor.goal_fix_confirm":  "Rewrite the goal of %d entr(ies)? (y/N): ",
+	"doctor.goals_fixed":       "✓ Rewrote the goal of %d entr(ies)\n",
+	"doctor.name_mismatches":   "%d entr(ies) spell their exercise or level differently from cali (stored → canonical):\n",
+	"doctor.name_unknown":      "%d entr(ies) name an exercise or level cali doesn't know; they are left alone:\n",
+	"paths.legacy":             "Your data is in %[1]s, where older versions kept it. Move its files to %[2]s and remove %[1]s to use the standard location.\n",
+	"paths.legacy_split":       "Your data is in %[1]s, where older versions kept it. Move descriptions.json to %[4]s, session.lock and tutorials-watched.json to %[3]s, the rest to %[2]s, and remove %[1]s to use the standard locations.\n",
+	"doctor.names_ok":          "Every exercise and level name is spelled as cali spells it",
+	"doctor.name_fix_confirm":  "Rewrite the names of %d entr(ies)? (y/N): ",
+	"doctor.names_fixed":       "✓ Rewrote the names of %d entr(ies)\n",
+	"doctor.future_hint":       "Check the clock of the machine that logged them, then fix or remove the rows (cali -r).",
+	"doctor.columns_moved":     "Columns read by their heading: %s\n",
+	"doctor.column_warnings":   "⚠ %d problem(s) mapping sheet columns by their heading:\n",
+	"doctor.column_hint":       "Give every log column a single heading (Date, Day, Exercise, Level, RepsxSets, Goal, Comment, Type, Category) in row 1.",
+	"history.date_warning":     "⚠ %d entr(ies) have a date cali can't read and only show here (see cali doctor)\n",
+	"history.future_warning":   "⚠ %d entr(ies) dated in the future are ignored by stats and the day rotation (see cali doctor)\n",
+
+	// Sheet formatting
+	"sheet.formatted":        "✓ Formatted %s\n",
+	"sheet.no_tabs":          "no year tabs to format yet; log a workout first",
+	"sheet.dashboard":        "✓ Refreshed the %s tab\n",
+	"sheet.rollup":           "✓ Wrote the %s summary to the %s tab\n",
+	"rollup.entries":         "Working sets",
+	"dashboard.title":        "cali dashboard",
+	"dashboard.updated":      "Updated",
+	"dashboard.months":       "Sessions per month",
+	"dashboard.month":        "Month",
+	"dashboard.sessions":     "Sessions",
+	"dashboard.exercises":    "Exercises",
+	"dashboard.exercise":     "Exercise",
+	"dashboard.last_trained": "Last trained",
+	"dashboard.level":        "Current level",
+	"dashboard.totals":       "Totals",
+	"dashboard.goals_met":    "Goals met",
+	"dashboard.streak":       "Current streak (days)",
+
+	// Version
+	"version.line":         "cali %s (commit %s, built %s)\n",
+	"version.check_failed": "Warning: could not check for updates: %v\n",
+	"version.latest":       "Latest release is %s (this build is %s): %s\n",
+	"version.available":    "Update available: %s → %s\n%s\n",
+	"version.up_to_date":   "cali %s is up to date\n",
+
+	"compare.header":             "%s – %s vs. %s – %s\n",
+	"compare.previous":           "Before",
+	"compare.current":            "Now",
+	"compare.change":             "Change",
+	"compare.sessions":           "Training days",
+	"compare.workouts":           "Workouts",
+	"compare.total_reps":         "Total reps",
+	"compare.hold_minutes":       "Hold time (min)",
+	"compare.goals_met":          "Goals met",
+	"compare.mobility_sessions":  "Mobility sessions",
+	"compare.avg_readiness":      "Avg readiness (1-5)",
+	"compare.low_readiness_days": "Low-readiness days",
+	"compare.per_exercise":       "Per exercise:",
+	"compare.users_header":       "%s – %s\n",
+	"compare.you":                "You",
+
+	"backup.taken":          "Backed up your log to %s before changing it\n",
+	"restore.none":          "no automatic backups in %s yet",
+	"restore.other_backend": "the snapshot in %s was taken from the other storage backend",
+	"restore.confirm":       "Restore the snapshot in %s? Local year files are replaced; on Sheets, entries missing since are added back (y/N): ",
+	"restore.files":         "✓ Restored %d year file(s) in %s\n",
+	"restore.entries":       "✓ Restored %d entries\n",
+
+	"diff.more":           "…and %d more",
+	"diff.nothing":        "Nothing would change (--dry-run)",
+	"diff.summary":        "Would add %d, remove %d and change %d entr(ies); nothing was written (--dry-run)\n",
+	"diff.field_exercise": "exercise",
+	"diff.field_level":    "level",
+	"diff.field_goal":     "goal",
+	"diff.field_comment":  "comment",
+	"diff.field_type":     "type",
+	"diff.field_category": "category",
+	"diff.field_duration": "minutes",
+
+	"share.title":          "Training log",
+	"share.generated":      "Generated by cali on %s",
+	"share.range":          "entries from %s to %s",
+	"share.summary":        "Summary",
+	"share.matrix":         "Progression",
+	"share.history":        "History",
+	"share.legend_current": "Highlighted: level trained last",
+	"share.col_date":       "Date",
+	"share.col_day":        "Day",
+	"share.col_level":      "Level",
+	"share.col_work":       "Reps×Sets",
+	"share.col_goal":       "Goal",
+	"share.col_comment":    "Comment",
+	"share.written":        "✓ Wrote %[1]s (%[2]d entries)\n",
+
+	"report.title":        "cali recap %s – %s",
+	"report.nothing":      "No workouts logged.",
+	"report.days":         "Training days",
+	"report.workouts":     "Workouts",
+	"report.goals_met":    "Goals met",
+	"report.reps":         "Total reps",
+	"report.hold":         "Hold time",
+	"report.mobility":     "Mobility sessions",
+	"report.per_exercise": "Per exercise",
+	"report.best":         "Best sets",
+	"report.completed":    "Levels completed",
+	"report.skipped":      "Nothing logged from %s to %s; no report sent",
+	"report.sent":         "✓ Report sent to %s\n",
+
+	// Progress
+	"notes.header":          "Notes on %s (%d):",
+	"notes.none":            "No notes recorded for %s.",
+	"notes.none_matching":   "No notes on %s contain %q.",
+	"progress.header":       "Progress toward the progression standard:",
+	"progress.level":        "%s - %s (goal %s)\n",
+	"progress.eta":          "  At the current rate, %s %s around %s (%s, %s over %d sessions)\n",
+	"progress.eta_steady":   "steady trend",
+	"progress.eta_rough":    "uneven trend, rough estimate",
+	"progress.eta_reached":  "  Standard reached; %s\n",
+	"progress.next":         "time to move on to %s",
+	"progress.next_none":    "that's the last level",
+	"progress.eta_too_few":  "  No trend yet: %d session(s) at this level, need %d\n",
+	"progress.eta_no_trend": "  No trend: not improving over the last %d sessions\n",
+	"progress.eta_too_far":  "  More than %d months away at the current rate (%s)\n",
+	"progress.rate_reps":    "+%s reps/week",
+	"progress.rate_hold":    "+%s s/week",
+	"serve.listening":       "Serving the log on http://%s (GET and POST /entries); Ctrl-C stops",
+	"next.exercise":         "Exercise",
+	"next.level":            "Level",
+	"next.goal":             "Goal",
+	"next.best":             "Best recent",
+	"next.gap":              "Gap",
+	"next.next":             "Next",
+	"next.gap_reps":         "%d reps",
+	"next.gap_hold":         "%d s",
+	"next.final":            "last level",
+	"next.tutorial":         "%s - %s: %s",
+
+	"graph.title_reps":  "%s - %s: total reps per session",
+	"graph.title_hold":  "%s - %s: seconds held per session",
+	"graph.legend":      "* best session of a day (%d plotted)",
+	"graph.legend_goal": "* best session of a day (%d plotted)   - goal %s (%d)",
+	"graph.skipped":     "%d entr(ies) not plotted: their Reps×Sets can't be read or measure something else",
+	"graph.empty":       "No sessions of %s - %s to plot",
+
+	"targets.header":     "Frequency targets (last %d days):\n",
+	"targets.day":        "Day %s",
+	"targets.average":    "avg %.1f days over %d session(s)",
+	"targets.no_average": "too few sessions for an average",
+	"targets.compliant":  "compliant",
+	"targets.behind":     "behind (last %d day(s) ago)",
+	"targets.never":      "behind (not trained lately)",
+	"targets.none":       "No frequency targets set. Add CALI_TARGETS to the config, e.g. CALI_TARGETS=Pushups: every 6 days; B: 2x/week",
+
+	"template.header":        "Template %s: %d item(s) for %s. Enter takes the planned value, - skips an item.",
+	"template.item":          "%d/%d %s - %s [%s]: ",
+	"template.item_optional": "%d/%d %s - %s [%s, optional: Enter skips]: ",
+	"template.no_current":    "No current level of %s yet; choose one.",
+	"template.skipped":       "Skipped %s",
+	"template.nothing":       "Every item was skipped; nothing to save.",
+	"template.confirm":       "Save these %d entr(ies)? (Y/n): ",
+	"template.list":          "Templates:",
+	"template.list_row":      "  %-10s %d item(s)\n",
+	"template.unknown":       "No template %q. Define it as %s in the config.",
+
+	"comment.too_long": "Warning: the comment is %d characters, over the limit of %d (CALI_COMMENT_LIMIT)\n",
+	"comment.truncate": "Cut it to %d characters? (Y/n): ",
+
+	"unsaved.kept":      "Saving failed; the entry is kept in %s. The next cali run offers to save it, or run cali retry-unsaved.\n",
+	"unsaved.offer":     "%d entr(ies) from an earlier session could not be saved (kept in %s). Save them now? (Y/n): ",
+	"unsaved.later":     "Kept in %s; run cali retry-unsaved to save them.\n",
+	"unsaved.failed":    "Warning: saving the kept entries failed: %v\n",
+	"unsaved.remaining": "%d entr(ies) are still kept in %s.\n",
+	"unsaved.none":      "No unsaved entries.",
+
+	"help": `Calisthenics Workout Logger
+
+Usage:
+  cali [command] [flags]  Without a command, cali logs a new workout
+
+Commands:
+%s
+Date filters (history, search, grep, stats, metrics, export, report, rest --list, share, graph, progress --notes):
+  --since <date|7d|3w|2m>  Only entries on or after this date
+  --until <date|7d|3w|2m>  Only entries on or before this date
+  Relative forms count back from today (CALI_TZ sets the timezone).
+
+Output levels (any command):
+  -q, --quiet             Only results and errors; prompts go to stderr, no spinner
+  --verbose               Also show storage backend, sheet rows and API timing (stderr),
+                          and level descriptions in the logging menu
+  --fail-empty            Exit with code 4 when history, search, today, stats or report find nothing
+
+Shared logs (any command):
+  CALI_USER=<name>        Tag logged entries with your name and only show yours (and untagged ones)
+  --user <name>           Show another user's entries instead; you still log as CALI_USER
+  --all-users             Show everyone's entries
+
+Other tabs (log, history, search, export):
+  --sheet <tab>           Use another tab for one command (locally, a subdirectory)
+
+Plugins:
+  cali <name> [args]      Runs cali-<name> from PATH when <name> is no command, with
+                          the resolved storage settings in its environment
+  --no-plugins            Don't run plugins; an unknown command is an error
+
+Run cali help <command> (or cali <command> --help) for its flags and examples,
+and cali help settings for exit codes and environment variables.
+`,
+	"help.settings": `Exit codes:
+  0 success · 1 internal error · 2 usage error (bad flags, arguments or input)
+  3 storage/configuration error (missing env, auth, I/O) · 4 nothing found (--fail-empty)
+  130 cancelled (remove answered 0, input closed, or Ctrl-C)
+
+Language and dates:
+  CALI_LANG=de                   (optional; otherwise LC_ALL/LC_MESSAGES/LANG, default: en)
+  CALI_DATE_FORMAT=DD.MM.YYYY    (optional; display only, dates are typed and stored as YYYY-MM-DD)
+
+Comments:
+  CALI_COMMENT_LIMIT=1000        Characters before cali offers to cut a comment (0: no limit)
+  CALI_PRIVATE_MARKER=[private]  Comments holding it (or #private) show as [redacted] in export,
+                                 report and share export-static, unless --include-private
+
+Interactive tutorials:
+  During logging, after selecting exercise and level, cali can open a tutorial link.
+  If opened, cali exits immediately without saving the log entry.
+
+Storage backends:
+  Default: Google Sheets
+  Local files override: set CALI_STORAGE=local
+  Local path: the workout directory of the data directory, e.g. ~/.local/share/cali-logger/workout
+
+Google Sheets env vars:
+  CALI_SHEET_ID=<spreadsheet-id> (required)
+  CALI_SHEET_NAME=<tab-name>     (optional, default: Log)
+  CALI_SHEET_PER_YEAR=1          (optional; one tab per year, e.g. "Log 2026")
+  CALI_SHEET_GOAL_PERCENT=1      (optional; write "% of goal" in column J on append)
+  CALI_SHEET_PAGE_SIZE=1000      (optional; rows read per request from large tabs)
+  CALI_GOOGLE_CREDENTIALS_JSON=<service-account-json-path>
+  or GOOGLE_APPLICATION_CREDENTIALS can be used instead
+  Unset values are read from the OS keyring (see cali auth store)
+
+Deload env vars:
+  CALI_DELOAD_PERCENT=<1-100>    (optional, default: 60; scales suggested targets)
+  CALI_READINESS=1               (optional; ask for a 1-5 readiness score before the day's first session)
+
+Export env vars:
+  CALI_SESSION_LENGTH=<duration> (optional, default: 45m; used for Google Fit sessions)
+
+Planning env vars:
+  CALI_SESSIONS_PER_WEEK=<1-7>   (optional, default: 3; sessions cali plan spreads over a week)
+`,
+}
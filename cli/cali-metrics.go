@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// printMetrics writes training stats in the Prometheus text exposition format,
+// suitable for the node_exporter textfile collector.
+func printMetrics(ctx context.Context, storage Storage, rng dateRange, w io.Writer) error {
+	entries, err := storage.Range(ctx, rng.Since, rng.Until)
+	if err != nil {
+		return err
+	}
+	now := currentTime()
+	return writeMetrics(w, computeStats(calio.WithoutFuture(entries, now), now))
+}
+
+func writeMetrics(w io.Writer, stats trainingStats) error {
+	var b strings.Builder
+
+	b.WriteString("# HELP cali_workouts_total Logged workout entries.\n")
+	b.WriteString("# TYPE cali_workouts_total counter\n")
+	fmt.Fprintf(&b, "cali_workouts_total %d\n", stats.Total)
+	for _, exercise := range statsExercises(stats) {
+		fmt.Fprintf(&b, "cali_workouts_total{exercise=%q} %d\n", exercise, stats.PerExercise[exercise])
+	}
+
+	b.WriteString("# HELP cali_workouts_last_7d Workout entries logged in the last 7 days.\n")
+	b.WriteString("# TYPE cali_workouts_last_7d gauge\n")
+	fmt.Fprintf(&b, "cali_workouts_last_7d %d\n", stats.Last7Days)
+
+	b.WriteString("# HELP cali_days_since_last_workout Days since the most recent workout (-1 if none).\n")
+	b.WriteString("# TYPE cali_days_since_last_workout gauge\n")
+	fmt.Fprintf(&b, "cali_days_since_last_workout %d\n", stats.DaysSinceLast)
+
+	b.WriteString("# HELP cali_goal_met_total Workout entries that met their goal.\n")
+	b.WriteString("# TYPE cali_goal_met_total counter\n")
+	fmt.Fprintf(&b, "cali_goal_met_total %d\n", stats.GoalsMet)
+
+	b.WriteString("# HELP cali_reps_total Reps logged across all entries (intervals count rounds x reps).\n")
+	b.WriteString("# TYPE cali_reps_total counter\n")
+	fmt.Fprintf(&b, "cali_reps_total %d\n", stats.TotalReps)
+
+	b.WriteString("# HELP cali_hold_seconds_total Time spent in timed holds.\n")
+	b.WriteString("# TYPE cali_hold_seconds_total counter\n")
+	fmt.Fprintf(&b, "cali_hold_seconds_total %d\n", stats.HoldTime)
+
+	b.WriteString("# HELP cali_mobility_workouts_total Logged mobility entries (not counted in cali_workouts_total).\n")
+	b.WriteString("# TYPE cali_mobility_workouts_total counter\n")
+	fmt.Fprintf(&b, "cali_mobility_workouts_total %d\n", stats.Mobility)
+
+	b.WriteString("# HELP cali_mobility_hold_seconds_total Time spent in mobility holds.\n")
+	b.WriteString("# TYPE cali_mobility_hold_seconds_total counter\n")
+	fmt.Fprintf(&b, "cali_mobility_hold_seconds_total %d\n", stats.MobilityHoldTime)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
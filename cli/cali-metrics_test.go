@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// metricsHistory is a small log covering every figure writeMetrics prints:
+// several exercises, a custom one, a met goal, a hold, a warm-up, a
+// mobility hold and an entry more than a week old.
+var metricsHistory = []WorkoutEntry{
+	{Date: "2026-09-30", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2"},
+	{Date: "2026-10-12", Exercise: "Squats", Level: "Full", RepsSets: "25x2", Goal: "30x2"},
+	{Date: "2026-10-14", Exercise: "Pushups", Level: "Full", RepsSets: "10x1", Goal: "20x2", Comment: "#warmup"},
+	{Date: "2026-10-14", Exercise: "Pullups", Level: "Half", RepsSets: "8x3", Goal: "15x2"},
+	{Date: "2026-10-15", Exercise: "Muscle-ups", Level: "Bar", RepsSets: "3x2"},
+	{Date: "2026-10-15", Exercise: "Handstand", Level: "Wall", RepsSets: "30s x 3"},
+	{Date: "2026-10-16", Exercise: "L-sit", Level: "Floor", RepsSets: "90s", Category: "mobility"},
+}
+
+func TestWriteMetricsGolden(t *testing.T) {
+	today := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	var out strings.Builder
+	if err := writeMetrics(&out, computeStats(metricsHistory, today)); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "metrics.prom", out.String())
+}
+
+// checkGolden compares got with the golden file name in testdata, which
+// go test -update rewrites instead.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s:\n%s", golden, got)
+	}
+}
@@ -0,0 +1,25 @@
+package cli
+
+import "github.com/ziad73/cali-logger/calio"
+
+// exercisesForCategory returns the exercise menu for a session category,
+// ordered and filtered by exerciseMenu.
+func exercisesForCategory(category string) []string {
+	if category == calio.CategoryMobility {
+		return exerciseMenu.exercises(calio.MobilityExercises())
+	}
+	return exerciseMenu.exercises(calio.Exercises())
+}
+
+// splitByCategory separates strength entries from mobility entries, keeping
+// their order.
+func splitByCategory(entries []WorkoutEntry) (strength, mobility []WorkoutEntry) {
+	for _, entry := range entries {
+		if calio.IsMobility(entry) {
+			mobility = append(mobility, entry)
+		} else {
+			strength = append(strength, entry)
+		}
+	}
+	return strength, mobility
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestSplitByCategory(t *testing.T) {
+	entries := []WorkoutEntry{
+		{Exercise: "Pushups", Category: calio.CategoryStrength},
+		{Exercise: "L-Sit", Category: calio.CategoryMobility},
+		{Exercise: "Squats"}, // lines from before categories are strength
+		{Exercise: "Twist", Category: " Mobility "},
+	}
+	strength, mobility := splitByCategory(entries)
+	if len(strength) != 2 || strength[0].Exercise != "Pushups" || strength[1].Exercise != "Squats" {
+		t.Errorf("strength = %+v", strength)
+	}
+	if len(mobility) != 2 || mobility[0].Exercise != "L-Sit" || mobility[1].Exercise != "Twist" {
+		t.Errorf("mobility = %+v", mobility)
+	}
+}
+
+// TestStatsKeepMobilitySeparate checks mobility holds count on their own
+// and leave the strength figures as they were.
+func TestStatsKeepMobilitySeparate(t *testing.T) {
+	today := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	strength := []WorkoutEntry{
+		{Date: "2026-10-14", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2"},
+		{Date: "2026-10-15", Day: "B", Exercise: "Pullups", Level: "Half", RepsSets: "8x3", Goal: "15x2"},
+	}
+	holds := []WorkoutEntry{
+		{Date: "2026-10-16", Exercise: "L-Sit", Level: "Tuck", RepsSets: "30s x 2", Category: calio.CategoryMobility},
+		{Date: "2026-10-17", Day: "C", Exercise: "Bridge Hold", Level: "Short", RepsSets: "1min", Category: calio.CategoryMobility},
+	}
+	before := computeStats(strength, today)
+	after := computeStats(append(append([]WorkoutEntry{}, strength...), holds...), today)
+
+	if after.Total != before.Total || after.GoalsMet != before.GoalsMet || after.TotalReps != before.TotalReps ||
+		after.Last7Days != before.Last7Days || after.DaysSinceLast != before.DaysSinceLast || len(after.PerExercise) != len(before.PerExercise) {
+		t.Errorf("strength stats with holds = %+v, want those without: %+v", after, before)
+	}
+	if after.Mobility != 2 || after.MobilityPerExercise["L-Sit"] != 1 || after.MobilityPerExercise["Bridge Hold"] != 1 {
+		t.Errorf("mobility = %d, %v, want one of each hold", after.Mobility, after.MobilityPerExercise)
+	}
+	if after.MobilityHoldTime != 120 || after.HoldTime != before.HoldTime {
+		t.Errorf("hold times = %v mobility, %v strength, want 2 minutes and %v", after.MobilityHoldTime, after.HoldTime, before.HoldTime)
+	}
+}
+
+// TestMobilityDoesNotAdvanceRotation logs a strength session, then a
+// mobility hold carrying a day letter, and checks the next strength entry
+// logged without --day continues the rotation from the strength session.
+func TestMobilityDoesNotAdvanceRotation(t *testing.T) {
+	storage := pipedLog(t)
+	yesterday := currentTime().AddDate(0, 0, -1).Format(calio.DateLayout)
+	if currentTime().YearDay() == 1 {
+		t.Skip("the local backend finds the last training day in this year's file")
+	}
+	for _, entry := range []WorkoutEntry{
+		{Date: yesterday, Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Category: calio.CategoryStrength},
+		{Date: yesterday, Day: "C", Exercise: "L-Sit", Level: "Tuck", RepsSets: "30s", Category: calio.CategoryMobility},
+	} {
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, stderr, code := runCLI(t, "", "log", "--exercise", "twist", "--level", "straight leg", "--reps", "30s")
+	if code != 0 {
+		t.Fatalf("logging a hold exited %d: %s", code, stderr)
+	}
+	_, stderr, code = runCLI(t, "", "log", "--exercise", "pullups", "--level", "full", "--reps", "8x2")
+	if code != 0 {
+		t.Fatalf("logging pullups exited %d: %s", code, stderr)
+	}
+	entries := logged(t, storage)
+	if len(entries) != 4 {
+		t.Fatalf("saved %d entries, want 4", len(entries))
+	}
+	if hold := entries[2]; hold.Category != calio.CategoryMobility || hold.Day != "" {
+		t.Errorf("hold = %+v, want a mobility entry without a day", hold)
+	}
+	if pullups := entries[3]; pullups.Day != "B" {
+		t.Errorf("pullups logged as day %q, want B after day A's strength session", pullups.Day)
+	}
+}
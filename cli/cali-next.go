@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// nextSessions is how many of the latest training days at a level count as
+// recent for cali next.
+const nextSessions = 5
+
+// nextStep is one row of cali next: where an exercise stands against the
+// standard of its current level.
+type nextStep struct {
+	Exercise string
+	Level    string
+	Goal     string // "-" when the level has none
+	Best     string // best recent Reps×Sets at Level; empty when none
+	Gap      int    // reps, or seconds for timed goals, still missing
+	Want     int    // the goal's total in the same unit, so Gap can be weighed
+	Timed    bool
+	Met      bool
+	Next     string // the level after Level once Met; empty otherwise
+	Final    bool   // Level is the last step of the ladder
+}
+
+// measured reports whether the step has a result to weigh against a goal.
+func (s nextStep) measured() bool {
+	return s.Best != "" && s.Want > 0
+}
+
+// goalGap returns what logged is short of goal, compared set by set as
+// meetsGoal does: the reps (or seconds held, for timed goals) missing from
+// the best sets to reach each set of the goal, with missing sets counting
+// whole. It is zero exactly when logged meets goal. want is the goal's
+// total; ok is false when either value doesn't parse, is an interval or
+// they don't measure the same thing.
+func goalGap(logged, goal string) (gap, want int, ok bool) {
+	done, ok := parseRepsSets(logged)
+	if !ok || done.interval() {
+		return 0, 0, false
+	}
+	target, ok := parseRepsSets(goal)
+	if !ok || target.interval() || target.timed() != done.timed() {
+		return 0, 0, false
+	}
+	have, need := done.Sets, target.Sets
+	if target.timed() {
+		have, need = holdSeconds(done.Holds), holdSeconds(target.Holds)
+	}
+	best := append([]int(nil), have...)
+	sort.Sort(sort.Reverse(sort.IntSlice(best)))
+	for i, reps := range need {
+		want += reps
+		if i < len(best) {
+			gap += max(0, reps-best[i])
+		} else {
+			gap += reps
+		}
+	}
+	return gap, want, want > 0
+}
+
+// planNext returns a nextStep per current level, closest to progressing
+// first: standards met, then by the share of the goal still missing, then
+// levels with nothing to weigh (untrained since pinned, or without a goal)
+// in the order of current. The best recent result is the best ranked
+// working set of the last nextSessions training days at the level; deloads,
+// warm-ups and intervals don't count. Apart from the goal overrides in
+// effect, the result depends only on the arguments.
+func planNext(strength []WorkoutEntry, current []exerciseLevel) []nextStep {
+	atLevel := map[exerciseLevel][]WorkoutEntry{}
+	for _, entry := range workingEntries(strength) {
+		if classifyEntry(entry) != kindWorking || isInterval(entry) {
+			continue
+		}
+		entry = calio.Canonical(entry)
+		key := exerciseLevel{entry.Exercise, entry.Level}
+		atLevel[key] = append(atLevel[key], entry)
+	}
+
+	steps := make([]nextStep, 0, len(current))
+	for _, key := range current {
+		step := nextStep{Exercise: key.Exercise, Level: key.Level, Goal: resolveGoal(key.Exercise, key.Level)}
+		levels := calio.Levels(key.Exercise)
+		index := slices.Index(levels, key.Level)
+		step.Final = index >= 0 && index == len(levels)-1
+
+		var best WorkoutEntry
+		var bestRank workRank
+		for _, entry := range recentSessions(atLevel[key], nextSessions) {
+			if rank, ok := entryRank(entry); ok && (best.RepsSets == "" || rank.beats(bestRank)) {
+				best, bestRank = entry, rank
+			}
+		}
+		step.Best = best.RepsSets
+		if step.Best != "" && step.Goal != "-" {
+			if gap, want, ok := goalGap(step.Best, step.Goal); ok {
+				parsed, _ := parseRepsSets(step.Goal)
+				step.Gap, step.Want, step.Timed = gap, want, parsed.timed()
+				step.Met = gap == 0
+			}
+		}
+		if step.Met && !step.Final && index >= 0 {
+			step.Next = levels[index+1]
+		}
+		steps = append(steps, step)
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		a, b := steps[i], steps[j]
+		if a.measured() != b.measured() {
+			return a.measured()
+		}
+		if !a.measured() {
+			return false
+		}
+		if a.Met != b.Met {
+			return a.Met
+		}
+		// Gap/Want, compared without dividing.
+		return a.Gap*b.Want < b.Gap*a.Want
+	})
+	return steps
+}
+
+// recentSessions returns the entries of the latest n dates of entries.
+func recentSessions(entries []WorkoutEntry, n int) []WorkoutEntry {
+	var dates []string
+	for _, entry := range entries {
+		if !slices.Contains(dates, entry.Date) {
+			dates = append(dates, entry.Date)
+		}
+	}
+	slices.Sort(dates)
+	if len(dates) > n {
+		dates = dates[len(dates)-n:]
+	}
+	var recent []WorkoutEntry
+	for _, entry := range entries {
+		if slices.Contains(dates, entry.Date) {
+			recent = append(recent, entry)
+		}
+	}
+	return recent
+}
+
+// gapText renders the gap column: what is missing, "✓" once met, or "-"
+// when there is nothing to weigh.
+func (s nextStep) gapText() string {
+	switch {
+	case !s.measured():
+		return "-"
+	case s.Met:
+		return "✓"
+	case s.Timed:
+		return msg("next.gap_hold", s.Gap)
+	}
+	return msg("next.gap_reps", s.Gap)
+}
+
+// nextText renders the next column: the next level, linked to its
+// tutorial, once the standard is met.
+func (s nextStep) nextText(links linker) string {
+	switch {
+	case s.Met && s.Final:
+		return msg("next.final")
+	case s.Next != "":
+		return links.tutorialLink(s.Exercise, s.Next)
+	}
+	return ""
+}
+
+// writeNextTable renders steps as a table. Without hyperlinks, the
+// tutorials of the next levels are listed under it instead.
+func writeNextTable(w io.Writer, steps []nextStep, links linker) error {
+	rows := [][]string{{msg("next.exercise"), msg("next.level"), msg("next.goal"), msg("next.best"), msg("next.gap"), msg("next.next")}}
+	var tutorials []string
+	for _, step := range steps {
+		best := step.Best
+		if best == "" {
+			best = "-"
+		}
+		rows = append(rows, []string{step.Exercise, step.Level, step.Goal, best, step.gapText(), step.nextText(links)})
+		if url := resolveTutorial(step.Exercise, step.Next); step.Next != "" && url != "" && !links.enabled {
+			tutorials = append(tutorials, msg("next.tutorial", step.Exercise, step.Next, url))
+		}
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for col, cell := range row {
+			widths[col] = max(widths[col], cellWidth(cell))
+		}
+	}
+	var b strings.Builder
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for col, cell := range row {
+			cells[col] = padCell(cell, widths[col])
+		}
+		b.WriteString(strings.TrimRight(strings.Join(cells, "  "), " ") + "\n")
+	}
+	if len(tutorials) > 0 {
+		b.WriteString("\n" + strings.Join(tutorials, "\n") + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func runNext(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		return usageError("usage: cali next")
+	}
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	entries, err := storage.All(ctx)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	loadGoalOverrides(ctx, storage)
+	loadLevelPins(ctx, storage)
+	strength, _ := splitByCategory(calio.WithoutFuture(entries, currentTime()))
+
+	steps := planNext(strength, currentLevels(strength))
+	if len(steps) == 0 {
+		fmt.Println(msg("history.empty"))
+		return errNoResults
+	}
+	return writeNextTable(os.Stdout, steps, newLinker(os.Stdout))
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoalGap(t *testing.T) {
+	tests := []struct {
+		logged, goal string
+		gap, want    int
+		ok           bool
+	}{
+		{"20x2", "20x2", 0, 40, true},
+		{"25x2", "20x2", 0, 40, true},
+		{"18x2", "20x2", 4, 40, true},
+		// Compared set by set, best sets first: extra reps in one set don't
+		// make up for another.
+		{"12,25", "20x2", 8, 40, true},
+		{"20", "20x2", 20, 40, true},
+		{"10x3", "20x2", 20, 40, true},
+		{"90s", "2min", 30, 120, true},
+		{"2min", "2min", 0, 120, true},
+		{"20x2", "2min", 0, 0, false},
+		{"emom10min@12", "20x2", 0, 0, false},
+		{"lots", "20x2", 0, 0, false},
+		{"20x2", "-", 0, 0, false},
+	}
+	for _, tt := range tests {
+		gap, want, ok := goalGap(tt.logged, tt.goal)
+		if gap != tt.gap || want != tt.want || ok != tt.ok {
+			t.Errorf("goalGap(%q, %q) = %d, %d, %v, want %d, %d, %v", tt.logged, tt.goal, gap, want, ok, tt.gap, tt.want, tt.ok)
+		}
+		if ok && (gap == 0) != meetsGoal(tt.logged, tt.goal) {
+			t.Errorf("goalGap(%q, %q) disagrees with meetsGoal", tt.logged, tt.goal)
+		}
+	}
+}
+
+// nextHistory is a log at several stages: a standard met, one met at the
+// last step, some short of it by more or less, and a pinned level not
+// trained yet. Only the last five training days at a level count, and
+// deloads and warm-ups never do.
+func nextHistory() ([]WorkoutEntry, []exerciseLevel) {
+	entry := func(date, exercise, level, reps, comment string) WorkoutEntry {
+		return WorkoutEntry{Date: date, Day: "A", Exercise: exercise, Level: level, RepsSets: reps, Goal: resolveGoal(exercise, level), Comment: comment}
+	}
+	history := []WorkoutEntry{
+		entry("2026-03-01", "Pushups", "Full", "18x2", ""),
+		entry("2026-03-03", "pushups", "full", "20x2", ""),
+		entry("2026-03-01", "Leg Raises", "Hanging", "30x2", ""),
+		entry("2026-03-02", "Squats", "Half", "40x2", ""),
+		entry("2026-03-04", "Squats", "Half", "45x2", ""),
+		entry("2026-03-02", "Handstand Push-ups", "Wall Headstand", "90s", ""),
+		// Six training days ago at the level: too old to count.
+		entry("2026-02-20", "Pullups", "Half", "15x2", ""),
+	}
+	for _, date := range []string{"2026-02-22", "2026-02-24", "2026-02-26", "2026-02-28", "2026-03-02"} {
+		history = append(history, entry(date, "Pullups", "Half", "5x2", ""))
+	}
+	history = append(history,
+		entry("2026-03-04", "Pullups", "Half", "15x2", "#deload"),
+		entry("2026-03-04", "Pullups", "Half", "15x2", "#warmup"),
+	)
+	current := []exerciseLevel{
+		{"Bridges", "Short"},
+		{"Pullups", "Half"},
+		{"Pushups", "Full"},
+		{"Squats", "Half"},
+		{"Handstand Push-ups", "Wall Headstand"},
+		{"Leg Raises", "Hanging"},
+	}
+	return history, current
+}
+
+// TestPlanNext checks each exercise's best recent result and gap, and that
+// met standards come first, then the smallest share of the goal missing,
+// then what can't be weighed.
+func TestPlanNext(t *testing.T) {
+	withGoalOverrides(t, nil)
+	withLevelPins(t, nil)
+	history, current := nextHistory()
+	var got []string
+	for _, s := range planNext(history, current) {
+		got = append(got, strings.Join([]string{s.Exercise, s.Level, s.Goal, s.Best, s.gapText(), s.Next}, " | "))
+	}
+	want := []string{
+		"Pushups | Full | 20x2 | 20x2 | ✓ | Close",
+		"Leg Raises | Hanging | 30x2 | 30x2 | ✓ | ",
+		"Squats | Half | 50x2 | 45x2 | 10 reps | ",
+		"Handstand Push-ups | Wall Headstand | 2min | 90s | 30 s | ",
+		"Pullups | Half | 15x2 | 5x2 | 20 reps | ",
+		"Bridges | Short | 50x3 |  | - | ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("planNext:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	steps := planNext(history, current)
+	if !steps[1].Final || steps[1].nextText(linker{}) != msg("next.final") || steps[0].Final {
+		t.Errorf("final steps: %+v, %+v", steps[0], steps[1])
+	}
+	if steps := planNext(nil, nil); len(steps) != 0 {
+		t.Errorf("planNext of nothing = %+v", steps)
+	}
+}
+
+// TestNextTableGolden renders the table, with the next level's tutorial
+// under it, or linked in place with hyperlinks.
+func TestNextTableGolden(t *testing.T) {
+	withGoalOverrides(t, nil)
+	withLevelPins(t, nil)
+	steps := planNext(nextHistory())
+	var plain strings.Builder
+	if err := writeNextTable(&plain, steps, linker{}); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "next.txt", plain.String())
+
+	var linked strings.Builder
+	if err := writeNextTable(&linked, steps, linker{enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	table, _, _ := strings.Cut(plain.String(), "\n\n")
+	if got := stripEscapes(linked.String()); got != table+"\n" {
+		t.Errorf("linked table:\n%s\nwant:\n%s", got, table)
+	}
+	if !strings.Contains(linked.String(), hyperlink("Close", resolveTutorial("Pushups", "Close"))) {
+		t.Errorf("Close is not linked:\n%q", linked.String())
+	}
+}
+
+func TestNextCommand(t *testing.T) {
+	pipedLog(t)
+	withGoalOverrides(t, nil)
+	if stdout, _, code := runCLI(t, "", "next"); code != 0 || !strings.Contains(stdout, msg("history.empty")) {
+		t.Errorf("cali next on an empty log exited %d: %q", code, stdout)
+	}
+	if _, _, code := runCLI(t, "", "next", "pushups"); code != exitUsage {
+		t.Errorf("cali next pushups exited %d", code)
+	}
+	if _, stderr, code := runCLI(t, "y\ny\n", "q", "pushups full 20x2"); code != 0 {
+		t.Fatalf("cali q exited %d: %s", code, stderr)
+	}
+	stdout, stderr, code := runCLI(t, "", "next")
+	lines := strings.Split(stdout, "\n")
+	if code != 0 || len(lines) < 2 || !strings.HasPrefix(lines[1], "Pushups ") || !strings.Contains(lines[1], "✓") || !strings.Contains(stdout, "Pushups - Close: https://") {
+		t.Errorf("cali next exited %d:\n%s%s", code, stdout, stderr)
+	}
+}
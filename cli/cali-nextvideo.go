@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// nextVideo is the video offered once a session meets the progression
+// standard of its level: the tutorial of the next level, or the exercise's
+// playlist when that level has none mapped. URL is empty when there is
+// neither.
+type nextVideo struct {
+	Exercise string
+	Level    string // the next level
+	URL      string
+	Playlist bool // URL is the exercise's playlist, not the level's tutorial
+}
+
+// nextLevelVideo returns the video to watch after entry: ok is true when
+// entry meets the progression standard of its level (as shown after
+// logging; deloads and intervals never do) and the exercise has a level
+// after it. The level's tutorial is preferred, then the exercise's
+// playlist.
+func nextLevelVideo(entry WorkoutEntry) (nextVideo, bool) {
+	entry = calio.Canonical(entry)
+	if isDeload(entry) || isInterval(entry) || tierMet(entry.RepsSets, resolveTiers(entry.Exercise, entry.Level)) != tierProgression {
+		return nextVideo{}, false
+	}
+	levels := calio.Levels(entry.Exercise)
+	index := slices.Index(levels, entry.Level)
+	if index < 0 || index == len(levels)-1 {
+		return nextVideo{}, false
+	}
+	video := nextVideo{Exercise: entry.Exercise, Level: levels[index+1]}
+	if link := resolveTutorial(video.Exercise, video.Level); link != "" {
+		video.URL = link
+	} else if link := resolvePlaylist(video.Exercise); link != "" {
+		video.URL, video.Playlist = link, true
+	}
+	return video, true
+}
+
+// offerNextVideo asks, after entry is logged, whether to open the video of
+// the level it unlocks, as promptOpenTutorial does before logging. An
+// opened tutorial is recorded as watched; a playlist isn't, as it belongs
+// to no level.
+func offerNextVideo(reader *bufio.Reader, entry WorkoutEntry) {
+	video, ok := nextLevelVideo(entry)
+	if !ok {
+		return
+	}
+	switch {
+	case video.URL == "":
+		say(msg("log.next_no_video", video.Exercise, video.Level))
+		return
+	case video.Playlist:
+		prompt(msg("log.next_playlist", video.Exercise, video.Level))
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input == "" || !slices.Contains(strings.Split(msg("answer.yes"), ","), input) {
+			return
+		}
+	default:
+		if !promptOpenTutorial(reader, video.Exercise, video.Level) {
+			return
+		}
+	}
+	if err := openURL(video.URL); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to open tutorial: %v\n", err)
+		return
+	}
+	if !video.Playlist {
+		recordWatched(video.Exercise, video.Level)
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bufio"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// isolatedHome points HOME and the XDG directories at a new temporary
+// directory, so no goal override or watched list of the user is read.
+func isolatedHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, name := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(name, filepath.Join(home, name))
+	}
+	return home
+}
+
+func TestNextLevelVideo(t *testing.T) {
+	isolatedHome(t)
+	const playlist = "https://www.youtube.com/playlist?list=PLhandstand"
+	incline, _ := calio.Tutorial("Pushups", "Incline")
+	met := func(exercise, level string) WorkoutEntry {
+		return WorkoutEntry{Exercise: exercise, Level: level, RepsSets: resolveGoal(exercise, level)}
+	}
+	deload := met("Pushups", "Wall")
+	deload.Comment = "#deload"
+	tests := []struct {
+		name      string
+		playlists map[string]string
+		entry     WorkoutEntry
+		want      nextVideo
+		ok        bool
+	}{
+		{"next level's tutorial", nil, met("Pushups", "Wall"), nextVideo{Exercise: "Pushups", Level: "Incline", URL: incline}, true},
+		{"playlist without a tutorial", map[string]string{"Handstand Push-ups": playlist}, met("Handstand Push-ups", "Wall Headstand"),
+			nextVideo{Exercise: "Handstand Push-ups", Level: "Crow", URL: playlist, Playlist: true}, true},
+		{"neither", map[string]string{"Pushups": playlist}, met("Handstand Push-ups", "Wall Headstand"),
+			nextVideo{Exercise: "Handstand Push-ups", Level: "Crow"}, true},
+		{"standard not met", nil, WorkoutEntry{Exercise: "Pushups", Level: "Wall", RepsSets: "10x1"}, nextVideo{}, false},
+		{"last level", nil, met("Pushups", "One-Arm"), nextVideo{}, false},
+		{"deload", nil, deload, nextVideo{}, false},
+	}
+	for _, tt := range tests {
+		withPlaylists(t, tt.playlists)
+		video, ok := nextLevelVideo(tt.entry)
+		if ok != tt.ok || video != tt.want {
+			t.Errorf("%s: nextLevelVideo = %+v, %v, want %+v, %v", tt.name, video, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestOfferNextVideoPlaylist checks the playlist offered in place of a
+// missing tutorial opens on yes, and isn't recorded as the level watched.
+func TestOfferNextVideoPlaylist(t *testing.T) {
+	isolatedHome(t)
+	quiet(t)
+	const playlist = "https://www.youtube.com/playlist?list=PLhandstand"
+	withPlaylists(t, map[string]string{"Handstand Push-ups": playlist})
+	entry := WorkoutEntry{Exercise: "Handstand Push-ups", Level: "Wall Headstand", RepsSets: resolveGoal("Handstand Push-ups", "Wall Headstand")}
+
+	for answer, want := range map[string][]string{"y\n": {playlist}, "n\n": nil, "\n": nil} {
+		opened := fakeBrowser(t)
+		offerNextVideo(bufio.NewReader(strings.NewReader(answer)), entry)
+		if !slices.Equal(*opened, want) {
+			t.Errorf("answering %q opened %q, want %q", answer, *opened, want)
+		}
+	}
+	store, err := newWatchedStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Watched("Handstand Push-ups", "Crow"); ok {
+		t.Error("opening the playlist recorded Crow as watched")
+	}
+
+	// The tutorial of a level is recorded.
+	opened := fakeBrowser(t)
+	offerNextVideo(bufio.NewReader(strings.NewReader("y\n")), WorkoutEntry{Exercise: "Pushups", Level: "Wall", RepsSets: resolveGoal("Pushups", "Wall")})
+	if store, err = newWatchedStore(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Watched("Pushups", "Incline"); len(*opened) != 1 || !ok {
+		t.Errorf("opened %q, watched: %v; want Incline's tutorial opened and recorded", *opened, ok)
+	}
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// tagHighlight shows #tags in cali progress --notes in bold.
+const tagHighlight = "\033[1m"
+
+// progressOptions are the flags of cali progress.
+type progressOptions struct {
+	Notes bool
+	Grep  string
+}
+
+// newProgressFlagSet declares the flags of cali progress into opts.
+func newProgressFlagSet(opts *progressOptions) *flag.FlagSet {
+	fs := newFlagSet("progress")
+	fs.BoolVar(&opts.Notes, "notes", false, "list the comments written on the exercise instead, oldest first")
+	fs.StringVar(&opts.Grep, "grep", "", "with --notes, only comments containing this text (ignoring case)")
+	return fs
+}
+
+// exerciseNotes returns the entries of exercise with a comment matching
+// grep (see matchesText), oldest first; entries of one date keep the order
+// they were logged in.
+func exerciseNotes(entries []WorkoutEntry, exercise, grep string) []WorkoutEntry {
+	var notes []WorkoutEntry
+	for _, entry := range entries {
+		entry = calio.Canonical(entry)
+		if entry.Exercise != exercise || strings.TrimSpace(entry.Comment) == "" || !matchesText(entry.Comment, grep) {
+			continue
+		}
+		notes = append(notes, entry)
+	}
+	slices.SortStableFunc(notes, func(a, b WorkoutEntry) int { return strings.Compare(a.Date, b.Date) })
+	return notes
+}
+
+// writeNotes lists notes for a terminal width columns wide: the date,
+// level and work of each entry, its comment wrapped below it. With color,
+// #tags such as #deload are highlighted.
+func writeNotes(w io.Writer, notes []WorkoutEntry, width int, color bool) error {
+	var b strings.Builder
+	for i, entry := range notes {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		work := loggedWork(entry)
+		if isInterval(entry) {
+			work = workText(entry)
+		}
+		// The level and work go under the date when they don't fit beside
+		// it, as in the stacked list.
+		heading := fmt.Sprintf("%s | %s | %s", displayDate(entry.Date), entry.Level, work)
+		if !fitsOn(heading, width) {
+			heading = fmt.Sprintf("%s\n%s%s | %s", displayDate(entry.Date), stackedIndent, entry.Level, work)
+		}
+		b.WriteString(heading + "\n")
+		for _, line := range wrapText(entry.Comment, width-len(stackedIndent)) {
+			if color {
+				line = highlightTags(line)
+			}
+			fmt.Fprintf(&b, "%s%s\n", stackedIndent, line)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// highlightTags shows the #tags of line in tagHighlight.
+func highlightTags(line string) string {
+	words := strings.Split(line, " ")
+	for i, word := range words {
+		if len(word) > 1 && strings.HasPrefix(word, "#") {
+			words[i] = tagHighlight + word + matrixReset
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// showNotes prints what was written on exercise in rng (cali progress
+// --notes).
+func showNotes(ctx context.Context, exercise string, rng dateRange, grep string) error {
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	entries, err := storage.Range(ctx, rng.Since, rng.Until)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	notes := exerciseNotes(calio.WithoutFuture(entries, currentTime()), exercise, grep)
+	if len(notes) == 0 {
+		if grep != "" {
+			fmt.Println(msg("notes.none_matching", exercise, grep))
+		} else {
+			fmt.Println(msg("notes.none", exercise))
+		}
+		return errNoResults
+	}
+	sayln(msg("notes.header", exercise, len(notes)))
+	color := os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+	return writeNotes(os.Stdout, notes, terminalWidth(), color)
+}
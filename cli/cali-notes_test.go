@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"", 20, nil},
+		{"  grip gave out  ", 20, []string{"grip gave out"}},
+		{"grip gave out on the last set", 13, []string{"grip gave out", "on the last", "set"}},
+		{"a supercalifragilistic set", 8, []string{"a", "supercal", "ifragili", "stic set"}},
+		{"übung über ärger", 10, []string{"übung über", "ärger"}},
+		{"one\ntwo", 0, []string{"o", "n", "e", "t", "w", "o"}},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.text, tt.width); !slices.Equal(got, tt.want) {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesText(t *testing.T) {
+	tests := []struct {
+		text, filter string
+		want         bool
+	}{
+		{"Grip gave out", "", true},
+		{"Grip gave out", "GRIP", true},
+		{"Grip gave out", "elbow", false},
+		{"tweaked elbow #deload", "#Deload", true},
+	}
+	for _, tt := range tests {
+		if got := matchesText(tt.text, tt.filter); got != tt.want {
+			t.Errorf("matchesText(%q, %q) = %v, want %v", tt.text, tt.filter, got, tt.want)
+		}
+	}
+}
+
+// notesLog is a pull-up history with comments long enough to wrap, one word
+// too long for a narrow terminal, two notes on one date and entries without
+// a comment.
+var notesLog = []WorkoutEntry{
+	{Date: "2026-03-09", Day: "B", Exercise: "pullups", Level: "Half", RepsSets: "8x2", Comment: "tweaked elbow on the way down, stopped the second set early and iced it #injury"},
+	{Date: "2026-03-02", Day: "B", Exercise: "Pullups", Level: "Half", RepsSets: "6x2", Comment: "grip gave out"},
+	{Date: "2026-03-02", Day: "B", Exercise: "Pullups", Level: "Half", RepsSets: "5x1", Comment: "extra set: hand-over-hand-and-then-some-more on the bar"},
+	{Date: "2026-03-04", Day: "A", Exercise: "Pullups", Level: "Half", RepsSets: "7x2"},
+	{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Comment: "easy"},
+	{Date: "2026-03-16", Day: "B", Exercise: "Pullups", Level: "Half", RepsSets: "9x2", Comment: "  "},
+	{Date: "2026-03-23", Day: "B", Exercise: "Pullups", Level: "Full", RepsSets: "3x2", Load: "+5kg", Comment: "first full ones #deload week"},
+}
+
+func TestExerciseNotes(t *testing.T) {
+	var got []string
+	for _, entry := range exerciseNotes(notesLog, "Pullups", "") {
+		got = append(got, entry.Date+" "+entry.RepsSets)
+	}
+	want := []string{"2026-03-02 6x2", "2026-03-02 5x1", "2026-03-09 8x2", "2026-03-23 3x2"}
+	if !slices.Equal(got, want) {
+		t.Errorf("exerciseNotes = %q, want %q", got, want)
+	}
+	if got := exerciseNotes(notesLog, "Pullups", "ELBOW"); len(got) != 1 || got[0].Date != "2026-03-09" {
+		t.Errorf("exerciseNotes with --grep ELBOW = %+v", got)
+	}
+	if got := exerciseNotes(notesLog, "Squats", ""); got != nil {
+		t.Errorf("exerciseNotes of an exercise without entries = %+v", got)
+	}
+}
+
+func TestWriteNotesGolden(t *testing.T) {
+	notes := exerciseNotes(notesLog, "Pullups", "")
+	for _, tt := range []struct {
+		golden string
+		width  int
+		color  bool
+	}{
+		{"notes.80.txt", 80, false},
+		{"notes.20.txt", minTerminalWidth, false},
+		{"notes.color.txt", 80, true},
+	} {
+		var b strings.Builder
+		if err := writeNotes(&b, notes, tt.width, tt.color); err != nil {
+			t.Fatal(err)
+		}
+		checkGolden(t, tt.golden, b.String())
+		for _, line := range strings.Split(stripEscapes(b.String()), "\n") {
+			if cellWidth(line) > tt.width {
+				t.Errorf("%s: %q is wider than %d", tt.golden, line, tt.width)
+			}
+		}
+	}
+}
+
+// TestNotesCommand runs cali progress --notes on a local log: with --since
+// and --grep, after the exercise or before it, and on an exercise without
+// notes.
+func TestNotesCommand(t *testing.T) {
+	storage := pipedLog(t)
+	if _, err := storage.AppendBatch(context.Background(), notesLog); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code := runCLI(t, "", "progress", "PULLUPS", "--notes", "--since", "2026-03-05", "--width", "80")
+	if code != 0 || !strings.HasPrefix(stdout, msg("notes.header", "Pullups", 2)) ||
+		strings.Contains(stdout, "grip gave out") || !strings.Contains(stdout, "first full ones") {
+		t.Errorf("cali progress PULLUPS --notes --since exited %d:\n%s%s", code, stdout, stderr)
+	}
+	stdout, _, code = runCLI(t, "", "progress", "--notes", "--grep", "GRIP", "pullups")
+	if code != 0 || !strings.Contains(stdout, "grip gave out") || strings.Contains(stdout, "elbow") {
+		t.Errorf("cali progress --notes --grep GRIP pullups exited %d:\n%s", code, stdout)
+	}
+
+	stdout, _, code = runCLI(t, "", "progress", "squats", "--notes")
+	if code != 0 || stdout != msg("notes.none", "Squats")+"\n" {
+		t.Errorf("cali progress squats --notes exited %d: %q", code, stdout)
+	}
+	if _, _, code := runCLI(t, "", "--fail-empty", "progress", "pullups", "--notes", "--grep", "shoulder"); code != exitNotFound {
+		t.Errorf("no matching notes with --fail-empty exited %d", code)
+	}
+	if _, _, code := runCLI(t, "", "progress", "--notes"); code != exitUsage {
+		t.Errorf("cali progress --notes without an exercise exited %d", code)
+	}
+	if _, _, code := runCLI(t, "", "progress", "pullups", "--grep", "grip"); code != exitUsage {
+		t.Errorf("--grep without --notes exited %d", code)
+	}
+}
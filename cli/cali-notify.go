@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// notifyTimeout bounds each notification command, so a hung notifier never
+// holds up cali.
+const notifyTimeout = 5 * time.Second
+
+// notifier tells the user something happened while the terminal may be out
+// of sight, such as the end of a rest.
+type notifier interface {
+	Notify(ctx context.Context, title, message string) error
+}
+
+// notifySettings are the CALI_NOTIFY* settings: which kinds of notice to
+// give and the commands overriding the platform ones.
+type notifySettings struct {
+	Desktop bool
+	Sound   bool
+	// Command and SoundCommand are templates split on spaces, with {title}
+	// and {message} replaced in each argument; empty uses the platform's.
+	Command      string
+	SoundCommand string
+}
+
+// notifyKinds are the values CALI_NOTIFY lists.
+var notifyKinds = []string{"desktop", "sound"}
+
+// configuredNotify reads CALI_NOTIFY, a comma-separated list of desktop and
+// sound (empty for neither), and the command overrides
+// CALI_NOTIFY_COMMAND and CALI_NOTIFY_SOUND_COMMAND.
+func configuredNotify() (notifySettings, error) {
+	settings := notifySettings{
+		Command:      strings.TrimSpace(os.Getenv("CALI_NOTIFY_COMMAND")),
+		SoundCommand: strings.TrimSpace(os.Getenv("CALI_NOTIFY_SOUND_COMMAND")),
+	}
+	for _, kind := range strings.Split(os.Getenv("CALI_NOTIFY"), ",") {
+		switch kind = strings.ToLower(strings.TrimSpace(kind)); kind {
+		case "":
+		case "desktop":
+			settings.Desktop = true
+		case "sound":
+			settings.Sound = true
+		default:
+			return notifySettings{}, fmt.Errorf("invalid CALI_NOTIFY %q (use %s, or both separated by a comma)",
+				kind, strings.Join(notifyKinds, " or "))
+		}
+	}
+	return settings, nil
+}
+
+// notifyCommands returns the commands that give the notices of settings on
+// goos, each as a program and its arguments. The result depends only on the
+// arguments.
+func notifyCommands(goos string, settings notifySettings, title, message string) [][]string {
+	var commands [][]string
+	if settings.Desktop {
+		if settings.Command != "" {
+			commands = append(commands, expandCommand(settings.Command, title, message))
+		} else {
+			commands = append(commands, desktopCommand(goos, title, message))
+		}
+	}
+	if settings.Sound {
+		if settings.SoundCommand != "" {
+			commands = append(commands, expandCommand(settings.SoundCommand, title, message))
+		} else {
+			commands = append(commands, soundCommand(goos))
+		}
+	}
+	return slices.DeleteFunc(commands, func(command []string) bool { return len(command) == 0 })
+}
+
+// expandCommand splits a command template on spaces and fills in {title}
+// and {message} per argument, so neither needs quoting.
+func expandCommand(template, title, message string) []string {
+	replacer := strings.NewReplacer("{title}", title, "{message}", message)
+	fields := strings.Fields(template)
+	for i, field := range fields {
+		fields[i] = replacer.Replace(field)
+	}
+	return fields
+}
+
+// desktopCommand is the platform's desktop notification: notify-send on
+// Linux and the BSDs, AppleScript on macOS, a toast through PowerShell on
+// Windows.
+func desktopCommand(goos, title, message string) []string {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return []string{"osascript", "-e", script}
+	case "windows":
+		script := strings.Join([]string{
+			"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null",
+			"$toast = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)",
+			"$text = $toast.GetElementsByTagName('text')",
+			"$text.Item(0).AppendChild($toast.CreateTextNode(" + powerShellString(title) + ")) > $null",
+			"$text.Item(1).AppendChild($toast.CreateTextNode(" + powerShellString(message) + ")) > $null",
+			"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('cali').Show([Windows.UI.Notifications.ToastNotification]::new($toast))",
+		}, "; ")
+		return powerShellCommand(script)
+	}
+	return []string{"notify-send", "--app-name=cali", title, message}
+}
+
+// soundCommand plays a short system sound.
+func soundCommand(goos string) []string {
+	switch goos {
+	case "darwin":
+		return []string{"afplay", "/System/Library/Sounds/Glass.aiff"}
+	case "windows":
+		return powerShellCommand("[System.Media.SystemSounds]::Exclamation.Play(); Start-Sleep -Milliseconds 500")
+	}
+	return []string{"paplay", "/usr/share/sounds/freedesktop/stereo/complete.oga"}
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// powerShellCommand runs script in PowerShell. It is passed encoded, as
+// UTF-16LE in base64, so no quote in it meets the command line.
+func powerShellCommand(script string) []string {
+	units := utf16.Encode([]rune(script))
+	encoded := make([]byte, 2*len(units))
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(encoded[2*i:], unit)
+	}
+	return []string{"powershell", "-NoProfile", "-NonInteractive", "-EncodedCommand", base64.StdEncoding.EncodeToString(encoded)}
+}
+
+// powerShellString quotes s as a single-quoted PowerShell string.
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// commandNotifier runs the notification commands of its settings for the
+// current platform.
+type commandNotifier struct {
+	settings notifySettings
+}
+
+// Notify runs every command, each bounded by notifyTimeout, and returns
+// their errors joined.
+func (n commandNotifier) Notify(ctx context.Context, title, message string) error {
+	var errs []error
+	for _, command := range notifyCommands(runtime.GOOS, n.settings, title, message) {
+		cmdCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		if err := exec.CommandContext(cmdCtx, command[0], command[1:]...).Run(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", command[0], err))
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+func TestConfiguredNotify(t *testing.T) {
+	tests := []struct {
+		env  string
+		want notifySettings
+		ok   bool
+	}{
+		{"", notifySettings{}, true},
+		{"desktop", notifySettings{Desktop: true}, true},
+		{" Sound , desktop ", notifySettings{Desktop: true, Sound: true}, true},
+		{"sound,", notifySettings{Sound: true}, true},
+		{"email", notifySettings{}, false},
+	}
+	for _, tt := range tests {
+		t.Setenv("CALI_NOTIFY", tt.env)
+		got, err := configuredNotify()
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("CALI_NOTIFY=%q: %+v, %v", tt.env, got, err)
+		}
+	}
+	t.Setenv("CALI_NOTIFY", "desktop")
+	t.Setenv("CALI_NOTIFY_COMMAND", " my-notify {title} ")
+	t.Setenv("CALI_NOTIFY_SOUND_COMMAND", "aplay ding.wav")
+	if got, _ := configuredNotify(); got.Command != "my-notify {title}" || got.SoundCommand != "aplay ding.wav" {
+		t.Errorf("overrides read as %+v", got)
+	}
+}
+
+func TestNotifyCommands(t *testing.T) {
+	both := notifySettings{Desktop: true, Sound: true}
+	title, message := "Rest over", `Say "go" \ 1'30`
+	tests := []struct {
+		goos     string
+		settings notifySettings
+		want     [][]string
+	}{
+		{"linux", both, [][]string{
+			{"notify-send", "--app-name=cali", title, message},
+			{"paplay", "/usr/share/sounds/freedesktop/stereo/complete.oga"},
+		}},
+		{"freebsd", notifySettings{Desktop: true}, [][]string{{"notify-send", "--app-name=cali", title, message}}},
+		{"darwin", both, [][]string{
+			{"osascript", "-e", `display notification "Say \"go\" \\ 1'30" with title "Rest over"`},
+			{"afplay", "/System/Library/Sounds/Glass.aiff"},
+		}},
+		{"linux", notifySettings{}, nil},
+		{"linux", notifySettings{Sound: true, SoundCommand: "aplay ding.wav"}, [][]string{{"aplay", "ding.wav"}}},
+		// Templates fill in each argument as a whole, so nothing needs quoting.
+		{"darwin", notifySettings{Desktop: true, Command: "terminal-notifier -title {title} -message {message}"}, [][]string{
+			{"terminal-notifier", "-title", title, "-message", message},
+		}},
+	}
+	for _, tt := range tests {
+		got := notifyCommands(tt.goos, tt.settings, title, message)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s %+v: %q, want %q", tt.goos, tt.settings, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if strings.Join(got[i], "\x00") != strings.Join(tt.want[i], "\x00") {
+				t.Errorf("%s %+v: command %d is %q, want %q", tt.goos, tt.settings, i, got[i], tt.want[i])
+			}
+		}
+	}
+
+	windows := notifyCommands("windows", both, title, message)
+	if len(windows) != 2 {
+		t.Fatalf("windows: %q", windows)
+	}
+	for i, want := range []string{
+		"CreateTextNode('Rest over')",
+		"[System.Media.SystemSounds]::Exclamation.Play()",
+	} {
+		command := windows[i]
+		if len(command) != 5 || command[0] != "powershell" || command[3] != "-EncodedCommand" {
+			t.Errorf("windows command %d is %q", i, command)
+			continue
+		}
+		if script := decodePowerShell(t, command[4]); !strings.Contains(script, want) {
+			t.Errorf("windows command %d runs %q, want it to contain %q", i, script, want)
+		}
+	}
+	if script := decodePowerShell(t, windows[0][4]); !strings.Contains(script, `CreateTextNode('Say "go" \ 1''30')`) {
+		t.Errorf("the toast message is quoted as %q", script)
+	}
+}
+
+// decodePowerShell reverses the -EncodedCommand encoding.
+func decodePowerShell(t *testing.T, encoded string) string {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data)%2 != 0 {
+		t.Fatalf("bad encoded command %q: %v", encoded, err)
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// fakeNotifier records notifications and fails with err.
+type fakeNotifier struct {
+	notices []string
+	err     error
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, title, message string) error {
+	f.notices = append(f.notices, title+": "+message)
+	return f.err
+}
+
+// instant is a restTimer clock that doesn't wait, counting what it was
+// asked to wait for.
+func instant(waited *time.Duration) func(time.Duration) <-chan time.Time {
+	return func(d time.Duration) <-chan time.Time {
+		*waited += d
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+}
+
+func TestRestTimer(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	var waited time.Duration
+	notify := &fakeNotifier{}
+	timer := restTimer{out: &out, notify: notify, after: instant(&waited)}
+	if err := timer.run(ctx, 2500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if waited != 2500*time.Millisecond {
+		t.Errorf("waited %v", waited)
+	}
+	for _, want := range []string{"0:03", "0:02", "0:01", msg("timer.done") + "\a\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("the countdown doesn't show %q:\n%q", want, out.String())
+		}
+	}
+	if len(notify.notices) != 1 || !strings.HasPrefix(notify.notices[0], msg("timer.notify_title")+": ") {
+		t.Errorf("notices %q", notify.notices)
+	}
+
+	// A failed notification warns; the rest is over either way.
+	notify = &fakeNotifier{err: errors.New("no notification daemon")}
+	timer.notify = notify
+	var err error
+	stderr := captureOutput(t, &os.Stderr, func() { err = timer.run(ctx, time.Second) })
+	if err != nil || len(notify.notices) != 1 || !strings.Contains(stderr, "no notification daemon") {
+		t.Errorf("failed notification: %v, %q, %q", err, notify.notices, stderr)
+	}
+
+	// Without a notifier only the bell rings.
+	out.Reset()
+	timer.notify = nil
+	if err := timer.run(ctx, time.Second); err != nil || !strings.HasSuffix(out.String(), "\a\n") {
+		t.Errorf("bell only: %v, %q", err, out.String())
+	}
+
+	// A rest cut short gives no notice.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	notify = &fakeNotifier{}
+	timer = restTimer{out: &out, notify: notify, after: func(time.Duration) <-chan time.Time { return nil }}
+	if err := timer.run(cancelled, time.Minute); !errors.Is(err, context.Canceled) || len(notify.notices) != 0 {
+		t.Errorf("cancelled: %v, %q", err, notify.notices)
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		90 * time.Second:       "1:30",
+		65 * time.Second:       "1:05",
+		500 * time.Millisecond: "0:01",
+		10 * time.Minute:       "10:00",
+		59*time.Second + 1:     "1:00",
+	} {
+		if got := formatClock(d); got != want {
+			t.Errorf("formatClock(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+// TestCommandNotifier runs real commands: one that succeeds, and missing
+// and failing ones, whose errors are reported rather than fatal.
+func TestCommandNotifier(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	out := dir + "/notice"
+	script := dir + "/notify.sh"
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf '%s|%s' \"$1\" \"$2\" > "+out+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ok := commandNotifier{settings: notifySettings{Desktop: true, Command: script + " {title} {message}"}}
+	if err := ok.Notify(ctx, "Rest over", "1:30 of rest"); err != nil {
+		t.Skipf("can't run shell scripts here: %v", err)
+	}
+	if got := readFile(t, out); got != "Rest over|1:30 of rest" {
+		t.Errorf("the command got %q", got)
+	}
+
+	failing := commandNotifier{settings: notifySettings{
+		Desktop: true, Command: dir + "/missing {title}",
+		Sound: true, SoundCommand: script + " {nothing}",
+	}}
+	err := failing.Notify(ctx, "Rest over", "")
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("a missing command: %v", err)
+	}
+	if got := readFile(t, out); got != "{nothing}|" {
+		t.Errorf("the sound command didn't run after the failed one: %q", got)
+	}
+}
+
+// TestTimerCommand checks cali timer's arguments, and that a notification
+// that fails leaves the exit status alone.
+func TestTimerCommand(t *testing.T) {
+	for _, args := range [][]string{{"timer", "soon"}, {"timer", "1s", "2s"}, {"timer", "--loud"}} {
+		if _, _, code := runCLI(t, "", args...); code != exitUsage {
+			t.Errorf("cali %s exited %d", strings.Join(args, " "), code)
+		}
+	}
+	t.Setenv("CALI_NOTIFY", "beep")
+	if _, stderr, code := runCLI(t, "", "timer", "1s"); code != exitUsage || !strings.Contains(stderr, "CALI_NOTIFY") {
+		t.Errorf("an invalid CALI_NOTIFY exited %d: %s", code, stderr)
+	}
+	t.Setenv("CALI_NOTIFY", "desktop")
+	t.Setenv("CALI_NOTIFY_COMMAND", t.TempDir()+"/missing")
+	stdout, stderr, code := runCLI(t, "", "timer", "1s")
+	if code != 0 || !strings.Contains(stdout+stderr, msg("timer.done")) || !strings.Contains(stderr, "notification failed") {
+		t.Errorf("cali timer with a broken notifier exited %d:\n%s%s", code, stdout, stderr)
+	}
+	if _, stderr, code := runCLI(t, "", "timer", "--no-notify", "1s"); code != 0 || strings.Contains(stderr, "notification") {
+		t.Errorf("cali timer --no-notify exited %d: %s", code, stderr)
+	}
+}
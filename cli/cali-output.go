@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// verbosity is the output level chosen with the global --quiet and --verbose
+// flags.
+type verbosity int
+
+const (
+	levelQuiet verbosity = iota
+	levelNormal
+	levelVerbose
+)
+
+var outputLevel = levelNormal
+
+// extractOutputFlags removes the global --quiet/-q and --verbose flags from
+// args. The last one given wins.
+func extractOutputFlags(args []string) ([]string, verbosity) {
+	var rest []string
+	level := levelNormal
+	for _, arg := range args {
+		switch arg {
+		case "--quiet", "-q":
+			level = levelQuiet
+		case "--verbose":
+			level = levelVerbose
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, level
+}
+
+// Output goes through four helpers. Essential results (listings, the saved
+// entry, JSON) are printed with fmt directly and always appear.
+//
+//   - say: banners, headers and separators; dropped by --quiet.
+//   - detail: extra context for --verbose, written to stderr.
+//   - prompt: the interactive dialogue; moved to stderr by --quiet so stdout
+//     only carries results.
+
+func say(format string, a ...any) {
+	if outputLevel >= levelNormal {
+		fmt.Printf(format, a...)
+	}
+}
+
+func sayln(a ...any) {
+	if outputLevel >= levelNormal {
+		fmt.Println(a...)
+	}
+}
+
+func detail(format string, a ...any) {
+	if outputLevel >= levelVerbose {
+		fmt.Fprintf(os.Stderr, format, a...)
+	}
+}
+
+func promptWriter() io.Writer {
+	if outputLevel == levelQuiet {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func prompt(format string, a ...any) {
+	fmt.Fprintf(promptWriter(), format, a...)
+}
+
+func promptln(a ...any) {
+	fmt.Fprintln(promptWriter(), a...)
+}
+
+// wrapText breaks text into lines of at most width characters, at spaces
+// where it can; words longer than a line are split. Runs of whitespace,
+// line breaks included, count as one space. Empty text is no lines; color
+// goes on after wrapping.
+func wrapText(text string, width int) []string {
+	width = max(width, 1)
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for cellWidth(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case cellWidth(line)+1+cellWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// matchesText reports whether text contains filter, ignoring case; an
+// empty filter matches everything. A filter of the form key=value, such as
+// knee=poor, matches that answer to an extra prompt exactly, so it doesn't
+// find knee=poorly.
+func matchesText(text, filter string) bool {
+	if answers := commentExtras(filter); len(answers) == 1 && strings.TrimSpace(filter) == answers[0].String() {
+		return slices.ContainsFunc(commentExtras(text), func(a extraAnswer) bool {
+			return a.Key == answers[0].Key && strings.EqualFold(a.Value, answers[0].Value)
+		})
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(filter))
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExtractOutputFlags(t *testing.T) {
+	tests := []struct {
+		args  []string
+		rest  []string
+		level verbosity
+	}{
+		{[]string{"-s", "today"}, []string{"-s", "today"}, levelNormal},
+		{[]string{"--quiet", "-s", "today"}, []string{"-s", "today"}, levelQuiet},
+		{[]string{"-s", "today", "-q"}, []string{"-s", "today"}, levelQuiet},
+		{[]string{"--verbose", "stats"}, []string{"stats"}, levelVerbose},
+		{[]string{"-q", "stats", "--verbose"}, []string{"stats"}, levelVerbose}, // the last one wins
+	}
+	for _, tt := range tests {
+		rest, level := extractOutputFlags(tt.args)
+		if !slices.Equal(rest, tt.rest) || level != tt.level {
+			t.Errorf("extractOutputFlags(%q) = %q, %d, want %q, %d", tt.args, rest, level, tt.rest, tt.level)
+		}
+	}
+}
+
+// TestOutputLevels checks where each helper writes at each level.
+func TestOutputLevels(t *testing.T) {
+	saved := outputLevel
+	t.Cleanup(func() { outputLevel = saved })
+	tests := []struct {
+		level          verbosity
+		stdout, stderr string
+	}{
+		{levelQuiet, "result\n", "prompt\n"},
+		{levelNormal, "say\nprompt\nresult\n", ""},
+		{levelVerbose, "say\nprompt\nresult\n", "detail\n"},
+	}
+	for _, tt := range tests {
+		outputLevel = tt.level
+		var stdout string
+		stderr := captureOutput(t, &os.Stderr, func() {
+			stdout = captureOutput(t, &os.Stdout, func() {
+				say("say\n")
+				detail("detail\n")
+				prompt("prompt\n")
+				os.Stdout.WriteString("result\n")
+			})
+		})
+		if stdout != tt.stdout || stderr != tt.stderr {
+			t.Errorf("level %d: stdout %q, stderr %q, want %q, %q", tt.level, stdout, stderr, tt.stdout, tt.stderr)
+		}
+	}
+}
+
+// TestQuietSearch checks cali -q -s prints the entries without the header
+// and total around them.
+func TestQuietSearch(t *testing.T) {
+	pipedLog(t)
+	if _, stderr, code := runCLI(t, "", "log", "--exercise", "pushups", "--level", "full", "--reps", "20x2"); code != 0 {
+		t.Fatalf("cali log exited %d: %s", code, stderr)
+	}
+	normal, _, _ := runCLI(t, "", "-s", "today")
+	quietOut, stderr, code := runCLI(t, "", "-q", "-s", "today")
+	if code != 0 {
+		t.Fatalf("cali -q -s today exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(normal, "Workouts for") || !strings.Contains(normal, "Total: 1") {
+		t.Errorf("cali -s today printed:\n%s", normal)
+	}
+	if strings.Contains(quietOut, "Workouts for") || strings.Contains(quietOut, "Total:") || !strings.Contains(quietOut, "Pushups") {
+		t.Errorf("cali -q -s today printed:\n%s", quietOut)
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// holdStep is how much longer suggestOverload asks a hold to be: 10s, or
+// 15s from a minute on.
+func holdStep(hold holdTime) holdTime {
+	if hold >= 60 {
+		return 15
+	}
+	return 10
+}
+
+// overloadHint is what the reps prompt suggests after the last result at an
+// exercise and level.
+type overloadHint struct {
+	Last      string // the last result, as logged
+	Date      string // when it was logged
+	Target    string // one step up from Last; "" when NextLevel is set
+	Timed     bool   // Target is a hold
+	NextLevel string // the level to move on to, as Last met its standard
+}
+
+// suggestOverload returns the step up from last, the Reps×Sets of the
+// latest session: one more rep, or a hold holdStep longer, on the first set
+// that fell short of the one before it, or on the first set when none did,
+// so 8x2 becomes 9,8 and then 9x2. When met, last met the progression
+// standard, and the hint points at next instead, unless there is no next
+// level. ok is false for work that doesn't parse or is an interval.
+func suggestOverload(last string, met bool, next string) (hint overloadHint, ok bool) {
+	parsed, ok := parseRepsSets(last)
+	if !ok || parsed.interval() {
+		return overloadHint{}, false
+	}
+	hint = overloadHint{Last: strings.TrimSpace(last), Timed: parsed.timed()}
+	if met && next != "" {
+		hint.NextLevel = next
+		return hint, true
+	}
+	if parsed.timed() {
+		holds := slices.Clone(parsed.Holds)
+		i := shortSet(holdSeconds(holds))
+		holds[i] += holdStep(holds[i])
+		if i > 0 {
+			holds[i] = min(holds[i], holds[i-1])
+		}
+		parsed.Holds = holds
+	} else {
+		sets := slices.Clone(parsed.Sets)
+		sets[shortSet(sets)]++
+		parsed.Sets = sets
+	}
+	hint.Target = formatRepsSets(parsed)
+	return hint, true
+}
+
+// shortSet returns the first set with less than the one before it, or 0.
+func shortSet(sets []int) int {
+	for i := 1; i < len(sets); i++ {
+		if sets[i] < sets[i-1] {
+			return i
+		}
+	}
+	return 0
+}
+
+// acceptSuggestionEnabled reports whether Enter at the reps prompt takes
+// the suggested target (CALI_ACCEPT_SUGGESTION); otherwise the target is
+// only shown and work has to be typed.
+func acceptSuggestionEnabled() bool {
+	return envEnabled("CALI_ACCEPT_SUGGESTION")
+}
+
+// lastResultHint finds the latest working session at exercise and level
+// among the entries logging reads for current levels (see recentLevels) and
+// suggests the next one (see suggestOverload).
+func lastResultHint(ctx context.Context, storage Storage, exercise, level string) (overloadHint, bool) {
+	recent, err := storage.Recent(ctx, recentLevelEntries)
+	if err != nil {
+		detail("Not suggesting a target: %v\n", err)
+		return overloadHint{}, false
+	}
+	entries := calio.WithoutFuture(recent, currentTime())
+	slices.SortStableFunc(entries, func(a, b WorkoutEntry) int { return strings.Compare(a.Date, b.Date) })
+	last, ok := lastWorkingEntry(entries, exercise, level)
+	if !ok {
+		return overloadHint{}, false
+	}
+	met := tierMet(last.RepsSets, resolveTiers(exercise, level)) == tierProgression
+	var next string
+	levels := calio.Levels(exercise)
+	if i := slices.Index(levels, level); i >= 0 && i < len(levels)-1 {
+		next = levels[i+1]
+	}
+	hint, ok := suggestOverload(last.RepsSets, met, next)
+	hint.Date = last.Date
+	return hint, ok
+}
+
+// printOverloadHint shows hint above the reps prompt.
+func printOverloadHint(hint overloadHint) {
+	switch {
+	case hint.NextLevel != "":
+		prompt(msg("log.overload_next", hint.Last, displayDate(hint.Date), hint.NextLevel))
+	case hint.Timed:
+		prompt(msg("log.overload_hold", hint.Last, displayDate(hint.Date), hint.Target))
+	default:
+		prompt(msg("log.overload_reps", hint.Last, displayDate(hint.Date), hint.Target))
+	}
+}
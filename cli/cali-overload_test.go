@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSuggestOverload(t *testing.T) {
+	tests := []struct {
+		last   string
+		met    bool
+		next   string
+		want   overloadHint
+		wantOK bool
+	}{
+		// Reps: one more on the first set, then on the set that fell short.
+		{"8x2", false, "Close", overloadHint{Last: "8x2", Target: "9,8"}, true},
+		{" 8 x 2 ", false, "Close", overloadHint{Last: "8 x 2", Target: "9,8"}, true},
+		{"9,8", false, "Close", overloadHint{Last: "9,8", Target: "9x2"}, true},
+		{"10,8,8", false, "Close", overloadHint{Last: "10,8,8", Target: "10,9,8"}, true},
+		{"12", false, "Close", overloadHint{Last: "12", Target: "13x1"}, true},
+		// Holds: 10s longer, 15s from a minute on, never past the set before.
+		{"30s", false, "", overloadHint{Last: "30s", Target: "40s", Timed: true}, true},
+		{"58s", false, "", overloadHint{Last: "58s", Target: "68s", Timed: true}, true},
+		{"1min", false, "", overloadHint{Last: "1min", Target: "75s", Timed: true}, true},
+		{"45s x3", false, "", overloadHint{Last: "45s x3", Target: "55s,45s,45s", Timed: true}, true},
+		{"55s,45s,45s", false, "", overloadHint{Last: "55s,45s,45s", Target: "55s,55s,45s", Timed: true}, true},
+		{"50s,45s", false, "", overloadHint{Last: "50s,45s", Target: "50s x2", Timed: true}, true},
+		// A goal just met points at the next level, if there is one.
+		{"20x2", true, "Close", overloadHint{Last: "20x2", NextLevel: "Close"}, true},
+		{"2min", true, "Wall Handstand", overloadHint{Last: "2min", Timed: true, NextLevel: "Wall Handstand"}, true},
+		{"100x2", true, "", overloadHint{Last: "100x2", Target: "101,100"}, true},
+		// Nothing to step up from.
+		{"", false, "Close", overloadHint{}, false},
+		{"felt strong", false, "Close", overloadHint{}, false},
+		{"emom10min@12", false, "Close", overloadHint{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := suggestOverload(tt.last, tt.met, tt.next)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("suggestOverload(%q, %v, %q) = %+v, %v, want %+v, %v", tt.last, tt.met, tt.next, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// TestLastResultHint finds the latest working session at a level, past
+// deloads, other levels and future entries, and points at the next level
+// once the standard is met.
+func TestLastResultHint(t *testing.T) {
+	withGoalOverrides(t, nil)
+	ctx := context.Background()
+	if _, ok := lastResultHint(ctx, &memoryStorage{}, "Pushups", "Full"); ok {
+		t.Error("an empty history gave a hint")
+	}
+
+	storage := &memoryStorage{entries: []WorkoutEntry{
+		{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "8x2"},
+		{Date: "2026-03-02", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "6x2"},
+		{Date: "2026-03-06", Day: "A", Exercise: "Pushups", Level: "Half", RepsSets: "25x2"},
+		{Date: "2026-03-08", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "5x2", Comment: "#deload"},
+		{Date: "2099-01-01", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "30x2"},
+	}}
+	hint, ok := lastResultHint(ctx, storage, "Pushups", "Full")
+	if want := (overloadHint{Last: "8x2", Date: "2026-03-04", Target: "9,8"}); !ok || hint != want {
+		t.Errorf("the hint at Full is %+v, %v, want %+v", hint, ok, want)
+	}
+	hint, ok = lastResultHint(ctx, storage, "Pushups", "Half")
+	if want := (overloadHint{Last: "25x2", Date: "2026-03-06", NextLevel: "Full"}); !ok || hint != want {
+		t.Errorf("the hint at Half is %+v, %v, want %+v", hint, ok, want)
+	}
+	if _, ok := lastResultHint(ctx, storage, "Squats", "Full"); ok {
+		t.Error("an exercise without history gave a hint")
+	}
+}
+
+func TestPrintOverloadHint(t *testing.T) {
+	for _, tt := range []struct {
+		hint overloadHint
+		want string
+	}{
+		{overloadHint{Last: "8x2", Date: "2026-03-04", Target: "9,8"}, msg("log.overload_reps", "8x2", displayDate("2026-03-04"), "9,8")},
+		{overloadHint{Last: "30s", Date: "2026-03-04", Target: "40s", Timed: true}, msg("log.overload_hold", "30s", displayDate("2026-03-04"), "40s")},
+		{overloadHint{Last: "20x2", Date: "2026-03-04", NextLevel: "Close"}, msg("log.overload_next", "20x2", displayDate("2026-03-04"), "Close")},
+	} {
+		if got := captureOutput(t, &os.Stdout, func() { printOverloadHint(tt.hint) }); got != tt.want {
+			t.Errorf("printOverloadHint(%+v) = %q, want %q", tt.hint, got, tt.want)
+		}
+	}
+	if got := msg("log.overload_reps", "8x2", "Mar 4", "9,8"); got != "Last time: 8x2 on Mar 4 — try 9,8 or add a pause rep\n" {
+		t.Errorf("the reps hint reads %q", got)
+	}
+}
+
+// TestPromptRepsSets checks Enter only takes the suggestion when one is
+// offered, as with CALI_ACCEPT_SUGGESTION.
+func TestPromptRepsSets(t *testing.T) {
+	tests := []struct {
+		input, suggested string
+		want, prompt     string
+	}{
+		{"\n", "", "", msg("log.reps_sets_prompt")},
+		{"10 x 2\n", "", "10x2", msg("log.reps_sets_prompt")},
+		{"\n", "9,8", "9,8", msg("log.reps_sets_prompt_default", "9,8")},
+		{"7x2\n", "9,8", "7x2", msg("log.reps_sets_prompt_default", "9,8")},
+	}
+	for _, tt := range tests {
+		var got string
+		out := captureOutput(t, &os.Stdout, func() {
+			got = promptRepsSets(bufio.NewReader(strings.NewReader(tt.input)), tt.suggested)
+		})
+		if got != tt.want || out != tt.prompt {
+			t.Errorf("promptRepsSets(%q, %q) = %q after %q, want %q after %q", tt.input, tt.suggested, got, out, tt.want, tt.prompt)
+		}
+	}
+
+	t.Setenv("CALI_ACCEPT_SUGGESTION", "")
+	if acceptSuggestionEnabled() {
+		t.Error("suggestions are taken by default")
+	}
+	t.Setenv("CALI_ACCEPT_SUGGESTION", "1")
+	if !acceptSuggestionEnabled() {
+		t.Error("CALI_ACCEPT_SUGGESTION=1 doesn't take suggestions")
+	}
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appDirName is the directory cali keeps its files in under each base
+// directory, and the legacy data directory in the home directory.
+const appDirName = "cali-logger"
+
+// pathEnv is what cali's file locations depend on, so each OS can be
+// checked without running on it.
+type pathEnv struct {
+	goos    string
+	getenv  func(string) string
+	homeDir string
+	exists  func(path string) bool
+}
+
+func currentPathEnv() (pathEnv, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return pathEnv{}, err
+	}
+	return pathEnv{goos: runtime.GOOS, getenv: os.Getenv, homeDir: homeDir, exists: pathExists}, nil
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// appPaths resolves where cali keeps each kind of file. Every file cali
+// writes for itself goes through it:
+//
+//   - config: settings the user may edit (config.env, remind.env,
+//     descriptions.json)
+//   - data: what must not be lost (the local log, automatic backups,
+//     entries whose save failed)
+//   - state: what only matters to this machine (the session lock, watched
+//     tutorials)
+//   - cache: what can be rebuilt at any time
+//
+// On Linux and the BSDs they follow the XDG base directories, honoring
+// XDG_CONFIG_HOME, XDG_DATA_HOME, XDG_STATE_HOME and XDG_CACHE_HOME. macOS
+// keeps everything but the cache in ~/Library/Application Support, and
+// Windows uses %AppData% for config and data and %LocalAppData% for state
+// and cache.
+//
+// Older versions kept data and state together in ~/cali-logger. While that
+// directory exists, data and state stay there (see Legacy and legacyHint).
+type appPaths struct {
+	env pathEnv
+}
+
+// userPaths returns the locations for the current user.
+func userPaths() (appPaths, error) {
+	env, err := currentPathEnv()
+	if err != nil {
+		return appPaths{}, err
+	}
+	return appPaths{env: env}, nil
+}
+
+// baseDir returns the XDG variable when it holds an absolute path, as the
+// spec requires, and fallback under the home directory otherwise.
+func (p appPaths) baseDir(variable string, fallback ...string) string {
+	if dir := p.env.getenv(variable); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(append([]string{p.env.homeDir}, fallback...)...)
+}
+
+// windowsDir returns a Windows known folder from variable, or fallback
+// under the home directory when it is unset.
+func (p appPaths) windowsDir(variable string, fallback ...string) string {
+	if dir := p.env.getenv(variable); dir != "" {
+		return dir
+	}
+	return filepath.Join(append([]string{p.env.homeDir}, fallback...)...)
+}
+
+// ConfigDir is where settings live.
+func (p appPaths) ConfigDir() string {
+	switch p.env.goos {
+	case "windows":
+		return filepath.Join(p.windowsDir("AppData", "AppData", "Roaming"), appDirName)
+	case "darwin":
+		return filepath.Join(p.env.homeDir, "Library", "Application Support", appDirName)
+	}
+	return filepath.Join(p.baseDir("XDG_CONFIG_HOME", ".config"), appDirName)
+}
+
+// ConfigFile returns the path of the settings file name. A file only found
+// in the legacy directory is still read from there.
+func (p appPaths) ConfigFile(name string) string {
+	path := filepath.Join(p.ConfigDir(), name)
+	if legacy := filepath.Join(p.legacyDir(), name); !p.env.exists(path) && p.env.exists(legacy) {
+		return legacy
+	}
+	return path
+}
+
+// DataDir is where the local log, backups and unsaved entries live.
+func (p appPaths) DataDir() string {
+	if p.Legacy() {
+		return p.legacyDir()
+	}
+	return p.dataDir()
+}
+
+func (p appPaths) dataDir() string {
+	switch p.env.goos {
+	case "windows":
+		return filepath.Join(p.windowsDir("AppData", "AppData", "Roaming"), appDirName)
+	case "darwin":
+		return filepath.Join(p.env.homeDir, "Library", "Application Support", appDirName)
+	}
+	return filepath.Join(p.baseDir("XDG_DATA_HOME", ".local", "share"), appDirName)
+}
+
+// StateDir is where the session lock and watched tutorials live.
+func (p appPaths) StateDir() string {
+	if p.Legacy() {
+		return p.legacyDir()
+	}
+	return p.stateDir()
+}
+
+func (p appPaths) stateDir() string {
+	switch p.env.goos {
+	case "windows":
+		return filepath.Join(p.windowsDir("LocalAppData", "AppData", "Local"), appDirName)
+	case "darwin":
+		return filepath.Join(p.env.homeDir, "Library", "Application Support", appDirName)
+	}
+	return filepath.Join(p.baseDir("XDG_STATE_HOME", ".local", "state"), appDirName)
+}
+
+// CacheDir is where files that can be rebuilt live.
+func (p appPaths) CacheDir() string {
+	switch p.env.goos {
+	case "windows":
+		return filepath.Join(p.windowsDir("LocalAppData", "AppData", "Local"), appDirName, "cache")
+	case "darwin":
+		return filepath.Join(p.env.homeDir, "Library", "Caches", appDirName)
+	}
+	return filepath.Join(p.baseDir("XDG_CACHE_HOME", ".cache"), appDirName)
+}
+
+func (p appPaths) legacyDir() string {
+	return filepath.Join(p.env.homeDir, appDirName)
+}
+
+// Legacy reports whether data and state are still kept in ~/cali-logger,
+// which is so as long as it exists. Moving its files over and removing it
+// ends it; a config file written to the new location in the meantime
+// doesn't.
+func (p appPaths) Legacy() bool {
+	return p.env.exists(p.legacyDir())
+}
+
+// legacyHint says how to move from ~/cali-logger to the new directories,
+// or "" when it isn't in use.
+func (p appPaths) legacyHint() string {
+	if !p.Legacy() {
+		return ""
+	}
+	if p.stateDir() == p.dataDir() && p.ConfigDir() == p.dataDir() {
+		return msg("paths.legacy", p.legacyDir(), p.dataDir())
+	}
+	return msg("paths.legacy_split", p.legacyDir(), p.dataDir(), p.stateDir(), p.ConfigDir())
+}
+
+// makeDirFor creates the directory path goes in, private to the user as the
+// XDG spec asks of the directories it creates.
+func makeDirFor(path string) error {
+	return os.MkdirAll(filepath.Dir(path), 0700)
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakePaths returns the appPaths of goos for a home of /home/u with env
+// set and the given paths existing.
+func fakePaths(goos string, env map[string]string, existing ...string) appPaths {
+	return appPaths{env: pathEnv{
+		goos:    goos,
+		getenv:  func(name string) string { return env[name] },
+		homeDir: "/home/u",
+		exists: func(path string) bool {
+			for _, p := range existing {
+				if p == path {
+					return true
+				}
+			}
+			return false
+		},
+	}}
+}
+
+func TestXDGPaths(t *testing.T) {
+	tests := []struct {
+		name                        string
+		env                         map[string]string
+		config, data, state, legacy string
+	}{
+		{
+			name:   "defaults",
+			config: "/home/u/.config/cali-logger",
+			data:   "/home/u/.local/share/cali-logger",
+			state:  "/home/u/.local/state/cali-logger",
+		},
+		{
+			name:   "XDG variables",
+			env:    map[string]string{"XDG_CONFIG_HOME": "/cfg", "XDG_DATA_HOME": "/data", "XDG_STATE_HOME": "/state"},
+			config: "/cfg/cali-logger",
+			data:   "/data/cali-logger",
+			state:  "/state/cali-logger",
+		},
+		{
+			// The spec says to ignore relative paths.
+			name:   "relative XDG variables",
+			env:    map[string]string{"XDG_CONFIG_HOME": "cfg", "XDG_DATA_HOME": "./data"},
+			config: "/home/u/.config/cali-logger",
+			data:   "/home/u/.local/share/cali-logger",
+			state:  "/home/u/.local/state/cali-logger",
+		},
+	}
+	for _, tt := range tests {
+		p := fakePaths("linux", tt.env)
+		if got := p.ConfigDir(); got != filepath.FromSlash(tt.config) {
+			t.Errorf("%s: ConfigDir = %s, want %s", tt.name, got, tt.config)
+		}
+		if got := p.DataDir(); got != filepath.FromSlash(tt.data) {
+			t.Errorf("%s: DataDir = %s, want %s", tt.name, got, tt.data)
+		}
+		if got := p.StateDir(); got != filepath.FromSlash(tt.state) {
+			t.Errorf("%s: StateDir = %s, want %s", tt.name, got, tt.state)
+		}
+		if p.Legacy() || p.legacyHint() != "" {
+			t.Errorf("%s: legacy without ~/cali-logger", tt.name)
+		}
+	}
+}
+
+// TestLegacyPaths checks a ~/cali-logger left by an older version keeps
+// data and state, and config files found only there, until it is moved.
+func TestLegacyPaths(t *testing.T) {
+	legacy := filepath.FromSlash("/home/u/cali-logger")
+	env := map[string]string{"XDG_CONFIG_HOME": "/cfg"}
+	p := fakePaths("linux", env, legacy, filepath.Join(legacy, "descriptions.json"), filepath.Join(legacy, "config.env"),
+		filepath.FromSlash("/cfg/cali-logger/config.env"))
+
+	if !p.Legacy() || p.DataDir() != legacy || p.StateDir() != legacy {
+		t.Errorf("Legacy = %v, DataDir = %s, StateDir = %s, want %s", p.Legacy(), p.DataDir(), p.StateDir(), legacy)
+	}
+	if got := p.ConfigFile("descriptions.json"); got != filepath.Join(legacy, "descriptions.json") {
+		t.Errorf("descriptions.json only in the legacy directory: ConfigFile = %s", got)
+	}
+	if got := p.ConfigFile("config.env"); got != filepath.FromSlash("/cfg/cali-logger/config.env") {
+		t.Errorf("config.env in both: ConfigFile = %s, want the new one", got)
+	}
+	if got := p.ConfigFile("remind.env"); got != filepath.FromSlash("/cfg/cali-logger/remind.env") {
+		t.Errorf("remind.env in neither: ConfigFile = %s, want the new one", got)
+	}
+	hint := p.legacyHint()
+	for _, dir := range []string{legacy, p.dataDir(), p.stateDir(), p.ConfigDir()} {
+		if !strings.Contains(hint, dir) {
+			t.Errorf("legacy hint %q doesn't name %s", hint, dir)
+		}
+	}
+
+	// Once moved, the new directories take over.
+	moved := fakePaths("linux", env, filepath.FromSlash("/cfg/cali-logger/descriptions.json"))
+	if moved.Legacy() || moved.ConfigFile("descriptions.json") != filepath.FromSlash("/cfg/cali-logger/descriptions.json") ||
+		moved.DataDir() != filepath.FromSlash("/home/u/.local/share/cali-logger") {
+		t.Errorf("after moving: Legacy = %v, ConfigFile = %s, DataDir = %s", moved.Legacy(), moved.ConfigFile("descriptions.json"), moved.DataDir())
+	}
+}
+
+func TestPlatformPaths(t *testing.T) {
+	mac := fakePaths("darwin", map[string]string{"XDG_CONFIG_HOME": "/cfg"})
+	support := filepath.FromSlash("/home/u/Library/Application Support/cali-logger")
+	if mac.ConfigDir() != support || mac.DataDir() != support || mac.StateDir() != support {
+		t.Errorf("darwin: %s, %s, %s, want all in %s", mac.ConfigDir(), mac.DataDir(), mac.StateDir(), support)
+	}
+
+	windows := fakePaths("windows", map[string]string{"AppData": `C:\Users\u\AppData\Roaming`, "LocalAppData": `C:\Users\u\AppData\Local`})
+	if got := windows.ConfigDir(); got != filepath.Join(`C:\Users\u\AppData\Roaming`, appDirName) {
+		t.Errorf("windows ConfigDir = %s", got)
+	}
+	if got := windows.StateDir(); got != filepath.Join(`C:\Users\u\AppData\Local`, appDirName) {
+		t.Errorf("windows StateDir = %s", got)
+	}
+}
+
+// TestDescriptionOverridesFollowXDG reads descriptions.json from
+// XDG_CONFIG_HOME, and from ~/cali-logger while it is only there.
+func TestDescriptionOverridesFollowXDG(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "cfg"))
+	t.Cleanup(func() { loadedDescriptionOverrides = nil })
+	write := func(dir, summary string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		text := `{"Pushups": {"Wall": {"summary": "` + summary + `", "cues": []}}}`
+		if err := os.WriteFile(filepath.Join(dir, "descriptions.json"), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	summary := func() string {
+		loadedDescriptionOverrides = nil
+		desc, _ := resolveDescription("Pushups", "Wall")
+		return desc.Summary
+	}
+
+	builtIn := summary()
+	write(filepath.Join(home, appDirName), "legacy")
+	if got := summary(); got != "legacy" {
+		t.Errorf("with ~/cali-logger/descriptions.json: %q, want it", got)
+	}
+	write(filepath.Join(home, "cfg", appDirName), "xdg")
+	if got := summary(); got != "xdg" {
+		t.Errorf("with $XDG_CONFIG_HOME/cali-logger/descriptions.json too: %q, want the XDG one", got)
+	}
+	if err := os.RemoveAll(filepath.Join(home, "cfg")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(home, appDirName)); err != nil {
+		t.Fatal(err)
+	}
+	if got := summary(); got != builtIn {
+		t.Errorf("without either: %q, want the built-in %q", got, builtIn)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package cli
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether a process with pid is running. Signal 0 only
+// checks; EPERM means it runs as another user.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
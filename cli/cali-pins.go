@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// recentLevelEntries is how many of the latest entries logging reads to
+// find an exercise's current level when it isn't pinned.
+const recentLevelEntries = 50
+
+// loadedLevelPins holds the pins read by loadLevelPins, level by exercise.
+var loadedLevelPins map[string]string
+
+// loadLevelPins reads storage's level pins so currentLevels prefers them
+// over the latest session. Without them, or when they can't be read, the
+// current level is derived from the log.
+func loadLevelPins(ctx context.Context, storage Storage) {
+	loadedLevelPins = nil
+	store, ok := storage.(calio.LevelPinStore)
+	if !ok {
+		return
+	}
+	pins, err := store.LevelPins(ctx)
+	if errors.Is(err, calio.ErrNoLevelPinStore) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read level pins, using the latest sessions: %v\n", err)
+		return
+	}
+	loadedLevelPins = map[string]string{}
+	for _, pin := range pins {
+		loadedLevelPins[pin.Exercise] = pin.Level
+	}
+}
+
+// levelPin returns the level pinned for exercise, if there is one.
+func levelPin(exercise string) (string, bool) {
+	level, ok := loadedLevelPins[exercise]
+	return level, ok
+}
+
+// currentLevelMark follows the current level of exercise in level lists,
+// telling a pinned level from one derived from the log.
+func currentLevelMark(exercise string) string {
+	if _, ok := levelPin(exercise); ok {
+		return "  " + msg("levels.current_pinned")
+	}
+	return "  " + msg("levels.current_derived")
+}
+
+// recentLevels returns the current level by exercise: the pinned one, else
+// the level of its latest working session among the recent entries.
+// Exercises neither pinned nor trained lately are missing.
+func recentLevels(ctx context.Context, storage Storage) map[string]string {
+	current := map[string]string{}
+	recent, err := storage.Recent(ctx, recentLevelEntries)
+	if err != nil {
+		detail("Not showing current levels: %v\n", err)
+	}
+	latest := map[string]string{}
+	for _, entry := range workingEntries(calio.WithoutFuture(recent, currentTime())) {
+		entry = calio.Canonical(entry)
+		if entry.Date >= latest[entry.Exercise] {
+			latest[entry.Exercise] = entry.Date
+			current[entry.Exercise] = entry.Level
+		}
+	}
+	for exercise, level := range loadedLevelPins {
+		current[exercise] = level
+	}
+	return current
+}
+
+// runLevelPin handles cali levels set and cali levels unset.
+func runLevelPin(ctx context.Context, action string, args []string) error {
+	const usage = `usage: cali levels set <exercise> <level> | cali levels unset <exercise>`
+	var exercise, level string
+	if action == "set" {
+		var err error
+		if exercise, level, err = parseTutorialArgs(args); err != nil {
+			return err
+		}
+		if level == "" {
+			return usageError(usage)
+		}
+	} else {
+		var ok bool
+		if len(args) == 0 {
+			return usageError(usage)
+		}
+		if exercise, ok = normalizeExercise(strings.Join(args, " ")); !ok {
+			return usageError("%s", msg("error.unknown_exercise", strings.Join(args, " ")))
+		}
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	store, ok := storage.(calio.LevelPinStore)
+	if !ok {
+		return storageError("reading level pins", calio.ErrNoLevelPinStore)
+	}
+	loadLevelPins(ctx, storage)
+	pinned, isPinned := levelPin(exercise)
+	if action == "unset" && !isPinned {
+		sayln(msg("levels.not_pinned", exercise))
+		return nil
+	}
+	if action == "set" && isPinned && pinned == level {
+		sayln(msg("levels.pin_unchanged", exercise, level))
+		return nil
+	}
+
+	if err := store.SetLevelPin(ctx, calio.LevelPin{Exercise: exercise, Level: level}); err != nil {
+		return storageError("saving the level pin", err)
+	}
+	if action == "set" {
+		say(msg("levels.pinned", exercise, level))
+	} else {
+		say(msg("levels.unpinned", exercise))
+	}
+	return nil
+}
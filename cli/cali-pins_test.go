@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// withLevelPins replaces the loaded level pins for the test.
+func withLevelPins(t *testing.T, pins map[string]string) {
+	saved := loadedLevelPins
+	loadedLevelPins = pins
+	t.Cleanup(func() { loadedLevelPins = saved })
+}
+
+// pinHistory is a log where Pushups were last done at Half, a warm-up after
+// a week of Full sessions.
+var pinHistory = []WorkoutEntry{
+	{Date: "2026-03-02", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Goal: "20x2", Category: calio.CategoryStrength},
+	{Date: "2026-03-02", Day: "A", Exercise: "Squats", Level: "Half", RepsSets: "35x2", Goal: "50x2", Category: calio.CategoryStrength},
+	{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Half", RepsSets: "25x2", Goal: "25x2", Category: calio.CategoryStrength},
+}
+
+func TestLevelPinPrecedence(t *testing.T) {
+	storage := pipedLog(t)
+	ctx := context.Background()
+	for _, entry := range pinHistory {
+		if _, err := storage.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	withLevelPins(t, nil)
+	derived := map[string]string{"Pushups": "Half", "Squats": "Half"}
+	if got := recentLevels(ctx, storage); !maps.Equal(got, derived) {
+		t.Errorf("without pins the current levels are %v, want %v", got, derived)
+	}
+
+	withLevelPins(t, map[string]string{"Pushups": "Full", "Pullups": "Jackknife"})
+	pinned := map[string]string{"Pushups": "Full", "Squats": "Half", "Pullups": "Jackknife"}
+	if got := recentLevels(ctx, storage); !maps.Equal(got, pinned) {
+		t.Errorf("with pins the current levels are %v, want %v", got, pinned)
+	}
+	want := []exerciseLevel{{"Pushups", "Full"}, {"Squats", "Half"}, {"Pullups", "Jackknife"}}
+	if got := currentLevels(pinHistory); !slices.Equal(got, want) {
+		t.Errorf("currentLevels = %v, want %v", got, want)
+	}
+	if currentLevelMark("Pushups") == currentLevelMark("Squats") {
+		t.Errorf("a pinned and a derived level are marked alike: %q", currentLevelMark("Pushups"))
+	}
+}
+
+// TestLevelsPinCommand pins a level, checks it outlasts the command and a
+// later session at another level, and unpins it again.
+func TestLevelsPinCommand(t *testing.T) {
+	storage := pipedLog(t)
+	for _, entry := range pinHistory {
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	levelLine := func(stdout, level string) string {
+		for _, line := range strings.Split(stdout, "\n") {
+			if strings.Contains(line, ". "+level+" ") {
+				return line
+			}
+		}
+		return ""
+	}
+
+	stdout, _, _ := runCLI(t, "", "levels", "pushups")
+	if !strings.Contains(levelLine(stdout, "Half"), "(last logged)") {
+		t.Errorf("cali levels pushups before pinning printed\n%s", stdout)
+	}
+	if stdout, stderr, code := runCLI(t, "", "levels", "set", "pushups", "full"); code != 0 || !strings.Contains(stdout, "Pushups pinned at Full") {
+		t.Fatalf("cali levels set exited %d, printed %q %s", code, stdout, stderr)
+	}
+	if stdout, _, _ := runCLI(t, "", "levels", "set", "Pushups", "Full"); !strings.Contains(stdout, "already pinned") {
+		t.Errorf("pinning again printed %q", stdout)
+	}
+	if _, _, code := runCLI(t, "", "log", "--exercise", "pushups", "--level", "half", "--reps", "25x2"); code != 0 {
+		t.Fatal("logging exited", code)
+	}
+	stdout, _, _ = runCLI(t, "", "levels", "pushups")
+	if !strings.Contains(levelLine(stdout, "Full"), "(pinned)") || strings.Contains(levelLine(stdout, "Half"), "current") {
+		t.Errorf("cali levels pushups with a pin printed\n%s", stdout)
+	}
+
+	if stdout, _, code := runCLI(t, "", "levels", "unset", "pushups"); code != 0 || !strings.Contains(stdout, "no longer pinned") {
+		t.Errorf("cali levels unset exited %d, printed %q", code, stdout)
+	}
+	stdout, _, _ = runCLI(t, "", "levels", "pushups")
+	if !strings.Contains(levelLine(stdout, "Half"), "(last logged)") || strings.Contains(stdout, "(pinned)") {
+		t.Errorf("cali levels pushups after unpinning printed\n%s", stdout)
+	}
+	if stdout, _, code := runCLI(t, "", "levels", "unset", "pushups"); code != 0 || !strings.Contains(stdout, "is not pinned") {
+		t.Errorf("unpinning again exited %d, printed %q", code, stdout)
+	}
+
+	for _, args := range [][]string{
+		{"levels", "set", "pushups"},
+		{"levels", "set", "pushups", "archer"},
+		{"levels", "set", "burpees", "full"},
+		{"levels", "unset"},
+		{"levels", "unset", "burpees"},
+	} {
+		if _, _, code := runCLI(t, "", args...); code != exitUsage {
+			t.Errorf("cali %s exited %d, want %d", strings.Join(args, " "), code, exitUsage)
+		}
+	}
+	if entries := logged(t, storage); len(entries) != 4 {
+		t.Errorf("the log holds %d entries, want the pins kept apart from them", len(entries))
+	}
+}
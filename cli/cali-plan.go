@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// defaultSessionsPerWeek is how many sessions cali plan spreads over a week
+// unless CALI_SESSIONS_PER_WEEK says otherwise: the A/B/C rotation once.
+const defaultSessionsPerWeek = 3
+
+// planHistoryWeeks is how far back cali plan looks for the weekdays you
+// train on.
+const planHistoryWeeks = 8
+
+// sessionsPerWeek returns CALI_SESSIONS_PER_WEEK.
+func sessionsPerWeek() (int, error) {
+	raw := strings.TrimSpace(os.Getenv("CALI_SESSIONS_PER_WEEK"))
+	if raw == "" {
+		return defaultSessionsPerWeek, nil
+	}
+	perWeek, err := strconv.Atoi(raw)
+	if err != nil || perWeek < 1 || perWeek > 7 {
+		return 0, fmt.Errorf("invalid CALI_SESSIONS_PER_WEEK %q (use 1 to 7 sessions)", raw)
+	}
+	return perWeek, nil
+}
+
+// spreadWeekdays returns n weekdays spread over the week from Monday:
+// Mon/Thu for 2, Mon/Wed/Fri for 3, Mon to Sat for 6.
+func spreadWeekdays(n int) []time.Weekday {
+	days := make([]time.Weekday, n)
+	for i := range days {
+		days[i] = weekOrder[i*len(weekOrder)/n]
+	}
+	return days
+}
+
+// preferredWeekdays returns, in week order, the n weekdays most of dates
+// fall on; dates are distinct training dates. Ties, and the days left over
+// when dates cover fewer than n weekdays, go to spreadWeekdays(n) first and
+// then to the earlier day of the week, so no history gives the even spread.
+func preferredWeekdays(dates []time.Time, n int) []time.Weekday {
+	counts := map[time.Weekday]int{}
+	for _, date := range dates {
+		counts[date.Weekday()]++
+	}
+	spread := spreadWeekdays(n)
+	spreadFirst := func(day time.Weekday) int {
+		if slices.Contains(spread, day) {
+			return 0
+		}
+		return 1
+	}
+	ranked := slices.Clone(weekOrder)
+	slices.SortStableFunc(ranked, func(a, b time.Weekday) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(spreadFirst(a), spreadFirst(b))
+	})
+	var days []time.Weekday
+	for _, day := range weekOrder {
+		if slices.Contains(ranked[:n], day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// weekPlan is what projectWeek projects from.
+type weekPlan struct {
+	Today    time.Time // the first day projected, at midnight
+	LastDay  string    // letter of the latest strength session; "" with none
+	LastDate time.Time // date of that session
+	Next     string    // letter to train next (see suggestDay); "" follows LastDay
+	Weekdays []time.Weekday
+	Rest     map[string]string // rest days marked ahead, by date, to their reason
+}
+
+// plannedDay is one day of a projected week.
+type plannedDay struct {
+	Date   time.Time
+	Day    string // letter to train; "" on a rest day
+	Logged bool   // Day is already logged (today only)
+	Rest   bool   // marked as a rest day with cali rest
+	Reason string
+}
+
+// projectWeek projects the seven days from p.Today: a session on each of
+// p.Weekdays, following the rotation from p.Next, and rest on the others.
+// A marked rest day takes a session's place, and the letter moves on to the
+// next session, as for a day missed. Today shows the letter logged when it
+// is already trained. Nothing else is assumed, so logging another letter or
+// on another day shifts the projection that follows.
+func projectWeek(p weekPlan) []plannedDay {
+	next := p.Next
+	if next == "" {
+		next = nextDay(p.LastDay)
+	}
+	days := make([]plannedDay, 7)
+	for i := range days {
+		date := p.Today.AddDate(0, 0, i)
+		day := plannedDay{Date: date}
+		reason, rest := p.Rest[date.Format(calio.DateLayout)]
+		switch {
+		case i == 0 && p.LastDay != "" && p.LastDate.Equal(p.Today):
+			day.Day, day.Logged = p.LastDay, true
+		case rest:
+			day.Rest, day.Reason = true, reason
+		case slices.Contains(p.Weekdays, date.Weekday()):
+			day.Day = next
+			next = nextDay(next)
+		}
+		days[i] = day
+	}
+	return days
+}
+
+// planOptions are the flags of cali plan.
+type planOptions struct {
+	Week bool
+}
+
+// newPlanFlagSet declares the flags of cali plan into opts.
+func newPlanFlagSet(opts *planOptions) *flag.FlagSet {
+	fs := newFlagSet("plan")
+	fs.BoolVar(&opts.Week, "week", false, "project the next seven days (also what cali plan shows without it)")
+	return fs
+}
+
+func runPlan(ctx context.Context, args []string) error {
+	var opts planOptions
+	fs := newPlanFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError("usage: cali plan [--week]")
+	}
+	perWeek, err := sessionsPerWeek()
+	if err != nil {
+		return usageError("%v", err)
+	}
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+
+	now := currentTime()
+	today := truncateToDate(now)
+	end := today.AddDate(0, 0, 6)
+	entries, err := storage.Range(ctx, today.AddDate(0, 0, -7*planHistoryWeeks).Format(calio.DateLayout),
+		today.Format(calio.DateLayout))
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	strength, _ := splitByCategory(calio.WithoutFuture(entries, now))
+	var dates []time.Time
+	for date := range trainedDates(strength) {
+		if parsed, err := time.ParseInLocation(calio.DateLayout, date, today.Location()); err == nil {
+			dates = append(dates, parsed)
+		}
+	}
+
+	plan := weekPlan{Today: today, Weekdays: preferredWeekdays(dates, perWeek), Rest: map[string]string{}}
+	lastDay, lastDate, err := storage.LastTrainingDay(ctx)
+	if err != nil {
+		detail("Previous training day unavailable: %v\n", err)
+	}
+	if lastDay != "" {
+		plan.LastDay = lastDay
+		plan.LastDate, _ = time.ParseInLocation(calio.DateLayout, lastDate, today.Location())
+	}
+	plan.Next = suggestDay(ctx, storage, lastDay)
+	if rest, ok := storage.(calio.RestLog); ok {
+		marked, err := rest.RestDays(ctx, today.Format(calio.DateLayout), end.Format(calio.DateLayout))
+		if err != nil && !errors.Is(err, calio.ErrNoRestLog) {
+			return storageError("reading rest days", err)
+		}
+		for _, day := range marked {
+			plan.Rest[day.Date] = day.Reason
+		}
+	}
+
+	say(msg("plan.header"))
+	for _, day := range projectWeek(plan) {
+		when := day.Date.Weekday().String()[:3] + " " + displayDate(day.Date.Format(calio.DateLayout))
+		switch {
+		case day.Logged:
+			fmt.Print(msg("plan.logged", when, day.Day))
+		case day.Day != "":
+			fmt.Print(msg("plan.session", when, day.Day))
+		case day.Rest:
+			fmt.Print(msg("plan.marked_rest", when, strings.TrimSpace(restMark+day.Reason)))
+		default:
+			fmt.Print(msg("plan.rest", when))
+		}
+	}
+	weekdays := make([]string, len(plan.Weekdays))
+	for i, day := range plan.Weekdays {
+		weekdays[i] = day.String()[:3]
+	}
+	if len(dates) > 0 {
+		say(msg("plan.basis", perWeek, strings.Join(weekdays, "/"), planHistoryWeeks))
+	} else {
+		say(msg("plan.basis_spread", perWeek, strings.Join(weekdays, "/"), planHistoryWeeks))
+	}
+	say(msg("plan.shifts"))
+	return nil
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestSessionsPerWeek(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  int
+		err   bool
+	}{
+		{"", defaultSessionsPerWeek, false},
+		{" 2 ", 2, false},
+		{"7", 7, false},
+		{"0", 0, true},
+		{"8", 0, true},
+		{"thrice", 0, true},
+	} {
+		t.Setenv("CALI_SESSIONS_PER_WEEK", tt.value)
+		if got, err := sessionsPerWeek(); got != tt.want || (err != nil) != tt.err {
+			t.Errorf("CALI_SESSIONS_PER_WEEK=%q: %d, %v", tt.value, got, err)
+		}
+	}
+}
+
+// weekdays is a short way to write the weekdays of a plan.
+func weekdays(days ...time.Weekday) []time.Weekday { return days }
+
+const (
+	mon = time.Monday
+	tue = time.Tuesday
+	wed = time.Wednesday
+	thu = time.Thursday
+	fri = time.Friday
+	sat = time.Saturday
+	sun = time.Sunday
+)
+
+func TestPreferredWeekdays(t *testing.T) {
+	// trained returns a date on each of days, in each of weeks weeks.
+	trained := func(weeks int, days ...time.Weekday) []time.Time {
+		monday := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+		var dates []time.Time
+		for week := range weeks {
+			for _, day := range days {
+				dates = append(dates, monday.AddDate(0, 0, 7*week+(int(day)+6)%7))
+			}
+		}
+		return dates
+	}
+	tests := []struct {
+		name  string
+		dates []time.Time
+		n     int
+		want  []time.Weekday
+	}{
+		{"no history, once", nil, 1, weekdays(mon)},
+		{"no history, 2x", nil, 2, weekdays(mon, thu)},
+		{"no history, 3x", nil, 3, weekdays(mon, wed, fri)},
+		{"no history, 4x", nil, 4, weekdays(mon, tue, thu, sat)},
+		{"no history, 6x", nil, 6, weekdays(mon, tue, wed, thu, fri, sat)},
+		{"no history, daily", nil, 7, weekdays(mon, tue, wed, thu, fri, sat, sun)},
+		{"Tue/Thu/Sat, 3x", trained(8, tue, thu, sat), 3, weekdays(tue, thu, sat)},
+		{"Tue/Thu/Sat, 2x", append(trained(8, tue, sat), trained(5, thu)...), 2, weekdays(tue, sat)},
+		// Two days known; the third is the spread's before any other.
+		{"Tue/Sat, 3x", trained(4, tue, sat), 3, weekdays(mon, tue, sat)},
+		{"Sun, 2x", trained(1, sun), 2, weekdays(mon, sun)},
+		// A tie goes to the spread's day.
+		{"Tue/Wed tie, 1x", trained(3, tue, wed), 1, weekdays(tue)},
+		{"Tue/Wed/Thu tie, 2x", trained(3, tue, wed, thu), 2, weekdays(tue, thu)},
+		{"6x from 5 days", trained(8, mon, tue, thu, fri, sun), 6, weekdays(mon, tue, wed, thu, fri, sun)},
+	}
+	for _, tt := range tests {
+		if got := preferredWeekdays(tt.dates, tt.n); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: preferredWeekdays = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestProjectWeek projects weeks for 2, 3 and 6 sessions a week, with and
+// without history, from a day already trained, past a marked rest day and
+// with targets picking the first session.
+func TestProjectWeek(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		plan weekPlan
+		want string
+	}{
+		{"3x, no history", weekPlan{Today: monday, Weekdays: weekdays(mon, wed, fri)},
+			"Mon A, Tue -, Wed B, Thu -, Fri C, Sat -, Sun -"},
+		{"2x after B", weekPlan{Today: monday, LastDay: "B", LastDate: monday.AddDate(0, 0, -4), Weekdays: weekdays(mon, thu)},
+			"Mon C, Tue -, Wed -, Thu A, Fri -, Sat -, Sun -"},
+		{"6x after C", weekPlan{Today: monday, LastDay: "C", LastDate: monday.AddDate(0, 0, -1), Weekdays: weekdays(mon, tue, wed, thu, fri, sat)},
+			"Mon A, Tue B, Wed C, Thu A, Fri B, Sat C, Sun -"},
+		{"3x from Wednesday", weekPlan{Today: monday.AddDate(0, 0, 2), LastDay: "A", LastDate: monday, Weekdays: weekdays(mon, wed, fri)},
+			"Wed B, Thu -, Fri C, Sat -, Sun -, Mon A, Tue -"},
+		{"today trained", weekPlan{Today: monday, LastDay: "A", LastDate: monday, Next: "B", Weekdays: weekdays(mon, wed, fri)},
+			"Mon A logged, Tue -, Wed B, Thu -, Fri C, Sat -, Sun -"},
+		{"today trained off the plan", weekPlan{Today: monday.AddDate(0, 0, 1), LastDay: "A", LastDate: monday.AddDate(0, 0, 1), Next: "B", Weekdays: weekdays(mon, wed, fri)},
+			"Tue A logged, Wed B, Thu -, Fri C, Sat -, Sun -, Mon A"},
+		{"rest marked", weekPlan{Today: monday, LastDay: "C", LastDate: monday.AddDate(0, 0, -3), Weekdays: weekdays(mon, wed, fri),
+			Rest: map[string]string{"2026-03-04": "travel", "2026-03-05": ""}},
+			"Mon A, Tue -, Wed rest travel, Thu rest, Fri B, Sat -, Sun -"},
+		{"targets pick C", weekPlan{Today: monday, LastDay: "A", LastDate: monday.AddDate(0, 0, -3), Next: "C", Weekdays: weekdays(mon, wed, fri)},
+			"Mon C, Tue -, Wed A, Thu -, Fri B, Sat -, Sun -"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, day := range projectWeek(tt.plan) {
+			text := day.Date.Weekday().String()[:3] + " "
+			switch {
+			case day.Logged:
+				text += day.Day + " logged"
+			case day.Rest:
+				text += strings.TrimSpace("rest " + day.Reason)
+			case day.Day != "":
+				text += day.Day
+			default:
+				text += "-"
+			}
+			got = append(got, text)
+		}
+		if strings.Join(got, ", ") != tt.want {
+			t.Errorf("%s: projectWeek = %s, want %s", tt.name, strings.Join(got, ", "), tt.want)
+		}
+	}
+}
+
+// TestPlanCommand projects a week without history, then with today logged
+// and tomorrow marked as a rest day.
+func TestPlanCommand(t *testing.T) {
+	pipedLog(t)
+	withGoalOverrides(t, nil)
+	t.Setenv("CALI_SESSIONS_PER_WEEK", "")
+	t.Setenv("CALI_TARGETS", "")
+	stdout, stderr, code := runCLI(t, "", "plan", "--week")
+	if code != 0 || !strings.HasPrefix(stdout, msg("plan.header")) || strings.Count(stdout, "\n  ") != 7 ||
+		!strings.Contains(stdout, msg("plan.basis_spread", 3, "Mon/Wed/Fri", planHistoryWeeks)) || !strings.HasSuffix(stdout, msg("plan.shifts")) {
+		t.Errorf("cali plan --week exited %d:\n%s%s", code, stdout, stderr)
+	}
+	if strings.Count(stdout, "Day ") != 3 {
+		t.Errorf("cali plan --week projects %d sessions:\n%s", strings.Count(stdout, "Day "), stdout)
+	}
+
+	now := currentTime()
+	tomorrow := now.AddDate(0, 0, 1)
+	if _, stderr, code := runCLI(t, "", "log", "--day", "B", "--exercise", "pullups", "--level", "half", "--reps", "8x2"); code != 0 {
+		t.Fatalf("cali log exited %d: %s", code, stderr)
+	}
+	if _, stderr, code := runCLI(t, "", "rest", "--date", tomorrow.Format(calio.DateLayout), "--reason", "travel"); code != 0 {
+		t.Fatalf("cali rest --date tomorrow exited %d: %s", code, stderr)
+	}
+	stdout, _, code = runCLI(t, "", "plan")
+	when := func(day time.Time) string {
+		return day.Weekday().String()[:3] + " " + displayDate(day.Format(calio.DateLayout))
+	}
+	if code != 0 || !strings.Contains(stdout, msg("plan.logged", when(now), "B")) || !strings.Contains(stdout, msg("plan.marked_rest", when(tomorrow), restMark+"travel")) ||
+		!strings.Contains(stdout, msg("plan.basis", 3, strings.Join(shortNames(preferredWeekdays([]time.Time{now}, 3)), "/"), planHistoryWeeks)) {
+		t.Errorf("cali plan exited %d:\n%s", code, stdout)
+	}
+	if !strings.Contains(stdout, "Day C") {
+		t.Errorf("cali plan doesn't follow B with C:\n%s", stdout)
+	}
+
+	t.Setenv("CALI_SESSIONS_PER_WEEK", "9")
+	if _, _, code := runCLI(t, "", "plan"); code != exitUsage {
+		t.Errorf("CALI_SESSIONS_PER_WEEK=9 exited %d", code)
+	}
+	t.Setenv("CALI_SESSIONS_PER_WEEK", "")
+	if _, _, code := runCLI(t, "", "plan", "tomorrow"); code != exitUsage {
+		t.Errorf("cali plan tomorrow exited %d", code)
+	}
+}
+
+// shortNames returns days as cali plan names them.
+func shortNames(days []time.Weekday) []string {
+	names := make([]string, len(days))
+	for i, day := range days {
+		names[i] = day.String()[:3]
+	}
+	return names
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// pluginPrefix starts the name of an external subcommand: cali foo runs the
+// executable cali-foo from PATH, as git runs git-foo.
+const pluginPrefix = "cali-"
+
+// extractNoPlugins removes the global --no-plugins flag from args.
+func extractNoPlugins(args []string) ([]string, bool) {
+	var rest []string
+	noPlugins := false
+	for _, arg := range args {
+		if arg == "--no-plugins" {
+			noPlugins = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, noPlugins
+}
+
+// Global flags of cali that may come before a command name: those taking a
+// value, as the next argument or after '=', and the switches.
+var (
+	globalValueFlags = []string{"--since", "--until", "--user", "--sheet", "--width"}
+	globalSwitches   = []string{"--quiet", "-q", "--verbose", "--fail-empty", "--force-unrecognized", "--allow-future", "--all-users"}
+)
+
+// pluginCommand returns the command name in args, the first argument that
+// is neither a global flag nor a global flag's value, and the arguments
+// around it, which the plugin gets as given. ok is false when there is no
+// such argument.
+func pluginCommand(args []string) (name string, rest []string, ok bool) {
+	for i := 0; i < len(args); i++ {
+		flag, _, hasValue := strings.Cut(args[i], "=")
+		switch {
+		case slices.Contains(globalValueFlags, flag):
+			if !hasValue {
+				i++
+			}
+		case slices.Contains(globalSwitches, args[i]):
+		default:
+			return args[i], append(slices.Clone(args[:i]), args[i+1:]...), true
+		}
+	}
+	return "", nil, false
+}
+
+// pluginName reports whether name can name a plugin: letters, digits, '-'
+// and '_', starting with a letter or digit. Paths, flags and the names of
+// built-in commands never do, so a plugin can't replace a command.
+func pluginName(name string) bool {
+	if name == "" || (!unicode.IsLetter(rune(name[0])) && !unicode.IsDigit(rune(name[0]))) {
+		return false
+	}
+	for _, r := range name {
+		if r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_') {
+			return false
+		}
+	}
+	_, builtin := findCommand(name)
+	return !builtin
+}
+
+// findPlugin returns the path of the plugin for the command name, looked up
+// on PATH with lookPath (exec.LookPath). Only an executable named exactly
+// cali-<name> is found, and one found through a relative PATH entry, such
+// as ".", is refused with a warning rather than run.
+func findPlugin(name string, lookPath func(string) (string, error)) (string, bool) {
+	if !pluginName(name) {
+		return "", false
+	}
+	path, err := lookPath(pluginPrefix + name)
+	if errors.Is(err, exec.ErrDot) {
+		fmt.Fprintf(os.Stderr, "Warning: not running %s: it was found through a relative PATH entry\n", path)
+		return "", false
+	}
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// pluginSettings returns the configuration cali resolved, for a plugin to
+// read the same log: the backend, and the spreadsheet or the log directory
+// it uses, with settings from the config file and the keyring filled in.
+// CALI_BIN is cali itself, for plugins that run cali commands.
+func pluginSettings(getenv func(string) string, store secretStore) [][2]string {
+	backend := strings.TrimSpace(getenv("CALI_STORAGE"))
+	if backend == "" {
+		backend = defaultBackend
+	}
+	settings := [][2]string{{"CALI_RESOLVED_STORAGE", backend}}
+	switch backend {
+	case defaultBackend:
+		cfg := loadSheetsConfig(getenv, store)
+		settings = append(settings,
+			[2]string{"CALI_SHEET_ID", cfg.SpreadsheetID.Value},
+			[2]string{"CALI_SHEET_NAME", cfg.SheetName.Value},
+			[2]string{"CALI_GOOGLE_CREDENTIALS_JSON", cfg.Credentials.Value})
+	case "local":
+		if dir, err := localLogDir(); err == nil {
+			settings = append(settings, [2]string{"CALI_LOCAL_DIR", dir})
+		}
+	}
+	if configFile != "" {
+		settings = append(settings, [2]string{"CALI_CONFIG_FILE", configFile})
+	}
+	if self, err := os.Executable(); err == nil {
+		settings = append(settings, [2]string{"CALI_BIN", self})
+	}
+	return settings
+}
+
+// pluginEnv returns env with settings set in it, replacing the variables of
+// the same name. Empty settings are left out, rather than cleared.
+func pluginEnv(env []string, settings [][2]string) []string {
+	var set [][2]string
+	for _, setting := range settings {
+		if setting[1] != "" {
+			set = append(set, setting)
+		}
+	}
+	result := slices.DeleteFunc(slices.Clone(env), func(variable string) bool {
+		name, _, _ := strings.Cut(variable, "=")
+		return slices.ContainsFunc(set, func(setting [2]string) bool { return setting[0] == name })
+	})
+	for _, setting := range set {
+		result = append(result, setting[0]+"="+setting[1])
+	}
+	return result
+}
+
+// runPlugin runs the plugin at path with args, on cali's terminal and with
+// the settings of pluginSettings, and exits as it does. Ctrl-C is left to
+// the plugin, which gets it from the terminal as cali does.
+func runPlugin(path string, args []string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = pluginEnv(os.Environ(), pluginSettings(os.Getenv, keyringStore))
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		// The plugin has said what went wrong.
+		return &cliError{code: exitErr.ExitCode()}
+	}
+	if err != nil {
+		return fmt.Errorf("running %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPluginCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		name string
+		rest []string
+		ok   bool
+	}{
+		{[]string{"dips"}, "dips", []string{}, true},
+		{[]string{"dips", "--since", "1w", "x"}, "dips", []string{"--since", "1w", "x"}, true},
+		{[]string{"--quiet", "dips"}, "dips", []string{"--quiet"}, true},
+		{[]string{"-q", "--verbose", "dips", "-q"}, "dips", []string{"-q", "--verbose", "-q"}, true},
+		{[]string{"--since", "2w", "dips", "a"}, "dips", []string{"--since", "2w", "a"}, true},
+		{[]string{"--since=2w", "--until", "today", "dips"}, "dips", []string{"--since=2w", "--until", "today"}, true},
+		{[]string{"--user", "sam", "--all-users", "--sheet", "Log", "--width", "80", "--fail-empty", "--force-unrecognized", "dips"},
+			"dips", []string{"--user", "sam", "--all-users", "--sheet", "Log", "--width", "80", "--fail-empty", "--force-unrecognized"}, true},
+		{[]string{"--user=sam", "dips"}, "dips", []string{"--user=sam"}, true},
+		// A flag of cali's own commands comes first; it names no plugin.
+		{[]string{"-p", "dips"}, "-p", []string{"dips"}, true},
+		{[]string{"--since"}, "", nil, false},
+		{[]string{"--quiet"}, "", nil, false},
+		{nil, "", nil, false},
+	}
+	for _, tt := range tests {
+		name, rest, ok := pluginCommand(tt.args)
+		if name != tt.name || ok != tt.ok || !slices.Equal(rest, tt.rest) {
+			t.Errorf("pluginCommand(%q) = %q, %q, %v; want %q, %q, %v", tt.args, name, rest, ok, tt.name, tt.rest, tt.ok)
+		}
+	}
+}
+
+func TestFindPlugin(t *testing.T) {
+	var looked []string
+	path := map[string]string{"cali-dips": "/usr/local/bin/cali-dips", "cali-history": "/usr/local/bin/cali-history"}
+	lookPath := func(file string) (string, error) {
+		looked = append(looked, file)
+		if file == "cali-here" {
+			return "./cali-here", exec.ErrDot
+		}
+		if found, ok := path[file]; ok {
+			return found, nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	if got, ok := findPlugin("dips", lookPath); !ok || got != "/usr/local/bin/cali-dips" {
+		t.Errorf("dips = %q, %v", got, ok)
+	}
+	if _, ok := findPlugin("rows", lookPath); ok {
+		t.Error("found a plugin missing from PATH")
+	}
+	stderr := captureOutput(t, &os.Stderr, func() {
+		if _, ok := findPlugin("here", lookPath); ok {
+			t.Error("ran a plugin found through a relative PATH entry")
+		}
+	})
+	if !strings.Contains(stderr, "relative PATH entry") {
+		t.Errorf("no warning for a relative PATH entry: %q", stderr)
+	}
+
+	looked = nil
+	for _, name := range []string{"history", "q", "", "-p", "--quiet", "../dips", "dips/x", "dïps", "_dips"} {
+		if _, ok := findPlugin(name, lookPath); ok {
+			t.Errorf("%q found a plugin", name)
+		}
+	}
+	if len(looked) > 0 {
+		t.Errorf("looked up %q for names that can't be plugins", looked)
+	}
+}
+
+func TestPluginEnv(t *testing.T) {
+	env := []string{"PATH=/usr/bin", "CALI_SHEET_ID=from-the-shell", "CALI_SHEET_NAME=Shell tab", "HOME=/home/ziad"}
+	got := pluginEnv(env, [][2]string{
+		{"CALI_RESOLVED_STORAGE", "sheets"},
+		{"CALI_SHEET_ID", "from-the-keyring"},
+		{"CALI_SHEET_NAME", ""},
+	})
+	want := []string{"PATH=/usr/bin", "CALI_SHEET_NAME=Shell tab", "HOME=/home/ziad", "CALI_RESOLVED_STORAGE=sheets", "CALI_SHEET_ID=from-the-keyring"}
+	if !slices.Equal(got, want) {
+		t.Errorf("pluginEnv = %q, want %q", got, want)
+	}
+	if env[1] != "CALI_SHEET_ID=from-the-shell" {
+		t.Errorf("pluginEnv changed its argument: %q", env)
+	}
+}
+
+func TestPluginSettings(t *testing.T) {
+	keyring := &fakeKeyring{values: map[string]string{keySheetID: testSheetID, keyCredentials: "/keyring/key.json"}}
+	settings := pluginSettings(envOf(map[string]string{"CALI_SHEET_NAME": "Training"}), keyring)
+	got := map[string]string{}
+	for _, setting := range settings {
+		got[setting[0]] = setting[1]
+	}
+	for name, want := range map[string]string{
+		"CALI_RESOLVED_STORAGE":        "sheets",
+		"CALI_SHEET_ID":                testSheetID,
+		"CALI_SHEET_NAME":              "Training",
+		"CALI_GOOGLE_CREDENTIALS_JSON": "/keyring/key.json",
+	} {
+		if got[name] != want {
+			t.Errorf("%s = %q, want %q", name, got[name], want)
+		}
+	}
+	if got["CALI_BIN"] == "" {
+		t.Error("CALI_BIN is missing")
+	}
+}
+
+// TestRunPlugin runs a plugin script through cali, after global flags, and
+// checks what it was given and that cali exits as it did.
+func TestRunPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugin is a shell script")
+	}
+	bin := t.TempDir()
+	out := filepath.Join(bin, "out")
+	script := "#!/bin/sh\n" +
+		"printf '%s\\n' \"$@\" > " + out + "\n" +
+		"echo \"storage=$CALI_RESOLVED_STORAGE dir=$CALI_LOCAL_DIR\" >> " + out + "\n" +
+		"exit 7\n"
+	if err := os.WriteFile(filepath.Join(bin, "cali-dips"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	logDir := filepath.Join(t.TempDir(), "log")
+	t.Setenv("CALI_LOG_DIR", logDir)
+
+	if _, stderr, code := runCLI(t, "", "--quiet", "--since", "2w", "dips", "--week", "3"); code != 7 {
+		t.Fatalf("cali exited %d, want the plugin's 7: %s", code, stderr)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--quiet\n--since\n2w\n--week\n3\nstorage=local dir=" + logDir + "\n"
+	if string(data) != want {
+		t.Errorf("the plugin got\n%s\nwant\n%s", data, want)
+	}
+
+	os.Remove(out)
+	if _, _, code := runCLI(t, "", "--no-plugins", "dips"); code != exitUsage {
+		t.Errorf("--no-plugins dips exited %d, want %d", code, exitUsage)
+	}
+	if _, err := os.Stat(out); !errors.Is(err, os.ErrNotExist) {
+		t.Error("--no-plugins ran the plugin")
+	}
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	// defaultPrivateMarker marks a comment as private unless
+	// CALI_PRIVATE_MARKER names another marker.
+	defaultPrivateMarker = "[private]"
+	// privateTag marks a comment as private like the marker, as a tag such
+	// as #deload.
+	privateTag = "#private"
+	// redactedComment replaces private comments in shared output.
+	redactedComment = "[redacted]"
+)
+
+// privateMarker returns CALI_PRIVATE_MARKER, or the default marker.
+func privateMarker() string {
+	if marker := strings.TrimSpace(os.Getenv("CALI_PRIVATE_MARKER")); marker != "" {
+		return marker
+	}
+	return defaultPrivateMarker
+}
+
+// isPrivate reports whether comment holds the private marker (in any case)
+// or the #private tag.
+func isPrivate(comment string) bool {
+	lower := strings.ToLower(comment)
+	if strings.Contains(lower, strings.ToLower(privateMarker())) {
+		return true
+	}
+	for _, word := range strings.Fields(lower) {
+		if word == privateTag {
+			return true
+		}
+	}
+	return false
+}
+
+// shareable prepares entry for output meant to leave this machine: export,
+// report and share export-static pass every entry through it (or through
+// shareableEntries) before rendering, so none of them shows a private
+// comment unless includePrivate (--include-private) is set. Local listings
+// such as history and search don't, and show comments in full.
+func shareable(entry WorkoutEntry, includePrivate bool) WorkoutEntry {
+	if includePrivate || !isPrivate(entry.Comment) {
+		return entry
+	}
+	// Stats still tell warm-ups and deload sessions apart by their tags.
+	comment := redactedComment
+	if isWarmup(entry) {
+		comment += " " + warmupTag
+	}
+	if isDeload(entry) {
+		comment += " " + deloadTag
+	}
+	entry.Comment = comment
+	return entry
+}
+
+// shareableEntries is shareable for a slice, returning a copy.
+func shareableEntries(entries []WorkoutEntry, includePrivate bool) []WorkoutEntry {
+	shared := make([]WorkoutEntry, len(entries))
+	for i, entry := range entries {
+		shared[i] = shareable(entry, includePrivate)
+	}
+	return shared
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestIsPrivate(t *testing.T) {
+	for comment, want := range map[string]bool{
+		"knee meds [private]":   true,
+		"knee meds [PRIVATE]":   true,
+		"[private]":             true,
+		"knee meds #private":    true,
+		"#Private knee meds":    true,
+		"knee meds #privateer":  false,
+		"knee meds private":     false,
+		"knee meds (secret)":    false,
+		"":                      false,
+		"felt strong #deload":   false,
+		"[private] #warmup set": true,
+	} {
+		if got := isPrivate(comment); got != want {
+			t.Errorf("isPrivate(%q) = %v", comment, got)
+		}
+	}
+	t.Setenv("CALI_PRIVATE_MARKER", " (secret) ")
+	if !isPrivate("knee meds (SECRET)") || isPrivate("knee meds [private]") || !isPrivate("knee meds #private") {
+		t.Errorf("CALI_PRIVATE_MARKER=(secret) isn't the marker")
+	}
+}
+
+func TestShareable(t *testing.T) {
+	entry := WorkoutEntry{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "12x2"}
+	for comment, want := range map[string]string{
+		"felt strong":                  "felt strong",
+		"knee meds [private]":          "[redacted]",
+		"knee meds #private #deload":   "[redacted] #deload",
+		"#warmup knee meds #private":   "[redacted] #warmup",
+		"#deload #warmup [private] ok": "[redacted] #warmup #deload",
+	} {
+		entry.Comment = comment
+		if got := shareable(entry, false); got.Comment != want || got.RepsSets != "12x2" {
+			t.Errorf("shareable(%q) = %q", comment, got.Comment)
+		}
+		if got := shareable(entry, true); got != entry {
+			t.Errorf("shareable(%q) with --include-private = %q", comment, got.Comment)
+		}
+	}
+	entries := []WorkoutEntry{entry, entry}
+	entries[0].Comment = "knee meds #private"
+	if shared := shareableEntries(entries, false); shared[0].Comment != redactedComment || entries[0].Comment != "knee meds #private" {
+		t.Errorf("shareableEntries = %q and changed its argument to %q", shared[0].Comment, entries[0].Comment)
+	}
+}
+
+// TestPrivateOutputs runs every output meant to leave the machine with and
+// without --include-private over a log with private comments, and the
+// local listings, which always show them. The outputs that print no
+// comments are checked to count the same either way.
+func TestPrivateOutputs(t *testing.T) {
+	storage := pipedLog(t)
+	date := currentTime().AddDate(0, 0, -1).Format(calio.DateLayout)
+	for _, entry := range []WorkoutEntry{
+		{Exercise: "Pushups", Level: "Half", RepsSets: "10x1", Comment: "#warmup knee meds [private]"},
+		{Exercise: "Pushups", Level: "Full", RepsSets: "12x2", Comment: "knee meds #private"},
+		{Exercise: "Squats", Level: "Full", RepsSets: "20x2", Comment: "therapy notes [private] #deload"},
+		{Exercise: "Pullups", Level: "Full", RepsSets: "8x2", Comment: "felt strong"},
+	} {
+		entry.Date, entry.Day = date, "A"
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secrets := []string{"knee meds", "therapy notes"}
+	out := filepath.Join(t.TempDir(), "log.html")
+
+	for _, tc := range []struct {
+		args     []string
+		comments bool // the output shows comments
+	}{
+		{[]string{"export", "--format", "csv"}, true},
+		{[]string{"export", "--format", "tsv", "--header"}, true},
+		{[]string{"export", "--format", "gfit-json"}, false},
+		{[]string{"report", "--since", "1w"}, false},
+		{[]string{"report", "--markdown", "--since", "1w"}, false},
+		{[]string{"share", "export-static", "--out", out}, true},
+	} {
+		name := strings.Join(tc.args, " ")
+		output := func(args ...string) string {
+			t.Helper()
+			stdout, stderr, code := runCLI(t, "", args...)
+			if code != 0 {
+				t.Fatalf("cali %s exited %d: %s", strings.Join(args, " "), code, stderr)
+			}
+			if tc.args[0] == "share" {
+				return readFile(t, out)
+			}
+			return stdout
+		}
+		redacted := output(tc.args...)
+		full := output(append(tc.args, "--include-private")...)
+		for _, secret := range secrets {
+			if strings.Contains(redacted, secret) {
+				t.Errorf("cali %s shows the private %q", name, secret)
+			}
+			if tc.comments && !strings.Contains(full, secret) {
+				t.Errorf("cali %s --include-private hides %q", name, secret)
+			}
+		}
+		if tc.comments && (!strings.Contains(redacted, redactedComment) || !strings.Contains(redacted, "felt strong")) {
+			t.Errorf("cali %s doesn't show [redacted] next to the public comment:\n%s", name, redacted)
+		}
+		if !tc.comments && redacted != full {
+			t.Errorf("cali %s counts differently with --include-private:\n%s\nand\n%s", name, redacted, full)
+		}
+	}
+
+	for _, args := range [][]string{{"history", "--include", "warmups"}, {"-s", date}} {
+		stdout, _, _ := runCLI(t, "", args...)
+		for _, secret := range secrets {
+			if !strings.Contains(stdout, secret) {
+				t.Errorf("cali %s hides %q", strings.Join(args, " "), secret)
+			}
+		}
+	}
+}
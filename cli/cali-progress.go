@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// progress reports slow operations. Implementations write to stderr only, so
+// stdout stays clean for listings and JSON.
+type progress interface {
+	Start(message string)
+	Step(done, total int)
+	Finish()
+}
+
+// progressOff turns spinners off for modes whose storage calls overlap,
+// such as cali serve.
+var progressOff bool
+
+// newProgress returns a spinner when stderr is a terminal, --quiet is not
+// set and progressOff isn't either, and a no-op otherwise.
+func newProgress() progress {
+	if progressOff || outputLevel == levelQuiet || !isTerminal(os.Stderr) {
+		return noProgress{}
+	}
+	return &spinner{w: os.Stderr}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+type noProgress struct{}
+
+func (noProgress) Start(string)  {}
+func (noProgress) Step(int, int) {}
+func (noProgress) Finish()       {}
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinner animates a status line until Finish erases it. Start while running
+// only replaces the message.
+type spinner struct {
+	w io.Writer
+
+	mu      sync.Mutex
+	message string
+	counter string
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func (s *spinner) Start(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.message = message
+	s.counter = ""
+	if s.done != nil {
+		return
+	}
+	s.done = make(chan struct{})
+	s.stopped = make(chan struct{})
+	go s.run(s.done, s.stopped)
+}
+
+func (s *spinner) Step(done, total int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counter = fmt.Sprintf(" %d/%d", done, total)
+}
+
+func (s *spinner) Finish() {
+	s.mu.Lock()
+	done, stopped := s.done, s.stopped
+	s.done, s.stopped = nil, nil
+	s.mu.Unlock()
+	if done == nil {
+		return
+	}
+	close(done)
+	<-stopped
+}
+
+func (s *spinner) run(done, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		s.mu.Lock()
+		fmt.Fprintf(s.w, "\r\033[K%s %s%s", spinnerFrames[frame%len(spinnerFrames)], s.message, s.counter)
+		s.mu.Unlock()
+		select {
+		case <-done:
+			fmt.Fprint(s.w, "\r\033[K")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpinner(t *testing.T) {
+	var out strings.Builder
+	s := &spinner{w: &out}
+	s.Start("Reading the sheet")
+	done := s.done
+	s.Step(2, 5)
+	s.Start("Writing the sheet") // only replaces the message
+	if s.done != done || s.message != "Writing the sheet" || s.counter != "" {
+		t.Errorf("after a second Start: restarted %v, message %q, counter %q", s.done != done, s.message, s.counter)
+	}
+	s.Finish()
+	s.Finish() // a second Finish does nothing
+
+	// The first frame is drawn before Finish can stop the spinner.
+	text := out.String()
+	if !strings.HasPrefix(text, "\r\033[K"+spinnerFrames[0]+" ") {
+		t.Errorf("the spinner started with %q", text)
+	}
+	if !strings.HasSuffix(text, "\r\033[K") {
+		t.Errorf("Finish left the line as %q", text)
+	}
+
+	// The spinner starts again after Finish.
+	out.Reset()
+	s.Start("Again")
+	s.Finish()
+	if !strings.Contains(out.String(), "Again") {
+		t.Errorf("a restarted spinner wrote %q", out.String())
+	}
+}
+
+// TestNewProgressOff checks a pipe, --quiet and progressOff all get no
+// spinner.
+func TestNewProgressOff(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	saved := os.Stderr
+	os.Stderr = w
+	t.Cleanup(func() { os.Stderr = saved })
+
+	if _, ok := newProgress().(noProgress); !ok {
+		t.Error("stderr is a pipe and newProgress returned a spinner")
+	}
+	quiet(t)
+	if _, ok := newProgress().(noProgress); !ok {
+		t.Error("--quiet and newProgress returned a spinner")
+	}
+}
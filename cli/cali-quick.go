@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// quickEntry is what cali q read from its one-line form.
+type quickEntry struct {
+	Day      string // "" when not given
+	Exercise string
+	Level    string
+	RepsSets string
+	Comment  string
+}
+
+// exerciseAliases are short names cali q accepts besides the exercise names,
+// their singulars and spellings without spaces or hyphens.
+var exerciseAliases = map[string]string{
+	"hspu":      "Handstand Push-ups",
+	"handstand": "Handstand Push-ups",
+	"lr":        "Leg Raises",
+}
+
+// quickKey folds a name for matching: "Push-ups", "push ups" and "pushups"
+// are the same.
+func quickKey(s string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(s))
+}
+
+// matchExercise resolves a name, alias or singular to an exercise.
+func matchExercise(input string) (string, bool) {
+	key := quickKey(input)
+	if exercise, ok := exerciseAliases[key]; ok {
+		return exercise, true
+	}
+	for _, exercise := range append(calio.Exercises(), calio.MobilityExercises()...) {
+		if name := quickKey(exercise); name == key || name == key+"s" {
+			return exercise, true
+		}
+	}
+	return "", false
+}
+
+// matchLevel resolves a level of exercise by name, ignoring case, spaces and
+// hyphens, or by step number ("3", "step 3").
+func matchLevel(exercise, input string) (string, bool) {
+	for _, level := range calio.Levels(exercise) {
+		if quickKey(level) == quickKey(input) {
+			return level, true
+		}
+	}
+	return normalizeLevel(exercise, input)
+}
+
+// quickReps reads the work done at a level: a hold for levels with a timed
+// goal, reps otherwise. Intervals need cali --interval.
+func quickReps(exercise, level, input string) (string, error) {
+	if hasTimedGoal(exercise, level) {
+		parsed, err := parseHoldInput(input)
+		if err != nil {
+			return "", err
+		}
+		return formatRepsSets(parsed), nil
+	}
+	parsed, ok := parseRepsSets(input)
+	if !ok || parsed.interval() {
+		return "", errors.New(msg("quick.bad_reps", input))
+	}
+	return normalizeRepsSets(input), nil
+}
+
+// joinedBySets reports whether words form one reps value, split only around
+// its "x" as in "8 x 2" or "2min x2". The reps parser ignores spaces, so "8x2
+// 3" would otherwise read as 8x23.
+func joinedBySets(words []string) bool {
+	isX := func(r rune) bool { return r == 'x' || r == 'X' || r == '×' }
+	for i := 1; i < len(words); i++ {
+		before, after := []rune(words[i-1]), []rune(words[i])
+		if !isX(before[len(before)-1]) && !isX(after[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Longest spans the parser tries, in words: "Assisted One-Leg" is two,
+// "2min x 2" three.
+const (
+	maxExerciseWords = 3
+	maxLevelWords    = 4
+	maxRepsWords     = 3
+)
+
+// parseQuickLog reads "[day] <exercise> <level> <reps> [comment]", e.g.
+// "B pullups full 8x2 felt strong". The level is the longest run of words
+// naming one of the exercise's levels that is followed by valid reps, and the
+// reps the longest run that parses, so "half one-arm 5x2" is the Half
+// One-Arm level. Everything after the reps is the comment; a "--" word ends
+// the entry early, so anything after it is comment whatever it looks like.
+// Errors name the word that couldn't be read.
+func parseQuickLog(text string) (quickEntry, error) {
+	words, comment, _ := strings.Cut(" "+strings.Join(strings.Fields(text), " ")+" ", " -- ")
+	tokens := strings.Fields(words)
+	comment = strings.TrimSpace(comment)
+	if len(tokens) == 0 {
+		return quickEntry{}, errors.New(msg("quick.empty"))
+	}
+	span := func(from, n int) string {
+		return strings.Join(tokens[from:from+n], " ")
+	}
+
+	var entry quickEntry
+	i := 0
+	if len(tokens) > 1 && slices.Contains(calio.DayLetters(), strings.ToUpper(tokens[0])) {
+		entry.Day = strings.ToUpper(tokens[0])
+		i++
+	}
+
+	for n := min(maxExerciseWords, len(tokens)-i); n >= 1; n-- {
+		if exercise, ok := matchExercise(span(i, n)); ok {
+			entry.Exercise = exercise
+			i += n
+			break
+		}
+	}
+	if entry.Exercise == "" {
+		return quickEntry{}, errors.New(msg("quick.bad_exercise", tokens[i], i+1))
+	}
+	if slices.Contains(calio.MobilityExercises(), entry.Exercise) {
+		entry.Day = ""
+	}
+	if i == len(tokens) {
+		return quickEntry{}, errors.New(msg("quick.no_level", entry.Exercise))
+	}
+
+	// The first reps error after the longest level that matched explains a
+	// failure best.
+	var repsErr error
+	for n := min(maxLevelWords, len(tokens)-i); n >= 1; n-- {
+		level, ok := matchLevel(entry.Exercise, span(i, n))
+		if !ok {
+			continue
+		}
+		at := i + n
+		if at == len(tokens) {
+			if repsErr == nil {
+				repsErr = errors.New(msg("quick.no_reps", entry.Exercise, level))
+			}
+			continue
+		}
+		for r := min(maxRepsWords, len(tokens)-at); r >= 1; r-- {
+			if !joinedBySets(tokens[at : at+r]) {
+				continue
+			}
+			reps, err := quickReps(entry.Exercise, level, span(at, r))
+			if err != nil {
+				if r == 1 && repsErr == nil {
+					repsErr = fmt.Errorf("%s: %w", msg("quick.word", at+1), err)
+				}
+				continue
+			}
+			entry.Level, entry.RepsSets = level, reps
+			entry.Comment = strings.TrimSpace(span(at+r, len(tokens)-at-r) + " " + comment)
+			return entry, nil
+		}
+	}
+	if repsErr != nil {
+		return quickEntry{}, repsErr
+	}
+	return quickEntry{}, errors.New(msg("quick.bad_level", tokens[i], i+1, entry.Exercise))
+}
+
+// quickOptions are the flags of cali q.
+type quickOptions struct {
+	Yes    bool
+	Load   string
+	Extras extraList
+}
+
+// newQuickFlagSet declares the flags of cali q into opts.
+func newQuickFlagSet(opts *quickOptions) *flag.FlagSet {
+	fs := newFlagSet("q")
+	fs.BoolVar(&opts.Yes, "yes", false, "save without asking")
+	fs.StringVar(&opts.Load, "load", "", "weight added to the set, e.g. +10kg")
+	fs.Var(&opts.Extras, "extra", "answer an extra prompt of the exercise, e.g. knee=good (repeatable); cali q asks none")
+	return fs
+}
+
+func runQuickLog(ctx context.Context, args []string) error {
+	var opts quickOptions
+	fs := newQuickFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() == 0 {
+		return usageError(`usage: cali q [--yes] [--load <weight>] [--extra <key=value>]... "[day] <exercise> <level> <reps> [comment]", e.g. cali q "B pullups full 8x2 felt strong"`)
+	}
+
+	load, err := parseLoadInput(opts.Load)
+	if err != nil {
+		return usageError("%v", err)
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	loadGoalOverrides(ctx, storage)
+	parsed, err := parseQuickLog(strings.Join(fs.Args(), " "))
+	if err != nil {
+		return usageError("%s", err)
+	}
+
+	answers, err := checkExtras(promptsFor(extraPrompts, parsed.Exercise), parsed.Exercise, opts.Extras)
+	if err != nil {
+		return usageError("%v", err)
+	}
+	parsed.Comment = addExtras(parsed.Comment, answers)
+
+	date := currentTime().Format(calio.DateLayout)
+	category := calio.CategoryStrength
+	if slices.Contains(calio.MobilityExercises(), parsed.Exercise) {
+		category = calio.CategoryMobility
+	} else if parsed.Day == "" {
+		parsed.Day = quickDay(ctx, storage, date)
+	}
+	entry := WorkoutEntry{
+		Date:     date,
+		Day:      parsed.Day,
+		Exercise: parsed.Exercise,
+		Level:    parsed.Level,
+		RepsSets: parsed.RepsSets,
+		Goal:     resolveGoal(parsed.Exercise, parsed.Level),
+		Comment:  parsed.Comment,
+		Type:     calio.TypeStraightSets,
+		Category: category,
+		Load:     load,
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	release, err := guardSession(reader)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if entry.Comment, err = checkCommentLength(reader, entry.Comment, !opts.Yes); err != nil {
+		return err
+	}
+	if !opts.Yes {
+		prompt(msg("list.row", displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level,
+			workText(entry), entry.Comment))
+		prompt(msg("quick.confirm"))
+		answer, _ := reader.ReadString('\n')
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if answer != "" && !slices.Contains(strings.Split(msg("answer.yes"), ","), answer) {
+			promptln(msg("remove.cancelled"))
+			return errCancelled
+		}
+	}
+	return saveEntry(ctx, storage, entry)
+}
+
+// quickDay picks the day letter when cali q isn't given one: the day already
+// being trained today, else the suggested one (see suggestDay).
+func quickDay(ctx context.Context, storage Storage, today string) string {
+	if entries, err := storage.SearchByDate(ctx, today); err == nil {
+		strength, _ := splitByCategory(entries)
+		if day := inferDay(strength); day != "" {
+			return day
+		}
+	}
+	last, _, err := storage.LastTrainingDay(ctx)
+	if err != nil {
+		detail("Previous training day unavailable: %v\n", err)
+	}
+	return suggestDay(ctx, storage, last)
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseQuickLog(t *testing.T) {
+	tests := []struct {
+		text string
+		want quickEntry
+	}{
+		{"B pullups full 8x2 felt strong", quickEntry{Day: "B", Exercise: "Pullups", Level: "Full", RepsSets: "8x2", Comment: "felt strong"}},
+		{"pullups full 8x2", quickEntry{Exercise: "Pullups", Level: "Full", RepsSets: "8x2"}},
+		{"b Pullups FULL 8x2", quickEntry{Day: "B", Exercise: "Pullups", Level: "Full", RepsSets: "8x2"}},
+		{"  pullups   full\t8x2  ", quickEntry{Exercise: "Pullups", Level: "Full", RepsSets: "8x2"}},
+		{"pullup full 8x2", quickEntry{Exercise: "Pullups", Level: "Full", RepsSets: "8x2"}},
+		{"A push-ups full 20x2", quickEntry{Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2"}},
+		{"push ups full 20x2", quickEntry{Exercise: "Pushups", Level: "Full", RepsSets: "20x2"}},
+		{"leg raises flat 15x2", quickEntry{Exercise: "Leg Raises", Level: "Flat", RepsSets: "15x2"}},
+		{"lr knee tuck 20x3", quickEntry{Exercise: "Leg Raises", Level: "Knee Tuck", RepsSets: "20x3"}},
+		{"hspu wall 2min", quickEntry{Exercise: "Handstand Push-ups", Level: "Wall", RepsSets: "2min"}},
+		{"pullups 5 8x2", quickEntry{Exercise: "Pullups", Level: "Full", RepsSets: "8x2"}},
+		{"pullups step 5 8x2", quickEntry{Exercise: "Pullups", Level: "Full", RepsSets: "8x2"}},
+		{"pullups full 8 x 2", quickEntry{Exercise: "Pullups", Level: "Full", RepsSets: "8x2"}},
+		{"pullups full 8x 2 then rows", quickEntry{Exercise: "Pullups", Level: "Full", RepsSets: "8x2", Comment: "then rows"}},
+		{"pullups full 8,7,6", quickEntry{Exercise: "Pullups", Level: "Full", RepsSets: "8,7,6"}},
+		{"pullups full 12", quickEntry{Exercise: "Pullups", Level: "Full", RepsSets: "12x1"}},
+		{"pullups full 8x2 3 sets left", quickEntry{Exercise: "Pullups", Level: "Full", RepsSets: "8x2", Comment: "3 sets left"}},
+		{"squats half 35x2 -- 50x2 next", quickEntry{Exercise: "Squats", Level: "Half", RepsSets: "35x2", Comment: "50x2 next"}},
+		{"squats half 35x2 knee ok -- 50x2 next", quickEntry{Exercise: "Squats", Level: "Half", RepsSets: "35x2", Comment: "knee ok 50x2 next"}},
+
+		// The longest level followed by valid reps wins.
+		{"pullups half one-arm 5x2 half done", quickEntry{Exercise: "Pullups", Level: "Half One-Arm", RepsSets: "5x2", Comment: "half done"}},
+		{"pullups half 5x2 one-arm next", quickEntry{Exercise: "Pullups", Level: "Half", RepsSets: "5x2", Comment: "one-arm next"}},
+		{"pullups assisted one arm 3x2", quickEntry{Exercise: "Pullups", Level: "Assisted One-Arm", RepsSets: "3x2"}},
+		{"squats assisted one-leg 10x2", quickEntry{Exercise: "Squats", Level: "Assisted One-Leg", RepsSets: "10x2"}},
+		{"squats one-leg 10x2", quickEntry{Exercise: "Squats", Level: "One-Leg", RepsSets: "10x2"}},
+		{"leg raises hanging bent 10x2", quickEntry{Exercise: "Leg Raises", Level: "Hanging Bent", RepsSets: "10x2"}},
+		{"leg raises hanging 10x2", quickEntry{Exercise: "Leg Raises", Level: "Hanging", RepsSets: "10x2"}},
+		{"bridges wall down 6x2", quickEntry{Exercise: "Bridges", Level: "Wall Down", RepsSets: "6x2"}},
+		{"hspu wall headstand 1min", quickEntry{Exercise: "Handstand Push-ups", Level: "Wall Headstand", RepsSets: "1min"}},
+
+		// Mobility holds take no day.
+		{"A l-sit tuck 30s", quickEntry{Exercise: "L-Sit", Level: "Tuck", RepsSets: "30s"}},
+		{"twist bent leg 45s", quickEntry{Exercise: "Twist", Level: "Bent Leg", RepsSets: "45s"}},
+		{"bridge hold full 1min x 2", quickEntry{Exercise: "Bridge Hold", Level: "Full", RepsSets: "1min x2"}},
+	}
+	for _, tt := range tests {
+		got, err := parseQuickLog(tt.text)
+		if err != nil || got != tt.want {
+			t.Errorf("parseQuickLog(%q) = %+v, %v; want %+v", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseQuickLogErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // part of the error
+	}{
+		{"", "nothing to log"},
+		{"   ", "nothing to log"},
+		{"-- just a comment", "nothing to log"},
+		{"B", `"B" (word 1) is not an exercise`},
+		{"B rows full 8x2", `"rows" (word 2) is not an exercise`},
+		{"dips full 8x2", `"dips" (word 1) is not an exercise`},
+		{"pullups", "missing the Pullups level"},
+		{"B pullups -- full 8x2", "missing the Pullups level"},
+		{"pullups fulll 8x2", `"fulll" (word 2) is not a Pullups level`},
+		{"pullups step 11 8x2", `"step" (word 2) is not a Pullups level`},
+		{"pullups one-leg 8x2", `"one-leg" (word 2) is not a Pullups level`},
+		{"pullups full", "missing reps after Pullups Full"},
+		{"pullups half one-arm", "missing reps after Pullups Half One-Arm"},
+		{"pullups full lots", `word 3: "lots" is not reps`},
+		{"B pullups full eight twice", `word 4: "eight" is not reps`},
+		{"pullups half one-arm many", `word 4: "many" is not reps`},
+		{"pullups full 8x2x", `word 3: "8x2x" is not reps`},
+		{"hspu wall forever", "word 3"},
+	}
+	for _, tt := range tests {
+		_, err := parseQuickLog(tt.text)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseQuickLog(%q) = %v, want an error with %q", tt.text, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// dateRange is an inclusive range of canonical dates (YYYY-MM-DD). Empty
+// bounds are open.
+type dateRange struct {
+	Since string `json:"since"`
+	Until string `json:"until"`
+}
+
+func (r dateRange) isSet() bool {
+	return r.Since != "" || r.Until != ""
+}
+
+func (r dateRange) contains(date string) bool {
+	if r.Since != "" && date < r.Since {
+		return false
+	}
+	if r.Until != "" && date > r.Until {
+		return false
+	}
+	return true
+}
+
+// relativeDuration is a calendar offset such as "7d", "3w" or "2m".
+type relativeDuration struct {
+	Days   int
+	Months int
+	Years  int
+}
+
+var relativeUnits = map[string]func(n int) relativeDuration{
+	"d":      func(n int) relativeDuration { return relativeDuration{Days: n} },
+	"day":    func(n int) relativeDuration { return relativeDuration{Days: n} },
+	"days":   func(n int) relativeDuration { return relativeDuration{Days: n} },
+	"w":      func(n int) relativeDuration { return relativeDuration{Days: 7 * n} },
+	"week":   func(n int) relativeDuration { return relativeDuration{Days: 7 * n} },
+	"weeks":  func(n int) relativeDuration { return relativeDuration{Days: 7 * n} },
+	"m":      func(n int) relativeDuration { return relativeDuration{Months: n} },
+	"month":  func(n int) relativeDuration { return relativeDuration{Months: n} },
+	"months": func(n int) relativeDuration { return relativeDuration{Months: n} },
+	"y":      func(n int) relativeDuration { return relativeDuration{Years: n} },
+	"year":   func(n int) relativeDuration { return relativeDuration{Years: n} },
+	"years":  func(n int) relativeDuration { return relativeDuration{Years: n} },
+}
+
+// parseRelativeDuration parses "<count><unit>" with optional whitespace
+// between count and unit ("7d", "10 days", "2w", "3 months", "1y").
+func parseRelativeDuration(input string) (relativeDuration, error) {
+	value := strings.ToLower(strings.TrimSpace(input))
+	split := strings.IndexFunc(value, func(r rune) bool { return !unicode.IsDigit(r) })
+	if split <= 0 {
+		return relativeDuration{}, fmt.Errorf("invalid relative duration %q (examples: 7d, 3w, 2m, 10 days)", input)
+	}
+
+	count, err := strconv.Atoi(value[:split])
+	if err != nil {
+		return relativeDuration{}, fmt.Errorf("invalid relative duration %q", input)
+	}
+
+	unit := strings.TrimSpace(value[split:])
+	build, ok := relativeUnits[unit]
+	if !ok {
+		return relativeDuration{}, fmt.Errorf("unknown unit %q in %q (use d, w, m or y)", unit, input)
+	}
+	return build(count), nil
+}
+
+// rangeCommands are the commands --since and --until apply to. The others
+// reject them rather than show everything.
+var rangeCommands = []string{
+	"history", "-p", "--print", "--history", "search", "-s", "--search", "grep",
+	"stats", "--stats", "metrics", "rest", "share", "progress", "graph", "report", "export",
+}
+
+// acceptsRange reports whether the command in args is one of rangeCommands.
+func acceptsRange(args []string) bool {
+	return len(args) > 0 && slices.Contains(rangeCommands, args[0])
+}
+
+// before returns the date the duration reaches when counted back from t.
+func (d relativeDuration) before(t time.Time) time.Time {
+	return t.AddDate(-d.Years, -d.Months, -d.Days)
+}
+
+// parseRangeBound accepts an absolute YYYY-MM-DD date or a relative duration
+// counted back from today.
+func parseRangeBound(input string, today time.Time) (string, error) {
+	value := strings.TrimSpace(input)
+	if date, err := time.Parse(calio.DateLayout, value); err == nil {
+		return date.Format(calio.DateLayout), nil
+	}
+
+	offset, err := parseRelativeDuration(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q: use YYYY-MM-DD or a relative form like 7d, 3w, 2m", input)
+	}
+	return offset.before(today).Format(calio.DateLayout), nil
+}
+
+// extractRangeFlags removes the global --since/--until flags from args and
+// resolves them against today in the configured timezone.
+func extractRangeFlags(args []string, today time.Time) (dateRange, []string, error) {
+	var rng dateRange
+	var sinceRaw, untilRaw string
+	var rest []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--since" && name != "--until" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return dateRange{}, nil, fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		target := &sinceRaw
+		if name == "--until" {
+			target = &untilRaw
+		}
+		if *target != "" && *target != value {
+			return dateRange{}, nil, fmt.Errorf("%s given more than once (%q and %q)", name, *target, value)
+		}
+		*target = value
+	}
+
+	var err error
+	if sinceRaw != "" {
+		if rng.Since, err = parseRangeBound(sinceRaw, today); err != nil {
+			return dateRange{}, nil, fmt.Errorf("--since: %w", err)
+		}
+	}
+	if untilRaw != "" {
+		if rng.Until, err = parseRangeBound(untilRaw, today); err != nil {
+			return dateRange{}, nil, fmt.Errorf("--until: %w", err)
+		}
+	}
+	if rng.Since != "" && rng.Until != "" && rng.Since > rng.Until {
+		return dateRange{}, nil, fmt.Errorf("--since %s is after --until %s", rng.Since, rng.Until)
+	}
+	return rng, rest, nil
+}
+
+func filterByRange(entries []WorkoutEntry, rng dateRange) []WorkoutEntry {
+	if !rng.isSet() {
+		return entries
+	}
+	var filtered []WorkoutEntry
+	for _, entry := range entries {
+		if rng.contains(entry.Date) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// configuredLocation returns the timezone named by CALI_TZ, falling back to
+// the system local zone.
+func configuredLocation() *time.Location {
+	name := strings.TrimSpace(os.Getenv("CALI_TZ"))
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unknown CALI_TZ %q, using local time\n", name)
+		return time.Local
+	}
+	return loc
+}
+
+func currentTime() time.Time {
+	return time.Now().In(configuredLocation())
+}
+
+// recentInRange returns the last limit entries that keep accepts,
+// restricted to rng when set; the limit counts kept entries only. Without
+// a range it reads ever more recent entries until it has limit of them or
+// there are no more.
+func recentInRange(ctx context.Context, storage Storage, limit int, rng dateRange, keep func(WorkoutEntry) bool) ([]WorkoutEntry, error) {
+	last := func(entries []WorkoutEntry) []WorkoutEntry {
+		entries = slices.DeleteFunc(entries, func(entry WorkoutEntry) bool { return !keep(entry) })
+		return entries[max(0, len(entries)-limit):]
+	}
+	if rng.isSet() {
+		entries, err := storage.Range(ctx, rng.Since, rng.Until)
+		if err != nil {
+			return nil, err
+		}
+		return last(entries), nil
+	}
+	for n := limit; ; n *= 4 {
+		entries, err := storage.Recent(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		read := len(entries)
+		if kept := last(entries); len(kept) >= limit || read < n {
+			return kept, nil
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRelativeDuration(t *testing.T) {
+	tests := []struct {
+		input string
+		want  relativeDuration
+	}{
+		{"1d", relativeDuration{Days: 1}},
+		{"7d", relativeDuration{Days: 7}},
+		{"10 days", relativeDuration{Days: 10}},
+		{"1 day", relativeDuration{Days: 1}},
+		{"2w", relativeDuration{Days: 14}},
+		{"3 weeks", relativeDuration{Days: 21}},
+		{" 2W ", relativeDuration{Days: 14}},
+		{"2m", relativeDuration{Months: 2}},
+		{"3 months", relativeDuration{Months: 3}},
+		{"1y", relativeDuration{Years: 1}},
+		{"2 years", relativeDuration{Years: 2}},
+		{"0d", relativeDuration{}},
+	}
+	for _, tt := range tests {
+		got, err := parseRelativeDuration(tt.input)
+		if err != nil {
+			t.Errorf("parseRelativeDuration(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseRelativeDuration(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseRelativeDurationErrors(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"", "invalid relative duration"},
+		{"d", "invalid relative duration"},
+		{"-3d", "invalid relative duration"},
+		{"3", "invalid relative duration"},
+		{"3x", `unknown unit "x"`},
+		{"3 fortnights", `unknown unit "fortnights"`},
+		{"3h", `unknown unit "h"`},
+		{"3d ago", `unknown unit "d ago"`},
+		{"99999999999999999999d", "invalid relative duration"},
+	}
+	for _, tt := range tests {
+		_, err := parseRelativeDuration(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseRelativeDuration(%q) error = %v, want one containing %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestParseRangeBound(t *testing.T) {
+	today := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input, want string
+	}{
+		{"2026-01-24", "2026-01-24"},
+		{"1d", "2026-03-30"},
+		{"2w", "2026-03-17"},
+		{"1m", "2026-03-03"}, // Feb 31st normalizes as time.AddDate does
+		{"1y", "2025-03-31"},
+	}
+	for _, tt := range tests {
+		got, err := parseRangeBound(tt.input, today)
+		if err != nil || got != tt.want {
+			t.Errorf("parseRangeBound(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+	if _, err := parseRangeBound("2026-13-01", today); err == nil {
+		t.Error("parseRangeBound(2026-13-01): no error")
+	}
+}
+
+func TestExtractRangeFlags(t *testing.T) {
+	today := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	rng, rest, err := extractRangeFlags([]string{"-p", "--since", "7d", "--full", "--until=2026-10-16"}, today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rng != (dateRange{Since: "2026-10-10", Until: "2026-10-16"}) || strings.Join(rest, " ") != "-p --full" {
+		t.Errorf("got %+v, rest %q", rng, rest)
+	}
+
+	for _, args := range [][]string{
+		{"-p", "--since"},
+		{"--since", "1w", "--since", "2w"},
+		{"--since", "2026-10-16", "--until", "2026-10-01"},
+		{"--until", "soon"},
+	} {
+		if _, _, err := extractRangeFlags(args, today); err == nil {
+			t.Errorf("extractRangeFlags(%q): no error", args)
+		}
+	}
+}
+
+func TestAcceptsRange(t *testing.T) {
+	for _, command := range []string{"-p", "history", "-s", "--stats", "export", "report", "graph"} {
+		if !acceptsRange([]string{command}) {
+			t.Errorf("%s rejects --since", command)
+		}
+	}
+	for _, args := range [][]string{nil, {"status"}, {"today"}, {"achievements"}, {"compare"}, {"next"}, {"serve"}, {"--deload"}} {
+		if acceptsRange(args) {
+			t.Errorf("%q accepts --since", args)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// A readiness score rates, before training, how ready you are: 1 (slept
+// badly, sore) to readinessScale. It is kept in the comment as an extra
+// answer, readiness=<score>, on the first entry logged that day, so a log
+// written with it reads as before in older builds and other tools.
+const (
+	readinessKey   = "readiness"
+	readinessScale = 5
+	// readinessLow is the score from which down cali log suggests deload
+	// targets.
+	readinessLow = 2
+	// readinessWeeks is how many of the latest weeks cali --stats averages
+	// readiness for.
+	readinessWeeks = 8
+)
+
+// readinessEnabled reports whether cali log asks for a readiness score at
+// the start of the first session of a day (CALI_READINESS). Without it,
+// only --readiness records one.
+func readinessEnabled() bool {
+	return envEnabled("CALI_READINESS")
+}
+
+// parseReadiness reads a score as typed: "2" or "2/5".
+func parseReadiness(input string) (int, error) {
+	value := strings.TrimSpace(input)
+	value = strings.TrimSuffix(value, "/"+strconv.Itoa(readinessScale))
+	score, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || score < 1 || score > readinessScale {
+		return 0, fmt.Errorf("%s", msg("log.readiness_invalid", strings.TrimSpace(input), readinessScale))
+	}
+	return score, nil
+}
+
+// entryReadiness returns the readiness score stored with entry, or 0.
+func entryReadiness(entry WorkoutEntry) int {
+	for _, answer := range commentExtras(entry.Comment) {
+		if answer.Key != readinessKey {
+			continue
+		}
+		if score, err := strconv.Atoi(answer.Value); err == nil && score >= 1 && score <= readinessScale {
+			return score
+		}
+	}
+	return 0
+}
+
+// addReadiness stores score in comment; 0 leaves it as it is.
+func addReadiness(comment string, score int) string {
+	if score == 0 {
+		return comment
+	}
+	return addExtras(comment, []extraAnswer{{Key: readinessKey, Value: strconv.Itoa(score)}})
+}
+
+// dayReadiness returns the score recorded with an entry of date already
+// logged, or 0.
+func dayReadiness(ctx context.Context, storage Storage, date string) int {
+	entries, err := storage.Range(ctx, date, date)
+	if err != nil {
+		detail("Not reading today's readiness: %v\n", err)
+		return 0
+	}
+	for _, entry := range entries {
+		if score := entryReadiness(entry); score > 0 {
+			return score
+		}
+	}
+	return 0
+}
+
+// promptReadiness asks for the readiness score until it reads one; Enter
+// alone skips it, as 0.
+func promptReadiness(reader *bufio.Reader) (int, error) {
+	for {
+		prompt(msg("log.readiness_prompt", readinessScale))
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			return 0, inputClosed()
+		}
+		if strings.TrimSpace(input) == "" {
+			return 0, nil
+		}
+		score, parseErr := parseReadiness(input)
+		if parseErr == nil {
+			return score, nil
+		}
+		promptln(parseErr)
+	}
+}
+
+// sessionReadiness returns the readiness of today's session and whether it
+// is new, to be stored with the entry: given with --readiness, already
+// recorded today, or asked for when CALI_READINESS is on.
+func sessionReadiness(ctx context.Context, storage Storage, reader *bufio.Reader, given int, date string) (score int, isNew bool, err error) {
+	if given > 0 {
+		return given, true, nil
+	}
+	if !readinessEnabled() {
+		return 0, false, nil
+	}
+	if score := dayReadiness(ctx, storage, date); score > 0 {
+		return score, false, nil
+	}
+	score, err = promptReadiness(reader)
+	return score, score > 0, err
+}
+
+// readinessTarget scales the latest working result at exercise and level
+// (see lastWorkingEntry) as deload mode does, for a day of low readiness.
+// ok is false without a result to scale.
+func readinessTarget(entries []WorkoutEntry, exercise, level string, percent int) (target string, last WorkoutEntry, ok bool) {
+	last, ok = lastWorkingEntry(entries, exercise, level)
+	if !ok {
+		return "", WorkoutEntry{}, false
+	}
+	target, ok = scaleRepsSets(last.RepsSets, percent)
+	return target, last, ok
+}
+
+// printReadinessAdvice suggests deload targets for exercise and level when
+// score is readinessLow or below, and reports whether it did. The advice
+// is only shown; nothing is logged differently.
+func printReadinessAdvice(ctx context.Context, storage Storage, exercise, level string, score int) bool {
+	if score == 0 || score > readinessLow {
+		return false
+	}
+	recent, err := storage.Recent(ctx, recentLevelEntries)
+	if err != nil {
+		detail("Not suggesting a target: %v\n", err)
+		return false
+	}
+	entries := calio.WithoutFuture(recent, currentTime())
+	slices.SortStableFunc(entries, func(a, b WorkoutEntry) int { return strings.Compare(a.Date, b.Date) })
+	percent := deloadPercent()
+	target, last, ok := readinessTarget(entries, exercise, level, percent)
+	if !ok {
+		prompt("%s", msg("log.readiness_low_rest", score, readinessScale))
+		return true
+	}
+	prompt("%s", msg("log.readiness_low", score, readinessScale, exercise, target, last.RepsSets, percent))
+	return true
+}
+
+// readinessWeek is the average readiness of the days of one week that
+// recorded a score.
+type readinessWeek struct {
+	Monday  string // the week's Monday, in calio.DateLayout
+	Average float64
+	Days    int
+}
+
+// weeklyReadiness averages scores, by date, per week from Monday, oldest
+// week first. Dates that don't parse are left out.
+func weeklyReadiness(scores map[string]int) []readinessWeek {
+	sums := map[string]int{}
+	days := map[string]int{}
+	for date, score := range scores {
+		day, err := time.Parse(calio.DateLayout, date)
+		if err != nil {
+			continue
+		}
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)).Format(calio.DateLayout)
+		sums[monday] += score
+		days[monday]++
+	}
+	weeks := make([]readinessWeek, 0, len(sums))
+	for monday, sum := range sums {
+		weeks = append(weeks, readinessWeek{Monday: monday, Average: roundTenth(float64(sum) / float64(days[monday])), Days: days[monday]})
+	}
+	slices.SortFunc(weeks, func(a, b readinessWeek) int { return cmp.Compare(a.Monday, b.Monday) })
+	return weeks
+}
+
+// averageReadiness is the mean of scores, 0 for none.
+func averageReadiness(scores map[string]int) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, score := range scores {
+		sum += score
+	}
+	return roundTenth(float64(sum) / float64(len(scores)))
+}
+
+// lowReadinessDays counts the dates of scores at readinessLow or below.
+func lowReadinessDays(scores map[string]int) int {
+	low := 0
+	for _, score := range scores {
+		if score <= readinessLow {
+			low++
+		}
+	}
+	return low
+}
+
+func roundTenth(value float64) float64 {
+	return math.Round(value*10) / 10
+}
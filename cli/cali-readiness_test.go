@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestParseReadiness(t *testing.T) {
+	for input, want := range map[string]int{"1": 1, " 3 ": 3, "2/5": 2, "5/5": 5, "0": 0, "6": 0, "2/10": 0, "good": 0, "": 0} {
+		got, err := parseReadiness(input)
+		if got != want || (err != nil) != (want == 0) {
+			t.Errorf("parseReadiness(%q) = %d, %v, want %d", input, got, err, want)
+		}
+	}
+}
+
+// TestReadinessComment stores scores in comments, next to extra answers and
+// flags, and reads them back; comments written without one, or by hand
+// with a score out of range, read as none.
+func TestReadinessComment(t *testing.T) {
+	flags := []entryFlag{{Kind: "pain", Note: "left shoulder"}}
+	tests := []struct {
+		comment string
+		score   int
+		want    string
+	}{
+		{"", 2, "readiness=2"},
+		{"slept badly", 1, "slept badly readiness=1"},
+		{"slept badly", 0, "slept badly"},
+		{addExtras("sore", []extraAnswer{{"knee", "ok"}}), 4, "sore knee=ok readiness=4"},
+	}
+	for _, tt := range tests {
+		got := addReadiness(tt.comment, tt.score)
+		if got != tt.want {
+			t.Errorf("addReadiness(%q, %d) = %q, want %q", tt.comment, tt.score, got, tt.want)
+		}
+		flagged := addCommentFlags(got, flags)
+		if score := entryReadiness(WorkoutEntry{Comment: flagged}); score != tt.score {
+			t.Errorf("%q reads as readiness %d, want %d", flagged, score, tt.score)
+		}
+	}
+	for _, comment := range []string{"", "felt strong", "readiness=9", "readiness=high", "readiness = 2", "my readiness was 2"} {
+		if score := entryReadiness(WorkoutEntry{Comment: comment}); score != 0 {
+			t.Errorf("%q reads as readiness %d", comment, score)
+		}
+	}
+}
+
+// TestReadinessRoundTrip logs with --readiness on a local log and reads the
+// score back from the file, where the comment keeps its text.
+func TestReadinessRoundTrip(t *testing.T) {
+	storage := pipedLog(t)
+	withGoalOverrides(t, nil)
+	if _, stderr, code := runCLI(t, "", "log", "--exercise", "pullups", "--level", "half", "--reps", "6x2", "--comment", "tired", "--readiness", "2", "--flag", "pain:elbow"); code != 0 {
+		t.Fatalf("cali log --readiness exited %d: %s", code, stderr)
+	}
+	entries := logged(t, storage)
+	if len(entries) != 1 || entryReadiness(entries[0]) != 2 || !strings.Contains(entries[0].Comment, "tired") || !strings.Contains(entries[0].Comment, "[pain: elbow]") {
+		t.Fatalf("the log holds %+v", entries)
+	}
+	if _, _, code := runCLI(t, "", "log", "--exercise", "pullups", "--level", "half", "--reps", "6x2", "--readiness", "6"); code != exitUsage {
+		t.Errorf("--readiness 6 exited %d", code)
+	}
+}
+
+// TestSessionReadiness checks where the day's score comes from: the flag,
+// an entry already logged that day, or the prompt, which Enter skips.
+func TestSessionReadiness(t *testing.T) {
+	ctx := context.Background()
+	storage := &memoryStorage{entries: []WorkoutEntry{
+		{Date: "2026-03-04", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Comment: "ok"},
+		{Date: "2026-03-04", Exercise: "Squats", Level: "Full", RepsSets: "20x2", Comment: "readiness=3"},
+	}}
+	ask := func(input, date string, given int) (int, bool, error, string) {
+		var score int
+		var isNew bool
+		var err error
+		out := captureOutput(t, &os.Stdout, func() {
+			score, isNew, err = sessionReadiness(ctx, storage, bufio.NewReader(strings.NewReader(input)), given, date)
+		})
+		return score, isNew, err, out
+	}
+
+	t.Setenv("CALI_READINESS", "")
+	if score, isNew, err, out := ask("4\n", "2026-03-05", 0); score != 0 || isNew || err != nil || out != "" {
+		t.Errorf("without CALI_READINESS: %d, %v, %v, asked %q", score, isNew, err, out)
+	}
+	if score, isNew, err, _ := ask("", "2026-03-05", 5); score != 5 || !isNew || err != nil {
+		t.Errorf("--readiness 5: %d, %v, %v", score, isNew, err)
+	}
+
+	t.Setenv("CALI_READINESS", "1")
+	if score, isNew, err, out := ask("", "2026-03-04", 0); score != 3 || isNew || err != nil || out != "" {
+		t.Errorf("a day with a score: %d, %v, %v, asked %q", score, isNew, err, out)
+	}
+	score, isNew, err, out := ask("7\n2/5\n", "2026-03-05", 0)
+	want := msg("log.readiness_prompt", 5) + msg("log.readiness_invalid", "7", 5) + "\n" + msg("log.readiness_prompt", 5)
+	if score != 2 || !isNew || err != nil || out != want {
+		t.Errorf("asked: %d, %v, %v, printing %q, want %q", score, isNew, err, out, want)
+	}
+	if score, isNew, err, _ := ask("\n", "2026-03-05", 0); score != 0 || isNew || err != nil {
+		t.Errorf("skipped: %d, %v, %v", score, isNew, err)
+	}
+	if _, _, err, _ := ask("", "2026-03-05", 0); err == nil {
+		t.Error("a closed input gave no error")
+	}
+}
+
+// TestReadinessTarget checks low readiness scales the last working result
+// with deload mode's scaler, past deloads and other levels.
+func TestReadinessTarget(t *testing.T) {
+	entries := []WorkoutEntry{
+		{Date: "2026-03-02", Exercise: "Pullups", Level: "Half", RepsSets: "8x2"},
+		{Date: "2026-03-04", Exercise: "Pullups", Level: "Full", RepsSets: "4x2"},
+		{Date: "2026-03-06", Exercise: "Pullups", Level: "Half", RepsSets: "5x2", Comment: "#deload"},
+		{Date: "2026-03-09", Exercise: "Plank", Level: "Full", RepsSets: "90s"},
+	}
+	for _, tt := range []struct {
+		exercise, level string
+		percent         int
+		want, last      string
+	}{
+		{"Pullups", "Half", 75, "6x2", "8x2"},
+		{"Pullups", "Half", 60, "5x2", "8x2"},
+		{"Pullups", "Full", 10, "1x2", "4x2"},
+		{"Plank", "Full", 60, "54s", "90s"},
+	} {
+		target, last, ok := readinessTarget(entries, tt.exercise, tt.level, tt.percent)
+		deload, _ := scaleRepsSets(tt.last, tt.percent)
+		if !ok || target != tt.want || last.RepsSets != tt.last || target != deload {
+			t.Errorf("readinessTarget(%s %s, %d%%) = %q from %q, %v, want %q from %q", tt.exercise, tt.level, tt.percent, target, last.RepsSets, ok, tt.want, tt.last)
+		}
+	}
+	if _, _, ok := readinessTarget(entries, "Squats", "Full", 60); ok {
+		t.Error("a level without history gave a target")
+	}
+}
+
+func TestPrintReadinessAdvice(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("CALI_DELOAD_PERCENT", "75")
+	storage := &memoryStorage{entries: []WorkoutEntry{{Date: "2026-03-02", Exercise: "Pullups", Level: "Half", RepsSets: "8x2"}}}
+	for _, tt := range []struct {
+		exercise string
+		score    int
+		advised  bool
+		want     string
+	}{
+		{"Pullups", 0, false, ""},
+		{"Pullups", 3, false, ""},
+		{"Pullups", 2, true, msg("log.readiness_low", 2, 5, "Pullups", "6x2", "8x2", 75)},
+		{"Pullups", 1, true, msg("log.readiness_low", 1, 5, "Pullups", "6x2", "8x2", 75)},
+		{"Squats", 2, true, msg("log.readiness_low_rest", 2, 5)},
+	} {
+		var advised bool
+		out := captureOutput(t, &os.Stdout, func() { advised = printReadinessAdvice(ctx, storage, tt.exercise, "Half", tt.score) })
+		if advised != tt.advised || out != tt.want {
+			t.Errorf("%s at %d: advised %v, printing %q, want %q", tt.exercise, tt.score, advised, out, tt.want)
+		}
+	}
+	if got := msg("log.readiness_low", 2, 5, "Pullups", "6x2", "8x2", 75); got != "Readiness 2/5 — consider deload targets today: Pullups ~6x2 instead of 8x2 (75%)\n" {
+		t.Errorf("the advice reads %q", got)
+	}
+}
+
+func TestWeeklyReadiness(t *testing.T) {
+	scores := map[string]int{
+		"2026-03-02": 2, // Monday
+		"2026-03-04": 3,
+		"2026-03-08": 5, // Sunday, same week
+		"2026-03-09": 1, // the next Monday
+		"2026-02-27": 4, // a Friday before
+		"someday":    5,
+	}
+	want := []readinessWeek{
+		{Monday: "2026-02-23", Average: 4, Days: 1},
+		{Monday: "2026-03-02", Average: 3.3, Days: 3},
+		{Monday: "2026-03-09", Average: 1, Days: 1},
+	}
+	if got := weeklyReadiness(scores); !slices.Equal(got, want) {
+		t.Errorf("weeklyReadiness = %+v, want %+v", got, want)
+	}
+	if got := weeklyReadiness(nil); len(got) != 0 {
+		t.Errorf("weeklyReadiness(nil) = %+v", got)
+	}
+	delete(scores, "someday")
+	if got := averageReadiness(scores); got != 3 {
+		t.Errorf("averageReadiness = %v, want 3", got)
+	}
+	if got := averageReadiness(nil); got != 0 {
+		t.Errorf("averageReadiness(nil) = %v", got)
+	}
+	if got := lowReadinessDays(scores); got != 2 {
+		t.Errorf("lowReadinessDays = %d, want 2", got)
+	}
+}
+
+// TestReadinessStats checks stats count a day's score once, from its first
+// entry, and compare sets readiness beside volume once scores exist.
+func TestReadinessStats(t *testing.T) {
+	stats := computeStats([]WorkoutEntry{
+		{Date: "2026-03-02", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Comment: "readiness=2"},
+		{Date: "2026-03-02", Day: "A", Exercise: "Squats", Level: "Full", RepsSets: "20x2", Comment: "readiness=4"},
+		{Date: "2026-03-04", Day: "B", Exercise: "Pullups", Level: "Half", RepsSets: "8x2", Comment: "readiness=5"},
+		{Date: "2026-03-06", Day: "C", Exercise: "Pushups", Level: "Full", RepsSets: "10x2"},
+	}, time.Date(2026, 3, 8, 12, 0, 0, 0, time.Local))
+	if want := map[string]int{"2026-03-02": 2, "2026-03-04": 5}; !maps.Equal(stats.Readiness, want) {
+		t.Errorf("stats.Readiness = %v, want %v", stats.Readiness, want)
+	}
+
+	storage := pipedLog(t)
+	today := currentTime()
+	for days, score := range map[int]string{0: "readiness=2", 1: "readiness=4", 9: "readiness=5", 10: ""} {
+		entry := WorkoutEntry{Date: today.AddDate(0, 0, -days).Format(calio.DateLayout), Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Comment: score}
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stdout, stderr, code := runCLI(t, "", "--stats")
+	if code != 0 || !strings.Contains(stdout, msg("stats.readiness", 3.7, 5, 3)) || !strings.Contains(stdout, msg("stats.readiness_weeks")) {
+		t.Errorf("cali --stats exited %d:\n%s%s", code, stdout, stderr)
+	}
+
+	stdout, _, code = runCLI(t, "", "compare", "--window", "7d", "--json")
+	var result comparison
+	if err := json.Unmarshal([]byte(stdout), &result); code != 0 || err != nil {
+		t.Fatalf("cali compare --json exited %d, printed %q: %v", code, stdout, err)
+	}
+	metric := func(name string) metricDelta {
+		i := slices.IndexFunc(result.Metrics, func(m metricDelta) bool { return m.Metric == name })
+		if i < 0 {
+			t.Fatalf("cali compare has no %s: %+v", name, result.Metrics)
+		}
+		return result.Metrics[i]
+	}
+	if m := metric("avg_readiness"); m.Previous != 5 || m.Current != 3 || m.Delta != -2 {
+		t.Errorf("avg_readiness = %+v", m)
+	}
+	if m := metric("low_readiness_days"); m.Previous != 0 || m.Current != 1 {
+		t.Errorf("low_readiness_days = %+v", m)
+	}
+}
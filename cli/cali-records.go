@@ -0,0 +1,141 @@
+package cli
+
+import "github.com/ziad73/cali-logger/calio"
+
+// plateauSessions is how many sessions in a row without beating the
+// previous best count as a plateau.
+const plateauSessions = 3
+
+type exerciseLevel struct {
+	Exercise string
+	Level    string
+}
+
+// workScore ranks a Reps×Sets value for record keeping: the longest hold in
+// seconds for timed work, total reps otherwise. Intervals aren't comparable with set work
+// and never score.
+func workScore(value string) (int, bool) {
+	parsed, ok := parseRepsSets(value)
+	if !ok || parsed.interval() {
+		return 0, false
+	}
+	if parsed.timed() {
+		return int(parsed.longestHold()), true
+	}
+	return parsed.totalReps(), true
+}
+
+// workRank orders the sessions of one level for records and plateaus: by
+// workScore, then by load in kilograms, so 20x2 +10kg beats 20x2 but never
+// 21x2. Unloaded work has load 0.
+type workRank struct {
+	score int
+	load  float64
+}
+
+// entryRank returns the rank of entry; ok is false when its work doesn't
+// score.
+func entryRank(entry WorkoutEntry) (rank workRank, ok bool) {
+	score, ok := workScore(entry.RepsSets)
+	if !ok {
+		return workRank{}, false
+	}
+	return workRank{score: score, load: entryLoad(entry).Kilograms()}, true
+}
+
+// beats reports whether r ranks above other.
+func (r workRank) beats(other workRank) bool {
+	if r.score != other.score {
+		return r.score > other.score
+	}
+	return r.load > other.load
+}
+
+// recordTracker follows personal records and plateaus one entry at a time,
+// so they can be worked out while streaming the log. Entries should come
+// oldest first; deload sessions and unparseable values are ignored.
+type recordTracker struct {
+	records map[exerciseLevel]WorkoutEntry
+	best    map[exerciseLevel]workRank
+	scores  map[exerciseLevel][]workRank
+	order   []exerciseLevel
+}
+
+func newRecordTracker() *recordTracker {
+	return &recordTracker{
+		records: map[exerciseLevel]WorkoutEntry{},
+		best:    map[exerciseLevel]workRank{},
+		scores:  map[exerciseLevel][]workRank{},
+	}
+}
+
+func (t *recordTracker) add(entry WorkoutEntry) {
+	if isDeload(entry) || classifyEntry(entry) != kindWorking {
+		return
+	}
+	rank, ok := entryRank(entry)
+	if !ok {
+		return
+	}
+	entry = calio.Canonical(entry)
+	key := exerciseLevel{entry.Exercise, entry.Level}
+	if current, seen := t.best[key]; !seen || rank.beats(current) {
+		t.best[key] = rank
+		t.records[key] = entry
+	}
+	if _, seen := t.scores[key]; !seen {
+		t.order = append(t.order, key)
+	}
+	t.scores[key] = append(t.scores[key], rank)
+}
+
+// plateaus lists exercise levels whose last plateauSessions working
+// sessions did not beat the best result recorded before them.
+func (t *recordTracker) plateaus() []exerciseLevel {
+	var stuck []exerciseLevel
+	for _, key := range t.order {
+		series := t.scores[key]
+		if len(series) <= plateauSessions {
+			continue
+		}
+		split := len(series) - plateauSessions
+		var before workRank
+		for _, rank := range series[:split] {
+			if rank.beats(before) {
+				before = rank
+			}
+		}
+		improved := false
+		for _, rank := range series[split:] {
+			if rank.beats(before) {
+				improved = true
+				break
+			}
+		}
+		if !improved {
+			stuck = append(stuck, key)
+		}
+	}
+	return stuck
+}
+
+// personalRecords returns the best entry per exercise and level, ranked by
+// workRank. Deload sessions and unparseable values are ignored; ties keep
+// the earliest entry.
+func personalRecords(entries []WorkoutEntry) map[exerciseLevel]WorkoutEntry {
+	tracker := newRecordTracker()
+	for _, entry := range entries {
+		tracker.add(entry)
+	}
+	return tracker.records
+}
+
+// plateaus lists exercise levels whose last plateauSessions working sessions
+// did not beat the best result recorded before them.
+func plateaus(entries []WorkoutEntry) []exerciseLevel {
+	tracker := newRecordTracker()
+	for _, entry := range entries {
+		tracker.add(entry)
+	}
+	return tracker.plateaus()
+}
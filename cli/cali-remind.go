@@ -0,0 +1,640 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// cali remind install schedules `cali remind --check`, which nudges when
+// nothing has been logged today, as a systemd user timer on Linux or a
+// launchd agent on macOS. On Windows it prints the schtasks command instead.
+
+const (
+	remindUnitName = "cali-remind"
+	launchdLabel   = "com.ziad73.cali-logger.remind"
+
+	// remindStampPrefix marks the line carrying the checksum of a generated
+	// file, so edits made by hand can be told apart from what cali wrote.
+	remindStampPrefix = "cali-remind sha256="
+)
+
+// reminderEnvVars are copied into the scheduled job's environment when set,
+// since timers don't inherit the login shell's variables.
+var reminderEnvVars = []string{
+	"CALI_STORAGE", "CALI_LOG_DIR", "CALI_SHEET_ID", "CALI_SHEET_NAME", "CALI_SHEET_PER_YEAR", "CALI_SHEET_PAGE_SIZE",
+	"CALI_GOOGLE_CREDENTIALS_JSON", "GOOGLE_APPLICATION_CREDENTIALS",
+	"CALI_AUTO_BACKUPS", "CALI_TZ", "CALI_LANG", "CALI_DATE_FORMAT",
+	"CALI_REPORT_EMAIL", "CALI_SMTP_HOST", "CALI_SMTP_PORT", "CALI_SMTP_SECURITY",
+	"CALI_SMTP_USER", "CALI_SMTP_PASSWORD", "CALI_SMTP_FROM", "CALI_SMTP_TO",
+	"CALI_TARGETS", "CALI_COMMENT_LIMIT", "CALI_PRIVATE_MARKER", "CALI_LOADED", "CALI_LOAD_UNIT", "CALI_HYPERLINKS",
+	"CALI_EXERCISE_ORDER", "CALI_HIDE_EXERCISES", "CALI_HIDE_LEVELS", "CALI_JOURNAL", "CALI_JOURNAL_FORMAT",
+	"CALI_ACCEPT_SUGGESTION", "CALI_KEY_MAX_AGE", "CALI_SESSIONS_PER_WEEK", "CALI_READINESS",
+}
+
+// reminderSchedule is when the reminder fires: at Hour:Minute on Days
+// (every day when Days holds all seven).
+type reminderSchedule struct {
+	Hour, Minute int
+	Days         []time.Weekday
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseWeekday reads a three-letter or full day name, e.g. "tue" or
+// "tuesday".
+func parseWeekday(name string) (time.Weekday, bool) {
+	day, ok := weekdayNames[name]
+	if !ok && len(name) > 3 {
+		day, ok = weekdayNames[name[:3]]
+		ok = ok && strings.EqualFold(day.String(), name)
+	}
+	return day, ok
+}
+
+// weekOrder lists the days Monday first, the order schedules are shown in.
+var weekOrder = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// parseSchedule reads --at ("18:00") and --days ("mon,wed,fri", full day
+// names, or "daily").
+func parseSchedule(at, days string) (reminderSchedule, error) {
+	hour, minute, ok := strings.Cut(strings.TrimSpace(at), ":")
+	h, errH := strconv.Atoi(hour)
+	m, errM := strconv.Atoi(minute)
+	if !ok || errH != nil || errM != nil || len(minute) != 2 || h < 0 || h > 23 || m < 0 || m > 59 {
+		return reminderSchedule{}, fmt.Errorf("invalid time %q (use HH:MM, e.g. 18:00)", at)
+	}
+	schedule := reminderSchedule{Hour: h, Minute: m}
+
+	days = strings.ToLower(strings.TrimSpace(days))
+	if days == "daily" || days == "" {
+		schedule.Days = slices.Clone(weekOrder)
+		return schedule, nil
+	}
+	seen := map[time.Weekday]bool{}
+	for _, name := range strings.Split(days, ",") {
+		name = strings.TrimSpace(name)
+		day, ok := parseWeekday(name)
+		if !ok {
+			return reminderSchedule{}, fmt.Errorf("unknown day %q (use mon,tue,wed,thu,fri,sat,sun or daily)", name)
+		}
+		seen[day] = true
+	}
+	for _, day := range weekOrder {
+		if seen[day] {
+			schedule.Days = append(schedule.Days, day)
+		}
+	}
+	return schedule, nil
+}
+
+func (s reminderSchedule) Daily() bool {
+	return len(s.Days) == len(weekOrder)
+}
+
+// dayAbbrevs returns the days as "Mon", "Wed", ... in week order.
+func (s reminderSchedule) dayAbbrevs() []string {
+	names := make([]string, len(s.Days))
+	for i, day := range s.Days {
+		names[i] = day.String()[:3]
+	}
+	return names
+}
+
+// OnCalendar is the systemd OnCalendar= expression for the schedule.
+func (s reminderSchedule) OnCalendar() string {
+	at := fmt.Sprintf("*-*-* %02d:%02d:00", s.Hour, s.Minute)
+	if s.Daily() {
+		return at
+	}
+	return strings.Join(s.dayAbbrevs(), ",") + " " + at
+}
+
+func (s reminderSchedule) String() string {
+	days := "daily"
+	if !s.Daily() {
+		days = strings.Join(s.dayAbbrevs(), ",")
+	}
+	return fmt.Sprintf("%s at %02d:%02d", days, s.Hour, s.Minute)
+}
+
+// reminderConfig is what the unit templates are rendered from.
+type reminderConfig struct {
+	Binary   string
+	Schedule reminderSchedule
+	Env      [][2]string // name, value
+	EnvFile  string
+}
+
+// reminderFile is one generated file and where it is installed.
+type reminderFile struct {
+	Path    string
+	Content string
+}
+
+var remindTemplates = template.Must(template.New("remind").Funcs(template.FuncMap{
+	"systemdQuote": func(value string) string {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+		value = strings.ReplaceAll(value, `"`, `\"`)
+		return `"` + strings.ReplaceAll(value, "%", "%%") + `"`
+	},
+	"envQuote": func(value string) string {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+		return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	},
+	"xml": func(value string) string {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(value))
+		return buf.String()
+	},
+}).Parse(`
+{{define "service"}}# Generated by cali remind install; remove with cali remind uninstall.
+[Unit]
+Description=cali workout reminder
+
+[Service]
+Type=oneshot
+EnvironmentFile=-{{systemdQuote .EnvFile}}
+ExecStart={{systemdQuote .Binary}} remind --check
+{{end}}
+
+{{define "timer"}}# Generated by cali remind install; remove with cali remind uninstall.
+[Unit]
+Description=cali workout reminder ({{.Schedule}})
+
+[Timer]
+OnCalendar={{.Schedule.OnCalendar}}
+
+[Install]
+WantedBy=timers.target
+{{end}}
+
+{{define "env"}}# Environment for the cali reminder, copied by cali remind install.
+{{range .Env}}{{index . 0}}={{envQuote (index . 1)}}
+{{end}}{{end}}
+
+{{define "plist"}}<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + launchdLabel + `</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{xml .Binary}}</string>
+		<string>remind</string>
+		<string>--check</string>
+	</array>
+	<key>StartCalendarInterval</key>
+	<array>
+{{- $s := .Schedule}}{{range $s.Days}}
+		<dict>
+			{{if not $s.Daily}}<key>Weekday</key>
+			<integer>{{printf "%d" .}}</integer>
+			{{end}}<key>Hour</key>
+			<integer>{{$s.Hour}}</integer>
+			<key>Minute</key>
+			<integer>{{$s.Minute}}</integer>
+		</dict>{{if $s.Daily}}{{break}}{{end}}{{end}}
+	</array>
+	<key>EnvironmentVariables</key>
+	<dict>
+{{- range .Env}}
+		<key>{{index . 0}}</key>
+		<string>{{xml (index . 1)}}</string>
+{{- end}}
+	</dict>
+</dict>
+</plist>
+{{end}}`))
+
+// renderReminderFiles renders the files to install for goos. Each carries a
+// checksum line (see stampFile).
+func renderReminderFiles(goos, configDir, homeDir string, cfg reminderConfig) ([]reminderFile, error) {
+	type unit struct{ name, path, comment string }
+	var units []unit
+	switch goos {
+	case "linux":
+		unitDir := filepath.Join(configDir, "systemd", "user")
+		units = []unit{
+			{"service", filepath.Join(unitDir, remindUnitName+".service"), "# %s\n"},
+			{"timer", filepath.Join(unitDir, remindUnitName+".timer"), "# %s\n"},
+			{"env", cfg.EnvFile, "# %s\n"},
+		}
+	case "darwin":
+		units = []unit{
+			{"plist", filepath.Join(homeDir, "Library", "LaunchAgents", launchdLabel+".plist"), "<!-- %s -->\n"},
+		}
+	default:
+		return nil, fmt.Errorf("cali remind install supports Linux (systemd) and macOS (launchd), not %s", goos)
+	}
+
+	var files []reminderFile
+	for _, u := range units {
+		var buf bytes.Buffer
+		if err := remindTemplates.ExecuteTemplate(&buf, u.name, cfg); err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", u.name, err)
+		}
+		files = append(files, reminderFile{Path: u.path, Content: stampFile(buf.String(), u.comment)})
+	}
+	return files, nil
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// stampFile adds a checksum line after the first line of content, written
+// with the comment format (e.g. "# %s\n").
+func stampFile(content, comment string) string {
+	first, rest, _ := strings.Cut(content, "\n")
+	return first + "\n" + fmt.Sprintf(comment, remindStampPrefix+contentHash(content)) + rest
+}
+
+// unmodified reports whether content is a generated file nobody has edited
+// since: its checksum line matches the rest of the file.
+func unmodified(content string) bool {
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		_, stamp, found := strings.Cut(line, remindStampPrefix)
+		if !found {
+			continue
+		}
+		hash, _, _ := strings.Cut(stamp, " ")
+		hash = strings.TrimSpace(hash)
+		rest := strings.Join(slices.Delete(slices.Clone(lines), i, i+1), "")
+		return hash == contentHash(rest)
+	}
+	return false
+}
+
+// schedulerControl runs the platform's service manager. It sits behind an
+// interface so install and uninstall can be exercised without touching it.
+type schedulerControl interface {
+	Enable(files []reminderFile) error
+	Disable(files []reminderFile) error
+	Status() (string, error)
+}
+
+type systemdControl struct{}
+
+func (systemdControl) Enable([]reminderFile) error {
+	if err := runTool("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	return runTool("systemctl", "--user", "enable", "--now", remindUnitName+".timer")
+}
+
+func (systemdControl) Disable([]reminderFile) error {
+	if err := runTool("systemctl", "--user", "disable", "--now", remindUnitName+".timer"); err != nil {
+		return err
+	}
+	return runTool("systemctl", "--user", "daemon-reload")
+}
+
+func (systemdControl) Status() (string, error) {
+	out, err := exec.Command("systemctl", "--user", "list-timers", remindUnitName+".timer", "--no-pager").CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+type launchdControl struct{}
+
+func (launchdControl) Enable(files []reminderFile) error {
+	// Unloading first makes a reinstall pick up the new plist.
+	_ = exec.Command("launchctl", "unload", files[0].Path).Run()
+	return runTool("launchctl", "load", "-w", files[0].Path)
+}
+
+func (launchdControl) Disable(files []reminderFile) error {
+	return runTool("launchctl", "unload", "-w", files[0].Path)
+}
+
+func (launchdControl) Status() (string, error) {
+	out, err := exec.Command("launchctl", "list", launchdLabel).CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+func runTool(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %v: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+var newSchedulerControl = func(goos string) schedulerControl {
+	if goos == "darwin" {
+		return launchdControl{}
+	}
+	return systemdControl{}
+}
+
+// newRemindFlagSet declares the --check flag of cali remind into check.
+func newRemindFlagSet(check *bool) *flag.FlagSet {
+	fs := newFlagSet("remind")
+	fs.BoolVar(check, "check", false, "notify when nothing has been logged today")
+	return fs
+}
+
+func runRemind(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "install":
+			return installReminder(args[1:])
+		case "uninstall":
+			return uninstallReminder()
+		case "status":
+			return reminderStatus()
+		}
+	}
+
+	var check bool
+	fs := newRemindFlagSet(&check)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if !check || fs.NArg() > 0 {
+		return usageError("usage: cali remind install|uninstall|status or cali remind --check")
+	}
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	err = checkReminder(ctx, storage)
+	if reportDue(currentTime()) {
+		err = errors.Join(err, sendWeeklyReport(ctx, storage))
+	}
+	return err
+}
+
+// reportDue reports whether the reminder run should also email last week's
+// recap: on Mondays, when CALI_REPORT_EMAIL is on.
+func reportDue(now time.Time) bool {
+	return now.Weekday() == time.Monday && envEnabled("CALI_REPORT_EMAIL")
+}
+
+// sendWeeklyReport is cali report --email for the timer: an empty week is
+// logged and skipped without failing the run.
+func sendWeeklyReport(ctx context.Context, storage Storage) error {
+	cfg, err := loadSMTPConfig(os.Getenv)
+	if err != nil {
+		return usageError("%v", err)
+	}
+	err = emailReport(ctx, storage, cfg, dateRange{}, reportOptions{Email: true})
+	if errors.Is(err, errNoResults) {
+		return nil
+	}
+	return err
+}
+
+// checkReminder sends a desktop notification, and prints it, when today has
+// no entries yet. It is what the scheduled job runs.
+func checkReminder(ctx context.Context, storage Storage) error {
+	today := currentTime().Format(calio.DateLayout)
+	entries, err := storage.SearchByDate(ctx, today)
+	if err != nil {
+		return storageError("reading today's workouts", err)
+	}
+	if len(entries) > 0 {
+		detail("Already logged %d entr(ies) today, no reminder\n", len(entries))
+		return nil
+	}
+
+	lastDay, _, err := storage.LastTrainingDay(ctx)
+	if err != nil {
+		return storageError("reading the last training day", err)
+	}
+	text := msg("remind.nudge", suggestDay(ctx, storage, lastDay))
+	sayln(text)
+	if err := notifyDesktop("cali", text); err != nil {
+		detail("Desktop notification failed: %v\n", err)
+	}
+	return nil
+}
+
+func notifyDesktop(title, text string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(text), strconv.Quote(title))
+		return exec.Command("osascript", "-e", script).Run()
+	case "windows":
+		return errors.New("desktop notifications are not supported on Windows")
+	default:
+		return exec.Command("notify-send", title, text).Run()
+	}
+}
+
+// reminderPaths returns the directories the scheduler files live under and
+// the environment file, which is cali's own (see appPaths).
+func reminderPaths() (configDir, homeDir, envFile string, err error) {
+	if configDir, err = os.UserConfigDir(); err != nil {
+		return "", "", "", err
+	}
+	paths, err := userPaths()
+	if err != nil {
+		return "", "", "", err
+	}
+	return configDir, paths.env.homeDir, paths.ConfigFile("remind.env"), nil
+}
+
+func reminderEnv(getenv func(string) string) [][2]string {
+	var env [][2]string
+	for _, name := range reminderEnvVars {
+		if value := strings.TrimSpace(getenv(name)); value != "" {
+			env = append(env, [2]string{name, value})
+		}
+	}
+	return env
+}
+
+// installOptions are the flags of cali remind install.
+type installOptions struct {
+	At    string
+	Days  string
+	Force bool
+}
+
+// newInstallFlagSet declares the flags of cali remind install into opts.
+func newInstallFlagSet(opts *installOptions) *flag.FlagSet {
+	fs := newFlagSet("remind install")
+	fs.StringVar(&opts.At, "at", "18:00", "time of day (HH:MM)")
+	fs.StringVar(&opts.Days, "days", "daily", "days to remind on, e.g. mon,wed,fri")
+	fs.BoolVar(&opts.Force, "force", false, "overwrite reminder files edited by hand")
+	return fs
+}
+
+func installReminder(args []string) error {
+	var opts installOptions
+	fs := newInstallFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError("usage: cali remind install [--at HH:MM] [--days mon,wed,fri] [--force]")
+	}
+	schedule, err := parseSchedule(opts.At, opts.Days)
+	if err != nil {
+		return usageError("%v", err)
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating the cali binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+
+	if runtime.GOOS == "windows" {
+		fmt.Println(msg("remind.windows_hint"))
+		fmt.Println(schtasksCommand(binary, schedule))
+		return nil
+	}
+
+	configDir, homeDir, envFile, err := reminderPaths()
+	if err != nil {
+		return storageError("locating the config directory", err)
+	}
+	cfg := reminderConfig{
+		Binary:   binary,
+		Schedule: schedule,
+		Env:      reminderEnv(os.Getenv),
+		EnvFile:  envFile,
+	}
+	files, err := renderReminderFiles(runtime.GOOS, configDir, homeDir, cfg)
+	if err != nil {
+		return usageError("%v", err)
+	}
+
+	for _, file := range files {
+		existing, err := os.ReadFile(file.Path)
+		if err == nil && !opts.Force && string(existing) != file.Content && !unmodified(string(existing)) {
+			return usageError("%s", msg("remind.modified", file.Path))
+		}
+	}
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file.Path), 0755); err != nil {
+			return storageError("writing reminder files", err)
+		}
+		// The environment file may name the credentials; keep it private.
+		if err := os.WriteFile(file.Path, []byte(file.Content), 0600); err != nil {
+			return storageError("writing reminder files", err)
+		}
+		detail("Wrote %s\n", file.Path)
+	}
+
+	if err := newSchedulerControl(runtime.GOOS).Enable(files); err != nil {
+		return storageError("enabling the reminder", err)
+	}
+	say(msg("remind.installed", schedule))
+	return nil
+}
+
+func uninstallReminder() error {
+	if runtime.GOOS == "windows" {
+		fmt.Println(msg("remind.windows_hint"))
+		fmt.Printf("schtasks /Delete /TN %q /F\n", remindUnitName)
+		return nil
+	}
+	configDir, homeDir, envFile, err := reminderPaths()
+	if err != nil {
+		return storageError("locating the config directory", err)
+	}
+	files, err := renderReminderFiles(runtime.GOOS, configDir, homeDir, reminderConfig{
+		EnvFile: envFile,
+	})
+	if err != nil {
+		return usageError("%v", err)
+	}
+	if _, err := os.Stat(files[0].Path); errors.Is(err, os.ErrNotExist) {
+		sayln(msg("remind.not_installed"))
+		return nil
+	}
+
+	if err := newSchedulerControl(runtime.GOOS).Disable(files); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	for _, file := range files {
+		if err := os.Remove(file.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return storageError("removing reminder files", err)
+		}
+	}
+	sayln(msg("remind.uninstalled"))
+	return nil
+}
+
+func reminderStatus() error {
+	if runtime.GOOS == "windows" {
+		fmt.Println(msg("remind.windows_hint"))
+		fmt.Printf("schtasks /Query /TN %q\n", remindUnitName)
+		return nil
+	}
+	configDir, homeDir, envFile, err := reminderPaths()
+	if err != nil {
+		return storageError("locating the config directory", err)
+	}
+	files, err := renderReminderFiles(runtime.GOOS, configDir, homeDir, reminderConfig{
+		EnvFile: envFile,
+	})
+	if err != nil {
+		return usageError("%v", err)
+	}
+
+	installed := false
+	for _, file := range files {
+		content, err := os.ReadFile(file.Path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			fmt.Printf("%s: %s\n", file.Path, msg("remind.file_missing"))
+		case err != nil:
+			return storageError("reading reminder files", err)
+		case unmodified(string(content)):
+			installed = true
+			fmt.Printf("%s: %s\n", file.Path, msg("remind.file_generated"))
+		default:
+			installed = true
+			fmt.Printf("%s: %s\n", file.Path, msg("remind.file_edited"))
+		}
+	}
+	if !installed {
+		return nil
+	}
+	if out, err := newSchedulerControl(runtime.GOOS).Status(); out != "" || err != nil {
+		fmt.Println(out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// schtasksCommand is the Windows Task Scheduler command equivalent to the
+// schedule, printed for the user to run.
+func schtasksCommand(binary string, s reminderSchedule) string {
+	schedule := "/SC DAILY"
+	if !s.Daily() {
+		schedule = "/SC WEEKLY /D " + strings.ToUpper(strings.Join(s.dayAbbrevs(), ","))
+	}
+	return fmt.Sprintf(`schtasks /Create /TN %q %s /ST %02d:%02d /TR "\"%s\" remind --check"`,
+		remindUnitName, schedule, s.Hour, s.Minute, binary)
+}
@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		at, days string
+		want     string // as reminderSchedule.String shows it
+		calendar string
+	}{
+		{"18:00", "daily", "daily at 18:00", "*-*-* 18:00:00"},
+		{"07:05", "", "daily at 07:05", "*-*-* 07:05:00"},
+		{"0:30", "mon,wed,fri", "Mon,Wed,Fri at 00:30", "Mon,Wed,Fri *-*-* 00:30:00"},
+		{"23:59", "fri, MON ,wednesday", "Mon,Wed,Fri at 23:59", "Mon,Wed,Fri *-*-* 23:59:00"},
+		{"18:00", "sun,sat,sun", "Sat,Sun at 18:00", "Sat,Sun *-*-* 18:00:00"},
+		{"18:00", "mon,tue,wed,thu,fri,sat,sun", "daily at 18:00", "*-*-* 18:00:00"},
+	}
+	for _, tt := range tests {
+		schedule, err := parseSchedule(tt.at, tt.days)
+		if err != nil {
+			t.Errorf("parseSchedule(%q, %q): %v", tt.at, tt.days, err)
+			continue
+		}
+		if schedule.String() != tt.want || schedule.OnCalendar() != tt.calendar {
+			t.Errorf("parseSchedule(%q, %q) = %s (%s), want %s (%s)", tt.at, tt.days, schedule, schedule.OnCalendar(), tt.want, tt.calendar)
+		}
+	}
+
+	for _, bad := range [][2]string{
+		{"24:00", "daily"}, {"18:60", "daily"}, {"18", "daily"}, {"18:0", "daily"}, {"six", "daily"}, {"-1:00", "daily"},
+		{"18:00", "mon,funday"}, {"18:00", "monx"}, {"18:00", "mo"}, {"18:00", "mon,,fri"},
+	} {
+		if _, err := parseSchedule(bad[0], bad[1]); err == nil {
+			t.Errorf("parseSchedule(%q, %q): no error", bad[0], bad[1])
+		}
+	}
+}
+
+func testReminderConfig(t *testing.T, days string) reminderConfig {
+	t.Helper()
+	schedule, err := parseSchedule("18:30", days)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reminderConfig{
+		Binary:   "/opt/my tools/cali",
+		Schedule: schedule,
+		Env:      [][2]string{{"CALI_SHEET_ID", "abc"}, {"CALI_SMTP_PASSWORD", `p"a\ss 100%`}},
+		EnvFile:  "/home/me/.config/cali-logger/remind.env",
+	}
+}
+
+func TestRenderReminderFilesSystemd(t *testing.T) {
+	files, err := renderReminderFiles("linux", "/home/me/.config", "/home/me", testReminderConfig(t, "mon,fri"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+		if !unmodified(file.Content) {
+			t.Errorf("%s: checksum line doesn't match its content", file.Path)
+		}
+	}
+	want := []string{
+		"/home/me/.config/systemd/user/cali-remind.service",
+		"/home/me/.config/systemd/user/cali-remind.timer",
+		"/home/me/.config/cali-logger/remind.env",
+	}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("paths = %q, want %q", paths, want)
+	}
+
+	service, timer, env := files[0].Content, files[1].Content, files[2].Content
+	for _, line := range []string{
+		`ExecStart="/opt/my tools/cali" remind --check`,
+		`EnvironmentFile=-"/home/me/.config/cali-logger/remind.env"`,
+		"Type=oneshot",
+	} {
+		if !strings.Contains(service, line+"\n") {
+			t.Errorf("service lacks %q:\n%s", line, service)
+		}
+	}
+	for _, line := range []string{"OnCalendar=Mon,Fri *-*-* 18:30:00", "Description=cali workout reminder (Mon,Fri at 18:30)", "WantedBy=timers.target"} {
+		if !strings.Contains(timer, line+"\n") {
+			t.Errorf("timer lacks %q:\n%s", line, timer)
+		}
+	}
+	for _, line := range []string{`CALI_SHEET_ID="abc"`, `CALI_SMTP_PASSWORD="p\"a\\ss 100%"`} {
+		if !strings.Contains(env, line+"\n") {
+			t.Errorf("environment file lacks %q:\n%s", line, env)
+		}
+	}
+}
+
+func TestRenderReminderFilesLaunchd(t *testing.T) {
+	for _, tt := range []struct {
+		days     string
+		weekdays []string
+		entries  int
+	}{
+		{"daily", nil, 1},
+		{"mon,wed,sun", []string{"1", "3", "0"}, 3},
+	} {
+		files, err := renderReminderFiles("darwin", "/Users/me/Library/Application Support", "/Users/me", testReminderConfig(t, tt.days))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 || files[0].Path != "/Users/me/Library/LaunchAgents/com.ziad73.cali-logger.remind.plist" {
+			t.Fatalf("files = %+v", files)
+		}
+		content := files[0].Content
+		if !unmodified(content) {
+			t.Errorf("%s: checksum line doesn't match its content", tt.days)
+		}
+
+		// The plist must be well-formed XML; collect what matters from it.
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		decoder.Strict = true
+		var keys, strs []string
+		var last string
+		weekdays := []string{}
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				if err != io.EOF {
+					t.Fatalf("%s: plist isn't well-formed: %v\n%s", tt.days, err, content)
+				}
+				break
+			}
+			switch token := token.(type) {
+			case xml.StartElement:
+				last = token.Name.Local
+			case xml.CharData:
+				text := strings.TrimSpace(string(token))
+				switch {
+				case text == "":
+				case last == "key":
+					keys = append(keys, text)
+				case last == "string":
+					strs = append(strs, text)
+				case last == "integer" && keys[len(keys)-1] == "Weekday":
+					weekdays = append(weekdays, text)
+				}
+			}
+		}
+		if got := strings.Count(content, "<key>Hour</key>"); got != tt.entries {
+			t.Errorf("%s: %d calendar entries, want %d", tt.days, got, tt.entries)
+		}
+		if !slices.Equal(weekdays, append([]string{}, tt.weekdays...)) {
+			t.Errorf("%s: weekdays = %q, want %q", tt.days, weekdays, tt.weekdays)
+		}
+		if !slices.Contains(strs, "/opt/my tools/cali") || !slices.Contains(strs, `p"a\ss 100%`) || !slices.Contains(keys, "CALI_SMTP_PASSWORD") {
+			t.Errorf("%s: plist lacks the binary or environment: %q %q", tt.days, keys, strs)
+		}
+	}
+}
+
+func TestRenderReminderFilesUnsupported(t *testing.T) {
+	if _, err := renderReminderFiles("plan9", "/c", "/h", testReminderConfig(t, "daily")); err == nil {
+		t.Error("plan9: no error")
+	}
+}
+
+func TestUnmodified(t *testing.T) {
+	content := stampFile("# Generated\nA=1\n", "# %s\n")
+	if !unmodified(content) {
+		t.Fatalf("freshly stamped file reads as edited:\n%s", content)
+	}
+	if unmodified(strings.Replace(content, "A=1", "A=2", 1)) {
+		t.Error("edited file reads as unmodified")
+	}
+	if unmodified("# Written by hand\nA=1\n") {
+		t.Error("file without a checksum reads as unmodified")
+	}
+}
+
+func TestSchtasksCommand(t *testing.T) {
+	schedule, _ := parseSchedule("06:15", "tue,thu")
+	want := `schtasks /Create /TN "cali-remind" /SC WEEKLY /D TUE,THU /ST 06:15 /TR "\"C:\cali.exe\" remind --check"`
+	if got := schtasksCommand(`C:\cali.exe`, schedule); got != want {
+		t.Errorf("schtasksCommand = %s, want %s", got, want)
+	}
+}
+
+// fakeScheduler records what install and uninstall ask of the service
+// manager.
+type fakeScheduler struct {
+	enabled, disabled [][]reminderFile
+}
+
+func (f *fakeScheduler) Enable(files []reminderFile) error {
+	f.enabled = append(f.enabled, files)
+	return nil
+}
+
+func (f *fakeScheduler) Disable(files []reminderFile) error {
+	f.disabled = append(f.disabled, files)
+	return nil
+}
+
+func (f *fakeScheduler) Status() (string, error) { return "NEXT LEFT cali-remind.timer", nil }
+
+func TestInstallAndUninstallReminder(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("installs systemd units; the other platforms' files are checked by rendering")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("CALI_SHEET_ID", "sheet-for-timer")
+	scheduler := &fakeScheduler{}
+	saved := newSchedulerControl
+	newSchedulerControl = func(string) schedulerControl { return scheduler }
+	t.Cleanup(func() { newSchedulerControl = saved })
+	quiet(t)
+
+	if err := installReminder([]string{"--at", "18:00", "--days", "mon"}); err != nil {
+		t.Fatal(err)
+	}
+	timer := filepath.Join(home, ".config", "systemd", "user", "cali-remind.timer")
+	env := filepath.Join(home, ".config", "cali-logger", "remind.env")
+	content, err := os.ReadFile(env)
+	if err != nil || !strings.Contains(string(content), `CALI_SHEET_ID="sheet-for-timer"`) {
+		t.Fatalf("remind.env = %q, %v", content, err)
+	}
+	if info, err := os.Stat(env); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("remind.env mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+	if len(scheduler.enabled) != 1 || len(scheduler.enabled[0]) != 3 {
+		t.Fatalf("Enable calls = %d", len(scheduler.enabled))
+	}
+
+	// A reinstall replaces unedited files but keeps edited ones without
+	// --force.
+	if err := installReminder([]string{"--at", "19:00"}); err != nil {
+		t.Fatalf("reinstall: %v", err)
+	}
+	edited := strings.Replace(readFile(t, timer), "19:00", "20:00", 1)
+	if err := os.WriteFile(timer, []byte(edited), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := installReminder([]string{"--at", "21:00"}); exitCode(err) != exitUsage {
+		t.Fatalf("reinstall over an edited timer: %v, want a usage error", err)
+	}
+	if readFile(t, timer) != edited {
+		t.Error("edited timer overwritten without --force")
+	}
+	if err := installReminder([]string{"--at", "21:00", "--force"}); err != nil {
+		t.Fatalf("reinstall with --force: %v", err)
+	}
+	if !strings.Contains(readFile(t, timer), "*-*-* 21:00:00") {
+		t.Error("--force didn't rewrite the timer")
+	}
+
+	if err := uninstallReminder(); err != nil {
+		t.Fatal(err)
+	}
+	if len(scheduler.disabled) != 1 {
+		t.Errorf("Disable calls = %d, want 1", len(scheduler.disabled))
+	}
+	for _, path := range []string{timer, env} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s left after uninstall: %v", path, err)
+		}
+	}
+	if err := uninstallReminder(); err != nil || len(scheduler.disabled) != 1 {
+		t.Errorf("second uninstall: %v, %d Disable calls", err, len(scheduler.disabled))
+	}
+}
+
+func TestReportDue(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 18, 0, 0, 0, time.UTC)
+	t.Setenv("CALI_REPORT_EMAIL", "1")
+	if !reportDue(monday) || reportDue(monday.AddDate(0, 0, 1)) {
+		t.Error("the report is due on Mondays only")
+	}
+	t.Setenv("CALI_REPORT_EMAIL", "")
+	if reportDue(monday) {
+		t.Error("the report is due without CALI_REPORT_EMAIL")
+	}
+}
+
+// quiet silences progress and detail output for the rest of the test.
+func quiet(t *testing.T) {
+	saved := outputLevel
+	outputLevel = levelQuiet
+	t.Cleanup(func() { outputLevel = saved })
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// searchNumbers returns the comment cali -s prints after each number.
+func searchNumbers(t *testing.T, stdout string) map[string]string {
+	t.Helper()
+	numbers := map[string]string{}
+	for _, match := range regexp.MustCompile(`(?m)^\[(\d+)\] .* \| (\w+)$`).FindAllStringSubmatch(stdout, -1) {
+		numbers[match[1]] = match[2]
+	}
+	return numbers
+}
+
+// TestRemoveSearchedIndex logs three entries on one date, reads their
+// numbers from cali -s and removes number 2 with cali -r --index: exactly
+// the middle entry goes, on the local log and on a shared one.
+func TestRemoveSearchedIndex(t *testing.T) {
+	for _, user := range []string{"", "ziad"} {
+		t.Run("user="+user, func(t *testing.T) {
+			storage := pipedLog(t)
+			t.Setenv("CALI_USER", user)
+			home := t.TempDir()
+			date := currentTime().AddDate(0, 0, -1).Format(calio.DateLayout)
+			for _, comment := range []string{"first", "middle", "last"} {
+				if _, err := storage.Append(context.Background(), WorkoutEntry{Date: date, Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Comment: comment, User: user}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stdout, _, code := runCLIIn(t, home, "", "-s", date)
+			numbers := searchNumbers(t, stdout)
+			if code != 0 || numbers["1"] != "first" || numbers["2"] != "middle" || numbers["3"] != "last" {
+				t.Fatalf("cali -s %s exited %d, numbered %v", date, code, numbers)
+			}
+			if want := "Remove one with: cali -r --date " + date + " --index <number>\n"; !strings.HasSuffix(stdout, want) {
+				t.Errorf("cali -s ends with %q, want %q", stdout[strings.LastIndex(stdout[:len(stdout)-1], "\n")+1:], want)
+			}
+
+			if _, stderr, code := runCLIIn(t, home, "", "-r", "--date", date, "--index", "2", "--yes"); code != 0 {
+				t.Fatalf("cali -r --index 2 exited %d: %s", code, stderr)
+			}
+			var left []string
+			for _, entry := range logged(t, storage) {
+				left = append(left, entry.Comment)
+			}
+			if strings.Join(left, ",") != "first,last" {
+				t.Errorf("after removing [2] the log holds %q", left)
+			}
+			stdout, _, _ = runCLIIn(t, home, "", "-s", date)
+			if numbers := searchNumbers(t, stdout); len(numbers) != 2 || numbers["2"] != "last" {
+				t.Errorf("cali -s numbers the rest %v", numbers)
+			}
+		})
+	}
+}
+
+// TestRemoveByIndexCommand checks the confirmation and usage errors of
+// cali -r --date --index.
+func TestRemoveByIndexCommand(t *testing.T) {
+	storage := pipedLog(t)
+	home := t.TempDir()
+	date := currentTime().Format(calio.DateLayout)
+	for _, comment := range []string{"first", "second"} {
+		if _, err := storage.Append(context.Background(), WorkoutEntry{Date: date, Day: "A", Exercise: "Squats", Level: "Full", RepsSets: "20x2", Comment: comment}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		stdin string
+		args  []string
+		code  int
+		want  string
+	}{
+		{"", []string{"-r", "--index", "1"}, exitUsage, "usage: cali -r"},
+		{"", []string{"-r", "--date", date}, exitUsage, "usage: cali -r"},
+		{"", []string{"-r", "--date", date, "--index", "0"}, exitUsage, "usage: cali -r"},
+		{"", []string{"-r", "--yes"}, exitUsage, "usage: cali -r"},
+		{"", []string{"-r", "--date", date, "--index", "3", "--yes"}, exitUsage, "there is no entry 3 on " + date + " (2 listed by cali -s)"},
+		{"", []string{"-r", "--date", "someday", "--index", "1"}, exitUsage, "someday"},
+		{"", []string{"-r", "--date", "2020-01-01", "--index", "1", "--yes"}, 0, ""},
+		{"n\n", []string{"-r", "--date", date, "--index", "1"}, exitCancelled, "Cancelled"},
+		{"", []string{"-r", "--date", date, "--index", "1"}, exitCancelled, ""},
+	} {
+		stdout, stderr, code := runCLIIn(t, home, tc.stdin, tc.args...)
+		if code != tc.code || !strings.Contains(stdout+stderr, tc.want) {
+			t.Errorf("cali %s exited %d: %s%s", strings.Join(tc.args, " "), code, stdout, stderr)
+		}
+	}
+	if entries := logged(t, storage); len(entries) != 2 {
+		t.Fatalf("a refused removal left %d entries", len(entries))
+	}
+
+	stdout, stderr, code := runCLIIn(t, home, "y\n", "-r", "--date", date, "--index", "2")
+	if code != 0 || !strings.Contains(stdout, "Comment: second") {
+		t.Errorf("cali -r --index 2 confirmed exited %d: %s%s", code, stdout, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 1 || entries[0].Comment != "first" {
+		t.Errorf("cali -r --index 2 left %+v", entries)
+	}
+}
+
+// TestEntriesOnDate checks the numbers follow SearchByDate's order, the
+// order RemoveByDateIndex counts in.
+func TestEntriesOnDate(t *testing.T) {
+	storage := calio.NewFileStorage(t.TempDir())
+	ctx := context.Background()
+	other := WorkoutEntry{Date: "2026-03-05", Day: "B", Exercise: "Pullups", Level: "Full", RepsSets: "8x2"}
+	for _, entry := range []WorkoutEntry{
+		{Date: "2026-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2"},
+		other,
+		{Date: "2026-03-04", Day: "A", Exercise: "Squats", Level: "Full", RepsSets: "20x2"},
+	} {
+		if _, err := storage.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	numbered, err := entriesOnDate(ctx, storage, "2026-03-04")
+	if err != nil || len(numbered) != 2 {
+		t.Fatalf("entriesOnDate = %+v, %v", numbered, err)
+	}
+	for i, want := range []string{"Pushups", "Squats"} {
+		if numbered[i].Number != i+1 || numbered[i].Entry.Exercise != want {
+			t.Errorf("number %d is %+v, want %s", i+1, numbered[i], want)
+		}
+	}
+	if numbered, err := entriesOnDate(ctx, storage, "2026-03-06"); err != nil || len(numbered) != 0 {
+		t.Errorf("entriesOnDate of an empty date = %+v, %v", numbered, err)
+	}
+}
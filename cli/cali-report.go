@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// weeklyReport is the recap behind cali report: the stats of one period,
+// by default the last full Monday–Sunday week.
+type weeklyReport struct {
+	Since, Until string
+	Days         int // distinct training dates, mobility included
+	Stats        trainingStats
+	Best         []WorkoutEntry // best working set per exercise and level, in dataset order
+	Completed    []WorkoutEntry // levels whose goal was first reached in the period, oldest first
+}
+
+// reportRow is one label/value line of the report, shared by the text and
+// HTML renderings.
+type reportRow struct {
+	Label, Value string
+}
+
+type reportSection struct {
+	Title string
+	Rows  []reportRow
+}
+
+// lastWeek returns the Monday–Sunday week before the one now falls in.
+func lastWeek(now time.Time) dateRange {
+	today := truncateToDate(now)
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	return dateRange{
+		Since: monday.AddDate(0, 0, -7).Format(calio.DateLayout),
+		Until: monday.AddDate(0, 0, -1).Format(calio.DateLayout),
+	}
+}
+
+func buildReport(entries []WorkoutEntry, rng dateRange, now time.Time) weeklyReport {
+	report := weeklyReport{Since: rng.Since, Until: rng.Until, Stats: computeStats(entries, now)}
+	dates := map[string]bool{}
+	for _, entry := range entries {
+		dates[entry.Date] = true
+	}
+	report.Days = len(dates)
+
+	strength, _ := splitByCategory(entries)
+	records := personalRecords(strength)
+	for _, exercise := range statsExercises(report.Stats) {
+		for _, level := range calio.Levels(exercise) {
+			if record, ok := records[exerciseLevel{exercise, level}]; ok {
+				report.Best = append(report.Best, record)
+			}
+		}
+	}
+	return report
+}
+
+func (r weeklyReport) empty() bool {
+	return r.Stats.Total == 0 && r.Stats.Mobility == 0
+}
+
+func (r weeklyReport) title() string {
+	return msg("report.title", displayDate(r.Since), displayDate(r.Until))
+}
+
+func (r weeklyReport) sections() []reportSection {
+	if r.empty() {
+		return nil
+	}
+	summary := reportSection{Rows: []reportRow{
+		{msg("report.days"), strconv.Itoa(r.Days)},
+		{msg("report.workouts"), strconv.Itoa(r.Stats.Total)},
+		{msg("report.goals_met"), strconv.Itoa(r.Stats.GoalsMet)},
+		{msg("report.reps"), strconv.Itoa(r.Stats.TotalReps)},
+		{msg("report.hold"), fmt.Sprintf("%.1f min", r.Stats.HoldTime.minutes())},
+	}}
+	if r.Stats.Mobility > 0 {
+		summary.Rows = append(summary.Rows, reportRow{msg("report.mobility"), strconv.Itoa(r.Stats.Mobility)})
+	}
+	sections := []reportSection{summary}
+
+	if len(r.Stats.PerExercise) > 0 {
+		perExercise := reportSection{Title: msg("report.per_exercise")}
+		for _, exercise := range statsExercises(r.Stats) {
+			perExercise.Rows = append(perExercise.Rows, reportRow{exercise, strconv.Itoa(r.Stats.PerExercise[exercise])})
+		}
+		sections = append(sections, perExercise)
+	}
+	if len(r.Best) > 0 {
+		best := reportSection{Title: msg("report.best")}
+		for _, entry := range r.Best {
+			best.Rows = append(best.Rows, reportRow{entry.Exercise + " - " + entry.Level, loggedWork(entry)})
+		}
+		sections = append(sections, best)
+	}
+	if len(r.Completed) > 0 {
+		completed := reportSection{Title: msg("report.completed")}
+		for _, entry := range r.Completed {
+			completed.Rows = append(completed.Rows, reportRow{entry.Exercise + " - " + entry.Level, displayDate(entry.Date)})
+		}
+		sections = append(sections, completed)
+	}
+	return sections
+}
+
+// writeText renders the plain-text report, the body of the email and what
+// cali report prints.
+func (r weeklyReport) writeText(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(r.title() + "\n")
+	b.WriteString(strings.Repeat("-", 40) + "\n")
+	if r.empty() {
+		b.WriteString(msg("report.nothing") + "\n")
+	}
+	for _, section := range r.sections() {
+		indent := ""
+		if section.Title != "" {
+			b.WriteString("\n" + section.Title + "\n")
+			indent = "  "
+		}
+		for _, row := range section.Rows {
+			fmt.Fprintf(&b, "%s%-22s %s\n", indent, row.Label, row.Value)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdown renders the report as Markdown, e.g. for a training journal.
+func (r weeklyReport) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("## " + r.title() + "\n")
+	if r.empty() {
+		b.WriteString("\n" + msg("report.nothing") + "\n")
+	}
+	for _, section := range r.sections() {
+		b.WriteString("\n")
+		if section.Title != "" {
+			b.WriteString("### " + section.Title + "\n\n")
+		}
+		for _, row := range section.Rows {
+			fmt.Fprintf(&b, "- %s: %s\n", markdownEscaper.Replace(row.Label), markdownEscaper.Replace(row.Value))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var reportHTML = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html><body style="font-family: sans-serif">
+<h2>{{.Title}}</h2>
+{{if .Empty}}<p>{{.Nothing}}</p>
+{{end}}{{range .Sections}}{{if .Title}}<h3>{{.Title}}</h3>
+{{end}}<table>
+{{range .Rows}}<tr><td style="padding-right: 1.5em">{{.Label}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{end}}</body></html>
+`))
+
+// writeHTML renders the HTML alternative of the email.
+func (r weeklyReport) writeHTML(w io.Writer) error {
+	return reportHTML.Execute(w, struct {
+		Title, Nothing string
+		Empty          bool
+		Sections       []reportSection
+	}{r.title(), msg("report.nothing"), r.empty(), r.sections()})
+}
+
+// reportOptions are the flags of cali report.
+type reportOptions struct {
+	Email          bool
+	SendEmpty      bool
+	IncludePrivate bool
+	Markdown       bool
+}
+
+// newReportFlagSet declares the flags of cali report into opts.
+func newReportFlagSet(opts *reportOptions) *flag.FlagSet {
+	fs := newFlagSet("report")
+	fs.BoolVar(&opts.Email, "email", false, "send the report by email (CALI_SMTP_* settings) instead of printing it")
+	fs.BoolVar(&opts.SendEmpty, "send-empty", false, "email the report even when nothing was logged")
+	fs.BoolVar(&opts.IncludePrivate, "include-private", false, "keep comments marked private instead of showing [redacted]")
+	fs.BoolVar(&opts.Markdown, "markdown", false, "print the report as Markdown")
+	return fs
+}
+
+func runReport(ctx context.Context, args []string, rng dateRange) error {
+	var opts reportOptions
+	fs := newReportFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 || (opts.Markdown && opts.Email) {
+		return usageError("usage: cali report [--email [--send-empty] | --markdown] [--include-private] [--since <date>] [--until <date>]")
+	}
+
+	var smtpCfg smtpConfig
+	if opts.Email {
+		// Check the settings before touching storage, so a typo fails fast.
+		var err error
+		if smtpCfg, err = loadSMTPConfig(os.Getenv); err != nil {
+			return usageError("%v", err)
+		}
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	if !opts.Email {
+		report, err := loadReport(ctx, storage, rng, opts.IncludePrivate)
+		if err != nil {
+			return err
+		}
+		write := report.writeText
+		if opts.Markdown {
+			write = report.writeMarkdown
+		}
+		if err := write(os.Stdout); err != nil {
+			return err
+		}
+		if report.empty() {
+			return errNoResults
+		}
+		return nil
+	}
+	return emailReport(ctx, storage, smtpCfg, rng, opts)
+}
+
+// loadReport reads the entries of rng, the last full week when rng is unset,
+// with private comments redacted unless includePrivate is set.
+func loadReport(ctx context.Context, storage Storage, rng dateRange, includePrivate bool) (weeklyReport, error) {
+	now := currentTime()
+	if !rng.isSet() {
+		rng = lastWeek(now)
+	} else if rng.Until == "" {
+		rng.Until = now.Format(calio.DateLayout)
+	}
+	entries, err := storage.Range(ctx, rng.Since, rng.Until)
+	if err != nil {
+		return weeklyReport{}, storageError("reading workout history", err)
+	}
+	entries = shareableEntries(calio.WithoutFuture(entries, now), includePrivate)
+	report := buildReport(entries, rng, now)
+
+	// Whether a level was completed in the period depends on what came
+	// before it, so this reads the log up to its end.
+	history, err := storage.Range(ctx, "", rng.Until)
+	if err != nil {
+		return weeklyReport{}, storageError("reading workout history", err)
+	}
+	for _, entry := range achievements(history) {
+		if entry.Date >= rng.Since {
+			report.Completed = append(report.Completed, entry)
+		}
+	}
+	return report, nil
+}
+
+// emailReport sends the report for rng. An empty period is logged and
+// skipped unless opts.SendEmpty is set; it returns errNoResults, so the
+// command exits 0 unless --fail-empty was given.
+func emailReport(ctx context.Context, storage Storage, cfg smtpConfig, rng dateRange, opts reportOptions) error {
+	report, err := loadReport(ctx, storage, rng, opts.IncludePrivate)
+	if err != nil {
+		return err
+	}
+	if report.empty() && !opts.SendEmpty {
+		fmt.Println(msg("report.skipped", displayDate(report.Since), displayDate(report.Until)))
+		return errNoResults
+	}
+
+	var text, html strings.Builder
+	if err := report.writeText(&text); err != nil {
+		return err
+	}
+	if err := report.writeHTML(&html); err != nil {
+		return err
+	}
+	message, err := buildMail(cfg, report.title(), text.String(), html.String(), currentTime())
+	if err != nil {
+		return err
+	}
+	if err := sendMail(ctx, cfg, message); err != nil {
+		return &cliError{code: exitStorage, err: fmt.Errorf("sending the report: %w", err)}
+	}
+	say(msg("report.sent", strings.Join(cfg.To, ", ")))
+	return nil
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// repsSets is a parsed Reps×Sets value. Rep-based work lists the reps of each
+// set in Sets; timed holds (e.g. "2min" or "90s x2") list each hold in Holds
+// instead. Interval protocols also set Protocol and Duration and list one set
+// per round.
+type repsSets struct {
+	Sets     []int
+	Holds    []holdTime
+	Protocol string
+	Duration int
+}
+
+func (r repsSets) timed() bool {
+	return len(r.Holds) > 0
+}
+
+func (r repsSets) interval() bool {
+	return r.Protocol != ""
+}
+
+// totalReps is the volume of the work; for intervals that is rounds × reps.
+func (r repsSets) totalReps() int {
+	total := 0
+	for _, reps := range r.Sets {
+		total += reps
+	}
+	return total
+}
+
+// longestHold is the best single hold of timed work.
+func (r repsSets) longestHold() holdTime {
+	var longest holdTime
+	for _, hold := range r.Holds {
+		longest = max(longest, hold)
+	}
+	return longest
+}
+
+// totalHold is the time under tension of timed work.
+func (r repsSets) totalHold() holdTime {
+	var total holdTime
+	for _, hold := range r.Holds {
+		total += hold
+	}
+	return total
+}
+
+// parseRepsSets understands the formats used in goals and in logged entries:
+// "20x2" (also "20×2" and "20 x 2"), ranges such as "10-30x2" (the upper bound
+// counts), per-set lists like "8,7,6" or "8/7/6", holds like "2min", "90s",
+// "1:30" or "2min x2" (also as lists, "90s,60s"), and interval protocols like
+// "EMOM 10min @ 12". A bare number is always reps, never a hold. A cell the
+// spreadsheet turned into a date or time (see calio.CoercedWork) is not
+// work, even where it would read as a list of reps.
+func parseRepsSets(input string) (repsSets, bool) {
+	if calio.CoercedWork(input) {
+		return repsSets{}, false
+	}
+	value := strings.ToLower(strings.TrimSpace(input))
+	value = strings.ReplaceAll(value, "×", "x")
+	value = strings.ReplaceAll(value, " ", "")
+	if value == "" {
+		return repsSets{}, false
+	}
+
+	if strings.Contains(value, "@") {
+		return parseInterval(value)
+	}
+
+	if hold, ok := parseHold(value); ok {
+		return repsSets{Holds: []holdTime{hold}}, true
+	}
+
+	if reps, sets, found := strings.Cut(value, "x"); found {
+		count, err := strconv.Atoi(sets)
+		if err != nil || count < 1 {
+			return repsSets{}, false
+		}
+		if hold, ok := parseHold(reps); ok {
+			result := repsSets{Holds: make([]holdTime, count)}
+			for i := range result.Holds {
+				result.Holds[i] = hold
+			}
+			return result, true
+		}
+		perSet, ok := parseRepCount(reps)
+		if !ok {
+			return repsSets{}, false
+		}
+		result := repsSets{Sets: make([]int, count)}
+		for i := range result.Sets {
+			result.Sets[i] = perSet
+		}
+		return result, true
+	}
+
+	var result repsSets
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '/' }) {
+		if hold, ok := parseHold(part); ok {
+			result.Holds = append(result.Holds, hold)
+			continue
+		}
+		reps, err := strconv.Atoi(part)
+		if err != nil || reps < 0 {
+			return repsSets{}, false
+		}
+		result.Sets = append(result.Sets, reps)
+	}
+	if len(result.Sets) == 0 && len(result.Holds) == 0 {
+		return repsSets{}, false
+	}
+	if len(result.Sets) > 0 && len(result.Holds) > 0 {
+		return repsSets{}, false
+	}
+	return result, true
+}
+
+// normalizeRepsSets rewrites a logged value in its canonical form, e.g.
+// "20 × 2" as "20x2" or "1:30" as "90s". Ranges and unparseable values are
+// kept as typed.
+func normalizeRepsSets(input string) string {
+	value := strings.TrimSpace(input)
+	parsed, ok := parseRepsSets(value)
+	if !ok || strings.Contains(value, "-") {
+		return value
+	}
+	return formatRepsSets(parsed)
+}
+
+func parseRepCount(value string) (int, bool) {
+	if low, high, found := strings.Cut(value, "-"); found {
+		if _, err := strconv.Atoi(low); err != nil {
+			return 0, false
+		}
+		value = high
+	}
+	reps, err := strconv.Atoi(value)
+	if err != nil || reps < 0 {
+		return 0, false
+	}
+	return reps, true
+}
+
+// meetsGoal reports whether a logged Reps×Sets value reaches the goal. The
+// best sets (or holds, for timed goals) are compared against the goal set by
+// set, so "8,10,10" meets "10x2" and "90s,2min" meets "2min". Reps never meet
+// a timed goal or the other way round. Unparseable values never meet a goal.
+// Progression standards are bodyweight standards, so a load never counts.
+func meetsGoal(logged, goal string) bool {
+	done, ok := parseRepsSets(logged)
+	if !ok {
+		return false
+	}
+	target, ok := parseRepsSets(goal)
+	if !ok {
+		return false
+	}
+
+	if target.timed() != done.timed() {
+		return false
+	}
+	if target.timed() {
+		return bestSetsReach(holdSeconds(done.Holds), holdSeconds(target.Holds))
+	}
+	return bestSetsReach(done.Sets, target.Sets)
+}
+
+// goalPercent is how much of the goal the logged value covers, in whole
+// percent: total reps against the goal's total (the upper bound of a range
+// such as "10-30x2"), or total seconds held against the goal's for timed
+// goals. It can exceed 100. The bool is false when either value doesn't
+// parse or they don't measure the same thing.
+func goalPercent(logged, goal string) (int, bool) {
+	have, want, ok := goalAmounts(logged, goal)
+	if !ok {
+		return 0, false
+	}
+	return int(math.Round(float64(have) * 100 / float64(want))), true
+}
+
+// goalAmounts returns the logged and goal totals goalPercent compares: reps,
+// or seconds held for timed goals. want is always above zero.
+func goalAmounts(logged, goal string) (have, want int, ok bool) {
+	done, ok := parseRepsSets(logged)
+	if !ok {
+		return 0, 0, false
+	}
+	target, ok := parseRepsSets(goal)
+	if !ok || target.timed() != done.timed() {
+		return 0, 0, false
+	}
+
+	have, want = done.totalReps(), target.totalReps()
+	if target.timed() {
+		have, want = int(done.totalHold()), int(target.totalHold())
+	}
+	if want <= 0 {
+		return 0, 0, false
+	}
+	return have, want, true
+}
+
+// bestSetsReach reports whether the largest values of done reach each value
+// of target.
+func bestSetsReach(done, target []int) bool {
+	if len(done) < len(target) {
+		return false
+	}
+	best := append([]int(nil), done...)
+	sort.Sort(sort.Reverse(sort.IntSlice(best)))
+	for i, want := range target {
+		if best[i] < want {
+			return false
+		}
+	}
+	return true
+}
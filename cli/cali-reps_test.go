@@ -0,0 +1,44 @@
+package cli
+
+import "testing"
+
+func TestGoalPercent(t *testing.T) {
+	tests := []struct {
+		logged, goal string
+		want         int
+		ok           bool
+	}{
+		{"10x2", "20x2", 50, true},
+		{"25x2", "20x2", 125, true},
+		{"8,7,6", "10x3", 70, true},
+		{"10x2", "10-30x2", 33, true}, // the top of a range
+		{"90s", "2min", 75, true},
+		{"2min x2", "1min", 400, true},
+		{"90s", "20x2", 0, false},
+		{"20x2", "1min", 0, false},
+		{"lots", "20x2", 0, false},
+		{"20x2", "", 0, false},
+		{"5", "0x1", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := goalPercent(tt.logged, tt.goal)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("goalPercent(%q, %q) = %d, %v, want %d, %v", tt.logged, tt.goal, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestParseCoercedWork checks work a spreadsheet turned into a date or time
+// isn't read as reps, though "10/30/2026" would pass for a list of sets.
+func TestParseCoercedWork(t *testing.T) {
+	for _, value := range []string{"10/30/2026", "30.10.2026", "46325", "0.0625", "1:30:00 AM", "Oct-30"} {
+		if parsed, ok := parseRepsSets(value); ok {
+			t.Errorf("parseRepsSets(%q) = %+v", value, parsed)
+		}
+	}
+	for _, value := range []string{"10-30x2", "8/7/6", "1:30", "12"} {
+		if _, ok := parseRepsSets(value); !ok {
+			t.Errorf("parseRepsSets(%q) failed", value)
+		}
+	}
+}
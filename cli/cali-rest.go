@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// restMark flags rest days in listings.
+const restMark = "☾ "
+
+// defaultRestPerWeek is how many rest days a week keep a streak going
+// unless CALI_REST_PER_WEEK says otherwise: four leaves the three sessions
+// of the A/B/C rotation.
+const defaultRestPerWeek = 4
+
+// restPerWeek returns CALI_REST_PER_WEEK.
+func restPerWeek() (int, error) {
+	raw := strings.TrimSpace(os.Getenv("CALI_REST_PER_WEEK"))
+	if raw == "" {
+		return defaultRestPerWeek, nil
+	}
+	perWeek, err := strconv.Atoi(raw)
+	if err != nil || perWeek < 0 || perWeek > 7 {
+		return 0, fmt.Errorf("invalid CALI_REST_PER_WEEK %q (use 0 to 7 days)", raw)
+	}
+	return perWeek, nil
+}
+
+// trainedDates returns the dates with any entry, strength or mobility.
+func trainedDates(entries []WorkoutEntry) map[string]bool {
+	dates := map[string]bool{}
+	for _, entry := range entries {
+		dates[entry.Date] = true
+	}
+	return dates
+}
+
+// plannedRest returns the rest days that were kept: a workout logged on a
+// rest day's date counts over the marker, and one date counts once.
+func plannedRest(days []calio.RestDay, trained map[string]bool) []calio.RestDay {
+	seen := map[string]bool{}
+	var kept []calio.RestDay
+	for _, day := range days {
+		if trained[day.Date] || seen[day.Date] {
+			continue
+		}
+		seen[day.Date] = true
+		kept = append(kept, day)
+	}
+	return kept
+}
+
+// streak is a run of consecutive days ending today, each trained or a
+// planned rest day.
+type streak struct {
+	Trained int
+	Rested  int
+}
+
+func (s streak) days() int {
+	return s.Trained + s.Rested
+}
+
+// currentStreak counts back from today, or from yesterday while today has
+// nothing logged yet. A day without a workout or a rest marker ends the
+// streak, and so does a rest day beyond perWeek in its Monday-to-Sunday
+// week. A streak needs at least one trained day.
+func currentStreak(trained map[string]bool, rest []calio.RestDay, today time.Time, perWeek int) streak {
+	rested := map[string]bool{}
+	for _, day := range plannedRest(rest, trained) {
+		rested[day.Date] = true
+	}
+
+	day := truncateToDate(today)
+	if date := day.Format(calio.DateLayout); !trained[date] && !rested[date] {
+		day = day.AddDate(0, 0, -1)
+	}
+	var s streak
+	restsInWeek := map[string]int{}
+	for {
+		date := day.Format(calio.DateLayout)
+		if trained[date] {
+			s.Trained++
+		} else if rested[date] {
+			week := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)).Format(calio.DateLayout)
+			if restsInWeek[week] >= perWeek {
+				break
+			}
+			restsInWeek[week]++
+			s.Rested++
+		} else {
+			break
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	if s.Trained == 0 {
+		return streak{}
+	}
+	return s
+}
+
+// restReasons renders rest days by reason, e.g. "travel 2, sick 1", most
+// frequent first; days without a reason are left out.
+func restReasons(days []calio.RestDay) string {
+	counts := map[string]int{}
+	for _, day := range days {
+		if day.Reason != "" {
+			counts[day.Reason]++
+		}
+	}
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%s %d", reason, counts[reason])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// readRestDays returns storage's rest days within rng, up to today; storage
+// without a rest log has none.
+func readRestDays(ctx context.Context, storage Storage, rng dateRange, today string) ([]calio.RestDay, error) {
+	rest, ok := storage.(calio.RestLog)
+	if !ok {
+		return nil, nil
+	}
+	days, err := rest.RestDays(ctx, rng.Since, rng.Until)
+	if errors.Is(err, calio.ErrNoRestLog) {
+		return nil, nil
+	}
+	var past []calio.RestDay
+	for _, day := range days {
+		if day.Date <= today {
+			past = append(past, day)
+		}
+	}
+	return past, err
+}
+
+// restOptions are the flags of cali rest.
+type restOptions struct {
+	Reason string
+	List   bool
+	// Date marks another day than today, e.g. one ahead for cali plan.
+	Date string
+}
+
+// newRestFlagSet declares the flags of cali rest into opts.
+func newRestFlagSet(opts *restOptions) *flag.FlagSet {
+	fs := newFlagSet("rest")
+	fs.StringVar(&opts.Reason, "reason", "", "why, e.g. travel or sick")
+	fs.BoolVar(&opts.List, "list", false, "list rest days instead of adding one")
+	fs.StringVar(&opts.Date, "date", "", "mark this date instead of today, e.g. 2026-10-24 to plan ahead")
+	return fs
+}
+
+func runRest(ctx context.Context, args []string, rng dateRange) error {
+	var opts restOptions
+	fs := newRestFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 || (rng.isSet() && !opts.List) || (opts.List && (opts.Reason != "" || opts.Date != "")) {
+		return usageError("usage: cali rest [--reason <text>] [--date <date>] or cali rest --list [--since <date>] [--until <date>]")
+	}
+	today := currentTime().Format(calio.DateLayout)
+	date := today
+	if opts.Date != "" {
+		var err error
+		if date, err = userDate(opts.Date); err != nil {
+			return err
+		}
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	rest, ok := storage.(calio.RestLog)
+	if !ok {
+		return storageError("recording rest days", calio.ErrNoRestLog)
+	}
+	if opts.List {
+		return listRestDays(ctx, storage, rng)
+	}
+
+	entries, err := storage.SearchByDate(ctx, date)
+	if err != nil {
+		return storageError("reading the day's workouts", err)
+	}
+	if len(entries) > 0 {
+		if date == today {
+			fmt.Println(msg("rest.trained"))
+		} else {
+			fmt.Print(msg("rest.trained_on", displayDate(date)))
+		}
+		return nil
+	}
+	days, err := rest.RestDays(ctx, date, date)
+	if err != nil {
+		return storageError("reading rest days", err)
+	}
+	if len(days) > 0 {
+		if date == today {
+			sayln(msg("rest.already"))
+		} else {
+			say(msg("rest.already_on", displayDate(date)))
+		}
+		return nil
+	}
+
+	if err := rest.AddRest(ctx, calio.RestDay{Date: date, Reason: strings.TrimSpace(opts.Reason)}); err != nil {
+		return storageError("saving the rest day", err)
+	}
+	say(msg("rest.saved", displayDate(date)))
+	return nil
+}
+
+func listRestDays(ctx context.Context, storage Storage, rng dateRange) error {
+	days, err := readRestDays(ctx, storage, rng, "9999-12-31")
+	if err != nil {
+		return storageError("reading rest days", err)
+	}
+	if len(days) == 0 {
+		fmt.Println(msg("rest.none"))
+		return errNoResults
+	}
+	entries, err := storage.Range(ctx, days[0].Date, days[len(days)-1].Date)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	trained := trainedDates(entries)
+	for _, day := range days {
+		line := restMark + displayDate(day.Date)
+		if day.Reason != "" {
+			line += "  " + day.Reason
+		}
+		if trained[day.Date] {
+			line += "  " + msg("rest.superseded")
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
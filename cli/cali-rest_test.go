@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestPlannedRest(t *testing.T) {
+	days := []calio.RestDay{{Date: "2026-03-02"}, {Date: "2026-03-03"}, {Date: "2026-03-03", Reason: "twice"}, {Date: "2026-03-04"}}
+	kept := plannedRest(days, map[string]bool{"2026-03-04": true})
+	if !slices.Equal(kept, days[:2]) {
+		t.Errorf("plannedRest = %+v, want the 2nd and 3rd once and the 4th, trained, left out", kept)
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	trained := map[string]bool{}
+	for _, date := range []string{"2026-03-02", "2026-03-04", "2026-03-06", "2026-03-09"} {
+		trained[date] = true
+	}
+	rest := []calio.RestDay{{Date: "2026-03-03"}, {Date: "2026-03-05"}, {Date: "2026-03-07"}, {Date: "2026-03-08"}}
+	monday := time.Date(2026, 3, 9, 20, 0, 0, 0, time.UTC) // 2026-03-02 is a Monday too
+	tests := []struct {
+		name    string
+		today   time.Time
+		perWeek int
+		want    streak
+	}{
+		{"rest days kept", monday, 4, streak{Trained: 4, Rested: 4}},
+		{"today not logged yet", monday.AddDate(0, 0, 1), 4, streak{Trained: 4, Rested: 4}},
+		{"a day missed", monday.AddDate(0, 0, 2), 4, streak{}},
+		// The week of the 2nd allows three rests: the 3rd breaks it.
+		{"too many rests in a week", monday, 3, streak{Trained: 3, Rested: 3}},
+		{"no rests allowed", monday, 0, streak{Trained: 1}},
+		{"rest alone is no streak", time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC), 0, streak{}},
+	}
+	for _, tt := range tests {
+		if got := currentStreak(trained, rest, tt.today, tt.perWeek); got != tt.want {
+			t.Errorf("%s: currentStreak = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRestReasons(t *testing.T) {
+	days := []calio.RestDay{{Reason: "sick"}, {Reason: "travel"}, {}, {Reason: "travel"}, {Reason: "family"}}
+	if got := restReasons(days); got != "travel 2, family 1, sick 1" {
+		t.Errorf("restReasons = %q", got)
+	}
+}
+
+func TestRestPerWeek(t *testing.T) {
+	for value, want := range map[string]int{"": defaultRestPerWeek, "0": 0, " 2 ": 2, "7": 7, "8": -1, "two": -1} {
+		t.Setenv("CALI_REST_PER_WEEK", value)
+		got, err := restPerWeek()
+		if want < 0 && err == nil || want >= 0 && (err != nil || got != want) {
+			t.Errorf("CALI_REST_PER_WEEK=%q: restPerWeek = %d, %v", value, got, err)
+		}
+	}
+}
+
+// TestRestCommand marks rest days around a workout and checks a workout on
+// the date counts over a marker.
+func TestRestCommand(t *testing.T) {
+	storage := pipedLog(t)
+	today := currentTime()
+	yesterday := today.AddDate(0, 0, -1).Format(calio.DateLayout)
+
+	if stdout, stderr, code := runCLI(t, "", "rest", "--date", yesterday, "--reason", "travel"); code != 0 || !strings.Contains(stdout, "marked as a rest day") {
+		t.Fatalf("cali rest --date yesterday exited %d, printed %q %s", code, stdout, stderr)
+	}
+	if stdout, _, _ := runCLI(t, "", "rest", "--date", yesterday); !strings.Contains(stdout, "is already marked as a rest day") {
+		t.Errorf("marking yesterday again printed %q", stdout)
+	}
+	if _, stderr, code := runCLI(t, "", "log", "--exercise", "pushups", "--level", "full", "--reps", "10x2"); code != 0 {
+		t.Fatalf("cali log exited %d: %s", code, stderr)
+	}
+	if stdout, _, _ := runCLI(t, "", "rest"); !strings.Contains(stdout, "already logged a workout today") {
+		t.Errorf("cali rest after a workout printed %q", stdout)
+	}
+	if days, err := storage.RestDays(context.Background(), "", ""); err != nil || len(days) != 1 || days[0].Reason != "travel" {
+		t.Errorf("the rest days are %+v, %v, want yesterday's only", days, err)
+	}
+
+	stdout, _, _ := runCLI(t, "", "rest", "--list")
+	if !strings.Contains(stdout, "travel") {
+		t.Errorf("cali rest --list printed %q", stdout)
+	}
+	stdout, _, _ = runCLI(t, "", "stats")
+	if !strings.Contains(stdout, "Planned rest days:   1 (travel 1)") || !strings.Contains(stdout, "Current streak:      2 day(s) (1 trained, 1 rest)") {
+		t.Errorf("cali stats printed %q, want the rest day and a streak counting it", stdout)
+	}
+	if _, _, code := runCLI(t, "", "rest", "--list", "--reason", "x"); code != exitUsage {
+		t.Errorf("cali rest --list --reason exited %d, want %d", code, exitUsage)
+	}
+}
@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"debug/buildinfo"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// modulePath identifies cali binaries by the main module Go records in them.
+const modulePath = "github.com/ziad73/cali-logger"
+
+// selfEnv is what cali self install looks at to choose a directory, so each
+// GOOS can be checked with an injected environment.
+type selfEnv struct {
+	goos     string
+	getenv   func(string) string
+	homeDir  string
+	writable func(dir string) bool
+}
+
+func currentSelfEnv() (selfEnv, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return selfEnv{}, err
+	}
+	return selfEnv{goos: runtime.GOOS, getenv: os.Getenv, homeDir: homeDir, writable: dirWritable}, nil
+}
+
+// binaryName is the installed file name on env's OS.
+func (env selfEnv) binaryName() string {
+	if env.goos == "windows" {
+		return "cali.exe"
+	}
+	return "cali"
+}
+
+// installDir picks where cali self install puts the binary:
+// %LOCALAPPDATA%\Programs\cali on Windows, /usr/local/bin on macOS when it
+// can be written without sudo (it is on the default PATH there), and
+// ~/.local/bin otherwise.
+func (env selfEnv) installDir() string {
+	switch env.goos {
+	case "windows":
+		local := env.getenv("LOCALAPPDATA")
+		if local == "" {
+			local = filepath.Join(env.homeDir, "AppData", "Local")
+		}
+		return filepath.Join(local, "Programs", "cali")
+	case "darwin":
+		if env.writable("/usr/local/bin") {
+			return "/usr/local/bin"
+		}
+	}
+	return filepath.Join(env.homeDir, ".local", "bin")
+}
+
+// onPath reports whether dir is one of the directories in PATH. Windows
+// compares case-insensitively and ignores trailing separators.
+func (env selfEnv) onPath(dir string) bool {
+	separator := ":"
+	if env.goos == "windows" {
+		separator = ";"
+	}
+	clean := func(path string) string {
+		path = strings.TrimRight(strings.Trim(strings.TrimSpace(path), `"`), `/\`)
+		if env.goos == "windows" {
+			return strings.ToLower(strings.ReplaceAll(path, "/", `\`))
+		}
+		return path
+	}
+	for _, entry := range strings.Split(env.getenv("PATH"), separator) {
+		if entry != "" && clean(entry) == clean(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathHint is the command that adds dir to PATH for the current user.
+func (env selfEnv) pathHint(dir string) string {
+	if env.goos == "windows" {
+		return fmt.Sprintf(`[Environment]::SetEnvironmentVariable("Path", [Environment]::GetEnvironmentVariable("Path", "User") + ";%s", "User")`, dir)
+	}
+	if home := env.homeDir; home != "" && strings.HasPrefix(dir, home+string(filepath.Separator)) {
+		dir = "$HOME" + strings.TrimPrefix(dir, home)
+	}
+	return fmt.Sprintf(`export PATH="%s:$PATH"`, dir)
+}
+
+// profileFile is where pathHint's line goes on env's OS.
+func (env selfEnv) profileFile() string {
+	switch env.goos {
+	case "windows":
+		return "PowerShell"
+	case "darwin":
+		return "~/.zshrc"
+	}
+	return "~/.bashrc"
+}
+
+func dirWritable(dir string) bool {
+	file, err := os.CreateTemp(dir, ".cali-write-check-*")
+	if err != nil {
+		return false
+	}
+	file.Close()
+	os.Remove(file.Name())
+	return true
+}
+
+// isCaliBinary reports whether path is a Go binary built from this module.
+// Other programs named cali, and files that aren't Go binaries, are not.
+func isCaliBinary(path string) bool {
+	info, err := buildinfo.ReadFile(path)
+	return err == nil && info.Main.Path == modulePath
+}
+
+// runningBinary returns the path of the running executable with symlinks
+// resolved, so installing from a symlink copies the real file.
+func runningBinary() (string, error) {
+	binary, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("locating the cali binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+	return binary, nil
+}
+
+func runSelf(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "install":
+			return selfInstall(args[1:])
+		case "uninstall":
+			return selfUninstall(args[1:])
+		}
+	}
+	return usageError("usage: cali self install|uninstall [--dir <path>] [--force]")
+}
+
+// selfOptions are the flags of cali self install and uninstall.
+type selfOptions struct {
+	Dir   string
+	Force bool
+}
+
+// newSelfFlagSet declares the flags of cali self name into opts.
+func newSelfFlagSet(name string, opts *selfOptions) *flag.FlagSet {
+	fs := newFlagSet("self " + name)
+	fs.StringVar(&opts.Dir, "dir", "", "directory to "+name+" cali in (default: per OS)")
+	fs.BoolVar(&opts.Force, "force", false, "replace or remove a file that isn't cali")
+	return fs
+}
+
+// selfFlags parses the flags both subcommands share and returns the target
+// binary path.
+func selfFlags(name string, args []string) (env selfEnv, target string, force bool, err error) {
+	var opts selfOptions
+	fs := newSelfFlagSet(name, &opts)
+	if err := fs.Parse(args); err != nil {
+		return selfEnv{}, "", false, flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return selfEnv{}, "", false, usageError("usage: cali self %s [--dir <path>] [--force]", name)
+	}
+	env, err = currentSelfEnv()
+	if err != nil {
+		return selfEnv{}, "", false, storageError("locating the home directory", err)
+	}
+	target = opts.Dir
+	if target == "" {
+		target = env.installDir()
+	}
+	if target, err = filepath.Abs(target); err != nil {
+		return selfEnv{}, "", false, usageError("--dir: %v", err)
+	}
+	return env, filepath.Join(target, env.binaryName()), opts.Force, nil
+}
+
+func selfInstall(args []string) error {
+	env, target, force, err := selfFlags("install", args)
+	if err != nil {
+		return err
+	}
+	source, err := runningBinary()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(target)
+
+	existing, statErr := os.Stat(target)
+	if sourceInfo, err := os.Stat(source); err == nil && statErr == nil && os.SameFile(sourceInfo, existing) {
+		sayln(msg("self.already", target))
+		return nil
+	}
+	if statErr == nil && !force && !isCaliBinary(target) {
+		return usageError("%s", msg("self.not_cali", target))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil || !dirWritable(dir) {
+		return usageError("%s", msg("self.no_permission", dir))
+	}
+	if err := copyExecutable(source, target); err != nil {
+		return storageError("installing cali", err)
+	}
+	say(msg("self.installed", target))
+	if !env.onPath(dir) {
+		fmt.Print(msg("self.not_on_path", dir, env.profileFile()))
+		fmt.Println("  " + env.pathHint(dir))
+	}
+	return nil
+}
+
+// copyExecutable copies source to a temporary file next to target and renames
+// it into place, so an interrupted copy never leaves a broken binary.
+func copyExecutable(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func selfUninstall(args []string) error {
+	env, target, force, err := selfFlags("uninstall", args)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(target); errors.Is(err, os.ErrNotExist) {
+		sayln(msg("self.not_installed", target))
+		return nil
+	}
+	if !force && !isCaliBinary(target) {
+		return usageError("%s", msg("self.not_cali", target))
+	}
+	if err := os.Remove(target); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return usageError("%s", msg("self.no_permission", filepath.Dir(target)))
+		}
+		return storageError("removing cali", err)
+	}
+	// The Windows directory is cali's own; leave others in place.
+	if env.goos == "windows" && filepath.Base(filepath.Dir(target)) == "cali" {
+		os.Remove(filepath.Dir(target))
+	}
+	say(msg("self.uninstalled", target))
+	return nil
+}
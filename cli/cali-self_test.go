@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// filepath.Join uses the host's separator, so the Windows cases below
+// expect forward slashes when the tests run elsewhere.
+
+func TestSelfInstallDir(t *testing.T) {
+	home := filepath.FromSlash("/home/ziad")
+	tests := []struct {
+		name     string
+		goos     string
+		env      map[string]string
+		writable bool
+		want     string
+	}{
+		{"linux", "linux", nil, true, "/home/ziad/.local/bin"},
+		{"freebsd", "freebsd", nil, true, "/home/ziad/.local/bin"},
+		{"macOS with a writable /usr/local/bin", "darwin", nil, true, "/usr/local/bin"},
+		{"macOS needing sudo for /usr/local/bin", "darwin", nil, false, "/home/ziad/.local/bin"},
+		{"windows", "windows", map[string]string{"LOCALAPPDATA": "C:/Users/ziad/AppData/Local"}, false, "C:/Users/ziad/AppData/Local/Programs/cali"},
+		{"windows without LOCALAPPDATA", "windows", nil, false, "/home/ziad/AppData/Local/Programs/cali"},
+	}
+	for _, tt := range tests {
+		env := selfEnv{
+			goos:     tt.goos,
+			getenv:   envOf(tt.env),
+			homeDir:  home,
+			writable: func(dir string) bool { return tt.writable && dir == "/usr/local/bin" },
+		}
+		if got := env.installDir(); got != filepath.FromSlash(tt.want) {
+			t.Errorf("%s: installDir = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := (selfEnv{goos: "windows"}).binaryName(); got != "cali.exe" {
+		t.Errorf("windows binaryName = %q", got)
+	}
+	if got := (selfEnv{goos: "darwin"}).binaryName(); got != "cali" {
+		t.Errorf("darwin binaryName = %q", got)
+	}
+}
+
+func TestSelfOnPath(t *testing.T) {
+	tests := []struct {
+		goos, path, dir string
+		want            bool
+	}{
+		{"linux", "/usr/bin:/home/ziad/.local/bin", "/home/ziad/.local/bin", true},
+		{"linux", "/usr/bin:/home/ziad/.local/bin/", "/home/ziad/.local/bin", true},
+		{"linux", "/usr/bin:/home/ziad/.local/bin", "/home/ziad/.local/bin/", true},
+		{"linux", "/usr/bin:/home/ziad/.local/binaries", "/home/ziad/.local/bin", false},
+		{"linux", "/usr/bin:/HOME/ziad/.local/bin", "/home/ziad/.local/bin", false},
+		{"linux", "/usr/bin;/home/ziad/.local/bin", "/home/ziad/.local/bin", false},
+		{"linux", "", "/home/ziad/.local/bin", false},
+		{"darwin", "/opt/homebrew/bin:/usr/local/bin", "/usr/local/bin", true},
+		{"windows", `C:\Windows;C:\Users\ziad\AppData\Local\Programs\cali`, `C:\Users\ziad\AppData\Local\Programs\cali`, true},
+		{"windows", `C:\Windows;c:\users\ziad\appdata\local\programs\CALI\`, `C:\Users\ziad\AppData\Local\Programs\cali`, true},
+		{"windows", `C:\Windows;"C:\Users\ziad\AppData\Local\Programs\cali"`, `C:\Users\ziad\AppData\Local\Programs\cali`, true},
+		{"windows", `C:\Windows;C:/Users/ziad/AppData/Local/Programs/cali`, `C:\Users\ziad\AppData\Local\Programs\cali`, true},
+		{"windows", `C:\Windows:C:\Users\ziad\AppData\Local\Programs\cali`, `C:\Users\ziad\AppData\Local\Programs\cali`, false},
+		{"windows", `C:\Windows`, `C:\Users\ziad\AppData\Local\Programs\cali`, false},
+	}
+	for _, tt := range tests {
+		env := selfEnv{goos: tt.goos, getenv: envOf(map[string]string{"PATH": tt.path})}
+		if got := env.onPath(tt.dir); got != tt.want {
+			t.Errorf("%s: onPath(%q) with PATH %q = %v, want %v", tt.goos, tt.dir, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSelfPathHint(t *testing.T) {
+	home := filepath.FromSlash("/home/ziad")
+	tests := []struct {
+		goos, dir, hint, profile string
+	}{
+		{"linux", filepath.FromSlash("/home/ziad/.local/bin"), `export PATH="$HOME/.local/bin:$PATH"`, "~/.bashrc"},
+		{"linux", filepath.FromSlash("/home/ziadx/bin"), `export PATH="` + filepath.FromSlash("/home/ziadx/bin") + `:$PATH"`, "~/.bashrc"},
+		{"darwin", "/usr/local/bin", `export PATH="/usr/local/bin:$PATH"`, "~/.zshrc"},
+		{"windows", `C:\Users\ziad\AppData\Local\Programs\cali`, `[Environment]::SetEnvironmentVariable("Path", [Environment]::GetEnvironmentVariable("Path", "User") + ";C:\Users\ziad\AppData\Local\Programs\cali", "User")`, "PowerShell"},
+	}
+	for _, tt := range tests {
+		env := selfEnv{goos: tt.goos, homeDir: home}
+		if got := env.pathHint(tt.dir); got != tt.hint {
+			t.Errorf("%s: pathHint(%q) = %s, want %s", tt.goos, tt.dir, got, tt.hint)
+		}
+		if got := env.profileFile(); got != tt.profile {
+			t.Errorf("%s: profileFile = %q, want %q", tt.goos, got, tt.profile)
+		}
+	}
+}
+
+// TestSelfInstallAndUninstall installs the test binary, a Go binary built
+// from this module, into a temporary --dir.
+func TestSelfInstallAndUninstall(t *testing.T) {
+	quiet(t)
+	dir := t.TempDir()
+	env, err := currentSelfEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, env.binaryName())
+
+	if err := selfInstall([]string{"--dir", dir}); err != nil {
+		t.Fatal(err)
+	}
+	if !isCaliBinary(target) {
+		t.Fatalf("%s isn't recognized as cali after installing it", target)
+	}
+	if info, err := os.Stat(target); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("installed binary: %v, %v", info, err)
+	}
+	if err := selfInstall([]string{"--dir", dir}); err != nil {
+		t.Errorf("reinstall over cali: %v", err)
+	}
+	if err := selfUninstall([]string{"--dir", dir}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("binary left after uninstall: %v", err)
+	}
+	if err := selfUninstall([]string{"--dir", dir}); err != nil {
+		t.Errorf("uninstall when not installed: %v", err)
+	}
+}
+
+func TestSelfLeavesOtherProgramsAlone(t *testing.T) {
+	quiet(t)
+	dir := t.TempDir()
+	env, err := currentSelfEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, env.binaryName())
+	if err := os.WriteFile(target, []byte("#!/bin/sh\necho another cali\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := selfInstall([]string{"--dir", dir}); exitCode(err) != exitUsage {
+		t.Errorf("install over another program: %v, want a usage error", err)
+	}
+	if err := selfUninstall([]string{"--dir", dir}); exitCode(err) != exitUsage {
+		t.Errorf("uninstall of another program: %v, want a usage error", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "#!/bin/sh\necho another cali\n" {
+		t.Fatalf("the other program was changed to %d bytes", len(data))
+	}
+
+	if err := selfInstall([]string{"--dir", dir, "--force"}); err != nil {
+		t.Fatalf("install --force: %v", err)
+	}
+	if !isCaliBinary(target) {
+		t.Errorf("install --force left %s in place", target)
+	}
+}
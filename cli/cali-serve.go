@@ -0,0 +1,319 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// Limits of cali serve.
+const (
+	serveReadTimeout = 30 * time.Second // a shared read, however many wait on it
+	serveMaxBody     = 1 << 20          // bytes of a POST /entries body
+	serveMaxEntries  = 100              // entries of one POST /entries
+)
+
+// readCache shares reads of the log between the requests of cali serve: a
+// GET arriving while the same range is being read waits for that read, and
+// one arriving within ttl of it gets its result, so a burst of requests
+// costs one read of the storage. A write makes every earlier result stale.
+type readCache struct {
+	ttl  time.Duration
+	now  func() time.Time
+	mu   sync.Mutex
+	gen  int // bumped by each write
+	hits map[string]*sharedRead
+}
+
+// sharedRead is one read of a range; done closes once entries and err are
+// set.
+type sharedRead struct {
+	done    chan struct{}
+	entries []WorkoutEntry
+	err     error
+	at      time.Time
+	gen     int
+}
+
+func newReadCache(ttl time.Duration) *readCache {
+	return &readCache{ttl: ttl, now: time.Now, hits: map[string]*sharedRead{}}
+}
+
+// get returns the entries of [since, until], from a read in flight or a
+// fresh enough earlier one when there is one, from read otherwise. The read
+// runs detached from ctx, bounded by serveReadTimeout, so a client hanging
+// up doesn't fail the others waiting on it; ctx only bounds the wait.
+func (c *readCache) get(ctx context.Context, since, until string, read func(context.Context) ([]WorkoutEntry, error)) ([]WorkoutEntry, error) {
+	key := since + "|" + until
+	c.mu.Lock()
+	shared, ok := c.hits[key]
+	if ok && shared.gen == c.gen {
+		select {
+		case <-shared.done:
+			ok = shared.err == nil && c.now().Sub(shared.at) < c.ttl
+		default: // in flight
+		}
+	} else {
+		ok = false
+	}
+	if !ok {
+		shared = &sharedRead{done: make(chan struct{}), gen: c.gen}
+		c.hits[key] = shared
+		go func() {
+			readCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), serveReadTimeout)
+			defer cancel()
+			entries, err := read(readCtx)
+			c.mu.Lock()
+			shared.entries, shared.err, shared.at = entries, err, c.now()
+			c.mu.Unlock()
+			close(shared.done)
+		}()
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-shared.done:
+		return shared.entries, shared.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// invalidate makes every read so far stale, for after a write.
+func (c *readCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	clear(c.hits)
+}
+
+// entryServer is the HTTP side of cali serve. Every request runs with its
+// own context on one shared storage; appends go through a Coalescer so a
+// burst of them is one write.
+type entryServer struct {
+	storage Storage
+	writes  *calio.Coalescer
+	cache   *readCache
+}
+
+func (s *entryServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /entries", s.listEntries)
+	mux.HandleFunc("POST /entries", s.addEntries)
+	return mux
+}
+
+// listEntries answers GET /entries[?since=...&until=...] with the entries
+// of the range as a JSON array, oldest first. Bounds take what --since and
+// --until take.
+func (s *entryServer) listEntries(w http.ResponseWriter, r *http.Request) {
+	var rng dateRange
+	for _, bound := range []struct {
+		name string
+		into *string
+	}{{"since", &rng.Since}, {"until", &rng.Until}} {
+		if value := r.URL.Query().Get(bound.name); value != "" {
+			date, err := userDate(value)
+			if err != nil {
+				serveError(w, http.StatusBadRequest, fmt.Errorf("%s: %w", bound.name, err))
+				return
+			}
+			*bound.into = date
+		}
+	}
+	entries, err := s.cache.get(r.Context(), rng.Since, rng.Until, func(ctx context.Context) ([]WorkoutEntry, error) {
+		entries := []WorkoutEntry{}
+		err := calio.ForEach(ctx, s.storage, rng.Since, rng.Until, func(entry WorkoutEntry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+		return entries, err
+	})
+	if err != nil {
+		serveError(w, http.StatusBadGateway, err)
+		return
+	}
+	serveJSON(w, http.StatusOK, entries)
+}
+
+// addEntries answers POST /entries, whose body is one entry or an array of
+// them, with the entries as stored. Nothing is written unless every entry
+// is valid.
+func (s *entryServer) addEntries(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, serveMaxBody))
+	if err != nil {
+		serveError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	var entries []WorkoutEntry
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &entries)
+	} else {
+		var entry WorkoutEntry
+		err = json.Unmarshal(trimmed, &entry)
+		entries = []WorkoutEntry{entry}
+	}
+	if err != nil {
+		serveError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(entries) == 0 || len(entries) > serveMaxEntries {
+		serveError(w, http.StatusBadRequest, fmt.Errorf("send 1 to %d entries", serveMaxEntries))
+		return
+	}
+	for i := range entries {
+		entry, err := servedEntry(entries[i])
+		if err != nil {
+			serveError(w, http.StatusBadRequest, fmt.Errorf("entry %d: %w", i+1, err))
+			return
+		}
+		entries[i] = entry
+	}
+
+	stored, err := s.writes.AppendBatch(r.Context(), entries)
+	s.cache.invalidate()
+	if err != nil {
+		serveError(w, http.StatusBadGateway, err)
+		return
+	}
+	detail("Serve: saved %d entry(ies)\n", len(stored))
+	serveJSON(w, http.StatusCreated, stored)
+}
+
+// servedEntry checks and completes an entry sent to POST /entries as cali q
+// would: names are matched as cali q matches them, the date defaults to
+// today and the goal, day, category and type follow from the rest.
+func servedEntry(entry WorkoutEntry) (WorkoutEntry, error) {
+	date := strings.TrimSpace(entry.Date)
+	if date == "" {
+		date = currentTime().Format(calio.DateLayout)
+	}
+	date, err := userDate(date)
+	if err != nil {
+		return WorkoutEntry{}, err
+	}
+	if err := checkFutureDates([]WorkoutEntry{{Date: date}}, currentTime()); err != nil {
+		return WorkoutEntry{}, err
+	}
+	exercise, ok := matchExercise(entry.Exercise)
+	if !ok {
+		return WorkoutEntry{}, errors.New(msg("error.unknown_exercise", entry.Exercise))
+	}
+	level, ok := matchLevel(exercise, entry.Level)
+	if !ok {
+		return WorkoutEntry{}, fmt.Errorf("unknown level %q of %s", entry.Level, exercise)
+	}
+	parsed, ok := parseRepsSets(entry.RepsSets)
+	if !ok {
+		return WorkoutEntry{}, fmt.Errorf("can't read RepsSets %q", entry.RepsSets)
+	}
+	load, err := parseLoadInput(entry.Load)
+	if err != nil {
+		return WorkoutEntry{}, err
+	}
+
+	served := WorkoutEntry{
+		Date:     date,
+		Day:      strings.ToUpper(strings.TrimSpace(entry.Day)),
+		Exercise: exercise,
+		Level:    level,
+		RepsSets: normalizeRepsSets(entry.RepsSets),
+		Goal:     strings.TrimSpace(entry.Goal),
+		Comment:  entry.Comment,
+		Type:     calio.TypeStraightSets,
+		Category: calio.CategoryStrength,
+		Duration: entry.Duration,
+		Load:     load,
+	}
+	if parsed.interval() {
+		served.Type = calio.TypeInterval
+	}
+	if served.Goal == "" && !parsed.interval() {
+		served.Goal = resolveGoal(exercise, level)
+	}
+	if slices.Contains(calio.MobilityExercises(), exercise) {
+		served.Category, served.Day = calio.CategoryMobility, ""
+	} else if served.Day == "" {
+		served.Day, _ = calio.PlannedDay(exercise)
+	}
+	return served, nil
+}
+
+func serveJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func serveError(w http.ResponseWriter, status int, err error) {
+	detail("Serve: %d %v\n", status, err)
+	serveJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// serveOptions are the flags of cali serve.
+type serveOptions struct {
+	Addr  string
+	Cache time.Duration
+}
+
+// newServeFlagSet declares the flags of cali serve into opts.
+func newServeFlagSet(opts *serveOptions) *flag.FlagSet {
+	fs := newFlagSet("serve")
+	fs.StringVar(&opts.Addr, "addr", "127.0.0.1:8765", "address to listen on")
+	fs.DurationVar(&opts.Cache, "cache", 5*time.Second, "how long a read answers repeated GET /entries")
+	return fs
+}
+
+func runServe(ctx context.Context, args []string) error {
+	var opts serveOptions
+	fs := newServeFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError("usage: cali serve [--addr host:port] [--cache 5s]")
+	}
+
+	// Requests overlap, so a spinner would only garble stderr.
+	progressOff = true
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	loadGoalOverrides(ctx, storage)
+	writes := calio.NewCoalescer(ctx, storage, calio.CoalescerConfig{Window: 500 * time.Millisecond})
+	defer writes.Close()
+
+	listener, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return usageError("listening on %s: %v", opts.Addr, err)
+	}
+	// Requests keep their own contexts on shutdown: Shutdown lets those in
+	// flight finish.
+	server := &http.Server{
+		Handler: (&entryServer{storage: storage, writes: writes, cache: newReadCache(opts.Cache)}).routes(),
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+	fmt.Println(msg("serve.listening", listener.Addr()))
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// fakeClock is a settable now for readCache.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func TestReadCacheSharesOneRead(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC)}
+	cache := newReadCache(5 * time.Second)
+	cache.now = clock.now
+	var reads atomic.Int32
+	release := make(chan struct{})
+	read := func(context.Context) ([]WorkoutEntry, error) {
+		reads.Add(1)
+		<-release
+		return []WorkoutEntry{{Exercise: "Pushups"}}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entries, err := cache.get(context.Background(), "2026-03-01", "", read)
+			if err != nil || len(entries) != 1 {
+				t.Errorf("get = %v, %v", entries, err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+	if n := reads.Load(); n != 1 {
+		t.Fatalf("20 gets within the ttl read %d times, want 1", n)
+	}
+
+	cache.get(context.Background(), "2026-03-02", "", read)
+	if n := reads.Load(); n != 2 {
+		t.Errorf("another range read %d times in all, want 2", n)
+	}
+	clock.advance(5 * time.Second)
+	cache.get(context.Background(), "2026-03-01", "", read)
+	if n := reads.Load(); n != 3 {
+		t.Errorf("a get past the ttl read %d times in all, want 3", n)
+	}
+	cache.invalidate()
+	cache.get(context.Background(), "2026-03-01", "", read)
+	if n := reads.Load(); n != 4 {
+		t.Errorf("a get after a write read %d times in all, want 4", n)
+	}
+}
+
+func TestReadCacheDoesNotKeepErrors(t *testing.T) {
+	cache := newReadCache(time.Hour)
+	var reads atomic.Int32
+	read := func(context.Context) ([]WorkoutEntry, error) {
+		if reads.Add(1) == 1 {
+			return nil, errors.New("quota exceeded")
+		}
+		return []WorkoutEntry{}, nil
+	}
+	if _, err := cache.get(context.Background(), "", "", read); err == nil {
+		t.Fatal("first get succeeded")
+	}
+	if _, err := cache.get(context.Background(), "", "", read); err != nil {
+		t.Errorf("get after a failed read = %v, want a new read", err)
+	}
+}
+
+// TestReadCacheOutlivesTheClient checks a client hanging up neither fails
+// the read others wait on nor waits for it.
+func TestReadCacheOutlivesTheClient(t *testing.T) {
+	cache := newReadCache(time.Hour)
+	started, release := make(chan struct{}), make(chan struct{})
+	read := func(ctx context.Context) ([]WorkoutEntry, error) {
+		close(started)
+		<-release
+		return []WorkoutEntry{{Exercise: "Squats"}}, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	gone := make(chan error)
+	go func() {
+		_, err := cache.get(ctx, "", "", read)
+		gone <- err
+	}()
+	<-started
+	cancel()
+	if err := <-gone; !errors.Is(err, context.Canceled) {
+		t.Errorf("get of the client that hung up = %v, want context.Canceled", err)
+	}
+
+	waiting := make(chan error)
+	go func() {
+		entries, err := cache.get(context.Background(), "", "", read)
+		if err == nil && len(entries) != 1 {
+			err = fmt.Errorf("%d entries", len(entries))
+		}
+		waiting <- err
+	}()
+	close(release)
+	if err := <-waiting; err != nil {
+		t.Errorf("get sharing the read = %v", err)
+	}
+}
+
+// newTestServer serves a local log in a temporary directory.
+func newTestServer(t *testing.T) (*httptest.Server, *calio.FileStorage) {
+	t.Helper()
+	quiet(t)
+	storage := calio.NewFileStorage(t.TempDir())
+	writes := calio.NewCoalescer(context.Background(), storage, calio.CoalescerConfig{Window: 10 * time.Millisecond})
+	server := httptest.NewServer((&entryServer{storage: storage, writes: writes, cache: newReadCache(time.Minute)}).routes())
+	t.Cleanup(func() {
+		server.Close()
+		writes.Close()
+	})
+	return server, storage
+}
+
+func postEntries(t *testing.T, url, body string) (int, []WorkoutEntry) {
+	t.Helper()
+	resp, err := http.Post(url+"/entries", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var stored []WorkoutEntry
+	if resp.StatusCode == http.StatusCreated {
+		if err := json.NewDecoder(resp.Body).Decode(&stored); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode, stored
+}
+
+func getEntries(t *testing.T, url string) []WorkoutEntry {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Error(err)
+		return nil
+	}
+	defer resp.Body.Close()
+	var entries []WorkoutEntry
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET %s: %s", url, resp.Status)
+	} else if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		t.Error(err)
+	}
+	return entries
+}
+
+func TestServeEntries(t *testing.T) {
+	server, _ := newTestServer(t)
+	status, stored := postEntries(t, server.URL, `{"Date": "2024-03-04", "Exercise": "push ups", "Level": "5", "RepsSets": "20 x 2"}`)
+	if status != http.StatusCreated || len(stored) != 1 {
+		t.Fatalf("POST one entry = %d, %v", status, stored)
+	}
+	want := WorkoutEntry{Date: "2024-03-04", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: resolveGoal("Pushups", "Full"), Type: calio.TypeStraightSets, Category: calio.CategoryStrength}
+	if got := stored[0]; got.Date != want.Date || got.Exercise != want.Exercise || got.Level != want.Level || got.RepsSets != want.RepsSets || got.Goal != want.Goal || got.Type != want.Type || got.Category != want.Category {
+		t.Errorf("stored %+v, want %+v", got, want)
+	}
+
+	status, stored = postEntries(t, server.URL, `[{"Date": "2024-03-05", "Exercise": "l-sit", "Level": "tuck", "RepsSets": "30s", "Day": "B"},
+		{"Date": "2024-03-05", "Exercise": "squats", "Level": "half", "RepsSets": "35x2"}]`)
+	if status != http.StatusCreated || len(stored) != 2 || stored[0].Category != calio.CategoryMobility || stored[0].Day != "" {
+		t.Fatalf("POST two entries = %d, %+v", status, stored)
+	}
+
+	if entries := getEntries(t, server.URL+"/entries"); len(entries) != 3 {
+		t.Errorf("GET /entries = %d entries, want 3", len(entries))
+	}
+	if entries := getEntries(t, server.URL+"/entries?since=2024-03-05&until=2024-03-05"); len(entries) != 2 {
+		t.Errorf("GET /entries of 2024-03-05 = %d entries, want 2", len(entries))
+	}
+	if resp, err := http.Get(server.URL + "/entries?since=someday"); err != nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("GET with a bad since = %v, %v", resp, err)
+	}
+
+	for _, body := range []string{
+		``,
+		`[]`,
+		`{"Exercise": "rows", "Level": "full", "RepsSets": "8x2"}`,
+		`{"Exercise": "pullups", "Level": "one-leg", "RepsSets": "8x2"}`,
+		`{"Exercise": "pullups", "Level": "full", "RepsSets": "lots"}`,
+		`[{"Date": "2024-03-06", "Exercise": "pullups", "Level": "full", "RepsSets": "8x2"}, {"Exercise": "pullups"}]`,
+	} {
+		if status, _ := postEntries(t, server.URL, body); status != http.StatusBadRequest {
+			t.Errorf("POST %s = %d, want 400", body, status)
+		}
+	}
+	if entries := getEntries(t, server.URL+"/entries"); len(entries) != 3 {
+		t.Errorf("GET /entries after bad POSTs = %d entries, want 3", len(entries))
+	}
+}
+
+// TestServeParallel runs GETs and POSTs at once; run it with -race.
+func TestServeParallel(t *testing.T) {
+	server, storage := newTestServer(t)
+	const posts = 12
+	var wg sync.WaitGroup
+	for i := range posts {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"Date": "2024-03-%02d", "Exercise": "pullups", "Level": "full", "RepsSets": "%dx2"}`, i%5+1, i+1)
+			if status, stored := postEntries(t, server.URL, body); status != http.StatusCreated || len(stored) != 1 {
+				t.Errorf("POST %d = %d", i, status)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			getEntries(t, server.URL+"/entries?since=2024-03-01")
+		}()
+	}
+	wg.Wait()
+
+	entries := getEntries(t, server.URL+"/entries?since=2024-03-01")
+	if len(entries) != posts {
+		t.Errorf("GET after the POSTs = %d entries, want %d", len(entries), posts)
+	}
+	all, err := storage.All(context.Background())
+	if err != nil || len(all) != posts {
+		t.Errorf("log holds %d entries, %v; want %d", len(all), err, posts)
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// sessionInfo is what the lock file records about the session holding it.
+type sessionInfo struct {
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+}
+
+// sessionLock marks an interactive logging session as running, so one started
+// by accident in a second terminal warns before both write an entry. Locks
+// left by processes that are no longer running are removed on the next
+// acquire. alive and now are replaced in tests.
+type sessionLock struct {
+	path  string
+	pid   int
+	alive func(pid int) bool
+	now   func() time.Time
+}
+
+func newSessionLock() (*sessionLock, error) {
+	paths, err := userPaths()
+	if err != nil {
+		return nil, err
+	}
+	return &sessionLock{
+		path:  filepath.Join(paths.StateDir(), "session.lock"),
+		pid:   os.Getpid(),
+		alive: processAlive,
+		now:   currentTime,
+	}, nil
+}
+
+// Acquire takes the lock. When a running session holds it, Acquire leaves it
+// alone and returns that session's info with ok false.
+func (l *sessionLock) Acquire() (holder sessionInfo, ok bool, err error) {
+	if err := makeDirFor(l.path); err != nil {
+		return sessionInfo{}, false, err
+	}
+	// A second attempt follows removing a stale lock; losing that race to
+	// another session means it is live.
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			err = json.NewEncoder(file).Encode(sessionInfo{PID: l.pid, Started: l.now()})
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			return sessionInfo{}, err == nil, err
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return sessionInfo{}, false, err
+		}
+
+		holder, valid := l.read()
+		if valid && holder.PID != l.pid && l.alive(holder.PID) {
+			return holder, false, nil
+		}
+		detail("Removing stale session lock %s\n", l.path)
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return sessionInfo{}, false, err
+		}
+	}
+	holder, _ = l.read()
+	return holder, false, nil
+}
+
+// Force takes the lock even though another session holds it.
+func (l *sessionLock) Force() error {
+	data, err := json.Marshal(sessionInfo{PID: l.pid, Started: l.now()})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, append(data, '\n'), 0644)
+}
+
+// Release removes the lock if this process still holds it.
+func (l *sessionLock) Release() {
+	if holder, valid := l.read(); valid && holder.PID == l.pid {
+		os.Remove(l.path)
+	}
+}
+
+// read returns the lock's holder; valid is false for an unreadable file.
+func (l *sessionLock) read() (holder sessionInfo, valid bool) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return sessionInfo{}, false
+	}
+	if err := json.Unmarshal(data, &holder); err != nil || holder.PID <= 0 {
+		return sessionInfo{}, false
+	}
+	return holder, true
+}
+
+// sessionAge renders how long ago a session started, e.g. "2 minute(s)".
+func sessionAge(started, now time.Time) string {
+	age := now.Sub(started)
+	switch {
+	case age < time.Minute:
+		return msg("session.age_seconds", max(0, int(age.Seconds())))
+	case age < time.Hour:
+		return msg("session.age_minutes", int(age.Minutes()))
+	}
+	return msg("session.age_hours", int(age.Hours()))
+}
+
+// guardSession takes the session lock for the interactive logging flow. When
+// another session is active it asks whether to go on; declining cancels.
+// The returned release is safe to call more than once. Failing to use the
+// lock file only shows in --verbose, since the lock is a safety net.
+func guardSession(reader *bufio.Reader) (release func(), err error) {
+	lock, err := newSessionLock()
+	if err != nil {
+		detail("Session lock unavailable: %v\n", err)
+		return func() {}, nil
+	}
+	holder, ok, err := lock.Acquire()
+	if err != nil {
+		detail("Session lock unavailable: %v\n", err)
+		return func() {}, nil
+	}
+	if !ok {
+		prompt(msg("session.active", holder.PID, sessionAge(holder.Started, lock.now())))
+		answer, _ := reader.ReadString('\n')
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if answer == "" || !slices.Contains(strings.Split(msg("answer.yes"), ","), answer) {
+			return nil, errCancelled
+		}
+		if err := lock.Force(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	onForcedExit(lock.Release)
+	return lock.Release, nil
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testLock is a session lock for pid in dir, with running as the set of
+// live processes and a clock fixed at now.
+func testLock(dir string, pid int, running map[int]bool, now time.Time) *sessionLock {
+	return &sessionLock{
+		path:  filepath.Join(dir, "state", "session.lock"),
+		pid:   pid,
+		alive: func(pid int) bool { return running[pid] },
+		now:   func() time.Time { return now },
+	}
+}
+
+func TestSessionLock(t *testing.T) {
+	quiet(t)
+	dir := t.TempDir()
+	started := time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC)
+	running := map[int]bool{100: true, 200: true}
+	first := testLock(dir, 100, running, started)
+	second := testLock(dir, 200, running, started.Add(3*time.Minute))
+
+	if _, ok, err := first.Acquire(); !ok || err != nil {
+		t.Fatalf("first Acquire = %v, %v", ok, err)
+	}
+	holder, ok, err := second.Acquire()
+	if ok || err != nil || holder.PID != 100 || !holder.Started.Equal(started) {
+		t.Fatalf("second Acquire while the first runs = %+v, %v, %v", holder, ok, err)
+	}
+	if got := sessionAge(holder.Started, second.now()); got != "3 minute(s)" {
+		t.Errorf("sessionAge = %q", got)
+	}
+
+	// The second session doesn't remove a lock it doesn't hold.
+	second.Release()
+	if holder, valid := first.read(); !valid || holder.PID != 100 {
+		t.Fatalf("lock after the other session's Release = %+v, %v", holder, valid)
+	}
+
+	// The first session's process dies without releasing.
+	running[100] = false
+	if _, ok, err := second.Acquire(); !ok || err != nil {
+		t.Fatalf("Acquire over a dead session's lock = %v, %v", ok, err)
+	}
+	if holder, _ := second.read(); holder.PID != 200 || !holder.Started.Equal(second.now()) {
+		t.Errorf("lock after taking it over = %+v", holder)
+	}
+	second.Release()
+	if _, err := os.Stat(second.path); !os.IsNotExist(err) {
+		t.Errorf("lock left after Release: %v", err)
+	}
+}
+
+func TestSessionLockForce(t *testing.T) {
+	quiet(t)
+	dir := t.TempDir()
+	running := map[int]bool{100: true, 200: true}
+	first := testLock(dir, 100, running, time.Now())
+	second := testLock(dir, 200, running, time.Now())
+	if _, ok, _ := first.Acquire(); !ok {
+		t.Fatal("first Acquire failed")
+	}
+	if err := second.Force(); err != nil {
+		t.Fatal(err)
+	}
+	// Whichever session quits first, only the holder's Release counts.
+	first.Release()
+	if holder, valid := second.read(); !valid || holder.PID != 200 {
+		t.Fatalf("lock after the overridden session's Release = %+v, %v", holder, valid)
+	}
+	second.Release()
+	if _, err := os.Stat(second.path); !os.IsNotExist(err) {
+		t.Errorf("lock left after Release: %v", err)
+	}
+}
+
+// TestSessionLockStaleFiles covers lock files no running session holds.
+func TestSessionLockStaleFiles(t *testing.T) {
+	quiet(t)
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"empty", ""},
+		{"truncated", `{"pid": 1`},
+		{"no pid", `{"started": "2026-03-04T18:00:00Z"}`},
+		{"negative pid", `{"pid": -5}`},
+		{"dead process", `{"pid": 300, "started": "2026-03-04T18:00:00Z"}`},
+		{"this process, left from a reused pid", `{"pid": 100, "started": "2026-03-04T18:00:00Z"}`},
+	}
+	for _, tt := range tests {
+		lock := testLock(t.TempDir(), 100, map[int]bool{100: true}, time.Now())
+		if err := makeDirFor(lock.path); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(lock.path, []byte(tt.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, err := lock.Acquire(); !ok || err != nil {
+			t.Errorf("%s: Acquire = %v, %v", tt.name, ok, err)
+		}
+		if holder, valid := lock.read(); !valid || holder.PID != 100 {
+			t.Errorf("%s: lock after Acquire = %+v, %v", tt.name, holder, valid)
+		}
+	}
+}
+
+func TestSessionAge(t *testing.T) {
+	started := time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{-5 * time.Second, "0 second(s)"},
+		{0, "0 second(s)"},
+		{59 * time.Second, "59 second(s)"},
+		{time.Minute, "1 minute(s)"},
+		{59*time.Minute + 59*time.Second, "59 minute(s)"},
+		{time.Hour, "1 hour(s)"},
+		{26 * time.Hour, "26 hour(s)"},
+	}
+	for _, tt := range tests {
+		if got := sessionAge(started, started.Add(tt.age)); got != tt.want {
+			t.Errorf("sessionAge(%v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestProcessAlive(t *testing.T) {
+	if !processAlive(os.Getpid()) {
+		t.Error("processAlive is false for this process")
+	}
+}
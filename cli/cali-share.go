@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"context"
+	_ "embed"
+	"flag"
+	"html/template"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// shareHTML is the page of cali share export-static: one file with its
+// styles and the table-sorting script inline, so it works from any static
+// host, an email attachment or the local disk. html/template escapes every
+// value, comments included.
+//
+//go:embed cali-share.html
+var shareHTML string
+
+var shareTemplate = template.Must(template.New("share").Parse(shareHTML))
+
+// sharePage is what the page template renders.
+type sharePage struct {
+	Lang      string
+	Title     string
+	Labels    map[string]string
+	Summary   []reportSection
+	Matrix    shareMatrix
+	Exercises []shareExercise
+}
+
+// shareExercise is the history of one exercise, newest first.
+type shareExercise struct {
+	Name string
+	Rows []shareRow
+}
+
+// shareRow is one entry. Date, Step and Score are the sort keys of the
+// columns showing DisplayDate, Level and Work.
+type shareRow struct {
+	Date, DisplayDate string
+	Day               string
+	Level             string
+	Step              int
+	Work              string
+	Score             int
+	Met               bool
+	Goal              string
+	Comment           string
+}
+
+type shareMatrix struct {
+	Exercises []string
+	Rows      []shareMatrixRow
+	Legend    []string
+}
+
+type shareMatrixRow struct {
+	Step  int
+	Cells []shareMatrixCell
+}
+
+type shareMatrixCell struct {
+	Level, Goal    string
+	Current, Empty bool
+}
+
+// shareOptions are the flags of cali share export-static.
+type shareOptions struct {
+	Title          string
+	Out            string
+	IncludePrivate bool
+}
+
+// newShareFlagSet declares the flags of cali share export-static into opts.
+func newShareFlagSet(opts *shareOptions) *flag.FlagSet {
+	fs := newFlagSet("share export-static")
+	fs.StringVar(&opts.Title, "title", msg("share.title"), "page title")
+	fs.StringVar(&opts.Out, "out", "", "write the page to this file instead of stdout")
+	fs.BoolVar(&opts.IncludePrivate, "include-private", false, "keep comments marked private instead of showing [redacted]")
+	return fs
+}
+
+func runShare(ctx context.Context, args []string, rng dateRange) error {
+	const usage = "usage: cali share export-static [--title <title>] [--out <file>] [--include-private] [--since <date>] [--until <date>]"
+	if len(args) == 0 || args[0] != "export-static" {
+		return usageError(usage)
+	}
+	var opts shareOptions
+	fs := newShareFlagSet(&opts)
+	if err := fs.Parse(args[1:]); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(usage)
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	loadGoalOverrides(ctx, storage)
+	loadLevelPins(ctx, storage)
+	entries, err := storage.Range(ctx, rng.Since, rng.Until)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	now := currentTime()
+	entries = shareableEntries(calio.WithoutFuture(entries, now), opts.IncludePrivate)
+	page := buildSharePage(entries, opts.Title, rng, now)
+
+	if opts.Out == "" {
+		return writeSharePage(os.Stdout, page)
+	}
+	file, err := os.Create(opts.Out)
+	if err != nil {
+		return err
+	}
+	err = writeSharePage(file, page)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	say(msg("share.written", opts.Out, len(entries)))
+	return nil
+}
+
+func writeSharePage(w io.Writer, page sharePage) error {
+	return shareTemplate.Execute(w, page)
+}
+
+// buildSharePage collects the page: the summary of cali report over the
+// entries, the level chart with the levels trained last, and every entry
+// grouped by exercise, as of now.
+func buildSharePage(entries []WorkoutEntry, title string, rng dateRange, now time.Time) sharePage {
+	generated := msg("share.generated", displayDate(now.Format(calio.DateLayout)))
+	if rng.isSet() {
+		generated += " · " + msg("share.range", openEnd(displayDate(rng.Since)), openEnd(displayDate(rng.Until)))
+	}
+	page := sharePage{
+		Lang:  locale,
+		Title: title,
+		Labels: map[string]string{
+			"Generated": generated,
+			"Summary":   msg("share.summary"),
+			"Matrix":    msg("share.matrix"),
+			"History":   msg("share.history"),
+			"Nothing":   msg("report.nothing"),
+			"Step":      msg("matrix.step"),
+			"Date":      msg("share.col_date"),
+			"Day":       msg("share.col_day"),
+			"Level":     msg("share.col_level"),
+			"Work":      msg("share.col_work"),
+			"Goal":      msg("share.col_goal"),
+			"Comment":   msg("share.col_comment"),
+		},
+		Summary: buildReport(entries, rng, now).sections(),
+	}
+
+	strength, _ := splitByCategory(entries)
+	current := map[string]string{}
+	for _, key := range currentLevels(strength) {
+		current[key.Exercise] = key.Level
+	}
+	page.Matrix = buildShareMatrix(buildLevelMatrix(current))
+
+	byExercise := map[string][]WorkoutEntry{}
+	for _, entry := range entries {
+		exercise := calio.Canonical(entry).Exercise
+		byExercise[exercise] = append(byExercise[exercise], entry)
+	}
+	names := append(calio.Exercises(), calio.MobilityExercises()...)
+	var others []string
+	for name := range byExercise {
+		if !slices.Contains(names, name) {
+			others = append(others, name)
+		}
+	}
+	slices.Sort(others)
+	for _, name := range append(names, others...) {
+		if len(byExercise[name]) == 0 {
+			continue
+		}
+		exercise := shareExercise{Name: name}
+		for _, entry := range byExercise[name] {
+			exercise.Rows = append(exercise.Rows, shareRowFor(entry))
+		}
+		slices.SortStableFunc(exercise.Rows, func(a, b shareRow) int {
+			return strings.Compare(b.Date, a.Date)
+		})
+		page.Exercises = append(page.Exercises, exercise)
+	}
+	return page
+}
+
+func shareRowFor(entry WorkoutEntry) shareRow {
+	row := shareRow{
+		Date:        entry.Date,
+		DisplayDate: displayDate(entry.Date),
+		Day:         entry.Day,
+		Level:       entry.Level,
+		Step:        slices.Index(calio.Levels(entry.Exercise), entry.Level) + 1,
+		Work:        loggedWork(entry),
+		Goal:        entry.Goal,
+		Comment:     entry.Comment,
+	}
+	if isInterval(entry) {
+		row.Work = workText(entry)
+		row.Goal = ""
+	} else {
+		row.Score, _ = workScore(entry.RepsSets)
+		row.Met = meetsGoal(entry.RepsSets, entry.Goal)
+	}
+	return row
+}
+
+func buildShareMatrix(m levelMatrix) shareMatrix {
+	matrix := shareMatrix{Exercises: m.Exercises}
+	for step := 0; step < m.steps(); step++ {
+		row := shareMatrixRow{Step: step + 1}
+		for col := range m.Exercises {
+			level, goal, ok := m.cell(col, step)
+			row.Cells = append(row.Cells, shareMatrixCell{
+				Level:   level,
+				Goal:    goal,
+				Current: ok && m.isCurrent(col, level),
+				Empty:   !ok,
+			})
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+	if m.hasCurrent() {
+		matrix.Legend = append(matrix.Legend, msg("share.legend_current"))
+	}
+	if hasGoalOverrides(m.Exercises...) {
+		matrix.Legend = append(matrix.Legend, msg("matrix.legend_goal"))
+	}
+	return matrix
+}
+
+// openEnd shows the open end of a range as "…".
+func openEnd(date string) string {
+	if date == "" {
+		return "…"
+	}
+	return date
+}
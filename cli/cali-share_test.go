@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// shareEntries are the history of the golden page: a few weeks of two
+// exercises, an interval, a mobility session, an exercise cali doesn't know
+// and a comment trying to run a script.
+var shareEntries = []WorkoutEntry{
+	{Date: "2026-02-16", Day: "A", Exercise: "Pushups", Level: "Half", RepsSets: "20x2", Goal: "25x2", Comment: "slow", Category: calio.CategoryStrength},
+	{Date: "2026-02-16", Day: "A", Exercise: "Squats", Level: "Half", RepsSets: "35x2", Goal: "50x2", Category: calio.CategoryStrength},
+	{Date: "2026-02-23", Day: "A", Exercise: "Pushups", Level: "Half", RepsSets: "25x2", Goal: "25x2", Comment: `<script>alert("x")</script> & done`, Category: calio.CategoryStrength},
+	{Date: "2026-02-25", Day: "B", Exercise: "Pullups", Level: "Jackknife", RepsSets: "EMOM 10min @ 5", Goal: "15x3", Type: calio.TypeInterval, Category: calio.CategoryStrength},
+	{Date: "2026-03-01", Exercise: "Bridge Hold", Level: "Short", RepsSets: "30sx3", Category: calio.CategoryMobility},
+	{Date: "2026-03-02", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Goal: "20x2", Category: calio.CategoryStrength},
+	{Date: "2026-03-02", Day: "A", Exercise: "Burpees", Level: "Any", RepsSets: "10x3", Comment: "off plan", Category: calio.CategoryStrength},
+}
+
+func TestSharePageGolden(t *testing.T) {
+	withGoalOverrides(t, nil)
+	now := time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC)
+	page := buildSharePage(shareEntries, "Sam's <training>", dateRange{Since: "2026-02-16"}, now)
+	var b strings.Builder
+	if err := writeSharePage(&b, page); err != nil {
+		t.Fatal(err)
+	}
+	html := b.String()
+	checkGolden(t, "share.html", html)
+
+	if strings.Count(html, "<script>") != 1 || strings.Contains(html, `alert("x")`) {
+		t.Errorf("the comment's script isn't escaped:\n%s", html)
+	}
+	if !strings.Contains(html, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; done") {
+		t.Errorf("the comment isn't on the page as text")
+	}
+	if !strings.Contains(html, "<title>Sam&#39;s &lt;training&gt;</title>") {
+		t.Errorf("the title isn't escaped")
+	}
+}
+
+// TestShareRows checks the rows of each exercise come newest first, with
+// the sort keys of their columns.
+func TestShareRows(t *testing.T) {
+	withGoalOverrides(t, nil)
+	page := buildSharePage(shareEntries, "", dateRange{}, time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC))
+	var names []string
+	for _, exercise := range page.Exercises {
+		names = append(names, exercise.Name)
+	}
+	if got := strings.Join(names, ", "); got != "Pushups, Squats, Pullups, Bridge Hold, Burpees" {
+		t.Fatalf("the exercises are %s", got)
+	}
+	pushups := page.Exercises[0].Rows
+	if len(pushups) != 3 || pushups[0].Date != "2026-03-02" || pushups[2].Date != "2026-02-16" {
+		t.Fatalf("the pushups rows are %+v, want newest first", pushups)
+	}
+	if pushups[0].Step <= pushups[1].Step || pushups[1].Score != 50 || !pushups[1].Met || pushups[2].Met {
+		t.Errorf("the pushups rows are %+v", pushups)
+	}
+	if pullups := page.Exercises[2].Rows[0]; pullups.Goal != "" || pullups.Work == "" {
+		t.Errorf("the interval row is %+v, want its work and no goal", pullups)
+	}
+}
+
+func TestShareCommand(t *testing.T) {
+	storage := pipedLog(t)
+	today := currentTime()
+	for i, comment := range []string{"<b>ancient</b>", "[private] sore knee"} {
+		entry := shareEntries[0]
+		entry.Date = today.AddDate(0, 0, -10*(1-i)).Format(calio.DateLayout)
+		entry.Comment = comment
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(t.TempDir(), "history.html")
+	since := today.AddDate(0, 0, -5).Format(calio.DateLayout)
+	stdout, stderr, code := runCLI(t, "", "share", "export-static", "--title", "For coach", "--out", out, "--since", since)
+	if code != 0 {
+		t.Fatalf("cali share export-static exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, out) {
+		t.Errorf("cali share export-static printed %q, want where the page went", stdout)
+	}
+	html := readFile(t, out)
+	if !strings.Contains(html, "<title>For coach</title>") || strings.Contains(html, "ancient") || strings.Contains(html, "sore knee") {
+		t.Errorf("the page is\n%s\nwant the title, the recent entry and its comment redacted", html)
+	}
+
+	stdout, _, code = runCLI(t, "", "share", "export-static", "--include-private")
+	if code != 0 || !strings.Contains(stdout, "sore knee") || !strings.Contains(stdout, "&lt;b&gt;ancient&lt;/b&gt;") {
+		t.Errorf("cali share export-static --include-private exited %d, printed\n%s", code, stdout)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"share"}, {"share", "export"}, {"share", "export-static", "extra"}} {
+		if _, _, code := runCLI(t, "", args...); code != exitUsage {
+			t.Errorf("cali %s exited %d, want %d", strings.Join(args, " "), code, exitUsage)
+		}
+	}
+}
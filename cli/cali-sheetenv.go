@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// envEnabled reports whether an on/off environment variable is switched on.
+func envEnabled(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// perYearTabsEnabled reports whether the Sheets backend keeps one tab per
+// year (see calio.SheetsConfig.PerYear).
+func perYearTabsEnabled() bool {
+	return envEnabled("CALI_SHEET_PER_YEAR")
+}
+
+// goalPercentEnabled reports whether appends also write column J, the logged
+// work as a percentage of the goal, for people reading the sheet directly.
+// cali never reads the column back.
+func goalPercentEnabled() bool {
+	return envEnabled("CALI_SHEET_GOAL_PERCENT")
+}
+
+// sheetPageSize returns CALI_SHEET_PAGE_SIZE, the rows the Sheets backend
+// reads per request; 0 means calio.DefaultPageSize.
+func sheetPageSize() (int, error) {
+	raw := strings.TrimSpace(os.Getenv("CALI_SHEET_PAGE_SIZE"))
+	if raw == "" {
+		return 0, nil
+	}
+	size, err := strconv.Atoi(raw)
+	if err != nil || size < 1 {
+		return 0, fmt.Errorf("invalid CALI_SHEET_PAGE_SIZE %q (use a number of rows, e.g. 1000)", raw)
+	}
+	return size, nil
+}
+
+// selectedSheet is the tab set with the global --sheet flag; "" uses the
+// configured one.
+var selectedSheet string
+
+// forceUnrecognized is set with the global --force-unrecognized flag, to
+// write to a tab that doesn't look like a cali log (see
+// calio.SheetsConfig.ForceUnrecognized).
+var forceUnrecognized bool
+
+// extractForceUnrecognized removes the global --force-unrecognized flag
+// from args.
+func extractForceUnrecognized(args []string) ([]string, bool) {
+	return extractSwitch(args, "--force-unrecognized")
+}
+
+// extractSwitch removes the global switch name from args and reports
+// whether it was there.
+func extractSwitch(args []string, name string) ([]string, bool) {
+	var rest []string
+	found := false
+	for _, arg := range args {
+		if arg == name {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
+
+// sheetCommands are the commands besides logging that --sheet applies to.
+var sheetCommands = []string{"log", "history", "-p", "--print", "--history", "search", "-s", "--search", "export", "import"}
+
+// extractSheetFlag removes the global --sheet <tab> flag from args.
+func extractSheetFlag(args []string) (string, []string, error) {
+	var sheet string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != "--sheet" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("--sheet requires a tab name")
+			}
+			i++
+			value = args[i]
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			return "", nil, fmt.Errorf("--sheet requires a tab name")
+		}
+		sheet = value
+	}
+	return sheet, rest, nil
+}
+
+// acceptsSheet reports whether the command in args is one --sheet applies
+// to: logging, with or without its flags, or one of sheetCommands.
+func acceptsSheet(args []string) bool {
+	if len(args) == 0 || slices.Contains(sheetCommands, args[0]) {
+		return true
+	}
+	name, _, _ := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+	return strings.HasPrefix(args[0], "-") && newLogFlagSet(&logOptions{}).Lookup(name) != nil
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestExtractSheetFlag(t *testing.T) {
+	tests := []struct {
+		args  []string
+		sheet string
+		rest  string
+		err   bool
+	}{
+		{[]string{"-p"}, "", "-p", false},
+		{[]string{"--sheet", "Experiments", "-p"}, "Experiments", "-p", false},
+		{[]string{"search", "--sheet=Old Log", "-s", "2026-03-04"}, "Old Log", "search -s 2026-03-04", false},
+		{[]string{"--sheet", "A", "--sheet", " B "}, "B", "", false},
+		{[]string{"-p", "--sheet"}, "", "", true},
+		{[]string{"--sheet=", "-p"}, "", "", true},
+	}
+	for _, tt := range tests {
+		sheet, rest, err := extractSheetFlag(tt.args)
+		if (err != nil) != tt.err || sheet != tt.sheet || strings.Join(rest, " ") != tt.rest {
+			t.Errorf("extractSheetFlag(%q) = %q, %q, %v", tt.args, sheet, rest, err)
+		}
+	}
+}
+
+func TestAcceptsSheet(t *testing.T) {
+	for _, args := range [][]string{nil, {"-p"}, {"search", "2026-03-04"}, {"export"}, {"import", "x.csv"}, {"--deload"}, {"--no-duration"}} {
+		if !acceptsSheet(args) {
+			t.Errorf("--sheet isn't accepted with %q", args)
+		}
+	}
+	for _, args := range [][]string{{"stats"}, {"doctor"}, {"--unknown"}} {
+		if acceptsSheet(args) {
+			t.Errorf("--sheet is accepted with %q", args)
+		}
+	}
+}
+
+// TestSheetFlagPrecedence checks --sheet wins over CALI_SHEET_NAME and the
+// keyring, and that without it they still apply.
+func TestSheetFlagPrecedence(t *testing.T) {
+	env := func(name string) string {
+		if name == "CALI_SHEET_NAME" {
+			return "Log"
+		}
+		return ""
+	}
+	store := &fakeKeyring{values: map[string]string{keySheetName: "Keyring tab"}}
+	if got := loadSheetsConfig(env, store).withSheet("Experiments").SheetName; got != (sheetsSetting{Value: "Experiments", Source: "flag"}) {
+		t.Errorf("with --sheet the tab is %+v", got)
+	}
+	if got := loadSheetsConfig(env, store).withSheet("").SheetName; got != (sheetsSetting{Value: "Log", Source: "env"}) {
+		t.Errorf("without --sheet the tab is %+v", got)
+	}
+	noEnv := func(string) string { return "" }
+	if got := loadSheetsConfig(noEnv, store).withSheet("Experiments").SheetName; got.Value != "Experiments" {
+		t.Errorf("with --sheet over a keyring tab the tab is %+v", got)
+	}
+}
+
+// TestSheetFlagLocal logs one entry to another local log with --sheet and
+// checks each log only shows its own entries.
+func TestSheetFlagLocal(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CALI_SHEET_NAME", "Log")
+	if _, stderr, code := runCLIIn(t, home, "", "--sheet", "Experiments", "log", "--exercise", "pushups", "--level", "full", "--reps", "10x2"); code != 0 {
+		t.Fatalf("logging with --sheet exited %d: %s", code, stderr)
+	}
+	if _, stderr, code := runCLIIn(t, home, "", "log", "--exercise", "squats", "--level", "half", "--reps", "30x2"); code != 0 {
+		t.Fatalf("logging exited %d: %s", code, stderr)
+	}
+	if files, err := os.ReadDir(filepath.Join(home, "log", "Experiments")); err != nil || len(files) != 1 {
+		t.Errorf("the Experiments log holds %v, %v, want one year file", files, err)
+	}
+
+	stdout, _, _ := runCLIIn(t, home, "", "-p", "--sheet=Experiments")
+	if !strings.Contains(stdout, "Pushups") || strings.Contains(stdout, "Squats") {
+		t.Errorf("cali -p --sheet=Experiments printed %q, want only the pushups", stdout)
+	}
+	stdout, _, _ = runCLIIn(t, home, "", "-p")
+	if strings.Contains(stdout, "Pushups") || !strings.Contains(stdout, "Squats") {
+		t.Errorf("cali -p printed %q, want only the squats", stdout)
+	}
+
+	for _, args := range [][]string{{"--sheet", "../elsewhere", "-p"}, {"--sheet", "Experiments", "stats"}, {"-p", "--sheet"}} {
+		if _, _, code := runCLIIn(t, home, "", args...); code == exitOK {
+			t.Errorf("cali %s succeeded", strings.Join(args, " "))
+		}
+	}
+	if _, _, code := runCLIIn(t, home, "", "--sheet", "Experiments", "stats"); code != exitUsage {
+		t.Errorf("--sheet with stats exited %d, want %d", code, exitUsage)
+	}
+}
+
+func TestExtractForceUnrecognized(t *testing.T) {
+	for _, tt := range []struct {
+		args  []string
+		rest  string
+		force bool
+	}{
+		{[]string{"-p"}, "-p", false},
+		{[]string{"--force-unrecognized", "q", "pushups full 10x2"}, "q pushups full 10x2", true},
+		{[]string{"log", "--force-unrecognized"}, "log", true},
+		{[]string{"--force-unrecognized=true"}, "--force-unrecognized=true", false},
+	} {
+		rest, force := extractForceUnrecognized(tt.args)
+		if force != tt.force || strings.Join(rest, " ") != tt.rest {
+			t.Errorf("extractForceUnrecognized(%q) = %q, %v", tt.args, rest, force)
+		}
+	}
+}
+
+// TestUnrecognizedTabHint checks a write refused as not to a cali log says
+// how to fix the settings or write anyway.
+func TestUnrecognizedTabHint(t *testing.T) {
+	failingLog(t, fmt.Errorf("sheet tab %q %w", "Budget", calio.ErrUnrecognizedTab))
+	_, stderr, code := runCLI(t, "", "q", "--yes", "pushups full 12x2")
+	if code != exitStorage || !strings.Contains(stderr, `sheet tab "Budget"`) || !strings.Contains(stderr, msg("error.unrecognized_tab")) {
+		t.Errorf("a refused write exited %d: %s", code, stderr)
+	}
+	if _, stderr, code := runCLI(t, "", "--force-unrecognized", "history"); code != 0 {
+		t.Errorf("cali --force-unrecognized history exited %d: %s", code, stderr)
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import "context"
+
+// runSheet runs cali sheet format (see SheetsStorage.Format), cali sheet
+// dashboard (see refreshDashboard) and cali sheet rollup (see writeRollup).
+func runSheet(ctx context.Context, args []string) error {
+	if len(args) < 1 || (args[0] != "format" && args[0] != "dashboard" && args[0] != "rollup") {
+		return usageError("usage: cali sheet format | cali sheet dashboard | cali sheet rollup [YYYY-MM]")
+	}
+	if args[0] == "rollup" {
+		if len(args) > 2 {
+			return usageError("usage: cali sheet rollup [YYYY-MM]")
+		}
+		month := ""
+		if len(args) == 2 {
+			month = args[1]
+		}
+		return writeRollup(ctx, month)
+	}
+	if len(args) > 1 {
+		return usageError("cali sheet %s takes no arguments", args[0])
+	}
+	if args[0] == "dashboard" {
+		return refreshDashboard(ctx)
+	}
+
+	storage, err := newSheetsStorage(ctx, "")
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	formatted, err := storage.Format(ctx)
+	if err != nil {
+		return storageError("formatting the sheet", err)
+	}
+	if len(formatted) == 0 {
+		return usageError("%s", msg("sheet.no_tabs"))
+	}
+	for _, title := range formatted {
+		say(msg("sheet.formatted", title))
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// goalTiers holds the three Convict Conditioning standards for a level. The
+// progression standard is the one in goals and the one stored with entries.
+type goalTiers struct {
+	Beginner     string
+	Intermediate string
+	Progression  string
+}
+
+const (
+	tierBeginner     = "beginner"
+	tierIntermediate = "intermediate"
+	tierProgression  = "progression"
+)
+
+// Beginner and intermediate standards from the book. Levels without an entry
+// get defaults derived from their progression goal (see deriveTiers).
+var standards = map[string]map[string]goalTiers{
+	"Pushups": {
+		"Wall":         {"10x1", "25x2", "50x3"},
+		"Incline":      {"10x1", "20x2", "40x3"},
+		"Kneeling":     {"10x1", "15x2", "30x3"},
+		"Half":         {"8x1", "12x2", "25x2"},
+		"Full":         {"5x1", "10x2", "20x2"},
+		"Close":        {"5x1", "10x2", "20x2"},
+		"Uneven":       {"5x1", "10x2", "20x2"},
+		"Half One-Arm": {"5x1", "10x2", "20x2"},
+		"Lever":        {"5x1", "10x2", "20x2"},
+		"One-Arm":      {"5x1", "10x2", "100x1"},
+	},
+	"Squats": {
+		"Shoulderstand":    {"10x1", "25x2", "50x3"},
+		"Jackknife":        {"10x1", "20x2", "40x3"},
+		"Supported":        {"10x1", "15x2", "30x3"},
+		"Half":             {"8x1", "35x2", "50x2"},
+		"Full":             {"5x1", "10x2", "30x2"},
+		"Close":            {"5x1", "10x2", "20x2"},
+		"Uneven":           {"5x1", "10x2", "20x2"},
+		"Half One-Leg":     {"5x1", "10x2", "20x2"},
+		"Assisted One-Leg": {"5x1", "10x2", "20x2"},
+		"One-Leg":          {"5x1", "10x2", "50x2"},
+	},
+	"Pullups": {
+		"Vertical":         {"10x1", "20x2", "40x3"},
+		"Horizontal":       {"10x1", "20x2", "30x3"},
+		"Jackknife":        {"10x1", "15x2", "20x3"},
+		"Half":             {"8x1", "11x2", "15x2"},
+		"Full":             {"5x1", "8x2", "10x2"},
+		"Close":            {"5x1", "8x2", "10x2"},
+		"Uneven":           {"5x1", "7x2", "9x2"},
+		"Half One-Arm":     {"4x1", "6x2", "8x2"},
+		"Assisted One-Arm": {"3x1", "5x2", "7x2"},
+		"One-Arm":          {"1x1", "3x2", "6x2"},
+	},
+}
+
+// resolveTiers returns the standards for a level, deriving the lower tiers
+// from the progression goal when the table has no entry or the user set
+// their own goal.
+func resolveTiers(exercise, level string) goalTiers {
+	if goal, ok := goalOverride(exercise, level); ok {
+		return deriveTiers(goal)
+	}
+	if tiers, ok := standards[exercise][level]; ok {
+		return tiers
+	}
+	return deriveTiers(resolveGoal(exercise, level))
+}
+
+// deriveTiers estimates beginner and intermediate standards from a
+// progression goal, following the book's pattern: holds start at a quarter
+// and half of the time; three-set goals at one set of a fifth and two sets of
+// half the reps; other goals at one set of a third and two sets of two thirds.
+// Unparseable goals keep "-" for the lower tiers.
+func deriveTiers(progression string) goalTiers {
+	tiers := goalTiers{Beginner: "-", Intermediate: "-", Progression: progression}
+	parsed, ok := parseRepsSets(progression)
+	if !ok || parsed.interval() {
+		return tiers
+	}
+
+	fraction := func(n int, f float64) int {
+		return max(1, int(math.Round(float64(n)*f)))
+	}
+
+	if parsed.timed() {
+		longest := int(parsed.longestHold())
+		tiers.Beginner = formatHold(fraction(longest, 0.25))
+		tiers.Intermediate = formatHold(fraction(longest, 0.5))
+		return tiers
+	}
+
+	reps := 0
+	for _, n := range parsed.Sets {
+		reps = max(reps, n)
+	}
+	low, mid := 1.0/3, 2.0/3
+	if len(parsed.Sets) >= 3 {
+		low, mid = 0.2, 0.5
+	}
+	tiers.Beginner = fmt.Sprintf("%dx1", fraction(reps, low))
+	tiers.Intermediate = fmt.Sprintf("%dx2", fraction(reps, mid))
+	return tiers
+}
+
+// tierMet returns the highest standard the logged value reaches, or "" when
+// it reaches none.
+func tierMet(logged string, tiers goalTiers) string {
+	switch {
+	case meetsGoal(logged, tiers.Progression):
+		return tierProgression
+	case meetsGoal(logged, tiers.Intermediate):
+		return tierIntermediate
+	case meetsGoal(logged, tiers.Beginner):
+		return tierBeginner
+	}
+	return ""
+}
+
+func validateStandards() error {
+	for exercise, levels := range standards {
+		if !calio.IsExercise(exercise) {
+			return fmt.Errorf("unknown exercise key in standards: %q", exercise)
+		}
+		for level, tiers := range levels {
+			goal, ok := calio.Goal(exercise, level)
+			if !ok {
+				return fmt.Errorf("unknown level key in standards: %q -> %q", exercise, level)
+			}
+			if tiers.Progression != goal {
+				return fmt.Errorf("progression standard for %q -> %q is %q, goal is %q", exercise, level, tiers.Progression, goal)
+			}
+		}
+	}
+	return nil
+}
+
+func (t goalTiers) String() string {
+	return msg("tier.summary", t.Beginner, t.Intermediate, t.Progression)
+}
+
+// levelsOptions are the flags of cali levels.
+type levelsOptions struct {
+	Matrix   bool
+	NoColor  bool
+	Markdown bool
+}
+
+// newLevelsFlagSet declares the flags of cali levels into opts.
+func newLevelsFlagSet(opts *levelsOptions) *flag.FlagSet {
+	fs := newFlagSet("levels")
+	fs.BoolVar(&opts.Matrix, "matrix", false, "print all strength ladders side by side")
+	fs.BoolVar(&opts.NoColor, "no-color", false, "don't highlight the current level with color")
+	fs.BoolVar(&opts.Markdown, "markdown", false, "print the matrix as a Markdown table")
+	return fs
+}
+
+func listLevels(ctx context.Context, args []string) error {
+	if len(args) > 0 && (args[0] == "set" || args[0] == "unset") {
+		return runLevelPin(ctx, args[0], args[1:])
+	}
+	var opts levelsOptions
+	fs := newLevelsFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	args = fs.Args()
+	if opts.Matrix {
+		if len(args) > 0 {
+			return usageError("usage: cali levels --matrix [--no-color] [--markdown]")
+		}
+		color := !opts.NoColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+		return showLevelMatrix(ctx, color, opts.Markdown)
+	}
+	if opts.NoColor || opts.Markdown {
+		return usageError("--no-color and --markdown only apply to cali levels --matrix")
+	}
+
+	selected := append(calio.Exercises(), calio.MobilityExercises()...)
+	if len(args) > 0 {
+		exercise, ok := normalizeExercise(strings.Join(args, " "))
+		if !ok {
+			return usageError("%s", msg("error.unknown_exercise", strings.Join(args, " ")))
+		}
+		selected = []string{exercise}
+	}
+
+	// Without storage the built-in goals still make a useful list.
+	current := map[string]string{}
+	if storage, err := newStorage(ctx); err != nil {
+		detail("Not showing your goals: %v\n", err)
+	} else {
+		loadGoalOverrides(ctx, storage)
+		loadLevelPins(ctx, storage)
+		current = recentLevels(ctx, storage)
+	}
+	for i, exercise := range selected {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s:\n", exercise)
+		for step, level := range calio.Levels(exercise) {
+			mark := ""
+			if level == current[exercise] {
+				mark = currentLevelMark(exercise)
+			}
+			fmt.Printf("  %2d. %-18s %s%s\n", step+1, level, tierSummary(exercise, level), mark)
+		}
+	}
+	if hasGoalOverrides(selected...) {
+		fmt.Println("\n" + msg("goal.legend"))
+	}
+	return nil
+}
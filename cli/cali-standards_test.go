@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStandardsMatchGoals(t *testing.T) {
+	isolatedHome(t)
+	if err := validateStandards(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeriveTiers(t *testing.T) {
+	tests := []struct {
+		progression string
+		want        goalTiers
+	}{
+		{"50x3", goalTiers{"10x1", "25x2", "50x3"}},
+		{"20x2", goalTiers{"7x1", "13x2", "20x2"}},
+		{"2x1", goalTiers{"1x1", "1x2", "2x1"}},
+		{"1min", goalTiers{"15s", "30s", "1min"}},
+		{"EMOM 10min @ 5", goalTiers{"-", "-", "EMOM 10min @ 5"}},
+		{"as many as you can", goalTiers{"-", "-", "as many as you can"}},
+	}
+	for _, tt := range tests {
+		if got := deriveTiers(tt.progression); got != tt.want {
+			t.Errorf("deriveTiers(%q) = %+v, want %+v", tt.progression, got, tt.want)
+		}
+	}
+}
+
+func TestTierMet(t *testing.T) {
+	isolatedHome(t)
+	tiers := resolveTiers("Pushups", "Full")
+	if tiers != (goalTiers{"5x1", "10x2", "20x2"}) {
+		t.Fatalf("resolveTiers(Pushups, Full) = %+v, want the book's standards", tiers)
+	}
+	for logged, want := range map[string]string{
+		"3x1":   "",
+		"6x1":   tierBeginner,
+		"12x2":  tierIntermediate,
+		"20x2":  tierProgression,
+		"25x3":  tierProgression,
+		"a lot": "",
+	} {
+		if got := tierMet(logged, tiers); got != want {
+			t.Errorf("tierMet(%q) = %q, want %q", logged, got, want)
+		}
+	}
+}
+
+func TestLevelsListsTiers(t *testing.T) {
+	pipedLog(t)
+	stdout, stderr, code := runCLI(t, "", "levels", "pushups")
+	if code != 0 {
+		t.Fatalf("cali levels exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Full") || !strings.Contains(stdout, "beginner 5x1 · intermediate 10x2 · progression 20x2") {
+		t.Errorf("cali levels pushups printed:\n%s", stdout)
+	}
+}
@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+type trainingStats struct {
+	Total         int
+	Last7Days     int
+	DaysSinceLast int // -1 when nothing has been logged
+	GoalsMet      int
+	TotalReps     int      // volume of rep-based work, intervals counted as rounds × reps
+	HoldTime      holdTime // total time of timed holds
+	Intervals     int
+	Deloads       int // distinct dates with a deload session
+	PerExercise   map[string]int
+	Readiness     map[string]int // readiness score by date, of the days that recorded one
+
+	// Warm-ups and mobility entries are counted here only, not in the
+	// strength figures above.
+	Warmups             int
+	Mobility            int
+	MobilityHoldTime    holdTime
+	MobilityPerExercise map[string]int
+}
+
+// statsCollector works out trainingStats one entry at a time, so the log
+// can be streamed rather than read whole.
+type statsCollector struct {
+	stats       trainingStats
+	today       time.Time
+	last        time.Time
+	deloadDates map[string]bool
+}
+
+func newStatsCollector(today time.Time) *statsCollector {
+	return &statsCollector{
+		stats: trainingStats{
+			DaysSinceLast:       -1,
+			PerExercise:         map[string]int{},
+			MobilityPerExercise: map[string]int{},
+			Readiness:           map[string]int{},
+		},
+		today:       today,
+		deloadDates: map[string]bool{},
+	}
+}
+
+func (c *statsCollector) add(entry WorkoutEntry) {
+	entry = calio.Canonical(entry)
+	stats := &c.stats
+	if score := entryReadiness(entry); score > 0 {
+		if _, seen := stats.Readiness[entry.Date]; !seen {
+			stats.Readiness[entry.Date] = score
+		}
+	}
+	switch classifyEntry(entry) {
+	case kindWarmup:
+		stats.Warmups++
+		return
+	case kindMobility:
+		stats.Mobility++
+		stats.MobilityPerExercise[entry.Exercise]++
+		if parsed, ok := parseRepsSets(entry.RepsSets); ok {
+			stats.MobilityHoldTime += parsed.totalHold()
+		}
+		return
+	}
+	if isDeload(entry) {
+		c.deloadDates[entry.Date] = true
+	}
+	stats.Total++
+	stats.PerExercise[entry.Exercise]++
+	if meetsGoal(entry.RepsSets, entry.Goal) {
+		stats.GoalsMet++
+	}
+	if isInterval(entry) {
+		stats.Intervals++
+	}
+	if parsed, ok := parseRepsSets(entry.RepsSets); ok {
+		stats.TotalReps += parsed.totalReps()
+		stats.HoldTime += parsed.totalHold()
+	}
+
+	date, err := time.ParseInLocation(calio.DateLayout, entry.Date, c.today.Location())
+	if err != nil {
+		return
+	}
+	if age := daysBetween(date, truncateToDate(c.today)); age >= 0 && age < 7 {
+		stats.Last7Days++
+	}
+	if date.After(c.last) {
+		c.last = date
+	}
+}
+
+// result returns the stats of the entries added so far.
+func (c *statsCollector) result() trainingStats {
+	stats := c.stats
+	if !c.last.IsZero() {
+		stats.DaysSinceLast = daysBetween(c.last, truncateToDate(c.today))
+	}
+	stats.Deloads = len(c.deloadDates)
+	return stats
+}
+
+func computeStats(entries []WorkoutEntry, today time.Time) trainingStats {
+	collector := newStatsCollector(today)
+	for _, entry := range entries {
+		collector.add(entry)
+	}
+	return collector.result()
+}
+
+// statsExercises returns the exercises present in stats, built-in exercises
+// first in their usual order followed by any others alphabetically.
+func statsExercises(stats trainingStats) []string {
+	var names []string
+	for _, exercise := range calio.Exercises() {
+		if stats.PerExercise[exercise] > 0 {
+			names = append(names, exercise)
+		}
+	}
+
+	var others []string
+	for exercise := range stats.PerExercise {
+		if !calio.IsExercise(exercise) {
+			others = append(others, exercise)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+func truncateToDate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(truncateToDate(to).Sub(truncateToDate(from)).Hours()/24 + 0.5)
+}
+
+// recordLines renders personal records for a terminal width columns wide:
+// one aligned line each when they all fit, otherwise the work and date
+// indented under the exercise and level.
+func recordLines(records []WorkoutEntry, width int) []string {
+	lines := make([]string, len(records))
+	fits := true
+	for i, record := range records {
+		lines[i] = fmt.Sprintf("  %-20s %-18s %s (%s)", record.Exercise, record.Level, loggedWork(record), displayDate(record.Date))
+		fits = fits && cellWidth(lines[i]) <= width
+	}
+	if fits {
+		return lines
+	}
+	lines = lines[:0]
+	for _, record := range records {
+		lines = append(lines, "  "+record.Exercise+" - "+record.Level,
+			fmt.Sprintf("%s%s (%s)", stackedIndent, loggedWork(record), displayDate(record.Date)))
+	}
+	return lines
+}
+
+// statsOptions are the flags of cali --stats.
+type statsOptions struct {
+	Times bool
+}
+
+// newStatsFlagSet declares the flags of cali --stats into opts.
+func newStatsFlagSet(opts *statsOptions) *flag.FlagSet {
+	fs := newFlagSet("stats")
+	fs.BoolVar(&opts.Times, "times", false, "show when you train: sessions per time of day")
+	return fs
+}
+
+func runStats(ctx context.Context, args []string, rng dateRange) error {
+	var opts statsOptions
+	fs := newStatsFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError("usage: cali --stats [--times] [--since <date>] [--until <date>]")
+	}
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	if opts.Times {
+		return showTimes(ctx, storage, rng)
+	}
+	return showStats(ctx, storage, rng)
+}
+
+func showStats(ctx context.Context, storage Storage, rng dateRange) error {
+	perWeek, err := restPerWeek()
+	if err != nil {
+		return usageError("%v", err)
+	}
+	targets, err := configuredTargets()
+	if err != nil {
+		return usageError("%v", err)
+	}
+	// The log is streamed: only the running figures are kept, not the
+	// entries.
+	now := currentTime()
+	collector := newStatsCollector(now)
+	tracker := newRecordTracker()
+	trained := map[string]bool{}
+	err = calio.ForEach(ctx, storage, rng.Since, rng.Until, func(entry WorkoutEntry) error {
+		if calio.FutureDated(entry.Date, now) {
+			return nil
+		}
+		collector.add(entry)
+		trained[entry.Date] = true
+		tracker.add(entry)
+		return nil
+	})
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+
+	stats := collector.result()
+	if stats.Total == 0 && stats.Mobility == 0 && stats.Warmups == 0 {
+		fmt.Println(msg("history.empty"))
+		return errNoResults
+	}
+
+	sayln(msg("stats.header"))
+	sayln(separatorRule())
+	fmt.Print(msg("stats.total", stats.Total))
+	fmt.Print(msg("stats.last_7_days", stats.Last7Days))
+	if stats.DaysSinceLast >= 0 {
+		fmt.Print(msg("stats.days_since", stats.DaysSinceLast))
+	}
+	fmt.Print(msg("stats.goals_met", stats.GoalsMet))
+	fmt.Print(msg("stats.total_reps", stats.TotalReps))
+	fmt.Print(msg("stats.hold_time", stats.HoldTime.minutes()))
+	fmt.Print(msg("stats.intervals", stats.Intervals))
+	fmt.Print(msg("stats.deloads", stats.Deloads))
+	if len(stats.Readiness) > 0 {
+		fmt.Print(msg("stats.readiness", averageReadiness(stats.Readiness), readinessScale, len(stats.Readiness)))
+	}
+	if stats.Warmups > 0 {
+		fmt.Print(msg("stats.warmups", stats.Warmups))
+	}
+	if err := showRestStats(ctx, storage, trained, rng, now, perWeek); err != nil {
+		return err
+	}
+	fmt.Println(msg("stats.per_exercise"))
+	for _, exercise := range statsExercises(stats) {
+		fmt.Printf("  %-20s %d\n", exercise, stats.PerExercise[exercise])
+	}
+
+	records := tracker.records
+	if len(records) > 0 {
+		fmt.Println(msg("stats.records"))
+		var ordered []WorkoutEntry
+		for _, exercise := range statsExercises(stats) {
+			for _, level := range calio.Levels(exercise) {
+				if record, ok := records[exerciseLevel{exercise, level}]; ok {
+					ordered = append(ordered, record)
+				}
+			}
+		}
+		for _, line := range recordLines(ordered, terminalWidth()) {
+			fmt.Println(line)
+		}
+	}
+
+	if stuck := tracker.plateaus(); len(stuck) > 0 {
+		fmt.Print(msg("stats.plateaus", plateauSessions))
+		for _, key := range stuck {
+			fmt.Printf("  %s - %s\n", key.Exercise, key.Level)
+		}
+	}
+
+	// Compliance is always over the last month, whatever --since/--until say.
+	if len(targets) > 0 {
+		results, err := loadCompliance(ctx, storage, targets, now)
+		if err != nil {
+			return storageError("reading workout history", err)
+		}
+		fmt.Println()
+		showCompliance(results)
+	}
+
+	if weeks := weeklyReadiness(stats.Readiness); len(weeks) > 0 {
+		fmt.Println(msg("stats.readiness_weeks"))
+		for _, week := range weeks[max(0, len(weeks)-readinessWeeks):] {
+			fmt.Print(msg("stats.readiness_week", displayDate(week.Monday), week.Average, week.Days))
+		}
+	}
+
+	if stats.Mobility > 0 {
+		fmt.Println(msg("stats.mobility"))
+		fmt.Printf("  %-20s %d\n", msg("stats.sessions"), stats.Mobility)
+		fmt.Printf("  %-20s %.1f min\n", msg("stats.hold_label"), stats.MobilityHoldTime.minutes())
+		for _, exercise := range calio.MobilityExercises() {
+			if count := stats.MobilityPerExercise[exercise]; count > 0 {
+				fmt.Printf("  %-20s %d\n", exercise, count)
+			}
+		}
+	}
+	sayln(separatorRule())
+	return nil
+}
+
+// showRestStats prints the planned rest days in rng and, over the whole log,
+// the current streak. trained holds the dates in rng with any entry.
+func showRestStats(ctx context.Context, storage Storage, trained map[string]bool, rng dateRange, now time.Time, perWeek int) error {
+	days, err := readRestDays(ctx, storage, rng, now.Format(calio.DateLayout))
+	if err != nil {
+		return storageError("reading rest days", err)
+	}
+	if planned := plannedRest(days, trained); len(planned) > 0 {
+		if reasons := restReasons(planned); reasons != "" {
+			fmt.Print(msg("stats.rest_days_reasons", len(planned), reasons))
+		} else {
+			fmt.Print(msg("stats.rest_days", len(planned)))
+		}
+	}
+	// A streak runs up to today, so it needs the log up to today.
+	if rng.isSet() {
+		return nil
+	}
+	if s := currentStreak(trained, days, now, perWeek); s.days() > 0 {
+		fmt.Print(msg("stats.streak", s.days(), s.Trained, s.Rested))
+	}
+	return nil
+}
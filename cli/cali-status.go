@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// statusUnavailable is what cali status --short prints when it can't read
+// the log (offline, unconfigured, ...). Status bars poll the command, so it
+// stays a single line and exits 0 rather than flapping between errors.
+const statusUnavailable = "cali: unavailable"
+
+// trainingStatus is the summary behind cali status.
+type trainingStatus struct {
+	LastDate  string // YYYY-MM-DD of the last strength day, "" if none
+	DaysSince int
+	Next      string // suggested next day letter
+	ThisWeek  int    // training dates since Monday, mobility included
+}
+
+// loadStatus reads the last training day and this week's entries only, so
+// the Sheets backend reads at most the current and previous year's tab
+// (and the last two months when day letters have frequency targets).
+func loadStatus(ctx context.Context, storage Storage, now time.Time) (trainingStatus, error) {
+	day, date, err := storage.LastTrainingDay(ctx)
+	if err != nil {
+		return trainingStatus{}, err
+	}
+
+	today := truncateToDate(now)
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	entries, err := storage.Range(ctx, monday.Format(calio.DateLayout), today.Format(calio.DateLayout))
+	if err != nil {
+		return trainingStatus{}, err
+	}
+	status := summarizeStatus(day, date, entries, now)
+	status.Next = suggestDay(ctx, storage, day)
+	return status, nil
+}
+
+func summarizeStatus(lastDay, lastDate string, week []WorkoutEntry, now time.Time) trainingStatus {
+	status := trainingStatus{Next: nextDay(lastDay)}
+	if last, err := time.ParseInLocation(calio.DateLayout, lastDate, now.Location()); err == nil {
+		status.LastDate = lastDate
+		status.DaysSince = daysBetween(last, now)
+	}
+
+	dates := map[string]bool{}
+	for _, entry := range week {
+		dates[entry.Date] = true
+	}
+	status.ThisWeek = len(dates)
+	return status
+}
+
+// short renders the one-line form for shell prompts and status bars, e.g.
+// "last trained 2d ago (next: B) · 3 this week". Scripts match on it, so it
+// is not translated and its shape must not change.
+func (s trainingStatus) short() string {
+	last := "no workouts yet"
+	switch {
+	case s.LastDate == "":
+	case s.DaysSince <= 0:
+		last = "last trained today"
+	default:
+		last = fmt.Sprintf("last trained %dd ago", s.DaysSince)
+	}
+	return fmt.Sprintf("%s (next: %s) · %d this week", last, s.Next, s.ThisWeek)
+}
+
+// statusOptions are the flags of cali status.
+type statusOptions struct {
+	Short bool
+}
+
+// newStatusFlagSet declares the flags of cali status into opts.
+func newStatusFlagSet(opts *statusOptions) *flag.FlagSet {
+	fs := newFlagSet("status")
+	fs.BoolVar(&opts.Short, "short", false, "print one undecorated line for prompts and status bars")
+	return fs
+}
+
+func runStatus(ctx context.Context, args []string) error {
+	var opts statusOptions
+	fs := newStatusFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError("usage: cali status [--short]")
+	}
+
+	if opts.Short {
+		// No spinner or detail lines around the one line a status bar shows.
+		outputLevel = levelQuiet
+		storage, err := newStorage(ctx)
+		if err != nil {
+			fmt.Println(statusUnavailable)
+			return nil
+		}
+		status, err := loadStatus(ctx, storage, currentTime())
+		if err != nil {
+			fmt.Println(statusUnavailable)
+			return nil
+		}
+		fmt.Println(status.short())
+		return nil
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	status, err := loadStatus(ctx, storage, currentTime())
+	if err != nil {
+		return storageError("reading workout status", err)
+	}
+	if status.LastDate == "" {
+		fmt.Println(msg("history.empty"))
+	} else {
+		fmt.Print(msg("status.last", displayDate(status.LastDate), status.DaysSince))
+	}
+	fmt.Print(msg("status.next", status.Next))
+	fmt.Print(msg("status.this_week", status.ThisWeek))
+	return nil
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// TestStatusShortGolden renders cali status --short for an empty log, a
+// week trained up to today and one last trained days ago. Scripts match
+// the line, so testdata/status.short only changes on purpose.
+func TestStatusShortGolden(t *testing.T) {
+	isolatedHome(t)
+	now := time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC) // a Friday
+	weeks := [][]WorkoutEntry{
+		nil,
+		{
+			{Date: "2026-10-12", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2"},
+			{Date: "2026-10-14", Day: "B", Exercise: "Pullups", Level: "Half", RepsSets: "8x2"},
+			{Date: "2026-10-14", Day: "B", Exercise: "Leg Raises", Level: "Flat Knee", RepsSets: "20x2"},
+			{Date: "2026-10-16", Day: "C", Exercise: "Bridges", Level: "Short", RepsSets: "30x2"},
+		},
+		{
+			{Date: "2026-10-09", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2"},
+			{Date: "2026-10-13", Exercise: "L-sit", Level: "Floor", RepsSets: "60s", Category: calio.CategoryMobility},
+		},
+	}
+	var lines []string
+	for _, entries := range weeks {
+		storage := calio.NewFileStorage(t.TempDir())
+		storage.Now = func() time.Time { return now }
+		for _, entry := range entries {
+			if _, err := storage.Append(context.Background(), entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+		status, err := loadStatus(context.Background(), storage, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, status.short())
+	}
+	lines = append(lines, statusUnavailable)
+
+	checkGolden(t, "status.short", strings.Join(lines, "\n")+"\n")
+}
+
+// TestStatusShortUnavailable checks cali status --short prints its one
+// line and succeeds when the log can't be read.
+func TestStatusShortUnavailable(t *testing.T) {
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CALI_LOG_DIR", notDir)
+	stdout, stderr, code := runCLI(t, "", "status", "--short")
+	if code != 0 || stdout != statusUnavailable+"\n" || stderr != "" {
+		t.Errorf("cali status --short exited %d, printed %q and %q", code, stdout, stderr)
+	}
+}
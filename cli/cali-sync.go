@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// syncIdentity is what makes two entries the same workout for cali sync,
+// wherever they are stored: the date, exercise, level, work, comment and
+// who logged it, with names canonical and spacing trimmed. Row positions,
+// schema markers and fields cali derives, such as the goal, don't count.
+func syncIdentity(e WorkoutEntry) string {
+	sum := sha256.Sum256([]byte(syncNearKey(e) + "\x00" + strings.TrimSpace(e.Comment)))
+	return hex.EncodeToString(sum[:12])
+}
+
+// syncNearKey is the identity without the comment: entries that share it
+// but not their identity are the same workout with a comment edited on one
+// side.
+func syncNearKey(e WorkoutEntry) string {
+	e = calio.Canonical(e)
+	return strings.Join([]string{e.Date, strings.TrimSpace(e.Exercise), strings.TrimSpace(e.Level),
+		strings.TrimSpace(e.RepsSets), strings.TrimSpace(e.Load), strings.TrimSpace(e.User)}, "\x00")
+}
+
+// syncConflict is an entry of the source whose comment differs from that of
+// the same workout at the destination.
+type syncConflict struct {
+	From, To WorkoutEntry
+}
+
+// syncPlan is what copying one side into the other does.
+type syncPlan struct {
+	Missing   []WorkoutEntry // to append to the destination, in source order
+	Conflicts []syncConflict // reported, never resolved
+}
+
+// planSync works out which entries of from are missing in to. Entries are
+// matched by syncIdentity as a multiset: an entry logged twice in from and
+// once in to is missing once, and duplicates in to are left alone. An
+// unmatched entry of from that shares its syncNearKey with an unmatched
+// entry of to is a conflict rather than missing, so an edited comment
+// isn't copied back as a second entry. The result depends only on the
+// arguments.
+func planSync(from, to []WorkoutEntry) syncPlan {
+	have := map[string]int{}
+	for _, entry := range to {
+		have[syncIdentity(entry)]++
+	}
+	var unmatched []WorkoutEntry
+	for _, entry := range from {
+		id := syncIdentity(entry)
+		if have[id] > 0 {
+			have[id]--
+			continue
+		}
+		unmatched = append(unmatched, entry)
+	}
+
+	// Entries of to left over once from is matched are what a conflict
+	// pairs with, each at most once.
+	spare := map[string][]WorkoutEntry{}
+	for _, entry := range to {
+		if id := syncIdentity(entry); have[id] > 0 {
+			have[id]--
+			key := syncNearKey(entry)
+			spare[key] = append(spare[key], entry)
+		}
+	}
+	var plan syncPlan
+	for _, entry := range unmatched {
+		key := syncNearKey(entry)
+		if others := spare[key]; len(others) > 0 {
+			plan.Conflicts = append(plan.Conflicts, syncConflict{From: entry, To: others[0]})
+			spare[key] = others[1:]
+			continue
+		}
+		plan.Missing = append(plan.Missing, entry)
+	}
+	return plan
+}
+
+// syncOptions are the flags of cali sync.
+type syncOptions struct {
+	Pull   bool
+	Push   bool
+	DryRun bool
+}
+
+// newSyncFlagSet declares the flags of cali sync into opts.
+func newSyncFlagSet(opts *syncOptions) *flag.FlagSet {
+	fs := newFlagSet("sync")
+	fs.BoolVar(&opts.Pull, "pull", false, "copy sheet entries missing locally into the local log")
+	fs.BoolVar(&opts.Push, "push", false, "copy local entries missing in the sheet into the sheet")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "show what would be copied without writing anything")
+	return fs
+}
+
+// runSync copies entries one way between the sheet and the local log,
+// whichever CALI_STORAGE selects: --pull from the sheet, --push to it, or
+// both. It prints nothing when nothing is missing on either side, so it can
+// run from a timer; conflicts are listed but are not errors.
+func runSync(ctx context.Context, args []string) error {
+	var opts syncOptions
+	fs := newSyncFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 || (!opts.Pull && !opts.Push) {
+		return usageError("usage: cali sync --pull | --push [--dry-run]")
+	}
+	sheet, err := newSheetsStorage(ctx, selectedSheet)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	local, err := newFileStorage(ctx, selectedSheet)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+
+	if opts.Pull {
+		if err := syncInto(ctx, sheet, local, msg("sync.sheet"), msg("sync.local", local.Dir()), opts.DryRun); err != nil {
+			return err
+		}
+	}
+	if opts.Push {
+		if err := syncInto(ctx, local, sheet, msg("sync.local", local.Dir()), msg("sync.sheet"), opts.DryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncInto appends the entries of from that to is missing, in one batch,
+// and lists them and the conflicts found.
+func syncInto(ctx context.Context, from, to Storage, fromName, toName string, dryRun bool) error {
+	source, err := from.All(ctx)
+	if err != nil {
+		return storageError("reading "+fromName, err)
+	}
+	destination, err := to.All(ctx)
+	if err != nil {
+		return storageError("reading "+toName, err)
+	}
+	plan := planSync(source, destination)
+	row := func(entry WorkoutEntry) string {
+		return "  " + msg("list.row", displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level,
+			workText(entry), entry.Comment)
+	}
+
+	if len(plan.Missing) > 0 {
+		if !dryRun {
+			if _, err := to.AppendBatch(ctx, plan.Missing); err != nil {
+				return storageError("writing "+toName, err)
+			}
+		}
+		key := "sync.copied"
+		if dryRun {
+			key = "sync.would_copy"
+		}
+		fmt.Print(msg(key, len(plan.Missing), fromName, toName))
+		for _, entry := range plan.Missing {
+			fmt.Print(row(entry))
+		}
+	}
+	if len(plan.Conflicts) > 0 {
+		fmt.Print(msg("sync.conflicts", len(plan.Conflicts), fromName, toName))
+		for _, conflict := range plan.Conflicts {
+			fmt.Print(msg("sync.conflict", displayDate(conflict.From.Date), conflict.From.Exercise, conflict.From.Level,
+				conflict.From.RepsSets, fromName, quoteEmpty(conflict.From.Comment), toName, quoteEmpty(conflict.To.Comment)))
+		}
+	}
+	return nil
+}
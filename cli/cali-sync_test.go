@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// synced is an entry as the tests of cali sync log it.
+func synced(date, exercise, level, reps, comment string) WorkoutEntry {
+	return WorkoutEntry{Date: date, Day: "A", Exercise: exercise, Level: level, RepsSets: reps, Comment: comment}
+}
+
+func TestSyncIdentity(t *testing.T) {
+	entry := synced("2026-03-04", "Pushups", "Full", "20x2", "good")
+	same := []WorkoutEntry{
+		// Name case and spacing, and what cali derives or where it is kept.
+		{Date: "2026-03-04", Day: "B", Exercise: "pushups ", Level: " FULL", RepsSets: " 20x2", Comment: "good ", Goal: "20x2", RowIndex: 7, Schema: 4},
+	}
+	for _, other := range same {
+		if syncIdentity(other) != syncIdentity(entry) {
+			t.Errorf("%+v isn't the same workout as %+v", other, entry)
+		}
+	}
+	loaded, user := entry, entry
+	loaded.Load = "+10kg"
+	user.User = "sam"
+	for _, other := range []WorkoutEntry{
+		synced("2026-03-05", "Pushups", "Full", "20x2", "good"),
+		synced("2026-03-04", "Squats", "Full", "20x2", "good"),
+		synced("2026-03-04", "Pushups", "Half", "20x2", "good"),
+		synced("2026-03-04", "Pushups", "Full", "21x2", "good"),
+		synced("2026-03-04", "Pushups", "Full", "20x2", "great"),
+		loaded, user,
+	} {
+		if syncIdentity(other) == syncIdentity(entry) {
+			t.Errorf("%+v is taken for %+v", other, entry)
+		}
+	}
+	edited := synced("2026-03-04", "Pushups", "Full", "20x2", "great")
+	if syncNearKey(edited) != syncNearKey(entry) || syncNearKey(loaded) == syncNearKey(entry) {
+		t.Error("the near key doesn't ignore only the comment")
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	a := synced("2026-03-02", "Pushups", "Full", "20x2", "")
+	b := synced("2026-03-03", "Squats", "Half", "30x2", "knees ok")
+	c := synced("2026-03-04", "Pullups", "Full", "8x2", "")
+	bEdited := synced("2026-03-03", "Squats", "Half", "30x2", "knees sore")
+	bSpelled := synced("2026-03-03", "squats", "half ", "30x2", "knees ok")
+	describe := func(entries []WorkoutEntry) string {
+		var parts []string
+		for _, entry := range entries {
+			parts = append(parts, entry.Date+" "+entry.Exercise+" "+entry.Comment)
+		}
+		return strings.Join(parts, ", ")
+	}
+	tests := []struct {
+		name      string
+		from, to  []WorkoutEntry
+		missing   []WorkoutEntry
+		conflicts int
+	}{
+		{"nothing on either side", nil, nil, nil, 0},
+		{"into an empty side", []WorkoutEntry{a, b}, nil, []WorkoutEntry{a, b}, 0},
+		{"from an empty side", nil, []WorkoutEntry{a, b}, nil, 0},
+		{"overlap", []WorkoutEntry{a, b, c}, []WorkoutEntry{b}, []WorkoutEntry{a, c}, 0},
+		{"the same entries", []WorkoutEntry{a, b}, []WorkoutEntry{b, a}, nil, 0},
+		{"duplicate in the source", []WorkoutEntry{a, a, b}, []WorkoutEntry{a, b}, []WorkoutEntry{a}, 0},
+		{"duplicate in the destination", []WorkoutEntry{a, b}, []WorkoutEntry{a, a, b}, nil, 0},
+		{"both logged twice", []WorkoutEntry{a, a}, []WorkoutEntry{a, a}, nil, 0},
+		{"near match in spelling", []WorkoutEntry{bSpelled}, []WorkoutEntry{b}, nil, 0},
+		{"edited comment", []WorkoutEntry{a, bEdited}, []WorkoutEntry{b}, []WorkoutEntry{a}, 1},
+		{"edited comment the other way", []WorkoutEntry{b}, []WorkoutEntry{a, bEdited}, nil, 1},
+		// The destination's copy is matched by the source's own copy, so
+		// the edited one is new rather than a conflict.
+		{"edited and kept", []WorkoutEntry{b, bEdited}, []WorkoutEntry{b}, []WorkoutEntry{bEdited}, 0},
+		// Each destination entry pairs with one conflict at most.
+		{"two edits of one entry", []WorkoutEntry{bEdited, bEdited}, []WorkoutEntry{b}, []WorkoutEntry{bEdited}, 1},
+	}
+	for _, tt := range tests {
+		plan := planSync(tt.from, tt.to)
+		if describe(plan.Missing) != describe(tt.missing) || len(plan.Conflicts) != tt.conflicts {
+			t.Errorf("%s: missing [%s], %d conflict(s); want [%s], %d", tt.name, describe(plan.Missing), len(plan.Conflicts), describe(tt.missing), tt.conflicts)
+		}
+	}
+	plan := planSync([]WorkoutEntry{bEdited}, []WorkoutEntry{b})
+	if conflict := plan.Conflicts[0]; conflict.From.Comment != "knees sore" || conflict.To.Comment != "knees ok" {
+		t.Errorf("the conflict is %+v", conflict)
+	}
+}
+
+// unreadableStorage fails every read, as a sheet does offline.
+type unreadableStorage struct{ memoryStorage }
+
+func (unreadableStorage) All(ctx context.Context) ([]WorkoutEntry, error) {
+	return nil, errors.New("offline")
+}
+
+func TestSyncInto(t *testing.T) {
+	ctx := context.Background()
+	a := synced("2026-03-02", "Pushups", "Full", "20x2", "")
+	b := synced("2026-03-03", "Squats", "Half", "30x2", "knees ok")
+	from := &memoryStorage{entries: []WorkoutEntry{a, b, synced("2026-03-04", "Pullups", "Full", "8x2", "new grip")}}
+	to := &memoryStorage{entries: []WorkoutEntry{synced("2026-03-03", "Squats", "Half", "30x2", "knees sore")}}
+
+	var err error
+	stdout := captureOutput(t, &os.Stdout, func() { err = syncInto(ctx, from, to, "here", "there", true) })
+	if err != nil || len(to.entries) != 1 || to.batches != 0 {
+		t.Fatalf("--dry-run: %v, wrote %d batch(es)", err, to.batches)
+	}
+	if !strings.Contains(stdout, "Would copy 2 entr(ies) from here to there") || !strings.Contains(stdout, "here has knees ok, there has knees sore") {
+		t.Errorf("--dry-run said:\n%s", stdout)
+	}
+
+	stdout = captureOutput(t, &os.Stdout, func() { err = syncInto(ctx, from, to, "here", "there", false) })
+	if err != nil || len(to.entries) != 3 || to.batches != 1 {
+		t.Fatalf("sync: %v, %d entries in %d batch(es)", err, len(to.entries), to.batches)
+	}
+	if !strings.Contains(stdout, "Copied 2 entr(ies)") || !strings.Contains(stdout, "new grip") {
+		t.Errorf("sync said:\n%s", stdout)
+	}
+
+	// Run again, only the conflict is left to report; with the comments
+	// agreeing, nothing is said at all.
+	stdout = captureOutput(t, &os.Stdout, func() { err = syncInto(ctx, from, to, "here", "there", false) })
+	if err != nil || to.batches != 1 || strings.Contains(stdout, "Copied") || !strings.Contains(stdout, "knees sore") {
+		t.Errorf("second sync: %v, %d batch(es):\n%s", err, to.batches, stdout)
+	}
+	to.entries[0].Comment = "knees ok"
+	stdout = captureOutput(t, &os.Stdout, func() { err = syncInto(ctx, from, to, "here", "there", false) })
+	if err != nil || stdout != "" {
+		t.Errorf("nothing to do: %v, said %q", err, stdout)
+	}
+
+	for _, pair := range [][2]Storage{{&unreadableStorage{}, to}, {from, &unreadableStorage{}}} {
+		captureOutput(t, &os.Stderr, func() { err = syncInto(ctx, pair[0], pair[1], "here", "there", false) })
+		if exitCode(err) != exitStorage || !strings.Contains(err.Error(), "offline") {
+			t.Errorf("a failed read: %v (exit %d)", err, exitCode(err))
+		}
+	}
+}
+
+func TestSyncCommand(t *testing.T) {
+	for _, args := range [][]string{{"sync"}, {"sync", "--dry-run"}, {"sync", "--pull", "extra"}, {"sync", "--both"}} {
+		if _, _, code := runCLI(t, "", args...); code != exitUsage {
+			t.Errorf("cali %s exited %d, want %d", strings.Join(args, " "), code, exitUsage)
+		}
+	}
+	// Without a sheet configured, that is the error.
+	t.Setenv("CALI_SHEET_ID", "")
+	if _, stderr, code := runCLI(t, "", "sync", "--pull"); code != exitStorage || !strings.Contains(stderr, "CALI_SHEET_ID") {
+		t.Errorf("cali sync --pull without a sheet exited %d: %s", code, stderr)
+	}
+}
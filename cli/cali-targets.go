@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// targetWindowDays is the stretch of history compliance is measured over.
+const targetWindowDays = 30
+
+// frequencyTarget is how often an exercise, or a day letter of the rotation,
+// should be trained: at most Every days apart.
+type frequencyTarget struct {
+	Subject string  // exercise name, or day letter when Day is set
+	Day     bool    // Subject is a day letter
+	Every   float64 // days between sessions
+	Rule    string  // as written, e.g. "2x/week"
+}
+
+// label names the target's subject, e.g. "Pushups" or "Day B".
+func (t frequencyTarget) label() string {
+	if t.Day {
+		return msg("targets.day", t.Subject)
+	}
+	return t.Subject
+}
+
+// matches reports whether entry counts as a session of the target.
+func (t frequencyTarget) matches(entry WorkoutEntry) bool {
+	if t.Day {
+		return strings.EqualFold(strings.TrimSpace(entry.Day), t.Subject)
+	}
+	return sameName(entry.Exercise, t.Subject)
+}
+
+// parseTargets reads CALI_TARGETS: targets separated by ';', each a subject
+// and a rule, e.g. "Pushups: every 6 days; B: 2x/week". A subject is an
+// exercise or a day letter; a rule is "every N days" (or "every day") or
+// "Nx/week". Unknown exercises and unreadable rules are errors.
+func parseTargets(spec string) ([]frequencyTarget, error) {
+	var targets []frequencyTarget
+	seen := map[string]bool{}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		subject, rule, ok := strings.Cut(part, ":")
+		subject, rule = strings.TrimSpace(subject), strings.TrimSpace(rule)
+		if !ok || subject == "" || rule == "" {
+			return nil, fmt.Errorf("%q: expected <exercise or day>: <rule>, e.g. \"Pushups: every 6 days\"", part)
+		}
+
+		target := frequencyTarget{Rule: rule}
+		if day := strings.ToUpper(subject); slices.Contains(calio.DayLetters(), day) {
+			target.Subject, target.Day = day, true
+		} else if exercise, ok := normalizeExercise(subject); ok {
+			target.Subject = exercise
+		} else {
+			return nil, fmt.Errorf("%q: unknown exercise or day %q", part, subject)
+		}
+		every, err := parseTargetRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", part, err)
+		}
+		target.Every = every
+		if seen[target.label()] {
+			return nil, fmt.Errorf("%q: %s already has a target", part, target.label())
+		}
+		seen[target.label()] = true
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// parseTargetRule returns the days between sessions a rule asks for.
+func parseTargetRule(rule string) (float64, error) {
+	fields := strings.Fields(strings.ToLower(rule))
+	switch {
+	case len(fields) == 2 && fields[0] == "every" && fields[1] == "day":
+		return 1, nil
+	case len(fields) == 3 && fields[0] == "every" && (fields[2] == "days" || fields[2] == "day"):
+		if n, err := strconv.Atoi(fields[1]); err == nil && n >= 1 {
+			return float64(n), nil
+		}
+	case len(fields) == 1 && strings.HasSuffix(fields[0], "x/week"):
+		if n, err := strconv.Atoi(strings.TrimSuffix(fields[0], "x/week")); err == nil && n >= 1 && n <= 7 {
+			return 7 / float64(n), nil
+		}
+	}
+	return 0, fmt.Errorf(`unknown rule %q (use "every N days" or "Nx/week")`, rule)
+}
+
+// configuredTargets returns the targets set in CALI_TARGETS, if any.
+func configuredTargets() ([]frequencyTarget, error) {
+	spec := strings.TrimSpace(os.Getenv("CALI_TARGETS"))
+	if spec == "" {
+		return nil, nil
+	}
+	targets, err := parseTargets(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CALI_TARGETS: %w", err)
+	}
+	return targets, nil
+}
+
+// targetCompliance is how a target has been kept over the last window.
+type targetCompliance struct {
+	Target     frequencyTarget
+	Sessions   int     // training dates in the window
+	Average    float64 // mean days between sessions, when HasAverage
+	HasAverage bool
+	DaysSince  int // days since the last session, -1 if there was none
+	Behind     bool
+}
+
+// overdue is how many days past its interval the target is; a target never
+// trained is the most overdue of all.
+func (c targetCompliance) overdue() float64 {
+	if c.DaysSince < 0 {
+		return math.Inf(1)
+	}
+	return float64(c.DaysSince) - c.Target.Every
+}
+
+// measureTarget works out compliance with target at now from dates, the
+// distinct training dates of its subject in any order. The average interval
+// covers the gaps ending within the last window days, the first of them
+// reaching back to the latest session before the window. The target is
+// behind when that average is longer than asked for, or when the time since
+// the last session already is.
+func measureTarget(target frequencyTarget, dates []time.Time, now time.Time, window int) targetCompliance {
+	result := targetCompliance{Target: target, DaysSince: -1}
+	today := truncateToDate(now)
+	start := today.AddDate(0, 0, -window)
+
+	var past []time.Time
+	for _, date := range dates {
+		if date = truncateToDate(date); !date.After(today) {
+			past = append(past, date)
+		}
+	}
+	slices.SortFunc(past, func(a, b time.Time) int { return a.Compare(b) })
+	past = slices.CompactFunc(past, time.Time.Equal)
+	if len(past) == 0 {
+		result.Behind = true
+		return result
+	}
+
+	var gaps, total int
+	for i, date := range past {
+		if date.Before(start) {
+			continue
+		}
+		result.Sessions++
+		if i > 0 {
+			gaps++
+			total += daysBetween(past[i-1], date)
+		}
+	}
+	if gaps > 0 {
+		result.Average = float64(total) / float64(gaps)
+		result.HasAverage = true
+	}
+	result.DaysSince = daysBetween(past[len(past)-1], today)
+	result.Behind = float64(result.DaysSince) > target.Every ||
+		(result.HasAverage && result.Average > target.Every)
+	return result
+}
+
+// measureTargets measures every target against entries, which should
+// reach back past the window.
+func measureTargets(targets []frequencyTarget, entries []WorkoutEntry, now time.Time, window int) []targetCompliance {
+	results := make([]targetCompliance, 0, len(targets))
+	for _, target := range targets {
+		var dates []time.Time
+		for _, entry := range entries {
+			if !target.matches(entry) {
+				continue
+			}
+			if date, err := time.ParseInLocation(calio.DateLayout, entry.Date, now.Location()); err == nil {
+				dates = append(dates, date)
+			}
+		}
+		results = append(results, measureTarget(target, dates, now, window))
+	}
+	return results
+}
+
+// mostOverdueDay picks the day letter to train next from results: of the
+// day targets that are behind, the one furthest past its interval, ties
+// going to the letter the rotation reaches first after lastDay. It returns
+// "" when no day target is behind.
+func mostOverdueDay(results []targetCompliance, lastDay string) string {
+	days := calio.DayLetters()
+	after := (slices.Index(days, strings.ToUpper(strings.TrimSpace(lastDay))) + 1) % len(days)
+	rotation := append(days[after:], days[:after]...)
+
+	best, bestOverdue := "", math.Inf(-1)
+	for _, day := range rotation {
+		for _, result := range results {
+			if !result.Target.Day || result.Target.Subject != day || !result.Behind {
+				continue
+			}
+			if overdue := result.overdue(); best == "" || overdue > bestOverdue {
+				best, bestOverdue = day, overdue
+			}
+		}
+	}
+	return best
+}
+
+// loadCompliance measures the configured targets against the log up to now.
+// It reads twice the window so the first gap has a session to start from.
+func loadCompliance(ctx context.Context, storage Storage, targets []frequencyTarget, now time.Time) ([]targetCompliance, error) {
+	today := truncateToDate(now)
+	entries, err := storage.Range(ctx, today.AddDate(0, 0, -2*targetWindowDays).Format(calio.DateLayout),
+		today.Format(calio.DateLayout))
+	if err != nil {
+		return nil, err
+	}
+	return measureTargets(targets, calio.WithoutFuture(entries, now), now, targetWindowDays), nil
+}
+
+// suggestDay is the day letter to train after lastDay: the most overdue day
+// target that is behind, else the next letter of the rotation.
+func suggestDay(ctx context.Context, storage Storage, lastDay string) string {
+	targets, err := configuredTargets()
+	if err != nil || !slices.ContainsFunc(targets, func(t frequencyTarget) bool { return t.Day }) {
+		return nextDay(lastDay)
+	}
+	results, err := loadCompliance(ctx, storage, targets, currentTime())
+	if err != nil {
+		detail("Frequency targets unavailable, following the rotation: %v\n", err)
+		return nextDay(lastDay)
+	}
+	if day := mostOverdueDay(results, lastDay); day != "" {
+		return day
+	}
+	return nextDay(lastDay)
+}
+
+// complianceLine renders one target, e.g. "Pushups  every 6 days  avg 5.5
+// days over 4 session(s)  compliant".
+func complianceLine(result targetCompliance) string {
+	average := msg("targets.no_average")
+	if result.HasAverage {
+		average = msg("targets.average", result.Average, result.Sessions)
+	}
+	status := msg("targets.compliant")
+	switch {
+	case result.DaysSince < 0:
+		status = msg("targets.never")
+	case result.Behind:
+		status = msg("targets.behind", result.DaysSince)
+	}
+	return fmt.Sprintf("  %-20s %-14s %-32s %s", result.Target.label(), result.Target.Rule, average, status)
+}
+
+// showCompliance prints the compliance section shared by cali compliance
+// and cali --stats.
+func showCompliance(results []targetCompliance) {
+	fmt.Print(msg("targets.header", targetWindowDays))
+	for _, result := range results {
+		fmt.Println(complianceLine(result))
+	}
+}
+
+func runCompliance(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		return usageError("usage: cali compliance")
+	}
+	targets, err := configuredTargets()
+	if err != nil {
+		return usageError("%v", err)
+	}
+	if len(targets) == 0 {
+		fmt.Println(msg("targets.none"))
+		return errNoResults
+	}
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	results, err := loadCompliance(ctx, storage, targets, currentTime())
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	showCompliance(results)
+	return nil
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := parseTargets(" pushups: every 6 days;; b: 2x/week; C : every day ; Squats: Every 1 Day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []frequencyTarget{
+		{Subject: "Pushups", Every: 6, Rule: "every 6 days"},
+		{Subject: "B", Day: true, Every: 3.5, Rule: "2x/week"},
+		{Subject: "C", Day: true, Every: 1, Rule: "every day"},
+		{Subject: "Squats", Every: 1, Rule: "Every 1 Day"},
+	}
+	if len(targets) != len(want) {
+		t.Fatalf("parseTargets = %+v", targets)
+	}
+	for i := range want {
+		if targets[i] != want[i] {
+			t.Errorf("target %d = %+v, want %+v", i, targets[i], want[i])
+		}
+	}
+
+	for spec, want := range map[string]string{
+		"Pushups":                     "expected <exercise or day>",
+		"Pushups:":                    "expected <exercise or day>",
+		": every 6 days":              "expected <exercise or day>",
+		"Burpees: every 6 days":       `unknown exercise or day "Burpees"`,
+		"Pushups: every 0 days":       "unknown rule",
+		"Pushups: every six days":     "unknown rule",
+		"Pushups: 8x/week":            "unknown rule",
+		"Pushups: weekly":             "unknown rule",
+		"B: 2x/week; b: every 3 days": "Day B already has a target",
+	} {
+		if _, err := parseTargets(spec); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseTargets(%q) = %v, want an error containing %q", spec, err, want)
+		}
+	}
+}
+
+// TestMeasureTarget measures irregular histories: unsorted, with a
+// duplicate date, a session just before the window, one in the future and
+// gaps of every length.
+func TestMeasureTarget(t *testing.T) {
+	now := time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC)
+	day := func(date string) time.Time {
+		parsed, err := time.Parse(calio.DateLayout, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed.Add(9 * time.Hour)
+	}
+	irregular := []time.Time{
+		day("2026-10-01"), day("2026-09-18"), day("2026-10-05"), day("2026-10-20"),
+		day("2026-09-10"), day("2026-10-01"), day("2026-09-20"),
+	}
+	every := func(days float64) frequencyTarget { return frequencyTarget{Subject: "Pushups", Every: days} }
+
+	for _, tc := range []struct {
+		name   string
+		target frequencyTarget
+		dates  []time.Time
+		want   targetCompliance
+	}{
+		// Gaps of 8 (from before the window), 2, 11 and 4 days.
+		{"irregular", every(6), irregular, targetCompliance{Sessions: 4, Average: 6.25, HasAverage: true, DaysSince: 11, Behind: true}},
+		{"irregular kept", every(14), irregular, targetCompliance{Sessions: 4, Average: 6.25, HasAverage: true, DaysSince: 11}},
+		{"average too long", every(7), []time.Time{day("2026-09-20"), day("2026-10-05"), day("2026-10-15")}, targetCompliance{Sessions: 3, Average: 12.5, HasAverage: true, DaysSince: 1, Behind: true}},
+		{"one session", every(6), []time.Time{day("2026-10-12")}, targetCompliance{Sessions: 1, DaysSince: 4}},
+		{"today", every(1), []time.Time{day("2026-10-16"), day("2026-10-16")}, targetCompliance{Sessions: 1, DaysSince: 0}},
+		{"only before the window", every(6), []time.Time{day("2026-08-01"), day("2026-09-01")}, targetCompliance{DaysSince: 45, Behind: true}},
+		{"only in the future", every(6), []time.Time{day("2026-10-17")}, targetCompliance{DaysSince: -1, Behind: true}},
+		{"never", every(6), nil, targetCompliance{DaysSince: -1, Behind: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.want.Target = tc.target
+			if got := measureTarget(tc.target, tc.dates, now, 30); got != tc.want {
+				t.Errorf("measureTarget = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMostOverdueDay(t *testing.T) {
+	dayTarget := func(letter string, every float64, daysSince int, behind bool) targetCompliance {
+		return targetCompliance{
+			Target:    frequencyTarget{Subject: letter, Day: true, Every: every},
+			DaysSince: daysSince,
+			Behind:    behind,
+		}
+	}
+	exercise := targetCompliance{Target: frequencyTarget{Subject: "Pushups", Every: 1}, DaysSince: 30, Behind: true}
+
+	for _, tc := range []struct {
+		name    string
+		results []targetCompliance
+		lastDay string
+		want    string
+	}{
+		{"furthest past its interval", []targetCompliance{dayTarget("A", 3, 6, true), dayTarget("B", 2, 7, true), dayTarget("C", 7, 2, false)}, "B", "B"},
+		{"interval counts, not days", []targetCompliance{dayTarget("A", 3, 6, true), dayTarget("B", 7, 8, true)}, "C", "A"},
+		{"tie goes to the rotation", []targetCompliance{dayTarget("A", 3, 5, true), dayTarget("C", 4, 6, true)}, "A", "C"},
+		{"tie after C wraps", []targetCompliance{dayTarget("A", 3, 5, true), dayTarget("C", 4, 6, true)}, "C", "A"},
+		{"never trained first", []targetCompliance{dayTarget("A", 3, 60, true), dayTarget("B", 3, -1, true)}, "B", "B"},
+		{"none behind", []targetCompliance{dayTarget("A", 3, 1, false), dayTarget("B", 3, 2, false)}, "A", ""},
+		{"exercises don't pick days", []targetCompliance{exercise}, "A", ""},
+		{"no last day", []targetCompliance{dayTarget("B", 3, 5, true)}, "", "B"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mostOverdueDay(tc.results, tc.lastDay); got != tc.want {
+				t.Errorf("mostOverdueDay = %q, want %q", got, tc.want)
+			}
+		})
+	}
+	if never := dayTarget("A", 3, -1, true).overdue(); !math.IsInf(never, 1) {
+		t.Errorf("overdue of a day never trained = %v", never)
+	}
+}
+
+// TestComplianceCommand checks cali compliance, the section of cali
+// --stats, the next day cali status suggests and the config error.
+func TestComplianceCommand(t *testing.T) {
+	storage := pipedLog(t)
+	today := truncateToDate(currentTime())
+	for _, entry := range []struct {
+		daysAgo  int
+		day      string
+		exercise string
+	}{
+		{20, "B", "Pullups"},
+		{14, "A", "Pushups"},
+		{9, "A", "Pushups"},
+		{4, "A", "Pushups"},
+		{1, "C", "Squats"},
+	} {
+		date := today.AddDate(0, 0, -entry.daysAgo).Format(calio.DateLayout)
+		if _, err := storage.Append(context.Background(), WorkoutEntry{Date: date, Day: entry.day, Exercise: entry.exercise, Level: "Full", RepsSets: "10x2"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("CALI_TARGETS", "Pushups: every 6 days; B: 2x/week; C: every 7 days")
+
+	stdout, stderr, code := runCLI(t, "", "compliance")
+	want := []string{
+		"Frequency targets (last 30 days):",
+		"Pushups              every 6 days   avg 5.0 days over 3 session(s)   compliant",
+		"Day B                2x/week        too few sessions for an average  behind (last 20 day(s) ago)",
+		"Day C                every 7 days   too few sessions for an average  compliant",
+	}
+	for _, line := range want {
+		if code != 0 || !strings.Contains(stdout, line) {
+			t.Errorf("cali compliance exited %d, printed\n%s%s\nwant %q", code, stdout, stderr, line)
+		}
+	}
+	if stdout, _, _ := runCLI(t, "", "--stats"); !strings.Contains(stdout, want[2]) {
+		t.Errorf("cali --stats has no compliance section:\n%s", stdout)
+	}
+	// After C the rotation says A, but B is the day that is behind.
+	if stdout, _, _ := runCLI(t, "", "status", "--short"); !strings.Contains(stdout, "next: B") {
+		t.Errorf("cali status --short = %q, want the overdue day next", stdout)
+	}
+
+	if _, _, code := runCLI(t, "", "compliance", "extra"); code != exitUsage {
+		t.Errorf("cali compliance extra exited %d", code)
+	}
+	t.Setenv("CALI_TARGETS", "Burpees: every 3 days")
+	if _, stderr, code := runCLI(t, "", "today"); code != exitUsage || !strings.Contains(stderr, `Config error: invalid CALI_TARGETS: "Burpees: every 3 days": unknown exercise or day "Burpees"`) {
+		t.Errorf("cali with an unknown target exercise exited %d: %s", code, stderr)
+	}
+	t.Setenv("CALI_TARGETS", "")
+	if stdout, _, code := runCLI(t, "", "compliance"); code != 0 || !strings.Contains(stdout, "No frequency targets set") {
+		t.Errorf("cali compliance without targets exited %d, printed %q", code, stdout)
+	}
+}
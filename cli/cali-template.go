@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// templatePrefix starts the settings that define workout templates:
+// CALI_TEMPLATE_A is the template cali template A runs.
+const templatePrefix = "CALI_TEMPLATE_"
+
+// currentLevelWord stands for the level an exercise is currently trained at
+// in a template item.
+const currentLevelWord = "current"
+
+// templateItem is one exercise of a workout template.
+type templateItem struct {
+	Exercise string
+	Level    string // "" for the current level
+	Planned  string // Reps×Sets pre-filled at the prompt
+	Optional bool   // skipped unless a value is typed
+}
+
+// workoutTemplate is a named session logged with cali template.
+type workoutTemplate struct {
+	Name  string
+	Items []templateItem
+}
+
+// parseTemplate reads the value of a template setting: items separated by
+// ';', each "exercise, level or current, planned[, optional]", e.g.
+// "Pushups, current, 12x2; Bridges, Short, 30s x2, optional". The planned
+// value may itself be a per-set list such as "8,7,6".
+func parseTemplate(name, spec string) (workoutTemplate, error) {
+	tmpl := workoutTemplate{Name: name}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.Split(part, ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		var item templateItem
+		if last := len(fields) - 1; last >= 3 && strings.EqualFold(fields[last], "optional") {
+			item.Optional = true
+			fields = fields[:last]
+		}
+		if len(fields) < 3 {
+			return workoutTemplate{}, fmt.Errorf("%q: expected <exercise>, <level or current>, <planned>[, optional]", part)
+		}
+
+		exercise, ok := matchExercise(fields[0])
+		if !ok {
+			return workoutTemplate{}, fmt.Errorf("%q: unknown exercise %q", part, fields[0])
+		}
+		item.Exercise = exercise
+		if !strings.EqualFold(fields[1], currentLevelWord) {
+			level, ok := matchLevel(exercise, fields[1])
+			if !ok {
+				return workoutTemplate{}, fmt.Errorf("%q: unknown level %q of %s", part, fields[1], exercise)
+			}
+			item.Level = level
+		}
+
+		item.Planned = strings.Join(fields[2:], ",")
+		if item.Level != "" {
+			reps, err := quickReps(exercise, item.Level, item.Planned)
+			if err != nil {
+				return workoutTemplate{}, fmt.Errorf("%q: %w", part, err)
+			}
+			item.Planned = reps
+		} else if parsed, ok := parseRepsSets(item.Planned); !ok || parsed.interval() {
+			return workoutTemplate{}, fmt.Errorf("%q: %s", part, msg("quick.bad_reps", item.Planned))
+		}
+		tmpl.Items = append(tmpl.Items, item)
+	}
+	if len(tmpl.Items) == 0 {
+		return workoutTemplate{}, errors.New("no items")
+	}
+	return tmpl, nil
+}
+
+// configuredTemplates returns the templates set by CALI_TEMPLATE_<name>
+// variables, by name.
+func configuredTemplates(environ []string) ([]workoutTemplate, error) {
+	var templates []workoutTemplate
+	for _, variable := range environ {
+		key, value, _ := strings.Cut(variable, "=")
+		name, ok := strings.CutPrefix(key, templatePrefix)
+		if !ok || name == "" || strings.TrimSpace(value) == "" {
+			continue
+		}
+		tmpl, err := parseTemplate(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		templates = append(templates, tmpl)
+	}
+	slices.SortFunc(templates, func(a, b workoutTemplate) int { return strings.Compare(a.Name, b.Name) })
+	return templates, nil
+}
+
+// templateReps reads the answer to an item's prompt against its planned
+// value: a bare number of reps keeps the planned number of sets, so "10"
+// for a planned 12x2 is 10x2. Anything else is read like cali q's reps.
+func templateReps(exercise, level, planned, input string) (string, error) {
+	if reps, err := strconv.Atoi(input); err == nil && reps > 0 && !hasTimedGoal(exercise, level) {
+		if parsed, ok := parseRepsSets(planned); ok && len(parsed.Sets) > 1 && len(parsed.Holds) == 0 {
+			return fmt.Sprintf("%dx%d", reps, len(parsed.Sets)), nil
+		}
+	}
+	return quickReps(exercise, level, input)
+}
+
+// logTemplate steps through tmpl's items, reading the work done for each
+// from reader, and appends the session as one batch after a confirmation.
+// Enter takes the planned value, or skips an optional item; "-" skips any
+// item.
+func logTemplate(ctx context.Context, storage Storage, reader *bufio.Reader, tmpl workoutTemplate) error {
+	loadGoalOverrides(ctx, storage)
+	loadLevelPins(ctx, storage)
+	current := recentLevels(ctx, storage)
+
+	date := currentTime().Format(calio.DateLayout)
+	day := strings.ToUpper(tmpl.Name)
+	if !slices.Contains(calio.DayLetters(), day) {
+		day = quickDay(ctx, storage, date)
+	}
+	sayln(msg("template.header", tmpl.Name, len(tmpl.Items), displayDate(date)))
+
+	var entries []WorkoutEntry
+	for i, item := range tmpl.Items {
+		level := item.Level
+		if level == "" {
+			level = current[item.Exercise]
+		}
+		if level == "" {
+			promptln(msg("template.no_current", item.Exercise))
+			level = chooseLevel(reader, item.Exercise, "", false, false)
+		}
+
+		var reps string
+		for reps == "" {
+			if item.Optional {
+				prompt(msg("template.item_optional", i+1, len(tmpl.Items), item.Exercise, level, item.Planned))
+			} else {
+				prompt(msg("template.item", i+1, len(tmpl.Items), item.Exercise, level, item.Planned))
+			}
+			input, err := reader.ReadString('\n')
+			if err != nil && input == "" {
+				return inputClosed()
+			}
+			input = strings.TrimSpace(input)
+			if input == "-" || (input == "" && item.Optional) {
+				break
+			}
+			if input == "" {
+				input = item.Planned
+			}
+			if reps, err = templateReps(item.Exercise, level, item.Planned, input); err != nil {
+				promptln(err)
+			}
+		}
+		if reps == "" {
+			promptln(msg("template.skipped", item.Exercise))
+			continue
+		}
+
+		entry := WorkoutEntry{
+			Date:     date,
+			Day:      day,
+			Exercise: item.Exercise,
+			Level:    level,
+			RepsSets: reps,
+			Goal:     resolveGoal(item.Exercise, level),
+			Type:     calio.TypeStraightSets,
+			Category: calio.CategoryStrength,
+		}
+		if slices.Contains(calio.MobilityExercises(), item.Exercise) {
+			entry.Day, entry.Category = "", calio.CategoryMobility
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		sayln(msg("template.nothing"))
+		return errCancelled
+	}
+	for _, entry := range entries {
+		prompt(msg("list.row", displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level,
+			workText(entry), entry.Comment))
+	}
+	prompt(msg("template.confirm", len(entries)))
+	answer, _ := reader.ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	if answer != "" && !slices.Contains(strings.Split(msg("answer.yes"), ","), answer) {
+		promptln(msg("remove.cancelled"))
+		return errCancelled
+	}
+
+	if err := checkFutureDates(entries, currentTime()); err != nil {
+		return err
+	}
+	entries = markAchievements(ctx, storage, entries)
+	saved, err := storage.AppendBatch(ctx, entries)
+	if err != nil {
+		keepUnsaved(err, entries...)
+		return storageError("writing workouts", err)
+	}
+	journal.record(saved)
+	sayln(msg("log.logged"))
+	for _, entry := range saved {
+		fmt.Print(msg("log.saved", displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level, entry.RepsSets))
+		if isAchievement(entry) {
+			say(msg("log.achievement", entry.Exercise, entry.Level))
+		}
+	}
+	return nil
+}
+
+func runTemplate(ctx context.Context, args []string) error {
+	templates, err := configuredTemplates(os.Environ())
+	if err != nil {
+		return usageError("%v", err)
+	}
+	if len(args) != 1 {
+		if len(args) == 0 && len(templates) > 0 {
+			fmt.Println(msg("template.list"))
+			for _, tmpl := range templates {
+				fmt.Print(msg("template.list_row", tmpl.Name, len(tmpl.Items)))
+			}
+			return nil
+		}
+		return usageError("usage: cali template <name> (templates are set as %s<name> in the config)", templatePrefix)
+	}
+	i := slices.IndexFunc(templates, func(t workoutTemplate) bool { return strings.EqualFold(t.Name, args[0]) })
+	if i < 0 {
+		return usageError("%s", msg("template.unknown", args[0], templatePrefix+strings.ToUpper(args[0])))
+	}
+
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	release, err := guardSession(reader)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return logTemplate(ctx, storage, reader, templates[i])
+}
@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// memoryStorage keeps entries in a slice, in the order they were stored.
+type memoryStorage struct {
+	entries []WorkoutEntry
+	batches int // calls to AppendBatch
+}
+
+func (m *memoryStorage) Append(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, error) {
+	entry.RowIndex = int64(len(m.entries))
+	m.entries = append(m.entries, entry)
+	return entry, nil
+}
+
+func (m *memoryStorage) AppendBatch(ctx context.Context, entries []WorkoutEntry) ([]WorkoutEntry, error) {
+	m.batches++
+	saved := make([]WorkoutEntry, len(entries))
+	for i, entry := range entries {
+		saved[i], _ = m.Append(ctx, entry)
+	}
+	return saved, nil
+}
+
+func (m *memoryStorage) Recent(ctx context.Context, limit int) ([]WorkoutEntry, error) {
+	return slices.Clone(m.entries[max(0, len(m.entries)-limit):]), nil
+}
+
+func (m *memoryStorage) All(ctx context.Context) ([]WorkoutEntry, error) {
+	return slices.Clone(m.entries), nil
+}
+
+func (m *memoryStorage) Range(ctx context.Context, since, until string) ([]WorkoutEntry, error) {
+	var entries []WorkoutEntry
+	for _, entry := range m.entries {
+		if (since == "" || entry.Date >= since) && (until == "" || entry.Date <= until) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (m *memoryStorage) SearchByDate(ctx context.Context, date string) ([]WorkoutEntry, error) {
+	return m.Range(ctx, date, date)
+}
+
+func (m *memoryStorage) RemoveByDateIndex(ctx context.Context, date string, index int) error {
+	return errors.New("memoryStorage doesn't remove")
+}
+
+func (m *memoryStorage) LastTrainingDay(ctx context.Context) (string, string, error) {
+	today := currentTime().Format(calio.DateLayout)
+	day, date := "", ""
+	for _, entry := range m.entries {
+		if entry.Category != calio.CategoryMobility && entry.Date <= today && entry.Date >= date {
+			day, date = entry.Day, entry.Date
+		}
+	}
+	return day, date, nil
+}
+
+const dayATemplate = "Pushups, current, 12x2; Squats, Half, 12x2; Bridge Hold, Straight, 30s, optional"
+
+func TestParseTemplate(t *testing.T) {
+	tmpl, err := parseTemplate("A", " pushups , CURRENT, 12x2 ;; Squats, 4, 8,7,6; Bridge Hold, straight, 30s, Optional ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []templateItem{
+		{Exercise: "Pushups", Planned: "12x2"},
+		{Exercise: "Squats", Level: "Half", Planned: "8,7,6"},
+		{Exercise: "Bridge Hold", Level: "Straight", Planned: "30s", Optional: true},
+	}
+	if tmpl.Name != "A" || !slices.Equal(tmpl.Items, want) {
+		t.Errorf("parseTemplate = %+v, want items %+v", tmpl, want)
+	}
+
+	for spec, want := range map[string]string{
+		"":                                     "no items",
+		" ; ":                                  "no items",
+		"Pushups, current":                     "expected <exercise>, <level or current>, <planned>",
+		"Pushups, optional":                    "expected <exercise>, <level or current>, <planned>",
+		"Burpees, current, 10x2":               `unknown exercise "Burpees"`,
+		"Pushups, Diamond, 10x2":               `unknown level "Diamond" of Pushups`,
+		"Pushups, current, lots":               "lots",
+		"Pushups, current, 10x2; Squats, Full": "expected <exercise>",
+	} {
+		if _, err := parseTemplate("A", spec); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseTemplate(%q) = %v, want an error containing %q", spec, err, want)
+		}
+	}
+}
+
+func TestConfiguredTemplates(t *testing.T) {
+	templates, err := configuredTemplates([]string{
+		"CALI_TEMPLATE_legs=Squats, current, 20x2",
+		"HOME=/home/ziad",
+		"CALI_TEMPLATE_=Pushups, current, 10x2",
+		"CALI_TEMPLATE_B= ",
+		"CALI_TEMPLATE_A=" + dayATemplate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+	}
+	if !slices.Equal(names, []string{"A", "legs"}) {
+		t.Errorf("configuredTemplates = %q", names)
+	}
+	_, err = configuredTemplates([]string{"CALI_TEMPLATE_A=Pushups, Diamond, 10x2"})
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid CALI_TEMPLATE_A: ") {
+		t.Errorf("configuredTemplates with an unknown level = %v", err)
+	}
+}
+
+func TestTemplateReps(t *testing.T) {
+	for _, tc := range []struct{ level, planned, input, want string }{
+		{"Full", "12x2", "10", "10x2"},
+		{"Full", "12x2", "10x3", "10x3"},
+		{"Full", "12", "10", "10x1"},
+		{"Full", "8,7,6", "9", "9x3"},
+		{"Full", "12x2", "9,8", "9,8"},
+	} {
+		if got, err := templateReps("Pushups", tc.level, tc.planned, tc.input); err != nil || got != tc.want {
+			t.Errorf("templateReps(%q, %q) = %q, %v, want %q", tc.planned, tc.input, got, err, tc.want)
+		}
+	}
+	if _, err := templateReps("Pushups", "Full", "12x2", "many"); err == nil {
+		t.Errorf("templateReps took %q", "many")
+	}
+}
+
+// TestLogTemplate runs the three items of a day A template against an
+// in-memory log: a number for the reps of each planned set, Enter for the
+// planned value and an optional item given and skipped, then an item
+// skipped with "-", a session with everything skipped and one declined.
+func TestLogTemplate(t *testing.T) {
+	isolatedHome(t)
+	quiet(t)
+	withGoalOverrides(t, nil)
+	withLevelPins(t, nil)
+	ctx := context.Background()
+	tmpl, err := parseTemplate("A", dayATemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	today := currentTime().Format(calio.DateLayout)
+	yesterday := currentTime().AddDate(0, 0, -1).Format(calio.DateLayout)
+
+	run := func(script string) (*memoryStorage, []WorkoutEntry, error) {
+		t.Helper()
+		storage := &memoryStorage{}
+		storage.Append(ctx, WorkoutEntry{Date: yesterday, Day: "C", Exercise: "Pushups", Level: "Full", RepsSets: "12x2"})
+		var err error
+		captureOutput(t, &os.Stderr, func() {
+			captureOutput(t, &os.Stdout, func() {
+				err = logTemplate(ctx, storage, bufio.NewReader(strings.NewReader(script)), tmpl)
+			})
+		})
+		return storage, storage.entries[1:], err
+	}
+	summary := func(entries []WorkoutEntry) []string {
+		var got []string
+		for _, entry := range entries {
+			got = append(got, strings.Join([]string{entry.Date, entry.Day, entry.Exercise, entry.Level, entry.RepsSets, entry.Goal, entry.Category}, "|"))
+		}
+		return got
+	}
+
+	storage, saved, err := run("10\n\n45s\ny\n")
+	want := []string{
+		today + "|A|Pushups|Full|10x2|" + resolveGoal("Pushups", "Full") + "|strength",
+		today + "|A|Squats|Half|12x2|" + resolveGoal("Squats", "Half") + "|strength",
+		today + "||Bridge Hold|Straight|45s|" + resolveGoal("Bridge Hold", "Straight") + "|mobility",
+	}
+	if err != nil || storage.batches != 1 || !slices.Equal(summary(saved), want) {
+		t.Errorf("logTemplate = %v after %d batch(es), saved\n%s\nwant\n%s", err, storage.batches,
+			strings.Join(summary(saved), "\n"), strings.Join(want, "\n"))
+	}
+
+	// Bad reps are asked again; "-" skips, Enter skips the optional item
+	// and Enter takes the default answer to the confirmation.
+	storage, saved, err = run("-\nlots\n15\n\n\n")
+	if want := []string{today + "|A|Squats|Half|15x2|" + resolveGoal("Squats", "Half") + "|strength"}; err != nil || storage.batches != 1 || !slices.Equal(summary(saved), want) {
+		t.Errorf("logTemplate with skips = %v, saved %q", err, summary(saved))
+	}
+
+	for script, name := range map[string]string{
+		"-\n-\n\n":  "everything skipped",
+		"\n\n\nn\n": "declined",
+	} {
+		storage, saved, err := run(script)
+		if !errors.Is(err, errCancelled) || storage.batches != 0 || len(saved) != 0 {
+			t.Errorf("logTemplate with %s = %v, saved %q", name, err, summary(saved))
+		}
+	}
+	if storage, _, err := run("10\n"); err == nil || storage.batches != 0 {
+		t.Errorf("logTemplate at the end of input = %v after %d batch(es)", err, storage.batches)
+	}
+}
+
+// TestTemplateCommand runs cali template against the local log and checks
+// the list, the unknown template and the config error.
+func TestTemplateCommand(t *testing.T) {
+	storage := pipedLog(t)
+	t.Setenv("CALI_TEMPLATE_A", dayATemplate)
+	t.Setenv("CALI_TEMPLATE_legs", "Squats, Full, 20x2")
+
+	stdout, stderr, code := runCLI(t, "", "template")
+	if code != 0 || !strings.Contains(stdout, "Templates:\n  A          3 item(s)\n  legs       1 item(s)\n") {
+		t.Errorf("cali template exited %d, printed %q %s", code, stdout, stderr)
+	}
+	if _, stderr, code := runCLI(t, "", "template", "push"); code != exitUsage || !strings.Contains(stderr, `No template "push". Define it as CALI_TEMPLATE_PUSH in the config.`) {
+		t.Errorf("cali template push exited %d: %s", code, stderr)
+	}
+	if _, _, code := runCLI(t, "", "template", "A", "B"); code != exitUsage {
+		t.Errorf("cali template A B exited %d", code)
+	}
+
+	stdout, stderr, code = runCLI(t, "18\n", "template", "LEGS")
+	if code != 0 || !strings.Contains(stdout, "Squats - Full | 18x2") {
+		t.Errorf("cali template LEGS exited %d, printed %q %s", code, stdout, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 1 || entries[0].RepsSets != "18x2" {
+		t.Errorf("cali template LEGS logged %+v", entries)
+	}
+
+	t.Setenv("CALI_TEMPLATE_A", "Pushups, Diamond, 10x2")
+	if _, stderr, code := runCLI(t, "", "today"); code != exitUsage || !strings.Contains(stderr, `Config error: invalid CALI_TEMPLATE_A: "Pushups, Diamond, 10x2": unknown level "Diamond" of Pushups`) {
+		t.Errorf("cali with an invalid template exited %d: %s", code, stderr)
+	}
+}
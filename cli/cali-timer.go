@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultRestTime is what cali timer counts down without a duration.
+const defaultRestTime = 90 * time.Second
+
+// restTimer counts a rest down on out and gives notice when it ends. after
+// is time.After, replaceable so the countdown can run without waiting.
+type restTimer struct {
+	out    io.Writer
+	notify notifier // nil rings the terminal bell only
+	after  func(d time.Duration) <-chan time.Time
+}
+
+// run counts rest down, redrawing the time left each second, then rings
+// the terminal bell and notifies. A failed notification only warns: the
+// rest is over either way. When ctx ends first, run returns its error.
+func (t restTimer) run(ctx context.Context, rest time.Duration) error {
+	for left := rest; left > 0; left -= time.Second {
+		fmt.Fprintf(t.out, "\r%s ", msg("timer.left", formatClock(left)))
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(t.out)
+			return ctx.Err()
+		case <-t.after(min(time.Second, left)):
+		}
+	}
+	fmt.Fprintf(t.out, "\r%s\a\n", msg("timer.done"))
+	if t.notify == nil {
+		return nil
+	}
+	err := t.notify.Notify(ctx, msg("timer.notify_title"), msg("timer.notify_message", formatHold(int(rest/time.Second))))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: notification failed: %v\n", err)
+	}
+	return nil
+}
+
+// formatClock renders a countdown as minutes and seconds, e.g. "1:05".
+func formatClock(d time.Duration) string {
+	seconds := int((d + time.Second - 1) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// timerOptions are the flags of cali timer.
+type timerOptions struct {
+	NoNotify bool
+}
+
+// newTimerFlagSet declares the flags of cali timer into opts.
+func newTimerFlagSet(opts *timerOptions) *flag.FlagSet {
+	fs := newFlagSet("timer")
+	fs.BoolVar(&opts.NoNotify, "no-notify", false, "only ring the terminal bell, whatever CALI_NOTIFY says")
+	return fs
+}
+
+// runTimer counts down a rest between sets: the duration given as "90s",
+// "2min" or "1:30", or defaultRestTime.
+func runTimer(ctx context.Context, args []string) error {
+	var opts timerOptions
+	fs := newTimerFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 1 {
+		return usageError("usage: cali timer [--no-notify] [duration]")
+	}
+	rest := defaultRestTime
+	if fs.NArg() == 1 {
+		seconds, ok := parseHold(strings.ToLower(strings.ReplaceAll(fs.Arg(0), " ", "")))
+		if !ok {
+			return usageError("%s", msg("timer.invalid", fs.Arg(0)))
+		}
+		rest = time.Duration(seconds) * time.Second
+	}
+	settings, err := configuredNotify()
+	if err != nil {
+		return usageError("%v", err)
+	}
+
+	timer := restTimer{out: promptWriter(), after: time.After}
+	if !opts.NoNotify && (settings.Desktop || settings.Sound) {
+		timer.notify = commandNotifier{settings: settings}
+	}
+	return timer.run(ctx, rest)
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// cali --stats --times counts sessions in timeBuckets parts of the day,
+// timeBucketHours each.
+const (
+	timeBucketHours = 2
+	timeBuckets     = 24 / timeBucketHours
+	timeBarWidth    = 20
+)
+
+// lateLogHours is how far past midnight after its date a session's entries
+// can be logged and still say when it happened: a session running past
+// midnight is logged just after it, one typed in the next day was not.
+const lateLogHours = 6
+
+// timeHistogram counts training sessions, the entries of one date, by the
+// part of the day they started in, on weekdays and at weekends.
+type timeHistogram struct {
+	Weekday [timeBuckets]int
+	Weekend [timeBuckets]int
+	Unknown int // sessions without a log time to go by
+}
+
+// sessionsByTime sorts the sessions of entries into a timeHistogram by the
+// time of day they started in loc. A session started at the earliest time
+// one of its entries was logged, less the session minutes when measured.
+// Times are compared as instants, so a session running past midnight
+// starts on its own date, and only then turned into loc's wall clock, so a
+// DST change doesn't shift a session into another bucket. Whether it is a
+// weekend session goes by its date. Log times are only believed from the
+// start of the session's date to lateLogHours past its end; sessions with
+// none count as Unknown.
+func sessionsByTime(entries []WorkoutEntry, loc *time.Location) timeHistogram {
+	type session struct {
+		day     time.Time
+		start   time.Time
+		started bool
+	}
+	var order []string
+	sessions := map[string]*session{}
+	for _, entry := range entries {
+		s, ok := sessions[entry.Date]
+		if !ok {
+			day, err := time.ParseInLocation(calio.DateLayout, entry.Date, loc)
+			if err != nil {
+				continue
+			}
+			s = &session{day: day}
+			sessions[entry.Date] = s
+			order = append(order, entry.Date)
+		}
+		start, _, ok := loggedSpan(entry, s.day, loc)
+		if !ok {
+			continue
+		}
+		if !s.started || start.Before(s.start) {
+			s.start, s.started = start, true
+		}
+	}
+
+	var h timeHistogram
+	for _, date := range order {
+		s := sessions[date]
+		if !s.started {
+			h.Unknown++
+			continue
+		}
+		bucket := s.start.In(loc).Hour() / timeBucketHours
+		if weekday := s.day.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			h.Weekend[bucket]++
+		} else {
+			h.Weekday[bucket]++
+		}
+	}
+	return h
+}
+
+// loggedSpan returns when the session of entry started, its log time less
+// the session minutes when measured, and when entry was logged. day is the
+// start of entry's date in loc. ok is false when entry has no log time, or
+// one outside its date and the lateLogHours after it; a session doesn't
+// start before its own date.
+func loggedSpan(entry WorkoutEntry, day time.Time, loc *time.Location) (start, logged time.Time, ok bool) {
+	logged, ok = calio.LoggedTime(entry)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	// Midnight of the next date in loc, which a DST change moves off 24
+	// hours after this one.
+	end := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+	if logged.Before(day) || !logged.Before(end.Add(lateLogHours*time.Hour)) {
+		return time.Time{}, time.Time{}, false
+	}
+	start = logged.Add(-time.Duration(entry.Duration) * time.Minute)
+	return maxTime(start, day), logged, true
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// writeTimeHistogram renders h as two columns of ASCII bars, weekdays and
+// weekends, one row per part of the day, each bar with its count. Bars are
+// scaled to the largest count.
+func writeTimeHistogram(w io.Writer, h timeHistogram, zone string) error {
+	most := 1
+	for bucket := range timeBuckets {
+		most = max(most, h.Weekday[bucket], h.Weekend[bucket])
+	}
+	bar := func(count int) string {
+		n := (count*timeBarWidth + most - 1) / most
+		return fmt.Sprintf("%-*s %3d", timeBarWidth, strings.Repeat("#", n), count)
+	}
+
+	var b strings.Builder
+	fmt.Fprintln(&b, msg("stats.times_header", timeBucketHours, zone))
+	fmt.Fprintf(&b, "%-5s  %-*s   %s\n", "", timeBarWidth+4, msg("stats.times_weekdays"), msg("stats.times_weekends"))
+	for bucket := range timeBuckets {
+		from := bucket * timeBucketHours
+		fmt.Fprintf(&b, "%02d-%02d  %s   %s\n", from, from+timeBucketHours, bar(h.Weekday[bucket]), bar(h.Weekend[bucket]))
+	}
+	if h.Unknown > 0 {
+		fmt.Fprintln(&b, msg("stats.times_unknown", h.Unknown))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// showTimes prints when the sessions in rng were trained, in the timezone
+// of CALI_TZ.
+func showTimes(ctx context.Context, storage Storage, rng dateRange) error {
+	entries, err := storage.Range(ctx, rng.Since, rng.Until)
+	if err != nil {
+		return storageError("reading workout history", err)
+	}
+	now := currentTime()
+	entries = calio.WithoutFuture(entries, now)
+	if len(entries) == 0 {
+		fmt.Println(msg("history.empty"))
+		return errNoResults
+	}
+	loc, zone := now.Location(), now.Location().String()
+	if loc == time.Local {
+		zone = now.Format("MST")
+	}
+	return writeTimeHistogram(os.Stdout, sessionsByTime(entries, loc), zone)
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// session is one entry dated date, logged at logged (RFC 3339) after a
+// session of minutes.
+func session(date, logged string, minutes int) WorkoutEntry {
+	return WorkoutEntry{Date: date, Exercise: "Pushups", Level: "Full", RepsSets: "10x2", LoggedAt: logged, Duration: minutes}
+}
+
+func TestSessionsByTimeAroundMidnight(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   WorkoutEntry
+		bucket  int // -1 for Unknown
+		weekend bool
+	}{
+		{"late evening", session("2026-06-03", "2026-06-03T23:50:00Z", 30), 11, false},
+		{"past midnight", session("2026-06-03", "2026-06-04T00:20:00Z", 40), 11, false},
+		{"logged after its session ended", session("2026-06-03", "2026-06-04T05:59:00Z", 0), 2, false},
+		{"logged the next morning", session("2026-06-03", "2026-06-04T06:00:00Z", 0), -1, false},
+		{"logged before its date", session("2026-06-03", "2026-06-02T23:59:00Z", 0), -1, false},
+		{"started before its date", session("2026-06-03", "2026-06-03T00:10:00Z", 30), 0, false},
+		{"saturday night", session("2026-06-06", "2026-06-07T00:30:00Z", 45), 11, true},
+		{"no log time", session("2026-06-03", "", 30), -1, false},
+	}
+	for _, tt := range tests {
+		h := sessionsByTime([]WorkoutEntry{tt.entry}, time.UTC)
+		var want timeHistogram
+		switch {
+		case tt.bucket < 0:
+			want.Unknown = 1
+		case tt.weekend:
+			want.Weekend[tt.bucket] = 1
+		default:
+			want.Weekday[tt.bucket] = 1
+		}
+		if h != want {
+			t.Errorf("%s: %+v, want %+v", tt.name, h, want)
+		}
+	}
+}
+
+// TestSessionsByTimeAcrossDST checks sessions on the days Berlin's clocks
+// change land in the part of the day its wall clock showed, and that the
+// next midnight is found on the clock, not 24 hours on.
+func TestSessionsByTimeAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		name   string
+		entry  WorkoutEntry
+		bucket int // of a weekend session; -1 for Unknown
+	}{
+		// 01:30 UTC is 03:30 CEST, just after the clocks went forward.
+		{"spring forward", session("2026-03-29", "2026-03-29T01:30:00Z", 0), 1},
+		// 00:30 UTC is 02:30 CEST and 01:30 UTC is 02:30 CET: the hour
+		// that comes twice lands in the same part of the day both times.
+		{"fall back", session("2026-10-25", "2026-10-25T00:30:00Z", 0), 1},
+		{"fall back, again", session("2026-10-25", "2026-10-25T01:30:00Z", 0), 1},
+		// 04:30 UTC on the 26th is 05:30 CET, within lateLogHours of the
+		// 25-hour day's end; 24 hours on from its start it wouldn't be.
+		{"past the long day", session("2026-10-25", "2026-10-26T04:30:00Z", 0), 2},
+		{"after the long day", session("2026-10-25", "2026-10-26T05:00:00Z", 0), -1},
+		// Logged at 03:00 CEST after 90 minutes, so started at 00:30 CET,
+		// though the clock moved two and a half hours in between.
+		{"timed across the change", session("2026-03-29", "2026-03-29T01:00:00Z", 90), 0},
+	}
+	for _, tt := range tests {
+		h := sessionsByTime([]WorkoutEntry{tt.entry}, berlin)
+		var want timeHistogram
+		if tt.bucket < 0 {
+			want.Unknown = 1
+		} else {
+			want.Weekend[tt.bucket] = 1
+		}
+		if h != want {
+			t.Errorf("%s: %+v, want %+v", tt.name, h, want)
+		}
+	}
+}
+
+// TestLogDateFollowsCaliTZ logs an entry under timezones a day apart and
+// checks each is dated by the clock of CALI_TZ, not the machine's.
+func TestLogDateFollowsCaliTZ(t *testing.T) {
+	for _, zone := range []string{"Pacific/Kiritimati", "Etc/GMT+12"} {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			t.Skip(err)
+		}
+		storage := pipedLog(t)
+		t.Setenv("CALI_TZ", zone)
+		before := time.Now().In(loc).Format("2006-01-02")
+		_, stderr, code := runCLI(t, "", "log", "--day", "A", "--exercise", "pushups", "--level", "full", "--reps", "10x2")
+		after := time.Now().In(loc).Format("2006-01-02")
+		if code != 0 {
+			t.Fatalf("%s: exited %d: %s", zone, code, stderr)
+		}
+		entries := logged(t, storage)
+		if len(entries) != 1 || !slices.Contains([]string{before, after}, entries[0].Date) {
+			t.Errorf("%s: saved %+v, want it dated %s", zone, entries, before)
+		}
+	}
+}
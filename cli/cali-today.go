@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// dayProgress compares the exercises logged on one day against the plan for
+// that day's letter.
+type dayProgress struct {
+	Day       string
+	Done      []string
+	Remaining []string
+	OffPlan   []string // logged exercises not planned for Day
+}
+
+// inferDay returns the day letter of the latest entry, or "" when entries is
+// empty.
+func inferDay(entries []WorkoutEntry) string {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if day := strings.ToUpper(strings.TrimSpace(entries[i].Day)); day != "" {
+			return day
+		}
+	}
+	return ""
+}
+
+// nextDay returns the letter following last in the A/B/C rotation, starting
+// over at A for an empty or unknown letter.
+func nextDay(last string) string {
+	days := calio.DayLetters()
+	for i, day := range days {
+		if strings.EqualFold(strings.TrimSpace(last), day) {
+			return days[(i+1)%len(days)]
+		}
+	}
+	return days[0]
+}
+
+func planProgress(entries []WorkoutEntry, plan func(day string) []string) dayProgress {
+	progress := dayProgress{Day: inferDay(entries)}
+
+	logged := map[string]bool{}
+	for _, entry := range entries {
+		logged[calio.Canonical(entry).Exercise] = true
+	}
+
+	planned := map[string]bool{}
+	for _, exercise := range plan(progress.Day) {
+		planned[exercise] = true
+		if logged[exercise] {
+			progress.Done = append(progress.Done, exercise)
+		} else {
+			progress.Remaining = append(progress.Remaining, exercise)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		exercise := calio.Canonical(entry).Exercise
+		if planned[exercise] || seen[exercise] {
+			continue
+		}
+		seen[exercise] = true
+		progress.OffPlan = append(progress.OffPlan, exercise)
+	}
+	return progress
+}
+
+func showToday(ctx context.Context, storage Storage) error {
+	today := currentTime().Format(calio.DateLayout)
+	entries, err := storage.SearchByDate(ctx, today)
+	if err != nil {
+		return storageError("reading today's workouts", err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Print(msg("today.nothing", displayDate(today)))
+		lastDay, lastDate, err := storage.LastTrainingDay(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read previous training day: %v\n", err)
+		}
+		suggested := suggestDay(ctx, storage, lastDay)
+		if lastDay != "" {
+			fmt.Print(msg("today.suggested_after", suggested, lastDay, displayDate(lastDate)))
+		} else {
+			fmt.Print(msg("today.suggested", suggested))
+		}
+		for _, exercise := range exerciseMenu.dayPlan(suggested) {
+			fmt.Printf("  - %s\n", exercise)
+		}
+		return errNoResults
+	}
+
+	strength, _ := splitByCategory(entries)
+	progress := planProgress(strength, exerciseMenu.dayPlan)
+	if progress.Day != "" {
+		fmt.Print(msg("today.header_day", displayDate(today), progress.Day))
+	} else {
+		fmt.Print(msg("today.header", displayDate(today)))
+	}
+	sayln(separatorRule())
+	for _, entry := range entries {
+		fmt.Printf("%s - %s | %s | %s\n", entry.Exercise, entry.Level, workText(entry), entry.Comment)
+	}
+	sayln(separatorRule())
+
+	var parts []string
+	if len(progress.Done) > 0 {
+		parts = append(parts, msg("today.done", strings.Join(progress.Done, " ✓, ")))
+	}
+	if len(progress.Remaining) > 0 {
+		parts = append(parts, msg("today.remaining", strings.Join(progress.Remaining, ", ")))
+	} else if len(progress.Done) > 0 {
+		parts = append(parts, msg("today.all_done"))
+	}
+	if len(progress.OffPlan) > 0 {
+		parts = append(parts, msg("today.also_logged", strings.Join(progress.OffPlan, ", ")))
+	}
+	if len(parts) > 0 {
+		fmt.Println(strings.Join(parts, " · "))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNextDay(t *testing.T) {
+	for last, want := range map[string]string{"A": "B", "b": "C", " C ": "A", "": "A", "Z": "A"} {
+		if got := nextDay(last); got != want {
+			t.Errorf("nextDay(%q) = %q, want %q", last, got, want)
+		}
+	}
+}
+
+func TestPlanProgress(t *testing.T) {
+	plan := func(day string) []string {
+		if day == "A" {
+			return []string{"Pushups", "Squats"}
+		}
+		return nil
+	}
+	entries := []WorkoutEntry{
+		{Day: "A", Exercise: "pushups", Level: "Full"},
+		{Day: "A", Exercise: "Pullups", Level: "Full"},
+		{Day: "A", Exercise: "Pushups", Level: "Full"},
+		{Day: "", Exercise: "Pullups", Level: "Full"},
+	}
+	got := planProgress(entries, plan)
+	if got.Day != "A" || !slices.Equal(got.Done, []string{"Pushups"}) ||
+		!slices.Equal(got.Remaining, []string{"Squats"}) || !slices.Equal(got.OffPlan, []string{"Pullups"}) {
+		t.Errorf("planProgress = %+v", got)
+	}
+
+	if got := planProgress(nil, plan); got.Day != "" || len(got.Done)+len(got.Remaining)+len(got.OffPlan) != 0 {
+		t.Errorf("planProgress of no entries = %+v", got)
+	}
+}
+
+func TestTodayCommand(t *testing.T) {
+	pipedLog(t)
+	stdout, _, code := runCLI(t, "", "today")
+	if code != 0 || !strings.Contains(stdout, "Nothing logged yet today") || !strings.Contains(stdout, "Suggested: Day A") {
+		t.Errorf("cali today on an empty log exited %d:\n%s", code, stdout)
+	}
+
+	if _, stderr, code := runCLI(t, "", "log", "--day", "A", "--exercise", "pushups", "--level", "full", "--reps", "20x2"); code != 0 {
+		t.Fatalf("cali log exited %d: %s", code, stderr)
+	}
+	stdout, stderr, code := runCLI(t, "", "today")
+	if code != 0 {
+		t.Fatalf("cali today exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Day A") || !strings.Contains(stdout, "Done: Pushups ✓") || !strings.Contains(stdout, "Remaining: Squats") {
+		t.Errorf("cali today printed:\n%s", stdout)
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// removeUndoWindow is how long cali -r waits for an answer to its undo
+// offer before the removal stands.
+const removeUndoWindow = 15 * time.Second
+
+// printRemoved shows a removed entry with every field it had, so a wrong
+// choice can be spotted and, if need be, logged again by hand.
+func printRemoved(entry WorkoutEntry) {
+	goal := entry.Goal
+	if goal == "" {
+		goal = "-"
+	}
+	fmt.Print(msg("remove.removed", displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level, loggedWork(entry), goal))
+	if entry.Duration > 0 {
+		fmt.Print(msg("log.duration", entry.Duration))
+	}
+	if entry.Comment != "" {
+		fmt.Print(msg("remove.removed_comment", entry.Comment))
+	}
+}
+
+// offerUndo asks whether to undo the removal and waits up to window for
+// the answer; after fires when the window is over. Only yes undoes: no
+// answer, a closed input or anything else lets the removal stand.
+func offerUndo(reader *bufio.Reader, window time.Duration, after func(time.Duration) <-chan time.Time) bool {
+	prompt(msg("remove.undo_prompt", int(window.Seconds())))
+	answers := make(chan string, 1)
+	go func() {
+		answer, _ := reader.ReadString('\n')
+		answers <- answer
+	}()
+	select {
+	case answer := <-answers:
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		return slices.Contains(strings.Split(msg("answer.yes"), ","), answer)
+	case <-after(window):
+		promptln()
+		promptln(msg("remove.undo_expired"))
+		return false
+	}
+}
+
+// undoRemove puts a removed entry back: where it was when the storage can
+// (see calio.EntryRestorer), appended at the end otherwise.
+func undoRemove(ctx context.Context, storage Storage, entry WorkoutEntry) error {
+	restorer, ok := storage.(calio.EntryRestorer)
+	if !ok {
+		restorer = appendRestorer{storage}
+	}
+	stored, inPlace, err := restorer.Restore(ctx, entry)
+	if errors.Is(err, calio.ErrNoRestorer) {
+		stored, inPlace, err = appendRestorer{storage}.Restore(ctx, entry)
+	}
+	if err != nil {
+		keepUnsaved(err, entry)
+		return storageError("restoring entry", err)
+	}
+	if inPlace {
+		fmt.Println(msg("remove.undone"))
+	} else {
+		fmt.Println(msg("remove.undone_appended"))
+	}
+	if location := entryLocation(storage, stored); location != "" {
+		say(location)
+	}
+	return nil
+}
+
+// appendRestorer restores by appending, for storages that can't insert.
+type appendRestorer struct {
+	Storage
+}
+
+func (a appendRestorer) Restore(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, bool, error) {
+	stored, err := a.Append(ctx, entry)
+	return stored, false, err
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestPrintRemoved(t *testing.T) {
+	entry := WorkoutEntry{Date: "2026-03-04", Day: "A", Exercise: "Squats", Level: "Full", RepsSets: "20x2", Load: "+10kg", Duration: 25, Comment: "knees ok"}
+	got := captureOutput(t, &os.Stdout, func() { printRemoved(entry) })
+	want := msg("remove.removed", displayDate("2026-03-04"), "A", "Squats", "Full", "20x2 +10kg", "-") +
+		msg("log.duration", 25) + msg("remove.removed_comment", "knees ok")
+	if got != want {
+		t.Errorf("printRemoved = %q, want %q", got, want)
+	}
+}
+
+// TestOfferUndo checks only yes undoes, and that the removal stands once
+// the window is over without an answer.
+func TestOfferUndo(t *testing.T) {
+	never := func(time.Duration) <-chan time.Time { return nil }
+	for _, tt := range []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{" YES \n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	} {
+		var got bool
+		captureOutput(t, &os.Stdout, func() {
+			got = offerUndo(bufio.NewReader(strings.NewReader(tt.input)), removeUndoWindow, never)
+		})
+		if got != tt.want {
+			t.Errorf("offerUndo(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	// Nobody answers: the reader blocks until the test ends.
+	silent, w := io.Pipe()
+	t.Cleanup(func() { w.Close() })
+	var windows []time.Duration
+	over := func(d time.Duration) <-chan time.Time {
+		windows = append(windows, d)
+		fired := make(chan time.Time, 1)
+		fired <- time.Time{}
+		return fired
+	}
+	var undone bool
+	out := captureOutput(t, &os.Stdout, func() { undone = offerUndo(bufio.NewReader(silent), 15*time.Second, over) })
+	if undone || len(windows) != 1 || windows[0] != 15*time.Second {
+		t.Errorf("an unanswered offer undid %v after waiting %v", undone, windows)
+	}
+	if !strings.HasPrefix(out, msg("remove.undo_prompt", 15)) || !strings.Contains(out, msg("remove.undo_expired")) {
+		t.Errorf("an unanswered offer printed %q", out)
+	}
+}
+
+// TestUndoRemove checks an entry goes back in place on the local log,
+// appended on a storage that can't restore, and is kept as unsaved when
+// that fails too.
+func TestUndoRemove(t *testing.T) {
+	isolatedHome(t)
+	ctx := context.Background()
+	local := calio.NewFileStorage(t.TempDir())
+	for _, comment := range []string{"first", "second", "third"} {
+		if _, err := local.Append(ctx, WorkoutEntry{Date: "2026-03-04", Day: "A", Exercise: "Squats", Level: "Full", RepsSets: "20x2", Comment: comment}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries := logged(t, local)
+	if err := local.RemoveEntry(ctx, entries[1]); err != nil {
+		t.Fatal(err)
+	}
+	out := captureOutput(t, &os.Stdout, func() {
+		if err := undoRemove(ctx, local, entries[1]); err != nil {
+			t.Error(err)
+		}
+	})
+	var comments []string
+	for _, entry := range logged(t, local) {
+		comments = append(comments, entry.Comment)
+	}
+	if strings.Join(comments, ",") != "first,second,third" || !strings.Contains(out, msg("remove.undone")) {
+		t.Errorf("undo on the local log left %q and printed %q", comments, out)
+	}
+
+	memory := &memoryStorage{}
+	out = captureOutput(t, &os.Stdout, func() {
+		if err := undoRemove(ctx, memory, entries[1]); err != nil {
+			t.Error(err)
+		}
+	})
+	if len(memory.entries) != 1 || !strings.Contains(out, msg("remove.undone_appended")) {
+		t.Errorf("undo without a restorer stored %+v and printed %q", memory.entries, out)
+	}
+
+	err := undoRemove(ctx, &flakyStorage{}, entries[1])
+	if exitCode(err) != exitStorage {
+		t.Errorf("a failed undo = %v", err)
+	}
+	store, err := newUnsavedEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept, err := store.Load(); err != nil || len(kept) != 1 {
+		t.Errorf("a failed undo kept %+v, %v", kept, err)
+	}
+}
+
+// TestRemovePrintsEntry checks cali -r prints what it removed, and doesn't
+// offer an undo when its input isn't a terminal.
+func TestRemovePrintsEntry(t *testing.T) {
+	storage := pipedLog(t)
+	date := currentTime().Format(calio.DateLayout)
+	if _, err := storage.Append(context.Background(), WorkoutEntry{Date: date, Day: "A", Exercise: "Squats", Level: "Full", RepsSets: "20x2", Goal: "30x2", Comment: "gone"}); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code := runCLI(t, "", "-r", "--date", date, "--index", "1", "--yes")
+	want := msg("remove.removed", displayDate(date), "A", "Squats", "Full", "20x2", "30x2") + msg("remove.removed_comment", "gone")
+	if code != 0 || !strings.Contains(stdout, want) || strings.Contains(stdout, "Undo?") {
+		t.Errorf("cali -r --yes exited %d: %s%s", code, stdout, stderr)
+	}
+	if entries := logged(t, storage); len(entries) != 0 {
+		t.Errorf("cali -r left %+v", entries)
+	}
+}
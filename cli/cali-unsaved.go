@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// unsavedEntry is an entry whose save failed, with why and when.
+type unsavedEntry struct {
+	Entry  WorkoutEntry `json:"entry"`
+	Error  string       `json:"error"`
+	Failed time.Time    `json:"failed"`
+}
+
+// unsavedEntries keeps entries the backend refused, so a failed Append at
+// the end of an interactive session doesn't lose what was typed. The file
+// holds them oldest first until they are saved again.
+type unsavedEntries struct {
+	path string
+	now  func() time.Time
+}
+
+func newUnsavedEntries() (*unsavedEntries, error) {
+	paths, err := userPaths()
+	if err != nil {
+		return nil, err
+	}
+	return &unsavedEntries{
+		path: filepath.Join(paths.DataDir(), "unsaved-entry.json"),
+		now:  currentTime,
+	}, nil
+}
+
+// Load returns the kept entries, oldest first; none when there is no file.
+func (u *unsavedEntries) Load() ([]unsavedEntry, error) {
+	data, err := os.ReadFile(u.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var kept []unsavedEntry
+	if err := json.Unmarshal(data, &kept); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", u.path, err)
+	}
+	return kept, nil
+}
+
+// Add keeps entries after any kept before, recording cause.
+func (u *unsavedEntries) Add(cause error, entries ...WorkoutEntry) error {
+	kept, err := u.Load()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		kept = append(kept, unsavedEntry{Entry: entry, Error: cause.Error(), Failed: u.now()})
+	}
+	return u.write(kept)
+}
+
+// Retry appends the kept entries to storage one at a time, oldest first,
+// dropping each from the file once saved, so a failure part way keeps only
+// the ones not yet saved. It returns the entries as saved.
+func (u *unsavedEntries) Retry(ctx context.Context, storage Storage) ([]WorkoutEntry, error) {
+	kept, err := u.Load()
+	if err != nil {
+		return nil, err
+	}
+	var saved []WorkoutEntry
+	for len(kept) > 0 {
+		entry, err := storage.Append(ctx, kept[0].Entry)
+		if err != nil {
+			return saved, err
+		}
+		saved = append(saved, entry)
+		kept = kept[1:]
+		if err := u.write(kept); err != nil {
+			return saved, err
+		}
+	}
+	return saved, nil
+}
+
+// write replaces the file with kept, or removes it once nothing is left.
+func (u *unsavedEntries) write(kept []unsavedEntry) error {
+	if len(kept) == 0 {
+		if err := os.Remove(u.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := makeDirFor(u.path); err != nil {
+		return err
+	}
+	tmp := u.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, u.path)
+}
+
+// keepUnsaved keeps entries that failed to save with cause and says where
+// they went; the caller still reports cause itself.
+func keepUnsaved(cause error, entries ...WorkoutEntry) {
+	store, err := newUnsavedEntries()
+	if err == nil {
+		err = store.Add(cause, entries...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to keep the unsaved entry: %v\n", err)
+		return
+	}
+	fmt.Fprint(os.Stderr, msg("unsaved.kept", store.path))
+}
+
+// unsavedOfferSkips are the commands that don't stop to offer saving kept
+// entries: retrying them is the command itself, and status lines must stay
+// one line.
+var unsavedOfferSkips = []string{"retry-unsaved", "status", "--version", "--check-update"}
+
+// offerUnsaved asks, when stdin is a terminal, whether to save the entries
+// an earlier session couldn't, before the command runs. A failed or
+// declined retry keeps them and lets the command go on.
+func offerUnsaved(ctx context.Context, args []string) {
+	if !isTerminal(os.Stdin) || (len(args) > 0 && slices.Contains(unsavedOfferSkips, args[0])) {
+		return
+	}
+	store, err := newUnsavedEntries()
+	if err != nil {
+		return
+	}
+	kept, err := store.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	if len(kept) == 0 {
+		return
+	}
+
+	prompt(msg("unsaved.offer", len(kept), store.path))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	if answer != "" && !slices.Contains(strings.Split(msg("answer.yes"), ","), answer) {
+		say(msg("unsaved.later", store.path))
+		return
+	}
+	storage, err := newStorage(ctx)
+	if err != nil {
+		fmt.Fprint(os.Stderr, msg("unsaved.failed", err))
+		return
+	}
+	if _, err := retryUnsaved(ctx, store, storage); err != nil {
+		fmt.Fprint(os.Stderr, msg("unsaved.failed", err))
+	}
+}
+
+// retryUnsaved saves the kept entries and lists the ones saved, saying how
+// many are left when saving stops part way.
+func retryUnsaved(ctx context.Context, store *unsavedEntries, storage Storage) ([]WorkoutEntry, error) {
+	saved, err := store.Retry(ctx, storage)
+	if len(saved) > 0 {
+		sayln(msg("log.logged"))
+	}
+	for _, entry := range saved {
+		fmt.Print(msg("log.saved", displayDate(entry.Date), entry.Day, entry.Exercise, entry.Level, entry.RepsSets))
+	}
+	if err != nil {
+		if left, loadErr := store.Load(); loadErr == nil && len(left) > 0 {
+			fmt.Fprint(os.Stderr, msg("unsaved.remaining", len(left), store.path))
+		}
+	}
+	return saved, err
+}
+
+func runRetryUnsaved(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		return usageError("usage: cali retry-unsaved")
+	}
+	store, err := newUnsavedEntries()
+	if err != nil {
+		return storageError("locating the home directory", err)
+	}
+	kept, err := store.Load()
+	if err != nil {
+		return storageError("reading unsaved entries", err)
+	}
+	if len(kept) == 0 {
+		sayln(msg("unsaved.none"))
+		return errNoResults
+	}
+	storage, err := newStorage(ctx)
+	if err != nil {
+		return storageError("configuring storage", err)
+	}
+	if _, err := retryUnsaved(ctx, store, storage); err != nil {
+		return storageError("writing workout", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// failingStorage is the local backend with appends failing while
+// failAppends is set, as a sheet does when the token expired.
+type failingStorage struct {
+	*calio.FileStorage
+}
+
+var failAppends error
+
+func (f failingStorage) Append(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, error) {
+	if failAppends != nil {
+		return WorkoutEntry{}, failAppends
+	}
+	return f.FileStorage.Append(ctx, entry)
+}
+
+func (f failingStorage) AppendBatch(ctx context.Context, entries []WorkoutEntry) ([]WorkoutEntry, error) {
+	if failAppends != nil {
+		return nil, failAppends
+	}
+	return f.FileStorage.AppendBatch(ctx, entries)
+}
+
+var registerFailing sync.Once
+
+// failingLog makes runCLI store through failingStorage, over the local log
+// it returns, with appends failing with err until the test sets
+// failAppends to nil.
+func failingLog(t *testing.T, err error) *calio.FileStorage {
+	registerFailing.Do(func() {
+		calio.RegisterBackend("failing", func(ctx context.Context, cfg calio.BackendConfig) (calio.Storage, error) {
+			return failingStorage{calio.NewFileStorage(cfg.Getenv("CALI_LOG_DIR"))}, nil
+		})
+	})
+	saved := testBackend
+	testBackend, failAppends = "failing", err
+	t.Cleanup(func() { testBackend, failAppends = saved, nil })
+	return pipedLog(t)
+}
+
+// flakyStorage fails every append after the first ok ones.
+type flakyStorage struct {
+	memoryStorage
+	ok int
+}
+
+func (f *flakyStorage) Append(ctx context.Context, entry WorkoutEntry) (WorkoutEntry, error) {
+	if len(f.entries) >= f.ok {
+		return WorkoutEntry{}, errors.New("network is unreachable")
+	}
+	return f.memoryStorage.Append(ctx, entry)
+}
+
+func TestUnsavedEntries(t *testing.T) {
+	now := time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC)
+	store := &unsavedEntries{path: t.TempDir() + "/data/unsaved-entry.json", now: func() time.Time { return now }}
+	if kept, err := store.Load(); err != nil || kept != nil {
+		t.Fatalf("Load without a file = %v, %v", kept, err)
+	}
+
+	first := WorkoutEntry{Date: "2026-10-15", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "12x2"}
+	second := WorkoutEntry{Date: "2026-10-16", Day: "B", Exercise: "Pullups", Level: "Half", RepsSets: "8x2"}
+	third := WorkoutEntry{Date: "2026-10-16", Day: "B", Exercise: "Leg Raises", Level: "Flat Knee", RepsSets: "20x2"}
+	if err := store.Add(errors.New("token expired"), first); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(errors.New("network is unreachable"), second, third); err != nil {
+		t.Fatal(err)
+	}
+	kept, err := store.Load()
+	want := []unsavedEntry{
+		{Entry: first, Error: "token expired", Failed: now},
+		{Entry: second, Error: "network is unreachable", Failed: now},
+		{Entry: third, Error: "network is unreachable", Failed: now},
+	}
+	if err != nil || !slices.Equal(kept, want) {
+		t.Fatalf("Load = %+v, %v; want the entries stacked oldest first", kept, err)
+	}
+	if info, err := os.Stat(store.path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("the file is %v, %v; want it private", info.Mode(), err)
+	}
+
+	// Saving stops at the first failure and keeps only what is left.
+	storage := &flakyStorage{ok: 1}
+	saved, err := store.Retry(context.Background(), storage)
+	if err == nil || len(saved) != 1 || saved[0].Exercise != "Pushups" {
+		t.Fatalf("Retry failing after one = %+v, %v", saved, err)
+	}
+	if kept, _ := store.Load(); !slices.Equal(kept, want[1:]) {
+		t.Errorf("after a partial retry the file keeps %+v", kept)
+	}
+	storage.ok = 10
+	saved, err = store.Retry(context.Background(), storage)
+	if err != nil || len(saved) != 2 || saved[0].Exercise != "Pullups" || saved[1].Exercise != "Leg Raises" {
+		t.Fatalf("Retry = %+v, %v", saved, err)
+	}
+	if _, err := os.Stat(store.path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the file is still there once everything is saved: %v", err)
+	}
+	if got := storage.entries; len(got) != 3 || got[0].Exercise != "Pushups" || got[2].Exercise != "Leg Raises" {
+		t.Errorf("the log holds %+v, want the entries in the order they failed", got)
+	}
+
+	if err := os.WriteFile(store.path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err == nil || !strings.Contains(err.Error(), store.path) {
+		t.Errorf("Load of a broken file = %v", err)
+	}
+}
+
+// TestUnsavedRoundTrip fails the saves of cali q and cali template, checks
+// what they kept and where they said it is, then saves it all with cali
+// retry-unsaved once the storage works again.
+func TestUnsavedRoundTrip(t *testing.T) {
+	storage := failingLog(t, errors.New("oauth2: token expired"))
+	home := t.TempDir()
+	t.Setenv("CALI_TEMPLATE_legs", "Squats, Full, 20x2; Bridges, Short, 25x2")
+
+	_, stderr, code := runCLIIn(t, home, "", "q", "--yes", "pushups full 12x2 felt strong")
+	store, err := newUnsavedEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != exitStorage || !strings.Contains(stderr, "oauth2: token expired") || !strings.Contains(stderr, "the entry is kept in "+store.path) {
+		t.Errorf("cali q with saving failing exited %d: %s", code, stderr)
+	}
+	if _, stderr, code := runCLIIn(t, home, "\n\ny\n", "template", "legs"); code != exitStorage {
+		t.Errorf("cali template with saving failing exited %d: %s", code, stderr)
+	}
+	kept, err := store.Load()
+	if err != nil || len(kept) != 3 || kept[0].Entry.Comment != "felt strong" || kept[2].Entry.Exercise != "Bridges" ||
+		kept[0].Error != "oauth2: token expired" {
+		t.Fatalf("the unsaved entries are %+v, %v", kept, err)
+	}
+	if entries := logged(t, storage); len(entries) != 0 {
+		t.Fatalf("the failed saves logged %+v", entries)
+	}
+
+	// Still failing, the retry keeps them all.
+	if _, _, code := runCLIIn(t, home, "", "retry-unsaved"); code != exitStorage {
+		t.Errorf("cali retry-unsaved with saving failing exited %d", code)
+	}
+	if again, _ := store.Load(); len(again) != 3 {
+		t.Errorf("a failed retry left %d entries, want 3", len(again))
+	}
+
+	failAppends = nil
+	stdout, stderr, code := runCLIIn(t, home, "", "retry-unsaved")
+	if code != 0 || strings.Count(stdout, "Saved") < 1 {
+		t.Errorf("cali retry-unsaved exited %d, printed %q %s", code, stdout, stderr)
+	}
+	entries := logged(t, storage)
+	var exercises []string
+	for _, entry := range entries {
+		exercises = append(exercises, entry.Exercise)
+	}
+	if !slices.Equal(exercises, []string{"Pushups", "Squats", "Bridges"}) || entries[0].Comment != "felt strong" {
+		t.Errorf("cali retry-unsaved logged %q", exercises)
+	}
+	if _, err := os.Stat(store.path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the unsaved entries are still kept after saving: %v", err)
+	}
+	if stdout, _, code := runCLIIn(t, home, "", "retry-unsaved"); code != 0 || !strings.Contains(stdout, "No unsaved entries.") {
+		t.Errorf("cali retry-unsaved with nothing kept exited %d, printed %q", code, stdout)
+	}
+}
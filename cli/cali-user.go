@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// userSelection is whose entries commands read from a log shared by several
+// people (CALI_USER names the local one).
+type userSelection struct {
+	User string // --user; "" means the local user
+	All  bool   // --all-users
+}
+
+// selectedUser is set from the global --user/--all-users flags.
+var selectedUser userSelection
+
+// localUser is who this cali logs as, from CALI_USER; "" when the log isn't
+// shared.
+func localUser() string {
+	return strings.TrimSpace(os.Getenv("CALI_USER"))
+}
+
+// extractUserFlags removes the global --user <name> and --all-users flags
+// from args.
+func extractUserFlags(args []string) (userSelection, []string, error) {
+	var sel userSelection
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--all-users" {
+			sel.All = true
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--user" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return userSelection{}, nil, fmt.Errorf("--user requires a name")
+			}
+			i++
+			value = args[i]
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			return userSelection{}, nil, fmt.Errorf("--user requires a name")
+		}
+		sel.User = value
+	}
+	if sel.All && sel.User != "" {
+		return userSelection{}, nil, fmt.Errorf("--user and --all-users can't be combined")
+	}
+	return sel, rest, nil
+}
+
+// withUser scopes backend to the selected user when the log is shared: new
+// entries are tagged with CALI_USER, and reads show the local user's entries
+// (plus untagged ones, logged before the log was shared) unless --user or
+// --all-users says otherwise.
+func withUser(backend Storage) Storage {
+	me := localUser()
+	if me == "" && selectedUser.User == "" {
+		return backend
+	}
+	show := me
+	if selectedUser.User != "" {
+		show = selectedUser.User
+	}
+	if selectedUser.All {
+		show = ""
+	}
+	if show != "" {
+		detail("Showing entries of user %q\n", show)
+	}
+	return &calio.UserStorage{
+		Storage:      backend,
+		Author:       me,
+		Show:         show,
+		Unattributed: show == me,
+		Now:          currentTime,
+	}
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+func TestExtractUserFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		sel  userSelection
+		rest string
+		ok   bool
+	}{
+		{[]string{"stats"}, userSelection{}, "stats", true},
+		{[]string{"stats", "--user", "sam"}, userSelection{User: "sam"}, "stats", true},
+		{[]string{"--user=sam", "history", "-n", "5"}, userSelection{User: "sam"}, "history -n 5", true},
+		{[]string{"history", "--all-users"}, userSelection{All: true}, "history", true},
+		{[]string{"stats", "--user"}, userSelection{}, "", false},
+		{[]string{"stats", "--user= "}, userSelection{}, "", false},
+		{[]string{"--user", "sam", "--all-users"}, userSelection{}, "", false},
+	}
+	for _, tt := range tests {
+		sel, rest, err := extractUserFlags(tt.args)
+		if (err == nil) != tt.ok || sel != tt.sel || strings.Join(rest, " ") != tt.rest {
+			t.Errorf("extractUserFlags(%q) = %+v, %q, %v", tt.args, sel, rest, err)
+		}
+	}
+}
+
+func TestWithUser(t *testing.T) {
+	saved := selectedUser
+	t.Cleanup(func() { selectedUser = saved })
+	backend := calio.NewFileStorage(t.TempDir())
+
+	tests := []struct {
+		me           string
+		sel          userSelection
+		show         string
+		unattributed bool
+	}{
+		{"ziad", userSelection{}, "ziad", true},
+		{"ziad", userSelection{User: "sam"}, "sam", false},
+		{"ziad", userSelection{All: true}, "", false},
+		{"", userSelection{User: "sam"}, "sam", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("CALI_USER", tt.me)
+		selectedUser = tt.sel
+		user, ok := withUser(backend).(*calio.UserStorage)
+		if !ok || user.Author != tt.me || user.Show != tt.show || user.Unattributed != tt.unattributed {
+			t.Errorf("CALI_USER=%q, %+v: withUser = %+v", tt.me, tt.sel, user)
+		}
+	}
+	t.Setenv("CALI_USER", "")
+	selectedUser = userSelection{}
+	if withUser(backend) != Storage(backend) {
+		t.Error("a log that isn't shared got wrapped")
+	}
+}
+
+// TestSharedLog logs as two users and checks reads and cali compare --user
+// keep them apart.
+func TestSharedLog(t *testing.T) {
+	storage := pipedLog(t)
+	today := currentTime().Format(calio.DateLayout)
+	for _, entry := range []WorkoutEntry{
+		{Date: today, Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "10x2", Goal: "20x2", User: "ziad"},
+		{Date: today, Day: "A", Exercise: "Squats", Level: "Full", RepsSets: "10x2", Goal: "20x2", User: "sam"},
+		{Date: today, Day: "A", Exercise: "Lunges", Level: "Full", RepsSets: "10x2", Goal: "20x2", User: "sam"},
+	} {
+		if _, err := storage.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("CALI_USER", "ziad")
+
+	stdout, _, _ := runCLI(t, "", "-s", today, "--format", "csv")
+	if !strings.Contains(stdout, "Pushups") || strings.Contains(stdout, "Squats") {
+		t.Errorf("ziad's search printed %q, want only Pushups", stdout)
+	}
+	stdout, _, _ = runCLI(t, "", "-s", today, "--format", "csv", "--user", "sam")
+	if strings.Contains(stdout, "Pushups") || !strings.Contains(stdout, "Squats") {
+		t.Errorf("sam's search printed %q, want only sam's entries", stdout)
+	}
+	stdout, _, _ = runCLI(t, "", "-s", today, "--format", "csv", "--all-users")
+	if strings.Count(stdout, today) != 3 {
+		t.Errorf("--all-users search printed %q, want all 3 entries", stdout)
+	}
+
+	stdout, stderr, code := runCLI(t, "", "compare", "--user", "sam")
+	if code != 0 || !strings.Contains(stdout, "sam") || !strings.Contains(stdout, "↓ 1") {
+		t.Errorf("cali compare --user sam exited %d, printed %q %s", code, stdout, stderr)
+	}
+	if _, _, code := runCLI(t, "", "compare", "--user", "sam", "--against", againstLastYear); code != exitUsage {
+		t.Errorf("cali compare --user --against exited %d, want %d", code, exitUsage)
+	}
+}
@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Build metadata, set with -ldflags "-X <module>/cli.version=..." (see the
+// Makefile).
+// Plain `go build` falls back to the VCS info Go embeds.
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+const (
+	releasesAPI     = "https://api.github.com/repos/ziad73/cali-logger/releases/latest"
+	updateCheckWait = 5 * time.Second
+)
+
+// writerName identifies this build in the schema marker of logged rows.
+func writerName() string {
+	ver, _, _ := buildInfo()
+	return "cali/" + ver
+}
+
+// buildInfo returns the version, commit and build date, filling blanks from
+// what Go embeds: the module version for `go install ...@vX.Y.Z` and the VCS
+// stamp for a plain `go build` in a checkout.
+func buildInfo() (string, string, string) {
+	ver, rev, date := version, commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if ver == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			ver = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if rev == "" {
+					rev = setting.Value
+				}
+			case "vcs.time":
+				if date == "" {
+					date = setting.Value
+				}
+			}
+		}
+	}
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	if rev == "" {
+		rev = "unknown"
+	}
+	if date == "" {
+		date = "unknown"
+	}
+	return ver, rev, date
+}
+
+func printVersion() {
+	ver, rev, date := buildInfo()
+	fmt.Print(msg("version.line", ver, rev, date))
+}
+
+// release is the part of the GitHub releases API response cali reads.
+type release struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+func parseRelease(r io.Reader) (release, error) {
+	var rel release
+	if err := json.NewDecoder(r).Decode(&rel); err != nil {
+		return release{}, fmt.Errorf("decoding release: %w", err)
+	}
+	if rel.TagName == "" {
+		return release{}, fmt.Errorf("release has no tag")
+	}
+	return rel, nil
+}
+
+func fetchLatestRelease() (release, error) {
+	client := &http.Client{Timeout: updateCheckWait}
+	req, err := http.NewRequest(http.MethodGet, releasesAPI, nil)
+	if err != nil {
+		return release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return release{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return release{}, fmt.Errorf("releases API returned %s", resp.Status)
+	}
+	return parseRelease(resp.Body)
+}
+
+// checkUpdate compares this build with the latest GitHub release. It only
+// runs when asked for and never fails the command: network or API problems
+// are reported as a warning.
+func checkUpdate() {
+	rel, err := fetchLatestRelease()
+	if err != nil {
+		fmt.Fprint(os.Stderr, msg("version.check_failed", err))
+		return
+	}
+
+	current, _, _ := buildInfo()
+	order, ok := compareVersions(current, rel.TagName)
+	switch {
+	case !ok:
+		fmt.Print(msg("version.latest", rel.TagName, current, rel.HTMLURL))
+	case order < 0:
+		fmt.Print(msg("version.available", current, rel.TagName, rel.HTMLURL))
+	default:
+		fmt.Print(msg("version.up_to_date", current))
+	}
+}
+
+// semver is a parsed MAJOR.MINOR.PATCH[-PRERELEASE] version; build metadata
+// after "+" is ignored.
+type semver struct {
+	Major, Minor, Patch int
+	Pre                 string
+}
+
+func parseSemver(value string) (semver, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "v")
+	value, _, _ = strings.Cut(value, "+")
+	core, pre, _ := strings.Cut(value, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		nums[i] = n
+	}
+	return semver{Major: nums[0], Minor: nums[1], Patch: nums[2], Pre: pre}, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, equal to or newer
+// than b. The bool is false when either isn't a semantic version (e.g. "dev").
+func compareVersions(a, b string) (int, bool) {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	for _, pair := range [][2]int{{va.Major, vb.Major}, {va.Minor, vb.Minor}, {va.Patch, vb.Patch}} {
+		if pair[0] != pair[1] {
+			return cmp.Compare(pair[0], pair[1]), true
+		}
+	}
+	return comparePrerelease(va.Pre, vb.Pre), true
+}
+
+// comparePrerelease orders pre-release tags per semver: a release sorts after
+// its pre-releases, numeric identifiers compare numerically and below
+// alphanumeric ones, and a shorter list of equal identifiers sorts first.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	idsA, idsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(idsA) && i < len(idsB); i++ {
+		if idsA[i] == idsB[i] {
+			continue
+		}
+		numA, errA := strconv.Atoi(idsA[i])
+		numB, errB := strconv.Atoi(idsB[i])
+		switch {
+		case errA == nil && errB == nil:
+			return cmp.Compare(numA, numB)
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		}
+		return strings.Compare(idsA[i], idsB[i])
+	}
+	return cmp.Compare(len(idsA), len(idsB))
+}
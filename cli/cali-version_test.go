@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		order int
+		ok    bool
+	}{
+		{"v1.2.3", "1.2.3", 0, true},
+		{"v1.2.3", "v1.10.0", -1, true},
+		{"v2.0.0", "v1.99.99", 1, true},
+		{"v1.0.0-rc.1", "v1.0.0", -1, true},
+		{"v1.0.0-rc.2", "v1.0.0-rc.10", -1, true}, // numeric identifiers compare as numbers
+		{"v1.0.0-1", "v1.0.0-alpha", -1, true},    // and below alphanumeric ones
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1, true},
+		{"v1.0.0+build.5", "v1.0.0", 0, true},
+		{"dev", "v1.0.0", 0, false},
+		{"v1.0", "v1.0.0", 0, false},
+		{"v1.-1.0", "v1.0.0", 0, false},
+	}
+	for _, tt := range tests {
+		order, ok := compareVersions(tt.a, tt.b)
+		if order != tt.order || ok != tt.ok {
+			t.Errorf("compareVersions(%q, %q) = %d, %v, want %d, %v", tt.a, tt.b, order, ok, tt.order, tt.ok)
+		}
+	}
+}
+
+func TestParseRelease(t *testing.T) {
+	rel, err := parseRelease(strings.NewReader(`{"tag_name": "v1.4.0", "html_url": "https://github.com/ziad73/cali-logger/releases/tag/v1.4.0", "draft": false}`))
+	if err != nil || rel.TagName != "v1.4.0" || !strings.HasSuffix(rel.HTMLURL, "/v1.4.0") {
+		t.Errorf("parseRelease = %+v, %v", rel, err)
+	}
+	for _, body := range []string{`{"html_url": "x"}`, `not json`} {
+		if _, err := parseRelease(strings.NewReader(body)); err == nil {
+			t.Errorf("parseRelease(%q) took it for a release", body)
+		}
+	}
+}
+
+// TestBuildInfo checks the values set with -ldflags win over what Go
+// embeds, and the commit is shortened.
+func TestBuildInfo(t *testing.T) {
+	savedVersion, savedCommit, savedDate := version, commit, buildDate
+	t.Cleanup(func() { version, commit, buildDate = savedVersion, savedCommit, savedDate })
+	version, commit, buildDate = "v1.4.0", "0123456789abcdef0123", "2026-10-01T12:00:00Z"
+
+	if ver, rev, date := buildInfo(); ver != "v1.4.0" || rev != "0123456789ab" || date != "2026-10-01T12:00:00Z" {
+		t.Errorf("buildInfo = %q, %q, %q", ver, rev, date)
+	}
+	if got := writerName(); got != "cali/v1.4.0" {
+		t.Errorf("writerName = %q", got)
+	}
+	stdout, _, code := runCLI(t, "", "--version")
+	if code != 0 || !strings.Contains(stdout, "v1.4.0") || !strings.Contains(stdout, "0123456789ab") {
+		t.Errorf("cali --version exited %d, printed %q", code, stdout)
+	}
+}
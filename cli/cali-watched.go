@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// watchedStore remembers when a tutorial video was last opened.
+type watchedStore interface {
+	Watched(exercise, level string) (time.Time, bool)
+	MarkWatched(exercise, level string, at time.Time) error
+}
+
+// fileWatchedStore keeps watched state as JSON: Exercise -> Level -> time.
+// A missing or corrupted file is treated as empty.
+type fileWatchedStore struct {
+	path    string
+	watched map[string]map[string]time.Time
+}
+
+func newWatchedStore() (watchedStore, error) {
+	paths, err := userPaths()
+	if err != nil {
+		return nil, err
+	}
+	store := &fileWatchedStore{
+		path:    filepath.Join(paths.StateDir(), "tutorials-watched.json"),
+		watched: map[string]map[string]time.Time{},
+	}
+
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.watched); err != nil || store.watched == nil {
+		store.watched = map[string]map[string]time.Time{}
+	}
+	return store, nil
+}
+
+func (s *fileWatchedStore) Watched(exercise, level string) (time.Time, bool) {
+	at, ok := s.watched[exercise][level]
+	return at, ok
+}
+
+func (s *fileWatchedStore) MarkWatched(exercise, level string, at time.Time) error {
+	if s.watched[exercise] == nil {
+		s.watched[exercise] = map[string]time.Time{}
+	}
+	s.watched[exercise][level] = at
+
+	data, err := json.MarshalIndent(s.watched, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := makeDirFor(s.path); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// recordWatched marks a tutorial as watched, warning instead of failing
+// since the state is only a convenience.
+func recordWatched(exercise, level string) {
+	store, err := newWatchedStore()
+	if err == nil {
+		err = store.MarkWatched(exercise, level, currentTime())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record watched tutorial: %v\n", err)
+	}
+}
+
+// newTutorialsFlagSet declares the --unwatched flag of cali tutorials into
+// unwatched.
+func newTutorialsFlagSet(unwatched *bool) *flag.FlagSet {
+	fs := newFlagSet("tutorials")
+	fs.BoolVar(unwatched, "unwatched", false, "only list tutorials not opened yet")
+	return fs
+}
+
+func listTutorials(args []string) error {
+	var unwatched bool
+	fs := newTutorialsFlagSet(&unwatched)
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+
+	selected := calio.Exercises()
+	if fs.NArg() > 0 {
+		exercise, ok := normalizeExercise(strings.Join(fs.Args(), " "))
+		if !ok {
+			return usageError("%s", msg("error.unknown_exercise", strings.Join(fs.Args(), " ")))
+		}
+		selected = []string{exercise}
+	}
+
+	store, err := newWatchedStore()
+	if err != nil {
+		return err
+	}
+
+	links := newLinker(os.Stdout)
+	printed := 0
+	for _, exercise := range selected {
+		var lines []string
+		for _, level := range calio.Levels(exercise) {
+			link := resolveTutorial(exercise, level)
+			if link == "" {
+				continue
+			}
+			mark := " "
+			watched := ""
+			if at, ok := store.Watched(exercise, level); ok {
+				if unwatched {
+					continue
+				}
+				mark = "✓"
+				watched = msg("log.watched_on", at.Format(displayDateLayout))
+			}
+			lines = append(lines, fmt.Sprintf("  %s %s %s%s", mark, padCell(links.link(level, link), 20), link, watched))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", exercise)
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		printed += len(lines)
+	}
+
+	if printed == 0 {
+		if unwatched {
+			fmt.Println(msg("tutorials.all_watched"))
+		} else {
+			fmt.Println(msg("tutorials.none"))
+		}
+	}
+	return nil
+}
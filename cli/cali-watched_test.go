@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchedStore(t *testing.T) {
+	isolatedHome(t)
+	store, err := newWatchedStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 10, 17, 18, 30, 0, 0, time.UTC)
+	if err := store.MarkWatched("Pushups", "Wall", at); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new store reads what the first one wrote.
+	if store, err = newWatchedStore(); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := store.Watched("Pushups", "Wall"); !ok || !got.Equal(at) {
+		t.Errorf("Watched(Pushups, Wall) = %v, %v, want %v", got, ok, at)
+	}
+	if _, ok := store.Watched("Pushups", "Incline"); ok {
+		t.Error("Incline counts as watched")
+	}
+
+	// A corrupted file reads as empty.
+	if err := os.WriteFile(store.(*fileWatchedStore).path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if store, err = newWatchedStore(); err != nil {
+		t.Fatalf("newWatchedStore with a corrupted file: %v", err)
+	}
+	if _, ok := store.Watched("Pushups", "Wall"); ok {
+		t.Error("a corrupted file still counts Wall as watched")
+	}
+}
+
+// TestTutorialsUnwatched opens one tutorial and checks cali tutorials marks
+// it, and --unwatched leaves it out.
+func TestTutorialsUnwatched(t *testing.T) {
+	home := t.TempDir()
+	fakeBrowser(t)
+	if _, stderr, code := runCLIIn(t, home, "", "--tutorial", "Pushups", "Wall"); code != 0 {
+		t.Fatalf("cali --tutorial exited %d: %s", code, stderr)
+	}
+
+	stdout, stderr, code := runCLIIn(t, home, "", "tutorials", "Pushups")
+	if code != 0 {
+		t.Fatalf("cali tutorials exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "✓ Wall") || strings.Contains(stdout, "✓ Incline") {
+		t.Errorf("cali tutorials marks the wrong levels:\n%s", stdout)
+	}
+
+	stdout, stderr, code = runCLIIn(t, home, "", "tutorials", "--unwatched", "Pushups")
+	if code != 0 {
+		t.Fatalf("cali tutorials --unwatched exited %d: %s", code, stderr)
+	}
+	if strings.Contains(stdout, "Wall") || !strings.Contains(stdout, "Incline") {
+		t.Errorf("cali tutorials --unwatched lists the wrong levels:\n%s", stdout)
+	}
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// Terminal widths cali lays out its output for.
+const (
+	defaultTerminalWidth = 80 // when the width can't be detected, e.g. piped output
+	minTerminalWidth     = 20 // narrower --width values are raised to this
+)
+
+// selectedWidth is the width set with the global --width flag; 0 detects
+// it.
+var selectedWidth int
+
+// extractWidthFlag removes the global --width <columns> flag from args.
+func extractWidthFlag(args []string) (int, []string, error) {
+	var width int
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != "--width" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return 0, nil, fmt.Errorf("--width requires a number of columns")
+			}
+			i++
+			value = args[i]
+		}
+		columns, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || columns < 1 {
+			return 0, nil, fmt.Errorf("invalid --width %q (use a number of columns, e.g. 60)", value)
+		}
+		width = max(columns, minTerminalWidth)
+	}
+	return width, rest, nil
+}
+
+// terminalWidth returns the width output is laid out for: --width, else
+// the width of the terminal stdout writes to, else $COLUMNS, which shells
+// set for interactive terminals, else defaultTerminalWidth.
+func terminalWidth() int {
+	if selectedWidth > 0 {
+		return selectedWidth
+	}
+	if columns, ok := stdoutColumns(); ok {
+		return max(columns, minTerminalWidth)
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return max(columns, minTerminalWidth)
+	}
+	return defaultTerminalWidth
+}
+
+// separatorRule is a line of '-' across the terminal.
+func separatorRule() string {
+	return strings.Repeat("-", terminalWidth())
+}
+
+// listLayout is how a list of entries fits the terminal, from the widest to
+// the narrowest.
+type listLayout int
+
+const (
+	layoutFull        listLayout = iota // every column on one line
+	layoutWithoutGoal                   // the goal dropped
+	layoutBare                          // the goal and the comment dropped
+	layoutStacked                       // two lines per entry
+)
+
+// Thresholds of planList.
+const (
+	stackedBelow    = 50 // narrower terminals always get layoutStacked
+	minCommentWidth = 12 // a comment column needs this much room to be kept
+	stackedIndent   = "    "
+)
+
+// listColumns are the columns of one listed entry as shown.
+type listColumns struct {
+	Lead    string // date and day, or number and day: "2026-10-17 | Day A"
+	What    string // "Pushups - Full"
+	Work    string // "20x2 +10kg"
+	Goal    string // "20x2"; empty for intervals, which show no goal
+	Comment string
+}
+
+// entryColumns returns the columns of entry listed under lead. The comment
+// is flattened to one line unless whole is set.
+func entryColumns(lead string, entry WorkoutEntry, whole bool) listColumns {
+	columns := listColumns{
+		Lead:    lead,
+		What:    entry.Exercise + " - " + entry.Level,
+		Work:    loggedWork(entry),
+		Goal:    entry.Goal,
+		Comment: entry.Comment,
+	}
+	if isInterval(entry) {
+		columns.Work, columns.Goal = workText(entry), ""
+	}
+	if !whole {
+		columns.Comment = oneLineComment(columns.Comment, 0)
+	}
+	return columns
+}
+
+// restColumns lists a rest day: its date and reason.
+func restColumns(day calio.RestDay) listColumns {
+	return listColumns{Lead: msg("list.rest_lead", displayDate(day.Date)), What: day.Reason}
+}
+
+// line renders the one-line layouts with comment in place of Comment. Rows
+// without work, such as rest days, are the lead and what alone.
+func (c listColumns) line(layout listLayout, comment string) string {
+	if c.Work == "" {
+		return strings.TrimSuffix(c.Lead+" | "+c.What, " | ")
+	}
+	work := c.Work
+	if layout == layoutFull && c.Goal != "" {
+		work += " → " + c.Goal
+	}
+	line := c.Lead + " | " + c.What + " | " + work
+	if layout == layoutBare {
+		return line
+	}
+	return line + " | " + comment
+}
+
+// fits reports whether the row fits width in a one-line layout, leaving
+// room for at least minCommentWidth characters of its comment when the
+// layout shows it, or for all of it when whole is set.
+func (c listColumns) fits(layout listLayout, width int, whole bool) bool {
+	need := cellWidth(c.line(layout, ""))
+	if layout != layoutBare && c.Work != "" {
+		if whole {
+			need += cellWidth(c.Comment)
+		} else {
+			need += min(minCommentWidth, cellWidth(c.Comment))
+		}
+	}
+	return need <= width
+}
+
+// render returns the lines of the row in layout. Unless wholeComments is
+// set, comments are cut to the room left on their line; whole ones that
+// don't fit beside the work in layoutStacked are wrapped below it. An
+// empty comment leaves no trailing separator.
+func (c listColumns) render(layout listLayout, width int, wholeComments bool) []string {
+	cut := func(used int) string {
+		if wholeComments {
+			return c.Comment
+		}
+		return truncateText(c.Comment, max(1, width-used))
+	}
+	if layout != layoutStacked || c.Work == "" {
+		line := c.line(layout, cut(cellWidth(c.line(layout, ""))))
+		if c.Comment == "" {
+			line = strings.TrimSuffix(line, " | ")
+		}
+		return []string{line}
+	}
+	// The exercise goes under the date when they don't fit side by side.
+	lines := []string{c.Lead + " | " + c.What}
+	if !fitsOn(lines[0], width) {
+		lines = []string{c.Lead, stackedIndent + c.What}
+	}
+	second := stackedIndent + c.Work
+	if c.Comment == "" {
+		return append(lines, second)
+	}
+	if !wholeComments || fitsOn(second+" | "+c.Comment, width) {
+		second += " | "
+		return append(lines, second+cut(cellWidth(second)))
+	}
+	// A whole comment too long for the work's line goes under it, wrapped.
+	lines = append(lines, second)
+	for _, paragraph := range strings.Split(c.Comment, "\n") {
+		for _, line := range wrapText(paragraph, width-len(stackedIndent)) {
+			lines = append(lines, stackedIndent+line)
+		}
+	}
+	return lines
+}
+
+// fitsOn reports whether line is a single line at most width wide.
+func fitsOn(line string, width int) bool {
+	return !strings.Contains(line, "\n") && cellWidth(line) <= width
+}
+
+// listPlan is how a list of entries is printed at a given width.
+type listPlan struct {
+	Layout listLayout
+	Rule   string   // the separator above and below the entries
+	Lines  []string // the entries, without line breaks
+}
+
+// planList lays rows out for a terminal width columns wide: on one line
+// each with every column when they all fit, then without the goal, then
+// without the comment as well, and below stackedBelow columns, or when
+// even that doesn't fit, over two lines each. Whole comments are never
+// dropped: they go on the second line when they don't fit on the first.
+// The result depends only on the arguments.
+func planList(width int, rows []listColumns, wholeComments bool) listPlan {
+	plan := listPlan{Layout: layoutStacked, Rule: strings.Repeat("-", width)}
+	layouts := []listLayout{layoutFull, layoutWithoutGoal, layoutBare}
+	if wholeComments {
+		layouts = layouts[:2]
+	}
+	if width >= stackedBelow {
+		for _, layout := range layouts {
+			fits := true
+			for _, row := range rows {
+				fits = fits && row.fits(layout, width, wholeComments)
+			}
+			if fits {
+				plan.Layout = layout
+				break
+			}
+		}
+	}
+	for _, row := range rows {
+		plan.Lines = append(plan.Lines, row.render(plan.Layout, width, wholeComments)...)
+	}
+	return plan
+}
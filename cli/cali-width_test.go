@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// widthRows are the rows the list layouts are golden-tested with: a short
+// entry, a loaded one with a long comment, an interval, which shows no
+// goal, and a rest day.
+func widthRows() []listColumns {
+	entries := []WorkoutEntry{
+		{Date: "2026-03-02", Day: "A", Exercise: "Pushups", Level: "Full", RepsSets: "20x2", Goal: "20x2", Comment: "easy"},
+		{Date: "2026-03-03", Day: "B", Exercise: "Handstand Push-ups", Level: "Half", RepsSets: "8x2", Goal: "15x2", Load: "+10kg", Comment: "shoulders tired after the long ride home, stopped early"},
+		{Date: "2026-03-04", Day: "C", Exercise: "Squats", Level: "Full", RepsSets: "emom10min@12", Goal: "50x2", Type: calio.TypeInterval},
+	}
+	var rows []listColumns
+	for _, entry := range entries {
+		rows = append(rows, entryColumns(msg("list.lead", displayDate(entry.Date), entry.Day), entry, false))
+	}
+	return append(rows, restColumns(calio.RestDay{Date: "2026-03-05", Reason: "travel"}))
+}
+
+// planText renders a plan as printed, after the name of its layout.
+func planText(plan listPlan) string {
+	names := map[listLayout]string{layoutFull: "full", layoutWithoutGoal: "without goal", layoutBare: "bare", layoutStacked: "stacked"}
+	return fmt.Sprintf("layout: %s\n%s\n%s\n%s\n", names[plan.Layout], plan.Rule, strings.Join(plan.Lines, "\n"), plan.Rule)
+}
+
+func TestPlanListGolden(t *testing.T) {
+	for _, width := range []int{40, 60, 80, 120} {
+		plan := planList(width, widthRows(), false)
+		checkGolden(t, fmt.Sprintf("list.%d.txt", width), planText(plan))
+		for _, line := range append(plan.Lines, plan.Rule) {
+			if cellWidth(line) > width {
+				t.Errorf("width %d: %q is %d wide", width, line, cellWidth(line))
+			}
+		}
+	}
+}
+
+func TestPlanList(t *testing.T) {
+	short := listColumns{Lead: "2026-03-04 | Day A", What: "Pushups - Full", Work: "20x2", Goal: "20x2", Comment: "a comment of thirty characters"}
+	tests := []struct {
+		width int
+		whole bool
+		want  listLayout
+	}{
+		{120, false, layoutFull},
+		{64, false, layoutFull},
+		{63, false, layoutWithoutGoal},
+		{57, false, layoutWithoutGoal},
+		{56, false, layoutBare},
+		{50, false, layoutBare},
+		{49, false, layoutStacked},
+		// Whole comments need the room for all of them, and are never
+		// dropped.
+		{82, true, layoutFull},
+		{81, true, layoutWithoutGoal},
+		{75, true, layoutWithoutGoal},
+		{74, true, layoutStacked},
+		{30, true, layoutStacked},
+	}
+	for _, tt := range tests {
+		plan := planList(tt.width, []listColumns{short}, tt.whole)
+		if plan.Layout != tt.want {
+			t.Errorf("width %d, whole %v: layout %d, want %d (%q)", tt.width, tt.whole, plan.Layout, tt.want, plan.Lines)
+		}
+		if len(plan.Rule) != tt.width {
+			t.Errorf("width %d: the rule is %d wide", tt.width, len(plan.Rule))
+		}
+		if tt.whole && !strings.Contains(strings.Join(strings.Fields(strings.Join(plan.Lines, " ")), " "), short.Comment) {
+			t.Errorf("width %d: the whole comment is gone: %q", tt.width, plan.Lines)
+		}
+	}
+	if plan := planList(80, nil, false); plan.Layout != layoutFull || len(plan.Lines) != 0 {
+		t.Errorf("no rows: %+v", plan)
+	}
+}
+
+func TestRecordLines(t *testing.T) {
+	records := []WorkoutEntry{
+		{Date: "2026-03-04", Exercise: "Pushups", Level: "Full", RepsSets: "20x2"},
+		{Date: "2026-03-03", Exercise: "Squats", Level: "Assisted One-Leg", RepsSets: "10x2", Load: "+10kg"},
+	}
+	wide := recordLines(records, 80)
+	if len(wide) != 2 || !strings.HasPrefix(wide[1], "  Squats               Assisted One-Leg   10x2 +10kg (") {
+		t.Errorf("at 80 columns: %q", wide)
+	}
+	narrow := recordLines(records, 60)
+	if len(narrow) != 4 || narrow[2] != "  Squats - Assisted One-Leg" || !strings.HasPrefix(narrow[3], stackedIndent+"10x2 +10kg (") {
+		t.Errorf("at 60 columns: %q", narrow)
+	}
+	for _, line := range narrow {
+		if cellWidth(line) > 60 {
+			t.Errorf("%q is over 60 wide", line)
+		}
+	}
+}
+
+func TestExtractWidthFlag(t *testing.T) {
+	tests := []struct {
+		args  []string
+		width int
+		rest  []string
+		ok    bool
+	}{
+		{[]string{"history"}, 0, []string{"history"}, true},
+		{[]string{"--width", "60", "history"}, 60, []string{"history"}, true},
+		{[]string{"history", "--width=120"}, 120, []string{"history"}, true},
+		{[]string{"--width", "5", "-p"}, minTerminalWidth, []string{"-p"}, true},
+		{[]string{"--width"}, 0, nil, false},
+		{[]string{"--width", "wide"}, 0, nil, false},
+		{[]string{"--width=0"}, 0, nil, false},
+	}
+	for _, tt := range tests {
+		width, rest, err := extractWidthFlag(tt.args)
+		if width != tt.width || strings.Join(rest, " ") != strings.Join(tt.rest, " ") || (err == nil) != tt.ok {
+			t.Errorf("extractWidthFlag(%q) = %d, %q, %v", tt.args, width, rest, err)
+		}
+	}
+}
+
+func TestTerminalWidth(t *testing.T) {
+	saved := selectedWidth
+	t.Cleanup(func() { selectedWidth = saved })
+	selectedWidth = 72
+	t.Setenv("COLUMNS", "100")
+	if got := terminalWidth(); got != 72 {
+		t.Errorf("with --width 72, terminalWidth() = %d", got)
+	}
+	selectedWidth = 0
+	if _, ok := stdoutColumns(); ok {
+		t.Skip("stdout is a terminal")
+	}
+	for columns, want := range map[string]int{"100": 100, "10": minTerminalWidth, "": defaultTerminalWidth, "wide": defaultTerminalWidth, "-5": defaultTerminalWidth} {
+		t.Setenv("COLUMNS", columns)
+		if got := terminalWidth(); got != want {
+			t.Errorf("COLUMNS=%q: terminalWidth() = %d, want %d", columns, got, want)
+		}
+	}
+}
+
+// TestNarrowCommands runs the listing commands at 60 columns and checks
+// nothing is wider, whole comments included.
+func TestNarrowCommands(t *testing.T) {
+	home := t.TempDir()
+	today := currentTime().Format(calio.DateLayout)
+	for _, args := range [][]string{
+		{"log", "--day", "A", "--exercise", "Squats", "--level", "Assisted One-Leg", "--reps", "10x2", "--load", "10"},
+		{"log", "--day", "A", "--exercise", "Pushups", "--level", "Full", "--reps", "12x2"},
+	} {
+		args = append(args, "--comment", "-")
+		if _, stderr, code := runCLIIn(t, home, "knees fine, the new shoes grip the floor much better than before\n", args...); code != 0 {
+			t.Fatalf("cali %s exited %d: %s", strings.Join(args, " "), code, stderr)
+		}
+	}
+	for _, args := range [][]string{
+		{"history"}, {"history", "--full"}, {"-s", today}, {"--stats"}, {"levels", "--matrix"},
+	} {
+		stdout, stderr, code := runCLIIn(t, home, "", append([]string{"--width", "60"}, args...)...)
+		if code != 0 {
+			t.Errorf("cali %s exited %d: %s", strings.Join(args, " "), code, stderr)
+		}
+		if !strings.Contains(stdout, strings.Repeat("-", 60)) && args[0] != "levels" {
+			t.Errorf("cali %s has no rule 60 wide:\n%s", strings.Join(args, " "), stdout)
+		}
+		for _, line := range strings.Split(stdout, "\n") {
+			if cellWidth(line) > 60 {
+				t.Errorf("cali %s: %q is %d wide", strings.Join(args, " "), line, cellWidth(line))
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// stdoutColumns returns the width of the terminal stdout writes to; ok is
+// false when stdout isn't a terminal.
+func stdoutColumns() (columns int, ok bool) {
+	size, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || size.Col == 0 {
+		return 0, false
+	}
+	return int(size.Col), true
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package cli
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// stdoutColumns returns the width of the console window stdout writes to;
+// ok is false when stdout isn't a console.
+func stdoutColumns() (columns int, ok bool) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(os.Stdout.Fd()), &info); err != nil {
+		return 0, false
+	}
+	columns = int(info.Window.Right-info.Window.Left) + 1
+	return columns, columns > 0
+}
@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ziad73/cali-logger/calio"
+)
+
+// setupEnvVars are the storage settings; any of them in the environment means
+// cali has been set up.
+var setupEnvVars = []string{
+	"CALI_STORAGE", "CALI_LOG_DIR", "CALI_SHEET_ID",
+	"CALI_GOOGLE_CREDENTIALS_JSON", "GOOGLE_APPLICATION_CREDENTIALS",
+}
+
+// needsSetup reports whether nothing is configured yet: no storage setting in
+// the environment, no config file, no spreadsheet in the keyring and no local
+// log in logDir. Only then does a bare cali start the setup wizard.
+func needsSetup(getenv func(string) string, store secretStore, configFile, logDir string) bool {
+	for _, name := range setupEnvVars {
+		if strings.TrimSpace(getenv(name)) != "" {
+			return false
+		}
+	}
+	if _, err := os.Stat(configFile); !errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	if loadSheetsConfig(getenv, store).SpreadsheetID.Value != "" {
+		return false
+	}
+	entries, err := os.ReadDir(logDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return true
+	}
+	return err == nil && len(entries) == 0
+}
+
+// wizard asks its questions on reader; setenv applies each answer to the
+// running process, so the checks and the first entry use it.
+type wizard struct {
+	reader *bufio.Reader
+	setenv func(key, value string) error
+}
+
+// ask prompts for one line. At the end of input it returns errCancelled
+// rather than an empty answer, so a question asked again can't loop.
+func (w wizard) ask(label string) (string, error) {
+	prompt(label)
+	input, err := w.reader.ReadString('\n')
+	if err == io.EOF && input == "" {
+		promptln()
+		return "", errCancelled
+	}
+	return strings.TrimSpace(input), nil
+}
+
+// confirm asks a yes/no question where an empty answer is yes.
+func (w wizard) confirm(label string) (bool, error) {
+	answer, err := w.ask(label)
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "" || slices.Contains(strings.Split(msg("answer.yes"), ","), answer), nil
+}
+
+// runSetupWizard walks a first-time user through choosing and checking a
+// backend, writes the answers to configFile and reports whether to log the
+// first workout right away.
+func runSetupWizard(ctx context.Context, reader *bufio.Reader, configFile string) (logNow bool, err error) {
+	w := wizard{reader: reader, setenv: os.Setenv}
+	promptln(msg("wizard.welcome"))
+
+	var settings [][2]string
+	for settings == nil {
+		choice, err := w.ask(msg("wizard.backend_prompt"))
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(choice) {
+		case "1", "sheets":
+			settings, err = w.setupSheets(ctx)
+		case "", "2", "local":
+			settings, err = w.setupLocal()
+		default:
+			promptln(msg("wizard.backend_invalid", choice))
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err := writeConfigFile(configFile, settings); err != nil {
+		return false, storageError("writing the config file", err)
+	}
+	promptln(msg("wizard.saved", configFile))
+	return w.confirm(msg("wizard.log_now"))
+}
+
+// apply sets settings in the running process.
+func (w wizard) apply(settings [][2]string) error {
+	for _, setting := range settings {
+		if err := w.setenv(setting[0], setting[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setupSheets asks for the spreadsheet, tab and credentials and connects
+// with them the way every command does, until that works or the user gives
+// up.
+func (w wizard) setupSheets(ctx context.Context) ([][2]string, error) {
+	promptln(msg("wizard.sheets_intro"))
+	for {
+		id, err := w.ask(msg("auth.sheet_id_prompt"))
+		if err != nil {
+			return nil, err
+		}
+		if id == "" {
+			promptln(msg("auth.sheet_id_required"))
+			continue
+		}
+		if id, err = calio.ParseSpreadsheetID(id); err != nil {
+			promptln(err.Error())
+			continue
+		}
+		name, err := w.ask(msg("auth.sheet_name_prompt", calio.DefaultSheetName))
+		if err != nil {
+			return nil, err
+		}
+		credentials, err := w.askPath(msg("auth.credentials_prompt"), "")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(credentials); err != nil {
+			promptln(msg("wizard.check_failed", err))
+			continue
+		}
+
+		settings := [][2]string{{"CALI_STORAGE", "sheets"}, {"CALI_SHEET_ID", id}}
+		if name != "" {
+			settings = append(settings, [2]string{"CALI_SHEET_NAME", name})
+		}
+		settings = append(settings, [2]string{"CALI_GOOGLE_CREDENTIALS_JSON", credentials})
+		if err := w.apply(settings); err != nil {
+			return nil, err
+		}
+		promptln(msg("wizard.checking"))
+		if _, err := newBackend(ctx); err != nil {
+			promptln(msg("wizard.check_failed", err))
+			retry, err := w.confirm(msg("wizard.retry"))
+			if err != nil {
+				return nil, err
+			}
+			if !retry {
+				return nil, errCancelled
+			}
+			continue
+		}
+		promptln(msg("wizard.sheets_ok"))
+		return settings, nil
+	}
+}
+
+// setupLocal asks where to keep the log files and checks that cali can
+// write there.
+func (w wizard) setupLocal() ([][2]string, error) {
+	defaultDir, err := defaultLogDir()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		dir, err := w.askPath(msg("wizard.local_dir_prompt", defaultDir), defaultDir)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkWritable(dir); err != nil {
+			promptln(msg("wizard.check_failed", err))
+			continue
+		}
+		settings := [][2]string{{"CALI_STORAGE", "local"}}
+		if dir != defaultDir {
+			settings = append(settings, [2]string{"CALI_LOG_DIR", dir})
+		}
+		return settings, w.apply(settings)
+	}
+}
+
+// askPath asks for a file or directory, expanding a leading ~ and making it
+// absolute; an empty answer is fallback.
+func (w wizard) askPath(label, fallback string) (string, error) {
+	path, err := w.ask(label)
+	if err != nil || path == "" {
+		return fallback, err
+	}
+	return expandHome(path)
+}
+
+// expandHome expands a leading ~ in path to the home directory and makes
+// the path absolute.
+func expandHome(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || os.IsPathSeparator(rest[0])) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = homeDir + rest
+	}
+	return filepath.Abs(path)
+}
+
+// checkWritable creates dir if needed and makes sure a file can be created
+// in it.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(dir, ".cali-check-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// firstRun reports whether this is a first run that needs the wizard.
+func firstRun() bool {
+	logDir, err := defaultLogDir()
+	return err == nil && configFile != "" && needsSetup(os.Getenv, keyringStore, configFile, logDir)
+}
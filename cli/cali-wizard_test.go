@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const wizardSheetID = "1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms"
+
+// wizardHome gives the wizard an empty home with no storage settings, and
+// puts the settings it applies back afterwards.
+func wizardHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	for name, value := range map[string]string{
+		"HOME":            home,
+		"XDG_CONFIG_HOME": filepath.Join(home, ".config"),
+		"XDG_DATA_HOME":   filepath.Join(home, ".local", "share"),
+		"CALI_LANG":       "en",
+		"CALI_SHEET_NAME": "",
+	} {
+		t.Setenv(name, value)
+	}
+	for _, name := range setupEnvVars {
+		t.Setenv(name, "")
+	}
+	return home
+}
+
+// runWizard answers the wizard with script and returns what it printed.
+func runWizard(t *testing.T, script, configFile string) (out string, logNow bool, err error) {
+	t.Helper()
+	out = captureOutput(t, &os.Stdout, func() {
+		logNow, err = runSetupWizard(context.Background(), bufio.NewReader(strings.NewReader(script)), configFile)
+	})
+	return out, logNow, err
+}
+
+func TestSetupWizardLocal(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   func(home string) string
+		settings func(home string) string
+		logNow   bool
+		printed  string
+	}{
+		{"defaults",
+			func(string) string { return "\n\n\n" },
+			func(string) string { return "CALI_STORAGE=local\n" },
+			true, "Storage (default 2)"},
+		{"another directory",
+			func(string) string { return "3\nlocal\n~/workouts\nn\n" },
+			func(home string) string {
+				return "CALI_STORAGE=local\nCALI_LOG_DIR=" + filepath.Join(home, "workouts") + "\n"
+			},
+			false, `"3" is not one of the choices`},
+		{"unwritable directory",
+			func(home string) string {
+				return "2\n" + filepath.Join(home, "file", "log") + "\n" + filepath.Join(home, "log") + "\nno\n"
+			},
+			func(home string) string {
+				return "CALI_STORAGE=local\nCALI_LOG_DIR=" + filepath.Join(home, "log") + "\n"
+			},
+			false, "✗"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := wizardHome(t)
+			if err := os.WriteFile(filepath.Join(home, "file"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			configFile := filepath.Join(home, ".config", "cali", configFileName)
+
+			out, logNow, err := runWizard(t, tt.script(home), configFile)
+			if err != nil {
+				t.Fatalf("the wizard failed: %v\n%s", err, out)
+			}
+			if logNow != tt.logNow {
+				t.Errorf("log now = %v, want %v", logNow, tt.logNow)
+			}
+			if !strings.Contains(out, tt.printed) || !strings.Contains(out, "Settings saved to "+configFile) {
+				t.Errorf("the wizard printed\n%s\nwant %q and where it saved", out, tt.printed)
+			}
+			want := "# Written by cali. Environment variables override these settings.\n" + tt.settings(home)
+			if got := readFile(t, configFile); got != want {
+				t.Errorf("the config file holds\n%s\nwant\n%s", got, want)
+			}
+			if info, err := os.Stat(configFile); err != nil || info.Mode().Perm() != 0600 {
+				t.Errorf("the config file is %v, %v, want it readable by the user only", info.Mode(), err)
+			}
+			if os.Getenv("CALI_STORAGE") != "local" {
+				t.Errorf("CALI_STORAGE is %q after the wizard, want the answer applied", os.Getenv("CALI_STORAGE"))
+			}
+			dir, err := defaultLogDir()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if logDir := os.Getenv("CALI_LOG_DIR"); logDir != "" {
+				dir = logDir
+			}
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				t.Errorf("the log directory %s wasn't created: %v", dir, err)
+			}
+		})
+	}
+}
+
+// TestSetupWizardSheets goes through each check of the Sheets answers; the
+// last credentials don't connect and the user gives up.
+func TestSetupWizardSheets(t *testing.T) {
+	home := wizardHome(t)
+	configFile := filepath.Join(home, ".config", "cali", configFileName)
+	credentials := filepath.Join(home, "credentials.json")
+	if err := os.WriteFile(credentials, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	script := strings.Join([]string{
+		"sheets",
+		"",
+		"not an id!",
+		wizardSheetID, "", filepath.Join(home, "missing.json"),
+		"https://docs.google.com/spreadsheets/d/" + wizardSheetID + "/edit", "Workouts", credentials,
+		"n",
+	}, "\n") + "\n"
+
+	out, _, err := runWizard(t, script, configFile)
+	if !errors.Is(err, errCancelled) {
+		t.Fatalf("giving up returned %v, want errCancelled\n%s", err, out)
+	}
+	for _, want := range []string{"spreadsheet ID", "is not a spreadsheet ID or URL", "missing.json", "Checking access", "Try again?"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("the wizard printed\n%s\nwant %q", out, want)
+		}
+	}
+	if strings.Count(out, "✗") != 2 {
+		t.Errorf("the wizard printed\n%s\nwant the missing and the broken credentials reported", out)
+	}
+	if _, err := os.Stat(configFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a cancelled wizard wrote the config file: %v", err)
+	}
+	for name, want := range map[string]string{"CALI_STORAGE": "sheets", "CALI_SHEET_ID": wizardSheetID, "CALI_SHEET_NAME": "Workouts", "CALI_GOOGLE_CREDENTIALS_JSON": credentials} {
+		if got := os.Getenv(name); got != want {
+			t.Errorf("%s = %q while checking, want %q", name, got, want)
+		}
+	}
+}
+
+// TestSetupWizardEndOfInput stops at every question when stdin runs out,
+// instead of asking again forever. Settings are only saved once the
+// storage questions are all answered.
+func TestSetupWizardEndOfInput(t *testing.T) {
+	for _, tt := range []struct {
+		script string
+		saved  bool
+	}{
+		{"", false},
+		{"4\n", false},
+		{"2\n", false},
+		{"1\n" + wizardSheetID + "\n", false},
+		{"\n\n", true},
+	} {
+		home := wizardHome(t)
+		configFile := filepath.Join(home, ".config", "cali", configFileName)
+		out, _, err := runWizard(t, tt.script, configFile)
+		if !errors.Is(err, errCancelled) {
+			t.Errorf("input %q returned %v, want errCancelled\n%s", tt.script, err, out)
+		}
+		if _, err := os.Stat(configFile); (err == nil) != tt.saved {
+			t.Errorf("input %q: the config file is there: %v, want %v", tt.script, err == nil, tt.saved)
+		}
+	}
+}
+
+func TestNeedsSetup(t *testing.T) {
+	noEnv := func(string) string { return "" }
+	tests := []struct {
+		name    string
+		getenv  func(string) string
+		keyring *fakeKeyring
+		prepare func(t *testing.T, configFile, logDir string)
+		want    bool
+	}{
+		{"nothing", noEnv, &fakeKeyring{}, nil, true},
+		{"empty log directory", noEnv, &fakeKeyring{},
+			func(t *testing.T, _, logDir string) {
+				if err := os.MkdirAll(logDir, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}, true},
+		{"storage env", func(name string) string {
+			if name == "CALI_LOG_DIR" {
+				return "/tmp/log"
+			}
+			return ""
+		}, &fakeKeyring{}, nil, false},
+		{"config file", noEnv, &fakeKeyring{},
+			func(t *testing.T, configFile, _ string) {
+				if err := writeConfigFile(configFile, [][2]string{{"CALI_STORAGE", "local"}}); err != nil {
+					t.Fatal(err)
+				}
+			}, false},
+		{"spreadsheet in the keyring", noEnv, &fakeKeyring{values: map[string]string{keySheetID: wizardSheetID}}, nil, false},
+		{"local log", noEnv, &fakeKeyring{},
+			func(t *testing.T, _, logDir string) {
+				if err := os.MkdirAll(logDir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(logDir, "2026.txt"), []byte("x\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}, false},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		configFile, logDir := filepath.Join(dir, "config", configFileName), filepath.Join(dir, "log")
+		if tt.prepare != nil {
+			tt.prepare(t, configFile, logDir)
+		}
+		if got := needsSetup(tt.getenv, tt.keyring, configFile, logDir); got != tt.want {
+			t.Errorf("%s: needsSetup = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestConfigFileRoundTrip reads back what the wizard writes, with a shell
+// variable winning over the file.
+func TestConfigFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), configFileName)
+	if err := writeConfigFile(path, [][2]string{{"CALI_STORAGE", "sheets"}, {"CALI_SHEET_ID", wizardSheetID}}); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"CALI_STORAGE": "local"}
+	err := loadConfigFile(path, func(name string) string { return env[name] }, func(name, value string) error {
+		env[name] = value
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env["CALI_STORAGE"] != "local" || env["CALI_SHEET_ID"] != wizardSheetID {
+		t.Errorf("loading the config file gave %v", env)
+	}
+}
+
+func TestNoWizardFlag(t *testing.T) {
+	opts, err := parseLogOptions([]string{"--no-wizard"})
+	if err != nil || !opts.NoWizard {
+		t.Errorf("--no-wizard parses as %+v, %v", opts, err)
+	}
+}
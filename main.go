@@ -0,0 +1,14 @@
+package main
+
+// run after updating:
+// go run . self install
+
+import (
+	"os"
+
+	"github.com/ziad73/cali-logger/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:]))
+}